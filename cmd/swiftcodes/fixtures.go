@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	config "github.com/zdziszkee/swift-codes/internal/configurations"
+	"github.com/zdziszkee/swift-codes/internal/database"
+	"github.com/zdziszkee/swift-codes/internal/fixtures"
+)
+
+// runFixtures samples the configured table into a fixture file for
+// integration tests and the memory driver. The fixture is written to the
+// file named after the flags, or to stdout. It returns the process exit
+// code.
+func runFixtures(args []string) int {
+	flags := flag.NewFlagSet("fixtures", flag.ExitOnError)
+	configPath := flags.String("config", "", "Path to configuration file")
+	perCountry := flags.Int("per-country", 5, "Codes drawn from every country")
+	countries := flags.String("countries", "", "Comma-separated ISO2 codes to sample; empty samples all")
+	seed := flags.Uint64("seed", 1, "Seed of the draw; the same seed and table give the same fixture")
+	anonymize := flags.Bool("anonymize", false, "Replace bank names, addresses and institution codes")
+	format := flags.String("format", fixtures.FormatCSV, `"csv" or "json"`)
+	_ = flags.Parse(args)
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fixtures: %v\n", err)
+		return 1
+	}
+	if cfg.Database.Driver == database.DriverMemory {
+		fmt.Fprintln(os.Stderr, "fixtures: the memory driver has no table to sample")
+		return 1
+	}
+	opts := fixtures.Options{PerCountry: *perCountry, Seed: *seed, Anonymize: *anonymize}
+	for _, country := range strings.Split(*countries, ",") {
+		if country = strings.TrimSpace(country); country != "" {
+			opts.Countries = append(opts.Countries, country)
+		}
+	}
+	if err := opts.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "fixtures: %v\n", err)
+		return 1
+	}
+
+	db, err := connect(cfg.Database)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fixtures: %v\n", err)
+		return 1
+	}
+	defer db.DB.Close()
+
+	records, err := fixtures.Sample(context.Background(), db, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fixtures: %v\n", err)
+		return 1
+	}
+
+	var out io.Writer = os.Stdout
+	if path := flags.Arg(0); path != "" && path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "fixtures: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		out = file
+	}
+	if err := fixtures.Write(out, *format, records); err != nil {
+		fmt.Fprintf(os.Stderr, "fixtures: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "fixtures: wrote %d codes\n", len(records))
+	return 0
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/zdziszkee/swift-codes/internal/bootstrap"
+	config "github.com/zdziszkee/swift-codes/internal/configurations"
+	"github.com/zdziszkee/swift-codes/internal/database"
+	"github.com/zdziszkee/swift-codes/internal/importer"
+	"github.com/zdziszkee/swift-codes/internal/mirror"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+)
+
+// readyTimeout bounds how long startup waits for Trino to accept queries
+const readyTimeout = 2 * time.Minute
+
+// connect opens the database once Trino answers pings
+func connect(cfg database.Config) (*database.Database, error) {
+	db, err := database.Open(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), readyTimeout)
+	defer cancel()
+
+	log.Printf("Waiting for %s to accept connections", cfg.Driver)
+	if err := db.WaitReady(ctx, 2*time.Second); err != nil {
+		db.DB.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// runInit creates the schema, loads the dataset, runs quality checks and
+// prints a readiness summary. It returns the process exit code.
+func runInit(args []string) int {
+	flags := flag.NewFlagSet("init", flag.ExitOnError)
+	configPath := flags.String("config", "", "Path to configuration file")
+	schemaFile := flags.String("schema", "schema.sql", "Path to the schema SQL file")
+	loadFile := flags.String("load", "", "Path to SWIFT codes CSV file to load")
+	_ = flags.Parse(args)
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Printf("Failed to load configuration: %v", err)
+		return 1
+	}
+	if *loadFile != "" {
+		cfg.Data.SwiftCodesFile = *loadFile
+	}
+	if cfg.Database.Driver != database.DriverTrino {
+		log.Printf("init prepares Trino deployments; with the %s driver the server creates its table and loads data.swift_codes_file at start-up", cfg.Database.Driver)
+		return 1
+	}
+
+	db, err := connect(cfg.Database)
+	if err != nil {
+		log.Printf("Failed to connect to database: %v", err)
+		return 1
+	}
+	defer db.DB.Close()
+
+	repo := repository.NewSQLSwiftRepository(db, cfg.Database)
+	importOpts, err := importOptions(cfg)
+	if err != nil {
+		log.Printf("Initialization failed: %v", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	if err := db.EnsureLayout(ctx, cfg.Database.MigrateLegacy); err != nil {
+		log.Printf("Initialization failed: %v", err)
+		return 1
+	}
+
+	summary, err := bootstrap.Run(ctx, db, repo, bootstrap.Options{
+		SchemaFile: *schemaFile,
+		DataFile:   cfg.Data.SwiftCodesFile,
+		Golden:     cfg.Data.Golden,
+		Import:     importOpts,
+	})
+	if err != nil {
+		log.Printf("Initialization failed: %v", err)
+		return 1
+	}
+
+	summary.Report(os.Stdout)
+	if !summary.Ready() {
+		return 1
+	}
+	return 0
+}
+
+// importOptions translates the data configuration into importer options
+func importOptions(cfg *config.Config) ([]importer.Option, error) {
+	var opts []importer.Option
+	if cfg.Data.TolerantHeader {
+		opts = append(opts, importer.WithTolerantHeader())
+	}
+	if cfg.Data.Upsert {
+		opts = append(opts, importer.WithUpsert())
+	}
+	// Files follow the same BIC8 equivalence as the API
+	if !cfg.Service.LegacyBICMatching {
+		opts = append(opts, importer.WithCanonicalBICs())
+	}
+	if quarantine := cfg.Data.Quarantine; quarantine.Enabled {
+		if quarantine.Dir != "" {
+			opts = append(opts, importer.WithQuarantine(importer.DirQuarantine(quarantine.Dir)))
+		} else {
+			store, err := mirror.NewObjectStore(cfg.Mirror)
+			if err != nil {
+				return nil, fmt.Errorf("quarantine: %w", err)
+			}
+			opts = append(opts, importer.WithQuarantine(importer.ObjectQuarantine{Store: store, Prefix: quarantine.ObjectPrefix}))
+		}
+	}
+	return opts, nil
+}
@@ -2,30 +2,68 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
+	"io"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/zdziszkee/swift-codes/internal/api/graphql"
+	"github.com/zdziszkee/swift-codes/internal/api/grpcapi"
 	handler "github.com/zdziszkee/swift-codes/internal/api/handlers"
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
 	"github.com/zdziszkee/swift-codes/internal/api/router"
+	"github.com/zdziszkee/swift-codes/internal/audit"
+	"github.com/zdziszkee/swift-codes/internal/cachebus"
 	config "github.com/zdziszkee/swift-codes/internal/configurations"
+	"github.com/zdziszkee/swift-codes/internal/correlation"
 	"github.com/zdziszkee/swift-codes/internal/database"
-	"github.com/zdziszkee/swift-codes/internal/models"
-	parser "github.com/zdziszkee/swift-codes/internal/parsers"
-	csvreader "github.com/zdziszkee/swift-codes/internal/readers/csv"
+	"github.com/zdziszkee/swift-codes/internal/events"
+	"github.com/zdziszkee/swift-codes/internal/importer"
+	"github.com/zdziszkee/swift-codes/internal/mirror"
 	repository "github.com/zdziszkee/swift-codes/internal/repositories"
 	service "github.com/zdziszkee/swift-codes/internal/services"
+	"github.com/zdziszkee/swift-codes/internal/sftpfeed"
+	"github.com/zdziszkee/swift-codes/internal/webhooks"
+	"google.golang.org/grpc"
 )
 
+// eventBuffer is how many changes an event stream client may lag behind
+// before it is disconnected
+const eventBuffer = 256
+
+// eventHistory is how many changes watch clients can catch up on
+const eventHistory = 1024
+
 func main() {
+	// "swiftcodes init" prepares a fresh deployment and exits
+	if len(os.Args) > 1 && os.Args[1] == "init" {
+		os.Exit(runInit(os.Args[2:]))
+	}
+	// "swiftcodes healthcheck" probes a running server for container
+	// health checks
+	if len(os.Args) > 1 && os.Args[1] == "healthcheck" {
+		os.Exit(runHealthcheck(os.Args[2:]))
+	}
+	// "swiftcodes replay" sends the GET traffic of a JSON access log to
+	// another environment
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		os.Exit(runReplay(os.Args[2:]))
+	}
+	// "swiftcodes fixtures" samples the table into a fixture file for tests
+	if len(os.Args) > 1 && os.Args[1] == "fixtures" {
+		os.Exit(runFixtures(os.Args[2:]))
+	}
+
 	// Parse command line flags
 	configPath := flag.String("config", "", "Path to configuration file")
 	loadFile := flag.String("load", "", "Path to SWIFT codes CSV file to load")
 	flag.Parse()
-	time.Sleep(20 * time.Second)
 
 	// Load configuration
 	cfg, err := config.Load(*configPath)
@@ -34,73 +72,382 @@ func main() {
 	}
 
 	// Override config with command line flags if provided
-	if *loadFile != "" {
-		cfg.Data.SwiftCodesFile = *loadFile
-		cfg.Data.AutoLoad = true
+	applyFlags := func(cfg *config.Config) {
+		if *loadFile != "" {
+			cfg.Data.SwiftCodesFile = *loadFile
+			cfg.Data.AutoLoad = true
+		}
 	}
+	applyFlags(cfg)
+	// Settings that a configuration reload applies while the server runs
+	reloader := config.NewReloader(*configPath, cfg, applyFlags)
 
-	// Initialize database
-	db, err := database.New(cfg.Database)
-	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+	// Initialize database once it is reachable; the memory driver keeps the
+	// data in the process instead. Features running Trino SQL of their own
+	// are only offered on Trino.
+	var db *database.Database
+	onTrino := cfg.Database.Driver == database.DriverTrino
+	if cfg.Database.Driver != database.DriverMemory {
+		db, err = connect(cfg.Database)
+		if err != nil {
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
+		defer db.DB.Close()
+	}
+	if db != nil && !onTrino {
+		tables := []string{cfg.Database.TableName}
+		for _, table := range cfg.Datasets.Tables {
+			tables = append(tables, table)
+		}
+		for _, table := range tables {
+			if err := db.CreateTable(context.Background(), table); err != nil {
+				log.Fatalf("Failed to create tables: %v", err)
+			}
+		}
+		if cfg.Aliases.Enabled {
+			if err := db.CreateAliasTable(context.Background(), cfg.Aliases.Table); err != nil {
+				log.Fatalf("Failed to create tables: %v", err)
+			}
+		}
+	}
+	if onTrino {
+		if err := db.EnsureLayout(context.Background(), cfg.Database.MigrateLegacy); err != nil {
+			log.Fatalf("Failed to check table layout: %v", err)
+		}
+		if err := db.ExecuteSchema("schema.sql"); err != nil {
+			log.Fatalf("Failed to execute schema: %v", err)
+		}
+		if cfg.Database.BackfillCountryNames {
+			updated, err := db.BackfillCountryNames(context.Background())
+			if err != nil {
+				log.Fatalf("Failed to backfill country names: %v", err)
+			}
+			log.Printf("Backfilled country names of %d rows", updated)
+		}
 	}
-	defer db.DB.Close()
 
 	// Initialize repository
-	repo := repository.NewSQLSwiftRepository(db, cfg.Database)
+	queryTracker := repository.NewQueryTracker()
+	repoMetrics := repository.NewMetrics()
+	// SQL is logged at the debug level
+	var debugLog atomic.Bool
+	debugLog.Store(strings.EqualFold(cfg.Log.Level, "debug"))
+	reloader.OnChange("log.level", func(cfg *config.Config) {
+		debugLog.Store(strings.EqualFold(cfg.Log.Level, "debug"))
+	})
+	repoOpts := []repository.Option{
+		repository.WithQueryTracker(queryTracker),
+		repository.WithQueryLog(cfg.Repository.QueryLog, debugLog.Load),
+	}
+	if cfg.Repository.PartialBranches {
+		repoOpts = append(repoOpts, repository.WithPartialBranches(repoMetrics))
+	}
+	// Replicas drop each other's stale cache entries through a shared bus
+	var cacheBus repository.InvalidationBus
+	if cfg.CacheBus.Enabled {
+		bus := cachebus.NewBus(cfg.CacheBus, cachebus.TCPDialer(cfg.CacheBus))
+		busCtx, stopBus := context.WithCancel(context.Background())
+		defer stopBus()
+		go bus.Run(busCtx)
+		cacheBus = bus
+	}
+	cacheTTL := repository.NewCacheTTL(cfg.Repository.CacheTTL)
+	if cfg.Repository.CacheTTL > 0 {
+		reloader.OnChange("repository.cache_ttl", func(cfg *config.Config) {
+			cacheTTL.Set(cfg.Repository.CacheTTL)
+		})
+	}
+	// Lookups missed by the in-process cache are shared through Redis,
+	// namespaced by table
+	var redisCache *cachebus.RedisCache
+	if cfg.RedisCache.Enabled {
+		redisCache = cachebus.NewRedisCache(cfg.RedisCache, cachebus.CacheDialer(cfg.RedisCache))
+	}
+	repoMiddlewares := func(table string) []repository.Middleware {
+		var remote repository.Middleware
+		if redisCache != nil {
+			remote = redisCache.Middleware(table, cfg.Repository.OverwriteDuplicates)
+		}
+		middlewares := cfg.Repository.Middlewares(repoMetrics, cacheBus, cacheTTL, remote)
+		if cfg.API.ServerTiming {
+			middlewares = append(middlewares, repository.WithTiming())
+		}
+		return middlewares
+	}
+	// With failover every table is read from the first healthy cluster and
+	// written on the cluster chosen by an operator
+	tableRepo := func(dbCfg database.Config) repository.SwiftRepository {
+		return repository.NewSQLSwiftRepository(db, dbCfg, repoOpts...)
+	}
+	if db == nil {
+		log.Println("Using the in-memory repository; data does not survive a restart")
+		tableRepo = func(database.Config) repository.SwiftRepository {
+			return repository.NewMemorySwiftRepository()
+		}
+	}
+	var failover *repository.Failover
+	if cfg.Database.Failover.Enabled {
+		secondary, err := database.Open(cfg.Database.Secondary())
+		if err != nil {
+			log.Fatalf("Failed to open secondary Trino cluster: %v", err)
+		}
+		defer secondary.DB.Close()
+		failover = repository.NewFailover(cfg.Database.Failover,
+			repository.Cluster{Name: repository.ClusterPrimary, Ping: db.Ping},
+			repository.Cluster{Name: repository.ClusterSecondary, Ping: secondary.Ping},
+		)
+		healthCtx, stopHealth := context.WithCancel(context.Background())
+		defer stopHealth()
+		go failover.Run(healthCtx)
+		tableRepo = func(dbCfg database.Config) repository.SwiftRepository {
+			return failover.Repository(
+				repository.NewSQLSwiftRepository(db, dbCfg, repoOpts...),
+				repository.NewSQLSwiftRepository(secondary, dbCfg, repoOpts...),
+			)
+		}
+	}
+	repo := repository.Chain(tableRepo(cfg.Database), repoMiddlewares(cfg.Database.TableName)...)
 
-	// Initialize service
-	swiftService := service.NewSwiftService(repo)
+	// Initialize service; with several datasets every call is routed to the
+	// service of the selected table
+	baseService := service.NewSwiftService(repo, cfg.Service)
+	var datasetHandler *handler.DatasetHandler
+	if len(cfg.Datasets.Tables) > 0 {
+		services := make(map[string]service.SwiftService, len(cfg.Datasets.Tables))
+		for name, table := range cfg.Datasets.Tables {
+			datasetRepo := repo
+			if table != cfg.Database.TableName {
+				datasetCfg := cfg.Database
+				datasetCfg.TableName = table
+				datasetRepo = repository.Chain(tableRepo(datasetCfg), repoMiddlewares(table)...)
+			}
+			services[name] = service.NewSwiftService(datasetRepo, cfg.Service)
+		}
+		datasets, err := service.NewDatasetRouter(services, cfg.Datasets.Default)
+		if err != nil {
+			log.Fatalf("Failed to configure datasets: %v", err)
+		}
+		log.Printf("Serving datasets %v, default %s", datasets.Names(), datasets.Default())
+		baseService = datasets
+		datasetHandler = handler.NewDatasetHandler(datasets)
+	}
+	baseService = service.WithCountryNames(baseService)
+	// Aliases are shared by every dataset; lookups of an alias read the
+	// bank it names and new codes may not take one
+	var aliasHandler *handler.AliasHandler
+	if cfg.Aliases.Enabled {
+		var aliasRepo repository.AliasRepository = repository.NewMemoryAliasRepository()
+		if db != nil {
+			aliasRepo = repository.NewSQLAliasRepository(db, cfg.Aliases.Table)
+		}
+		baseService = service.WithAliases(baseService, aliasRepo)
+		aliasHandler = handler.NewAliasHandler(service.NewAliases(baseService, aliasRepo))
+	}
+	if cfg.Sampling.Enabled {
+		log.Printf("Sampling mode: serving %.0f%% of institutions, masking %v", cfg.Sampling.Rate*100, cfg.Sampling.MaskFields)
+		baseService = service.WithSampling(baseService, cfg.Sampling)
+	}
+	var tierLimits *middleware.TierLimits
+	if cfg.Tiers.Enabled {
+		log.Printf("Access tiers: anonymous %d/min, authenticated %d/min", cfg.Tiers.Anonymous.RequestsPerMinute, cfg.Tiers.Authenticated.RequestsPerMinute)
+		baseService = service.WithRedaction(baseService)
+		tierLimits = middleware.NewTierLimits(cfg.Tiers)
+		updateTiers := func(cfg *config.Config) { tierLimits.Update(cfg.Tiers) }
+		reloader.OnChange("tiers.anonymous", updateTiers)
+		reloader.OnChange("tiers.authenticated", updateTiers)
+	}
+	var auditHandler *handler.AuditHandler
+	if cfg.Audit.Enabled && !onTrino {
+		log.Println("WARNING: the audit log needs the trino driver and is disabled")
+	}
+	if cfg.Audit.Enabled && onTrino {
+		auditLog := audit.NewLog(db, cfg.Audit.Table)
+		baseService = audit.WithAudit(baseService, auditLog)
+		auditHandler = handler.NewAuditHandler(auditLog)
+	}
+	accessStats := service.NewAccessStats()
+	swiftService := service.WithAccessStats(baseService, accessStats)
+	// Warm-ups read below the access statistics so they do not count as
+	// traffic, and only make sense with a cache to fill
+	var cacheHandler *handler.CacheHandler
+	if cfg.Repository.CacheTTL > 0 {
+		cacheHandler = handler.NewCacheHandler(baseService, accessStats)
+	}
+	if cfg.API.ServerTiming {
+		swiftService = service.WithTiming(swiftService)
+	}
 
-	// Auto-load data if configured
-	if cfg.Data.AutoLoad && cfg.Data.SwiftCodesFile != "" {
-		log.Printf("Loading SWIFT codes from %s", cfg.Data.SwiftCodesFile)
+	// Publish data changes to the Last-Modified clock, the country versions,
+	// the event stream and registered webhooks
+	eventBus := events.NewBus(eventBuffer, eventHistory)
+	defer eventBus.Close()
+	changeClock := service.NewChangeClock()
+	countryVersions := service.NewCountryVersions()
+	if cacheBus != nil {
+		// Replicas keep their country versions and clocks in step, so one
+		// of them never confirms data another one changed
+		countryVersions.Share(cacheBus)
+		changeClock.Follow(cacheBus)
+	}
+	changeHooks := []service.ChangeHook{changeClock.Record, countryVersions.Record, eventBus.Publish}
+	var webhookHandler *handler.WebhookHandler
+	if cfg.Webhooks.Enabled {
+		registry := webhooks.NewRegistry()
+		dispatcher := webhooks.NewDispatcher(registry, cfg.Webhooks)
+		dispatchCtx, stopDispatch := context.WithCancel(context.Background())
+		defer stopDispatch()
+		go dispatcher.Run(dispatchCtx)
 
-		// Use a timeout context for loading
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-		defer cancel()
+		changeHooks = append(changeHooks, dispatcher.Notify)
+		webhookHandler = handler.NewWebhookHandler(registry)
+	}
+	swiftService = service.WithChangeHooks(swiftService, changeHooks...)
+	importOpts, err := importOptions(cfg)
+	if err != nil {
+		log.Fatalf("Failed to configure importer: %v", err)
+	}
+	importOpts = append(importOpts, importer.WithLoadHook(func(ctx context.Context, summary importer.Summary) {
+		for _, hook := range changeHooks {
+			hook(ctx, service.Change{Type: service.ChangeBulkLoaded, Count: int64(summary.Loaded), IDs: correlation.FromContext(ctx)})
+		}
+	}))
 
-		// Open the CSV file
-		file, err := os.Open(cfg.Data.SwiftCodesFile)
-		if err != nil {
-			log.Printf("WARNING: Failed to open SWIFT codes file: %v", err)
-		} else {
-			defer file.Close()
+	// Maintenance windows make the service and the importer read-only and
+	// pause the SFTP feed; configured ones follow configuration reloads
+	maintenanceSchedule := service.NewMaintenanceSchedule(cfg.MaintenanceWindows)
+	reloader.OnChange("maintenance_windows", func(cfg *config.Config) {
+		maintenanceSchedule.SetConfigured(cfg.MaintenanceWindows)
+	})
+	importOpts = append(importOpts, importer.WithWriteCheck(maintenanceSchedule.Refuse))
 
-			// Load SWIFT bank records from CSV
-			reader := csvreader.CSVSwiftBanksReader{}
-			records, err := reader.LoadSwiftBanks(file)
-			if err != nil {
-				log.Printf("WARNING: Failed to read CSV file: %v", err)
-			} else {
-				// Parse the records into SwiftBank models
-				defaultParser := parser.DefaultSwiftBanksParser{}
-				banks, err := defaultParser.ParseSwiftBanks(records)
-				if err != nil {
-					log.Printf("WARNING: Failed to parse SWIFT bank records: %v", err)
-				} else {
-					// Convert banks slice to a slice of pointers to models.SwiftBank
-					var bankPtrs []*models.SwiftBank
-					for i := range banks {
-						bankPtrs = append(bankPtrs, &banks[i])
-					}
-					// Create banks in batches
-					err = repo.CreateBatch(ctx, bankPtrs)
-					if err != nil {
-						log.Printf("WARNING: Failed to load SWIFT codes into database: %v", err)
-					} else {
-						log.Printf("Successfully loaded %d SWIFT codes", len(bankPtrs))
-					}
-				}
+	// Auto-load data if configured. Unless the load is set to block
+	// startup, it runs while the server already answers reads, and the
+	// dataset status reports "loading" until it finishes
+	dataImporter := importer.NewImporter(repo, cfg.Data.Golden, importOpts...)
+	startupLoad := &service.StartupLoad{}
+	if cfg.Data.BlockingAutoLoad {
+		if err := autoLoad(context.Background(), cfg, dataImporter); errors.Is(err, importer.ErrGoldenMismatch) {
+			log.Fatalf("Import failed golden dataset check: %v", err)
+		}
+	} else {
+		// A failed load leaves the server up on the data stored before it;
+		// the importer history, the import failure metrics and the
+		// dataset status report it
+		startupLoad.Begin()
+		loadCtx, stopLoad := context.WithCancel(context.Background())
+		defer stopLoad()
+		go func() {
+			err := autoLoad(loadCtx, cfg, dataImporter)
+			if errors.Is(err, importer.ErrGoldenMismatch) {
+				log.Printf("ALERT: startup import failed golden dataset check, serving the previous data: %v", err)
+			}
+			startupLoad.Finish(err)
+		}()
+	}
+	swiftService = service.WithStartupLoad(swiftService, startupLoad)
+
+	swiftService = service.WithMaintenance(swiftService, maintenanceSchedule)
+
+	// Pull directory updates that institutions deliver over SFTP
+	if cfg.SFTP.Enabled {
+		dial, err := sftpfeed.SSHDialer(cfg.SFTP)
+		if err != nil {
+			log.Fatalf("Failed to configure SFTP feed: %v", err)
+		}
+		feedCtx, stopFeed := context.WithCancel(context.Background())
+		defer stopFeed()
+		go sftpfeed.NewFetcher(cfg.SFTP, dial).PauseWhile(maintenanceSchedule.InMaintenance).Run(feedCtx, func(ctx context.Context, name string, r io.Reader) error {
+			summary, err := dataImporter.RunNamed(ctx, name, r)
+			if err == nil {
+				log.Printf("Loaded %d SWIFT codes from %s (%d skipped)", summary.Loaded, name, summary.Skipped)
 			}
+			return err
+		})
+	}
+
+	// Publish export snapshots to object storage for large downloads
+	var exportHandler *handler.ExportHandler
+	if cfg.Mirror.Enabled {
+		publisher, err := mirror.NewPublisher(repo, cfg.Mirror, cfg.Attribution, changeClock.LastModified)
+		if err != nil {
+			log.Fatalf("Failed to configure export mirror: %v", err)
 		}
+		mirrorCtx, stopMirror := context.WithCancel(context.Background())
+		defer stopMirror()
+		go publisher.Run(mirrorCtx)
+		exportHandler = handler.NewExportHandler(publisher)
 	}
 
-	// Initialize handler
-	handler := handler.NewSwiftHandler(swiftService)
+	// Writes may be restricted to internal networks
+	allowlist, err := middleware.NewIPAllowlist(cfg.IPAllowlist)
+	if err != nil {
+		log.Fatalf("Failed to configure IP allowlist: %v", err)
+	}
+
+	// Initialize handlers
+	swiftHandler := handler.NewSwiftHandler(swiftService, cfg.API)
+	reloader.OnChange("api.computed_fields", func(cfg *config.Config) {
+		swiftHandler.SetComputedFields(cfg.API.ComputedFields)
+	})
+	graphqlHandler := graphql.NewHandler(swiftService, cfg.Auth, allowlist)
+	adminHandler := handler.NewAdminHandler(queryTracker, repoMetrics, accessStats)
+	reloadHandler := handler.NewReloadHandler(dataImporter, cfg.Data.SwiftCodesFile)
+	statsHandler := handler.NewStatsHandler(swiftService, dataImporter)
+	// Maintenance and the schema status run SQL on Trino
+	var maintenanceHandler *handler.MaintenanceHandler
+	var schemaHandler *handler.SchemaHandler
+	if onTrino {
+		maintenanceHandler = handler.NewMaintenanceHandler(db)
+		schemaHandler = handler.NewSchemaHandler(db)
+	}
+	var failoverHandler *handler.FailoverHandler
+	if failover != nil {
+		failoverHandler = handler.NewFailoverHandler(failover)
+	}
+	// Analytical templates read the table directly, so a sampled demo
+	// deployment does not offer them
+	var queryHandler *handler.QueryHandler
+	if !cfg.Sampling.Enabled && onTrino {
+		queryHandler = handler.NewQueryHandler(db)
+	}
+	metricsHandler := handler.NewMetricsHandler(dataImporter)
+	if cfg.Repository.CacheTTL > 0 {
+		metricsHandler.ReportCache(repoMetrics)
+	}
+	var scrapeGuard *middleware.ScrapeGuard
+	if cfg.Scraping.Enabled {
+		scrapeGuard = middleware.NewScrapeGuard(cfg.Scraping, cfg.Auth)
+		metricsHandler.ReportScraping(scrapeGuard)
+	}
+	eventsHandler := handler.NewEventsHandler(eventBus)
 
 	// Setup routes
-	app := router.SetupRoutes(handler)
+	app := router.SetupRoutes(router.Handlers{
+		Swift:          swiftHandler,
+		GraphQL:        graphqlHandler,
+		Admin:          adminHandler,
+		Reload:         reloadHandler,
+		Stats:          statsHandler,
+		Maintenance:    maintenanceHandler,
+		Queries:        queryHandler,
+		Webhooks:       webhookHandler,
+		Metrics:        metricsHandler,
+		Events:         eventsHandler,
+		Export:         exportHandler,
+		Datasets:       datasetHandler,
+		Audit:          auditHandler,
+		Aliases:        aliasHandler,
+		Schema:         schemaHandler,
+		Failover:       failoverHandler,
+		Config:         handler.NewConfigHandler(reloader),
+		Cache:          cacheHandler,
+		Windows:        handler.NewMaintenanceScheduleHandler(maintenanceSchedule),
+		TierLimits:     tierLimits,
+		LastModified:   changeClock.LastModified,
+		CountryVersion: countryVersions.Version,
+		Allowlist:      allowlist,
+		Scraping:       scrapeGuard,
+	}, cfg)
 
 	// Start server in a goroutine so we can handle graceful shutdown
 	go func() {
@@ -110,6 +457,18 @@ func main() {
 		}
 	}()
 
+	// Start the gRPC server on its own port
+	var grpcServer *grpc.Server
+	if cfg.GRPC.Enabled {
+		grpcServer = grpcapi.NewServer(swiftService, cfg.Auth, allowlist)
+		go func() {
+			log.Printf("Starting gRPC server on %s", cfg.GRPC.Address)
+			if err := grpcapi.Serve(grpcServer, cfg.GRPC); err != nil {
+				log.Fatalf("gRPC server error: %v", err)
+			}
+		}()
+	}
+
 	// Set up graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -121,9 +480,50 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	if grpcServer != nil {
+		grpcServer.GracefulStop()
+	}
+
+	// End open event streams and watches so they do not hold up the shutdown
+	eventBus.Close()
+
 	if err := app.ShutdownWithContext(ctx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
 	log.Println("Server exiting")
 }
+
+// autoLoad imports the configured SWIFT codes and contacts files and
+// returns the error of a failed SWIFT codes import. A failed contacts
+// import is only logged.
+func autoLoad(ctx context.Context, cfg *config.Config, dataImporter *importer.Importer) error {
+	var loadErr error
+	if cfg.Data.AutoLoad && cfg.Data.SwiftCodesFile != "" {
+		log.Printf("Loading SWIFT codes from %s", cfg.Data.SwiftCodesFile)
+
+		// Use a timeout context for loading
+		loadCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+		defer cancel()
+
+		count, err := dataImporter.ImportFile(loadCtx, cfg.Data.SwiftCodesFile)
+		if err != nil {
+			log.Printf("WARNING: %v", err)
+			loadErr = err
+		} else {
+			log.Printf("Successfully loaded %d SWIFT codes", count)
+		}
+	}
+	if cfg.Data.ContactsFile != "" {
+		loadCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+		defer cancel()
+
+		summary, err := dataImporter.ImportContactsFile(loadCtx, cfg.Data.ContactsFile)
+		if err != nil {
+			log.Printf("WARNING: %v", err)
+		} else {
+			log.Printf("Loaded contacts for %d SWIFT codes from %s", summary.Updated, cfg.Data.ContactsFile)
+		}
+	}
+	return loadErr
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/zdziszkee/swift-codes/internal/replay"
+)
+
+// runReplay replays the GET requests of a JSON access log against a target
+// environment and prints a summary of statuses and latencies. The log is
+// read from the file named after the flags, or from stdin. It returns the
+// process exit code: 0 once the log is replayed, whatever the answers, and
+// 1 when the replay cannot run.
+func runReplay(args []string) int {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	target := flags.String("target", "", "Base URL of the environment to replay against, e.g. http://staging:8081")
+	speed := flags.Float64("speed", 1, "Multiple of the recorded pace; 0 sends requests as fast as -concurrency allows")
+	concurrency := flags.Int("concurrency", 8, "Most requests in flight")
+	timeout := flags.Duration("timeout", 10*time.Second, "How long to wait for each answer")
+	token := flags.String("token", "", "Bearer token sent with every request")
+	exclude := flags.String("exclude", "/v1/events,/v1/swiftCodes/watch", "Comma-separated path prefixes not to replay")
+	limit := flags.Int("limit", 0, "Stop after this many requests; 0 replays the whole log")
+	_ = flags.Parse(args)
+
+	var logs io.Reader = os.Stdin
+	if path := flags.Arg(0); path != "" && path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "replay: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		logs = file
+	}
+
+	cfg := replay.Config{
+		Target:      *target,
+		Speed:       *speed,
+		Concurrency: *concurrency,
+		Timeout:     *timeout,
+		Header:      http.Header{},
+		Limit:       *limit,
+	}
+	if *token != "" {
+		cfg.Header.Set("Authorization", "Bearer "+*token)
+	}
+	for _, prefix := range strings.Split(*exclude, ",") {
+		if prefix = strings.TrimSpace(prefix); prefix != "" {
+			cfg.Exclude = append(cfg.Exclude, prefix)
+		}
+	}
+
+	// Ctrl+C stops the replay and still prints what was sent so far
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	summary, err := replay.Run(ctx, logs, cfg, &http.Client{Transport: &http.Transport{MaxIdleConnsPerHost: *concurrency}})
+	if summary == nil {
+		fmt.Fprintf(os.Stderr, "replay: %v\n", err)
+		return 1
+	}
+	printReplaySummary(os.Stdout, summary)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "replay: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func printReplaySummary(w io.Writer, s *replay.Summary) {
+	fmt.Fprintf(w, "Replayed %d requests in %v (%d failed, %d log lines skipped)\n",
+		s.Sent+s.Failed, s.Duration.Round(time.Millisecond), s.Failed, s.Skipped)
+	statuses := make([]int, 0, len(s.Statuses))
+	for status := range s.Statuses {
+		statuses = append(statuses, status)
+	}
+	slices.Sort(statuses)
+	for _, status := range statuses {
+		fmt.Fprintf(w, "  %d: %d\n", status, s.Statuses[status])
+	}
+	fmt.Fprintf(w, "Status differing from the log: %d\n", s.Mismatched)
+	fmt.Fprintf(w, "Latency p50 %v, p90 %v, p99 %v, max %v\n",
+		s.P50.Round(time.Microsecond), s.P90.Round(time.Microsecond), s.P99.Round(time.Microsecond), s.Max.Round(time.Microsecond))
+}
@@ -0,0 +1,51 @@
+// Package adminui serves a small embedded admin page for deployments without
+// a separate frontend. The page itself holds no data: every search, reload
+// and diagnostic it shows goes through the API with the admin token entered
+// by the user, so the admin auth layer protects it like any other client.
+package adminui
+
+import (
+	"embed"
+	"io/fs"
+	"path"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+)
+
+//go:embed static
+var assets embed.FS
+
+// contentTypes maps the asset extensions in static to their MIME types
+var contentTypes = map[string]string{
+	".html": fiber.MIMETextHTMLCharsetUTF8,
+	".js":   fiber.MIMETextJavaScriptCharsetUTF8,
+	".css":  fiber.MIMETextCSSCharsetUTF8,
+}
+
+// Index serves the admin page
+func Index(c fiber.Ctx) error {
+	return serve(c, "index.html")
+}
+
+// Asset serves the script or stylesheet named in the :file parameter
+func Asset(c fiber.Ctx) error {
+	return serve(c, c.Params("file"))
+}
+
+func serve(c fiber.Ctx, name string) error {
+	contentType, ok := contentTypes[path.Ext(name)]
+	if !ok || name != path.Base(name) {
+		return apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "Asset not found")
+	}
+	body, err := fs.ReadFile(assets, "static/"+name)
+	if err != nil {
+		return apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "Asset not found")
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	// The page only talks to this API and never embeds third-party content
+	c.Set("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'")
+	return c.Status(fiber.StatusOK).Send(body)
+}
@@ -0,0 +1,64 @@
+package adminui_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/adminui"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+)
+
+func TestAdminUI(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Admin UI Suite")
+}
+
+var _ = Describe("Admin UI", func() {
+	var app *fiber.App
+
+	BeforeEach(func() {
+		app = fiber.New(fiber.Config{ErrorHandler: apierror.Handler})
+		app.Get("/admin/ui", adminui.Index)
+		app.Get("/admin/ui/:file", adminui.Asset)
+	})
+
+	get := func(path string) (*http.Response, string) {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil), fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return resp, string(body)
+	}
+
+	It("should serve the page with a restrictive CSP", func() {
+		resp, body := get("/admin/ui")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get(fiber.HeaderContentType)).To(HavePrefix(fiber.MIMETextHTML))
+		Expect(resp.Header.Get("Content-Security-Policy")).To(ContainSubstring("default-src 'self'"))
+		Expect(body).To(ContainSubstring(`<script src="/admin/ui/app.js">`))
+	})
+
+	It("should serve the script and stylesheet", func() {
+		resp, body := get("/admin/ui/app.js")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get(fiber.HeaderContentType)).To(HavePrefix(fiber.MIMETextJavaScript))
+		Expect(body).To(ContainSubstring("/v1/admin/imports"))
+
+		resp, _ = get("/admin/ui/style.css")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get(fiber.HeaderContentType)).To(HavePrefix(fiber.MIMETextCSS))
+	})
+
+	It("should not serve anything outside the known assets", func() {
+		for _, path := range []string{"/admin/ui/missing.js", "/admin/ui/adminui.go", "/admin/ui/..%2Fadminui.go"} {
+			resp, _ := get(path)
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound), path)
+		}
+	})
+})
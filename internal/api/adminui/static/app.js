@@ -0,0 +1,86 @@
+"use strict";
+
+// The token only lives in this tab; it is sent as a bearer token on every call
+const tokenKey = "swift-codes-admin-token";
+
+function api(path, options = {}) {
+  const headers = { Accept: "application/json" };
+  const token = sessionStorage.getItem(tokenKey);
+  if (token) {
+    headers.Authorization = "Bearer " + token;
+  }
+  return fetch(path, { ...options, headers }).then(async (resp) => {
+    const body = await resp.json().catch(() => null);
+    if (!resp.ok) {
+      const message = body && body.message ? body.message : resp.statusText;
+      const code = body && body.code ? " (" + body.code + ")" : "";
+      throw new Error(resp.status + " " + message + code);
+    }
+    return body;
+  });
+}
+
+function show(id, value) {
+  document.getElementById(id).textContent =
+    value instanceof Error ? value.message : JSON.stringify(value, null, 2);
+}
+
+function cell(row, text) {
+  const td = document.createElement("td");
+  td.textContent = text === undefined || text === null ? "" : String(text);
+  row.appendChild(td);
+}
+
+function loadImports() {
+  return api("/v1/admin/imports")
+    .then((body) => {
+      const rows = document.getElementById("imports");
+      rows.replaceChildren();
+      for (const run of body.imports) {
+        const row = document.createElement("tr");
+        cell(row, run.finished_at);
+        cell(row, run.duration_ms);
+        cell(row, run.rows);
+        cell(row, run.loaded);
+        cell(row, run.skipped);
+        cell(row, run.error);
+        rows.appendChild(row);
+      }
+    })
+    .catch((err) => show("reload-result", err));
+}
+
+document.getElementById("token").value = sessionStorage.getItem(tokenKey) || "";
+
+document.getElementById("token-form").addEventListener("submit", (event) => {
+  event.preventDefault();
+  sessionStorage.setItem(tokenKey, document.getElementById("token").value.trim());
+  loadImports();
+});
+
+document.getElementById("search-form").addEventListener("submit", (event) => {
+  event.preventDefault();
+  const query = document.getElementById("query").value.trim().toUpperCase();
+  const path = query.length === 2
+    ? "/v1/swiftCodes/country/" + encodeURIComponent(query)
+    : "/v1/swiftCodes/" + encodeURIComponent(query) + "?fields=contacts,swiftCode,bankName,address,countryName,isHeadquarter";
+  api(path).then((body) => show("search-result", body)).catch((err) => show("search-result", err));
+});
+
+document.getElementById("reload").addEventListener("click", () => {
+  show("reload-result", "Reloading...");
+  api("/v1/admin/reload", { method: "POST" })
+    .then((body) => show("reload-result", body))
+    .catch((err) => show("reload-result", err))
+    .finally(loadImports);
+});
+
+document.getElementById("refresh-imports").addEventListener("click", loadImports);
+
+document.getElementById("load-diagnostic").addEventListener("click", () => {
+  api(document.getElementById("diagnostic").value)
+    .then((body) => show("diagnostic-result", body))
+    .catch((err) => show("diagnostic-result", err));
+});
+
+loadImports();
@@ -0,0 +1,146 @@
+// Package apierror defines the body of every HTTP error response, so clients
+// can branch on a stable code instead of matching messages.
+package apierror
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+)
+
+// Code identifies the kind of error. Codes are part of the public API and
+// must not change once released.
+type Code string
+
+// Error codes
+const (
+	CodeInvalidInput  Code = "INVALID_INPUT"
+	CodeNotFound      Code = "NOT_FOUND"
+	CodeAlreadyExists Code = "ALREADY_EXISTS"
+	// CodeAliasConflict rejects a code that is an alias of an existing bank
+	CodeAliasConflict    Code = "ALIAS_CONFLICT"
+	CodeConflict         Code = "CONFLICT"
+	CodeUnauthorized     Code = "UNAUTHORIZED"
+	CodeForbidden        Code = "FORBIDDEN"
+	CodeRouteNotFound    Code = "ROUTE_NOT_FOUND"
+	CodeMethodNotAllowed Code = "METHOD_NOT_ALLOWED"
+	CodeNotAcceptable    Code = "NOT_ACCEPTABLE"
+	CodePayloadTooLarge  Code = "PAYLOAD_TOO_LARGE"
+	CodeUnprocessable    Code = "UNPROCESSABLE"
+	// CodePreconditionFailed reports a conditional write whose If-Match or
+	// If-None-Match precondition does not hold
+	CodePreconditionFailed Code = "PRECONDITION_FAILED"
+	// CodeIdempotencyKeyReused rejects an Idempotency-Key sent again with a
+	// different request
+	CodeIdempotencyKeyReused Code = "IDEMPOTENCY_KEY_REUSED"
+	// CodeGoldenMismatch reports an import that failed the golden dataset
+	// check
+	CodeGoldenMismatch Code = "GOLDEN_MISMATCH"
+	// CodeCursorExpired rejects a watch cursor whose changes are no longer
+	// kept; the client has to resynchronize
+	CodeCursorExpired Code = "CURSOR_EXPIRED"
+	// CodeReadOnly rejects a write during a maintenance window
+	CodeReadOnly    Code = "READ_ONLY"
+	CodeUnavailable Code = "UNAVAILABLE"
+	// CodeRateLimited rejects a caller over the request quota of its tier
+	CodeRateLimited Code = "RATE_LIMITED"
+	// CodeTimeout reports a request cut off by its route timeout or a query
+	// cut off by its repository timeout
+	CodeTimeout  Code = "TIMEOUT"
+	CodeInternal Code = "INTERNAL"
+)
+
+// Detail describes one problem with the request, usually a parameter or
+// body field
+type Detail struct {
+	Field  string `json:"field,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Error is the JSON body of an error response
+type Error struct {
+	Code    Code   `json:"code"`
+	Message string `json:"message"`
+	// Details is always present, empty when there is nothing to add
+	Details   []Detail `json:"details"`
+	RequestID string   `json:"requestId,omitempty"`
+}
+
+// New builds the error body for the request in c
+func New(c fiber.Ctx, code Code, message string, details ...Detail) *Error {
+	if details == nil {
+		details = []Detail{}
+	}
+	return &Error{
+		Code:      code,
+		Message:   message,
+		Details:   details,
+		RequestID: requestid.FromContext(c.Context()),
+	}
+}
+
+// Write answers the request in c with status and an error body
+func Write(c fiber.Ctx, status int, code Code, message string, details ...Detail) error {
+	return c.Status(status).JSON(New(c, code, message, details...))
+}
+
+// Field returns a Detail naming an invalid parameter or body field
+func Field(name, reason string) Detail {
+	return Detail{Field: name, Reason: reason}
+}
+
+// Handler is the Fiber ErrorHandler. It answers unmatched routes and
+// methods with their own codes, other *fiber.Error values with the code and
+// text of their status, and everything else with a 500 that does not leak
+// the error.
+func Handler(c fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	if !errors.As(err, &fiberErr) {
+		return Write(c, fiber.StatusInternalServerError, CodeInternal, "Internal server error")
+	}
+
+	switch fiberErr.Code {
+	case fiber.StatusNotFound:
+		return Write(c, fiberErr.Code, CodeRouteNotFound, "Route not found")
+	case fiber.StatusMethodNotAllowed:
+		return Write(c, fiberErr.Code, CodeMethodNotAllowed, "Method not allowed")
+	}
+	return Write(c, fiberErr.Code, CodeForStatus(fiberErr.Code), fiberErr.Message)
+}
+
+// CodeForStatus returns the generic code of an HTTP error status
+func CodeForStatus(status int) Code {
+	switch status {
+	case fiber.StatusBadRequest:
+		return CodeInvalidInput
+	case fiber.StatusUnauthorized:
+		return CodeUnauthorized
+	case fiber.StatusForbidden:
+		return CodeForbidden
+	case fiber.StatusNotFound:
+		return CodeNotFound
+	case fiber.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case fiber.StatusNotAcceptable:
+		return CodeNotAcceptable
+	case fiber.StatusConflict:
+		return CodeConflict
+	case fiber.StatusPreconditionFailed:
+		return CodePreconditionFailed
+	case fiber.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case fiber.StatusUnprocessableEntity:
+		return CodeUnprocessable
+	case fiber.StatusTooManyRequests:
+		return CodeRateLimited
+	case fiber.StatusServiceUnavailable:
+		return CodeUnavailable
+	case fiber.StatusGatewayTimeout:
+		return CodeTimeout
+	}
+	if status < fiber.StatusInternalServerError {
+		return CodeInvalidInput
+	}
+	return CodeInternal
+}
@@ -0,0 +1,87 @@
+package apierror_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+)
+
+func TestAPIError(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "API Error Suite")
+}
+
+var _ = Describe("API errors", func() {
+	var app *fiber.App
+
+	BeforeEach(func() {
+		app = fiber.New(fiber.Config{ErrorHandler: apierror.Handler})
+		app.Use(func(c fiber.Ctx) error {
+			c.SetContext(requestid.NewContext(c.Context(), "req-1"))
+			return c.Next()
+		})
+		app.Get("/invalid", func(c fiber.Ctx) error {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid sort parameter",
+				apierror.Field("sort", "unknown sort field"))
+		})
+		app.Get("/fail", func(c fiber.Ctx) error {
+			return errors.New("connection refused by trino:8080")
+		})
+		app.Get("/unavailable", func(c fiber.Ctx) error {
+			return fiber.ErrServiceUnavailable
+		})
+	})
+
+	do := func(method, path string) (int, apierror.Error) {
+		resp, err := app.Test(httptest.NewRequest(method, path, nil), fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		var body apierror.Error
+		Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		return resp.StatusCode, body
+	}
+
+	It("should write the code, message, details and request ID", func() {
+		status, body := do(http.MethodGet, "/invalid")
+		Expect(status).To(Equal(http.StatusBadRequest))
+		Expect(body).To(Equal(apierror.Error{
+			Code:      apierror.CodeInvalidInput,
+			Message:   "Invalid sort parameter",
+			Details:   []apierror.Detail{{Field: "sort", Reason: "unknown sort field"}},
+			RequestID: "req-1",
+		}))
+	})
+
+	It("should hide the cause of unexpected errors", func() {
+		status, body := do(http.MethodGet, "/fail")
+		Expect(status).To(Equal(http.StatusInternalServerError))
+		Expect(body.Code).To(Equal(apierror.CodeInternal))
+		Expect(body.Message).To(Equal("Internal server error"))
+		Expect(body.Details).To(BeEmpty())
+	})
+
+	It("should map Fiber errors by status", func() {
+		status, body := do(http.MethodGet, "/unavailable")
+		Expect(status).To(Equal(http.StatusServiceUnavailable))
+		Expect(body.Code).To(Equal(apierror.CodeUnavailable))
+	})
+
+	It("should answer unknown routes and methods", func() {
+		status, body := do(http.MethodGet, "/nowhere")
+		Expect(status).To(Equal(http.StatusNotFound))
+		Expect(body.Code).To(Equal(apierror.CodeRouteNotFound))
+		Expect(body.RequestID).To(Equal("req-1"))
+
+		status, body = do(http.MethodPost, "/invalid")
+		Expect(status).To(Equal(http.StatusMethodNotAllowed))
+		Expect(body.Code).To(Equal(apierror.CodeMethodNotAllowed))
+	})
+})
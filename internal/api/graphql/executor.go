@@ -0,0 +1,359 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+// Error is a GraphQL error entry
+type Error struct {
+	Message string   `json:"message"`
+	Path    []string `json:"path,omitempty"`
+}
+
+// Response is the GraphQL response envelope
+type Response struct {
+	Data   *object `json:"data"`
+	Errors []Error `json:"errors,omitempty"`
+}
+
+// queryError reports a problem with the query itself rather than the data
+type queryError struct {
+	message string
+}
+
+func (e *queryError) Error() string {
+	return e.message
+}
+
+func queryErrorf(format string, args ...any) error {
+	return &queryError{message: fmt.Sprintf(format, args...)}
+}
+
+// object is a JSON object that keeps its keys in selection order
+type object struct {
+	keys   []string
+	values []any
+}
+
+func (o *object) set(key string, value any) {
+	o.keys = append(o.keys, key)
+	o.values = append(o.values, value)
+}
+
+// MarshalJSON encodes the object preserving key order
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		v, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Executor resolves GraphQL operations through the Swift service so that
+// GraphQL and REST share the same validation
+type Executor struct {
+	service service.SwiftService
+}
+
+// NewExecutor creates a new executor backed by the given service
+func NewExecutor(service service.SwiftService) *Executor {
+	return &Executor{service: service}
+}
+
+// Execute runs the operation and collects per-field errors
+func (e *Executor) Execute(ctx context.Context, op *Operation, variables map[string]any) Response {
+	data := &object{}
+	var errs []Error
+
+	for _, field := range op.Selections {
+		args, err := resolveArguments(field.Arguments, variables)
+		var value any
+		if err == nil {
+			if op.Type == "mutation" {
+				value, err = e.resolveMutation(ctx, field, args)
+			} else {
+				value, err = e.resolveQuery(ctx, field, args)
+			}
+		}
+		if err != nil {
+			errs = append(errs, Error{Message: errorMessage(err), Path: []string{field.ResponseKey()}})
+			value = nil
+		}
+		data.set(field.ResponseKey(), value)
+	}
+
+	return Response{Data: data, Errors: errs}
+}
+
+// lookup reads the details of code and reports the lookup to the scrape
+// guard watching the request, as every queried code counts as one
+func (e *Executor) lookup(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+	detail, err := e.service.GetSwiftCodeDetails(ctx, code)
+	middleware.RecordLookup(ctx, code, !errors.Is(err, service.ErrNotFound))
+	return detail, err
+}
+
+func (e *Executor) resolveQuery(ctx context.Context, field Field, args map[string]any) (any, error) {
+	switch field.Name {
+	case "__typename":
+		return "Query", nil
+	case "swiftCode":
+		detail, err := e.lookup(ctx, stringArg(args, "code"))
+		if err != nil {
+			return nil, err
+		}
+		return projectBank(detail.Bank, detail.Branches, field)
+	case "branches":
+		detail, err := e.lookup(ctx, stringArg(args, "code"))
+		if err != nil {
+			return nil, err
+		}
+		return projectBanks(detail.Branches, field)
+	case "country":
+		opts, err := listOptions(args)
+		if err != nil {
+			return nil, err
+		}
+		codes, err := e.service.GetSwiftCodesByCountry(ctx, stringArg(args, "iso2"), opts)
+		if err != nil {
+			return nil, err
+		}
+		return projectCountry(codes.CountryISO2, codes.CountryName, codes.SwiftCodes, field)
+	case "search":
+		opts, err := listOptions(args)
+		if err != nil {
+			return nil, err
+		}
+		codes, err := e.service.GetSwiftCodesByCountry(ctx, stringArg(args, "countryISO2"), opts)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.ToUpper(stringArg(args, "bankName"))
+		var matches []models.SwiftBank
+		for _, bank := range codes.SwiftCodes {
+			if strings.Contains(strings.ToUpper(bank.BankName), name) {
+				matches = append(matches, bank)
+			}
+		}
+		return projectBanks(matches, field)
+	default:
+		return nil, queryErrorf("cannot query field %q on type Query", field.Name)
+	}
+}
+
+// listOptions reads the optional sort, type, address and town arguments,
+// e.g. sort: "bankName:desc", type: "headquarter", town: "warsaw"
+func listOptions(args map[string]any) (repository.QueryOptions, error) {
+	sort, err := repository.ParseSort(stringArg(args, "sort"))
+	if err != nil {
+		return repository.QueryOptions{}, queryErrorf("invalid sort %q", stringArg(args, "sort"))
+	}
+	bankType, err := repository.ParseBankType(stringArg(args, "type"))
+	if err != nil {
+		return repository.QueryOptions{}, queryErrorf("invalid type %q", stringArg(args, "type"))
+	}
+	return repository.QueryOptions{
+		Sort:    sort,
+		Type:    bankType,
+		Address: strings.TrimSpace(stringArg(args, "address")),
+		Town:    strings.TrimSpace(stringArg(args, "town")),
+	}, nil
+}
+
+func (e *Executor) resolveMutation(ctx context.Context, field Field, args map[string]any) (any, error) {
+	switch field.Name {
+	case "__typename":
+		return "Mutation", nil
+	case "createSwiftCode":
+		input, _ := args["input"].(map[string]any)
+		bank := &models.SwiftBank{
+			SwiftCode:      stringArg(input, "swiftCode"),
+			BankName:       stringArg(input, "bankName"),
+			Address:        stringArg(input, "address"),
+			CountryISOCode: stringArg(input, "countryISO2"),
+			CountryName:    stringArg(input, "countryName"),
+		}
+		if err := e.service.CreateSwiftCode(ctx, bank); err != nil {
+			return nil, err
+		}
+		return projectBank(*bank, nil, field)
+	case "deleteSwiftCode":
+		if err := e.service.DeleteSwiftCode(ctx, stringArg(args, "code")); err != nil {
+			return nil, err
+		}
+		return true, nil
+	default:
+		return nil, queryErrorf("cannot query field %q on type Mutation", field.Name)
+	}
+}
+
+func projectCountry(iso2, name string, banks []models.SwiftBank, field Field) (any, error) {
+	if len(field.Selections) == 0 {
+		return nil, queryErrorf("field %q of type Country must have a selection of subfields", field.Name)
+	}
+
+	obj := &object{}
+	for _, sel := range field.Selections {
+		switch sel.Name {
+		case "__typename":
+			obj.set(sel.ResponseKey(), "Country")
+		case "countryISO2":
+			obj.set(sel.ResponseKey(), iso2)
+		case "countryName":
+			obj.set(sel.ResponseKey(), name)
+		case "swiftCodes":
+			list, err := projectBanks(banks, sel)
+			if err != nil {
+				return nil, err
+			}
+			obj.set(sel.ResponseKey(), list)
+		default:
+			return nil, queryErrorf("cannot query field %q on type Country", sel.Name)
+		}
+	}
+	return obj, nil
+}
+
+func projectBanks(banks []models.SwiftBank, field Field) (any, error) {
+	list := make([]any, 0, len(banks))
+	for _, bank := range banks {
+		obj, err := projectBank(bank, nil, field)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, obj)
+	}
+	return list, nil
+}
+
+func projectBank(bank models.SwiftBank, branches []models.SwiftBank, field Field) (any, error) {
+	if len(field.Selections) == 0 {
+		return nil, queryErrorf("field %q of type SwiftCode must have a selection of subfields", field.Name)
+	}
+
+	obj := &object{}
+	for _, sel := range field.Selections {
+		switch sel.Name {
+		case "__typename":
+			obj.set(sel.ResponseKey(), "SwiftCode")
+		case "swiftCode":
+			obj.set(sel.ResponseKey(), bank.SwiftCode)
+		case "swiftCodeBase":
+			obj.set(sel.ResponseKey(), bank.SwiftCodeBase)
+		case "bankName":
+			obj.set(sel.ResponseKey(), bank.BankName)
+		case "address":
+			obj.set(sel.ResponseKey(), bank.Address)
+		case "countryISO2":
+			obj.set(sel.ResponseKey(), bank.CountryISOCode)
+		case "countryName":
+			obj.set(sel.ResponseKey(), bank.CountryName)
+		case "isHeadquarter":
+			obj.set(sel.ResponseKey(), bank.IsHeadquarter)
+		case "branches":
+			list, err := projectBanks(branches, sel)
+			if err != nil {
+				return nil, err
+			}
+			obj.set(sel.ResponseKey(), list)
+		default:
+			return nil, queryErrorf("cannot query field %q on type SwiftCode", sel.Name)
+		}
+	}
+	return obj, nil
+}
+
+func resolveArguments(args map[string]any, variables map[string]any) (map[string]any, error) {
+	resolved := make(map[string]any, len(args))
+	for name, value := range args {
+		v, err := resolveValue(value, variables)
+		if err != nil {
+			return nil, err
+		}
+		resolved[name] = v
+	}
+	return resolved, nil
+}
+
+func resolveValue(value any, variables map[string]any) (any, error) {
+	switch v := value.(type) {
+	case variable:
+		resolved, ok := variables[string(v)]
+		if !ok {
+			return nil, queryErrorf("variable $%s is not defined", v)
+		}
+		return resolved, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			r, err := resolveValue(item, variables)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = r
+		}
+		return out, nil
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			r, err := resolveValue(item, variables)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = r
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+func stringArg(args map[string]any, name string) string {
+	s, _ := args[name].(string)
+	return s
+}
+
+// errorMessage maps service errors to the same messages as the REST API
+func errorMessage(err error) string {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return "SWIFT code not found"
+	case errors.Is(err, service.ErrInvalidInput):
+		return "Invalid input provided"
+	case errors.Is(err, service.ErrAlreadyExists):
+		return "SWIFT code already exists"
+	case errors.Is(err, service.ErrReadOnly):
+		return "Service is read-only for maintenance"
+	case errors.Is(err, repository.ErrQueryTimeout):
+		return "Query timed out"
+	case errors.As(err, new(*queryError)):
+		return err.Error()
+	default:
+		return "Internal server error"
+	}
+}
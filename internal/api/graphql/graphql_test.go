@@ -0,0 +1,202 @@
+package graphql_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/graphql"
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+func TestGraphQL(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GraphQL Suite")
+}
+
+var _ = Describe("GraphQL", func() {
+	var (
+		app     *fiber.App
+		mockSvc *mocks.MockSwiftService
+		auth    middleware.AuthConfig
+		allow   *middleware.IPAllowlist
+	)
+
+	BeforeEach(func() {
+		mockSvc = &mocks.MockSwiftService{
+			GetSwiftCodeDetailsFunc: func(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+				if code != "ABCDUS33XXX" {
+					return nil, service.ErrNotFound
+				}
+				return &repository.SwiftBankDetail{
+					Bank: models.SwiftBank{SwiftCode: "ABCDUS33XXX", BankName: "Test Bank", IsHeadquarter: true},
+					Branches: []models.SwiftBank{
+						{SwiftCode: "ABCDUS33001", BankName: "Test Bank Branch"},
+					},
+				}, nil
+			},
+			GetSwiftCodesByCountryFunc: func(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+				return &repository.CountrySwiftCodes{
+					CountryISO2: "US",
+					CountryName: "UNITED STATES",
+					SwiftCodes: []models.SwiftBank{
+						{SwiftCode: "ABCDUS33XXX", BankName: "Test Bank"},
+						{SwiftCode: "EFGHUS33XXX", BankName: "Other Bank"},
+					},
+				}, nil
+			},
+		}
+		auth = middleware.AuthConfig{}
+		allow = nil
+	})
+
+	JustBeforeEach(func() {
+		app = fiber.New()
+		h := graphql.NewHandler(mockSvc, auth, allow)
+		app.Get("/graphql", h.Serve)
+		app.Post("/graphql", h.Serve)
+	})
+
+	post := func(query string, variables map[string]any) (int, map[string]any) {
+		body, err := json.Marshal(graphql.Request{Query: query, Variables: variables})
+		Expect(err).NotTo(HaveOccurred())
+		req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		Expect(err).NotTo(HaveOccurred())
+
+		var result map[string]any
+		Expect(json.NewDecoder(resp.Body).Decode(&result)).To(Succeed())
+		return resp.StatusCode, result
+	}
+
+	It("should resolve a swift code with its branches", func() {
+		status, result := post(`query Lookup($code: String!) {
+			hq: swiftCode(code: $code) { swiftCode bankName branches { swiftCode } }
+		}`, map[string]any{"code": "ABCDUS33XXX"})
+
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(result).NotTo(HaveKey("errors"))
+		hq := result["data"].(map[string]any)["hq"].(map[string]any)
+		Expect(hq["swiftCode"]).To(Equal("ABCDUS33XXX"))
+		Expect(hq["branches"]).To(HaveLen(1))
+	})
+
+	It("should search a country by bank name", func() {
+		_, result := post(`{ search(countryISO2: "US", bankName: "other") { swiftCode } }`, nil)
+
+		matches := result["data"].(map[string]any)["search"].([]any)
+		Expect(matches).To(HaveLen(1))
+		Expect(matches[0].(map[string]any)["swiftCode"]).To(Equal("EFGHUS33XXX"))
+	})
+
+	It("should pass address and town search terms to the service", func() {
+		var got repository.QueryOptions
+		listing := mockSvc.GetSwiftCodesByCountryFunc
+		mockSvc.GetSwiftCodesByCountryFunc = func(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+			got = opts
+			return listing(ctx, countryCode, opts)
+		}
+
+		_, result := post(`{ search(countryISO2: "US", address: "main st", town: " Springfield ") { swiftCode } }`, nil)
+
+		Expect(result).NotTo(HaveKey("errors"))
+		Expect(got.Address).To(Equal("main st"))
+		Expect(got.Town).To(Equal("Springfield"))
+	})
+
+	It("should report service errors per field", func() {
+		_, result := post(`{ swiftCode(code: "ZZZZUS33XXX") { swiftCode } country(iso2: "US") { countryName } }`, nil)
+
+		data := result["data"].(map[string]any)
+		Expect(data["swiftCode"]).To(BeNil())
+		Expect(data["country"].(map[string]any)["countryName"]).To(Equal("UNITED STATES"))
+		Expect(result["errors"]).To(ConsistOf(HaveKeyWithValue("message", "SWIFT code not found")))
+	})
+
+	It("should reject unknown fields", func() {
+		_, result := post(`{ swiftCode(code: "ABCDUS33XXX") { iban } }`, nil)
+		Expect(result["errors"]).To(ConsistOf(HaveKeyWithValue("message", ContainSubstring(`"iban"`))))
+	})
+
+	It("should create swift codes through the service", func() {
+		var created *models.SwiftBank
+		mockSvc.CreateSwiftCodeFunc = func(ctx context.Context, bank *models.SwiftBank) error {
+			created = bank
+			return nil
+		}
+
+		_, result := post(`mutation { createSwiftCode(input: {swiftCode: "ABCDUS33XXX", bankName: "Test Bank", countryISO2: "US"}) { swiftCode } }`, nil)
+
+		Expect(result).NotTo(HaveKey("errors"))
+		Expect(created.BankName).To(Equal("Test Bank"))
+		Expect(created.CountryISOCode).To(Equal("US"))
+	})
+
+	It("should not allow mutations over GET", func() {
+		req := httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(`mutation { deleteSwiftCode(code: "ABCDUS33XXX") }`), nil)
+		resp, err := app.Test(req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusMethodNotAllowed))
+	})
+
+	It("should return 400 for malformed queries", func() {
+		status, _ := post(`{ swiftCode(code: "ABC" { swiftCode } }`, nil)
+		Expect(status).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should return 400 for deeply nested queries", func() {
+		depth := 100000
+		query := `{ search(countryISO2: ` + strings.Repeat("[", depth) + strings.Repeat("]", depth) + `) { swiftCode } }`
+		status, result := post(query, nil)
+		Expect(status).To(Equal(http.StatusBadRequest))
+		Expect(result["errors"]).To(ConsistOf(HaveKeyWithValue("message", ContainSubstring("nested deeper"))))
+
+		status, _ = post(strings.Repeat("{ swiftCode ", depth)+strings.Repeat("}", depth), nil)
+		Expect(status).To(Equal(http.StatusBadRequest))
+	})
+
+	Context("when auth is enabled", func() {
+		BeforeEach(func() {
+			auth = middleware.AuthConfig{Enabled: true, SigningKey: "secret"}
+		})
+
+		It("should require a token for mutations", func() {
+			status, _ := post(`mutation { deleteSwiftCode(code: "ABCDUS33XXX") }`, nil)
+			Expect(status).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("should keep queries public", func() {
+			status, _ := post(`{ swiftCode(code: "ABCDUS33XXX") { swiftCode } }`, nil)
+			Expect(status).To(Equal(http.StatusOK))
+		})
+	})
+	Context("when the IP allowlist excludes the client", func() {
+		BeforeEach(func() {
+			var err error
+			allow, err = middleware.NewIPAllowlist(middleware.IPAllowlistConfig{Enabled: true, CIDRs: []string{"10.0.0.0/8"}})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should refuse mutations but keep queries", func() {
+			status, _ := post(`mutation { deleteSwiftCode(code: "ABCDUS33XXX") }`, nil)
+			Expect(status).To(Equal(http.StatusForbidden))
+
+			status, _ = post(`{ swiftCode(code: "ABCDUS33XXX") { swiftCode } }`, nil)
+			Expect(status).To(Equal(http.StatusOK))
+		})
+	})
+})
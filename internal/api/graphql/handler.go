@@ -0,0 +1,76 @@
+package graphql
+
+import (
+	"encoding/json"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+// Request is a GraphQL-over-HTTP request body
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Handler serves the /graphql endpoint
+type Handler struct {
+	executor *Executor
+	auth     middleware.AuthConfig
+	allow    *middleware.IPAllowlist
+}
+
+// NewHandler creates a new GraphQL handler. Mutations require the same
+// writer/admin role and client networks as the REST write endpoints.
+func NewHandler(service service.SwiftService, auth middleware.AuthConfig, allow *middleware.IPAllowlist) *Handler {
+	return &Handler{executor: NewExecutor(service), auth: auth, allow: allow}
+}
+
+// Serve handles GET (queries only) and POST GraphQL requests
+func (h *Handler) Serve(c fiber.Ctx) error {
+	var req Request
+	if c.Method() == fiber.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if vars := c.Query("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				return badRequest(c, "Invalid variables")
+			}
+		}
+	} else if err := c.Bind().Body(&req); err != nil {
+		return badRequest(c, "Invalid request body")
+	}
+
+	if req.Query == "" {
+		return badRequest(c, "Query cannot be empty")
+	}
+
+	op, err := Parse(req.Query, req.OperationName)
+	if err != nil {
+		return badRequest(c, err.Error())
+	}
+
+	if op.Type == "mutation" {
+		if c.Method() == fiber.MethodGet {
+			return c.Status(fiber.StatusMethodNotAllowed).JSON(Response{
+				Errors: []Error{{Message: "Mutations must be sent with POST"}},
+			})
+		}
+		if err := middleware.AllowIP(h.allow, c); err != nil {
+			return middleware.RespondIPError(c)
+		}
+		if err := middleware.Authorize(h.auth, c, middleware.RoleWriter, middleware.RoleAdmin); err != nil {
+			return middleware.RespondAuthError(c, err)
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(h.executor.Execute(c.Context(), op, req.Variables))
+}
+
+func badRequest(c fiber.Ctx, message string) error {
+	return c.Status(fiber.StatusBadRequest).JSON(Response{
+		Errors: []Error{{Message: message}},
+	})
+}
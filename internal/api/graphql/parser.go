@@ -0,0 +1,420 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Operation is a parsed GraphQL operation
+type Operation struct {
+	Type       string // "query" or "mutation"
+	Name       string
+	Selections []Field
+}
+
+// Field is a single selection with its arguments and sub-selections
+type Field struct {
+	Alias      string
+	Name       string
+	Arguments  map[string]any
+	Selections []Field
+}
+
+// ResponseKey returns the alias if set, otherwise the field name
+func (f Field) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// variable marks an argument value to be substituted at execution time
+type variable string
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenName
+	tokenString
+	tokenNumber
+	tokenPunct
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+// Parse parses a GraphQL document and returns the operation named
+// operationName, or the only operation when operationName is empty.
+// Fragments and directives are not supported.
+func Parse(document, operationName string) (*Operation, error) {
+	tokens, err := lex(document)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	var operations []*Operation
+	for p.peek().kind != tokenEOF {
+		op, err := p.parseOperation()
+		if err != nil {
+			return nil, err
+		}
+		operations = append(operations, op)
+	}
+
+	if len(operations) == 0 {
+		return nil, fmt.Errorf("document contains no operations")
+	}
+	if operationName == "" {
+		if len(operations) > 1 {
+			return nil, fmt.Errorf("operationName is required when the document contains multiple operations")
+		}
+		return operations[0], nil
+	}
+	for _, op := range operations {
+		if op.Name == operationName {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation %q", operationName)
+}
+
+// maxDepth bounds how deeply selection sets and list/object values may
+// nest. The parser is recursive, so an unbounded document could exhaust the
+// goroutine stack, which no recover can catch.
+const maxDepth = 32
+
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+}
+
+// descend records one more level of nesting and fails once maxDepth is
+// exceeded. Callers pair it with a deferred ascend.
+func (p *parser) descend() error {
+	p.depth++
+	if p.depth > maxDepth {
+		return fmt.Errorf("document is nested deeper than %d levels", maxDepth)
+	}
+	return nil
+}
+
+func (p *parser) ascend() {
+	p.depth--
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expectPunct(value string) error {
+	t := p.next()
+	if t.kind != tokenPunct || t.value != value {
+		return fmt.Errorf("expected %q, got %q", value, t.value)
+	}
+	return nil
+}
+
+func (p *parser) isPunct(value string) bool {
+	t := p.peek()
+	return t.kind == tokenPunct && t.value == value
+}
+
+func (p *parser) parseOperation() (*Operation, error) {
+	op := &Operation{Type: "query"}
+
+	if t := p.peek(); t.kind == tokenName {
+		switch t.value {
+		case "query", "mutation":
+			op.Type = t.value
+		case "fragment", "subscription":
+			return nil, fmt.Errorf("%s definitions are not supported", t.value)
+		default:
+			return nil, fmt.Errorf("unexpected %q", t.value)
+		}
+		p.next()
+		if p.peek().kind == tokenName {
+			op.Name = p.next().value
+		}
+		if p.isPunct("(") {
+			if err := p.skipVariableDefinitions(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	selections, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.Selections = selections
+	return op, nil
+}
+
+// skipVariableDefinitions consumes "($name: Type = default, ...)". Types are
+// not enforced; resolvers validate their own arguments.
+func (p *parser) skipVariableDefinitions() error {
+	p.next()
+	for !p.isPunct(")") {
+		if p.peek().kind == tokenEOF {
+			return fmt.Errorf("unterminated variable definitions")
+		}
+		p.next()
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) parseSelectionSet() ([]Field, error) {
+	if err := p.descend(); err != nil {
+		return nil, err
+	}
+	defer p.ascend()
+
+	if err := p.expectPunct("{"); err != nil {
+		return nil, err
+	}
+
+	var fields []Field
+	for !p.isPunct("}") {
+		if p.isPunct("...") {
+			return nil, fmt.Errorf("fragments are not supported")
+		}
+		field, err := p.parseField()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	p.next()
+
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("selection set cannot be empty")
+	}
+	return fields, nil
+}
+
+func (p *parser) parseField() (Field, error) {
+	t := p.next()
+	if t.kind != tokenName {
+		return Field{}, fmt.Errorf("expected field name, got %q", t.value)
+	}
+
+	field := Field{Name: t.value}
+	if p.isPunct(":") {
+		p.next()
+		name := p.next()
+		if name.kind != tokenName {
+			return Field{}, fmt.Errorf("expected field name after alias %q", t.value)
+		}
+		field.Alias = t.value
+		field.Name = name.value
+	}
+
+	if p.isPunct("(") {
+		p.next()
+		field.Arguments = map[string]any{}
+		for !p.isPunct(")") {
+			name := p.next()
+			if name.kind != tokenName {
+				return Field{}, fmt.Errorf("expected argument name, got %q", name.value)
+			}
+			if err := p.expectPunct(":"); err != nil {
+				return Field{}, err
+			}
+			value, err := p.parseValue()
+			if err != nil {
+				return Field{}, err
+			}
+			field.Arguments[name.value] = value
+		}
+		p.next()
+	}
+
+	if p.isPunct("@") {
+		return Field{}, fmt.Errorf("directives are not supported")
+	}
+
+	if p.isPunct("{") {
+		selections, err := p.parseSelectionSet()
+		if err != nil {
+			return Field{}, err
+		}
+		field.Selections = selections
+	}
+
+	return field, nil
+}
+
+func (p *parser) parseValue() (any, error) {
+	if err := p.descend(); err != nil {
+		return nil, err
+	}
+	defer p.ascend()
+
+	t := p.next()
+	switch t.kind {
+	case tokenString:
+		return t.value, nil
+	case tokenNumber:
+		if i, err := strconv.ParseInt(t.value, 10, 64); err == nil {
+			return int(i), nil
+		}
+		return strconv.ParseFloat(t.value, 64)
+	case tokenName:
+		switch t.value {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		default:
+			// Enum values are passed through as strings
+			return t.value, nil
+		}
+	case tokenPunct:
+		switch t.value {
+		case "$":
+			name := p.next()
+			if name.kind != tokenName {
+				return nil, fmt.Errorf("expected variable name")
+			}
+			return variable(name.value), nil
+		case "[":
+			var list []any
+			for !p.isPunct("]") {
+				if p.peek().kind == tokenEOF {
+					return nil, fmt.Errorf("unterminated list")
+				}
+				v, err := p.parseValue()
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, v)
+			}
+			p.next()
+			return list, nil
+		case "{":
+			obj := map[string]any{}
+			for !p.isPunct("}") {
+				name := p.next()
+				if name.kind != tokenName {
+					return nil, fmt.Errorf("expected object field name, got %q", name.value)
+				}
+				if err := p.expectPunct(":"); err != nil {
+					return nil, err
+				}
+				v, err := p.parseValue()
+				if err != nil {
+					return nil, err
+				}
+				obj[name.value] = v
+			}
+			p.next()
+			return obj, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected %q in value", t.value)
+}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		ch := src[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == ',':
+			i++
+		case ch == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case ch == '.':
+			if !strings.HasPrefix(src[i:], "...") {
+				return nil, fmt.Errorf("unexpected '.' at offset %d", i)
+			}
+			tokens = append(tokens, token{tokenPunct, "..."})
+			i += 3
+		case strings.IndexByte("{}()[]:$!=@", ch) >= 0:
+			tokens = append(tokens, token{tokenPunct, string(ch)})
+			i++
+		case ch == '"':
+			value, n, err := lexString(src[i:])
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{tokenString, value})
+			i += n
+		case ch == '-' || (ch >= '0' && ch <= '9'):
+			start := i
+			i++
+			for i < len(src) && strings.IndexByte("0123456789.eE+-", src[i]) >= 0 {
+				i++
+			}
+			tokens = append(tokens, token{tokenNumber, src[start:i]})
+		case ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z'):
+			start := i
+			for i < len(src) && (src[i] == '_' || (src[i] >= 'a' && src[i] <= 'z') || (src[i] >= 'A' && src[i] <= 'Z') || (src[i] >= '0' && src[i] <= '9')) {
+				i++
+			}
+			tokens = append(tokens, token{tokenName, src[start:i]})
+		default:
+			return nil, fmt.Errorf("unexpected character %q at offset %d", ch, i)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF}), nil
+}
+
+// lexString reads a double-quoted string and returns its value and length
+func lexString(src string) (string, int, error) {
+	var sb strings.Builder
+	for i := 1; i < len(src); i++ {
+		switch src[i] {
+		case '"':
+			return sb.String(), i + 1, nil
+		case '\n':
+			return "", 0, fmt.Errorf("unterminated string")
+		case '\\':
+			if i+1 >= len(src) {
+				return "", 0, fmt.Errorf("unterminated string")
+			}
+			i++
+			switch src[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			case 'u':
+				if i+4 >= len(src) {
+					return "", 0, fmt.Errorf("invalid unicode escape")
+				}
+				r, err := strconv.ParseUint(src[i+1:i+5], 16, 32)
+				if err != nil {
+					return "", 0, fmt.Errorf("invalid unicode escape")
+				}
+				sb.WriteRune(rune(r))
+				i += 4
+			default:
+				sb.WriteByte(src[i])
+			}
+		default:
+			sb.WriteByte(src[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
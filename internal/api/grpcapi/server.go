@@ -0,0 +1,223 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+//go:generate protoc --proto_path=../../.. --go_out=../../.. --go_opt=module=github.com/zdziszkee/swift-codes --go-grpc_out=../../.. --go-grpc_opt=module=github.com/zdziszkee/swift-codes proto/swiftcodes/v1/swiftcodes.proto
+
+// Config holds configuration for the gRPC server
+type Config struct {
+	Enabled bool   `koanf:"enabled"`
+	Address string `koanf:"address"`
+}
+
+// Server implements the SwiftCodes gRPC service on top of SwiftService
+type Server struct {
+	UnimplementedSwiftCodesServer
+	service service.SwiftService
+}
+
+// NewServer creates a gRPC server exposing the SwiftCodes service.
+// Write methods require a writer or admin token when auth is enabled.
+func NewServer(svc service.SwiftService, auth middleware.AuthConfig, allow *middleware.IPAllowlist) *grpc.Server {
+	srv := grpc.NewServer(grpc.UnaryInterceptor(authInterceptor(auth, allow)))
+	RegisterSwiftCodesServer(srv, &Server{service: svc})
+	return srv
+}
+
+// Serve listens on the configured address until the server is stopped
+func Serve(srv *grpc.Server, cfg Config) error {
+	lis, err := net.Listen("tcp", cfg.Address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", cfg.Address, err)
+	}
+	return srv.Serve(lis)
+}
+
+// GetByCode returns a SWIFT code and its branches
+func (s *Server) GetByCode(ctx context.Context, req *GetByCodeRequest) (*GetByCodeResponse, error) {
+	detail, err := s.service.GetSwiftCodeDetails(ctx, req.SwiftCode)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return NewGetByCodeResponse(detail), nil
+}
+
+// GetByCountry returns all SWIFT codes of a country
+func (s *Server) GetByCountry(ctx context.Context, req *GetByCountryRequest) (*GetByCountryResponse, error) {
+	codes, err := s.service.GetSwiftCodesByCountry(ctx, req.CountryIso2, repository.QueryOptions{})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return NewGetByCountryResponse(codes), nil
+}
+
+// NewGetByCodeResponse converts a repository detail into its wire message
+func NewGetByCodeResponse(detail *repository.SwiftBankDetail) *GetByCodeResponse {
+	resp := &GetByCodeResponse{Bank: fromModel(detail.Bank)}
+	for _, branch := range detail.Branches {
+		resp.Branches = append(resp.Branches, fromModel(branch))
+	}
+	return resp
+}
+
+// NewGetByCountryResponse converts a country listing into its wire message
+func NewGetByCountryResponse(codes *repository.CountrySwiftCodes) *GetByCountryResponse {
+	resp := &GetByCountryResponse{CountryIso2: codes.CountryISO2, CountryName: codes.CountryName}
+	for _, bank := range codes.SwiftCodes {
+		resp.SwiftCodes = append(resp.SwiftCodes, fromModel(bank))
+	}
+	return resp
+}
+
+// Create adds a new SWIFT code
+func (s *Server) Create(ctx context.Context, req *CreateRequest) (*CreateResponse, error) {
+	if req.Bank == nil {
+		return nil, toStatus(service.ErrInvalidInput)
+	}
+	if err := s.service.CreateSwiftCode(ctx, toModel(req.Bank)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &CreateResponse{Message: "SWIFT code created successfully"}, nil
+}
+
+// Delete removes a SWIFT code
+func (s *Server) Delete(ctx context.Context, req *DeleteRequest) (*DeleteResponse, error) {
+	if err := s.service.DeleteSwiftCode(ctx, req.SwiftCode); err != nil {
+		return nil, toStatus(err)
+	}
+	return &DeleteResponse{Message: "SWIFT code deleted successfully"}, nil
+}
+
+// BatchCreate adds several SWIFT codes, reporting per-code failures
+func (s *Server) BatchCreate(ctx context.Context, req *BatchCreateRequest) (*BatchCreateResponse, error) {
+	resp := &BatchCreateResponse{}
+	for _, bank := range req.Banks {
+		if err := s.service.CreateSwiftCode(ctx, toModel(bank)); err != nil {
+			resp.Errors = append(resp.Errors, &BatchCreateError{
+				SwiftCode: strings.ToUpper(bank.SwiftCode),
+				Message:   status.Convert(toStatus(err)).Message(),
+			})
+			continue
+		}
+		resp.Created++
+	}
+	return resp, nil
+}
+
+func fromModel(bank models.SwiftBank) *SwiftCode {
+	return &SwiftCode{
+		SwiftCode:     bank.SwiftCode,
+		SwiftCodeBase: bank.SwiftCodeBase,
+		CountryIso2:   bank.CountryISOCode,
+		BankName:      bank.BankName,
+		IsHeadquarter: bank.IsHeadquarter,
+		Address:       bank.Address,
+		CountryName:   bank.CountryName,
+		TownName:      bank.Town,
+		TimeZone:      bank.TimeZone,
+		Website:       bank.Website,
+		Phone:         bank.Phone,
+	}
+}
+
+func toModel(code *SwiftCode) *models.SwiftBank {
+	return &models.SwiftBank{
+		SwiftCode:      code.SwiftCode,
+		CountryISOCode: code.CountryIso2,
+		BankName:       code.BankName,
+		Address:        code.Address,
+		CountryName:    code.CountryName,
+		Town:           code.TownName,
+		TimeZone:       code.TimeZone,
+		Website:        code.Website,
+		Phone:          code.Phone,
+	}
+}
+
+// toStatus maps service errors onto gRPC status codes
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return status.Error(codes.NotFound, "SWIFT code not found")
+	case errors.Is(err, service.ErrInvalidInput):
+		return status.Error(codes.InvalidArgument, "Invalid input provided")
+	case errors.Is(err, service.ErrAlreadyExists):
+		return status.Error(codes.AlreadyExists, "SWIFT code already exists")
+	case errors.Is(err, service.ErrReadOnly):
+		return status.Error(codes.Unavailable, "Service is read-only for maintenance")
+	case errors.Is(err, repository.ErrQueryTimeout):
+		return status.Error(codes.DeadlineExceeded, "Query timed out")
+	default:
+		return status.Error(codes.Internal, "Internal server error")
+	}
+}
+
+var writeMethods = map[string]bool{
+	SwiftCodes_Create_FullMethodName:      true,
+	SwiftCodes_Delete_FullMethodName:      true,
+	SwiftCodes_BatchCreate_FullMethodName: true,
+}
+
+// authInterceptor enforces the IP allowlist and the writer/admin role on
+// write methods
+func authInterceptor(auth middleware.AuthConfig, allow *middleware.IPAllowlist) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !writeMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		if allow != nil && !allow.Allows(peerIP(ctx)) {
+			return nil, status.Error(codes.PermissionDenied, "Client address not allowed")
+		}
+		if !auth.Enabled {
+			return handler(ctx, req)
+		}
+
+		var token string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get("authorization"); len(values) > 0 {
+				token = strings.TrimSpace(strings.TrimPrefix(values[0], "Bearer "))
+			}
+		}
+
+		claims, err := middleware.ParseToken(auth, token, time.Now())
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "Unauthorized")
+		}
+		if !claims.HasAnyRole(middleware.RoleWriter, middleware.RoleAdmin) {
+			return nil, status.Error(codes.PermissionDenied, "Forbidden")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// peerIP returns the address of the client of ctx, or "" when unknown
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
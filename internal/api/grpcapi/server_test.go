@@ -0,0 +1,203 @@
+package grpcapi_test
+
+import (
+	"context"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/zdziszkee/swift-codes/internal/api/grpcapi"
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+func TestGRPC(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "gRPC Suite")
+}
+
+var _ = Describe("gRPC Server", func() {
+	var (
+		ctx     context.Context
+		mockSvc *mocks.MockSwiftService
+		auth    middleware.AuthConfig
+		allow   *middleware.IPAllowlist
+		server  *grpc.Server
+		conn    *grpc.ClientConn
+		client  grpcapi.SwiftCodesClient
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		mockSvc = &mocks.MockSwiftService{}
+		auth = middleware.AuthConfig{}
+		allow = nil
+	})
+
+	JustBeforeEach(func() {
+		lis := bufconn.Listen(1024 * 1024)
+		server = grpcapi.NewServer(mockSvc, auth, allow)
+		go func() {
+			_ = server.Serve(lis)
+		}()
+
+		var err error
+		conn, err = grpc.NewClient("passthrough:///bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		client = grpcapi.NewSwiftCodesClient(conn)
+	})
+
+	AfterEach(func() {
+		conn.Close()
+		server.Stop()
+	})
+
+	It("should return a swift code with its branches", func() {
+		mockSvc.GetSwiftCodeDetailsFunc = func(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+			return &repository.SwiftBankDetail{
+				Bank:     models.SwiftBank{SwiftCode: code, BankName: "Test Bank", IsHeadquarter: true},
+				Branches: []models.SwiftBank{{SwiftCode: "ABCDUS33001"}},
+			}, nil
+		}
+
+		resp, err := client.GetByCode(ctx, &grpcapi.GetByCodeRequest{SwiftCode: "ABCDUS33XXX"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Bank.SwiftCode).To(Equal("ABCDUS33XXX"))
+		Expect(resp.Bank.IsHeadquarter).To(BeTrue())
+		Expect(resp.Branches).To(HaveLen(1))
+	})
+
+	It("should carry the town, time zone and contact details both ways", func() {
+		var created *models.SwiftBank
+		mockSvc.CreateSwiftCodeFunc = func(ctx context.Context, bank *models.SwiftBank) error {
+			created = bank
+			return nil
+		}
+		mockSvc.GetSwiftCodeDetailsFunc = func(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+			return &repository.SwiftBankDetail{Bank: *created}, nil
+		}
+
+		_, err := client.Create(ctx, &grpcapi.CreateRequest{Bank: &grpcapi.SwiftCode{
+			SwiftCode: "ABCDUS33XXX", CountryIso2: "US", BankName: "Test Bank",
+			TownName: "NEW YORK", TimeZone: "America/New_York", Website: "https://bank.example", Phone: "+1 212 555 0100",
+		}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created.Town).To(Equal("NEW YORK"))
+		Expect(created.Phone).To(Equal("+1 212 555 0100"))
+
+		resp, err := client.GetByCode(ctx, &grpcapi.GetByCodeRequest{SwiftCode: "ABCDUS33XXX"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Bank.TownName).To(Equal("NEW YORK"))
+		Expect(resp.Bank.TimeZone).To(Equal("America/New_York"))
+		Expect(resp.Bank.Website).To(Equal("https://bank.example"))
+		Expect(resp.Bank.Phone).To(Equal("+1 212 555 0100"))
+	})
+
+	It("should map service errors to status codes", func() {
+		mockSvc.GetSwiftCodesByCountryFunc = func(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+			return nil, service.ErrInvalidInput
+		}
+
+		_, err := client.GetByCountry(ctx, &grpcapi.GetByCountryRequest{CountryIso2: "USA"})
+		Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+	})
+
+	It("should report per-code failures from BatchCreate", func() {
+		mockSvc.CreateSwiftCodeFunc = func(ctx context.Context, bank *models.SwiftBank) error {
+			if bank.SwiftCode == "ABCDUS33XXX" {
+				return service.ErrAlreadyExists
+			}
+			return nil
+		}
+
+		resp, err := client.BatchCreate(ctx, &grpcapi.BatchCreateRequest{Banks: []*grpcapi.SwiftCode{
+			{SwiftCode: "ABCDUS33XXX", BankName: "Test Bank", CountryIso2: "US"},
+			{SwiftCode: "EFGHUS33XXX", BankName: "Other Bank", CountryIso2: "US"},
+		}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Created).To(Equal(int32(1)))
+		Expect(resp.Errors).To(HaveLen(1))
+		Expect(resp.Errors[0].Message).To(Equal("SWIFT code already exists"))
+	})
+
+	Context("when auth is enabled", func() {
+		BeforeEach(func() {
+			auth = middleware.AuthConfig{Enabled: true, SigningKey: "secret"}
+		})
+
+		It("should reject writes without a token", func() {
+			_, err := client.Delete(ctx, &grpcapi.DeleteRequest{SwiftCode: "ABCDUS33XXX"})
+			Expect(status.Code(err)).To(Equal(codes.Unauthenticated))
+		})
+	})
+
+	Context("when the IP allowlist excludes the client", func() {
+		BeforeEach(func() {
+			var err error
+			allow, err = middleware.NewIPAllowlist(middleware.IPAllowlistConfig{Enabled: true, CIDRs: []string{"10.0.0.0/8"}})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should refuse writes", func() {
+			_, err := client.Delete(ctx, &grpcapi.DeleteRequest{SwiftCode: "ABCDUS33XXX"})
+			Expect(status.Code(err)).To(Equal(codes.PermissionDenied))
+		})
+	})
+})
+
+var _ = Describe("Messages", func() {
+	It("should encode messages in protobuf wire format", func() {
+		data, err := proto.Marshal(&grpcapi.GetByCodeRequest{SwiftCode: "AB"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal([]byte{0x0a, 0x02, 'A', 'B'}))
+	})
+
+	It("should skip unknown fields when decoding", func() {
+		var req grpcapi.DeleteRequest
+		err := proto.Unmarshal([]byte{0x10, 0x01, 0x0a, 0x01, 'X'}, &req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(req.SwiftCode).To(Equal("X"))
+	})
+
+	It("should match the fields declared in swiftcodes.proto", func() {
+		source, err := os.ReadFile("../../../proto/swiftcodes/v1/swiftcodes.proto")
+		Expect(err).NotTo(HaveOccurred())
+		file := grpcapi.File_proto_swiftcodes_v1_swiftcodes_proto
+
+		messages := regexp.MustCompile(`(?s)message (\w+) \{(.*?)\}`).FindAllStringSubmatch(string(source), -1)
+		Expect(messages).To(HaveLen(file.Messages().Len()))
+		field := regexp.MustCompile(`(\w+) = (\d+);`)
+		for _, m := range messages {
+			desc := file.Messages().ByName(protoreflect.Name(m[1]))
+			Expect(desc).NotTo(BeNil(), m[1])
+			declared := field.FindAllStringSubmatch(m[2], -1)
+			Expect(declared).To(HaveLen(desc.Fields().Len()), m[1])
+			for _, f := range declared {
+				number, _ := strconv.Atoi(f[2])
+				generated := desc.Fields().ByName(protoreflect.Name(f[1]))
+				Expect(generated).NotTo(BeNil(), m[1]+"."+f[1])
+				Expect(int(generated.Number())).To(Equal(number), m[1]+"."+f[1])
+			}
+		}
+	})
+})
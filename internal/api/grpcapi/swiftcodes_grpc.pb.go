@@ -0,0 +1,287 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: proto/swiftcodes/v1/swiftcodes.proto
+
+package grpcapi
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SwiftCodes_GetByCode_FullMethodName    = "/swiftcodes.v1.SwiftCodes/GetByCode"
+	SwiftCodes_GetByCountry_FullMethodName = "/swiftcodes.v1.SwiftCodes/GetByCountry"
+	SwiftCodes_Create_FullMethodName       = "/swiftcodes.v1.SwiftCodes/Create"
+	SwiftCodes_Delete_FullMethodName       = "/swiftcodes.v1.SwiftCodes/Delete"
+	SwiftCodes_BatchCreate_FullMethodName  = "/swiftcodes.v1.SwiftCodes/BatchCreate"
+)
+
+// SwiftCodesClient is the client API for SwiftCodes service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SwiftCodes exposes the SWIFT code service to internal gRPC consumers.
+// Create, Delete and BatchCreate require a writer or admin bearer token in
+// the "authorization" metadata when auth is enabled.
+//
+// GetByCodeResponse and GetByCountryResponse are also served by the REST
+// lookup endpoints for clients sending Accept: application/x-protobuf.
+type SwiftCodesClient interface {
+	GetByCode(ctx context.Context, in *GetByCodeRequest, opts ...grpc.CallOption) (*GetByCodeResponse, error)
+	GetByCountry(ctx context.Context, in *GetByCountryRequest, opts ...grpc.CallOption) (*GetByCountryResponse, error)
+	Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error)
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	BatchCreate(ctx context.Context, in *BatchCreateRequest, opts ...grpc.CallOption) (*BatchCreateResponse, error)
+}
+
+type swiftCodesClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSwiftCodesClient(cc grpc.ClientConnInterface) SwiftCodesClient {
+	return &swiftCodesClient{cc}
+}
+
+func (c *swiftCodesClient) GetByCode(ctx context.Context, in *GetByCodeRequest, opts ...grpc.CallOption) (*GetByCodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetByCodeResponse)
+	err := c.cc.Invoke(ctx, SwiftCodes_GetByCode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftCodesClient) GetByCountry(ctx context.Context, in *GetByCountryRequest, opts ...grpc.CallOption) (*GetByCountryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetByCountryResponse)
+	err := c.cc.Invoke(ctx, SwiftCodes_GetByCountry_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftCodesClient) Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateResponse)
+	err := c.cc.Invoke(ctx, SwiftCodes_Create_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftCodesClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, SwiftCodes_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swiftCodesClient) BatchCreate(ctx context.Context, in *BatchCreateRequest, opts ...grpc.CallOption) (*BatchCreateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchCreateResponse)
+	err := c.cc.Invoke(ctx, SwiftCodes_BatchCreate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SwiftCodesServer is the server API for SwiftCodes service.
+// All implementations must embed UnimplementedSwiftCodesServer
+// for forward compatibility.
+//
+// SwiftCodes exposes the SWIFT code service to internal gRPC consumers.
+// Create, Delete and BatchCreate require a writer or admin bearer token in
+// the "authorization" metadata when auth is enabled.
+//
+// GetByCodeResponse and GetByCountryResponse are also served by the REST
+// lookup endpoints for clients sending Accept: application/x-protobuf.
+type SwiftCodesServer interface {
+	GetByCode(context.Context, *GetByCodeRequest) (*GetByCodeResponse, error)
+	GetByCountry(context.Context, *GetByCountryRequest) (*GetByCountryResponse, error)
+	Create(context.Context, *CreateRequest) (*CreateResponse, error)
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	BatchCreate(context.Context, *BatchCreateRequest) (*BatchCreateResponse, error)
+	mustEmbedUnimplementedSwiftCodesServer()
+}
+
+// UnimplementedSwiftCodesServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSwiftCodesServer struct{}
+
+func (UnimplementedSwiftCodesServer) GetByCode(context.Context, *GetByCodeRequest) (*GetByCodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetByCode not implemented")
+}
+func (UnimplementedSwiftCodesServer) GetByCountry(context.Context, *GetByCountryRequest) (*GetByCountryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetByCountry not implemented")
+}
+func (UnimplementedSwiftCodesServer) Create(context.Context, *CreateRequest) (*CreateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedSwiftCodesServer) Delete(context.Context, *DeleteRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedSwiftCodesServer) BatchCreate(context.Context, *BatchCreateRequest) (*BatchCreateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchCreate not implemented")
+}
+func (UnimplementedSwiftCodesServer) mustEmbedUnimplementedSwiftCodesServer() {}
+func (UnimplementedSwiftCodesServer) testEmbeddedByValue()                    {}
+
+// UnsafeSwiftCodesServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SwiftCodesServer will
+// result in compilation errors.
+type UnsafeSwiftCodesServer interface {
+	mustEmbedUnimplementedSwiftCodesServer()
+}
+
+func RegisterSwiftCodesServer(s grpc.ServiceRegistrar, srv SwiftCodesServer) {
+	// If the following call pancis, it indicates UnimplementedSwiftCodesServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SwiftCodes_ServiceDesc, srv)
+}
+
+func _SwiftCodes_GetByCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetByCodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftCodesServer).GetByCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftCodes_GetByCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftCodesServer).GetByCode(ctx, req.(*GetByCodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftCodes_GetByCountry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetByCountryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftCodesServer).GetByCountry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftCodes_GetByCountry_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftCodesServer).GetByCountry(ctx, req.(*GetByCountryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftCodes_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftCodesServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftCodes_Create_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftCodesServer).Create(ctx, req.(*CreateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftCodes_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftCodesServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftCodes_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftCodesServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwiftCodes_BatchCreate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchCreateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwiftCodesServer).BatchCreate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SwiftCodes_BatchCreate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwiftCodesServer).BatchCreate(ctx, req.(*BatchCreateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SwiftCodes_ServiceDesc is the grpc.ServiceDesc for SwiftCodes service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SwiftCodes_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "swiftcodes.v1.SwiftCodes",
+	HandlerType: (*SwiftCodesServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetByCode",
+			Handler:    _SwiftCodes_GetByCode_Handler,
+		},
+		{
+			MethodName: "GetByCountry",
+			Handler:    _SwiftCodes_GetByCountry_Handler,
+		},
+		{
+			MethodName: "Create",
+			Handler:    _SwiftCodes_Create_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _SwiftCodes_Delete_Handler,
+		},
+		{
+			MethodName: "BatchCreate",
+			Handler:    _SwiftCodes_BatchCreate_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/swiftcodes/v1/swiftcodes.proto",
+}
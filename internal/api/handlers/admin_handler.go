@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+const defaultTopN = 10
+
+// AdminHandler handles operator-facing admin API requests
+type AdminHandler struct {
+	tracker *repository.QueryTracker
+	metrics *repository.Metrics
+	stats   *service.AccessStats
+}
+
+// NewAdminHandler creates a new admin handler instance
+func NewAdminHandler(tracker *repository.QueryTracker, metrics *repository.Metrics, stats *service.AccessStats) *AdminHandler {
+	return &AdminHandler{tracker: tracker, metrics: metrics, stats: stats}
+}
+
+// InflightQueries lists repository operations that are currently running
+func (h *AdminHandler) InflightQueries(c fiber.Ctx) error {
+	queries := h.tracker.Snapshot()
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"count":   len(queries),
+		"queries": queries,
+	})
+}
+
+// RepositoryMetrics reports per-operation repository call statistics and
+// the hits, misses and evictions of the repository cache
+func (h *AdminHandler) RepositoryMetrics(c fiber.Ctx) error {
+	stats := []repository.OperationStats{}
+	var cache repository.CacheStats
+	if h.metrics != nil {
+		stats = h.metrics.Snapshot()
+		cache = h.metrics.Cache()
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"operations": stats,
+		"cache":      cache,
+	})
+}
+
+// AccessStats reports the most queried countries and SWIFT codes; ?limit=
+// controls how many entries of each are returned
+func (h *AdminHandler) AccessStats(c fiber.Ctx) error {
+	limit := defaultTopN
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid input provided",
+				apierror.Field("limit", "must be a positive integer"))
+		}
+		limit = n
+	}
+
+	if h.stats == nil {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"total_country_lookups": 0,
+			"total_code_lookups":    0,
+			"top_countries":         []service.AccessCount{},
+			"top_codes":             []service.AccessCount{},
+		})
+	}
+
+	countries, codes := h.stats.Totals()
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"total_country_lookups": countries,
+		"total_code_lookups":    codes,
+		"top_countries":         h.stats.TopCountries(limit),
+		"top_codes":             h.stats.TopCodes(limit),
+	})
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+// AliasHandler manages the aliases of stored SWIFT codes
+type AliasHandler struct {
+	aliases *service.Aliases
+}
+
+// NewAliasHandler creates a handler for the aliases in aliases
+func NewAliasHandler(aliases *service.Aliases) *AliasHandler {
+	return &AliasHandler{aliases: aliases}
+}
+
+// aliasRequest is the body accepted by Add
+type aliasRequest struct {
+	Alias string `json:"alias"`
+}
+
+// List returns the aliases of the code in the :swiftCode parameter
+func (h *AliasHandler) List(c fiber.Ctx) error {
+	code := strings.ToUpper(c.Params("swiftCode"))
+	aliases, err := h.aliases.List(c.Context(), code)
+	if err != nil {
+		return handleError(c, err)
+	}
+	return c.JSON(fiber.Map{"swift_code": code, "aliases": aliases})
+}
+
+// Add stores an alias of the code in the :swiftCode parameter. An alias
+// that is a stored code or an alias already is answered 409
+// ALIAS_CONFLICT.
+func (h *AliasHandler) Add(c fiber.Ctx) error {
+	ctx, dryRun, ok := writeContext(c)
+	if !ok {
+		return nil
+	}
+	var req aliasRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid request body")
+	}
+
+	err := h.aliases.Add(ctx, c.Params("swiftCode"), req.Alias)
+	if errors.Is(err, service.ErrAliasConflict) {
+		return apierror.Write(c, fiber.StatusConflict, apierror.CodeAliasConflict, "Alias is a stored SWIFT code or an alias already")
+	}
+	if err != nil {
+		return handleError(c, err)
+	}
+	if dryRun {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Alias would be added", "dry_run": true})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Alias added successfully"})
+}
+
+// Delete removes the alias in the :alias parameter from the code in the
+// :swiftCode parameter
+func (h *AliasHandler) Delete(c fiber.Ctx) error {
+	ctx, dryRun, ok := writeContext(c)
+	if !ok {
+		return nil
+	}
+
+	err := h.aliases.Remove(ctx, c.Params("swiftCode"), c.Params("alias"))
+	if errors.Is(err, service.ErrNotFound) {
+		return apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "SWIFT code or alias not found")
+	}
+	if err != nil {
+		return handleError(c, err)
+	}
+	if dryRun {
+		return c.JSON(fiber.Map{"message": "Alias would be deleted", "dry_run": true})
+	}
+	return c.JSON(fiber.Map{"message": "Alias deleted successfully"})
+}
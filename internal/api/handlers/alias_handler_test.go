@@ -0,0 +1,100 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	handlers "github.com/zdziszkee/swift-codes/internal/api/handlers"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+var _ = Describe("AliasHandler", func() {
+	var app *fiber.App
+
+	BeforeEach(func() {
+		repo := repository.NewMemorySwiftRepository()
+		Expect(repo.CreateBatch(context.Background(), []*models.SwiftBank{
+			{SwiftCode: "PKOPPLPWXXX", CountryISOCode: "PL", BankName: "PKO BP", IsHeadquarter: true},
+			{SwiftCode: "BREXPLPWXXX", CountryISOCode: "PL", BankName: "MBANK", IsHeadquarter: true},
+		})).To(Succeed())
+		aliasRepo := repository.NewMemoryAliasRepository()
+		svc := service.WithAliases(service.NewSwiftService(repo), aliasRepo)
+		h := handlers.NewAliasHandler(service.NewAliases(svc, aliasRepo))
+		swift := handlers.NewSwiftHandler(svc)
+		app = fiber.New()
+		app.Post("/swift", swift.Create)
+		app.Get("/swift/:swiftCode", swift.GetByCode)
+		app.Get("/swift/:swiftCode/aliases", h.List)
+		app.Post("/swift/:swiftCode/aliases", h.Add)
+		app.Delete("/swift/:swiftCode/aliases/:alias", h.Delete)
+	})
+
+	do := func(method, target, body string) *http.Response {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := app.Test(req, fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	errorCode := func(resp *http.Response) apierror.Code {
+		var body apierror.Error
+		Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		return body.Code
+	}
+
+	It("should add, list and serve aliases", func() {
+		Expect(do(http.MethodPost, "/swift/PKOPPLPWXXX/aliases", `{"alias":"BPKOPPLPXXX"}`).StatusCode).To(Equal(http.StatusCreated))
+
+		resp := do(http.MethodGet, "/swift/pkopplpwxxx/aliases", "")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		var listed struct {
+			Aliases []string `json:"aliases"`
+		}
+		Expect(json.NewDecoder(resp.Body).Decode(&listed)).To(Succeed())
+		Expect(listed.Aliases).To(Equal([]string{"BPKOPPLPXXX"}))
+
+		resp = do(http.MethodGet, "/swift/BPKOPPLPXXX", "")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		var detail handlers.SwiftCodeResponse
+		Expect(json.NewDecoder(resp.Body).Decode(&detail)).To(Succeed())
+		Expect(detail.Bank.SwiftCode).To(Equal("PKOPPLPWXXX"))
+	})
+
+	It("should answer conflicts across codes and aliases with ALIAS_CONFLICT", func() {
+		resp := do(http.MethodPost, "/swift/PKOPPLPWXXX/aliases", `{"alias":"BREXPLPWXXX"}`)
+		Expect(resp.StatusCode).To(Equal(http.StatusConflict))
+		Expect(errorCode(resp)).To(Equal(apierror.CodeAliasConflict))
+
+		Expect(do(http.MethodPost, "/swift/PKOPPLPWXXX/aliases", `{"alias":"BPKOPPLPXXX"}`).StatusCode).To(Equal(http.StatusCreated))
+		resp = do(http.MethodPost, "/swift", `{"swiftCode":"BPKOPPLPXXX","countryISO2":"PL","bankName":"BANK PKO"}`)
+		Expect(resp.StatusCode).To(Equal(http.StatusConflict))
+		Expect(errorCode(resp)).To(Equal(apierror.CodeAliasConflict))
+	})
+
+	It("should check an alias without storing it in dry-run mode", func() {
+		Expect(do(http.MethodPost, "/swift/PKOPPLPWXXX/aliases?dryRun=true", `{"alias":"BPKOPPLPXXX"}`).StatusCode).To(Equal(http.StatusOK))
+		Expect(do(http.MethodGet, "/swift/BPKOPPLPXXX", "").StatusCode).To(Equal(http.StatusNotFound))
+	})
+
+	It("should delete an alias of the code only", func() {
+		Expect(do(http.MethodPost, "/swift/PKOPPLPWXXX/aliases", `{"alias":"BPKOPPLPXXX"}`).StatusCode).To(Equal(http.StatusCreated))
+
+		resp := do(http.MethodDelete, "/swift/BREXPLPWXXX/aliases/BPKOPPLPXXX", "")
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		Expect(do(http.MethodDelete, "/swift/PKOPPLPWXXX/aliases/BPKOPPLPXXX", "").StatusCode).To(Equal(http.StatusOK))
+		Expect(do(http.MethodGet, "/swift/BPKOPPLPXXX", "").StatusCode).To(Equal(http.StatusNotFound))
+	})
+})
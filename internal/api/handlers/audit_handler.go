@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	"github.com/zdziszkee/swift-codes/internal/audit"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+)
+
+// Row limits of GET /v1/admin/audit
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// AuditHandler serves the audit log of API writes
+type AuditHandler struct {
+	log *audit.Log
+}
+
+// NewAuditHandler creates a handler that reads log
+func NewAuditHandler(log *audit.Log) *AuditHandler {
+	return &AuditHandler{log: log}
+}
+
+// List returns audit entries newest first. ?code= filters by SWIFT code,
+// ?from= and ?to= (RFC 3339) bound the time range, with from inclusive and
+// to exclusive, and ?limit= caps the entries.
+func (h *AuditHandler) List(c fiber.Ctx) error {
+	filter, details := parseAuditFilter(c)
+	filter.SwiftCode = strings.TrimSpace(c.Query("code"))
+	if len(details) > 0 {
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid input provided", details...)
+	}
+
+	entries, err := h.log.List(c.Context(), filter)
+	if err != nil {
+		requestid.Logf(c.Context(), "ERROR: reading audit log failed: %v", err)
+		return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Internal server error")
+	}
+	return c.JSON(fiber.Map{"entries": entries})
+}
+
+// Changes returns the field-level changes of one SWIFT code, newest first,
+// assembled from its audit entries. ?field= keeps a single field, such as
+// Address; ?from=, ?to= and ?limit= work as for List, with the limit
+// capping the audit entries read.
+func (h *AuditHandler) Changes(c fiber.Ctx) error {
+	filter, details := parseAuditFilter(c)
+	filter.SwiftCode = strings.ToUpper(c.Params("swiftCode"))
+	field := strings.TrimSpace(c.Query("field"))
+	if field != "" && !audit.ValidField(field) {
+		details = append(details, apierror.Field("field", "must name a SWIFT code field such as BankName or Address"))
+	}
+	if len(details) > 0 {
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid input provided", details...)
+	}
+
+	entries, err := h.log.List(c.Context(), filter)
+	if err != nil {
+		requestid.Logf(c.Context(), "ERROR: reading audit log failed: %v", err)
+		return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Internal server error")
+	}
+	return c.JSON(fiber.Map{"swiftCode": filter.SwiftCode, "changes": audit.FieldChanges(entries, field)})
+}
+
+// parseAuditFilter reads ?from=, ?to= and ?limit= and describes every
+// invalid one
+func parseAuditFilter(c fiber.Ctx) (audit.Filter, []apierror.Detail) {
+	filter := audit.Filter{Limit: defaultAuditLimit}
+
+	var details []apierror.Detail
+	for _, bound := range []struct {
+		param  string
+		target *time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		raw := c.Query(bound.param)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			details = append(details, apierror.Field(bound.param, "must be an RFC 3339 timestamp"))
+			continue
+		}
+		*bound.target = t
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxAuditLimit {
+			details = append(details, apierror.Field("limit", "must be an integer between 1 and "+strconv.Itoa(maxAuditLimit)))
+		}
+		filter.Limit = limit
+	}
+	if len(details) == 0 && !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		details = append(details, apierror.Field("to", "must be after from"))
+	}
+	return filter, details
+}
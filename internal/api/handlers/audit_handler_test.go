@@ -0,0 +1,107 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	handlers "github.com/zdziszkee/swift-codes/internal/api/handlers"
+	"github.com/zdziszkee/swift-codes/internal/audit"
+	"github.com/zdziszkee/swift-codes/internal/database"
+)
+
+var _ = Describe("AuditHandler", func() {
+	var (
+		app    *fiber.App
+		mockDB sqlmock.Sqlmock
+	)
+
+	BeforeEach(func() {
+		db, mock, err := sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() { _ = db.Close() })
+		mockDB = mock
+
+		log := audit.NewLog(&database.Database{DB: db, Config: database.Config{Catalog: "c", Schema: "s"}}, "swift_audit_log")
+		app = fiber.New()
+		h := handlers.NewAuditHandler(log)
+		app.Get("/audit", h.List)
+		app.Get("/swift/:swiftCode/changes", h.Changes)
+	})
+
+	get := func(target string) *http.Response {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, target, nil), fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	It("should list the filtered entries", func() {
+		mockDB.ExpectQuery(`WHERE swift_code = \? AND occurred_at >= from_iso8601_timestamp\(\?\) ORDER BY occurred_at DESC, id LIMIT 100`).
+			WithArgs("PKOPPLPWXXX", "2025-03-01T00:00:00Z").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "occurred_at", "actor", "action", "swift_code", "country_iso_code", "affected", "before_state", "after_state", "request_id"}).
+				AddRow("id-1", "2025-03-01T12:00:00Z", "alice", audit.ActionCreate, "PKOPPLPWXXX", "PL", int64(1), nil, `{"SwiftCode":"PKOPPLPWXXX"}`, "req-1"))
+
+		resp := get("/audit?code=PKOPPLPWXXX&from=2025-03-01T00:00:00Z")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		var body struct {
+			Entries []audit.Entry `json:"entries"`
+		}
+		Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		Expect(body.Entries).To(HaveLen(1))
+		Expect(body.Entries[0].Actor).To(Equal("alice"))
+		Expect(body.Entries[0].After.SwiftCode).To(Equal("PKOPPLPWXXX"))
+		Expect(mockDB.ExpectationsWereMet()).To(Succeed())
+	})
+
+	It("should reject malformed filters", func() {
+		resp := get("/audit?from=yesterday&limit=0")
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		var body apierror.Error
+		Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		Expect(body.Details).To(ConsistOf(
+			apierror.Detail{Field: "from", Reason: "must be an RFC 3339 timestamp"},
+			apierror.Detail{Field: "limit", Reason: "must be an integer between 1 and 1000"},
+		))
+
+		Expect(get("/audit?from=2025-03-02T00:00:00Z&to=2025-03-01T00:00:00Z").StatusCode).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should list the field changes of a code, newest first", func() {
+		mockDB.ExpectQuery(`WHERE swift_code = \? ORDER BY occurred_at DESC, id LIMIT 100`).
+			WithArgs("PKOPPLPWXXX").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "occurred_at", "actor", "action", "swift_code", "country_iso_code", "affected", "before_state", "after_state", "request_id"}).
+				AddRow("id-2", "2025-03-02T12:00:00Z", "bob", audit.ActionUpdate, "PKOPPLPWXXX", "PL", int64(1),
+					`{"SwiftCode":"PKOPPLPWXXX","BankName":"PKO","Address":"ZUBRA 1"}`, `{"SwiftCode":"PKOPPLPWXXX","BankName":"PKO","Address":"ZUBRA 2"}`, "req-2").
+				AddRow("id-1", "2025-03-01T12:00:00Z", "alice", audit.ActionCreate, "PKOPPLPWXXX", "PL", int64(1),
+					nil, `{"SwiftCode":"PKOPPLPWXXX","BankName":"PKO","Address":"ZUBRA 1"}`, "req-1"))
+
+		resp := get("/swift/pkopplpwxxx/changes?field=address")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		var body struct {
+			SwiftCode string              `json:"swiftCode"`
+			Changes   []audit.FieldChange `json:"changes"`
+		}
+		Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		Expect(body.SwiftCode).To(Equal("PKOPPLPWXXX"))
+		Expect(body.Changes).To(HaveLen(2))
+		Expect(body.Changes[0].Field).To(Equal("Address"))
+		Expect(body.Changes[0].Old).To(Equal("ZUBRA 1"))
+		Expect(body.Changes[0].New).To(Equal("ZUBRA 2"))
+		Expect(body.Changes[0].Actor).To(Equal("bob"))
+		Expect(body.Changes[1].Old).To(BeEmpty())
+		Expect(body.Changes[1].New).To(Equal("ZUBRA 1"))
+		Expect(body.Changes[1].Actor).To(Equal("alice"))
+		Expect(mockDB.ExpectationsWereMet()).To(Succeed())
+	})
+
+	It("should reject unknown fields", func() {
+		resp := get("/swift/PKOPPLPWXXX/changes?field=rating")
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+	})
+})
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+// defaultMaxBatchCodes applies when no MaxBatchCodes is configured
+const defaultMaxBatchCodes = 50
+
+// Per-code statuses of a batch lookup
+const (
+	LookupFound    = "found"
+	LookupNotFound = "not_found"
+	LookupInvalid  = "invalid"
+)
+
+// CodeLookup is the outcome for one code of a batch lookup; Detail is only
+// set when the code was found
+type CodeLookup struct {
+	Status string             `json:"status"`
+	Detail *SwiftCodeResponse `json:"detail,omitempty"`
+}
+
+// GetByCodes looks up the comma-separated ?codes= in one call and returns a
+// map of code to CodeLookup, so a missing or malformed code does not fail
+// the others. Codes are matched case-insensitively and keyed upper-case.
+func (h *SwiftHandler) GetByCodes(c fiber.Ctx) error {
+	var codes []string
+	seen := make(map[string]bool)
+	for _, code := range strings.Split(c.Query("codes"), ",") {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if code != "" && !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+
+	maxCodes := h.config.MaxBatchCodes
+	if maxCodes <= 0 {
+		maxCodes = defaultMaxBatchCodes
+	}
+	switch {
+	case len(codes) == 0:
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid input provided",
+			apierror.Field("codes", "must list at least one SWIFT code"))
+	case len(codes) > maxCodes:
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid input provided",
+			apierror.Field("codes", "must list at most "+strconv.Itoa(maxCodes)+" SWIFT codes"))
+	}
+
+	results := make(map[string]CodeLookup, len(codes))
+	for _, code := range codes {
+		detail, err := h.service.GetSwiftCodeDetails(h.embeddedBranches(c), code)
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			results[code] = CodeLookup{Status: LookupNotFound}
+		case errors.Is(err, service.ErrInvalidInput):
+			results[code] = CodeLookup{Status: LookupInvalid}
+		case err != nil:
+			return handleError(c, err)
+		default:
+			results[code] = CodeLookup{Status: LookupFound, Detail: h.swiftCodeResponse(c, detail)}
+		}
+	}
+	return c.JSON(fiber.Map{"results": results})
+}
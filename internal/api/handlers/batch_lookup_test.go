@@ -0,0 +1,88 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	handlers "github.com/zdziszkee/swift-codes/internal/api/handlers"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+var _ = Describe("GetByCodes", func() {
+	var (
+		app     *fiber.App
+		mockSvc *mocks.MockSwiftService
+		lookups []string
+	)
+
+	BeforeEach(func() {
+		lookups = nil
+		mockSvc = &mocks.MockSwiftService{
+			GetSwiftCodeDetailsFunc: func(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+				lookups = append(lookups, code)
+				switch code {
+				case "PKOPPLPWXXX":
+					return &repository.SwiftBankDetail{Bank: models.SwiftBank{SwiftCode: code, BankName: "PKO BP"}}, nil
+				case "BAD":
+					return nil, service.ErrInvalidInput
+				case "BOOMPLPWXXX":
+					return nil, errors.New("trino down")
+				}
+				return nil, service.ErrNotFound
+			},
+		}
+		h := handlers.NewSwiftHandler(mockSvc, handlers.Config{MaxBatchCodes: 3})
+		app = fiber.New()
+		app.Get("/swift", h.GetByCodes)
+	})
+
+	get := func(target string) *http.Response {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, target, nil), fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	It("should map every code to its detail or a status marker", func() {
+		resp := get("/swift?codes=pkopplpwxxx,%20AAAAUS33,bad,PKOPPLPWXXX")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		var body struct {
+			Results map[string]handlers.CodeLookup `json:"results"`
+		}
+		Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		Expect(body.Results).To(HaveLen(3))
+		Expect(body.Results["PKOPPLPWXXX"].Status).To(Equal(handlers.LookupFound))
+		Expect(body.Results["PKOPPLPWXXX"].Detail.Bank.BankName).To(Equal("PKO BP"))
+		Expect(body.Results["AAAAUS33"]).To(Equal(handlers.CodeLookup{Status: handlers.LookupNotFound}))
+		Expect(body.Results["BAD"]).To(Equal(handlers.CodeLookup{Status: handlers.LookupInvalid}))
+		Expect(lookups).To(Equal([]string{"PKOPPLPWXXX", "AAAAUS33", "BAD"}))
+	})
+
+	It("should require at least one code", func() {
+		Expect(get("/swift?codes=,").StatusCode).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should cap the number of codes", func() {
+		resp := get("/swift?codes=A,B,C,D")
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		var body apierror.Error
+		Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		Expect(body.Details).To(ConsistOf(apierror.Detail{Field: "codes", Reason: "must list at most 3 SWIFT codes"}))
+		Expect(lookups).To(BeEmpty())
+	})
+
+	It("should fail the call when a lookup errors", func() {
+		Expect(get("/swift?codes=BOOMPLPWXXX").StatusCode).To(Equal(http.StatusInternalServerError))
+	})
+})
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+// maxWarmCountries caps the countries of one warm-up, about every ISO country
+const maxWarmCountries = 250
+
+var (
+	warmCountryPattern = regexp.MustCompile(`^[A-Za-z]{2}$`)
+	warmTopPattern     = regexp.MustCompile(`^(?i)top:(\d+)$`)
+)
+
+// CacheHandler populates the repository cache ahead of expected traffic
+type CacheHandler struct {
+	service service.SwiftService
+	stats   *service.AccessStats
+}
+
+// NewCacheHandler creates a handler warming the cache through svc, the
+// service below access statistics; stats ranks countries for "top:N"
+func NewCacheHandler(svc service.SwiftService, stats *service.AccessStats) *CacheHandler {
+	return &CacheHandler{service: svc, stats: stats}
+}
+
+type warmRequest struct {
+	Countries []string `json:"countries"`
+}
+
+// Warm reads the listings of the requested countries so that they are
+// served from the cache, e.g. before a month-end payment run. Each entry of
+// "countries" is an ISO code or "top:N", the N countries most queried on
+// this replica since it started. It answers with a result per country.
+func (h *CacheHandler) Warm(c fiber.Ctx) error {
+	var req warmRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid request body")
+	}
+
+	countries, detail := h.expand(req.Countries)
+	if detail != "" {
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid input provided",
+			apierror.Field("countries", detail))
+	}
+
+	results := service.WarmCountries(c.Context(), h.service, countries)
+	warmed := 0
+	for _, result := range results {
+		if result.Status == service.WarmWarmed {
+			warmed++
+		}
+	}
+	requestid.Logf(c.Context(), "INFO: cache warmed for %d of %d countries", warmed, len(results))
+	return c.JSON(fiber.Map{
+		"warmed":  warmed,
+		"results": results,
+	})
+}
+
+// expand resolves "top:N" entries and drops duplicates, keeping the order
+// given. It returns a reason when the list is invalid.
+func (h *CacheHandler) expand(entries []string) ([]string, string) {
+	var countries []string
+	seen := make(map[string]bool)
+	add := func(country string) {
+		country = strings.ToUpper(country)
+		if !seen[country] {
+			seen[country] = true
+			countries = append(countries, country)
+		}
+	}
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if m := warmTopPattern.FindStringSubmatch(entry); m != nil {
+			n, err := strconv.Atoi(m[1])
+			if err != nil || n < 1 || n > maxWarmCountries {
+				return nil, "top:N must have N between 1 and " + strconv.Itoa(maxWarmCountries)
+			}
+			if h.stats != nil {
+				for _, top := range h.stats.TopCountries(n) {
+					add(top.Key)
+				}
+			}
+			continue
+		}
+		if !warmCountryPattern.MatchString(entry) {
+			return nil, "entries must be ISO 3166-1 alpha-2 codes or top:N, got " + strconv.Quote(entry)
+		}
+		add(entry)
+	}
+
+	switch {
+	case len(entries) == 0:
+		return nil, "must list at least one country or top:N"
+	case len(countries) > maxWarmCountries:
+		return nil, "must name at most " + strconv.Itoa(maxWarmCountries) + " countries"
+	}
+	return countries, ""
+}
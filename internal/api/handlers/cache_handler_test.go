@@ -0,0 +1,94 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	handlers "github.com/zdziszkee/swift-codes/internal/api/handlers"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+var _ = Describe("CacheHandler", func() {
+	var (
+		app     *fiber.App
+		mockSvc *mocks.MockSwiftService
+		stats   *service.AccessStats
+		warmed  []string
+	)
+
+	BeforeEach(func() {
+		warmed = nil
+		mockSvc = &mocks.MockSwiftService{
+			GetSwiftCodesByCountryFunc: func(ctx context.Context, country string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+				warmed = append(warmed, country)
+				switch country {
+				case "ZZ":
+					return nil, service.ErrNotFound
+				case "XX":
+					return nil, errors.New("trino unavailable")
+				}
+				return &repository.CountrySwiftCodes{CountryISO2: country, SwiftCodes: []models.SwiftBank{{SwiftCode: country + "AAAAAXXX"}}}, nil
+			},
+		}
+		stats = service.NewAccessStats()
+		app = fiber.New()
+		app.Post("/cache/warm", handlers.NewCacheHandler(mockSvc, stats).Warm)
+	})
+
+	warm := func(body string) (*http.Response, map[string]any) {
+		req := httptest.NewRequest(http.MethodPost, "/cache/warm", strings.NewReader(body))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		resp, err := app.Test(req, fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		var result map[string]any
+		Expect(json.NewDecoder(resp.Body).Decode(&result)).To(Succeed())
+		return resp, result
+	}
+
+	It("should read every country once and report each outcome", func() {
+		resp, result := warm(`{"countries": ["pl", "PL", "ZZ", "XX"]}`)
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(warmed).To(Equal([]string{"PL", "ZZ", "XX"}))
+		Expect(result["warmed"]).To(BeEquivalentTo(1))
+		Expect(result["results"]).To(HaveLen(3))
+
+		results := result["results"].([]any)
+		Expect(results[0]).To(HaveKeyWithValue("status", service.WarmWarmed))
+		Expect(results[0]).To(HaveKeyWithValue("codes", BeEquivalentTo(1)))
+		Expect(results[1]).To(HaveKeyWithValue("status", service.WarmNotFound))
+		Expect(results[2]).To(HaveKeyWithValue("status", service.WarmFailed))
+		Expect(results[2]).To(HaveKeyWithValue("error", "trino unavailable"))
+	})
+
+	It("should expand top:N from the most queried countries", func() {
+		tracked := service.WithAccessStats(mockSvc, stats)
+		for _, country := range []string{"DE", "DE", "FR", "PL"} {
+			_, err := tracked.GetSwiftCodesByCountry(context.Background(), country, repository.QueryOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		warmed = nil
+
+		_, result := warm(`{"countries": ["top:2", "MT"]}`)
+		Expect(warmed).To(Equal([]string{"DE", "FR", "MT"}))
+		Expect(result["warmed"]).To(BeEquivalentTo(3))
+	})
+
+	It("should reject empty lists, malformed entries and oversized top:N", func() {
+		for _, body := range []string{`{"countries": []}`, `{"countries": ["POL"]}`, `{"countries": ["top:0"]}`, `{"countries": ["top:1000"]}`, `not json`} {
+			resp, _ := warm(body)
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest), body)
+		}
+		Expect(warmed).To(BeEmpty())
+	})
+})
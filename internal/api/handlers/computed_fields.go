@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/expression"
+)
+
+// computedVariables are the bank fields expressions of computed fields can
+// read, in the order of computedValues
+var computedVariables = []string{
+	"swiftCode", "swiftCodeBase", "countryISO2", "countryISOCode", "bankName", "isHeadquarter",
+	"address", "countryName", "town", "timeZone", "website", "phone",
+}
+
+// computedValues returns the variables of bank in computedVariables order
+func computedValues(bank *BankResponse) []string {
+	return []string{
+		bank.SwiftCode, bank.SwiftCodeBase, bank.CountryISOCode, bank.CountryISOCode, bank.BankName,
+		strconv.FormatBool(bank.IsHeadquarter), bank.Address, bank.CountryName, bank.town, bank.timeZone,
+		bank.Website, bank.Phone,
+	}
+}
+
+// computedNamePattern keeps computed field names usable as JSON keys and CSV
+// headers without escaping
+var computedNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// computedField is a configured field derived from the others of a bank
+type computedField struct {
+	name string
+	expr *expression.Expression
+}
+
+// computedValue is the value of a computed field for one bank
+type computedValue struct {
+	name, value string
+}
+
+// compileComputedFields compiles the expressions of fields, ordered by name
+func compileComputedFields(fields map[string]string) ([]computedField, error) {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	compiled := make([]computedField, 0, len(names))
+	for _, name := range names {
+		if !computedNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("api computed field name %q must be a letter followed by letters, digits or underscores", name)
+		}
+		if _, ok := fieldNames[normalizeFieldName(name)]; ok {
+			return nil, fmt.Errorf("api computed field %q clashes with a bank field", name)
+		}
+		expr, err := expression.Compile(fields[name], computedVariables)
+		if err != nil {
+			return nil, fmt.Errorf("api computed field %q: %w", name, err)
+		}
+		compiled = append(compiled, computedField{name: name, expr: expr})
+	}
+	return compiled, nil
+}
+
+// Validate checks the computed fields of the configuration
+func (c Config) Validate() error {
+	_, err := compileComputedFields(c.ComputedFields)
+	return err
+}
+
+// SetComputedFields replaces the computed fields of the handler's responses
+// with those of a validated configuration. If one is invalid they are all
+// left out.
+func (h *SwiftHandler) SetComputedFields(fields map[string]string) {
+	compiled, err := compileComputedFields(fields)
+	if err != nil {
+		log.Printf("WARNING: computed fields disabled: %v", err)
+		compiled = nil
+	}
+	h.computed.Store(&compiled)
+}
+
+// addComputedFields evaluates the computed fields on every bank of a v1
+// payload. They accompany the default selection only: a ?fields= list
+// returns just the fields it names.
+func (h *SwiftHandler) addComputedFields(c fiber.Ctx, v any) {
+	computed := h.computed.Load()
+	if computed == nil || len(*computed) == 0 || c.Query("fields") != "" {
+		return
+	}
+	for _, bank := range responseBanks(v) {
+		values := computedValues(bank)
+		bank.computed = make([]computedValue, len(*computed))
+		for i, field := range *computed {
+			bank.computed[i] = computedValue{name: field.name, value: field.expr.Eval(values)}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+// writeMode maps the conditional headers of a write onto a service write
+// mode. "If-None-Match: *" creates only if absent and "If-Match: *" only
+// replaces a stored code; without either the fallback of the method is
+// used. Entity tags other than * are not supported for writes.
+func writeMode(c fiber.Ctx, fallback service.WriteMode) (mode service.WriteMode, conditional bool, ok bool) {
+	ifNoneMatch := strings.TrimSpace(c.Get(fiber.HeaderIfNoneMatch))
+	ifMatch := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	switch {
+	case ifNoneMatch != "" && ifMatch != "":
+		_ = apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "If-Match and If-None-Match cannot be combined")
+		return 0, false, false
+	case ifNoneMatch == "*":
+		return service.WriteCreate, true, true
+	case ifMatch == "*":
+		return service.WriteUpdate, true, true
+	case ifNoneMatch != "":
+		_ = apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid conditional header",
+			apierror.Field(fiber.HeaderIfNoneMatch, "must be *"))
+		return 0, false, false
+	case ifMatch != "":
+		_ = apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid conditional header",
+			apierror.Field(fiber.HeaderIfMatch, "must be *"))
+		return 0, false, false
+	default:
+		return fallback, false, true
+	}
+}
+
+// save stores bank in the mode selected by the request headers and
+// answers the write
+func (h *SwiftHandler) save(c fiber.Ctx, ctx context.Context, dryRun bool, bank *models.SwiftBank, fallback service.WriteMode) error {
+	mode, conditional, ok := writeMode(c, fallback)
+	if !ok {
+		return nil
+	}
+
+	if err := h.service.CreateSwiftCode(service.WithWriteMode(ctx, mode), bank); err != nil {
+		return handleWriteError(c, err, conditional)
+	}
+	if mode == service.WriteCreate || dryRun {
+		return created(c, dryRun)
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "SWIFT code saved successfully",
+	})
+}
+
+// handleWriteError reports a failed precondition as 412 and everything
+// else as handleError does. A conflict with an alias is not a failed
+// precondition since the alias is a different resource.
+func handleWriteError(c fiber.Ctx, err error, conditional bool) error {
+	if conditional && !errors.Is(err, service.ErrAliasConflict) {
+		switch {
+		case errors.Is(err, service.ErrAlreadyExists):
+			return apierror.Write(c, fiber.StatusPreconditionFailed, apierror.CodePreconditionFailed, "SWIFT code already exists")
+		case errors.Is(err, service.ErrNotFound):
+			return apierror.Write(c, fiber.StatusPreconditionFailed, apierror.CodePreconditionFailed, "SWIFT code not found")
+		}
+	}
+	return handleError(c, err)
+}
+
+// pathCode puts the code of the URL into bank, rejecting a body that names
+// another code
+func pathCode(c fiber.Ctx, bank *models.SwiftBank) bool {
+	code := c.Params("swiftCode")
+	switch {
+	case bank.SwiftCode == "":
+		bank.SwiftCode = code
+	case !strings.EqualFold(bank.SwiftCode, code):
+		_ = apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid input provided",
+			apierror.Field("swiftCode", "must match the SWIFT code in the path"))
+		return false
+	}
+	return true
+}
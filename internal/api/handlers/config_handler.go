@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+)
+
+// ConfigReloadResult lists the settings a configuration reload changed
+type ConfigReloadResult struct {
+	// Applied settings took effect immediately
+	Applied []string `json:"applied"`
+	// RestartRequired settings differ from the running ones but only take
+	// effect after a restart
+	RestartRequired []string `json:"restart_required"`
+}
+
+// ConfigReloader reads the configuration again and applies what it can
+type ConfigReloader interface {
+	Reload() (ConfigReloadResult, error)
+}
+
+// ConfigHandler reloads the configuration on request
+type ConfigHandler struct {
+	reloader ConfigReloader
+}
+
+// NewConfigHandler creates a handler that reloads through reloader
+func NewConfigHandler(reloader ConfigReloader) *ConfigHandler {
+	return &ConfigHandler{reloader: reloader}
+}
+
+// Reload re-reads the configuration file and environment, applies the
+// settings that can change at runtime and lists the changes
+func (h *ConfigHandler) Reload(c fiber.Ctx) error {
+	result, err := h.reloader.Reload()
+	if err != nil {
+		requestid.Logf(c.Context(), "ERROR: configuration reload failed: %v", err)
+		return apierror.Write(c, fiber.StatusUnprocessableEntity, apierror.CodeInvalidInput, "Invalid configuration",
+			apierror.Field("config", err.Error()))
+	}
+	requestid.Logf(c.Context(), "INFO: configuration reloaded; applied %v, restart required for %v", result.Applied, result.RestartRequired)
+	return c.JSON(result)
+}
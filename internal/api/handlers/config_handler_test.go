@@ -0,0 +1,67 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	handlers "github.com/zdziszkee/swift-codes/internal/api/handlers"
+)
+
+type fakeReloader struct {
+	result handlers.ConfigReloadResult
+	err    error
+}
+
+func (f *fakeReloader) Reload() (handlers.ConfigReloadResult, error) {
+	return f.result, f.err
+}
+
+var _ = Describe("ConfigHandler", func() {
+	var (
+		app      *fiber.App
+		reloader *fakeReloader
+	)
+
+	BeforeEach(func() {
+		reloader = &fakeReloader{}
+		app = fiber.New()
+		app.Post("/config/reload", handlers.NewConfigHandler(reloader).Reload)
+	})
+
+	post := func() *http.Response {
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/config/reload", nil), fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	It("should list the applied settings and those requiring a restart", func() {
+		reloader.result = handlers.ConfigReloadResult{
+			Applied:         []string{"log.level"},
+			RestartRequired: []string{"server.port"},
+		}
+
+		resp := post()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		var result handlers.ConfigReloadResult
+		Expect(json.NewDecoder(resp.Body).Decode(&result)).To(Succeed())
+		Expect(result).To(Equal(reloader.result))
+	})
+
+	It("should answer 422 when the new configuration is invalid", func() {
+		reloader.err = errors.New("server port must be between 1 and 65535")
+
+		resp := post()
+		Expect(resp.StatusCode).To(Equal(http.StatusUnprocessableEntity))
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(ContainSubstring("server port must be between 1 and 65535"))
+	})
+})
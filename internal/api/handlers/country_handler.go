@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	"github.com/zdziszkee/swift-codes/internal/countries"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+var iso2Pattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// CountryMetadata is the payload of GET /v1/countries/:iso2
+type CountryMetadata struct {
+	countries.Country
+	// HasSwiftCodes reports whether the directory holds any code of the country
+	HasSwiftCodes bool `json:"hasSwiftCodes"`
+}
+
+// GetCountry returns the ISO 3166 name and currency of a country from the
+// embedded table, and whether any SWIFT codes exist for it
+func (h *SwiftHandler) GetCountry(c fiber.Ctx) error {
+	iso2 := strings.ToUpper(c.Params("iso2"))
+	if !iso2Pattern.MatchString(iso2) {
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid input provided",
+			apierror.Field("iso2", "must be a 2-letter ISO 3166-1 code"))
+	}
+	country, ok := countries.Lookup(iso2)
+	if !ok {
+		return apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "Country not found")
+	}
+
+	codes, err := h.service.GetSwiftCodesByCountry(c.Context(), iso2, repository.QueryOptions{Limit: 1})
+	if err != nil && !errors.Is(err, service.ErrNotFound) {
+		return handleError(c, err)
+	}
+	return c.JSON(CountryMetadata{Country: country, HasSwiftCodes: err == nil && len(codes.SwiftCodes) > 0})
+}
@@ -0,0 +1,82 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	handlers "github.com/zdziszkee/swift-codes/internal/api/handlers"
+	"github.com/zdziszkee/swift-codes/internal/countries"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+var _ = Describe("GetCountry", func() {
+	var (
+		app     *fiber.App
+		mockSvc *mocks.MockSwiftService
+	)
+
+	BeforeEach(func() {
+		mockSvc = &mocks.MockSwiftService{
+			GetSwiftCodesByCountryFunc: func(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+				Expect(opts.Limit).To(Equal(1))
+				if countryCode == "PL" {
+					return &repository.CountrySwiftCodes{CountryISO2: "PL", SwiftCodes: []models.SwiftBank{{SwiftCode: "PKOPPLPWXXX"}}}, nil
+				}
+				return nil, service.ErrNotFound
+			},
+		}
+		app = fiber.New()
+		app.Get("/countries/:iso2", handlers.NewSwiftHandler(mockSvc).GetCountry)
+	})
+
+	get := func(target string) *http.Response {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, target, nil), fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	decode := func(resp *http.Response) handlers.CountryMetadata {
+		var body handlers.CountryMetadata
+		Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		return body
+	}
+
+	It("should return the ISO metadata of a country with codes", func() {
+		resp := get("/countries/pl")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(decode(resp)).To(Equal(handlers.CountryMetadata{
+			Country:       countries.Country{ISO2: "PL", ISO3: "POL", Name: "Poland", Currency: "PLN"},
+			HasSwiftCodes: true,
+		}))
+	})
+
+	It("should answer for countries without codes", func() {
+		resp := get("/countries/IS")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		body := decode(resp)
+		Expect(body.Name).To(Equal("Iceland"))
+		Expect(body.HasSwiftCodes).To(BeFalse())
+	})
+
+	It("should reject unknown and malformed codes", func() {
+		Expect(get("/countries/XX").StatusCode).To(Equal(http.StatusNotFound))
+		Expect(get("/countries/POL").StatusCode).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should fail when the lookup errors", func() {
+		mockSvc.GetSwiftCodesByCountryFunc = func(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+			return nil, errors.New("trino down")
+		}
+		Expect(get("/countries/PL").StatusCode).To(Equal(http.StatusInternalServerError))
+	})
+})
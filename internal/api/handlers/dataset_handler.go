@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+// DatasetHandler lists the configured datasets and switches the default
+type DatasetHandler struct {
+	router *service.DatasetRouter
+}
+
+// NewDatasetHandler creates a handler for the datasets of router
+func NewDatasetHandler(router *service.DatasetRouter) *DatasetHandler {
+	return &DatasetHandler{router: router}
+}
+
+// DatasetsResponse is the payload of the dataset admin endpoints
+type DatasetsResponse struct {
+	Default  string   `json:"default"`
+	Datasets []string `json:"datasets"`
+}
+
+type setDefaultRequest struct {
+	Name string `json:"name"`
+}
+
+// Router returns the router whose datasets the handler manages
+func (h *DatasetHandler) Router() *service.DatasetRouter {
+	return h.router
+}
+
+// List returns the datasets and the current default
+func (h *DatasetHandler) List(c fiber.Ctx) error {
+	return c.JSON(h.response())
+}
+
+// SetDefault switches the dataset served to requests that do not select
+// one, e.g. to cut over to a new directory release
+func (h *DatasetHandler) SetDefault(c fiber.Ctx) error {
+	var req setDefaultRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid request body")
+	}
+
+	previous := h.router.Default()
+	if err := h.router.SetDefault(req.Name); errors.Is(err, service.ErrUnknownDataset) {
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Unknown dataset",
+			apierror.Field("name", "must be one of the configured datasets"))
+	}
+	requestid.Logf(c.Context(), "INFO: default dataset switched from %s to %s", previous, req.Name)
+	return c.JSON(h.response())
+}
+
+func (h *DatasetHandler) response() DatasetsResponse {
+	return DatasetsResponse{Default: h.router.Default(), Datasets: h.router.Names()}
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+	"github.com/zdziszkee/swift-codes/internal/attribution"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+)
+
+// Config holds response-shaping switches for the HTTP handlers
+type Config struct {
+	// Envelope wraps JSON responses in {data, meta, errors}; a token's
+	// envelope claim and ?envelope= override it
+	Envelope bool `koanf:"envelope"`
+	// MaxPageSize caps ?limit= on list endpoints
+	MaxPageSize int `koanf:"max_page_size"`
+	// MaxEmbeddedBranches caps the branches embedded in a detail response
+	MaxEmbeddedBranches int `koanf:"max_embedded_branches"`
+	// MaxValidationRows caps the rows of a file sent to /validate/file
+	MaxValidationRows int `koanf:"max_validation_rows"`
+	// MaxBatchCodes caps the codes of one GET /v1/swiftCodes?codes= lookup
+	MaxBatchCodes int `koanf:"max_batch_codes"`
+	// MaxWriteBodyBytes caps request bodies on write endpoints; 0 disables it
+	MaxWriteBodyBytes int `koanf:"max_write_body_bytes"`
+	// ServerTiming adds a Server-Timing header with per-stage durations to
+	// every response; meant for debugging
+	ServerTiming bool `koanf:"server_timing"`
+	// AdminUI serves the embedded admin page at /admin/ui; the page calls the
+	// admin endpoints with a token entered by the user
+	AdminUI bool `koanf:"admin_ui"`
+	// ComputedFields adds fields derived from the others to the banks of v1
+	// JSON and CSV responses, keyed by name; see package expression for the
+	// language, e.g. displayName = 'join(", ", bankName, town)'
+	ComputedFields map[string]string `koanf:"computed_fields"`
+	// StrictSchema rejects write bodies setting server-managed fields
+	// (swiftCodeBase and isHeadquarter contradicting the code, timestamps)
+	// instead of ignoring them
+	StrictSchema bool `koanf:"strict_schema"`
+}
+
+// Meta describes an enveloped response: when it was produced, for which
+// request, under which data licence and, for lists, how many items match in
+// total and which window was returned
+type Meta struct {
+	Total      int    `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	// Version is the change counter of a listed country, as in its ETag
+	Version     uint64                   `json:"version,omitempty"`
+	GeneratedAt time.Time                `json:"generatedAt"`
+	RequestID   string                   `json:"requestId,omitempty"`
+	Attribution *attribution.Attribution `json:"attribution,omitempty"`
+}
+
+// listMetaKey holds the *Meta of a paged response, set with its pagination
+// headers
+const listMetaKey = "envelope.listMeta"
+
+// Envelope returns middleware that wraps the JSON responses of the routes
+// after it, errors included, in {"data", "meta", "errors"}. It is enabled
+// by cfg.Envelope, by the envelope claim of the caller's token and per
+// request with ?envelope=, in increasing order of precedence. CSV, XML,
+// protobuf and event stream responses are left alone. With
+// attr.InResponses the meta carries the data attribution.
+func Envelope(cfg Config, auth middleware.AuthConfig, attr attribution.Config) fiber.Handler {
+	var attrJSON []byte
+	if attr.InResponses {
+		attrJSON, _ = json.Marshal(attr.Attribution())
+	}
+	return func(c fiber.Ctx) error {
+		if !wantsEnvelope(c, cfg, auth) {
+			return c.Next()
+		}
+		if err := c.Next(); err != nil {
+			// Answer the error here so that its body is wrapped as well
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				return err
+			}
+		}
+		wrapResponse(c, attrJSON)
+		return nil
+	}
+}
+
+// wantsEnvelope reports whether the response to c should be wrapped
+func wantsEnvelope(c fiber.Ctx, cfg Config, auth middleware.AuthConfig) bool {
+	if raw := c.Query("envelope"); raw != "" {
+		enabled, err := strconv.ParseBool(raw)
+		return err == nil && enabled
+	}
+	if auth.Enabled {
+		// An invalid token is rejected by the route itself
+		if claims, err := middleware.RequestClaims(auth, c); err == nil && claims != nil && claims.Envelope != nil {
+			return *claims.Envelope
+		}
+	}
+	return cfg.Envelope
+}
+
+// wrapResponse replaces a JSON body with its envelope. A success body
+// becomes data; an error body becomes the only entry of errors. A non-empty
+// attr is the JSON of the attribution added to the meta.
+func wrapResponse(c fiber.Ctx, attr []byte) {
+	resp := c.Response()
+	body := resp.Body()
+	if len(body) == 0 || !strings.HasPrefix(string(resp.Header.ContentType()), fiber.MIMEApplicationJSON) {
+		return
+	}
+
+	meta := Meta{GeneratedAt: time.Now().UTC(), RequestID: requestid.FromContext(c.Context())}
+	list, paged := c.Locals(listMetaKey).(*Meta)
+	if paged {
+		meta.Total, meta.Limit, meta.Offset, meta.NextCursor = list.Total, list.Limit, list.Offset, list.NextCursor
+		meta.Version = list.Version
+	}
+
+	buf := make([]byte, 0, len(body)+len(attr)+160)
+	errs := []byte("[]")
+	if status := resp.StatusCode(); status < fiber.StatusBadRequest {
+		buf = append(buf, `{"data":`...)
+		buf = append(buf, body...)
+	} else {
+		var apiErr apierror.Error
+		if json.Unmarshal(body, &apiErr) != nil || apiErr.Code == "" {
+			apiErr = *apierror.New(c, apierror.CodeForStatus(status), fiber.NewError(status).Message)
+		}
+		errs, _ = json.Marshal([]apierror.Error{apiErr})
+		buf = append(buf, `{"data":null`...)
+	}
+	buf = append(buf, `,"meta":`...)
+	buf = appendMetaJSON(buf, meta, paged, attr)
+	buf = append(buf, `,"errors":`...)
+	buf = append(buf, errs...)
+	resp.SetBodyRaw(append(buf, '}'))
+}
+
+// appendMetaJSON appends the JSON encoding of meta to dst, leaving out the
+// list fields unless paged and taking the attribution as encoded attr
+func appendMetaJSON(dst []byte, meta Meta, paged bool, attr []byte) []byte {
+	dst = append(dst, '{')
+	if paged {
+		dst = append(dst, `"total":`...)
+		dst = strconv.AppendInt(dst, int64(meta.Total), 10)
+		dst = append(dst, `,"limit":`...)
+		dst = strconv.AppendInt(dst, int64(meta.Limit), 10)
+		dst = append(dst, `,"offset":`...)
+		dst = strconv.AppendInt(dst, int64(meta.Offset), 10)
+		if meta.NextCursor != "" {
+			dst = append(dst, `,"nextCursor":`...)
+			dst = appendJSONString(dst, meta.NextCursor)
+		}
+		if meta.Version != 0 {
+			dst = append(dst, `,"version":`...)
+			dst = strconv.AppendUint(dst, meta.Version, 10)
+		}
+		dst = append(dst, ',')
+	}
+	dst = append(dst, `"generatedAt":"`...)
+	dst = meta.GeneratedAt.AppendFormat(dst, time.RFC3339Nano)
+	dst = append(dst, '"')
+	if meta.RequestID != "" {
+		dst = append(dst, `,"requestId":`...)
+		dst = appendJSONString(dst, meta.RequestID)
+	}
+	if len(attr) > 0 {
+		dst = append(dst, `,"attribution":`...)
+		dst = append(dst, attr...)
+	}
+	return append(dst, '}')
+}
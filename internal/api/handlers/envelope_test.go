@@ -0,0 +1,157 @@
+package handlers_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	handlers "github.com/zdziszkee/swift-codes/internal/api/handlers"
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+	"github.com/zdziszkee/swift-codes/internal/attribution"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+var _ = Describe("Envelope", func() {
+	var (
+		app  *fiber.App
+		attr attribution.Config
+		cfg  handlers.Config
+		auth middleware.AuthConfig
+	)
+
+	type envelope struct {
+		Data   json.RawMessage  `json:"data"`
+		Meta   map[string]any   `json:"meta"`
+		Errors []apierror.Error `json:"errors"`
+	}
+
+	BeforeEach(func() {
+		cfg = handlers.Config{Envelope: true}
+		attr = attribution.Config{}
+		auth = middleware.AuthConfig{Enabled: true, SigningKey: "secret"}
+	})
+
+	JustBeforeEach(func() {
+		h := handlers.NewSwiftHandler(&mocks.MockSwiftService{
+			GetHeadquartersFunc: func(ctx context.Context, code string) (*models.SwiftBank, error) {
+				if code != "BSZLPLP1WAW" {
+					return nil, service.ErrNotFound
+				}
+				return &models.SwiftBank{SwiftCode: "BSZLPLP1XXX", IsHeadquarter: true}, nil
+			},
+		})
+		app = fiber.New(fiber.Config{ErrorHandler: apierror.Handler})
+		v1 := app.Group("/v1", handlers.Envelope(cfg, auth, attr))
+		v1.Get("/swift/:swiftCode/headquarters", h.GetHeadquarters)
+	})
+
+	get := func(path, token string) (*http.Response, string) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := app.Test(req, fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return resp, string(body)
+	}
+
+	decode := func(body string) envelope {
+		var env envelope
+		Expect(json.Unmarshal([]byte(body), &env)).To(Succeed())
+		return env
+	}
+
+	It("should put a successful body in data", func() {
+		resp, body := get("/v1/swift/BSZLPLP1WAW/headquarters?fields=swiftCode", "")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		env := decode(body)
+		Expect(string(env.Data)).To(Equal(`{"bank":{"SwiftCode":"BSZLPLP1XXX"}}`))
+		Expect(env.Meta).To(HaveKey("generatedAt"))
+		Expect(env.Meta).NotTo(HaveKey("total"))
+		Expect(env.Meta).NotTo(HaveKey("attribution"))
+		Expect(env.Errors).To(BeEmpty())
+	})
+
+	It("should move error bodies to errors, unmatched routes included", func() {
+		resp, body := get("/v1/swift/ABCDPLPWXXX/headquarters", "")
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		env := decode(body)
+		Expect(string(env.Data)).To(Equal("null"))
+		Expect(env.Errors).To(ConsistOf(HaveField("Code", apierror.CodeNotFound)))
+
+		resp, body = get("/v1/unknown", "")
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		Expect(decode(body).Errors).To(ConsistOf(HaveField("Code", apierror.CodeRouteNotFound)))
+	})
+
+	It("should leave other formats alone", func() {
+		_, body := get("/v1/swift/BSZLPLP1WAW/headquarters?format=csv&fields=swiftCode", "")
+		Expect(body).To(Equal("swift_code\nBSZLPLP1XXX\n"))
+	})
+
+	It("should let the query and then the caller's key override the config", func() {
+		_, body := get("/v1/swift/BSZLPLP1WAW/headquarters?envelope=false&fields=swiftCode", "")
+		Expect(body).To(Equal(`{"bank":{"SwiftCode":"BSZLPLP1XXX"}}`))
+
+		optOut := signToken("secret", map[string]any{"sub": "legacy", "envelope": false})
+		_, body = get("/v1/swift/BSZLPLP1WAW/headquarters?fields=swiftCode", optOut)
+		Expect(body).To(Equal(`{"bank":{"SwiftCode":"BSZLPLP1XXX"}}`))
+
+		_, body = get("/v1/swift/BSZLPLP1WAW/headquarters?envelope=true&fields=swiftCode", optOut)
+		Expect(body).To(HavePrefix(`{"data":`))
+	})
+
+	Context("with the attribution in responses", func() {
+		BeforeEach(func() {
+			attr = attribution.Config{Notice: "Contains BIC directory data", URL: "https://example.com/terms", InResponses: true}
+		})
+
+		It("should add it to the meta", func() {
+			_, body := get("/v1/swift/BSZLPLP1WAW/headquarters", "")
+			Expect(decode(body).Meta).To(HaveKeyWithValue("attribution", map[string]any{
+				"notice": "Contains BIC directory data",
+				"url":    "https://example.com/terms",
+			}))
+		})
+	})
+
+	Context("when disabled in config", func() {
+		BeforeEach(func() {
+			cfg.Envelope = false
+		})
+
+		It("should wrap only for keys that ask for it", func() {
+			_, body := get("/v1/swift/BSZLPLP1WAW/headquarters", "")
+			Expect(body).To(HavePrefix(`{"bank":`))
+
+			optIn := signToken("secret", map[string]any{"sub": "acme", "envelope": true})
+			_, body = get("/v1/swift/BSZLPLP1WAW/headquarters", optIn)
+			Expect(body).To(HavePrefix(`{"data":`))
+		})
+	})
+})
+
+// signToken builds an HS256 JWT for the given claims
+func signToken(key string, claims map[string]any) string {
+	header, _ := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	"github.com/zdziszkee/swift-codes/internal/events"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+// eventsHeartbeat is how often an idle stream sends a comment, so proxies
+// keep the connection open and disconnected clients are noticed
+const eventsHeartbeat = 15 * time.Second
+
+const (
+	// defaultWatchWait is how long Watch waits for a change unless ?wait=
+	// says otherwise
+	defaultWatchWait = 30 * time.Second
+	// MaxWatchWait caps ?wait=, in seconds
+	MaxWatchWait = 60
+)
+
+// EventsHandler streams dataset changes as server-sent events and answers
+// long polls for them
+type EventsHandler struct {
+	bus *events.Bus
+	// run tells the cursors of this process apart from those of earlier
+	// runs, whose event IDs started over
+	run string
+}
+
+// NewEventsHandler creates a handler streaming the changes published on bus
+func NewEventsHandler(bus *events.Bus) *EventsHandler {
+	return &EventsHandler{bus: bus, run: strconv.FormatInt(time.Now().UnixNano(), 36)}
+}
+
+// Stream sends every create, delete and bulk load from now on as an SSE
+// event named after the change type, with the change as JSON data. The
+// stream ends when the client falls behind or the server shuts down;
+// clients should invalidate everything they cached before reconnecting.
+func (h *EventsHandler) Stream(c fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	// Disable response buffering in nginx-style proxies
+	c.Set("X-Accel-Buffering", "no")
+
+	stream, cancel := h.bus.Subscribe()
+	return c.SendStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+		heartbeat := time.NewTicker(eventsHeartbeat)
+		defer heartbeat.Stop()
+
+		fmt.Fprint(w, "retry: 3000\n\n")
+		if w.Flush() != nil {
+			return
+		}
+		for {
+			select {
+			case event, ok := <-stream:
+				if !ok {
+					return
+				}
+				data, err := json.Marshal(event.Change)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Change.Type, data)
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": keepalive\n\n")
+			}
+			// A failed flush means the client went away
+			if w.Flush() != nil {
+				return
+			}
+		}
+	})
+}
+
+// watchResponse is the body of a watch answer
+type watchResponse struct {
+	// Cursor is passed as ?since= to the next watch
+	Cursor string       `json:"cursor"`
+	Events []watchEvent `json:"events"`
+}
+
+type watchEvent struct {
+	ID         uint64    `json:"id"`
+	OccurredAt time.Time `json:"occurredAt"`
+	service.Change
+}
+
+// CheckWatchCursor reports whether v has the form of a watch cursor
+func CheckWatchCursor(v string) error {
+	_, _, err := parseWatchCursor(v)
+	return err
+}
+
+func parseWatchCursor(v string) (run string, id uint64, err error) {
+	run, rawID, ok := strings.Cut(v, ".")
+	if ok {
+		id, err = strconv.ParseUint(rawID, 10, 64)
+	}
+	if !ok || run == "" || err != nil {
+		return "", 0, errors.New("must be a cursor returned by a previous watch")
+	}
+	return run, id, nil
+}
+
+// Watch long-polls for the changes published after ?since=: the kept ones
+// are returned at once, and otherwise the request waits up to ?wait=
+// seconds for the next change. The answer carries the cursor to watch from
+// next, with no events when the wait ran out. Without since it waits for
+// the next change. A cursor whose changes are no longer kept, or that was
+// issued by another run or replica of the server, gets 410 and the client
+// has to resynchronize, as after a dropped event stream.
+func (h *EventsHandler) Watch(c fiber.Ctx) error {
+	since := h.bus.LastID()
+	if cursor := c.Query("since"); cursor != "" {
+		run, id, err := parseWatchCursor(cursor)
+		if err != nil {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid query parameters", apierror.Field("since", err.Error()))
+		}
+		if run != h.run {
+			return apierror.Write(c, fiber.StatusGone, apierror.CodeCursorExpired, "Cursor was issued by another server run; resynchronize")
+		}
+		since = id
+	}
+	wait := defaultWatchWait
+	if seconds := fiber.Query[int](c, "wait", -1); seconds >= 0 {
+		wait = time.Duration(seconds) * time.Second
+	}
+
+	kept, next, ok := h.bus.After(since)
+	if ok && len(kept) == 0 && wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-next:
+			kept, _, ok = h.bus.After(since)
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+	if !ok {
+		return apierror.Write(c, fiber.StatusGone, apierror.CodeCursorExpired, "Changes after the cursor are no longer kept; resynchronize")
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	response := watchResponse{Cursor: h.cursor(since), Events: make([]watchEvent, 0, len(kept))}
+	for _, event := range kept {
+		response.Events = append(response.Events, watchEvent{ID: event.ID, OccurredAt: event.OccurredAt, Change: event.Change})
+		response.Cursor = h.cursor(event.ID)
+	}
+	return c.JSON(response)
+}
+
+func (h *EventsHandler) cursor(id uint64) string {
+	return h.run + "." + strconv.FormatUint(id, 10)
+}
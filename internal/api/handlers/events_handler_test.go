@@ -0,0 +1,112 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	handlers "github.com/zdziszkee/swift-codes/internal/api/handlers"
+	"github.com/zdziszkee/swift-codes/internal/events"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+var _ = Describe("EventsHandler", func() {
+	It("should stream published changes until the bus closes", func() {
+		bus := events.NewBus(8, 0)
+		app := fiber.New()
+		app.Get("/events", handlers.NewEventsHandler(bus).Stream)
+
+		type result struct {
+			resp *http.Response
+			err  error
+		}
+		done := make(chan result, 1)
+		go func() {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/events", nil), fiber.TestConfig{Timeout: 5 * time.Second})
+			done <- result{resp, err}
+		}()
+
+		Eventually(bus.Subscribers).Should(Equal(1))
+		bus.Publish(context.Background(), service.Change{Type: service.ChangeCreated, SwiftCode: "PKOPPLPWXXX", CountryISO2: "PL"})
+		bus.Publish(context.Background(), service.Change{Type: service.ChangeBulkLoaded, Count: 3})
+		bus.Close()
+
+		var res result
+		Eventually(done, 5*time.Second).Should(Receive(&res))
+		Expect(res.err).NotTo(HaveOccurred())
+		Expect(res.resp.Header.Get(fiber.HeaderContentType)).To(Equal("text/event-stream"))
+		body, err := io.ReadAll(res.resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal("retry: 3000\n\n" +
+			"id: 1\nevent: swift_code.created\ndata: {\"type\":\"swift_code.created\",\"swiftCode\":\"PKOPPLPWXXX\",\"countryISO2\":\"PL\"}\n\n" +
+			"id: 2\nevent: swift_code.bulk_loaded\ndata: {\"type\":\"swift_code.bulk_loaded\",\"count\":3}\n\n"))
+	})
+
+	Describe("Watch", func() {
+		var (
+			bus *events.Bus
+			app *fiber.App
+		)
+
+		BeforeEach(func() {
+			bus = events.NewBus(8, 2)
+			app = fiber.New()
+			app.Get("/watch", handlers.NewEventsHandler(bus).Watch)
+		})
+
+		watch := func(query string) (int, map[string]any) {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/watch"+query, nil), fiber.TestConfig{Timeout: 5 * time.Second})
+			Expect(err).NotTo(HaveOccurred())
+			var body map[string]any
+			Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+			return resp.StatusCode, body
+		}
+
+		It("should return the changes after a cursor, waiting for the next one when there are none", func() {
+			status, body := watch("?wait=0")
+			Expect(status).To(Equal(fiber.StatusOK))
+			Expect(body["events"]).To(BeEmpty())
+			cursor := body["cursor"].(string)
+
+			bus.Publish(context.Background(), service.Change{Type: service.ChangeCreated, SwiftCode: "PKOPPLPWXXX", CountryISO2: "PL"})
+			status, body = watch("?since=" + cursor)
+			Expect(status).To(Equal(fiber.StatusOK))
+			Expect(body["events"]).To(ConsistOf(And(HaveKeyWithValue("id", BeNumerically("==", 1)),
+				HaveKeyWithValue("type", "swift_code.created"), HaveKeyWithValue("swiftCode", "PKOPPLPWXXX"))))
+			cursor = body["cursor"].(string)
+
+			go func() {
+				defer GinkgoRecover()
+				time.Sleep(50 * time.Millisecond)
+				bus.Publish(context.Background(), service.Change{Type: service.ChangeDeleted, SwiftCode: "PKOPPLPWXXX"})
+			}()
+			status, body = watch("?wait=5&since=" + cursor)
+			Expect(status).To(Equal(fiber.StatusOK))
+			Expect(body["events"]).To(ConsistOf(HaveKeyWithValue("type", "swift_code.deleted")))
+			Expect(body["cursor"]).NotTo(Equal(cursor))
+		})
+
+		It("should answer 410 to cursors whose changes are gone", func() {
+			_, body := watch("?wait=0")
+			cursor := body["cursor"].(string)
+			for range 3 {
+				bus.Publish(context.Background(), service.Change{Type: service.ChangeBulkLoaded})
+			}
+			status, body := watch("?since=" + cursor)
+			Expect(status).To(Equal(fiber.StatusGone))
+			Expect(body["code"]).To(Equal("CURSOR_EXPIRED"))
+
+			status, _ = watch("?since=earlier.1")
+			Expect(status).To(Equal(fiber.StatusGone))
+			status, _ = watch("?since=nonsense")
+			Expect(status).To(Equal(fiber.StatusBadRequest))
+		})
+	})
+})
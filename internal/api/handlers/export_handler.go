@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	"github.com/zdziszkee/swift-codes/internal/mirror"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+)
+
+// ExportHandler hands out downloads of the full-dataset export snapshot
+type ExportHandler struct {
+	publisher *mirror.Publisher
+}
+
+// NewExportHandler creates a handler for the snapshots of publisher
+func NewExportHandler(publisher *mirror.Publisher) *ExportHandler {
+	return &ExportHandler{publisher: publisher}
+}
+
+// ExportLink is a time-limited download of an export snapshot
+type ExportLink struct {
+	URL       string          `json:"url"`
+	ExpiresAt time.Time       `json:"expiresAt"`
+	Snapshot  mirror.Snapshot `json:"snapshot"`
+}
+
+// Latest returns a signed URL for the latest snapshot. The data itself is
+// downloaded from object storage, not through the API.
+func (h *ExportHandler) Latest(c fiber.Ctx) error {
+	snapshot, err := h.publisher.Latest()
+	if errors.Is(err, mirror.ErrNoSnapshot) {
+		return apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "No export snapshot published yet")
+	}
+	if err != nil {
+		return err
+	}
+	return c.JSON(h.link(snapshot))
+}
+
+// Publish exports the dataset now instead of waiting for the next interval
+func (h *ExportHandler) Publish(c fiber.Ctx) error {
+	snapshot, err := h.publisher.Publish(c.Context())
+	if err != nil {
+		requestid.Logf(c.Context(), "ERROR: export snapshot failed: %v", err)
+		return apierror.Write(c, fiber.StatusBadGateway, apierror.CodeUnavailable, "Export snapshot could not be published")
+	}
+	return c.Status(fiber.StatusCreated).JSON(h.link(snapshot))
+}
+
+func (h *ExportHandler) link(snapshot mirror.Snapshot) ExportLink {
+	url, expiresAt := h.publisher.SignedURL(snapshot)
+	return ExportLink{URL: url, ExpiresAt: expiresAt, Snapshot: snapshot}
+}
@@ -0,0 +1,83 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	handlers "github.com/zdziszkee/swift-codes/internal/api/handlers"
+	"github.com/zdziszkee/swift-codes/internal/attribution"
+	"github.com/zdziszkee/swift-codes/internal/mirror"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+var _ = Describe("ExportHandler", func() {
+	var (
+		app  *fiber.App
+		repo *mocks.MockSwiftRepository
+	)
+
+	BeforeEach(func() {
+		store := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		DeferCleanup(store.Close)
+
+		repo = &mocks.MockSwiftRepository{
+			ListAllFunc: func(ctx context.Context) ([]models.SwiftBank, error) {
+				return []models.SwiftBank{{SwiftCode: "PKOPPLPWXXX", CountryISOCode: "PL"}}, nil
+			},
+		}
+		publisher, err := mirror.NewPublisher(repo, mirror.Config{
+			Endpoint: store.URL, Region: "us-east-1", Bucket: "exports", PathStyle: true,
+			ObjectKey: "latest.csv", URLExpiry: time.Hour,
+		}, attribution.Config{}, time.Now)
+		Expect(err).NotTo(HaveOccurred())
+
+		h := handlers.NewExportHandler(publisher)
+		app = fiber.New()
+		app.Get("/export/latest", h.Latest)
+		app.Post("/export", h.Publish)
+	})
+
+	do := func(method, target string) *http.Response {
+		resp, err := app.Test(httptest.NewRequest(method, target, nil), fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	It("should answer 404 until a snapshot is published", func() {
+		resp := do(http.MethodGet, "/export/latest")
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		var body apierror.Error
+		Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		Expect(body.Code).To(Equal(apierror.CodeNotFound))
+	})
+
+	It("should return a signed link to the latest snapshot", func() {
+		Expect(do(http.MethodPost, "/export").StatusCode).To(Equal(http.StatusCreated))
+
+		resp := do(http.MethodGet, "/export/latest")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		var link handlers.ExportLink
+		Expect(json.NewDecoder(resp.Body).Decode(&link)).To(Succeed())
+		Expect(link.URL).To(ContainSubstring("/exports/latest.csv?X-Amz-Algorithm="))
+		Expect(link.URL).To(ContainSubstring("X-Amz-Signature="))
+		Expect(link.ExpiresAt).To(BeTemporally("~", time.Now().Add(time.Hour), 2*time.Second))
+		Expect(link.Snapshot.Rows).To(Equal(1))
+	})
+
+	It("should report failed publishes as unavailable", func() {
+		repo.ListAllFunc = func(ctx context.Context) ([]models.SwiftBank, error) {
+			return nil, errors.New("trino down")
+		}
+		Expect(do(http.MethodPost, "/export").StatusCode).To(Equal(http.StatusBadGateway))
+	})
+})
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+)
+
+// FailoverHandler reports cluster health and switches the write cluster
+type FailoverHandler struct {
+	failover *repository.Failover
+}
+
+// NewFailoverHandler creates a handler for the clusters of failover
+func NewFailoverHandler(failover *repository.Failover) *FailoverHandler {
+	return &FailoverHandler{failover: failover}
+}
+
+type setWriteClusterRequest struct {
+	Cluster string `json:"cluster"`
+}
+
+// Status returns the health of every cluster and where reads and writes go
+func (h *FailoverHandler) Status(c fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(h.failover.Status())
+}
+
+// SetWriteCluster moves writes to another cluster. Writes never fail over
+// on their own, so this is how an operator takes them off a lost primary.
+func (h *FailoverHandler) SetWriteCluster(c fiber.Ctx) error {
+	var req setWriteClusterRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid request body")
+	}
+
+	previous := h.failover.Status().WriteCluster
+	if err := h.failover.SetWriteCluster(req.Cluster); errors.Is(err, repository.ErrUnknownCluster) {
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Unknown cluster",
+			apierror.Field("cluster", "must be primary or secondary"))
+	}
+	requestid.Logf(c.Context(), "INFO: write cluster switched from %s to %s", previous, req.Cluster)
+	return c.JSON(h.failover.Status())
+}
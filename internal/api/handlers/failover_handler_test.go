@@ -0,0 +1,66 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	handlers "github.com/zdziszkee/swift-codes/internal/api/handlers"
+	"github.com/zdziszkee/swift-codes/internal/database"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+)
+
+var _ = Describe("FailoverHandler", func() {
+	var app *fiber.App
+
+	BeforeEach(func() {
+		ping := func(ctx context.Context) error { return nil }
+		h := handlers.NewFailoverHandler(repository.NewFailover(database.FailoverConfig{HealthInterval: time.Second, HealthTimeout: time.Second},
+			repository.Cluster{Name: repository.ClusterPrimary, Ping: ping},
+			repository.Cluster{Name: repository.ClusterSecondary, Ping: ping},
+		))
+		app = fiber.New()
+		app.Get("/failover", h.Status)
+		app.Put("/failover/write", h.SetWriteCluster)
+	})
+
+	setWrite := func(body string) *http.Response {
+		req := httptest.NewRequest(http.MethodPut, "/failover/write", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	It("should report both clusters and where calls go", func() {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/failover", nil), fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		var status repository.FailoverStatus
+		Expect(json.NewDecoder(resp.Body).Decode(&status)).To(Succeed())
+		Expect(status.Clusters).To(HaveLen(2))
+		Expect(status.ReadCluster).To(Equal(repository.ClusterPrimary))
+		Expect(status.WriteCluster).To(Equal(repository.ClusterPrimary))
+	})
+
+	It("should switch the write cluster", func() {
+		resp := setWrite(`{"cluster":"secondary"}`)
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		var status repository.FailoverStatus
+		Expect(json.NewDecoder(resp.Body).Decode(&status)).To(Succeed())
+		Expect(status.WriteCluster).To(Equal(repository.ClusterSecondary))
+	})
+
+	It("should reject unknown clusters", func() {
+		Expect(setWrite(`{"cluster":"eu-west"}`).StatusCode).To(Equal(http.StatusBadRequest))
+	})
+})
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FieldMask selects which SwiftBank fields are written to a response
+type FieldMask uint16
+
+// Bank fields that can be requested through ?fields=
+const (
+	FieldSwiftCode FieldMask = 1 << iota
+	FieldSwiftCodeBase
+	FieldCountryISOCode
+	FieldBankName
+	FieldIsHeadquarter
+	FieldAddress
+	FieldCountryName
+	FieldWebsite
+	FieldPhone
+
+	// AllFields is the default selection. Contact fields are only written
+	// when requested.
+	AllFields = FieldSwiftCode | FieldSwiftCodeBase | FieldCountryISOCode | FieldBankName |
+		FieldIsHeadquarter | FieldAddress | FieldCountryName
+
+	ContactFields = FieldWebsite | FieldPhone
+)
+
+// fieldNames maps normalized field names (lower case, no underscores) to
+// their mask bit, so swiftCode, SwiftCode and swift_code are all accepted
+var fieldNames = map[string]FieldMask{
+	"swiftcode":      FieldSwiftCode,
+	"swiftcodebase":  FieldSwiftCodeBase,
+	"countryisocode": FieldCountryISOCode,
+	"countryiso2":    FieldCountryISOCode,
+	"bankname":       FieldBankName,
+	"isheadquarter":  FieldIsHeadquarter,
+	"address":        FieldAddress,
+	"countryname":    FieldCountryName,
+	"website":        FieldWebsite,
+	"phone":          FieldPhone,
+	"contacts":       ContactFields,
+}
+
+// ParseFieldMask parses a comma-separated field list such as
+// "swiftCode,bankName". An empty list selects AllFields; "contacts" selects
+// website and phone.
+func ParseFieldMask(spec string) (FieldMask, error) {
+	if strings.TrimSpace(spec) == "" {
+		return AllFields, nil
+	}
+
+	var mask FieldMask
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		field, ok := fieldNames[normalizeFieldName(name)]
+		if !ok {
+			return 0, fmt.Errorf("unknown field %q", name)
+		}
+		mask |= field
+	}
+	if mask == 0 {
+		return AllFields, nil
+	}
+	return mask, nil
+}
+
+// normalizeFieldName lower-cases name and drops its underscores, the form
+// of the keys of fieldNames
+func normalizeFieldName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// Has reports whether field is selected
+func (m FieldMask) Has(field FieldMask) bool {
+	return m&field != 0
+}
+
+// csvColumns returns the CSV header and values of bank restricted to the mask
+func (m FieldMask) csvColumns(bank *BankResponse) (header, values []string) {
+	add := func(field FieldMask, name, value string) {
+		if m.Has(field) {
+			header = append(header, name)
+			values = append(values, value)
+		}
+	}
+	add(FieldSwiftCode, "swift_code", bank.SwiftCode)
+	add(FieldSwiftCodeBase, "swift_code_base", bank.SwiftCodeBase)
+	add(FieldCountryISOCode, "country_iso_code", bank.CountryISOCode)
+	add(FieldBankName, "bank_name", bank.BankName)
+	add(FieldIsHeadquarter, "is_headquarter", strconv.FormatBool(bank.IsHeadquarter))
+	add(FieldAddress, "address", bank.Address)
+	add(FieldCountryName, "country_name", bank.CountryName)
+	add(FieldWebsite, "website", bank.Website)
+	add(FieldPhone, "phone", bank.Phone)
+	for _, field := range bank.computed {
+		header = append(header, field.name)
+		values = append(values, field.value)
+	}
+	return header, values
+}
+
+// apply returns a copy of bank with every field outside the mask cleared
+func (m FieldMask) apply(bank BankResponse) BankResponse {
+	var masked BankResponse
+	if m.Has(FieldSwiftCode) {
+		masked.SwiftCode = bank.SwiftCode
+	}
+	if m.Has(FieldSwiftCodeBase) {
+		masked.SwiftCodeBase = bank.SwiftCodeBase
+	}
+	if m.Has(FieldCountryISOCode) {
+		masked.CountryISOCode = bank.CountryISOCode
+	}
+	if m.Has(FieldBankName) {
+		masked.BankName = bank.BankName
+	}
+	if m.Has(FieldIsHeadquarter) {
+		masked.IsHeadquarter = bank.IsHeadquarter
+	}
+	if m.Has(FieldAddress) {
+		masked.Address = bank.Address
+	}
+	if m.Has(FieldCountryName) {
+		masked.CountryName = bank.CountryName
+	}
+	if m.Has(FieldWebsite) {
+		masked.Website = bank.Website
+	}
+	if m.Has(FieldPhone) {
+		masked.Phone = bank.Phone
+	}
+	return masked
+}
@@ -0,0 +1,12 @@
+package handlers
+
+import "github.com/gofiber/fiber/v3"
+
+// Ping answers liveness probes such as container HEALTHCHECKs. It touches
+// no dependency, so it reports the process as up whatever the state of
+// Trino; use /v1/dataset/status, which answers 503 while the startup
+// import runs, to check that data is served.
+func Ping(c fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.SendString("pong")
+}
@@ -0,0 +1,28 @@
+package handlers_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	handlers "github.com/zdziszkee/swift-codes/internal/api/handlers"
+)
+
+var _ = Describe("Ping", func() {
+	It("should answer without touching the service", func() {
+		app := fiber.New()
+		app.Get("/ping", handlers.Ping)
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/ping", nil), fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get(fiber.HeaderCacheControl)).To(Equal("no-store"))
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal("pong"))
+	})
+})
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	"github.com/zdziszkee/swift-codes/internal/database"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+)
+
+// MaintenanceHandler runs Iceberg table maintenance procedures on request
+type MaintenanceHandler struct {
+	db      *database.Database
+	running sync.Mutex
+}
+
+// NewMaintenanceHandler creates a handler that maintains the table of db
+func NewMaintenanceHandler(db *database.Database) *MaintenanceHandler {
+	return &MaintenanceHandler{db: db}
+}
+
+// MaintenanceResult is the summary returned by Run
+type MaintenanceResult struct {
+	Task database.MaintenanceTask `json:"task"`
+	// Retention is empty for an optimize run that expired no snapshots
+	Retention string `json:"retention,omitempty"`
+	// ExpiredSnapshots is set when optimize was followed by expire_snapshots
+	ExpiredSnapshots bool  `json:"expired_snapshots,omitempty"`
+	DurationMs       int64 `json:"duration_ms"`
+}
+
+// Run executes the maintenance task named in the path. ?retention= takes a
+// Go duration and defaults to the configured minimum. optimize accepts
+// ?expireSnapshots=true to expire the snapshots it leaves behind with that
+// retention. Only one task runs at a time.
+func (h *MaintenanceHandler) Run(c fiber.Ctx) error {
+	task, err := database.ParseMaintenanceTask(c.Params("task"))
+	if err != nil {
+		return apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "Unknown maintenance task")
+	}
+
+	var retention time.Duration
+	if raw := c.Query("retention"); raw != "" {
+		retention, err = time.ParseDuration(raw)
+		if err != nil || retention <= 0 {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid input provided",
+				apierror.Field("retention", "must be a positive duration such as 168h"))
+		}
+	}
+
+	var expire bool
+	if raw := c.Query("expireSnapshots"); raw != "" {
+		expire, err = strconv.ParseBool(raw)
+		if err != nil || (expire && task != database.TaskOptimize) {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid input provided",
+				apierror.Field("expireSnapshots", "must be true or false, and only applies to optimize"))
+		}
+	}
+	// Refuse before compacting rather than after, when expiring would fail
+	if expire && retention != 0 && retention < h.db.Config.Maintenance.MinRetention {
+		return h.retentionTooShort(c)
+	}
+
+	if !h.running.TryLock() {
+		return apierror.Write(c, fiber.StatusConflict, apierror.CodeConflict, "Maintenance already in progress")
+	}
+	defer h.running.Unlock()
+
+	start := time.Now()
+	result := MaintenanceResult{Task: task}
+	ran, err := h.db.RunMaintenance(c.Context(), task, retention)
+	if err == nil && expire {
+		result.ExpiredSnapshots = true
+		ran, err = h.db.RunMaintenance(c.Context(), database.TaskExpireSnapshots, retention)
+	}
+	switch {
+	case errors.Is(err, database.ErrRetentionTooShort):
+		return h.retentionTooShort(c)
+	case err != nil:
+		requestid.Logf(c.Context(), "ERROR: maintenance %s failed: %v", task, err)
+		return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Internal server error")
+	}
+
+	if ran > 0 {
+		result.Retention = ran.String()
+	}
+	result.DurationMs = time.Since(start).Milliseconds()
+	return c.Status(fiber.StatusOK).JSON(result)
+}
+
+// retentionTooShort answers a retention below the configured minimum
+func (h *MaintenanceHandler) retentionTooShort(c fiber.Ctx) error {
+	minimum := h.db.Config.Maintenance.MinRetention.String()
+	return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Retention is below the configured minimum of "+minimum,
+		apierror.Field("retention", "must be at least "+minimum))
+}
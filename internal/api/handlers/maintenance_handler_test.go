@@ -0,0 +1,102 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	handlers "github.com/zdziszkee/swift-codes/internal/api/handlers"
+	"github.com/zdziszkee/swift-codes/internal/database"
+)
+
+var _ = Describe("MaintenanceHandler", func() {
+	var (
+		app    *fiber.App
+		mockDB sqlmock.Sqlmock
+	)
+
+	BeforeEach(func() {
+		db, mock, err := sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() { _ = db.Close() })
+		mockDB = mock
+
+		h := handlers.NewMaintenanceHandler(&database.Database{DB: db, Config: database.Config{
+			Catalog:     "swift_catalog",
+			Schema:      "default_schema",
+			TableName:   "swift_banks",
+			Maintenance: database.MaintenanceConfig{MinRetention: 7 * 24 * time.Hour},
+		}})
+		app = fiber.New()
+		app.Post("/maintenance/:task", h.Run)
+	})
+
+	run := func(target string) *http.Response {
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, target, nil), fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	It("should run the task with the minimum retention by default", func() {
+		mockDB.ExpectExec(`ALTER TABLE swift_catalog\.default_schema\.swift_banks EXECUTE remove_orphan_files\(retention_threshold => '604800s'\)`).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		resp := run("/maintenance/remove_orphan_files")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		var result handlers.MaintenanceResult
+		Expect(json.NewDecoder(resp.Body).Decode(&result)).To(Succeed())
+		Expect(result.Task).To(Equal(database.TaskRemoveOrphanFiles))
+		Expect(result.Retention).To(Equal("168h0m0s"))
+		Expect(mockDB.ExpectationsWereMet()).To(Succeed())
+	})
+
+	It("should accept a longer retention", func() {
+		mockDB.ExpectExec(`EXECUTE expire_snapshots\(retention_threshold => '1209600s'\)`).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		Expect(run("/maintenance/expire_snapshots?retention=336h").StatusCode).To(Equal(http.StatusOK))
+		Expect(mockDB.ExpectationsWereMet()).To(Succeed())
+	})
+
+	It("should refuse a retention inside the safety window", func() {
+		Expect(run("/maintenance/remove_orphan_files?retention=1h").StatusCode).To(Equal(http.StatusBadRequest))
+		Expect(mockDB.ExpectationsWereMet()).To(Succeed())
+	})
+
+	It("should optimize the table and optionally expire snapshots afterwards", func() {
+		mockDB.ExpectExec(`ALTER TABLE swift_catalog\.default_schema\.swift_banks EXECUTE optimize$`).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		resp := run("/maintenance/optimize")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		var result handlers.MaintenanceResult
+		Expect(json.NewDecoder(resp.Body).Decode(&result)).To(Succeed())
+		Expect(result).To(Equal(handlers.MaintenanceResult{Task: database.TaskOptimize, DurationMs: result.DurationMs}))
+
+		mockDB.ExpectExec(`EXECUTE optimize$`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mockDB.ExpectExec(`EXECUTE expire_snapshots\(retention_threshold => '1209600s'\)`).WillReturnResult(sqlmock.NewResult(0, 0))
+		resp = run("/maintenance/optimize?expireSnapshots=true&retention=336h")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(json.NewDecoder(resp.Body).Decode(&result)).To(Succeed())
+		Expect(result.ExpiredSnapshots).To(BeTrue())
+		Expect(result.Retention).To(Equal("336h0m0s"))
+		Expect(mockDB.ExpectationsWereMet()).To(Succeed())
+	})
+
+	It("should not compact when the snapshot expiry would be refused", func() {
+		Expect(run("/maintenance/optimize?expireSnapshots=true&retention=1h").StatusCode).To(Equal(http.StatusBadRequest))
+		Expect(run("/maintenance/expire_snapshots?expireSnapshots=true").StatusCode).To(Equal(http.StatusBadRequest))
+		Expect(mockDB.ExpectationsWereMet()).To(Succeed())
+	})
+
+	It("should reject unknown tasks and malformed retentions", func() {
+		Expect(run("/maintenance/drop_table").StatusCode).To(Equal(http.StatusNotFound))
+		Expect(run("/maintenance/expire_snapshots?retention=soon").StatusCode).To(Equal(http.StatusBadRequest))
+	})
+})
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+// MaintenanceScheduleHandler lets operators list, schedule and cancel the
+// maintenance windows during which the service is read-only
+type MaintenanceScheduleHandler struct {
+	schedule *service.MaintenanceSchedule
+}
+
+// NewMaintenanceScheduleHandler creates a handler managing schedule
+func NewMaintenanceScheduleHandler(schedule *service.MaintenanceSchedule) *MaintenanceScheduleHandler {
+	return &MaintenanceScheduleHandler{schedule: schedule}
+}
+
+// List returns the windows that have not ended and the active one, if any
+func (h *MaintenanceScheduleHandler) List(c fiber.Ctx) error {
+	windows := h.schedule.Windows()
+	if windows == nil {
+		windows = []service.MaintenanceWindow{}
+	}
+	response := fiber.Map{"windows": windows}
+	if active, ok := h.schedule.Active(); ok {
+		response["active"] = active
+	}
+	return c.JSON(response)
+}
+
+// Schedule adds the window in the body, {start, end, reason} with RFC 3339
+// times. Windows scheduled here are lost on restart.
+func (h *MaintenanceScheduleHandler) Schedule(c fiber.Ctx) error {
+	var window service.MaintenanceWindow
+	if err := c.Bind().Body(&window); err != nil {
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid request body")
+	}
+	window.ID = ""
+
+	scheduled, err := h.schedule.Schedule(window)
+	if err != nil {
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid maintenance window",
+			apierror.Detail{Reason: err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(scheduled)
+}
+
+// Cancel removes the window in the :id parameter, ending it early when it
+// is active
+func (h *MaintenanceScheduleHandler) Cancel(c fiber.Ctx) error {
+	if err := h.schedule.Cancel(c.Params("id")); err != nil {
+		if errors.Is(err, service.ErrWindowNotFound) {
+			return apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "Maintenance window not found")
+		}
+		return err
+	}
+	return c.JSON(fiber.Map{"message": "Maintenance window cancelled"})
+}
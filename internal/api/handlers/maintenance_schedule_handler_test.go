@@ -0,0 +1,82 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	handlers "github.com/zdziszkee/swift-codes/internal/api/handlers"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+var _ = Describe("MaintenanceScheduleHandler", func() {
+	var app *fiber.App
+
+	BeforeEach(func() {
+		h := handlers.NewMaintenanceScheduleHandler(service.NewMaintenanceSchedule(nil))
+		app = fiber.New()
+		app.Get("/maintenance-windows", h.List)
+		app.Post("/maintenance-windows", h.Schedule)
+		app.Delete("/maintenance-windows/:id", h.Cancel)
+	})
+
+	do := func(method, target, body string) *http.Response {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := app.Test(req, fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	window := func(start, end time.Time) string {
+		return fmt.Sprintf(`{"start":%q,"end":%q,"reason":"upgrade"}`, start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+
+	It("should schedule a window and report it as active", func() {
+		resp := do(http.MethodPost, "/maintenance-windows", window(time.Now().Add(-time.Minute), time.Now().Add(time.Hour)))
+		Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+		var scheduled service.MaintenanceWindow
+		Expect(json.NewDecoder(resp.Body).Decode(&scheduled)).To(Succeed())
+		Expect(scheduled.ID).NotTo(BeEmpty())
+		Expect(scheduled.Reason).To(Equal("upgrade"))
+
+		resp = do(http.MethodGet, "/maintenance-windows", "")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		var listed struct {
+			Windows []service.MaintenanceWindow `json:"windows"`
+			Active  *service.MaintenanceWindow  `json:"active"`
+		}
+		Expect(json.NewDecoder(resp.Body).Decode(&listed)).To(Succeed())
+		Expect(listed.Windows).To(HaveLen(1))
+		Expect(listed.Active).NotTo(BeNil())
+		Expect(listed.Active.ID).To(Equal(scheduled.ID))
+	})
+
+	It("should reject windows that end before they start", func() {
+		resp := do(http.MethodPost, "/maintenance-windows", window(time.Now().Add(time.Hour), time.Now()))
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		var body apierror.Error
+		Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		Expect(body.Details).To(HaveLen(1))
+		Expect(body.Details[0].Reason).To(ContainSubstring("must end after its start"))
+	})
+
+	It("should cancel windows and report unknown IDs", func() {
+		resp := do(http.MethodPost, "/maintenance-windows", window(time.Now().Add(time.Hour), time.Now().Add(2*time.Hour)))
+		var scheduled service.MaintenanceWindow
+		Expect(json.NewDecoder(resp.Body).Decode(&scheduled)).To(Succeed())
+
+		Expect(do(http.MethodDelete, "/maintenance-windows/"+scheduled.ID, "").StatusCode).To(Equal(http.StatusOK))
+		Expect(do(http.MethodDelete, "/maintenance-windows/"+scheduled.ID, "").StatusCode).To(Equal(http.StatusNotFound))
+	})
+})
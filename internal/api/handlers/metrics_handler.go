@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+	"github.com/zdziszkee/swift-codes/internal/importer"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+)
+
+// prometheusContentType is the Prometheus text exposition format
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// MetricsHandler exposes import health in the Prometheus text format, so
+// staleness and failure alerts can be defined without scraping logs
+type MetricsHandler struct {
+	importer *importer.Importer
+	scraping *middleware.ScrapeGuard
+	cache    *repository.Metrics
+}
+
+// NewMetricsHandler creates a handler reporting the runs of imp
+func NewMetricsHandler(imp *importer.Importer) *MetricsHandler {
+	return &MetricsHandler{importer: imp}
+}
+
+// ReportScraping adds the detections of guard to the metrics
+func (h *MetricsHandler) ReportScraping(guard *middleware.ScrapeGuard) {
+	h.scraping = guard
+}
+
+// ReportCache adds the repository cache counters of metrics to the metrics
+func (h *MetricsHandler) ReportCache(metrics *repository.Metrics) {
+	h.cache = metrics
+}
+
+// Metrics writes the import gauges and counters, and the scraping and cache
+// ones when reported
+func (h *MetricsHandler) Metrics(c fiber.Ctx) error {
+	counters := h.importer.Counters()
+	var lastSuccess float64
+	if !counters.LastSuccess.IsZero() {
+		lastSuccess = float64(counters.LastSuccess.UnixMilli()) / 1000
+	}
+
+	var b strings.Builder
+	writeMetric(&b, "swift_codes_last_import_success_timestamp_seconds", "gauge",
+		"Unix time of the last successful import, 0 if none succeeded since the process started.", lastSuccess)
+	writeMetric(&b, "swift_codes_last_import_reject_count", "gauge",
+		"Rows rejected by the parser in the most recent import.", float64(counters.LastSkipped))
+	writeMetric(&b, "swift_codes_consecutive_import_failures", "gauge",
+		"Imports that failed since the last successful one.", float64(counters.ConsecutiveFailures))
+	writeMetric(&b, "swift_codes_imports_total", "counter",
+		"Imports finished since the process started.", float64(counters.Runs))
+	writeMetric(&b, "swift_codes_import_failures_total", "counter",
+		"Imports failed since the process started.", float64(counters.Failures))
+	if h.scraping != nil {
+		stats := h.scraping.Stats()
+		writeMetric(&b, "swift_codes_scraping_detections_total", "counter",
+			"Clients flagged for enumerating SWIFT codes since the process started.", float64(stats.Detections))
+		writeMetric(&b, "swift_codes_scraping_rejected_total", "counter",
+			"Lookups refused to flagged clients since the process started.", float64(stats.Rejected))
+		writeMetric(&b, "swift_codes_scraping_flagged_clients", "gauge",
+			"Clients currently flagged for enumerating SWIFT codes.", float64(stats.Flagged))
+	}
+	if h.cache != nil {
+		stats := h.cache.Cache()
+		writeMetric(&b, "swift_codes_cache_hits_total", "counter",
+			"Repository reads answered by the in-process cache.", float64(stats.Hits))
+		writeMetric(&b, "swift_codes_cache_misses_total", "counter",
+			"Repository reads the in-process cache could not answer.", float64(stats.Misses))
+		writeMetric(&b, "swift_codes_cache_evictions_total", "counter",
+			"Cache entries evicted to stay within repository.cache_max_entries.", float64(stats.Evictions))
+		writeMetric(&b, "swift_codes_cache_entries", "gauge",
+			"Entries held by the in-process cache.", float64(stats.Entries))
+	}
+
+	c.Set(fiber.HeaderContentType, prometheusContentType)
+	return c.Status(fiber.StatusOK).SendString(b.String())
+}
+
+// writeMetric appends one unlabelled sample with its HELP and TYPE lines
+func writeMetric(b *strings.Builder, name, kind, help string, value float64) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n%s %s\n", name, help, name, kind, name, strconv.FormatFloat(value, 'f', -1, 64))
+}
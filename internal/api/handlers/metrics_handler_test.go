@@ -0,0 +1,78 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	handlers "github.com/zdziszkee/swift-codes/internal/api/handlers"
+	"github.com/zdziszkee/swift-codes/internal/importer"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+var _ = Describe("MetricsHandler", func() {
+	var (
+		repo *mocks.MockSwiftRepository
+		imp  *importer.Importer
+	)
+
+	BeforeEach(func() {
+		repo = &mocks.MockSwiftRepository{
+			CreateBatchFunc: func(ctx context.Context, banks []*models.SwiftBank) error { return nil },
+		}
+		imp = importer.NewImporter(repo, importer.GoldenConfig{})
+	})
+
+	scrape := func() (*http.Response, string) {
+		app := fiber.New()
+		app.Get("/metrics", handlers.NewMetricsHandler(imp).Metrics)
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/metrics", nil), fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return resp, string(body)
+	}
+
+	It("should report zero values before any import", func() {
+		resp, body := scrape()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get(fiber.HeaderContentType)).To(HavePrefix("text/plain; version=0.0.4"))
+		Expect(body).To(ContainSubstring("# TYPE swift_codes_last_import_success_timestamp_seconds gauge\n"))
+		Expect(body).To(ContainSubstring("\nswift_codes_last_import_success_timestamp_seconds 0\n"))
+		Expect(body).To(ContainSubstring("\nswift_codes_consecutive_import_failures 0\n"))
+		Expect(body).To(ContainSubstring("# TYPE swift_codes_import_failures_total counter\n"))
+	})
+
+	It("should count consecutive failures until the next success", func() {
+		_, err := imp.Import(context.Background(), strings.NewReader(reloadCSV))
+		Expect(err).NotTo(HaveOccurred())
+		repo.CreateBatchFunc = func(ctx context.Context, banks []*models.SwiftBank) error {
+			return errors.New("db error")
+		}
+		for range 2 {
+			_, err = imp.Import(context.Background(), strings.NewReader(reloadCSV))
+			Expect(err).To(HaveOccurred())
+		}
+
+		_, body := scrape()
+		Expect(body).To(ContainSubstring("\nswift_codes_consecutive_import_failures 2\n"))
+		Expect(body).To(ContainSubstring("\nswift_codes_last_import_reject_count 1\n"))
+		Expect(body).To(ContainSubstring("\nswift_codes_imports_total 3\n"))
+		Expect(body).To(ContainSubstring("\nswift_codes_import_failures_total 2\n"))
+		Expect(body).NotTo(ContainSubstring("\nswift_codes_last_import_success_timestamp_seconds 0\n"))
+
+		repo.CreateBatchFunc = func(ctx context.Context, banks []*models.SwiftBank) error { return nil }
+		_, err = imp.Import(context.Background(), strings.NewReader(reloadCSV))
+		Expect(err).NotTo(HaveOccurred())
+		_, body = scrape()
+		Expect(body).To(ContainSubstring("\nswift_codes_consecutive_import_failures 0\n"))
+	})
+})
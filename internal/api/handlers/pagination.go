@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+// defaultMaxPageSize applies when no MaxPageSize is configured
+const defaultMaxPageSize = 1000
+
+// HeaderTotalCount carries the number of items matching a list request
+const HeaderTotalCount = "X-Total-Count"
+
+// HeaderNextCursor carries the ?after= value of the next page of a list
+// ordered by SWIFT code
+const HeaderNextCursor = "X-Next-Cursor"
+
+// defaultMaxEmbeddedBranches applies when no MaxEmbeddedBranches is configured
+const defaultMaxEmbeddedBranches = 100
+
+// PageSizeLimit returns the largest ?limit= of list endpoints
+func (c Config) PageSizeLimit() int {
+	if c.MaxPageSize <= 0 {
+		return defaultMaxPageSize
+	}
+	return c.MaxPageSize
+}
+
+// EmbeddedBranchLimit returns the most branches a detail response embeds
+func (c Config) EmbeddedBranchLimit() int {
+	if c.MaxEmbeddedBranches <= 0 {
+		return defaultMaxEmbeddedBranches
+	}
+	return c.MaxEmbeddedBranches
+}
+
+// parsePage reads ?limit= and ?offset=. A missing limit means no limit.
+func (h *SwiftHandler) parsePage(c fiber.Ctx) (limit, offset int, ok bool) {
+	return parseWindow(c, "limit", "offset", h.config.PageSizeLimit())
+}
+
+// parseWindow reads a limit of at most maxLimit and an offset from the
+// named query parameters
+func parseWindow(c fiber.Ctx, limitParam, offsetParam string, maxLimit int) (limit, offset int, ok bool) {
+	if raw := c.Query(limitParam); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxLimit {
+			return 0, 0, false
+		}
+		limit = n
+	}
+	if raw := c.Query(offsetParam); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return 0, 0, false
+		}
+		offset = n
+	}
+	return limit, offset, true
+}
+
+// setPaginationHeaders emits X-Total-Count and, for limited requests, an
+// RFC 5988 Link header with first, prev, next and last relations. The links
+// keep every other query parameter of the current request. It also records
+// the window for the meta of an enveloped response.
+func setPaginationHeaders(c fiber.Ctx, total, limit, offset int) {
+	c.Locals(listMetaKey, &Meta{Total: total, Limit: limit, Offset: offset})
+	c.Set(HeaderTotalCount, strconv.Itoa(total))
+	if limit <= 0 {
+		return
+	}
+
+	query, err := url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return
+	}
+	base := c.BaseURL() + c.Path()
+	link := func(rel string, offset int) string {
+		query.Set("limit", strconv.Itoa(limit))
+		query.Set("offset", strconv.Itoa(offset))
+		return "<" + base + "?" + query.Encode() + `>; rel="` + rel + `"`
+	}
+
+	last := 0
+	if total > 0 {
+		last = (total - 1) / limit * limit
+	}
+
+	links := []string{link("first", 0)}
+	if offset > 0 {
+		links = append(links, link("prev", max(offset-limit, 0)))
+	}
+	if offset+limit < total {
+		links = append(links, link("next", offset+limit))
+	}
+	links = append(links, link("last", last))
+	c.Set(fiber.HeaderLink, strings.Join(links, ", "))
+}
+
+// setListHeaders emits the pagination headers of a country listing read
+// with opts. A full page ordered by SWIFT code also carries X-Next-Cursor,
+// and a page read with ?after= links to the next one by cursor rather than
+// by offset.
+func setListHeaders(c fiber.Ctx, total int, opts repository.QueryOptions, page []models.SwiftBank) {
+	var next string
+	if opts.Limit > 0 && len(page) == opts.Limit && (opts.After != "" || opts.Sort.Field == repository.SortSwiftCode) {
+		next = page[len(page)-1].SwiftCode
+		c.Set(HeaderNextCursor, next)
+	}
+	version, _ := middleware.CountryVersion(c)
+	if opts.After == "" {
+		setPaginationHeaders(c, total, opts.Limit, opts.Offset)
+		if meta, ok := c.Locals(listMetaKey).(*Meta); ok {
+			meta.NextCursor, meta.Version = next, version
+		}
+		return
+	}
+
+	c.Locals(listMetaKey, &Meta{Total: total, Limit: opts.Limit, NextCursor: next, Version: version})
+	c.Set(HeaderTotalCount, strconv.Itoa(total))
+	query, err := url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return
+	}
+	base := c.BaseURL() + c.Path()
+	link := func(rel, after string) string {
+		query.Del("offset")
+		query.Set("after", after)
+		if after == "" {
+			query.Del("after")
+		}
+		return "<" + base + "?" + query.Encode() + `>; rel="` + rel + `"`
+	}
+	links := []string{link("first", "")}
+	if next != "" {
+		links = append(links, link("next", next))
+	}
+	c.Set(fiber.HeaderLink, strings.Join(links, ", "))
+}
+
+// parseBranchPage reads ?branchLimit= and ?branchOffset= of a detail
+// request. paged is false when neither is given; a missing limit defaults
+// to the embedded branch cap, which also bounds it.
+func (h *SwiftHandler) parseBranchPage(c fiber.Ctx) (limit, offset int, paged, ok bool) {
+	if c.Query("branchLimit") == "" && c.Query("branchOffset") == "" {
+		return 0, 0, false, true
+	}
+	limit, offset, ok = parseWindow(c, "branchLimit", "branchOffset", h.config.EmbeddedBranchLimit())
+	if limit == 0 {
+		limit = h.config.EmbeddedBranchLimit()
+	}
+	return limit, offset, true, ok
+}
+
+// embeddedBranches asks the service for no more branches than a detail
+// response embeds, so very large headquarters stay small
+func (h *SwiftHandler) embeddedBranches(c fiber.Ctx) context.Context {
+	return service.WithBranchWindow(c.Context(), h.config.EmbeddedBranchLimit(), 0)
+}
+
+// branchesLink returns, when detail holds only the first of its branches, a
+// link to the next page of the branches endpoint. The page is no larger
+// than that endpoint accepts, even when more branches are embedded.
+func (h *SwiftHandler) branchesLink(c fiber.Ctx, detail *repository.SwiftBankDetail) string {
+	if detail.BranchesTotal <= len(detail.Branches) {
+		return ""
+	}
+	embedded := h.config.EmbeddedBranchLimit()
+	limit := min(embedded, h.config.PageSizeLimit())
+	return c.BaseURL() + strings.TrimSuffix(c.Path(), "/") + "/branches?limit=" + strconv.Itoa(limit) + "&offset=" + strconv.Itoa(embedded)
+}
+
+// swiftCodeResponse maps detail, read with embeddedBranches, to its v1
+// payload
+func (h *SwiftHandler) swiftCodeResponse(c fiber.Ctx, detail *repository.SwiftBankDetail) *SwiftCodeResponse {
+	resp := NewSwiftCodeResponse(detail)
+	if link := h.branchesLink(c, detail); link != "" {
+		resp.BranchesTotal = detail.BranchesTotal
+		resp.BranchesLink = link
+	}
+	return resp
+}
+
+// pagedSwiftCodeResponse maps detail, read with the window of branches
+// selected by limit and offset, to its v1 payload with the branch count
+// and, while more branches follow, a link to the next window of the same
+// detail route
+func pagedSwiftCodeResponse(c fiber.Ctx, detail *repository.SwiftBankDetail, limit, offset int) *SwiftCodeResponse {
+	resp := NewSwiftCodeResponse(detail)
+	resp.BranchesTotal = detail.BranchesTotal
+	if offset+limit >= detail.BranchesTotal {
+		return resp
+	}
+
+	query, err := url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return resp
+	}
+	query.Set("branchLimit", strconv.Itoa(limit))
+	query.Set("branchOffset", strconv.Itoa(offset+limit))
+	resp.BranchesLink = c.BaseURL() + c.Path() + "?" + query.Encode()
+	return resp
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	"github.com/zdziszkee/swift-codes/internal/database"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+)
+
+// QueryHandler runs vetted analytical query templates for analysts. It
+// never accepts SQL, only a template name and parameter values.
+type QueryHandler struct {
+	db *database.Database
+}
+
+// NewQueryHandler creates a handler that queries the table of db
+func NewQueryHandler(db *database.Database) *QueryHandler {
+	return &QueryHandler{db: db}
+}
+
+// Templates lists the available templates and their parameters
+func (h *QueryHandler) Templates(c fiber.Ctx) error {
+	return c.JSON(fiber.Map{"templates": database.QueryTemplates()})
+}
+
+// Run executes the template named in the path with the query string as its
+// parameters and returns the rows together with column metadata
+func (h *QueryHandler) Run(c fiber.Ctx) error {
+	result, err := h.db.RunTemplate(c.Context(), c.Params("template"), c.Queries())
+	var paramErr *database.ParamError
+	switch {
+	case errors.Is(err, database.ErrUnknownTemplate):
+		return apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "Unknown query template")
+	case errors.As(err, &paramErr):
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid input provided",
+			apierror.Field(paramErr.Param, paramErr.Reason))
+	case err != nil:
+		requestid.Logf(c.Context(), "ERROR: query template %s failed: %v", c.Params("template"), err)
+		return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Internal server error")
+	}
+	return c.JSON(result)
+}
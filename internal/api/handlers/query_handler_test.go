@@ -0,0 +1,79 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	handlers "github.com/zdziszkee/swift-codes/internal/api/handlers"
+	"github.com/zdziszkee/swift-codes/internal/database"
+)
+
+var _ = Describe("QueryHandler", func() {
+	var (
+		app    *fiber.App
+		mockDB sqlmock.Sqlmock
+	)
+
+	BeforeEach(func() {
+		db, mock, err := sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() { _ = db.Close() })
+		mockDB = mock
+
+		h := handlers.NewQueryHandler(&database.Database{DB: db, Config: database.Config{
+			Catalog: "swift_catalog", Schema: "default_schema", TableName: "swift_banks",
+		}})
+		app = fiber.New()
+		app.Get("/templates", h.Templates)
+		app.Get("/templates/:template", h.Run)
+	})
+
+	get := func(target string) *http.Response {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, target, nil), fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	It("should list the templates with their parameters", func() {
+		resp := get("/templates")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		var body struct {
+			Templates []database.QueryTemplate `json:"templates"`
+		}
+		Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		Expect(body.Templates).To(ContainElement(HaveField("Name", "codes_per_country")))
+	})
+
+	It("should return rows with column metadata", func() {
+		mockDB.ExpectQuery(`GROUP BY country_iso_code, country_name .*LIMIT 2`).
+			WillReturnRows(sqlmock.NewRows([]string{"country_iso_code", "country_name", "codes", "headquarters"}).
+				AddRow("PL", "POLAND", int64(120), int64(30)))
+
+		resp := get("/templates/codes_per_country?limit=2")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		var result database.QueryResult
+		Expect(json.NewDecoder(resp.Body).Decode(&result)).To(Succeed())
+		Expect(result.Template).To(Equal("codes_per_country"))
+		Expect(result.Columns).To(HaveLen(4))
+		Expect(result.Rows).To(Equal([][]any{{"PL", "POLAND", float64(120), float64(30)}}))
+	})
+
+	It("should answer 404 for unknown templates", func() {
+		Expect(get("/templates/raw_sql").StatusCode).To(Equal(http.StatusNotFound))
+	})
+
+	It("should point at the invalid parameter", func() {
+		resp := get("/templates/codes_per_country?limit=0")
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		var body apierror.Error
+		Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		Expect(body.Details).To(ConsistOf(apierror.Detail{Field: "limit", Reason: "must be an integer between 1 and 1000"}))
+	})
+})
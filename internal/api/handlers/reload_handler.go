@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	"github.com/zdziszkee/swift-codes/internal/importer"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+// ReloadHandler re-runs the CSV load pipeline on request
+type ReloadHandler struct {
+	importer *importer.Importer
+	path     string
+	running  sync.Mutex
+}
+
+// NewReloadHandler creates a handler that reloads the SWIFT codes file at
+// path through imp
+func NewReloadHandler(imp *importer.Importer, path string) *ReloadHandler {
+	return &ReloadHandler{importer: imp, path: path}
+}
+
+// ReloadResult is the job summary returned by Reload
+type ReloadResult struct {
+	File string `json:"file"`
+	importer.Summary
+	DurationMs int64 `json:"duration_ms"`
+}
+
+// reloadError is the error body of a failed reload, which still reports
+// what was loaded
+type reloadError struct {
+	*apierror.Error
+	Summary ReloadResult `json:"summary"`
+}
+
+// Reload loads the configured SWIFT codes file again without restarting
+// the process. Only one reload runs at a time, and none during maintenance.
+func (h *ReloadHandler) Reload(c fiber.Ctx) error {
+	if h.path == "" {
+		return apierror.Write(c, fiber.StatusConflict, apierror.CodeConflict, "No SWIFT codes file configured")
+	}
+	if !h.running.TryLock() {
+		return apierror.Write(c, fiber.StatusConflict, apierror.CodeConflict, "Reload already in progress")
+	}
+	defer h.running.Unlock()
+
+	requestid.Logf(c.Context(), "Reloading SWIFT codes from %s", h.path)
+	start := time.Now()
+	summary, err := h.importer.RunFile(c.Context(), h.path)
+	result := ReloadResult{File: h.path, Summary: summary, DurationMs: time.Since(start).Milliseconds()}
+	if errors.Is(err, service.ErrReadOnly) {
+		return handleError(c, err)
+	}
+	if err != nil {
+		requestid.Logf(c.Context(), "ERROR: reload of %s failed: %v", h.path, err)
+		body := reloadError{Error: apierror.New(c, apierror.CodeInternal, "Internal server error"), Summary: result}
+		if errors.Is(err, importer.ErrGoldenMismatch) {
+			body.Error = apierror.New(c, apierror.CodeGoldenMismatch, "Golden dataset check failed")
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(body)
+	}
+
+	requestid.Logf(c.Context(), "Reloaded %d SWIFT codes from %s (%d skipped)", summary.Loaded, h.path, summary.Skipped)
+	return c.Status(fiber.StatusOK).JSON(result)
+}
+
+// History lists the most recent import runs, newest first
+func (h *ReloadHandler) History(c fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"imports": h.importer.History(),
+	})
+}
@@ -0,0 +1,109 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	handlers "github.com/zdziszkee/swift-codes/internal/api/handlers"
+	"github.com/zdziszkee/swift-codes/internal/importer"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+const reloadCSV = `COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE
+PL,PKOPPLPWXXX,BIC11,BANK PEKAO SA,"ZUBRA 1 WARSZAWA, 01-066",WARSZAWA,POLAND,Europe/Warsaw
+PL,BAD,BIC11,BANK PEKAO SA,"DLUGA 1 WARSZAWA, 01-066",WARSZAWA,POLAND,Europe/Warsaw
+`
+
+var _ = Describe("ReloadHandler", func() {
+	var (
+		repo *mocks.MockSwiftRepository
+		path string
+	)
+
+	BeforeEach(func() {
+		repo = &mocks.MockSwiftRepository{
+			CreateBatchFunc: func(ctx context.Context, banks []*models.SwiftBank) error { return nil },
+		}
+		path = filepath.Join(GinkgoT().TempDir(), "swift_codes.csv")
+		Expect(os.WriteFile(path, []byte(reloadCSV), 0o644)).To(Succeed())
+	})
+
+	reload := func(h *handlers.ReloadHandler) *http.Response {
+		app := fiber.New()
+		app.Post("/reload", h.Reload)
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/reload", nil), fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	It("should reload the configured file and summarize the run", func() {
+		resp := reload(handlers.NewReloadHandler(importer.NewImporter(repo, importer.GoldenConfig{}), path))
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		var result handlers.ReloadResult
+		Expect(json.NewDecoder(resp.Body).Decode(&result)).To(Succeed())
+		Expect(result.File).To(Equal(path))
+		Expect(result.Summary).To(Equal(importer.Summary{Rows: 2, Loaded: 1, Skipped: 1, Format: "csv"}))
+	})
+
+	It("should refuse to reload during a maintenance window", func() {
+		stored := 0
+		repo.CreateBatchFunc = func(ctx context.Context, banks []*models.SwiftBank) error {
+			stored += len(banks)
+			return nil
+		}
+		schedule := service.NewMaintenanceSchedule(nil)
+		window, err := schedule.Schedule(service.MaintenanceWindow{Start: time.Now().Add(-time.Minute), End: time.Now().Add(90 * time.Second), Reason: "upgrade"})
+		Expect(err).NotTo(HaveOccurred())
+		imp := importer.NewImporter(repo, importer.GoldenConfig{}, importer.WithWriteCheck(schedule.Refuse))
+		h := handlers.NewReloadHandler(imp, path)
+
+		resp := reload(h)
+		Expect(resp.StatusCode).To(Equal(http.StatusServiceUnavailable))
+		Expect(resp.Header.Get(fiber.HeaderRetryAfter)).To(BeElementOf("89", "90"))
+		var body apierror.Error
+		Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		Expect(body.Code).To(Equal(apierror.CodeReadOnly))
+		Expect(body.Message).To(ContainSubstring("upgrade"))
+		Expect(stored).To(BeZero())
+		Expect(imp.History()).To(BeEmpty())
+
+		Expect(schedule.Cancel(window.ID)).To(Succeed())
+		Expect(reload(h).StatusCode).To(Equal(http.StatusOK))
+		Expect(stored).To(Equal(1))
+	})
+
+	It("should refuse to reload when no file is configured", func() {
+		resp := reload(handlers.NewReloadHandler(importer.NewImporter(repo, importer.GoldenConfig{}), ""))
+		Expect(resp.StatusCode).To(Equal(http.StatusConflict))
+	})
+
+	It("should list past runs after a reload", func() {
+		h := handlers.NewReloadHandler(importer.NewImporter(repo, importer.GoldenConfig{}), path)
+		Expect(reload(h).StatusCode).To(Equal(http.StatusOK))
+
+		app := fiber.New()
+		app.Get("/imports", h.History)
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/imports", nil), fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+
+		var body struct {
+			Imports []importer.LoadRecord `json:"imports"`
+		}
+		Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		Expect(body.Imports).To(HaveLen(1))
+		Expect(body.Imports[0].Loaded).To(Equal(1))
+	})
+})
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	"github.com/zdziszkee/swift-codes/internal/api/grpcapi"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	"google.golang.org/protobuf/proto"
+)
+
+// Response formats supported by content negotiation
+const (
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+	FormatXML      = "xml"
+	FormatProtobuf = "protobuf"
+
+	mimeTextCSV  = "text/csv"
+	MIMEProtobuf = "application/x-protobuf"
+)
+
+// negotiateFormat picks the response format from ?format= or the Accept
+// header. It returns "" when the client asked for something unsupported.
+func negotiateFormat(c fiber.Ctx) string {
+	if format := strings.ToLower(c.Query("format")); format != "" {
+		switch format {
+		case FormatJSON, FormatCSV, FormatXML, FormatProtobuf:
+			return format
+		default:
+			return ""
+		}
+	}
+
+	switch c.Accepts(fiber.MIMEApplicationJSON, mimeTextCSV, fiber.MIMEApplicationXML, fiber.MIMETextXML, MIMEProtobuf) {
+	case fiber.MIMEApplicationJSON:
+		return FormatJSON
+	case mimeTextCSV:
+		return FormatCSV
+	case fiber.MIMEApplicationXML, fiber.MIMETextXML:
+		return FormatXML
+	case MIMEProtobuf:
+		return FormatProtobuf
+	default:
+		return ""
+	}
+}
+
+// respond encodes v in the given format with only the fields in mask,
+// answering 406 when the negotiated format is empty
+func respond(c fiber.Ctx, status int, format string, mask FieldMask, v any) error {
+	if mask != AllFields && format == FormatXML {
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Field selection is not supported for XML responses")
+	}
+
+	switch format {
+	case FormatJSON:
+		return c.Status(status).JSON(v)
+	case FormatCSV:
+		body, err := encodeCSV(v, mask)
+		if err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, mimeTextCSV+"; charset=utf-8")
+		return c.Status(status).Send(body)
+	case FormatXML:
+		body, err := encodeXML(v)
+		if err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationXMLCharsetUTF8)
+		return c.Status(status).Send(body)
+	case FormatProtobuf:
+		body, err := encodeProtobuf(v, mask)
+		if err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, MIMEProtobuf)
+		return c.Status(status).Send(body)
+	default:
+		return apierror.Write(c, fiber.StatusNotAcceptable, apierror.CodeNotAcceptable, "Unsupported response format")
+	}
+}
+
+// responseBanks returns the banks of a v1 payload, headquarters first
+func responseBanks(v any) []*BankResponse {
+	var banks []*BankResponse
+	add := func(list []BankResponse) {
+		for i := range list {
+			banks = append(banks, &list[i])
+		}
+	}
+	switch r := v.(type) {
+	case *SwiftCodeResponse:
+		banks = append(banks, &r.Bank)
+		add(r.Branches)
+	case *CountryResponse:
+		add(r.SwiftCodes)
+	case *BankGroupResponse:
+		if r.Headquarters != nil {
+			banks = append(banks, r.Headquarters)
+		}
+		add(r.Branches)
+	}
+	return banks
+}
+
+// encodeCSV flattens a response into one row per bank, writing only the
+// columns selected by mask followed by any computed fields
+func encodeCSV(v any, mask FieldMask) ([]byte, error) {
+	banks := responseBanks(v)
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	columns := &BankResponse{}
+	if len(banks) > 0 {
+		columns.computed = banks[0].computed
+	}
+	header, _ := mask.csvColumns(columns)
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	for _, bank := range banks {
+		_, values := mask.csvColumns(bank)
+		if err := w.Write(values); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// encodeXML wraps a response in a root element named after the resource
+func encodeXML(v any) ([]byte, error) {
+	root := "response"
+	switch v.(type) {
+	case *SwiftCodeResponse:
+		root = "swiftBankDetail"
+	case *CountryResponse:
+		root = "countrySwiftCodes"
+	case *BankGroupResponse:
+		root = "bankGroup"
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	if err := enc.EncodeElement(v, xml.StartElement{Name: xml.Name{Local: root}}); err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeProtobuf encodes a response with the message types of the gRPC
+// API. Fields outside mask are cleared, which proto3 leaves off the wire.
+func encodeProtobuf(v any, mask FieldMask) ([]byte, error) {
+	var msg proto.Message
+	switch r := v.(type) {
+	case *SwiftCodeResponse:
+		masked := repository.SwiftBankDetail{Bank: mask.apply(r.Bank).model()}
+		for _, branch := range r.Branches {
+			masked.Branches = append(masked.Branches, mask.apply(branch).model())
+		}
+		msg = grpcapi.NewGetByCodeResponse(&masked)
+	case *CountryResponse:
+		masked := repository.CountrySwiftCodes{CountryISO2: r.CountryISO2, CountryName: r.CountryName}
+		masked.SwiftCodes = make([]models.SwiftBank, len(r.SwiftCodes))
+		for i, bank := range r.SwiftCodes {
+			masked.SwiftCodes[i] = mask.apply(bank).model()
+		}
+		msg = grpcapi.NewGetByCountryResponse(&masked)
+	default:
+		return nil, fmt.Errorf("cannot encode %T as protobuf", v)
+	}
+	return proto.Marshal(msg)
+}
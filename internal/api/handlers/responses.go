@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+)
+
+// BankResponse is the v1 payload of a single SWIFT bank. Its keys are part
+// of the public API and must not follow renames in the internal model.
+type BankResponse struct {
+	SwiftCode      string `json:"SwiftCode" xml:"SwiftCode"`
+	SwiftCodeBase  string `json:"SwiftCodeBase" xml:"SwiftCodeBase"`
+	CountryISOCode string `json:"CountryISOCode" xml:"CountryISOCode"`
+	BankName       string `json:"BankName" xml:"BankName"`
+	IsHeadquarter  bool   `json:"IsHeadquarter" xml:"IsHeadquarter"`
+	Address        string `json:"Address" xml:"Address"`
+	CountryName    string `json:"CountryName" xml:"CountryName"`
+	// Website and Phone are only written when requested with ?fields=
+	Website string `json:"Website,omitempty" xml:"-"`
+	Phone   string `json:"Phone,omitempty" xml:"-"`
+
+	// town and timeZone are not part of the v1 payload but can be read by
+	// computed fields, whose values are written after the fields above
+	town     string
+	timeZone string
+	computed []computedValue
+}
+
+// SwiftCodeResponse is the v1 payload for a SWIFT code lookup
+type SwiftCodeResponse struct {
+	Bank     BankResponse   `json:"bank" xml:"bank"`
+	Branches []BankResponse `json:"branches,omitempty" xml:"branches>branch,omitempty"`
+	// BranchesTotal and BranchesLink are set when Branches was truncated
+	// or paged with ?branchLimit= and ?branchOffset=; they give the full
+	// branch count and, while more branches follow, where to read them
+	BranchesTotal int    `json:"branches_total,omitempty" xml:"branches_total,omitempty"`
+	BranchesLink  string `json:"branches_link,omitempty" xml:"branches_link,omitempty"`
+	// BranchesUnavailable is set when the branches could not be read and
+	// the headquarters is returned on its own
+	BranchesUnavailable bool `json:"branches_unavailable,omitempty" xml:"branches_unavailable,omitempty"`
+
+	// branchesJSON, when set, is the JSON encoding of Branches kept by the
+	// handler's branch cache
+	branchesJSON []byte
+}
+
+// CountryResponse is the v1 payload for a country listing
+type CountryResponse struct {
+	CountryISO2 string         `json:"country_iso2" xml:"country_iso2"`
+	CountryName string         `json:"country_name" xml:"country_name"`
+	SwiftCodes  []BankResponse `json:"swift_codes" xml:"swift_codes>swift_code"`
+}
+
+// BankGroupResponse is the v1 payload for the codes sharing a SWIFT code
+// base; Headquarters is null when none is stored
+type BankGroupResponse struct {
+	SwiftCodeBase string         `json:"swift_code_base" xml:"swift_code_base"`
+	Headquarters  *BankResponse  `json:"headquarters" xml:"headquarters,omitempty"`
+	Branches      []BankResponse `json:"branches" xml:"branches>branch"`
+}
+
+// NewBankResponse maps a model to its v1 payload
+func NewBankResponse(bank models.SwiftBank) BankResponse {
+	return BankResponse{
+		SwiftCode:      bank.SwiftCode,
+		SwiftCodeBase:  bank.SwiftCodeBase,
+		CountryISOCode: bank.CountryISOCode,
+		BankName:       bank.BankName,
+		IsHeadquarter:  bank.IsHeadquarter,
+		Address:        bank.Address,
+		CountryName:    bank.CountryName,
+		Website:        bank.Website,
+		Phone:          bank.Phone,
+		town:           bank.Town,
+		timeZone:       bank.TimeZone,
+	}
+}
+
+// newBankResponses maps banks, keeping nil as nil so an absent list still
+// encodes as null
+func newBankResponses(banks []models.SwiftBank) []BankResponse {
+	if banks == nil {
+		return nil
+	}
+	responses := make([]BankResponse, len(banks))
+	for i, bank := range banks {
+		responses[i] = NewBankResponse(bank)
+	}
+	return responses
+}
+
+// NewSwiftCodeResponse maps a repository detail to its v1 payload
+func NewSwiftCodeResponse(detail *repository.SwiftBankDetail) *SwiftCodeResponse {
+	return &SwiftCodeResponse{
+		Bank:                NewBankResponse(detail.Bank),
+		Branches:            newBankResponses(detail.Branches),
+		BranchesUnavailable: detail.BranchesUnavailable,
+	}
+}
+
+// NewCountryResponse maps a country listing to its v1 payload
+func NewCountryResponse(codes *repository.CountrySwiftCodes) *CountryResponse {
+	return &CountryResponse{
+		CountryISO2: codes.CountryISO2,
+		CountryName: codes.CountryName,
+		SwiftCodes:  newBankResponses(codes.SwiftCodes),
+	}
+}
+
+// NewBankGroupResponse maps a bank group to its v1 payload
+func NewBankGroupResponse(group *repository.BankGroup) *BankGroupResponse {
+	resp := &BankGroupResponse{SwiftCodeBase: group.SwiftCodeBase, Branches: newBankResponses(group.Branches)}
+	if group.Headquarters != nil {
+		hq := NewBankResponse(*group.Headquarters)
+		resp.Headquarters = &hq
+	}
+	if resp.Branches == nil {
+		resp.Branches = []BankResponse{}
+	}
+	return resp
+}
+
+// model maps a payload back to the model, for encoders that reuse the
+// gRPC message converters
+func (b BankResponse) model() models.SwiftBank {
+	return models.SwiftBank{
+		SwiftCode:      b.SwiftCode,
+		SwiftCodeBase:  b.SwiftCodeBase,
+		CountryISOCode: b.CountryISOCode,
+		BankName:       b.BankName,
+		IsHeadquarter:  b.IsHeadquarter,
+		Address:        b.Address,
+		CountryName:    b.CountryName,
+		Website:        b.Website,
+		Phone:          b.Phone,
+		Town:           b.town,
+		TimeZone:       b.timeZone,
+	}
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	"github.com/zdziszkee/swift-codes/internal/database"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+)
+
+// SchemaHandler reports whether the table matches what the binary expects
+type SchemaHandler struct {
+	db *database.Database
+}
+
+// NewSchemaHandler creates a handler that inspects the table of db
+func NewSchemaHandler(db *database.Database) *SchemaHandler {
+	return &SchemaHandler{db: db}
+}
+
+// Status returns the schema version of the table along with its applied
+// and pending migrations
+func (h *SchemaHandler) Status(c fiber.Ctx) error {
+	status, err := h.db.SchemaStatus(c.Context())
+	if err != nil {
+		requestid.Logf(c.Context(), "ERROR: schema status failed: %v", err)
+		return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Internal server error")
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(fiber.StatusOK).JSON(status)
+}
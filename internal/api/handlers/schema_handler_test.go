@@ -0,0 +1,66 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	handlers "github.com/zdziszkee/swift-codes/internal/api/handlers"
+	"github.com/zdziszkee/swift-codes/internal/database"
+)
+
+var _ = Describe("SchemaHandler", func() {
+	var (
+		app    *fiber.App
+		mockDB sqlmock.Sqlmock
+	)
+
+	BeforeEach(func() {
+		db, mock, err := sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() { _ = db.Close() })
+		mockDB = mock
+
+		h := handlers.NewSchemaHandler(&database.Database{DB: db, Config: database.Config{
+			Catalog: "swift_catalog", Schema: "default_schema", TableName: "swift_banks",
+		}})
+		app = fiber.New()
+		app.Get("/schema", h.Status)
+	})
+
+	get := func() *http.Response {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/schema", nil), fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	It("should report the applied and pending migrations", func() {
+		rows := sqlmock.NewRows([]string{"column_name"})
+		for _, column := range database.Migrations()[0].Columns {
+			rows.AddRow(column)
+		}
+		mockDB.ExpectQuery("information_schema.columns").WillReturnRows(rows)
+
+		resp := get()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get("Cache-Control")).To(Equal("no-store"))
+
+		var status map[string]any
+		Expect(json.NewDecoder(resp.Body).Decode(&status)).To(Succeed())
+		Expect(status["current_version"]).To(BeEquivalentTo(1))
+		Expect(status["up_to_date"]).To(BeFalse())
+		Expect(status["applied"]).To(HaveLen(1))
+		Expect(status["pending"]).To(HaveLen(len(database.Migrations()) - 1))
+	})
+
+	It("should answer 500 when the table cannot be inspected", func() {
+		mockDB.ExpectQuery("information_schema.columns").WillReturnError(errors.New("trino down"))
+		Expect(get().StatusCode).To(Equal(http.StatusInternalServerError))
+	})
+})
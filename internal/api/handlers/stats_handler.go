@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	"github.com/zdziszkee/swift-codes/internal/importer"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+// StatsHandler reports dataset totals together with load bookkeeping
+type StatsHandler struct {
+	service  service.SwiftService
+	importer *importer.Importer
+}
+
+// NewStatsHandler creates a stats handler; imp may be nil when the process
+// never loads data itself
+func NewStatsHandler(service service.SwiftService, imp *importer.Importer) *StatsHandler {
+	return &StatsHandler{service: service, importer: imp}
+}
+
+// Stats returns the number of codes, countries, headquarters and branches
+// and, once a load has finished, when it ran and how long it took
+func (h *StatsHandler) Stats(c fiber.Ctx) error {
+	status, err := h.service.DatasetStatus(c.Context())
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	var lastLoad *importer.LoadRecord
+	if h.importer != nil {
+		lastLoad = h.importer.LastLoad()
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"total_codes":  status.TotalCodes,
+		"headquarters": status.Headquarters,
+		"branches":     status.Branches,
+		"countries":    status.Countries,
+		"last_load":    lastLoad,
+	})
+}
+
+// Countries returns the number of codes, headquarters and branches of
+// every country, ordered by country
+func (h *StatsHandler) Countries(c fiber.Ctx) error {
+	counts, err := h.service.CountryCounts(c.Context())
+	if err != nil {
+		return handleError(c, err)
+	}
+	if counts == nil {
+		counts = []repository.CountryCount{}
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"countries": counts})
+}
+
+// Completeness returns per-country percentages of codes with an address,
+// town and time zone, and of branches whose headquarters is listed. The
+// optional country query parameter narrows the answer to one country.
+func (h *StatsHandler) Completeness(c fiber.Ctx) error {
+	country := strings.ToUpper(c.Query("country"))
+	if country != "" && !iso2Pattern.MatchString(country) {
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid input provided",
+			apierror.Field("country", "must be a 2-letter ISO 3166-1 code"))
+	}
+
+	scores, err := h.service.Completeness(c.Context())
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	if country != "" {
+		for _, score := range scores {
+			if score.CountryISO2 == country {
+				return c.Status(fiber.StatusOK).JSON(fiber.Map{"countries": []service.CountryCompleteness{score}})
+			}
+		}
+		return apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "No SWIFT codes found for country")
+	}
+	if scores == nil {
+		scores = []service.CountryCompleteness{}
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"countries": scores})
+}
@@ -0,0 +1,166 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	handlers "github.com/zdziszkee/swift-codes/internal/api/handlers"
+	"github.com/zdziszkee/swift-codes/internal/importer"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+var _ = Describe("StatsHandler", func() {
+	var mockSvc *mocks.MockSwiftService
+
+	BeforeEach(func() {
+		mockSvc = &mocks.MockSwiftService{
+			DatasetStatusFunc: func(ctx context.Context) (*service.DatasetStatus, error) {
+				return &service.DatasetStatus{
+					Status:       service.DatasetReady,
+					DatasetStats: repository.DatasetStats{TotalCodes: 3, Headquarters: 1, Branches: 2, Countries: 1},
+				}, nil
+			},
+		}
+	})
+
+	get := func(h *handlers.StatsHandler) (*http.Response, map[string]any) {
+		app := fiber.New()
+		app.Get("/stats", h.Stats)
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/stats", nil), fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+
+		var body map[string]any
+		Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		return resp, body
+	}
+
+	It("should report totals without a load", func() {
+		resp, body := get(handlers.NewStatsHandler(mockSvc, nil))
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(body["total_codes"]).To(BeNumerically("==", 3))
+		Expect(body["headquarters"]).To(BeNumerically("==", 1))
+		Expect(body["branches"]).To(BeNumerically("==", 2))
+		Expect(body["countries"]).To(BeNumerically("==", 1))
+		Expect(body["last_load"]).To(BeNil())
+	})
+
+	It("should include the last load once data was imported", func() {
+		repo := &mocks.MockSwiftRepository{
+			CreateBatchFunc: func(ctx context.Context, banks []*models.SwiftBank) error { return nil },
+		}
+		imp := importer.NewImporter(repo, importer.GoldenConfig{})
+		_, err := imp.Import(context.Background(), strings.NewReader(reloadCSV))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, body := get(handlers.NewStatsHandler(mockSvc, imp))
+		lastLoad, ok := body["last_load"].(map[string]any)
+		Expect(ok).To(BeTrue())
+		Expect(lastLoad["loaded"]).To(BeNumerically("==", 1))
+		Expect(lastLoad).To(HaveKey("finished_at"))
+		Expect(lastLoad).To(HaveKey("duration_ms"))
+	})
+
+	It("should return an internal error when the dataset cannot be inspected", func() {
+		mockSvc.DatasetStatusFunc = func(ctx context.Context) (*service.DatasetStatus, error) {
+			return nil, errors.New("db error")
+		}
+		resp, _ := get(handlers.NewStatsHandler(mockSvc, nil))
+		Expect(resp.StatusCode).To(Equal(http.StatusInternalServerError))
+	})
+
+	Describe("Completeness", func() {
+		BeforeEach(func() {
+			mockSvc.CompletenessFunc = func(ctx context.Context) ([]service.CountryCompleteness, error) {
+				return []service.CountryCompleteness{
+					{CountryISO2: "MT", TotalCodes: 3, Address: 66.7, Town: 100, TimeZone: 33.3, HQCoverage: 50, Score: 62.5},
+					{CountryISO2: "PL", TotalCodes: 1, Address: 100, Town: 100, TimeZone: 100, HQCoverage: 100, Score: 100},
+				}, nil
+			}
+		})
+
+		request := func(target string) (*http.Response, map[string]any) {
+			app := fiber.New()
+			app.Get("/stats/completeness", handlers.NewStatsHandler(mockSvc, nil).Completeness)
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, target, nil), fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+
+			var body map[string]any
+			Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+			return resp, body
+		}
+
+		It("should list every country", func() {
+			resp, body := request("/stats/completeness")
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(body["countries"]).To(HaveLen(2))
+
+			first := body["countries"].([]any)[0].(map[string]any)
+			Expect(first["country_iso2"]).To(Equal("MT"))
+			Expect(first["address_pct"]).To(BeNumerically("==", 66.7))
+			Expect(first["hq_coverage_pct"]).To(BeNumerically("==", 50))
+			Expect(first["score"]).To(BeNumerically("==", 62.5))
+		})
+
+		It("should narrow the answer to one country", func() {
+			resp, body := request("/stats/completeness?country=pl")
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(body["countries"]).To(HaveLen(1))
+			Expect(body["countries"].([]any)[0].(map[string]any)["country_iso2"]).To(Equal("PL"))
+		})
+
+		It("should return not found for a country without codes", func() {
+			resp, _ := request("/stats/completeness?country=DE")
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+
+		It("should reject a malformed country", func() {
+			resp, _ := request("/stats/completeness?country=POL")
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("Countries", func() {
+		request := func() (*http.Response, map[string]any) {
+			app := fiber.New()
+			app.Get("/stats/countries", handlers.NewStatsHandler(mockSvc, nil).Countries)
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/stats/countries", nil), fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+
+			var body map[string]any
+			Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+			return resp, body
+		}
+
+		It("should list the totals of every country", func() {
+			mockSvc.CountryCountsFunc = func(ctx context.Context) ([]repository.CountryCount, error) {
+				return []repository.CountryCount{{CountryISO2: "MT", CountryName: "MALTA", TotalCodes: 3, Headquarters: 1, Branches: 2}}, nil
+			}
+			resp, body := request()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(body["countries"]).To(Equal([]any{map[string]any{
+				"country_iso2": "MT", "country_name": "MALTA", "total_codes": 3.0, "headquarters": 1.0, "branches": 2.0,
+			}}))
+		})
+
+		It("should answer an empty list before any data is loaded", func() {
+			mockSvc.CountryCountsFunc = func(ctx context.Context) ([]repository.CountryCount, error) {
+				return nil, nil
+			}
+			resp, body := request()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(body["countries"]).To(BeEmpty())
+			Expect(body).To(HaveKey("countries"))
+		})
+	})
+})
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"strconv"
+	"sync"
+	"unicode/utf8"
+)
+
+// bufferPool recycles encoding buffers between lookup requests
+var bufferPool = sync.Pool{
+	New: func() any {
+		buf := make([]byte, 0, 4096)
+		return &buf
+	},
+}
+
+const hexDigits = "0123456789abcdef"
+
+// branchCacheSize bounds the branch lists a branchCache keeps encoded
+const branchCacheSize = 1024
+
+// branchCache keeps the JSON of the branches of details served from the
+// repository cache, by cache entry and field mask, so lookups of a large
+// headquarters do not encode the same branches on every request. Entries
+// are never stale: a write or reload makes the repository cache store the
+// detail again under a new entry. When full it starts over.
+type branchCache struct {
+	mu      sync.RWMutex
+	encoded map[branchKey][]byte
+}
+
+type branchKey struct {
+	entry uint64
+	mask  FieldMask
+}
+
+func newBranchCache() *branchCache {
+	return &branchCache{encoded: make(map[branchKey][]byte)}
+}
+
+// branchesJSON returns the encoded branches of resp, read from the cache
+// entry numbered entry
+func (b *branchCache) branchesJSON(entry uint64, resp *SwiftCodeResponse, mask FieldMask) []byte {
+	key := branchKey{entry: entry, mask: mask}
+	b.mu.RLock()
+	encoded, ok := b.encoded[key]
+	b.mu.RUnlock()
+	if ok {
+		return encoded
+	}
+
+	encoded = appendSwiftBanksJSON(nil, resp.Branches, mask)
+	b.mu.Lock()
+	if len(b.encoded) >= branchCacheSize {
+		clear(b.encoded)
+	}
+	b.encoded[key] = encoded
+	b.mu.Unlock()
+	return encoded
+}
+
+// AppendSwiftCodeResponseJSON appends the JSON encoding of resp to dst.
+// The output is byte-for-byte identical to encoding/json, but avoids
+// reflection and intermediate allocations on the GetByCode hot path.
+func AppendSwiftCodeResponseJSON(dst []byte, resp *SwiftCodeResponse) []byte {
+	return appendSwiftCodeResponseJSON(dst, resp, AllFields)
+}
+
+func appendSwiftCodeResponseJSON(dst []byte, detail *SwiftCodeResponse, mask FieldMask) []byte {
+	dst = append(dst, `{"bank":`...)
+	dst = appendSwiftBankJSON(dst, &detail.Bank, mask)
+	switch {
+	case detail.branchesJSON != nil:
+		dst = append(dst, `,"branches":`...)
+		dst = append(dst, detail.branchesJSON...)
+	case len(detail.Branches) > 0:
+		dst = append(dst, `,"branches":`...)
+		dst = appendSwiftBanksJSON(dst, detail.Branches, mask)
+	}
+	if detail.BranchesTotal != 0 {
+		dst = append(dst, `,"branches_total":`...)
+		dst = strconv.AppendInt(dst, int64(detail.BranchesTotal), 10)
+	}
+	if detail.BranchesLink != "" {
+		dst = append(dst, `,"branches_link":`...)
+		dst = appendJSONString(dst, detail.BranchesLink)
+	}
+	if detail.BranchesUnavailable {
+		dst = append(dst, `,"branches_unavailable":true`...)
+	}
+	return append(dst, '}')
+}
+
+// AppendCountryResponseJSON appends the JSON encoding of codes to dst,
+// matching encoding/json byte for byte
+func AppendCountryResponseJSON(dst []byte, codes *CountryResponse) []byte {
+	return appendCountryResponseJSON(dst, codes, AllFields)
+}
+
+func appendCountryResponseJSON(dst []byte, codes *CountryResponse, mask FieldMask) []byte {
+	dst = append(dst, `{"country_iso2":`...)
+	dst = appendJSONString(dst, codes.CountryISO2)
+	dst = append(dst, `,"country_name":`...)
+	dst = appendJSONString(dst, codes.CountryName)
+	dst = append(dst, `,"swift_codes":`...)
+	if codes.SwiftCodes == nil {
+		dst = append(dst, "null"...)
+	} else {
+		dst = appendSwiftBanksJSON(dst, codes.SwiftCodes, mask)
+	}
+	return append(dst, '}')
+}
+
+func appendBankGroupResponseJSON(dst []byte, group *BankGroupResponse, mask FieldMask) []byte {
+	dst = append(dst, `{"swift_code_base":`...)
+	dst = appendJSONString(dst, group.SwiftCodeBase)
+	dst = append(dst, `,"headquarters":`...)
+	if group.Headquarters == nil {
+		dst = append(dst, "null"...)
+	} else {
+		dst = appendSwiftBankJSON(dst, group.Headquarters, mask)
+	}
+	dst = append(dst, `,"branches":`...)
+	dst = appendSwiftBanksJSON(dst, group.Branches, mask)
+	return append(dst, '}')
+}
+
+func appendSwiftBanksJSON(dst []byte, banks []BankResponse, mask FieldMask) []byte {
+	dst = append(dst, '[')
+	for i := range banks {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendSwiftBankJSON(dst, &banks[i], mask)
+	}
+	return append(dst, ']')
+}
+
+// appendSwiftBankJSON writes the fields selected by mask, in struct order
+func appendSwiftBankJSON(dst []byte, bank *BankResponse, mask FieldMask) []byte {
+	sep := byte('{')
+	key := func(name string) {
+		dst = append(dst, sep, '"')
+		dst = append(dst, name...)
+		dst = append(dst, '"', ':')
+		sep = ','
+	}
+
+	if mask.Has(FieldSwiftCode) {
+		key("SwiftCode")
+		dst = appendJSONString(dst, bank.SwiftCode)
+	}
+	if mask.Has(FieldSwiftCodeBase) {
+		key("SwiftCodeBase")
+		dst = appendJSONString(dst, bank.SwiftCodeBase)
+	}
+	if mask.Has(FieldCountryISOCode) {
+		key("CountryISOCode")
+		dst = appendJSONString(dst, bank.CountryISOCode)
+	}
+	if mask.Has(FieldBankName) {
+		key("BankName")
+		dst = appendJSONString(dst, bank.BankName)
+	}
+	if mask.Has(FieldIsHeadquarter) {
+		key("IsHeadquarter")
+		if bank.IsHeadquarter {
+			dst = append(dst, "true"...)
+		} else {
+			dst = append(dst, "false"...)
+		}
+	}
+	if mask.Has(FieldAddress) {
+		key("Address")
+		dst = appendJSONString(dst, bank.Address)
+	}
+	if mask.Has(FieldCountryName) {
+		key("CountryName")
+		dst = appendJSONString(dst, bank.CountryName)
+	}
+	if mask.Has(FieldWebsite) {
+		key("Website")
+		dst = appendJSONString(dst, bank.Website)
+	}
+	if mask.Has(FieldPhone) {
+		key("Phone")
+		dst = appendJSONString(dst, bank.Phone)
+	}
+	for _, field := range bank.computed {
+		key(field.name)
+		dst = appendJSONString(dst, field.value)
+	}
+	if sep == '{' {
+		dst = append(dst, '{')
+	}
+	return append(dst, '}')
+}
+
+// appendJSONString quotes s the same way encoding/json does, including
+// HTML-safe escaping of <, > and &.
+func appendJSONString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch b {
+			case '"', '\\':
+				dst = append(dst, '\\', b)
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hexDigits[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
@@ -0,0 +1,221 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/valyala/fasthttp"
+
+	handlers "github.com/zdziszkee/swift-codes/internal/api/handlers"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+// sampleDetail builds a headquarters detail with the given number of branches.
+func sampleDetail(branches int) *repository.SwiftBankDetail {
+	detail := &repository.SwiftBankDetail{
+		Bank: models.SwiftBank{
+			SwiftCode:      "BSZLPLP1XXX",
+			SwiftCodeBase:  "BSZLPLP1",
+			CountryISOCode: "PL",
+			BankName:       "BANK SPOLDZIELCZY W ZLOCIENCU",
+			IsHeadquarter:  true,
+			Address:        "UL. KOSCIUSZKI 9, ZLOCIENIEC, 78-520",
+			CountryName:    "POLAND",
+		},
+	}
+	for i := 0; i < branches; i++ {
+		detail.Branches = append(detail.Branches, models.SwiftBank{
+			SwiftCode:      fmt.Sprintf("BSZLPLP1%03d", i),
+			SwiftCodeBase:  "BSZLPLP1",
+			CountryISOCode: "PL",
+			BankName:       "BANK SPOLDZIELCZY W ZLOCIENCU",
+			Address:        "UL. DLUGA 1, DRAWSKO POMORSKIE, 78-500",
+			CountryName:    "POLAND",
+		})
+	}
+	return detail
+}
+
+var _ = Describe("AppendSwiftCodeResponseJSON", func() {
+	It("should match encoding/json for a headquarters with branches", func() {
+		detail := sampleDetail(3)
+		resp := handlers.NewSwiftCodeResponse(detail)
+		expected, err := json.Marshal(resp)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(handlers.AppendSwiftCodeResponseJSON(nil, resp))).To(Equal(string(expected)))
+	})
+
+	It("should omit branches when there are none", func() {
+		detail := sampleDetail(0)
+		detail.Branches = []models.SwiftBank{}
+		resp := handlers.NewSwiftCodeResponse(detail)
+		expected, err := json.Marshal(resp)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(handlers.AppendSwiftCodeResponseJSON(nil, resp))).To(Equal(string(expected)))
+	})
+
+	It("should match encoding/json for a truncated branch list", func() {
+		resp := handlers.NewSwiftCodeResponse(sampleDetail(2))
+		resp.BranchesTotal = 250
+		resp.BranchesLink = "http://example.com/v1/swiftCodes/BSZLPLP1XXX/branches?limit=2&offset=2"
+		expected, err := json.Marshal(resp)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(handlers.AppendSwiftCodeResponseJSON(nil, resp))).To(Equal(string(expected)))
+	})
+
+	It("should escape strings the same way as encoding/json", func() {
+		detail := sampleDetail(0)
+		detail.Bank.BankName = "A \"quoted\" <b>&</b> name\\\n\t\x01  \xff ŁÓDŹ"
+		resp := handlers.NewSwiftCodeResponse(detail)
+		expected, err := json.Marshal(resp)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(handlers.AppendSwiftCodeResponseJSON(nil, resp))).To(Equal(string(expected)))
+	})
+})
+
+var _ = Describe("AppendCountryResponseJSON", func() {
+	It("should match encoding/json", func() {
+		codes := &repository.CountrySwiftCodes{
+			CountryISO2: "PL",
+			CountryName: "POLAND",
+			SwiftCodes:  sampleDetail(2).Branches,
+		}
+		resp := handlers.NewCountryResponse(codes)
+		expected, err := json.Marshal(resp)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(handlers.AppendCountryResponseJSON(nil, resp))).To(Equal(string(expected)))
+
+		codes.SwiftCodes = nil
+		resp = handlers.NewCountryResponse(codes)
+		expected, err = json.Marshal(resp)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(handlers.AppendCountryResponseJSON(nil, resp))).To(Equal(string(expected)))
+	})
+})
+
+var _ = Describe("NewSwiftCodeResponse", func() {
+	It("should keep the v1 payload keys", func() {
+		body, err := json.Marshal(handlers.NewSwiftCodeResponse(sampleDetail(0)))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal(`{"bank":{"SwiftCode":"BSZLPLP1XXX","SwiftCodeBase":"BSZLPLP1","CountryISOCode":"PL",` +
+			`"BankName":"BANK SPOLDZIELCZY W ZLOCIENCU","IsHeadquarter":true,"Address":"UL. KOSCIUSZKI 9, ZLOCIENIEC, 78-520","CountryName":"POLAND"}}`))
+	})
+})
+
+var _ = Describe("ParseFieldMask", func() {
+	It("should select every field when empty", func() {
+		mask, err := handlers.ParseFieldMask("")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mask).To(Equal(handlers.AllFields))
+	})
+
+	It("should accept camelCase and snake_case names", func() {
+		mask, err := handlers.ParseFieldMask("swiftCode, bank_name")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mask).To(Equal(handlers.FieldSwiftCode | handlers.FieldBankName))
+	})
+
+	It("should leave contacts out unless requested", func() {
+		Expect(handlers.AllFields.Has(handlers.FieldWebsite)).To(BeFalse())
+
+		mask, err := handlers.ParseFieldMask("bankName,contacts")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mask).To(Equal(handlers.FieldBankName | handlers.FieldWebsite | handlers.FieldPhone))
+	})
+
+	It("should reject unknown fields", func() {
+		_, err := handlers.ParseFieldMask("swiftCode,iban")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+func BenchmarkGetByCodeEncodingJSON(b *testing.B) {
+	detail := handlers.NewSwiftCodeResponse(sampleDetail(20))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(detail); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetByCodeAppendJSON(b *testing.B) {
+	detail := handlers.NewSwiftCodeResponse(sampleDetail(20))
+	buf := make([]byte, 0, 4096)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = handlers.AppendSwiftCodeResponseJSON(buf[:0], detail)
+	}
+}
+
+// BenchmarkGetByCodeParallel serves a headquarters with 20 branches through
+// the Fiber app from a cached memory repository on every core, reporting
+// the median and p99 latency of a request
+func BenchmarkGetByCodeParallel(b *testing.B) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	detail := sampleDetail(20)
+	banks := []*models.SwiftBank{&detail.Bank}
+	for i := range detail.Branches {
+		banks = append(banks, &detail.Branches[i])
+	}
+	repo := repository.NewMemorySwiftRepository()
+	if err := repo.CreateBatch(context.Background(), banks); err != nil {
+		b.Fatal(err)
+	}
+	h := handlers.NewSwiftHandler(service.NewSwiftService(repository.Chain(repo, repository.WithCache(time.Hour))))
+	app := fiber.New()
+	app.Get("/v1/swiftCodes/:swiftCode", h.GetByCode)
+	handler := app.Handler()
+
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+	)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		var ctx fasthttp.RequestCtx
+		var local []time.Duration
+		for pb.Next() {
+			ctx.Request.Reset()
+			ctx.Response.Reset()
+			ctx.Request.SetRequestURI("/v1/swiftCodes/BSZLPLP1XXX")
+			start := time.Now()
+			handler(&ctx)
+			local = append(local, time.Since(start))
+			if ctx.Response.StatusCode() != fiber.StatusOK {
+				b.Errorf("status %d", ctx.Response.StatusCode())
+				return
+			}
+		}
+		mu.Lock()
+		latencies = append(latencies, local...)
+		mu.Unlock()
+	})
+	b.StopTimer()
+
+	if len(latencies) == 0 {
+		return
+	}
+	slices.Sort(latencies)
+	percentile := func(p float64) float64 {
+		return float64(latencies[int(p*float64(len(latencies)-1))].Nanoseconds())
+	}
+	b.ReportMetric(percentile(0.50), "p50-ns")
+	b.ReportMetric(percentile(0.99), "p99-ns")
+}
@@ -1,67 +1,392 @@
 package handlers
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
 	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
 	service "github.com/zdziszkee/swift-codes/internal/services"
 )
 
 // SwiftHandler handles API requests for SWIFT codes
 type SwiftHandler struct {
 	service service.SwiftService
+	config  Config
+	// computed holds the compiled computed fields, swapped on reloads
+	computed atomic.Pointer[[]computedField]
+	// branches keeps the branch JSON of details served from the
+	// repository cache
+	branches *branchCache
 }
 
 // NewSwiftHandler creates a new handler instance
-func NewSwiftHandler(service service.SwiftService) *SwiftHandler {
-	return &SwiftHandler{service: service}
+func NewSwiftHandler(service service.SwiftService, config ...Config) *SwiftHandler {
+	h := &SwiftHandler{service: service, branches: newBranchCache()}
+	if len(config) > 0 {
+		h.config = config[0]
+		h.SetComputedFields(h.config.ComputedFields)
+	}
+	return h
 }
 
-// GetByCode handles requests for a specific SWIFT code
+// GetByCode handles requests for a specific SWIFT code. ?branchLimit= and
+// ?branchOffset= page through the branches of a headquarters.
 
 func (h *SwiftHandler) GetByCode(c fiber.Ctx) error {
 	code := strings.ToUpper(c.Params("swiftCode"))
-	log.Printf("INFO: GetByCode called with swift-code: %s", code)
+	requestid.Logf(c.Context(), "INFO: GetByCode called with swift-code: %s", code)
+
+	branchLimit, branchOffset, branchesPaged, ok := h.parseBranchPage(c)
+	if !ok {
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid pagination parameters")
+	}
+
+	ctx := h.embeddedBranches(c)
+	if branchesPaged {
+		ctx = service.WithBranchWindow(c.Context(), branchLimit, branchOffset)
+	}
+	bank, err := h.service.GetSwiftCodeDetails(ctx, code)
+	if err != nil {
+		requestid.Logf(c.Context(), "INFO: Error retrieving SWIFT code details for %s: %v", code, err)
+		return handleError(c, err)
+	}
+
+	requestid.Logf(c.Context(), "INFO: Successfully retrieved SWIFT code details for %s", code)
+	mask, err := ParseFieldMask(c.Query("fields"))
+	if err != nil {
+		return invalidFields(c, err)
+	}
+
+	var resp *SwiftCodeResponse
+	switch {
+	case !branchesPaged:
+		resp = h.swiftCodeResponse(c, bank)
+	case bank.BranchesUnavailable:
+		return branchesUnavailable(c)
+	default:
+		resp = pagedSwiftCodeResponse(c, bank, branchLimit, branchOffset)
+	}
+	h.addComputedFields(c, resp)
+	format := negotiateFormat(c)
+	if format == FormatJSON {
+		h.useCachedBranches(bank, resp, mask)
+		bufPtr := bufferPool.Get().(*[]byte)
+		*bufPtr = appendSwiftCodeResponseJSON((*bufPtr)[:0], resp, mask)
+		return sendPooledJSON(c, bufPtr)
+	}
+	return respond(c, fiber.StatusOK, format, mask, resp)
+}
+
+// useCachedBranches encodes the branches of resp through the branch cache
+// when they are exactly those of a repository cache entry: redaction and
+// sampling hand out fresh details with no CacheEntry, and computed fields
+// differ per configuration, so their branches are encoded per request
+func (h *SwiftHandler) useCachedBranches(detail *repository.SwiftBankDetail, resp *SwiftCodeResponse, mask FieldMask) {
+	if detail.CacheEntry == 0 || len(resp.Branches) == 0 || resp.Bank.computed != nil {
+		return
+	}
+	resp.branchesJSON = h.branches.branchesJSON(detail.CacheEntry, resp, mask)
+}
+
+// GetBranches pages through the branches of a headquarters with ?limit= and
+// ?offset=, answering with the HQ and the requested window of branches
+func (h *SwiftHandler) GetBranches(c fiber.Ctx) error {
+	code := strings.ToUpper(c.Params("swiftCode"))
 
-	bank, err := h.service.GetSwiftCodeDetails(c.Context(), code)
+	limit, offset, ok := h.parsePage(c)
+	if !ok {
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid pagination parameters")
+	}
+
+	detail, err := h.service.GetSwiftCodeDetails(service.WithBranchWindow(c.Context(), limit, offset), code)
 	if err != nil {
-		log.Printf("INFO: Error retrieving SWIFT code details for %s: %v", code, err)
 		return handleError(c, err)
 	}
+	if detail.BranchesUnavailable {
+		return branchesUnavailable(c)
+	}
+
+	mask, err := ParseFieldMask(c.Query("fields"))
+	if err != nil {
+		return invalidFields(c, err)
+	}
+
+	page := NewSwiftCodeResponse(detail)
+	setPaginationHeaders(c, detail.BranchesTotal, limit, offset)
+	h.addComputedFields(c, page)
 
-	log.Printf("INFO: Successfully retrieved SWIFT code details for %s", code)
-	return c.Status(fiber.StatusOK).JSON(bank)
+	format := negotiateFormat(c)
+	if format == FormatJSON {
+		bufPtr := bufferPool.Get().(*[]byte)
+		*bufPtr = appendSwiftCodeResponseJSON((*bufPtr)[:0], page, mask)
+		return sendPooledJSON(c, bufPtr)
+	}
+	return respond(c, fiber.StatusOK, format, mask, page)
+}
+
+// GetHeadquarters resolves the headquarters of a branch code, answering
+// with the HQ record on its own
+func (h *SwiftHandler) GetHeadquarters(c fiber.Ctx) error {
+	code := strings.ToUpper(c.Params("swiftCode"))
+
+	hq, err := h.service.GetHeadquarters(c.Context(), code)
+	if errors.Is(err, service.ErrNotFound) {
+		return apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "Headquarters not found")
+	}
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	mask, err := ParseFieldMask(c.Query("fields"))
+	if err != nil {
+		return invalidFields(c, err)
+	}
+
+	resp := &SwiftCodeResponse{Bank: NewBankResponse(*hq)}
+	h.addComputedFields(c, resp)
+	format := negotiateFormat(c)
+	if format == FormatJSON {
+		bufPtr := bufferPool.Get().(*[]byte)
+		*bufPtr = appendSwiftCodeResponseJSON((*bufPtr)[:0], resp, mask)
+		return sendPooledJSON(c, bufPtr)
+	}
+	return respond(c, fiber.StatusOK, format, mask, resp)
+}
+
+// GetBankGroup answers with the headquarters and every branch sharing the
+// 8-character base of :base, which may also be any 11-character code of
+// the group
+func (h *SwiftHandler) GetBankGroup(c fiber.Ctx) error {
+	base := strings.ToUpper(c.Params("base"))
+
+	group, err := h.service.GetBankGroup(c.Context(), base)
+	if errors.Is(err, service.ErrNotFound) {
+		return apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "SWIFT code base not found")
+	}
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	mask, err := ParseFieldMask(c.Query("fields"))
+	if err != nil {
+		return invalidFields(c, err)
+	}
+
+	resp := NewBankGroupResponse(group)
+	h.addComputedFields(c, resp)
+	switch format := negotiateFormat(c); format {
+	case FormatJSON:
+		bufPtr := bufferPool.Get().(*[]byte)
+		*bufPtr = appendBankGroupResponseJSON((*bufPtr)[:0], resp, mask)
+		return sendPooledJSON(c, bufPtr)
+	case FormatProtobuf:
+		// The gRPC API has no bank group message
+		return apierror.Write(c, fiber.StatusNotAcceptable, apierror.CodeNotAcceptable, "Unsupported response format")
+	default:
+		return respond(c, fiber.StatusOK, format, mask, resp)
+	}
+}
+
+// branchesUnavailable answers a branch listing whose branches could not be
+// read; an empty page would wrongly claim the headquarters has none
+func branchesUnavailable(c fiber.Ctx) error {
+	return apierror.Write(c, fiber.StatusServiceUnavailable, apierror.CodeUnavailable, "Branches are temporarily unavailable")
+}
+
+// sendPooledJSON writes an encoded JSON body taken from bufferPool; the
+// response copies the bytes so the buffer can be recycled immediately.
+func sendPooledJSON(c fiber.Ctx, bufPtr *[]byte) error {
+	c.Status(fiber.StatusOK)
+	c.Response().Header.SetContentType(fiber.MIMEApplicationJSON)
+	c.Response().SetBody(*bufPtr)
+
+	bufferPool.Put(bufPtr)
+	return nil
+}
+
+// invalidFields rejects a ?fields= list naming unknown fields
+func invalidFields(c fiber.Ctx, err error) error {
+	return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid fields parameter",
+		apierror.Field("fields", err.Error()))
+}
+
+// maxSearchLength caps the ?address= and ?town= search terms
+const maxSearchLength = 100
+
+// listOptions reads ?sort=, ?type=, ?address=, ?town=, ?limit=, ?offset= and
+// ?after=. When a parameter is invalid it writes the 400 response and
+// reports false.
+func (h *SwiftHandler) listOptions(c fiber.Ctx) (repository.QueryOptions, bool) {
+	badRequest := func(message string, details ...apierror.Detail) (repository.QueryOptions, bool) {
+		_ = apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, message, details...)
+		return repository.QueryOptions{}, false
+	}
+
+	sort, err := repository.ParseSort(c.Query("sort"))
+	if err != nil {
+		return badRequest("Invalid sort parameter", apierror.Field("sort", err.Error()))
+	}
+
+	bankType, err := repository.ParseBankType(c.Query("type"))
+	if err != nil {
+		return badRequest("Invalid type parameter", apierror.Field("type", err.Error()))
+	}
+
+	var terms [2]string
+	for i, param := range []string{"address", "town"} {
+		terms[i] = strings.TrimSpace(c.Query(param))
+		if len(terms[i]) > maxSearchLength {
+			return badRequest("Invalid "+param+" parameter", apierror.Field(param, fmt.Sprintf("must be at most %d characters", maxSearchLength)))
+		}
+	}
+	address, town := terms[0], terms[1]
+
+	limit, offset, ok := h.parsePage(c)
+	if !ok {
+		return badRequest("Invalid pagination parameters")
+	}
+
+	opts := repository.QueryOptions{Sort: sort, Type: bankType, Address: address, Town: town, Limit: limit, Offset: offset}
+	opts.After, err = repository.ParseCursor(c.Query("after"))
+	if err == nil {
+		err = opts.CheckCursor()
+	}
+	if err != nil {
+		return badRequest("Invalid after parameter", apierror.Field("after", err.Error()))
+	}
+	return opts, true
+}
+
+// writeContext reads ?dryRun= for write endpoints and returns the service
+// context, marked with service.WithDryRun when set. When the flag is invalid
+// it writes the 400 response and reports false.
+func writeContext(c fiber.Ctx) (ctx context.Context, dryRun bool, ok bool) {
+	ctx = c.Context()
+	if raw := c.Query("dryRun"); raw != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			_ = apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid dryRun parameter",
+				apierror.Field("dryRun", "must be true or false"))
+			return nil, false, false
+		}
+	}
+	if dryRun {
+		ctx = service.WithDryRun(ctx)
+	}
+	return ctx, dryRun, true
 }
 
 // GetByCountry handles requests for all SWIFT codes by country
 func (h *SwiftHandler) GetByCountry(c fiber.Ctx) error {
 	countryCode := strings.ToUpper(c.Params("countryISO2code"))
 
-	codes, err := h.service.GetSwiftCodesByCountry(c.Context(), countryCode)
+	opts, ok := h.listOptions(c)
+	if !ok {
+		return nil
+	}
+
+	codes, err := h.service.GetSwiftCodesByCountry(c.Context(), countryCode, opts)
 	if err != nil {
 		return handleError(c, err)
 	}
 
-	return c.Status(fiber.StatusOK).JSON(codes)
+	mask, err := ParseFieldMask(c.Query("fields"))
+	if err != nil {
+		return invalidFields(c, err)
+	}
+
+	setListHeaders(c, codes.Total, opts, codes.SwiftCodes)
+
+	resp := NewCountryResponse(codes)
+	h.addComputedFields(c, resp)
+	format := negotiateFormat(c)
+	if format == FormatJSON {
+		bufPtr := bufferPool.Get().(*[]byte)
+		*bufPtr = appendCountryResponseJSON((*bufPtr)[:0], resp, mask)
+		return sendPooledJSON(c, bufPtr)
+	}
+	return respond(c, fiber.StatusOK, format, mask, resp)
+}
+
+// Validate checks the format of a SWIFT code and breaks it into its parts
+// without touching the database, so it works while data is still loading
+func (h *SwiftHandler) Validate(c fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(service.ParseSwiftCode(c.Params("swiftCode")))
+}
+
+// DatasetStatus reports whether SWIFT data has been loaded yet. It answers
+// 503 while the startup import is still running, so that it can serve as a
+// readiness probe.
+func (h *SwiftHandler) DatasetStatus(c fiber.Ctx) error {
+	status, err := h.service.DatasetStatus(c.Context())
+	if err != nil {
+		return handleError(c, err)
+	}
+	if status.Status == service.DatasetLoading {
+		c.Set(fiber.HeaderCacheControl, "no-store")
+		return c.Status(fiber.StatusServiceUnavailable).JSON(status)
+	}
+	return c.Status(fiber.StatusOK).JSON(status)
 }
 
 // Create handles creation of a new SWIFT code
 func (h *SwiftHandler) Create(c fiber.Ctx) error {
 	var bank models.SwiftBank
 
+	ctx, dryRun, ok := writeContext(c)
+	if !ok {
+		return nil
+	}
+
 	if err := c.Bind().Body(&bank); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "Invalid request body",
-		})
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid request body")
+	}
+	if !h.serverManaged(c, bank.SwiftCode) {
+		return nil
 	}
 
-	err := h.service.CreateSwiftCode(c.Context(), &bank)
-	if err != nil {
-		return handleError(c, err)
+	return h.save(c, ctx, dryRun, &bank, service.WriteCreate)
+}
+
+// Put handles storing a SWIFT code at its URL. It replaces a stored code
+// unless the request is conditional.
+func (h *SwiftHandler) Put(c fiber.Ctx) error {
+	var bank models.SwiftBank
+
+	ctx, dryRun, ok := writeContext(c)
+	if !ok {
+		return nil
 	}
 
+	if err := c.Bind().Body(&bank); err != nil {
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid request body")
+	}
+	if !pathCode(c, &bank) || !h.serverManaged(c, bank.SwiftCode) {
+		return nil
+	}
+
+	return h.save(c, ctx, dryRun, &bank, service.WriteUpsert)
+}
+
+// created answers a successful create; a dry run reports 200 since nothing
+// was stored
+func created(c fiber.Ctx, dryRun bool) error {
+	if dryRun {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message": "SWIFT code would be created",
+			"dry_run": true,
+		})
+	}
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"message": "SWIFT code created successfully",
 	})
@@ -71,34 +396,84 @@ func (h *SwiftHandler) Create(c fiber.Ctx) error {
 func (h *SwiftHandler) Delete(c fiber.Ctx) error {
 	code := strings.ToUpper(c.Params("swiftCode"))
 
-	err := h.service.DeleteSwiftCode(c.Context(), code)
+	ctx, dryRun, ok := writeContext(c)
+	if !ok {
+		return nil
+	}
+
+	err := h.service.DeleteSwiftCode(ctx, code)
 	if err != nil {
 		return handleError(c, err)
 	}
 
+	if dryRun {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message": "SWIFT code would be deleted",
+			"dry_run": true,
+		})
+	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "SWIFT code deleted successfully",
 	})
 }
 
-// Helper function for error handling
+// DeleteByCountry removes every SWIFT code of a country, typically before
+// re-importing that country's dataset
+func (h *SwiftHandler) DeleteByCountry(c fiber.Ctx) error {
+	countryCode := strings.ToUpper(c.Params("countryISO2code"))
+
+	ctx, dryRun, ok := writeContext(c)
+	if !ok {
+		return nil
+	}
+
+	deleted, err := h.service.DeleteSwiftCodesByCountry(ctx, countryCode)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	if dryRun {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message": "SWIFT codes would be deleted",
+			"deleted": deleted,
+			"dry_run": true,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "SWIFT codes deleted successfully",
+		"deleted": deleted,
+	})
+}
+
+// handleError maps service errors onto API responses. Service errors arrive
+// wrapped with context, so they are matched with errors.Is and errors.As.
 func handleError(c fiber.Ctx, err error) error {
+	var inputErr *service.InputError
+	var maintenanceErr *service.MaintenanceError
 	switch {
-	case err == service.ErrNotFound:
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"message": "SWIFT code not found",
-		})
-	case err == service.ErrInvalidInput:
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "Invalid input provided",
-		})
-	case err == service.ErrAlreadyExists:
-		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-			"message": "SWIFT code already exists",
-		})
+	case errors.Is(err, service.ErrAliasConflict):
+		return apierror.Write(c, fiber.StatusConflict, apierror.CodeAliasConflict, "SWIFT code collides with an alias of an existing bank")
+	case errors.Is(err, service.ErrNotFound):
+		return apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "SWIFT code not found")
+	case errors.As(err, &inputErr):
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid input provided",
+			apierror.Field(inputErr.Field, inputErr.Reason))
+	case errors.Is(err, service.ErrInvalidInput):
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid input provided")
+	case errors.Is(err, service.ErrAlreadyExists):
+		return apierror.Write(c, fiber.StatusConflict, apierror.CodeAlreadyExists, "SWIFT code already exists")
+	case errors.As(err, &maintenanceErr):
+		window := maintenanceErr.Window
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(max(1, int(math.Ceil(time.Until(window.End).Seconds())))))
+		message := "Service is read-only for maintenance until " + window.End.UTC().Format(time.RFC3339)
+		if window.Reason != "" {
+			message += ": " + window.Reason
+		}
+		return apierror.Write(c, fiber.StatusServiceUnavailable, apierror.CodeReadOnly, message)
+	case errors.Is(err, repository.ErrQueryTimeout):
+		return apierror.Write(c, fiber.StatusGatewayTimeout, apierror.CodeTimeout, "Query timed out")
 	default:
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": "Internal server error",
-		})
+		return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Internal server error")
 	}
 }
@@ -4,16 +4,25 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/proto"
 
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	"github.com/zdziszkee/swift-codes/internal/api/grpcapi"
 	handlers "github.com/zdziszkee/swift-codes/internal/api/handlers"
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+	"github.com/zdziszkee/swift-codes/internal/attribution"
 	models "github.com/zdziszkee/swift-codes/internal/models"
 	repository "github.com/zdziszkee/swift-codes/internal/repositories"
 	service "github.com/zdziszkee/swift-codes/internal/services"
@@ -35,11 +44,31 @@ func setupApp(svc service.SwiftService) *fiber.App {
 	app.Get("/swift/:swiftCode", h.GetByCode)
 	app.Get("/country/:countryISO2code", h.GetByCountry)
 	app.Post("/swift", h.Create)
+	app.Put("/swift/:swiftCode", h.Put)
 	app.Delete("/swift/:swiftCode", h.Delete)
+	app.Delete("/country/:countryISO2code", h.DeleteByCountry)
+	app.Get("/dataset/status", h.DatasetStatus)
+	app.Get("/swift/:swiftCode/validate", h.Validate)
+	app.Get("/swift/:swiftCode/branches", h.GetBranches)
+	app.Get("/swift/:swiftCode/headquarters", h.GetHeadquarters)
+	app.Get("/swiftBases/:base", h.GetBankGroup)
 
 	return app
 }
 
+// storedDetail serves sampleDetail(branches) through a service over the
+// memory repository, which reads only the window of branches asked for
+func storedDetail(branches int) service.SwiftService {
+	repo := repository.NewMemorySwiftRepository()
+	detail := sampleDetail(branches)
+	banks := []*models.SwiftBank{&detail.Bank}
+	for i := range detail.Branches {
+		banks = append(banks, &detail.Branches[i])
+	}
+	Expect(repo.CreateBatch(context.Background(), banks)).To(Succeed())
+	return service.NewSwiftService(repo)
+}
+
 var _ = Describe("Swift Handler", func() {
 	var (
 		app     *fiber.App
@@ -67,7 +96,7 @@ var _ = Describe("Swift Handler", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(resp.StatusCode).To(Equal(http.StatusOK))
 
-				var bank repository.SwiftBankDetail
+				var bank handlers.SwiftCodeResponse
 				err = json.NewDecoder(resp.Body).Decode(&bank)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(bank.Bank.SwiftCode).To(Equal("ABC"))
@@ -86,10 +115,11 @@ var _ = Describe("Swift Handler", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
 
-				var body map[string]string
+				var body apierror.Error
 				err = json.NewDecoder(resp.Body).Decode(&body)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(body["message"]).To(Equal("SWIFT code not found"))
+				Expect(body.Code).To(Equal(apierror.CodeNotFound))
+				Expect(body.Message).To(Equal("SWIFT code not found"))
 			})
 		})
 
@@ -104,18 +134,319 @@ var _ = Describe("Swift Handler", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
 
-				var body map[string]string
+				var body apierror.Error
 				err = json.NewDecoder(resp.Body).Decode(&body)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(body["message"]).To(Equal("Invalid input provided"))
+				Expect(body.Code).To(Equal(apierror.CodeInvalidInput))
+				Expect(body.Message).To(Equal("Invalid input provided"))
+			})
+		})
+
+		Context("when the detail comes from the repository cache", func() {
+			var detail *repository.SwiftBankDetail
+
+			BeforeEach(func() {
+				detail = sampleDetail(2)
+				detail.CacheEntry = 7
+				mockSvc.GetSwiftCodeDetailsFunc = func(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+					served := *detail
+					served.Branches = append([]models.SwiftBank(nil), detail.Branches...)
+					return &served, nil
+				}
+				app = setupApp(mockSvc)
+			})
+
+			get := func(url string) string {
+				resp, err := app.Test(httptest.NewRequest(http.MethodGet, url, nil), fiber.TestConfig{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				body, err := io.ReadAll(resp.Body)
+				Expect(err).NotTo(HaveOccurred())
+				return string(body)
+			}
+
+			It("should match encoding/json", func() {
+				expected, err := json.Marshal(handlers.NewSwiftCodeResponse(detail))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(get("/swift/BSZLPLP1XXX")).To(Equal(string(expected)))
+				Expect(get("/swift/BSZLPLP1XXX")).To(Equal(string(expected)))
 			})
+
+			It("should reuse the encoded branches per cache entry and field mask", func() {
+				Expect(get("/swift/BSZLPLP1XXX")).To(ContainSubstring("DRAWSKO"))
+
+				detail.Branches[0].Address = "UL. NOWA 2"
+				Expect(get("/swift/BSZLPLP1XXX")).To(ContainSubstring("DRAWSKO"))
+				Expect(get("/swift/BSZLPLP1XXX?fields=swiftCode,address")).To(ContainSubstring("UL. NOWA 2"))
+
+				detail.CacheEntry = 8
+				Expect(get("/swift/BSZLPLP1XXX")).To(ContainSubstring("UL. NOWA 2"))
+			})
+
+			It("should encode the branches per request when the detail was not cached", func() {
+				detail.CacheEntry = 0
+				Expect(get("/swift/BSZLPLP1XXX")).To(ContainSubstring("DRAWSKO"))
+
+				detail.Branches[0].Address = "UL. NOWA 2"
+				Expect(get("/swift/BSZLPLP1XXX")).To(ContainSubstring("UL. NOWA 2"))
+			})
+		})
+	})
+
+	Describe("wrapped service errors", func() {
+		It("should map errors wrapped with context", func() {
+			mockSvc.GetSwiftCodeDetailsFunc = func(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+				return nil, fmt.Errorf("dataset eu: %w", fmt.Errorf("%w: %s", service.ErrNotFound, code))
+			}
+			app = setupApp(mockSvc)
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/swift/ABCDUS33XXX", nil), fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+
+		It("should name the invalid field of an input error", func() {
+			mockSvc.GetSwiftCodeDetailsFunc = func(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+				return nil, fmt.Errorf("lookup: %w", &service.InputError{Field: "swiftCode", Reason: "must be an 8 or 11 character SWIFT code"})
+			}
+			app = setupApp(mockSvc)
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/swift/ABC", nil), fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+
+			var body apierror.Error
+			Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+			Expect(body.Code).To(Equal(apierror.CodeInvalidInput))
+			Expect(body.Details).To(ConsistOf(apierror.Detail{Field: "swiftCode", Reason: "must be an 8 or 11 character SWIFT code"}))
+		})
+
+		It("should map wrapped conflicts", func() {
+			mockSvc.CreateSwiftCodeFunc = func(ctx context.Context, bank *models.SwiftBank) error {
+				return fmt.Errorf("%w: %s", service.ErrAlreadyExists, bank.SwiftCode)
+			}
+			app = setupApp(mockSvc)
+			body := `{"swiftCode":"ABCDUS33XXX","bankName":"Test Bank","address":"1 Main St","countryISO2":"US","countryName":"UNITED STATES","isHeadquarter":true}`
+			req := httptest.NewRequest(http.MethodPost, "/swift", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusConflict))
+
+			var apiErr apierror.Error
+			Expect(json.NewDecoder(resp.Body).Decode(&apiErr)).To(Succeed())
+			Expect(apiErr.Code).To(Equal(apierror.CodeAlreadyExists))
+		})
+
+		It("should answer writes during maintenance with 503 and a retry time", func() {
+			end := time.Now().Add(90 * time.Second)
+			mockSvc.DeleteSwiftCodeFunc = func(ctx context.Context, code string) error {
+				return &service.MaintenanceError{Window: service.MaintenanceWindow{End: end, Reason: "upgrade"}}
+			}
+			app = setupApp(mockSvc)
+			resp, err := app.Test(httptest.NewRequest(http.MethodDelete, "/swift/ABCDUS33XXX", nil), fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusServiceUnavailable))
+			Expect(resp.Header.Get(fiber.HeaderRetryAfter)).To(BeElementOf("89", "90"))
+
+			var apiErr apierror.Error
+			Expect(json.NewDecoder(resp.Body).Decode(&apiErr)).To(Succeed())
+			Expect(apiErr.Code).To(Equal(apierror.CodeReadOnly))
+			Expect(apiErr.Message).To(HaveSuffix(end.UTC().Format(time.RFC3339) + ": upgrade"))
+		})
+	})
+
+	Describe("Content negotiation", func() {
+		BeforeEach(func() {
+			mockSvc.GetSwiftCodeDetailsFunc = func(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+				return &repository.SwiftBankDetail{
+					Bank: models.SwiftBank{SwiftCode: "ABCDUS33XXX", BankName: "Test Bank", IsHeadquarter: true},
+					Branches: []models.SwiftBank{
+						{SwiftCode: "ABCDUS33001", BankName: "Test Bank, Branch"},
+					},
+				}, nil
+			}
+		})
+
+		It("should return CSV when requested through the Accept header", func() {
+			app = setupApp(mockSvc)
+			req := httptest.NewRequest(http.MethodGet, "/swift/ABCDUS33XXX", nil)
+			req.Header.Set("Accept", "text/csv")
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("Content-Type")).To(HavePrefix("text/csv"))
+
+			body, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+			Expect(lines).To(HaveLen(3))
+			Expect(lines[0]).To(HavePrefix("swift_code,swift_code_base"))
+			Expect(lines[2]).To(ContainSubstring(`"Test Bank, Branch"`))
+		})
+
+		It("should return XML when requested through the format parameter", func() {
+			app = setupApp(mockSvc)
+			req := httptest.NewRequest(http.MethodGet, "/swift/ABCDUS33XXX?format=xml", nil)
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("Content-Type")).To(HavePrefix("application/xml"))
+
+			var doc handlers.SwiftCodeResponse
+			Expect(xml.NewDecoder(resp.Body).Decode(&doc)).To(Succeed())
+			Expect(doc.Bank.SwiftCode).To(Equal("ABCDUS33XXX"))
+			Expect(doc.Branches).To(HaveLen(1))
+		})
+
+		It("should return protobuf using the gRPC message types", func() {
+			app = setupApp(mockSvc)
+			req := httptest.NewRequest(http.MethodGet, "/swift/ABCDUS33XXX?fields=swiftCode", nil)
+			req.Header.Set("Accept", "application/x-protobuf")
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("Content-Type")).To(Equal("application/x-protobuf"))
+
+			body, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			var msg grpcapi.GetByCodeResponse
+			Expect(proto.Unmarshal(body, &msg)).To(Succeed())
+			Expect(msg.Bank.SwiftCode).To(Equal("ABCDUS33XXX"))
+			Expect(msg.Bank.BankName).To(BeEmpty())
+			Expect(msg.Branches).To(HaveLen(1))
+		})
+
+		It("should reject unsupported formats", func() {
+			app = setupApp(mockSvc)
+			req := httptest.NewRequest(http.MethodGet, "/swift/ABCDUS33XXX?format=yaml", nil)
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNotAcceptable))
+		})
+	})
+
+	Describe("Sparse fieldsets", func() {
+		BeforeEach(func() {
+			mockSvc.GetSwiftCodesByCountryFunc = func(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+				return &repository.CountrySwiftCodes{
+					CountryISO2: "US",
+					CountryName: "UNITED STATES",
+					SwiftCodes: []models.SwiftBank{
+						{SwiftCode: "ABCDUS33XXX", BankName: "Bank A", Address: "Main St"},
+					},
+				}, nil
+			}
+		})
+
+		It("should return only the requested bank fields", func() {
+			app = setupApp(mockSvc)
+			req := httptest.NewRequest(http.MethodGet, "/country/us?fields=swiftCode,bankName", nil)
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			body, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(body)).To(Equal(`{"country_iso2":"US","country_name":"UNITED STATES","swift_codes":[{"SwiftCode":"ABCDUS33XXX","BankName":"Bank A"}]}`))
+		})
+
+		It("should restrict CSV columns", func() {
+			app = setupApp(mockSvc)
+			req := httptest.NewRequest(http.MethodGet, "/country/us?fields=swiftCode,address&format=csv", nil)
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+
+			body, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(body)).To(Equal("swift_code,address\nABCDUS33XXX,Main St\n"))
+		})
+
+		It("should include contacts only when requested", func() {
+			mockSvc.GetSwiftCodeDetailsFunc = func(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+				return &repository.SwiftBankDetail{Bank: models.SwiftBank{
+					SwiftCode: code, BankName: "Bank A", Website: "https://bank-a.example", Phone: "+1 555 0100",
+				}}, nil
+			}
+			app = setupApp(mockSvc)
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/swift/ABCDUS33XXX", nil), fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			body, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(body)).NotTo(ContainSubstring("Website"))
+
+			resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/swift/ABCDUS33XXX?fields=swiftCode,contacts", nil), fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			body, err = io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(body)).To(Equal(`{"bank":{"SwiftCode":"ABCDUS33XXX","Website":"https://bank-a.example","Phone":"+1 555 0100"}}`))
+		})
+
+		It("should reject unknown fields", func() {
+			app = setupApp(mockSvc)
+			req := httptest.NewRequest(http.MethodGet, "/country/us?fields=iban", nil)
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("Computed fields", func() {
+		var h *handlers.SwiftHandler
+
+		BeforeEach(func() {
+			// The banks are read back through the repository, so the town
+			// is the stored one
+			repo := repository.NewMemorySwiftRepository()
+			Expect(repo.CreateBatch(context.Background(), []*models.SwiftBank{
+				{SwiftCode: "PKOPPLPWXXX", CountryISOCode: "PL", BankName: "PKO BP", IsHeadquarter: true, CountryName: "POLAND", Town: "WARSZAWA"},
+				{SwiftCode: "BREXPLPWXXX", CountryISOCode: "PL", BankName: "MBANK", IsHeadquarter: true, CountryName: "POLAND"},
+			})).To(Succeed())
+			h = handlers.NewSwiftHandler(service.NewSwiftService(repo), handlers.Config{ComputedFields: map[string]string{
+				"displayName": `join(", ", bankName, town)`,
+				"label":       `lower(swiftCode) + "@" + countryISO2`,
+			}})
+			app = fiber.New()
+			app.Get("/country/:countryISO2code", h.GetByCountry)
+		})
+
+		get := func(url string) string {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, url, nil), fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			body, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			return string(body)
+		}
+
+		It("should append the fields in name order to JSON and CSV banks", func() {
+			Expect(get("/country/pl")).To(Equal(`{"country_iso2":"PL","country_name":"POLAND","swift_codes":[` +
+				`{"SwiftCode":"BREXPLPWXXX","SwiftCodeBase":"BREXPLPW","CountryISOCode":"PL","BankName":"MBANK","IsHeadquarter":true,"Address":"","CountryName":"POLAND","displayName":"MBANK","label":"brexplpwxxx@PL"},` +
+				`{"SwiftCode":"PKOPPLPWXXX","SwiftCodeBase":"PKOPPLPW","CountryISOCode":"PL","BankName":"PKO BP","IsHeadquarter":true,"Address":"","CountryName":"POLAND","displayName":"PKO BP, WARSZAWA","label":"pkopplpwxxx@PL"}]}`))
+
+			Expect(get("/country/pl?format=csv")).To(HavePrefix("swift_code,swift_code_base,country_iso_code,bank_name,is_headquarter,address,country_name,displayName,label\n"))
+		})
+
+		It("should leave them out of a ?fields= selection", func() {
+			Expect(get("/country/pl?fields=swiftCode")).To(Equal(`{"country_iso2":"PL","country_name":"POLAND","swift_codes":[{"SwiftCode":"BREXPLPWXXX"},{"SwiftCode":"PKOPPLPWXXX"}]}`))
+		})
+
+		It("should swap the fields on reload", func() {
+			h.SetComputedFields(map[string]string{"shortName": `upper(bankName)`})
+			Expect(get("/country/pl")).To(ContainSubstring(`"shortName":"MBANK"`))
+			Expect(get("/country/pl")).NotTo(ContainSubstring("displayName"))
+		})
+
+		It("should reject fields clashing with bank fields or with invalid expressions", func() {
+			Expect(handlers.Config{ComputedFields: map[string]string{"bank_name": `town`}}.Validate()).To(MatchError(ContainSubstring("clashes")))
+			Expect(handlers.Config{ComputedFields: map[string]string{"x": `iban`}}.Validate()).To(MatchError(ContainSubstring(`unknown variable "iban"`)))
+			Expect(handlers.Config{ComputedFields: map[string]string{"x": `town`}}.Validate()).To(Succeed())
 		})
 	})
 
 	Describe("GetByCountry", func() {
 		Context("when called with a country that has swift codes", func() {
 			It("should return a list of swift codes", func() {
-				mockSvc.GetSwiftCodesByCountryFunc = func(ctx context.Context, countryCode string) (*repository.CountrySwiftCodes, error) {
+				mockSvc.GetSwiftCodesByCountryFunc = func(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
 					return &repository.CountrySwiftCodes{
 						CountryISO2: strings.ToUpper(countryCode),
 						CountryName: "Test Country",
@@ -138,6 +469,259 @@ var _ = Describe("Swift Handler", func() {
 				Expect(countryCodes.SwiftCodes[0].SwiftCode).To(Equal("ABC"))
 			})
 		})
+
+		Context("when list parameters are given", func() {
+			It("should pass the sort to the service", func() {
+				var got repository.QueryOptions
+				mockSvc.GetSwiftCodesByCountryFunc = func(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+					got = opts
+					return &repository.CountrySwiftCodes{CountryISO2: "US"}, nil
+				}
+				app = setupApp(mockSvc)
+				req := httptest.NewRequest(http.MethodGet, "/country/us?sort=countryName:desc", nil)
+				resp, err := app.Test(req, fiber.TestConfig{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				Expect(got.Sort).To(Equal(repository.Sort{Field: repository.SortCountryName, Descending: true}))
+			})
+
+			It("should pass the bank type filter to the service", func() {
+				var got repository.QueryOptions
+				mockSvc.GetSwiftCodesByCountryFunc = func(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+					got = opts
+					return &repository.CountrySwiftCodes{CountryISO2: "US"}, nil
+				}
+				app = setupApp(mockSvc)
+				req := httptest.NewRequest(http.MethodGet, "/country/us?type=branch", nil)
+				resp, err := app.Test(req, fiber.TestConfig{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				Expect(got.Type).To(Equal(repository.BankTypeBranch))
+
+				req = httptest.NewRequest(http.MethodGet, "/country/us?type=atm", nil)
+				resp, err = app.Test(req, fiber.TestConfig{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+			})
+
+			It("should pass address and town search terms to the service", func() {
+				var got repository.QueryOptions
+				mockSvc.GetSwiftCodesByCountryFunc = func(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+					got = opts
+					return &repository.CountrySwiftCodes{CountryISO2: "US"}, nil
+				}
+				app = setupApp(mockSvc)
+				req := httptest.NewRequest(http.MethodGet, "/country/us?address=main%20st&town=Springfield", nil)
+				resp, err := app.Test(req, fiber.TestConfig{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				Expect(got.Address).To(Equal("main st"))
+				Expect(got.Town).To(Equal("Springfield"))
+
+				req = httptest.NewRequest(http.MethodGet, "/country/us?town="+strings.Repeat("x", 101), nil)
+				resp, err = app.Test(req, fiber.TestConfig{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+			})
+
+			It("should reject unknown sort fields", func() {
+				app = setupApp(mockSvc)
+				req := httptest.NewRequest(http.MethodGet, "/country/us?sort=address", nil)
+				resp, err := app.Test(req, fiber.TestConfig{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+
+	Describe("Paged listings", func() {
+		BeforeEach(func() {
+			mockSvc.GetSwiftCodesByCountryFunc = func(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+				return &repository.CountrySwiftCodes{
+					CountryISO2: "US",
+					CountryName: "UNITED STATES",
+					SwiftCodes:  []models.SwiftBank{{SwiftCode: "ABCDUS33XXX"}},
+					Total:       7,
+				}, nil
+			}
+		})
+
+		It("should wrap list responses in data and meta when requested", func() {
+			app = fiber.New()
+			app.Get("/country/:countryISO2code", handlers.NewSwiftHandler(mockSvc).GetByCountry, handlers.Envelope(handlers.Config{}, middleware.AuthConfig{}, attribution.Config{}))
+			req := httptest.NewRequest(http.MethodGet, "/country/us?envelope=true&limit=1&offset=3", nil)
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var body struct {
+				Data   repository.CountrySwiftCodes `json:"data"`
+				Meta   handlers.Meta                `json:"meta"`
+				Errors []apierror.Error             `json:"errors"`
+			}
+			Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+			Expect(body.Data.SwiftCodes).To(HaveLen(1))
+			Expect(body.Errors).To(BeEmpty())
+			Expect(body.Meta.Total).To(Equal(7))
+			Expect(body.Meta.Limit).To(Equal(1))
+			Expect(body.Meta.Offset).To(Equal(3))
+			Expect(body.Meta.GeneratedAt).NotTo(BeZero())
+		})
+
+		It("should report the country version in meta", func() {
+			app = fiber.New()
+			app.Get("/country/:countryISO2code", handlers.NewSwiftHandler(mockSvc).GetByCountry,
+				handlers.Envelope(handlers.Config{Envelope: true}, middleware.AuthConfig{}, attribution.Config{}),
+				middleware.CountryETag("countryISO2code", func(string) uint64 { return 42 }))
+			req := httptest.NewRequest(http.MethodGet, "/country/us", nil)
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Header.Get(fiber.HeaderETag)).To(Equal(`W/"42"`))
+
+			var body struct {
+				Meta handlers.Meta `json:"meta"`
+			}
+			Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+			Expect(body.Meta.Version).To(BeEquivalentTo(42))
+		})
+
+		It("should wrap responses when enabled in config", func() {
+			app = fiber.New()
+			app.Get("/country/:countryISO2code", handlers.NewSwiftHandler(mockSvc).GetByCountry, handlers.Envelope(handlers.Config{Envelope: true}, middleware.AuthConfig{}, attribution.Config{}))
+			req := httptest.NewRequest(http.MethodGet, "/country/us", nil)
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+
+			var body map[string]any
+			Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+			Expect(body).To(HaveKey("data"))
+			Expect(body).To(HaveKey("meta"))
+		})
+
+		It("should emit total count and Link headers for paged requests", func() {
+			app = setupApp(mockSvc)
+			req := httptest.NewRequest(http.MethodGet, "/country/us?limit=2&offset=2&sort=bankName", nil)
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Header.Get("X-Total-Count")).To(Equal("7"))
+
+			link := resp.Header.Get("Link")
+			Expect(link).To(ContainSubstring(`/country/us?limit=2&offset=0&sort=bankName>; rel="first"`))
+			Expect(link).To(ContainSubstring(`/country/us?limit=2&offset=0&sort=bankName>; rel="prev"`))
+			Expect(link).To(ContainSubstring(`/country/us?limit=2&offset=4&sort=bankName>; rel="next"`))
+			Expect(link).To(ContainSubstring(`/country/us?limit=2&offset=6&sort=bankName>; rel="last"`))
+		})
+
+		It("should pass the cursor on and link to the next page by cursor", func() {
+			var got repository.QueryOptions
+			mockSvc.GetSwiftCodesByCountryFunc = func(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+				got = opts
+				return &repository.CountrySwiftCodes{
+					CountryISO2: "US",
+					SwiftCodes:  []models.SwiftBank{{SwiftCode: "ABCDUS33XXX"}},
+					Total:       7,
+				}, nil
+			}
+			app = setupApp(mockSvc)
+			req := httptest.NewRequest(http.MethodGet, "/country/us?limit=1&after=aaaaus33xxx", nil)
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(got.After).To(Equal("AAAAUS33XXX"))
+			Expect(resp.Header.Get("X-Total-Count")).To(Equal("7"))
+			Expect(resp.Header.Get(handlers.HeaderNextCursor)).To(Equal("ABCDUS33XXX"))
+
+			link := resp.Header.Get("Link")
+			Expect(link).To(ContainSubstring(`/country/us?limit=1>; rel="first"`))
+			Expect(link).To(ContainSubstring(`/country/us?after=ABCDUS33XXX&limit=1>; rel="next"`))
+
+			app = fiber.New()
+			app.Get("/country/:countryISO2code", handlers.NewSwiftHandler(mockSvc).GetByCountry, handlers.Envelope(handlers.Config{}, middleware.AuthConfig{}, attribution.Config{}))
+			req = httptest.NewRequest(http.MethodGet, "/country/us?envelope=true&limit=1&sort=swiftCode", nil)
+			resp, err = app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			var body struct {
+				Meta handlers.Meta `json:"meta"`
+			}
+			Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+			Expect(body.Meta.NextCursor).To(Equal("ABCDUS33XXX"))
+		})
+
+		It("should reject a malformed cursor or one combined with an offset", func() {
+			app = setupApp(mockSvc)
+			for _, query := range []string{"after=ABC-US33", "after=ABCDUS33XXX&offset=2", "after=ABCDUS33XXX&sort=bankName"} {
+				req := httptest.NewRequest(http.MethodGet, "/country/us?"+query, nil)
+				resp, err := app.Test(req, fiber.TestConfig{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusBadRequest), query)
+			}
+		})
+
+		It("should omit Link when the request is not paged", func() {
+			app = setupApp(mockSvc)
+			req := httptest.NewRequest(http.MethodGet, "/country/us", nil)
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Header.Get("X-Total-Count")).To(Equal("7"))
+			Expect(resp.Header.Get("Link")).To(BeEmpty())
+		})
+
+		It("should reject out-of-range page sizes", func() {
+			app = setupApp(mockSvc)
+			req := httptest.NewRequest(http.MethodGet, "/country/us?limit=5000", nil)
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("Validate", func() {
+		It("should describe the code without calling the service", func() {
+			app = setupApp(mockSvc)
+			req := httptest.NewRequest(http.MethodGet, "/swift/ABCDUS33XXX/validate", nil)
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var breakdown service.SwiftCodeBreakdown
+			Expect(json.NewDecoder(resp.Body).Decode(&breakdown)).To(Succeed())
+			Expect(breakdown.FormatValid).To(BeTrue())
+			Expect(breakdown.CountryISO2).To(Equal("US"))
+			Expect(breakdown.IsHeadquarter).To(BeTrue())
+		})
+	})
+
+	Describe("DatasetStatus", func() {
+		It("should report the dataset state", func() {
+			mockSvc.DatasetStatusFunc = func(ctx context.Context) (*service.DatasetStatus, error) {
+				return &service.DatasetStatus{Status: service.DatasetEmpty}, nil
+			}
+			app = setupApp(mockSvc)
+			req := httptest.NewRequest(http.MethodGet, "/dataset/status", nil)
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var status map[string]any
+			Expect(json.NewDecoder(resp.Body).Decode(&status)).To(Succeed())
+			Expect(status["status"]).To(Equal("empty"))
+			Expect(status["total_codes"]).To(BeEquivalentTo(0))
+		})
+
+		It("should answer 503 while the startup import runs", func() {
+			mockSvc.DatasetStatusFunc = func(ctx context.Context) (*service.DatasetStatus, error) {
+				return &service.DatasetStatus{Status: service.DatasetLoading}, nil
+			}
+			app = setupApp(mockSvc)
+			req := httptest.NewRequest(http.MethodGet, "/dataset/status", nil)
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusServiceUnavailable))
+
+			var status map[string]any
+			Expect(json.NewDecoder(resp.Body).Decode(&status)).To(Succeed())
+			Expect(status["status"]).To(Equal("loading"))
+		})
 	})
 
 	Describe("Create", func() {
@@ -167,6 +751,66 @@ var _ = Describe("Swift Handler", func() {
 			})
 		})
 
+		Context("when the body sets server-managed fields", func() {
+			body := `{"swiftCode":"ABCDUS33ABC","swiftCodeBase":"WXYZUS33","isHeadquarter":true,"createdAt":"2026-01-01T00:00:00Z","bankName":"New Bank"}`
+
+			It("should name each contradicting field in strict mode", func() {
+				app = fiber.New()
+				app.Post("/swift", handlers.NewSwiftHandler(mockSvc, handlers.Config{StrictSchema: true}).Create)
+				req := httptest.NewRequest(http.MethodPost, "/swift", strings.NewReader(body))
+				req.Header.Set("Content-Type", "application/json")
+				resp, err := app.Test(req, fiber.TestConfig{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+
+				var apiErr apierror.Error
+				Expect(json.NewDecoder(resp.Body).Decode(&apiErr)).To(Succeed())
+				Expect(apiErr.Details).To(HaveLen(3))
+				Expect(apiErr.Details[0].Field).To(Equal("createdAt"))
+				Expect(apiErr.Details[1].Field).To(Equal("isHeadquarter"))
+				Expect(apiErr.Details[2].Field).To(Equal("swiftCodeBase"))
+			})
+
+			It("should accept values consistent with the code in strict mode", func() {
+				mockSvc.CreateSwiftCodeFunc = func(ctx context.Context, bank *models.SwiftBank) error { return nil }
+				app = fiber.New()
+				app.Post("/swift", handlers.NewSwiftHandler(mockSvc, handlers.Config{StrictSchema: true}).Create)
+				req := httptest.NewRequest(http.MethodPost, "/swift", strings.NewReader(`{"swiftCode":"abcdus33xxx","swiftCodeBase":"ABCDUS33","isHeadquarter":true,"bankName":"New Bank"}`))
+				req.Header.Set("Content-Type", "application/json")
+				resp, err := app.Test(req, fiber.TestConfig{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+			})
+
+			It("should leave them to the service otherwise", func() {
+				mockSvc.CreateSwiftCodeFunc = func(ctx context.Context, bank *models.SwiftBank) error { return nil }
+				app = setupApp(mockSvc)
+				req := httptest.NewRequest(http.MethodPost, "/swift", strings.NewReader(body))
+				req.Header.Set("Content-Type", "application/json")
+				resp, err := app.Test(req, fiber.TestConfig{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+			})
+		})
+
+		Context("when the code is an alias of an existing bank", func() {
+			It("should return a distinct conflict code", func() {
+				mockSvc.CreateSwiftCodeFunc = func(ctx context.Context, bank *models.SwiftBank) error {
+					return service.ErrAliasConflict
+				}
+				app = setupApp(mockSvc)
+				req := httptest.NewRequest(http.MethodPost, "/swift", strings.NewReader(`{"SwiftCode":"ABCDUS33XXX"}`))
+				req.Header.Set("Content-Type", "application/json")
+				resp, err := app.Test(req, fiber.TestConfig{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusConflict))
+
+				var body apierror.Error
+				Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+				Expect(body.Code).To(Equal(apierror.CodeAliasConflict))
+			})
+		})
+
 		Context("when provided with an invalid request body", func() {
 			It("should return a bad request error", func() {
 				mockSvc.CreateSwiftCodeFunc = func(ctx context.Context, bank *models.SwiftBank) error {
@@ -183,6 +827,121 @@ var _ = Describe("Swift Handler", func() {
 		})
 	})
 
+	Describe("conditional writes", func() {
+		send := func(method, target, body string, headers map[string]string) *http.Response {
+			app = setupApp(mockSvc)
+			req := httptest.NewRequest(method, target, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			for name, value := range headers {
+				req.Header.Set(name, value)
+			}
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			return resp
+		}
+
+		It("should upsert on PUT without conditions", func() {
+			mockSvc.CreateSwiftCodeFunc = func(ctx context.Context, bank *models.SwiftBank) error {
+				Expect(service.WriteModeOf(ctx)).To(Equal(service.WriteUpsert))
+				Expect(bank.SwiftCode).To(Equal("ABCDUS33XXX"))
+				return nil
+			}
+			resp := send(http.MethodPut, "/swift/ABCDUS33XXX", `{"BankName":"Test Bank"}`, nil)
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		})
+
+		It("should create only if absent with If-None-Match: *", func() {
+			mockSvc.CreateSwiftCodeFunc = func(ctx context.Context, bank *models.SwiftBank) error {
+				Expect(service.WriteModeOf(ctx)).To(Equal(service.WriteCreate))
+				return fmt.Errorf("%w: %s", service.ErrAlreadyExists, bank.SwiftCode)
+			}
+			resp := send(http.MethodPut, "/swift/ABCDUS33XXX", `{}`, map[string]string{"If-None-Match": "*"})
+			Expect(resp.StatusCode).To(Equal(http.StatusPreconditionFailed))
+
+			var body apierror.Error
+			Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+			Expect(body.Code).To(Equal(apierror.CodePreconditionFailed))
+		})
+
+		It("should only replace a stored code with If-Match: *", func() {
+			mockSvc.CreateSwiftCodeFunc = func(ctx context.Context, bank *models.SwiftBank) error {
+				Expect(service.WriteModeOf(ctx)).To(Equal(service.WriteUpdate))
+				return service.ErrNotFound
+			}
+			resp := send(http.MethodPost, "/swift", `{"SwiftCode":"ABCDUS33XXX"}`, map[string]string{"If-Match": "*"})
+			Expect(resp.StatusCode).To(Equal(http.StatusPreconditionFailed))
+		})
+
+		It("should keep 409 for unconditional creates", func() {
+			mockSvc.CreateSwiftCodeFunc = func(ctx context.Context, bank *models.SwiftBank) error {
+				return service.ErrAlreadyExists
+			}
+			resp := send(http.MethodPost, "/swift", `{"SwiftCode":"ABCDUS33XXX"}`, nil)
+			Expect(resp.StatusCode).To(Equal(http.StatusConflict))
+		})
+
+		It("should reject entity tags and a body naming another code", func() {
+			resp := send(http.MethodPut, "/swift/ABCDUS33XXX", `{}`, map[string]string{"If-None-Match": `"v1"`})
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+
+			resp = send(http.MethodPut, "/swift/ABCDUS33XXX", `{"SwiftCode":"ABCDGB22XXX"}`, nil)
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("dry run", func() {
+		It("should validate a create without reporting it as created", func() {
+			mockSvc.CreateSwiftCodeFunc = func(ctx context.Context, bank *models.SwiftBank) error {
+				Expect(service.IsDryRun(ctx)).To(BeTrue())
+				return nil
+			}
+			app = setupApp(mockSvc)
+			req := httptest.NewRequest(http.MethodPost, "/swift?dryRun=true", strings.NewReader(`{"swiftCode":"ABCDUS33XXX"}`))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var body map[string]any
+			Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+			Expect(body).To(HaveKeyWithValue("message", "SWIFT code would be created"))
+			Expect(body).To(HaveKeyWithValue("dry_run", true))
+		})
+
+		It("should surface conflicts found during a dry run", func() {
+			mockSvc.DeleteSwiftCodeFunc = func(ctx context.Context, code string) error {
+				Expect(service.IsDryRun(ctx)).To(BeTrue())
+				return service.ErrNotFound
+			}
+			app = setupApp(mockSvc)
+			resp, err := app.Test(httptest.NewRequest(http.MethodDelete, "/swift/ABCDUS33XXX?dryRun=1", nil), fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+
+		It("should report how many codes a country delete would remove", func() {
+			mockSvc.DeleteByCountryFunc = func(ctx context.Context, countryCode string) (int64, error) {
+				Expect(service.IsDryRun(ctx)).To(BeTrue())
+				return 4, nil
+			}
+			app = setupApp(mockSvc)
+			resp, err := app.Test(httptest.NewRequest(http.MethodDelete, "/country/PL?dryRun=true", nil), fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+
+			var body map[string]any
+			Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+			Expect(body).To(HaveKeyWithValue("deleted", BeNumerically("==", 4)))
+			Expect(body).To(HaveKeyWithValue("dry_run", true))
+		})
+
+		It("should reject a malformed dryRun flag", func() {
+			app = setupApp(mockSvc)
+			resp, err := app.Test(httptest.NewRequest(http.MethodDelete, "/swift/ABCDUS33XXX?dryRun=maybe", nil), fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+	})
+
 	Describe("Delete", func() {
 		Context("when deletion is successful", func() {
 			It("should delete the swift code successfully", func() {
@@ -213,10 +972,11 @@ var _ = Describe("Swift Handler", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
 
-				var body map[string]string
+				var body apierror.Error
 				err = json.NewDecoder(resp.Body).Decode(&body)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(body["message"]).To(Equal("SWIFT code not found"))
+				Expect(body.Code).To(Equal(apierror.CodeNotFound))
+				Expect(body.Message).To(Equal("SWIFT code not found"))
 			})
 		})
 
@@ -231,11 +991,267 @@ var _ = Describe("Swift Handler", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
 
-				var body map[string]string
+				var body apierror.Error
 				err = json.NewDecoder(resp.Body).Decode(&body)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(body["message"]).To(Equal("Invalid input provided"))
+				Expect(body.Code).To(Equal(apierror.CodeInvalidInput))
+				Expect(body.Message).To(Equal("Invalid input provided"))
 			})
 		})
 	})
+
+	Describe("DeleteByCountry", func() {
+		It("should report the number of deleted codes", func() {
+			mockSvc.DeleteByCountryFunc = func(ctx context.Context, countryCode string) (int64, error) {
+				Expect(countryCode).To(Equal("PL"))
+				return 12, nil
+			}
+			app = setupApp(mockSvc)
+			req := httptest.NewRequest(http.MethodDelete, "/country/pl", nil)
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var body map[string]any
+			err = json.NewDecoder(resp.Body).Decode(&body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body["message"]).To(Equal("SWIFT codes deleted successfully"))
+			Expect(body["deleted"]).To(BeNumerically("==", 12))
+		})
+
+		It("should return a bad request for an invalid country code", func() {
+			mockSvc.DeleteByCountryFunc = func(ctx context.Context, countryCode string) (int64, error) {
+				return 0, service.ErrInvalidInput
+			}
+			app = setupApp(mockSvc)
+			req := httptest.NewRequest(http.MethodDelete, "/country/POL", nil)
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("GetByCode with many branches", func() {
+		It("should embed only the first branches and link to the rest", func() {
+			app = fiber.New()
+			app.Get("/swift/:swiftCode", handlers.NewSwiftHandler(storedDetail(5), handlers.Config{MaxEmbeddedBranches: 2}).GetByCode)
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/swift/BSZLPLP1XXX", nil)
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var detail handlers.SwiftCodeResponse
+			Expect(json.NewDecoder(resp.Body).Decode(&detail)).To(Succeed())
+			Expect(detail.Branches).To(HaveLen(2))
+			Expect(detail.BranchesTotal).To(Equal(5))
+			Expect(detail.BranchesLink).To(Equal("http://example.com/swift/BSZLPLP1XXX/branches?limit=2&offset=2"))
+		})
+
+		It("should link to pages the branches endpoint accepts", func() {
+			app = fiber.New()
+			app.Get("/swift/:swiftCode", handlers.NewSwiftHandler(storedDetail(5), handlers.Config{MaxEmbeddedBranches: 3, MaxPageSize: 2}).GetByCode)
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/swift/BSZLPLP1XXX", nil)
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+
+			var detail handlers.SwiftCodeResponse
+			Expect(json.NewDecoder(resp.Body).Decode(&detail)).To(Succeed())
+			Expect(detail.Branches).To(HaveLen(3))
+			Expect(detail.BranchesLink).To(Equal("http://example.com/swift/BSZLPLP1XXX/branches?limit=2&offset=3"))
+		})
+
+		It("should leave small branch lists untouched", func() {
+			app = setupApp(storedDetail(3))
+			req := httptest.NewRequest(http.MethodGet, "/swift/BSZLPLP1XXX", nil)
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+
+			body, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(body)).NotTo(ContainSubstring("branches_total"))
+			Expect(string(body)).NotTo(ContainSubstring("branches_link"))
+		})
+	})
+
+	Describe("GetByCode with branch paging", func() {
+		BeforeEach(func() {
+			app = fiber.New()
+			app.Get("/swift/:swiftCode", handlers.NewSwiftHandler(storedDetail(5), handlers.Config{MaxEmbeddedBranches: 3}).GetByCode)
+		})
+
+		It("should return the window of branches with the count and a next link", func() {
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/swift/BSZLPLP1XXX?branchLimit=2&branchOffset=1&fields=SwiftCode", nil)
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var detail handlers.SwiftCodeResponse
+			Expect(json.NewDecoder(resp.Body).Decode(&detail)).To(Succeed())
+			Expect(detail.Branches).To(HaveLen(2))
+			Expect(detail.Branches[0].SwiftCode).To(Equal("BSZLPLP1001"))
+			Expect(detail.BranchesTotal).To(Equal(5))
+			Expect(detail.BranchesLink).To(Equal("http://example.com/swift/BSZLPLP1XXX?branchLimit=2&branchOffset=3&fields=SwiftCode"))
+		})
+
+		It("should default the window to the embedded branch cap and end without a link", func() {
+			req := httptest.NewRequest(http.MethodGet, "/swift/BSZLPLP1XXX?branchOffset=3", nil)
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+
+			var detail handlers.SwiftCodeResponse
+			Expect(json.NewDecoder(resp.Body).Decode(&detail)).To(Succeed())
+			Expect(detail.Branches).To(HaveLen(2))
+			Expect(detail.BranchesTotal).To(Equal(5))
+			Expect(detail.BranchesLink).To(BeEmpty())
+		})
+
+		It("should reject a window larger than the embedded branch cap", func() {
+			req := httptest.NewRequest(http.MethodGet, "/swift/BSZLPLP1XXX?branchLimit=4", nil)
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("GetBranches", func() {
+		BeforeEach(func() {
+			app = setupApp(storedDetail(5))
+		})
+
+		It("should return the requested window of branches", func() {
+			req := httptest.NewRequest(http.MethodGet, "/swift/BSZLPLP1XXX/branches?limit=2&offset=4", nil)
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get(handlers.HeaderTotalCount)).To(Equal("5"))
+
+			var detail handlers.SwiftCodeResponse
+			Expect(json.NewDecoder(resp.Body).Decode(&detail)).To(Succeed())
+			Expect(detail.Bank.SwiftCode).To(Equal("BSZLPLP1XXX"))
+			Expect(detail.Branches).To(HaveLen(1))
+			Expect(detail.Branches[0].SwiftCode).To(Equal("BSZLPLP1004"))
+		})
+
+		It("should reject invalid pagination parameters", func() {
+			req := httptest.NewRequest(http.MethodGet, "/swift/BSZLPLP1XXX/branches?offset=-1", nil)
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("GetHeadquarters", func() {
+		BeforeEach(func() {
+			mockSvc.GetHeadquartersFunc = func(ctx context.Context, code string) (*models.SwiftBank, error) {
+				if code != "BSZLPLP1WAW" {
+					return nil, fmt.Errorf("%w: headquarters of %s", service.ErrNotFound, code)
+				}
+				return &models.SwiftBank{SwiftCode: "BSZLPLP1XXX", SwiftCodeBase: "BSZLPLP1", IsHeadquarter: true}, nil
+			}
+			app = setupApp(mockSvc)
+		})
+
+		It("should return the headquarters of a branch", func() {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/swift/bszlplp1waw/headquarters", nil), fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var detail handlers.SwiftCodeResponse
+			Expect(json.NewDecoder(resp.Body).Decode(&detail)).To(Succeed())
+			Expect(detail.Bank.SwiftCode).To(Equal("BSZLPLP1XXX"))
+			Expect(detail.Branches).To(BeNil())
+		})
+
+		It("should write only the requested fields", func() {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/swift/BSZLPLP1WAW/headquarters?fields=swiftCode", nil), fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			body, _ := io.ReadAll(resp.Body)
+			Expect(string(body)).To(Equal(`{"bank":{"SwiftCode":"BSZLPLP1XXX"}}`))
+		})
+
+		It("should answer 404 when the headquarters is not stored", func() {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/swift/ABCDPLPWXYZ/headquarters", nil), fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+			body, _ := io.ReadAll(resp.Body)
+			Expect(string(body)).To(ContainSubstring("Headquarters not found"))
+		})
+	})
+
+	Describe("GetBankGroup", func() {
+		BeforeEach(func() {
+			mockSvc.GetBankGroupFunc = func(ctx context.Context, base string) (*repository.BankGroup, error) {
+				switch base {
+				case "BSZLPLP1":
+					return &repository.BankGroup{
+						SwiftCodeBase: "BSZLPLP1",
+						Headquarters:  &models.SwiftBank{SwiftCode: "BSZLPLP1XXX", IsHeadquarter: true},
+						Branches:      []models.SwiftBank{{SwiftCode: "BSZLPLP1WAW"}},
+					}, nil
+				case "ABCDPLPW":
+					return &repository.BankGroup{SwiftCodeBase: "ABCDPLPW", Branches: []models.SwiftBank{{SwiftCode: "ABCDPLPWKRK"}}}, nil
+				}
+				return nil, fmt.Errorf("%w: swift code base %s", service.ErrNotFound, base)
+			}
+			app = setupApp(mockSvc)
+		})
+
+		It("should return the headquarters and branches of the base", func() {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/swiftBases/bszlplp1", nil), fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var group handlers.BankGroupResponse
+			Expect(json.NewDecoder(resp.Body).Decode(&group)).To(Succeed())
+			Expect(group.SwiftCodeBase).To(Equal("BSZLPLP1"))
+			Expect(group.Headquarters.SwiftCode).To(Equal("BSZLPLP1XXX"))
+			Expect(group.Branches).To(ConsistOf(HaveField("SwiftCode", "BSZLPLP1WAW")))
+		})
+
+		It("should write a missing headquarters as null and only the requested fields", func() {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/swiftBases/ABCDPLPW?fields=swiftCode", nil), fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			body, _ := io.ReadAll(resp.Body)
+			Expect(string(body)).To(Equal(`{"swift_code_base":"ABCDPLPW","headquarters":null,"branches":[{"SwiftCode":"ABCDPLPWKRK"}]}`))
+		})
+
+		It("should list every code of the group in CSV", func() {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/swiftBases/BSZLPLP1?format=csv&fields=swiftCode", nil), fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			body, _ := io.ReadAll(resp.Body)
+			Expect(string(body)).To(Equal("swift_code\nBSZLPLP1XXX\nBSZLPLP1WAW\n"))
+		})
+
+		It("should answer 404 for an unknown base", func() {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/swiftBases/EFGHPLPW", nil), fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("partial branch data", func() {
+		BeforeEach(func() {
+			mockSvc.GetSwiftCodeDetailsFunc = func(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+				return &repository.SwiftBankDetail{
+					Bank:                models.SwiftBank{SwiftCode: "BSZLPLP1XXX", IsHeadquarter: true},
+					BranchesUnavailable: true,
+				}, nil
+			}
+			app = setupApp(mockSvc)
+		})
+
+		It("should flag the headquarters as returned without branches", func() {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/swift/BSZLPLP1XXX", nil), fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			body, _ := io.ReadAll(resp.Body)
+			Expect(string(body)).To(HaveSuffix(`,"branches_unavailable":true}`))
+		})
+
+		It("should not pass off missing branches as an empty page", func() {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/swift/BSZLPLP1XXX/branches", nil), fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusServiceUnavailable))
+		})
+	})
 })
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+// BankV2 is the v2 JSON shape of a SWIFT code. Entries nested in a country
+// or headquarters response leave out the country name.
+type BankV2 struct {
+	Address       string `json:"address"`
+	BankName      string `json:"bankName"`
+	CountryISO2   string `json:"countryISO2"`
+	CountryName   string `json:"countryName,omitempty"`
+	IsHeadquarter bool   `json:"isHeadquarter"`
+	SwiftCode     string `json:"swiftCode"`
+}
+
+// SwiftCodeV2 is the v2 response for a single SWIFT code; headquarters
+// carry their branches
+type SwiftCodeV2 struct {
+	BankV2
+	Branches      []BankV2 `json:"branches,omitempty"`
+	BranchesTotal int      `json:"branchesTotal,omitempty"`
+	BranchesLink  string   `json:"branchesLink,omitempty"`
+	// BranchesUnavailable is set when the branches could not be read
+	BranchesUnavailable bool `json:"branchesUnavailable,omitempty"`
+}
+
+// CountryV2 is the v2 response for the SWIFT codes of a country
+type CountryV2 struct {
+	CountryISO2 string   `json:"countryISO2"`
+	CountryName string   `json:"countryName"`
+	SwiftCodes  []BankV2 `json:"swiftCodes"`
+}
+
+// CreateRequestV2 is the v2 request body for adding a SWIFT code
+type CreateRequestV2 struct {
+	Address       string `json:"address"`
+	BankName      string `json:"bankName"`
+	CountryISO2   string `json:"countryISO2"`
+	CountryName   string `json:"countryName"`
+	IsHeadquarter bool   `json:"isHeadquarter"`
+	SwiftCode     string `json:"swiftCode"`
+}
+
+func newBankV2(bank models.SwiftBank, withCountryName bool) BankV2 {
+	dto := BankV2{
+		Address:       bank.Address,
+		BankName:      bank.BankName,
+		CountryISO2:   bank.CountryISOCode,
+		IsHeadquarter: bank.IsHeadquarter,
+		SwiftCode:     bank.SwiftCode,
+	}
+	if withCountryName {
+		dto.CountryName = bank.CountryName
+	}
+	return dto
+}
+
+func newBanksV2(banks []models.SwiftBank) []BankV2 {
+	dtos := make([]BankV2, len(banks))
+	for i, bank := range banks {
+		dtos[i] = newBankV2(bank, false)
+	}
+	return dtos
+}
+
+func newSwiftCodeV2(detail *repository.SwiftBankDetail) SwiftCodeV2 {
+	dto := SwiftCodeV2{BankV2: newBankV2(detail.Bank, true)}
+	if detail.Bank.IsHeadquarter {
+		dto.Branches = newBanksV2(detail.Branches)
+	}
+	return dto
+}
+
+// GetByCodeV2 handles v2 requests for a specific SWIFT code
+func (h *SwiftHandler) GetByCodeV2(c fiber.Ctx) error {
+	code := strings.ToUpper(c.Params("swiftCode"))
+
+	detail, err := h.service.GetSwiftCodeDetails(h.embeddedBranches(c), code)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	resp := newSwiftCodeV2(detail)
+	resp.BranchesUnavailable = detail.BranchesUnavailable
+	if link := h.branchesLink(c, detail); link != "" {
+		resp.BranchesTotal = detail.BranchesTotal
+		resp.BranchesLink = link
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// GetBranchesV2 pages through the branches of a headquarters in the v2 shape
+func (h *SwiftHandler) GetBranchesV2(c fiber.Ctx) error {
+	code := strings.ToUpper(c.Params("swiftCode"))
+
+	limit, offset, ok := h.parsePage(c)
+	if !ok {
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid pagination parameters")
+	}
+
+	detail, err := h.service.GetSwiftCodeDetails(service.WithBranchWindow(c.Context(), limit, offset), code)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	if detail.BranchesUnavailable {
+		return branchesUnavailable(c)
+	}
+
+	setPaginationHeaders(c, detail.BranchesTotal, limit, offset)
+	return c.Status(fiber.StatusOK).JSON(newSwiftCodeV2(detail))
+}
+
+// GetByCountryV2 handles v2 requests for all SWIFT codes of a country
+func (h *SwiftHandler) GetByCountryV2(c fiber.Ctx) error {
+	countryCode := strings.ToUpper(c.Params("countryISO2code"))
+
+	opts, ok := h.listOptions(c)
+	if !ok {
+		return nil
+	}
+
+	codes, err := h.service.GetSwiftCodesByCountry(c.Context(), countryCode, opts)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	setListHeaders(c, codes.Total, opts, codes.SwiftCodes)
+	return c.Status(fiber.StatusOK).JSON(CountryV2{
+		CountryISO2: codes.CountryISO2,
+		CountryName: codes.CountryName,
+		SwiftCodes:  newBanksV2(codes.SwiftCodes),
+	})
+}
+
+// CreateV2 handles v2 creation of a SWIFT code from a camelCase body
+func (h *SwiftHandler) CreateV2(c fiber.Ctx) error {
+	var req CreateRequestV2
+	ctx, dryRun, ok := writeContext(c)
+	if !ok {
+		return nil
+	}
+
+	if err := c.Bind().Body(&req); err != nil {
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid request body")
+	}
+	if !h.serverManaged(c, req.SwiftCode) {
+		return nil
+	}
+
+	return h.save(c, ctx, dryRun, req.bank(), service.WriteCreate)
+}
+
+// PutV2 handles v2 storing of a SWIFT code at its URL from a camelCase
+// body. It replaces a stored code unless the request is conditional.
+func (h *SwiftHandler) PutV2(c fiber.Ctx) error {
+	var req CreateRequestV2
+	ctx, dryRun, ok := writeContext(c)
+	if !ok {
+		return nil
+	}
+
+	if err := c.Bind().Body(&req); err != nil {
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid request body")
+	}
+	bank := req.bank()
+	if !pathCode(c, bank) || !h.serverManaged(c, bank.SwiftCode) {
+		return nil
+	}
+
+	return h.save(c, ctx, dryRun, bank, service.WriteUpsert)
+}
+
+func (req CreateRequestV2) bank() *models.SwiftBank {
+	return &models.SwiftBank{
+		SwiftCode:      req.SwiftCode,
+		CountryISOCode: req.CountryISO2,
+		BankName:       req.BankName,
+		IsHeadquarter:  req.IsHeadquarter,
+		Address:        req.Address,
+		CountryName:    req.CountryName,
+	}
+}
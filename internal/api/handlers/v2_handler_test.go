@@ -0,0 +1,106 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	handlers "github.com/zdziszkee/swift-codes/internal/api/handlers"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+var _ = Describe("Swift Handler v2", func() {
+	var (
+		app     *fiber.App
+		mockSvc *mocks.MockSwiftService
+	)
+
+	BeforeEach(func() {
+		mockSvc = &mocks.MockSwiftService{}
+		h := handlers.NewSwiftHandler(mockSvc)
+		app = fiber.New()
+		app.Get("/swift/:swiftCode", h.GetByCodeV2)
+		app.Get("/country/:countryISO2code", h.GetByCountryV2)
+		app.Post("/swift", h.CreateV2)
+	})
+
+	decode := func(resp *http.Response) map[string]any {
+		var body map[string]any
+		Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		return body
+	}
+
+	It("should return a headquarters with camelCase keys and its branches", func() {
+		mockSvc.GetSwiftCodeDetailsFunc = func(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+			return sampleDetail(1), nil
+		}
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/swift/BSZLPLP1XXX", nil), fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		body := decode(resp)
+		Expect(body).To(HaveKeyWithValue("swiftCode", "BSZLPLP1XXX"))
+		Expect(body).To(HaveKeyWithValue("bankName", "BANK SPOLDZIELCZY W ZLOCIENCU"))
+		Expect(body).To(HaveKeyWithValue("countryISO2", "PL"))
+		Expect(body).To(HaveKeyWithValue("countryName", "POLAND"))
+		Expect(body).To(HaveKeyWithValue("isHeadquarter", true))
+		Expect(body).To(HaveKey("address"))
+
+		branches := body["branches"].([]any)
+		Expect(branches).To(HaveLen(1))
+		Expect(branches[0]).To(HaveKeyWithValue("swiftCode", "BSZLPLP1000"))
+		Expect(branches[0]).NotTo(HaveKey("countryName"))
+	})
+
+	It("should leave out branches for a branch code", func() {
+		mockSvc.GetSwiftCodeDetailsFunc = func(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+			return &repository.SwiftBankDetail{Bank: sampleDetail(1).Branches[0]}, nil
+		}
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/swift/BSZLPLP1000", nil), fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decode(resp)).NotTo(HaveKey("branches"))
+	})
+
+	It("should return a country listing with camelCase keys", func() {
+		mockSvc.GetSwiftCodesByCountryFunc = func(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+			detail := sampleDetail(1)
+			return &repository.CountrySwiftCodes{
+				CountryISO2: "PL",
+				CountryName: "POLAND",
+				SwiftCodes:  append([]models.SwiftBank{detail.Bank}, detail.Branches...),
+				Total:       2,
+			}, nil
+		}
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/country/pl", nil), fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+
+		body := decode(resp)
+		Expect(body).To(HaveKeyWithValue("countryISO2", "PL"))
+		Expect(body).To(HaveKeyWithValue("countryName", "POLAND"))
+		Expect(body["swiftCodes"]).To(HaveLen(2))
+	})
+
+	It("should create a SWIFT code from a camelCase body", func() {
+		var created *models.SwiftBank
+		mockSvc.CreateSwiftCodeFunc = func(ctx context.Context, bank *models.SwiftBank) error {
+			created = bank
+			return nil
+		}
+		req := httptest.NewRequest(http.MethodPost, "/swift", strings.NewReader(
+			`{"address":"UL. KOSCIUSZKI 9","bankName":"BANK","countryISO2":"PL","countryName":"POLAND","isHeadquarter":true,"swiftCode":"BSZLPLP1XXX"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+		Expect(created.CountryISOCode).To(Equal("PL"))
+		Expect(created.SwiftCode).To(Equal("BSZLPLP1XXX"))
+	})
+})
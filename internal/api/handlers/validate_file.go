@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+// defaultMaxValidationRows applies when no MaxValidationRows is configured
+const defaultMaxValidationRows = 10000
+
+// Row statuses written by ValidateFile
+const (
+	ValidationValid         = "VALID"
+	ValidationInvalidFormat = "INVALID_FORMAT"
+	ValidationNotFound      = "NOT_FOUND"
+)
+
+// validationColumns are appended to every row of the annotated file
+var validationColumns = []string{"STATUS", "BANK_NAME", "REASON"}
+
+// bicColumnNames are header names recognized as the BIC column
+var bicColumnNames = map[string]bool{
+	"BIC":        true,
+	"SWIFT":      true,
+	"SWIFT CODE": true,
+	"SWIFT_CODE": true,
+	"SWIFTCODE":  true,
+}
+
+// ValidateFile screens a client CSV of BICs. Each row is checked for format
+// and looked up in the directory, and the file is returned with STATUS,
+// BANK_NAME and REASON columns appended. The BIC is taken from a column
+// named BIC or SWIFT CODE when the first row is such a header, otherwise
+// from the first column. The file is sent either as the request body or as
+// the "file" field of a multipart form.
+func (h *SwiftHandler) ValidateFile(c fiber.Ctx) error {
+	filename, body, err := uploadedFile(c)
+	if err != nil {
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid request body")
+	}
+
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil || len(rows) == 0 {
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid CSV file")
+	}
+
+	column, header := 0, false
+	for i, cell := range rows[0] {
+		if bicColumnNames[strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(cell, "\ufeff")))] {
+			column, header = i, true
+			break
+		}
+	}
+	data := rows
+	if header {
+		data = rows[1:]
+	}
+
+	maxRows := h.config.MaxValidationRows
+	if maxRows <= 0 {
+		maxRows = defaultMaxValidationRows
+	}
+	if len(data) > maxRows {
+		return apierror.Write(c, fiber.StatusRequestEntityTooLarge, apierror.CodePayloadTooLarge, "File exceeds "+strconv.Itoa(maxRows)+" rows")
+	}
+
+	var out bytes.Buffer
+	writer := csv.NewWriter(&out)
+	if header {
+		_ = writer.Write(append(rows[0], validationColumns...))
+	}
+
+	// Files often repeat a BIC; look each one up once
+	bankNames := map[string]string{}
+	counts := map[string]int{}
+	for _, row := range data {
+		var code string
+		if column < len(row) {
+			code = row[column]
+		}
+
+		status, bankName, reason := ValidationValid, "", ""
+		breakdown := service.ParseSwiftCode(code)
+		if !breakdown.FormatValid {
+			status, reason = ValidationInvalidFormat, breakdown.Reason
+		} else {
+			name, seen := bankNames[breakdown.SwiftCode]
+			if !seen {
+				detail, err := h.service.GetSwiftCodeDetails(c.Context(), breakdown.SwiftCode)
+				// Every distinct code counts against the scrape guard, as
+				// one file may hold thousands of lookups
+				middleware.RecordLookup(c.Context(), breakdown.SwiftCode, !errors.Is(err, service.ErrNotFound))
+				switch {
+				case err == nil:
+					name = detail.Bank.BankName
+				case !errors.Is(err, service.ErrNotFound):
+					return handleError(c, err)
+				}
+				bankNames[breakdown.SwiftCode] = name
+			}
+			if name == "" {
+				status, reason = ValidationNotFound, "not in the SWIFT directory"
+			}
+			bankName = name
+		}
+
+		counts[status]++
+		_ = writer.Write(append(row, status, bankName, reason))
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return handleError(c, err)
+	}
+
+	c.Set("X-Validation-Valid", strconv.Itoa(counts[ValidationValid]))
+	c.Set("X-Validation-Invalid-Format", strconv.Itoa(counts[ValidationInvalidFormat]))
+	c.Set("X-Validation-Not-Found", strconv.Itoa(counts[ValidationNotFound]))
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+strings.TrimSuffix(filename, ".csv")+`-validated.csv"`)
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	return c.Status(fiber.StatusOK).Send(out.Bytes())
+}
+
+// uploadedFile returns the name and content of the uploaded CSV, reading
+// the multipart "file" field when present and the raw body otherwise
+func uploadedFile(c fiber.Ctx) (string, []byte, error) {
+	if !strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		return "bics.csv", c.Body(), nil
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return "", nil, err
+	}
+	file, err := header.Open()
+	if err != nil {
+		return "", nil, err
+	}
+	defer file.Close()
+
+	body, err := io.ReadAll(file)
+	return safeFilename(header.Filename), body, err
+}
+
+// safeFilename strips the directory and any characters that would break the
+// Content-Disposition header from a client-supplied file name
+func safeFilename(name string) string {
+	name = filepath.Base(name)
+	name = strings.Map(func(r rune) rune {
+		if r == '"' || r == '\\' || r < ' ' {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" || name == "." {
+		return "bics.csv"
+	}
+	return name
+}
@@ -0,0 +1,99 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	handlers "github.com/zdziszkee/swift-codes/internal/api/handlers"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+var _ = Describe("ValidateFile", func() {
+	var (
+		app     *fiber.App
+		lookups []string
+	)
+
+	BeforeEach(func() {
+		lookups = nil
+		mockSvc := &mocks.MockSwiftService{
+			GetSwiftCodeDetailsFunc: func(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+				lookups = append(lookups, code)
+				if code == "BSZLPLP1XXX" {
+					return &repository.SwiftBankDetail{Bank: models.SwiftBank{BankName: "BANK SPOLDZIELCZY"}}, nil
+				}
+				return nil, service.ErrNotFound
+			},
+		}
+		app = fiber.New()
+		app.Post("/validate/file", handlers.NewSwiftHandler(mockSvc, handlers.Config{MaxValidationRows: 5}).ValidateFile)
+	})
+
+	post := func(req *http.Request) (*http.Response, [][]string) {
+		resp, err := app.Test(req, fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		if resp.StatusCode != http.StatusOK {
+			return resp, nil
+		}
+		rows, err := csv.NewReader(resp.Body).ReadAll()
+		Expect(err).NotTo(HaveOccurred())
+		return resp, rows
+	}
+
+	It("should annotate every row of a file with a header", func() {
+		body := "reference,BIC\n1,bszlplp1xxx\n2,BSZLPLP1XXX\n3,ABCDPLP1XXX\n4,BAD\n"
+		resp, rows := post(httptest.NewRequest(http.MethodPost, "/validate/file", strings.NewReader(body)))
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get("Content-Type")).To(HavePrefix("text/csv"))
+
+		Expect(rows[0]).To(Equal([]string{"reference", "BIC", "STATUS", "BANK_NAME", "REASON"}))
+		Expect(rows[1]).To(Equal([]string{"1", "bszlplp1xxx", handlers.ValidationValid, "BANK SPOLDZIELCZY", ""}))
+		Expect(rows[3][2]).To(Equal(handlers.ValidationNotFound))
+		Expect(rows[4][2]).To(Equal(handlers.ValidationInvalidFormat))
+		Expect(rows[4][4]).To(ContainSubstring("length"))
+
+		Expect(lookups).To(Equal([]string{"BSZLPLP1XXX", "ABCDPLP1XXX"}))
+		Expect(resp.Header.Get("X-Validation-Valid")).To(Equal("2"))
+		Expect(resp.Header.Get("X-Validation-Not-Found")).To(Equal("1"))
+		Expect(resp.Header.Get("X-Validation-Invalid-Format")).To(Equal("1"))
+	})
+
+	It("should read the first column of a multipart upload without a header", func() {
+		var form bytes.Buffer
+		writer := multipart.NewWriter(&form)
+		part, err := writer.CreateFormFile("file", "../payments.csv")
+		Expect(err).NotTo(HaveOccurred())
+		_, _ = part.Write([]byte("BSZLPLP1XXX,100.00\n"))
+		Expect(writer.Close()).To(Succeed())
+
+		req := httptest.NewRequest(http.MethodPost, "/validate/file", &form)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		resp, rows := post(req)
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get("Content-Disposition")).To(Equal(`attachment; filename="payments-validated.csv"`))
+		Expect(rows).To(Equal([][]string{{"BSZLPLP1XXX", "100.00", handlers.ValidationValid, "BANK SPOLDZIELCZY", ""}}))
+	})
+
+	It("should reject files over the row limit", func() {
+		body := strings.Repeat("BSZLPLP1XXX\n", 6)
+		resp, _ := post(httptest.NewRequest(http.MethodPost, "/validate/file", strings.NewReader(body)))
+		Expect(resp.StatusCode).To(Equal(http.StatusRequestEntityTooLarge))
+	})
+
+	It("should reject an empty file", func() {
+		resp, _ := post(httptest.NewRequest(http.MethodPost, "/validate/file", nil))
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+	})
+})
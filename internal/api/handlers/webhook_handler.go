@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	"github.com/zdziszkee/swift-codes/internal/webhooks"
+)
+
+// WebhookHandler manages webhook subscriptions
+type WebhookHandler struct {
+	registry *webhooks.Registry
+}
+
+// NewWebhookHandler creates a handler for the subscriptions in registry
+func NewWebhookHandler(registry *webhooks.Registry) *WebhookHandler {
+	return &WebhookHandler{registry: registry}
+}
+
+// webhookRequest is the body accepted by Create
+type webhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+}
+
+// Create registers a webhook. The response is the only one that includes
+// the signing secret.
+func (h *WebhookHandler) Create(c fiber.Ctx) error {
+	var req webhookRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid request body")
+	}
+
+	sub, err := h.registry.Add(webhooks.Subscription{URL: req.URL, Events: req.Events, Secret: req.Secret})
+	switch {
+	case errors.Is(err, webhooks.ErrInvalidURL):
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid webhook",
+			apierror.Field("url", "must be an absolute http or https URL"))
+	case errors.Is(err, webhooks.ErrInvalidEvent):
+		return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid webhook",
+			apierror.Field("events", "must be swift_code.created, swift_code.deleted or swift_code.bulk_loaded"))
+	case err != nil:
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(sub)
+}
+
+// List returns the registered webhooks without their secrets
+func (h *WebhookHandler) List(c fiber.Ctx) error {
+	return c.JSON(fiber.Map{"webhooks": h.registry.List()})
+}
+
+// Delete removes the webhook in the :id parameter
+func (h *WebhookHandler) Delete(c fiber.Ctx) error {
+	if err := h.registry.Remove(c.Params("id")); err != nil {
+		if errors.Is(err, webhooks.ErrNotFound) {
+			return apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "Webhook not found")
+		}
+		return err
+	}
+	return c.JSON(fiber.Map{"message": "Webhook deleted"})
+}
@@ -0,0 +1,78 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	handlers "github.com/zdziszkee/swift-codes/internal/api/handlers"
+	"github.com/zdziszkee/swift-codes/internal/webhooks"
+)
+
+var _ = Describe("WebhookHandler", func() {
+	var app *fiber.App
+
+	BeforeEach(func() {
+		h := handlers.NewWebhookHandler(webhooks.NewRegistry())
+		app = fiber.New()
+		app.Post("/webhooks", h.Create)
+		app.Get("/webhooks", h.List)
+		app.Delete("/webhooks/:id", h.Delete)
+	})
+
+	do := func(method, target, body string) *http.Response {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := app.Test(req, fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	It("should register a webhook and return its secret once", func() {
+		resp := do(http.MethodPost, "/webhooks", `{"url":"https://hooks.example.com/swift","events":["swift_code.created"]}`)
+		Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+		var created webhooks.Subscription
+		Expect(json.NewDecoder(resp.Body).Decode(&created)).To(Succeed())
+		Expect(created.ID).NotTo(BeEmpty())
+		Expect(created.Secret).NotTo(BeEmpty())
+		Expect(created.Events).To(Equal([]string{"swift_code.created"}))
+
+		resp = do(http.MethodGet, "/webhooks", "")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		var listed struct {
+			Webhooks []webhooks.Subscription `json:"webhooks"`
+		}
+		Expect(json.NewDecoder(resp.Body).Decode(&listed)).To(Succeed())
+		Expect(listed.Webhooks).To(HaveLen(1))
+		Expect(listed.Webhooks[0].ID).To(Equal(created.ID))
+		Expect(listed.Webhooks[0].Secret).To(BeEmpty())
+	})
+
+	It("should reject invalid URLs and events", func() {
+		resp := do(http.MethodPost, "/webhooks", `{"url":"ftp://hooks.example.com"}`)
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		var body apierror.Error
+		Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		Expect(body.Details).To(ConsistOf(apierror.Detail{Field: "url", Reason: "must be an absolute http or https URL"}))
+
+		resp = do(http.MethodPost, "/webhooks", `{"url":"https://hooks.example.com","events":["swift_code.renamed"]}`)
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should delete webhooks and report unknown IDs", func() {
+		resp := do(http.MethodPost, "/webhooks", `{"url":"https://hooks.example.com"}`)
+		var created webhooks.Subscription
+		Expect(json.NewDecoder(resp.Body).Decode(&created)).To(Succeed())
+
+		Expect(do(http.MethodDelete, "/webhooks/"+created.ID, "").StatusCode).To(Equal(http.StatusOK))
+		Expect(do(http.MethodDelete, "/webhooks/"+created.ID, "").StatusCode).To(Equal(http.StatusNotFound))
+	})
+})
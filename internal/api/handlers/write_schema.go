@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+)
+
+// serverManaged answers, with Config.StrictSchema, a write whose JSON body
+// sets fields the service derives itself: a swiftCodeBase or isHeadquarter
+// contradicting the SWIFT code, or a timestamp. Each offending field gets a
+// detail in one 400. Without strict mode such values are ignored, as the
+// service overwrites them. It returns false when the request was answered.
+func (h *SwiftHandler) serverManaged(c fiber.Ctx, code string) bool {
+	if !h.config.StrictSchema || !strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEApplicationJSON) {
+		return true
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(c.Body(), &fields) != nil {
+		return true
+	}
+
+	code = strings.ToUpper(code)
+	var details []apierror.Detail
+	for name, raw := range fields {
+		// Bodies are bound case-insensitively, so the names are matched
+		// the same way
+		switch strings.ToLower(name) {
+		case "swiftcodebase":
+			var base string
+			if len(code) >= 8 && (json.Unmarshal(raw, &base) != nil || !strings.EqualFold(base, code[:8])) {
+				details = append(details, apierror.Field(name, "is derived from swiftCode; omit it or send "+code[:8]))
+			}
+		case "isheadquarter":
+			var headquarter bool
+			if json.Unmarshal(raw, &headquarter) != nil || headquarter != strings.HasSuffix(code, "XXX") {
+				details = append(details, apierror.Field(name, "is derived from swiftCode, true only for codes ending in XXX"))
+			}
+		case "createdat", "updatedat":
+			details = append(details, apierror.Field(name, "is set by the server"))
+		}
+	}
+	if len(details) == 0 {
+		return true
+	}
+	slices.SortFunc(details, func(a, b apierror.Detail) int { return strings.Compare(a.Field, b.Field) })
+	_ = apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Request body sets server-managed fields", details...)
+	return false
+}
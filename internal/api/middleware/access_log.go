@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/logger"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+)
+
+// AccessRecord is one line of the JSON access log
+type AccessRecord struct {
+	// Time is when the request arrived
+	Time   time.Time `json:"time"`
+	IP     string    `json:"ip"`
+	Method string    `json:"method"`
+	Path   string    `json:"path"`
+	// Query is the raw query string, without the leading "?"
+	Query     string  `json:"query,omitempty"`
+	Status    int     `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	RequestID string  `json:"request_id,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// JSONAccessLog returns middleware writing an AccessRecord line to w for
+// every request, for log shippers and the replay command
+func JSONAccessLog(w io.Writer) fiber.Handler {
+	var mu sync.Mutex
+	return logger.New(logger.Config{
+		Output: w,
+		LoggerFunc: func(c fiber.Ctx, data *logger.Data, _ logger.Config) error {
+			record := AccessRecord{
+				Time:      data.Start.UTC(),
+				IP:        c.IP(),
+				Method:    c.Method(),
+				Path:      c.Path(),
+				Query:     string(c.Request().URI().QueryString()),
+				Status:    c.Response().StatusCode(),
+				LatencyMs: float64(data.Stop.Sub(data.Start)) / float64(time.Millisecond),
+				RequestID: c.GetRespHeader(requestid.Header),
+			}
+			if data.ChainErr != nil {
+				record.Error = data.ChainErr.Error()
+			}
+			line, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			_, err = w.Write(append(line, '\n'))
+			return err
+		},
+	})
+}
@@ -0,0 +1,41 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+)
+
+var _ = Describe("JSONAccessLog", func() {
+	It("should write one record per request with its query string", func() {
+		var out bytes.Buffer
+		app := fiber.New()
+		app.Use(middleware.RequestID())
+		app.Use(middleware.JSONAccessLog(&out))
+		app.Get("/v1/swiftCodes/:swiftCode", func(c fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusNotFound)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/swiftCodes/PKOPPLPWXXX?fields=bankName", nil)
+		req.Header.Set("X-Request-ID", "req-1")
+		_, err := app.Test(req, fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+
+		var record middleware.AccessRecord
+		Expect(json.Unmarshal(out.Bytes(), &record)).To(Succeed())
+		Expect(out.String()).To(HaveSuffix("}\n"))
+		Expect(record.Method).To(Equal(http.MethodGet))
+		Expect(record.Path).To(Equal("/v1/swiftCodes/PKOPPLPWXXX"))
+		Expect(record.Query).To(Equal("fields=bankName"))
+		Expect(record.Status).To(Equal(http.StatusNotFound))
+		Expect(record.RequestID).To(Equal("req-1"))
+		Expect(record.Time.IsZero()).To(BeFalse())
+	})
+})
@@ -0,0 +1,188 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	"github.com/zdziszkee/swift-codes/internal/correlation"
+)
+
+// Roles recognised in the "roles" (or "role") claim of a bearer token
+const (
+	RoleWriter  = "writer"
+	RoleAdmin   = "admin"
+	RoleAnalyst = "analyst"
+)
+
+const claimsLocalsKey = "auth.claims"
+
+var (
+	ErrMissingToken   = errors.New("missing bearer token")
+	ErrMalformedToken = errors.New("malformed bearer token")
+	ErrInvalidToken   = errors.New("invalid bearer token")
+	ErrExpiredToken   = errors.New("bearer token expired")
+	ErrForbidden      = errors.New("insufficient role")
+)
+
+// AuthConfig holds configuration for bearer-token authentication
+type AuthConfig struct {
+	Enabled    bool   `koanf:"enabled"`
+	SigningKey string `koanf:"signing_key"`
+	Issuer     string `koanf:"issuer"`
+}
+
+// Claims is the subset of JWT claims the API cares about
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+	Roles     []string `json:"roles"`
+	Role      string   `json:"role"`
+	// Envelope is the key's response envelope preference; it overrides
+	// api.envelope when set
+	Envelope *bool `json:"envelope,omitempty"`
+}
+
+// HasAnyRole reports whether the claims grant at least one of the given roles
+func (c *Claims) HasAnyRole(roles ...string) bool {
+	granted := c.Roles
+	if c.Role != "" {
+		granted = append(granted, c.Role)
+	}
+	for _, g := range granted {
+		for _, r := range roles {
+			if strings.EqualFold(g, r) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RequireRole returns middleware that rejects requests whose bearer token
+// does not carry one of the given roles. When auth is disabled it is a no-op.
+func RequireRole(cfg AuthConfig, roles ...string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if err := Authorize(cfg, c, roles...); err != nil {
+			return RespondAuthError(c, err)
+		}
+		return c.Next()
+	}
+}
+
+// Authorize checks the request's bearer token for one of the given roles and
+// stores its claims on the context. It returns ErrForbidden when the token is
+// valid but lacks the role, and a token error when it is missing or invalid.
+func Authorize(cfg AuthConfig, c fiber.Ctx, roles ...string) error {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	claims, err := ParseToken(cfg, bearerToken(c.Get(fiber.HeaderAuthorization)), time.Now())
+	if err != nil {
+		return err
+	}
+	if !claims.HasAnyRole(roles...) {
+		return ErrForbidden
+	}
+
+	c.Locals(claimsLocalsKey, claims)
+	c.SetContext(correlation.WithActor(c.Context(), claims.Subject))
+	return nil
+}
+
+// RequestClaims validates the bearer token of c without requiring a role.
+// It returns nil claims and no error when the request carries no token.
+func RequestClaims(cfg AuthConfig, c fiber.Ctx) (*Claims, error) {
+	token := bearerToken(c.Get(fiber.HeaderAuthorization))
+	if token == "" {
+		return nil, nil
+	}
+	return ParseToken(cfg, token, time.Now())
+}
+
+// RespondAuthError writes the 401/403 response matching an Authorize error
+func RespondAuthError(c fiber.Ctx, err error) error {
+	if errors.Is(err, ErrForbidden) {
+		return apierror.Write(c, fiber.StatusForbidden, apierror.CodeForbidden, "Forbidden")
+	}
+	return apierror.Write(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+}
+
+// ClaimsFromContext returns the claims stored by RequireRole, or nil
+func ClaimsFromContext(c fiber.Ctx) *Claims {
+	claims, _ := c.Locals(claimsLocalsKey).(*Claims)
+	return claims
+}
+
+// ParseToken validates an HS256-signed JWT and returns its claims
+func ParseToken(cfg AuthConfig, token string, now time.Time) (*Claims, error) {
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrMalformedToken
+	}
+	if header.Alg != "HS256" {
+		return nil, ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	mac := hmac.New(sha256.New, []byte(cfg.SigningKey))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrMalformedToken
+	}
+	if cfg.Issuer != "" && claims.Issuer != cfg.Issuer {
+		return nil, ErrInvalidToken
+	}
+	if claims.ExpiresAt != 0 && now.Unix() >= claims.ExpiresAt {
+		return nil, ErrExpiredToken
+	}
+	if claims.NotBefore != 0 && now.Unix() < claims.NotBefore {
+		return nil, ErrInvalidToken
+	}
+
+	return &claims, nil
+}
+
+func bearerToken(header string) string {
+	const prefix = "Bearer "
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return ""
+	}
+	return strings.TrimSpace(header[len(prefix):])
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
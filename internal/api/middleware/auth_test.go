@@ -0,0 +1,117 @@
+package middleware_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+)
+
+func TestMiddleware(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Middleware Suite")
+}
+
+// signToken builds an HS256 JWT for the given claims.
+func signToken(key string, claims map[string]any) string {
+	header, _ := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+var _ = Describe("Auth Middleware", func() {
+	var (
+		app *fiber.App
+		cfg middleware.AuthConfig
+	)
+
+	BeforeEach(func() {
+		cfg = middleware.AuthConfig{
+			Enabled:    true,
+			SigningKey: "secret",
+			Issuer:     "swift-codes",
+		}
+	})
+
+	JustBeforeEach(func() {
+		app = fiber.New()
+		app.Post("/write", func(c fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusCreated)
+		}, middleware.RequireRole(cfg, middleware.RoleWriter, middleware.RoleAdmin))
+	})
+
+	doRequest := func(token string) int {
+		req := httptest.NewRequest(http.MethodPost, "/write", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := app.Test(req)
+		Expect(err).NotTo(HaveOccurred())
+		return resp.StatusCode
+	}
+
+	It("should allow a token with the writer role", func() {
+		token := signToken("secret", map[string]any{
+			"iss":   "swift-codes",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"roles": []string{"writer"},
+		})
+		Expect(doRequest(token)).To(Equal(http.StatusCreated))
+	})
+
+	It("should accept a single role claim", func() {
+		token := signToken("secret", map[string]any{"iss": "swift-codes", "role": "admin"})
+		Expect(doRequest(token)).To(Equal(http.StatusCreated))
+	})
+
+	It("should reject requests without a token", func() {
+		Expect(doRequest("")).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should reject tokens signed with another key", func() {
+		token := signToken("other", map[string]any{"iss": "swift-codes", "roles": []string{"writer"}})
+		Expect(doRequest(token)).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should reject tokens from an unexpected issuer", func() {
+		token := signToken("secret", map[string]any{"iss": "someone-else", "roles": []string{"writer"}})
+		Expect(doRequest(token)).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should reject expired tokens", func() {
+		token := signToken("secret", map[string]any{
+			"iss":   "swift-codes",
+			"exp":   time.Now().Add(-time.Minute).Unix(),
+			"roles": []string{"writer"},
+		})
+		Expect(doRequest(token)).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should forbid tokens without a write role", func() {
+		token := signToken("secret", map[string]any{"iss": "swift-codes", "roles": []string{"reader"}})
+		Expect(doRequest(token)).To(Equal(http.StatusForbidden))
+	})
+
+	Context("when auth is disabled", func() {
+		BeforeEach(func() {
+			cfg.Enabled = false
+		})
+
+		It("should let every request through", func() {
+			Expect(doRequest("")).To(Equal(http.StatusCreated))
+		})
+	})
+})
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+)
+
+// BodyLimit rejects requests whose body is larger than maxBytes with 413
+// before any handler decodes it. A maxBytes of 0 or less disables the check.
+func BodyLimit(maxBytes int) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if maxBytes <= 0 {
+			return c.Next()
+		}
+		// The declared length catches oversized uploads up front; the body
+		// length covers chunked requests that declare none
+		if c.Request().Header.ContentLength() > maxBytes || len(c.Body()) > maxBytes {
+			return apierror.Write(c, fiber.StatusRequestEntityTooLarge, apierror.CodePayloadTooLarge, "Request body too large")
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,38 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+)
+
+var _ = Describe("BodyLimit", func() {
+	post := func(limit int, body string) int {
+		app := fiber.New()
+		app.Post("/write", func(c fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusCreated)
+		}, middleware.BodyLimit(limit))
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/write", strings.NewReader(body)), fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		return resp.StatusCode
+	}
+
+	It("should pass bodies up to the limit", func() {
+		Expect(post(8, "12345678")).To(Equal(http.StatusCreated))
+	})
+
+	It("should reject larger bodies with 413", func() {
+		Expect(post(8, "123456789")).To(Equal(http.StatusRequestEntityTooLarge))
+	})
+
+	It("should not limit bodies when disabled", func() {
+		Expect(post(0, strings.Repeat("x", 1<<16))).To(Equal(http.StatusCreated))
+	})
+})
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// CachePolicy sets the Cache-Control lifetimes of one route group. A zero
+// policy sends no Cache-Control header.
+type CachePolicy struct {
+	// MaxAge is how long browsers and other private caches may reuse a
+	// response
+	MaxAge time.Duration `koanf:"max_age"`
+	// SMaxAge overrides MaxAge for shared caches such as CDNs and proxies
+	SMaxAge time.Duration `koanf:"s_maxage"`
+}
+
+// Header returns the Cache-Control value of the policy, or "" when it is
+// zero
+func (p CachePolicy) Header() string {
+	if p.MaxAge <= 0 && p.SMaxAge <= 0 {
+		return ""
+	}
+	directives := []string{"public", fmt.Sprintf("max-age=%d", int64(p.MaxAge.Seconds()))}
+	if p.SMaxAge > 0 {
+		directives = append(directives, fmt.Sprintf("s-maxage=%d", int64(p.SMaxAge.Seconds())))
+	}
+	return strings.Join(directives, ", ")
+}
+
+// CacheControlConfig holds the cache policies of the read route groups
+type CacheControlConfig struct {
+	// Codes covers single-code, branch and batch lookups
+	Codes CachePolicy `koanf:"codes"`
+	// Countries covers country listings and country metadata
+	Countries CachePolicy `koanf:"countries"`
+}
+
+// Validate rejects negative lifetimes
+func (c CacheControlConfig) Validate() error {
+	groups := []struct {
+		name   string
+		policy CachePolicy
+	}{{"codes", c.Codes}, {"countries", c.Countries}}
+	for _, group := range groups {
+		if group.policy.MaxAge < 0 || group.policy.SMaxAge < 0 {
+			return fmt.Errorf("cache_control %s max_age and s_maxage cannot be negative", group.name)
+		}
+	}
+	return nil
+}
+
+// CacheControl adds the Cache-Control header of policy to successful GET
+// and HEAD responses. Responses also vary on the request headers in vary,
+// so shared caches keep the negotiated representations apart. A handler
+// that sets its own Cache-Control keeps it.
+func CacheControl(policy CachePolicy, vary ...string) fiber.Handler {
+	value := policy.Header()
+	return func(c fiber.Ctx) error {
+		if value == "" || (c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead) {
+			return c.Next()
+		}
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+		switch c.Response().StatusCode() {
+		case fiber.StatusOK, fiber.StatusNotModified:
+			if len(c.Response().Header.Peek(fiber.HeaderCacheControl)) == 0 {
+				c.Set(fiber.HeaderCacheControl, value)
+			}
+			c.Vary(vary...)
+		}
+		return nil
+	}
+}
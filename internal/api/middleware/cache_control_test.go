@@ -0,0 +1,61 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+)
+
+var _ = Describe("CacheControl", func() {
+	var app *fiber.App
+
+	BeforeEach(func() {
+		app = fiber.New()
+		cache := middleware.CacheControl(middleware.CachePolicy{MaxAge: time.Minute, SMaxAge: time.Hour}, fiber.HeaderAccept)
+		app.Get("/codes", func(c fiber.Ctx) error {
+			return c.SendString("data")
+		}, cache)
+		app.Get("/missing", func(c fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusNotFound)
+		}, cache)
+		app.Get("/private", func(c fiber.Ctx) error {
+			c.Set(fiber.HeaderCacheControl, "no-store")
+			return c.SendString("data")
+		}, cache)
+		app.Get("/uncached", func(c fiber.Ctx) error {
+			return c.SendString("data")
+		}, middleware.CacheControl(middleware.CachePolicy{}))
+	})
+
+	get := func(path string) *http.Response {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil), fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	It("should set the policy on successful reads", func() {
+		resp := get("/codes")
+		Expect(resp.Header.Get(fiber.HeaderCacheControl)).To(Equal("public, max-age=60, s-maxage=3600"))
+		Expect(resp.Header.Get(fiber.HeaderVary)).To(Equal(fiber.HeaderAccept))
+	})
+
+	It("should leave errors and handler-set headers alone", func() {
+		Expect(get("/missing").Header.Get(fiber.HeaderCacheControl)).To(BeEmpty())
+		Expect(get("/private").Header.Get(fiber.HeaderCacheControl)).To(Equal("no-store"))
+	})
+
+	It("should send nothing for a zero policy", func() {
+		Expect(get("/uncached").Header.Get(fiber.HeaderCacheControl)).To(BeEmpty())
+	})
+
+	It("should reject negative lifetimes", func() {
+		config := middleware.CacheControlConfig{Countries: middleware.CachePolicy{SMaxAge: -time.Second}}
+		Expect(config.Validate()).To(MatchError(ContainSubstring("countries")))
+	})
+})
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// CanonicalPath rewrites the request path to the casing of the registered
+// routes and drops a trailing slash, so /V1/SwiftCodes/abc/ is handled,
+// logged and linked as /v1/swiftCodes/abc. The path is matched against the
+// shape of every route, ignoring case, and only the static segments of the
+// most specific match are rewritten; parameters keep the client's casing
+// even when they spell a route word. The routes are collected from app on
+// the first request, once every route is registered.
+func CanonicalPath(app *fiber.App) fiber.Handler {
+	var (
+		once   sync.Once
+		shapes []routeShape
+	)
+
+	return func(c fiber.Ctx) error {
+		once.Do(func() { shapes = routeShapes(app.GetRoutes(true)) })
+
+		path := c.Path()
+		parts := strings.Split(strings.TrimSuffix(path, "/"), "/")
+		if shape := bestShape(shapes, parts); shape != nil {
+			for i, segment := range shape {
+				if segment.wildcard {
+					break
+				}
+				if !segment.param {
+					parts[i] = segment.text
+				}
+			}
+		}
+		if canonical := strings.Join(parts, "/"); canonical != path && canonical != "" {
+			c.Path(canonical)
+		}
+		return c.Next()
+	}
+}
+
+// routeSegment is one segment of a registered route path
+type routeSegment struct {
+	text     string
+	param    bool
+	optional bool
+	// wildcard matches this and every following segment
+	wildcard bool
+}
+
+// routeShape is a registered route path split into segments
+type routeShape []routeSegment
+
+// routeShapes splits the path of every route into its segments
+func routeShapes(routes []fiber.Route) []routeShape {
+	seen := make(map[string]bool)
+	var shapes []routeShape
+	for _, route := range routes {
+		if seen[route.Path] {
+			continue
+		}
+		seen[route.Path] = true
+
+		var shape routeShape
+		for _, part := range strings.Split(strings.TrimSuffix(route.Path, "/"), "/") {
+			switch {
+			case strings.ContainsAny(part, "*+"):
+				shape = append(shape, routeSegment{wildcard: true})
+			case strings.HasPrefix(part, ":"):
+				shape = append(shape, routeSegment{param: true, optional: strings.HasSuffix(part, "?")})
+			default:
+				shape = append(shape, routeSegment{text: part})
+			}
+		}
+		shapes = append(shapes, shape)
+	}
+	return shapes
+}
+
+// bestShape returns the shape matching parts with the most static
+// segments, the first registered one on a tie, or nil when none matches
+func bestShape(shapes []routeShape, parts []string) routeShape {
+	var (
+		best        routeShape
+		bestStatics = -1
+	)
+	for _, shape := range shapes {
+		if statics, ok := shape.match(parts); ok && statics > bestStatics {
+			best, bestStatics = shape, statics
+		}
+	}
+	return best
+}
+
+// match reports whether parts fit the shape, ignoring the case of static
+// segments, and how many static segments they matched
+func (s routeShape) match(parts []string) (int, bool) {
+	statics := 0
+	for i, segment := range s {
+		if segment.wildcard {
+			return statics, true
+		}
+		if i >= len(parts) {
+			return statics, segment.optional && i == len(s)-1
+		}
+		switch {
+		case segment.param:
+			if parts[i] == "" {
+				return 0, false
+			}
+		case strings.EqualFold(parts[i], segment.text):
+			statics++
+		default:
+			return 0, false
+		}
+	}
+	return statics, len(parts) == len(s)
+}
@@ -0,0 +1,65 @@
+package middleware_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+)
+
+var _ = Describe("CanonicalPath", func() {
+	var app *fiber.App
+
+	BeforeEach(func() {
+		app = fiber.New()
+		app.Use(middleware.CanonicalPath(app))
+		v1 := app.Group("/v1")
+		v1.Get("/swiftCodes/:swiftCode", func(c fiber.Ctx) error {
+			return c.SendString(c.Path() + " " + c.Params("swiftCode"))
+		})
+		v1.Get("/swiftCodes/country/:countryISO2code", func(c fiber.Ctx) error {
+			return c.SendString(c.Path())
+		})
+	})
+
+	get := func(path string) (int, string) {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil), fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	It("should serve differently cased paths with a trailing slash as the registered route", func() {
+		status, body := get("/V1/SwiftCodes/abc/")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(Equal("/v1/swiftCodes/abc abc"))
+
+		status, body = get("/v1/SWIFTCODES/COUNTRY/pl")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(Equal("/v1/swiftCodes/country/pl"))
+	})
+
+	It("should keep the casing of parameters that spell a static segment", func() {
+		status, body := get("/V1/SwiftCodes/COUNTRY")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(Equal("/v1/swiftCodes/COUNTRY COUNTRY"))
+
+		status, body = get("/v1/swiftcodes/Country/SwiftCodes")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(Equal("/v1/swiftCodes/country/SwiftCodes"))
+	})
+
+	It("should leave canonical and unknown paths alone", func() {
+		status, body := get("/v1/swiftCodes/abc")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(Equal("/v1/swiftCodes/abc abc"))
+
+		status, _ = get("/v1/Unknown")
+		Expect(status).To(Equal(http.StatusNotFound))
+	})
+})
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// countryVersionKey holds the version a country listing was answered at
+const countryVersionKey = "countryETag.version"
+
+// CountryETag answers conditional GETs of the country listing routes from
+// the version of the country named by param: an If-None-Match holding the
+// current ETag gets 304 without running the handler, and successful
+// responses carry the ETag. The version is read before the handler runs,
+// so a write racing the request can only make the tag older than the data.
+func CountryETag(param string, version func(country string) uint64) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+			return c.Next()
+		}
+
+		current := version(c.Params(param))
+		etag := `W/"` + strconv.FormatUint(current, 10) + `"`
+		if etagMatches(c.Get(fiber.HeaderIfNoneMatch), etag) {
+			c.Set(fiber.HeaderETag, etag)
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+
+		c.Locals(countryVersionKey, current)
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if c.Response().StatusCode() == fiber.StatusOK {
+			c.Set(fiber.HeaderETag, etag)
+		}
+		return nil
+	}
+}
+
+// CountryVersion returns the version a country listing is answered at, as
+// recorded by CountryETag
+func CountryVersion(c fiber.Ctx) (uint64, bool) {
+	version, ok := c.Locals(countryVersionKey).(uint64)
+	return version, ok
+}
+
+// etagMatches reports whether an If-None-Match header names etag, compared
+// weakly as RFC 9110 asks for GETs
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
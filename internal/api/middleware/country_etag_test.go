@@ -0,0 +1,62 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+)
+
+var _ = Describe("CountryETag", func() {
+	var (
+		app      *fiber.App
+		versions map[string]uint64
+		calls    int
+	)
+
+	BeforeEach(func() {
+		versions = map[string]uint64{"PL": 7, "DE": 3}
+		calls = 0
+		app = fiber.New()
+		app.Get("/country/:iso2", func(c fiber.Ctx) error {
+			calls++
+			version, _ := middleware.CountryVersion(c)
+			return c.SendString(strconv.FormatUint(version, 10))
+		}, middleware.CountryETag("iso2", func(country string) uint64 { return versions[country] }))
+	})
+
+	get := func(path, ifNoneMatch string) *http.Response {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set(fiber.HeaderIfNoneMatch, ifNoneMatch)
+		}
+		resp, err := app.Test(req, fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	It("should tag listings with their country's version", func() {
+		resp := get("/country/PL", "")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get(fiber.HeaderETag)).To(Equal(`W/"7"`))
+		Expect(get("/country/DE", "").Header.Get(fiber.HeaderETag)).To(Equal(`W/"3"`))
+		Expect(calls).To(Equal(2))
+	})
+
+	It("should answer a current tag with 304 until the country changes", func() {
+		resp := get("/country/PL", `"1", W/"7"`)
+		Expect(resp.StatusCode).To(Equal(http.StatusNotModified))
+		Expect(resp.Header.Get(fiber.HeaderETag)).To(Equal(`W/"7"`))
+		Expect(calls).To(BeZero())
+
+		versions["PL"] = 8
+		resp = get("/country/PL", `W/"7"`)
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get(fiber.HeaderETag)).To(Equal(`W/"8"`))
+	})
+})
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+// DatasetHeader selects a dataset on requests and names the dataset that
+// served the response
+const DatasetHeader = "X-Dataset"
+
+// SelectDataset picks the dataset of the request from ?dataset= or the
+// X-Dataset header and echoes the one that is served. Unknown names are
+// rejected rather than silently served from the default.
+func SelectDataset(router *service.DatasetRouter) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		name := c.Query("dataset")
+		if name == "" {
+			name = c.Get(DatasetHeader)
+		}
+		if name != "" {
+			if !router.Has(name) {
+				return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Unknown dataset",
+					apierror.Field("dataset", "must be one of the configured datasets"))
+			}
+			c.SetContext(service.WithDataset(c.Context(), name))
+		}
+		c.Set(DatasetHeader, router.Resolve(c.Context()))
+		return c.Next()
+	}
+}
@@ -0,0 +1,64 @@
+package middleware_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+var _ = Describe("SelectDataset", func() {
+	var app *fiber.App
+
+	BeforeEach(func() {
+		router, err := service.NewDatasetRouter(map[string]service.SwiftService{
+			"2024Q4": &mocks.MockSwiftService{},
+			"2025Q1": &mocks.MockSwiftService{},
+		}, "2025Q1")
+		Expect(err).NotTo(HaveOccurred())
+
+		app = fiber.New()
+		app.Use(middleware.SelectDataset(router))
+		app.Get("/codes", func(c fiber.Ctx) error {
+			return c.SendString(service.DatasetFromContext(c.Context()))
+		})
+	})
+
+	get := func(target, header string) (*http.Response, string) {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if header != "" {
+			req.Header.Set(middleware.DatasetHeader, header)
+		}
+		resp, err := app.Test(req, fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	It("should select the dataset from the query or the header", func() {
+		resp, selected := get("/codes?dataset=2024Q4", "")
+		Expect(selected).To(Equal("2024Q4"))
+		Expect(resp.Header.Get(middleware.DatasetHeader)).To(Equal("2024Q4"))
+
+		_, selected = get("/codes", "2024Q4")
+		Expect(selected).To(Equal("2024Q4"))
+	})
+
+	It("should report the default when none is selected", func() {
+		resp, selected := get("/codes", "")
+		Expect(selected).To(BeEmpty())
+		Expect(resp.Header.Get(middleware.DatasetHeader)).To(Equal("2025Q1"))
+	})
+
+	It("should reject unknown datasets", func() {
+		resp, _ := get("/codes?dataset=2023Q1", "")
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+	})
+})
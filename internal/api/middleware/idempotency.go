@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+)
+
+// HeaderIdempotencyKey is the request header naming a retry-safe request
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// HeaderIdempotentReplayed marks a response served from the idempotency store
+const HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyConfig holds configuration for Idempotency-Key handling
+type IdempotencyConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// TTL is how long an outcome is replayed for its key
+	TTL time.Duration `koanf:"ttl"`
+}
+
+// StoredResponse is the outcome recorded for an idempotency key
+type StoredResponse struct {
+	// Fingerprint identifies the request the outcome belongs to, so a key
+	// reused for a different request is detected
+	Fingerprint [sha256.Size]byte
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// IdempotencyStore keeps request outcomes by idempotency key. Implementations
+// must be safe for concurrent use.
+type IdempotencyStore interface {
+	// Load returns the outcome stored for key, if it has not expired
+	Load(key string) (StoredResponse, bool)
+	// Store records the outcome for key for ttl
+	Store(key string, resp StoredResponse, ttl time.Duration)
+}
+
+type memoryEntry struct {
+	resp    StoredResponse
+	expires time.Time
+}
+
+// MemoryIdempotencyStore is an in-process IdempotencyStore
+type MemoryIdempotencyStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+}
+
+// NewMemoryIdempotencyStore creates an empty in-process store
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{entries: map[string]memoryEntry{}}
+}
+
+// Load returns the unexpired outcome stored for key
+func (s *MemoryIdempotencyStore) Load(key string) (StoredResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || !time.Now().Before(entry.expires) {
+		return StoredResponse{}, false
+	}
+	return entry.resp, true
+}
+
+// Store records resp for key and drops expired entries at most once per ttl
+func (s *MemoryIdempotencyStore) Store(key string, resp StoredResponse, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) >= ttl {
+		for k, entry := range s.entries {
+			if !now.Before(entry.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = now
+	}
+	s.entries[key] = memoryEntry{resp: resp, expires: now.Add(ttl)}
+}
+
+// Idempotency replays the recorded outcome of a request that repeats an
+// Idempotency-Key, so a retried create neither inserts twice nor fails with
+// a conflict. Keys are scoped to the caller, the method and the URL: the
+// subject of the bearer token checked by RequireRole, so callers cannot
+// replay each other's outcomes by guessing keys. A key reused with a
+// different body is rejected with 422, and a retry that arrives while the
+// first request is still running gets 409. Server errors are not recorded,
+// so they can be retried. Requests without the header pass through.
+func Idempotency(cfg IdempotencyConfig, store IdempotencyStore) fiber.Handler {
+	var inflight sync.Map
+
+	return func(c fiber.Ctx) error {
+		key := c.Get(HeaderIdempotencyKey)
+		if !cfg.Enabled || key == "" {
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid Idempotency-Key header",
+				apierror.Field(HeaderIdempotencyKey, "must be at most 255 characters"))
+		}
+
+		// The path is canonical, so differently cased retries share a key.
+		// Without auth every caller shares one scope.
+		var subject string
+		if claims := ClaimsFromContext(c); claims != nil {
+			subject = claims.Subject
+		}
+		key = strconv.Quote(subject) + " " + c.Method() + " " + c.Path() + "?" + string(c.Request().URI().QueryString()) + " " + key
+		fingerprint := sha256.Sum256(c.Body())
+
+		if stored, ok := store.Load(key); ok {
+			return replay(c, stored, fingerprint)
+		}
+
+		if _, busy := inflight.LoadOrStore(key, struct{}{}); busy {
+			return apierror.Write(c, fiber.StatusConflict, apierror.CodeConflict, "A request with this Idempotency-Key is in progress")
+		}
+		defer inflight.Delete(key)
+
+		// The first request may have finished between Load and LoadOrStore
+		if stored, ok := store.Load(key); ok {
+			return replay(c, stored, fingerprint)
+		}
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		resp := c.Response()
+		if resp.StatusCode() < fiber.StatusInternalServerError {
+			store.Store(key, StoredResponse{
+				Fingerprint: fingerprint,
+				Status:      resp.StatusCode(),
+				ContentType: string(resp.Header.ContentType()),
+				Body:        append([]byte(nil), resp.Body()...),
+			}, cfg.TTL)
+		}
+		return nil
+	}
+}
+
+// replay answers with a stored outcome if it belongs to the same request
+func replay(c fiber.Ctx, stored StoredResponse, fingerprint [sha256.Size]byte) error {
+	if stored.Fingerprint != fingerprint {
+		return apierror.Write(c, fiber.StatusUnprocessableEntity, apierror.CodeIdempotencyKeyReused, "Idempotency-Key was already used for a different request")
+	}
+	c.Set(HeaderIdempotentReplayed, "true")
+	c.Set(fiber.HeaderContentType, stored.ContentType)
+	return c.Status(stored.Status).Send(stored.Body)
+}
@@ -0,0 +1,140 @@
+package middleware_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+)
+
+var _ = Describe("Idempotency", func() {
+	var (
+		app     *fiber.App
+		cfg     middleware.IdempotencyConfig
+		inserts int
+		status  int
+	)
+
+	BeforeEach(func() {
+		cfg = middleware.IdempotencyConfig{Enabled: true, TTL: time.Hour}
+		inserts = 0
+		status = fiber.StatusCreated
+	})
+
+	JustBeforeEach(func() {
+		app = fiber.New()
+		app.Post("/swiftCodes", func(c fiber.Ctx) error {
+			inserts++
+			if inserts > 1 && status == fiber.StatusCreated {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{"message": "SWIFT code already exists"})
+			}
+			return c.Status(status).JSON(fiber.Map{"message": "SWIFT code created successfully"})
+		}, middleware.Idempotency(cfg, middleware.NewMemoryIdempotencyStore()))
+	})
+
+	post := func(key, body string) *http.Response {
+		req := httptest.NewRequest(http.MethodPost, "/swiftCodes", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(middleware.HeaderIdempotencyKey, key)
+		}
+		resp, err := app.Test(req, fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	It("should replay the first outcome for a retried key", func() {
+		first := post("key-1", `{"swiftCode":"ABCDUS33XXX"}`)
+		Expect(first.StatusCode).To(Equal(http.StatusCreated))
+
+		retry := post("key-1", `{"swiftCode":"ABCDUS33XXX"}`)
+		Expect(retry.StatusCode).To(Equal(http.StatusCreated))
+		Expect(retry.Header.Get(middleware.HeaderIdempotentReplayed)).To(Equal("true"))
+		Expect(retry.Header.Get("Content-Type")).To(HavePrefix("application/json"))
+		body, _ := io.ReadAll(retry.Body)
+		Expect(string(body)).To(ContainSubstring("created successfully"))
+		Expect(inserts).To(Equal(1))
+	})
+
+	It("should reject a key reused for a different body", func() {
+		post("key-1", `{"swiftCode":"ABCDUS33XXX"}`)
+		Expect(post("key-1", `{"swiftCode":"ABCDUS33123"}`).StatusCode).To(Equal(http.StatusUnprocessableEntity))
+		Expect(inserts).To(Equal(1))
+	})
+
+	It("should pass requests without a key through", func() {
+		post("", `{}`)
+		Expect(post("", `{}`).StatusCode).To(Equal(http.StatusConflict))
+		Expect(inserts).To(Equal(2))
+	})
+
+	It("should keep the keys of different callers apart", func() {
+		auth := middleware.AuthConfig{Enabled: true, SigningKey: "secret", Issuer: "swift-codes"}
+		app = fiber.New()
+		app.Post("/swiftCodes", func(c fiber.Ctx) error {
+			inserts++
+			return c.Status(fiber.StatusCreated).SendString(middleware.ClaimsFromContext(c).Subject)
+		}, middleware.RequireRole(auth, middleware.RoleWriter), middleware.Idempotency(cfg, middleware.NewMemoryIdempotencyStore()))
+		postAs := func(subject string) *http.Response {
+			req := httptest.NewRequest(http.MethodPost, "/swiftCodes", strings.NewReader(`{}`))
+			req.Header.Set(middleware.HeaderIdempotencyKey, "key-1")
+			req.Header.Set(fiber.HeaderAuthorization, "Bearer "+signToken("secret", map[string]any{
+				"sub": subject, "iss": "swift-codes", "role": middleware.RoleWriter, "exp": time.Now().Add(time.Hour).Unix(),
+			}))
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			return resp
+		}
+
+		postAs("alice")
+		resp := postAs("bob")
+		Expect(resp.Header.Get(middleware.HeaderIdempotentReplayed)).To(BeEmpty())
+		body, _ := io.ReadAll(resp.Body)
+		Expect(string(body)).To(Equal("bob"))
+		Expect(postAs("alice").Header.Get(middleware.HeaderIdempotentReplayed)).To(Equal("true"))
+		Expect(inserts).To(Equal(2))
+	})
+
+	Context("when the handler fails", func() {
+		BeforeEach(func() {
+			status = fiber.StatusInternalServerError
+		})
+
+		It("should not record server errors so the request can be retried", func() {
+			post("key-1", `{}`)
+			post("key-1", `{}`)
+			Expect(inserts).To(Equal(2))
+		})
+	})
+
+	Context("when disabled", func() {
+		BeforeEach(func() {
+			cfg.Enabled = false
+		})
+
+		It("should ignore the header", func() {
+			post("key-1", `{}`)
+			Expect(post("key-1", `{}`).StatusCode).To(Equal(http.StatusConflict))
+		})
+	})
+})
+
+var _ = Describe("MemoryIdempotencyStore", func() {
+	It("should forget outcomes after their TTL", func() {
+		store := middleware.NewMemoryIdempotencyStore()
+		store.Store("key", middleware.StoredResponse{Status: fiber.StatusCreated}, 10*time.Millisecond)
+
+		_, ok := store.Load("key")
+		Expect(ok).To(BeTrue())
+		Eventually(func() bool {
+			_, ok := store.Load("key")
+			return ok
+		}).Should(BeFalse())
+	})
+})
@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+)
+
+// ErrIPNotAllowed is returned by AllowIP for clients outside the allowlist
+var ErrIPNotAllowed = errors.New("client address not allowed")
+
+// IPAllowlistConfig restricts write and admin endpoints to internal networks
+type IPAllowlistConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// CIDRs lists the allowed networks, such as "10.0.0.0/8"; a bare
+	// address allows that address only
+	CIDRs []string `koanf:"cidrs"`
+}
+
+// ProxyConfig describes the proxies the API runs behind. The allowlist, the
+// anonymous tier and the scraping guard read the client address through
+// them with ClientIP.
+type ProxyConfig struct {
+	// ProxyHeader names the header carrying the client address, such as
+	// X-Forwarded-For. It is only read from TrustedProxies.
+	ProxyHeader    string   `koanf:"proxy_header"`
+	TrustedProxies []string `koanf:"trusted_proxies"`
+}
+
+// Validate checks that a proxy header comes with the proxies it is read from
+func (c ProxyConfig) Validate() error {
+	if c.ProxyHeader != "" && len(c.TrustedProxies) == 0 {
+		return errors.New("server trusted_proxies cannot be empty when proxy_header is set")
+	}
+	return nil
+}
+
+// IPAllowlist matches client addresses against the configured networks. A
+// nil list allows every address.
+type IPAllowlist struct {
+	prefixes []netip.Prefix
+}
+
+// NewIPAllowlist parses the configured networks. It returns nil when the
+// allowlist is disabled.
+func NewIPAllowlist(cfg IPAllowlistConfig) (*IPAllowlist, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if len(cfg.CIDRs) == 0 {
+		return nil, errors.New("ip allowlist cidrs cannot be empty when the allowlist is enabled")
+	}
+	list := &IPAllowlist{prefixes: make([]netip.Prefix, 0, len(cfg.CIDRs))}
+	for _, cidr := range cfg.CIDRs {
+		cidr = strings.TrimSpace(cidr)
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(cidr)
+			if addrErr != nil {
+				return nil, fmt.Errorf("ip allowlist has invalid network %q", cidr)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		list.prefixes = append(list.prefixes, prefix.Masked())
+	}
+	return list, nil
+}
+
+// Allows reports whether the address ip is in one of the networks
+func (l *IPAllowlist) Allows(ip string) bool {
+	if l == nil {
+		return true
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range l.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowIP checks the client address of c against list and returns
+// ErrIPNotAllowed when it is outside every network
+func AllowIP(list *IPAllowlist, c fiber.Ctx) error {
+	if !list.Allows(ClientIP(c)) {
+		return ErrIPNotAllowed
+	}
+	return nil
+}
+
+// ClientIP returns the address of the client that sent c. Without a proxy
+// header, or when the peer is not a trusted proxy, that is the peer itself.
+// Otherwise the header is walked from the right, past the hops added by
+// trusted proxies, to the first address none of them vouches for. Entries
+// left of it were sent by the client and are ignored, unlike c.IP(), which
+// returns the leftmost one.
+func ClientIP(c fiber.Ctx) string {
+	peer := c.RequestCtx().RemoteIP().String()
+	cfg := c.App().Config()
+	if cfg.ProxyHeader == "" || !cfg.TrustProxy || !c.IsProxyTrusted() {
+		return peer
+	}
+
+	trusted := trustedProxies(cfg.TrustProxyConfig)
+	client := peer
+	hops := strings.Split(c.Get(cfg.ProxyHeader), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			// Nothing left of a malformed hop can be attributed
+			break
+		}
+		addr = addr.Unmap()
+		client = addr.String()
+		if !trusted(addr) {
+			break
+		}
+	}
+	return client
+}
+
+// trustedProxies reports whether an address belongs to one of the proxies
+// in cfg, which lists addresses and networks as fiber accepts them
+func trustedProxies(cfg fiber.TrustProxyConfig) func(netip.Addr) bool {
+	prefixes := make([]netip.Prefix, 0, len(cfg.Proxies))
+	for _, proxy := range cfg.Proxies {
+		proxy = strings.TrimSpace(proxy)
+		if prefix, err := netip.ParsePrefix(proxy); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(proxy); err == nil {
+			addr = addr.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+		}
+	}
+	return func(addr netip.Addr) bool {
+		if (cfg.Loopback && addr.IsLoopback()) ||
+			(cfg.Private && addr.IsPrivate()) ||
+			(cfg.LinkLocal && addr.IsLinkLocalUnicast()) {
+			return true
+		}
+		for _, prefix := range prefixes {
+			if prefix.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RespondIPError writes the 403 response for ErrIPNotAllowed
+func RespondIPError(c fiber.Ctx) error {
+	return apierror.Write(c, fiber.StatusForbidden, apierror.CodeForbidden, "Client address not allowed")
+}
@@ -0,0 +1,90 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+)
+
+var _ = Describe("IPAllowlist", func() {
+	It("should match networks, single addresses and mapped IPv4", func() {
+		list, err := middleware.NewIPAllowlist(middleware.IPAllowlistConfig{
+			Enabled: true, CIDRs: []string{"10.0.0.0/8", "192.168.1.7", "fd00::/8"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(list.Allows("10.20.30.40")).To(BeTrue())
+		Expect(list.Allows("::ffff:10.1.1.1")).To(BeTrue())
+		Expect(list.Allows("192.168.1.7")).To(BeTrue())
+		Expect(list.Allows("fd12::1")).To(BeTrue())
+		Expect(list.Allows("192.168.1.8")).To(BeFalse())
+		Expect(list.Allows("not-an-ip")).To(BeFalse())
+	})
+
+	It("should allow everyone when disabled", func() {
+		list, err := middleware.NewIPAllowlist(middleware.IPAllowlistConfig{CIDRs: []string{"10.0.0.0/8"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list).To(BeNil())
+		Expect(list.Allows("203.0.113.5")).To(BeTrue())
+	})
+
+	It("should reject invalid configurations", func() {
+		_, err := middleware.NewIPAllowlist(middleware.IPAllowlistConfig{Enabled: true})
+		Expect(err).To(HaveOccurred())
+
+		_, err = middleware.NewIPAllowlist(middleware.IPAllowlistConfig{Enabled: true, CIDRs: []string{"10.0.0.0/33"}})
+		Expect(err).To(MatchError(ContainSubstring("10.0.0.0/33")))
+	})
+
+	It("should require trusted proxies for a proxy header", func() {
+		Expect(middleware.ProxyConfig{}.Validate()).To(Succeed())
+		Expect(middleware.ProxyConfig{ProxyHeader: "X-Forwarded-For"}.Validate()).To(MatchError(ContainSubstring("trusted_proxies")))
+		Expect(middleware.ProxyConfig{ProxyHeader: "X-Forwarded-For", TrustedProxies: []string{"10.0.0.1"}}.Validate()).To(Succeed())
+	})
+
+	Context("behind trusted proxies", func() {
+		var app *fiber.App
+
+		BeforeEach(func() {
+			list, err := middleware.NewIPAllowlist(middleware.IPAllowlistConfig{Enabled: true, CIDRs: []string{"10.0.0.0/8"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			// app.Test connects from 0.0.0.0, which stands in for the edge proxy
+			app = fiber.New(fiber.Config{
+				ProxyHeader:        fiber.HeaderXForwardedFor,
+				TrustProxy:         true,
+				TrustProxyConfig:   fiber.TrustProxyConfig{Proxies: []string{"0.0.0.0", "172.16.0.0/12"}},
+				EnableIPValidation: true,
+			})
+			app.Post("/", func(c fiber.Ctx) error {
+				if err := middleware.AllowIP(list, c); err != nil {
+					return middleware.RespondIPError(c)
+				}
+				return c.SendString(middleware.ClientIP(c))
+			})
+		})
+
+		post := func(forwardedFor string) *http.Response {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.Header.Set(fiber.HeaderXForwardedFor, forwardedFor)
+			resp, err := app.Test(req)
+			Expect(err).NotTo(HaveOccurred())
+			return resp
+		}
+
+		It("should ignore a spoofed leftmost entry", func() {
+			resp := post("10.0.0.1, 203.0.113.9, 172.16.0.5")
+			Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+		})
+
+		It("should check the first address not added by a trusted proxy", func() {
+			resp := post("203.0.113.9, 10.0.0.2, 172.16.0.5")
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		})
+	})
+})
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// LastModified answers conditional GETs from the dataset's modification
+// time: an If-Modified-Since at or after it gets 304 without running the
+// handler, and successful responses carry a Last-Modified header. The time
+// is read before the handler runs, so a write racing the request can only
+// make the stamp older than the data, never newer.
+func LastModified(modified func() time.Time) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+			return c.Next()
+		}
+
+		lastModified := modified()
+		if since := c.Get(fiber.HeaderIfModifiedSince); since != "" && c.Get(fiber.HeaderIfNoneMatch) == "" {
+			if t, err := http.ParseTime(since); err == nil && !lastModified.After(t) {
+				c.Set(fiber.HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+				return c.SendStatus(fiber.StatusNotModified)
+			}
+		}
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if c.Response().StatusCode() == fiber.StatusOK {
+			c.Set(fiber.HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+		}
+		return nil
+	}
+}
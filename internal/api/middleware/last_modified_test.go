@@ -0,0 +1,70 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+)
+
+var _ = Describe("LastModified", func() {
+	var (
+		app      *fiber.App
+		modified time.Time
+		calls    int
+	)
+
+	BeforeEach(func() {
+		modified = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+		calls = 0
+		app = fiber.New()
+		conditional := middleware.LastModified(func() time.Time { return modified })
+		app.Get("/codes", func(c fiber.Ctx) error {
+			calls++
+			return c.SendString("data")
+		}, conditional)
+		app.Get("/missing", func(c fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusNotFound)
+		}, conditional)
+	})
+
+	get := func(path, ifModifiedSince string) *http.Response {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if ifModifiedSince != "" {
+			req.Header.Set(fiber.HeaderIfModifiedSince, ifModifiedSince)
+		}
+		resp, err := app.Test(req, fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	It("should stamp successful responses", func() {
+		resp := get("/codes", "")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get(fiber.HeaderLastModified)).To(Equal("Sat, 01 Mar 2025 12:00:00 GMT"))
+
+		Expect(get("/missing", "").Header.Get(fiber.HeaderLastModified)).To(BeEmpty())
+	})
+
+	It("should answer 304 without running the handler when nothing changed", func() {
+		resp := get("/codes", "Sat, 01 Mar 2025 12:00:00 GMT")
+		Expect(resp.StatusCode).To(Equal(http.StatusNotModified))
+		Expect(calls).To(BeZero())
+	})
+
+	It("should serve the data after a change", func() {
+		modified = modified.Add(time.Second)
+		resp := get("/codes", "Sat, 01 Mar 2025 12:00:00 GMT")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("should ignore malformed dates", func() {
+		Expect(get("/codes", "yesterday").StatusCode).To(Equal(http.StatusOK))
+	})
+})
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+)
+
+// QueryParam describes the valid values of one query parameter
+type QueryParam struct {
+	Name string
+	// Check returns why value is invalid; it only sees non-empty values
+	Check func(value string) error
+}
+
+// IntParam accepts integers from min to max
+func IntParam(name string, min, max int) QueryParam {
+	reason := fmt.Sprintf("must be an integer between %d and %d", min, max)
+	if max == math.MaxInt {
+		reason = fmt.Sprintf("must be an integer of at least %d", min)
+	}
+	return QueryParam{Name: name, Check: func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < min || n > max {
+			return errors.New(reason)
+		}
+		return nil
+	}}
+}
+
+// OneOfParam accepts one of values, ignoring case
+func OneOfParam(name string, values ...string) QueryParam {
+	reason := "must be one of " + strings.Join(values, ", ")
+	return QueryParam{Name: name, Check: func(value string) error {
+		for _, v := range values {
+			if strings.EqualFold(value, v) {
+				return nil
+			}
+		}
+		return errors.New(reason)
+	}}
+}
+
+// BoolParam accepts true or false
+func BoolParam(name string) QueryParam {
+	return QueryParam{Name: name, Check: func(value string) error {
+		if _, err := strconv.ParseBool(value); err != nil {
+			return errors.New("must be true or false")
+		}
+		return nil
+	}}
+}
+
+// ValidateQuery checks the query parameters of a request before the
+// handler runs and answers 400 with a detail for every invalid one, in the
+// order of params. Absent and empty parameters are left to the handler's
+// defaults.
+func ValidateQuery(params ...QueryParam) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		var details []apierror.Detail
+		for _, param := range params {
+			value := c.Query(param.Name)
+			if value == "" {
+				continue
+			}
+			if err := param.Check(value); err != nil {
+				details = append(details, apierror.Field(param.Name, err.Error()))
+			}
+		}
+		if len(details) > 0 {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeInvalidInput, "Invalid query parameters", details...)
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,65 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+)
+
+var _ = Describe("ValidateQuery", func() {
+	get := func(target string) (*http.Response, apierror.Error) {
+		app := fiber.New()
+		app.Get("/list", func(c fiber.Ctx) error {
+			return c.SendString("ok")
+		}, middleware.ValidateQuery(
+			middleware.IntParam("limit", 1, 100),
+			middleware.IntParam("offset", 0, math.MaxInt),
+			middleware.OneOfParam("format", "json", "csv"),
+			middleware.BoolParam("envelope"),
+			middleware.QueryParam{Name: "sort", Check: func(v string) error {
+				if v != "name" {
+					return errors.New("unknown sort field")
+				}
+				return nil
+			}},
+		))
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, target, nil), fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		var body apierror.Error
+		if resp.StatusCode != http.StatusOK {
+			Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		}
+		return resp, body
+	}
+
+	It("should pass valid and absent parameters", func() {
+		resp, _ := get("/list?limit=100&offset=0&format=CSV&envelope=true&sort=name")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		resp, _ = get("/list?limit=&other=anything")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	})
+
+	It("should list every invalid parameter in declaration order", func() {
+		resp, body := get("/list?sort=rank&format=yaml&limit=0&offset=-1&envelope=maybe")
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		Expect(body.Code).To(Equal(apierror.CodeInvalidInput))
+		Expect(body.Details).To(Equal([]apierror.Detail{
+			{Field: "limit", Reason: "must be an integer between 1 and 100"},
+			{Field: "offset", Reason: "must be an integer of at least 0"},
+			{Field: "format", Reason: "must be one of json, csv"},
+			{Field: "envelope", Reason: "must be true or false"},
+			{Field: "sort", Reason: "unknown sort field"},
+		}))
+	})
+})
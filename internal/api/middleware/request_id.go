@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+)
+
+// RequestID reuses a well-formed X-Request-ID from the client or generates
+// one, puts it in the context and echoes it in the response header. Error
+// bodies written with apierror pick it up from the context.
+func RequestID() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		id := c.Get(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+		c.SetContext(requestid.NewContext(c.Context(), id))
+		c.Set(requestid.Header, id)
+
+		err := c.Next()
+		if err != nil {
+			// Write the error while the ID is still in the context
+			return c.App().ErrorHandler(c, err)
+		}
+		return nil
+	}
+}
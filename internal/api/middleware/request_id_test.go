@@ -0,0 +1,79 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+)
+
+var _ = Describe("RequestID", func() {
+	var (
+		app  *fiber.App
+		seen string
+	)
+
+	BeforeEach(func() {
+		app = fiber.New()
+		app.Use(middleware.RequestID())
+		app.Get("/ok", func(c fiber.Ctx) error {
+			seen = requestid.FromContext(c.Context())
+			return c.JSON(fiber.Map{"message": "ok"})
+		})
+		app.Get("/missing", func(c fiber.Ctx) error {
+			return apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "SWIFT code not found")
+		})
+		app.Get("/fail", func(c fiber.Ctx) error {
+			return fiber.ErrServiceUnavailable
+		})
+	})
+
+	get := func(path, id string) *http.Response {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if id != "" {
+			req.Header.Set(requestid.Header, id)
+		}
+		resp, err := app.Test(req, fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	decode := func(resp *http.Response) map[string]any {
+		var body map[string]any
+		Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		return body
+	}
+
+	It("should propagate a client ID into the context and response", func() {
+		resp := get("/ok", "client-123")
+		Expect(resp.Header.Get(requestid.Header)).To(Equal("client-123"))
+		Expect(seen).To(Equal("client-123"))
+		Expect(decode(resp)).NotTo(HaveKey("requestId"))
+	})
+
+	It("should replace a malformed client ID", func() {
+		resp := get("/ok", `bad"id`)
+		Expect(resp.Header.Get(requestid.Header)).To(MatchRegexp(`^[0-9a-f]{32}$`))
+		Expect(seen).To(Equal(resp.Header.Get(requestid.Header)))
+	})
+
+	It("should add the ID to error bodies", func() {
+		resp := get("/missing", "client-123")
+		body := decode(resp)
+		Expect(body).To(HaveKeyWithValue("requestId", "client-123"))
+		Expect(body).To(HaveKeyWithValue("message", "SWIFT code not found"))
+	})
+
+	It("should annotate errors returned to the error handler", func() {
+		resp := get("/fail", "")
+		Expect(resp.StatusCode).To(Equal(http.StatusServiceUnavailable))
+		Expect(resp.Header.Get(requestid.Header)).NotTo(BeEmpty())
+	})
+})
@@ -0,0 +1,319 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+)
+
+// Actions taken against a client flagged as scraping
+const (
+	// ScrapeActionLog only logs and counts the detection
+	ScrapeActionLog = "log"
+	// ScrapeActionThrottle rejects the client's lookups until its penalty
+	// runs out
+	ScrapeActionThrottle = "throttle"
+	// ScrapeActionRequireAuth rejects the lookups of an anonymous client
+	// until it presents a bearer token; authenticated clients are
+	// accountable and only logged
+	ScrapeActionRequireAuth = "require_auth"
+)
+
+// ScrapingConfig sets the heuristics that tell a client enumerating SWIFT
+// codes from one looking up the codes it needs
+type ScrapingConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// Window is the sliding period every heuristic looks back over
+	Window time.Duration `koanf:"window"`
+	// MaxDistinctCodes is how many different codes a client may look up in
+	// the window; 0 disables the check
+	MaxDistinctCodes int `koanf:"max_distinct_codes"`
+	// MaxSequentialRun is how many codes in a row a client may look up in
+	// ascending order; 0 disables the check
+	MaxSequentialRun int `koanf:"max_sequential_run"`
+	// MaxNotFound is how many lookups of unknown codes a client may make in
+	// the window, guessed codes mostly missing; 0 disables the check
+	MaxNotFound int `koanf:"max_not_found"`
+	// Action is "log", "throttle" or "require_auth"
+	Action string `koanf:"action"`
+	// Penalty is how long a client stays flagged after a detection
+	Penalty time.Duration `koanf:"penalty"`
+}
+
+// Validate checks the window, thresholds and action of an enabled guard
+func (c ScrapingConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Window <= 0 || c.Penalty <= 0 {
+		return errors.New("scraping window and penalty must be positive")
+	}
+	if c.MaxDistinctCodes < 0 || c.MaxSequentialRun < 0 || c.MaxNotFound < 0 {
+		return errors.New("scraping thresholds cannot be negative")
+	}
+	if c.MaxDistinctCodes == 0 && c.MaxSequentialRun == 0 && c.MaxNotFound == 0 {
+		return errors.New("scraping needs at least one threshold when enabled")
+	}
+	switch c.Action {
+	case ScrapeActionLog, ScrapeActionThrottle, ScrapeActionRequireAuth:
+		return nil
+	default:
+		return fmt.Errorf("scraping action %q must be log, throttle or require_auth", c.Action)
+	}
+}
+
+// maxScrapeClients bounds how many clients are tracked before those with
+// nothing left in the window are dropped
+const maxScrapeClients = 10000
+
+// ScrapeGuard watches the codes each client looks up, keyed like the tiers
+// by bearer token subject or address, and flags clients whose pattern looks
+// like an enumeration of the dataset
+type ScrapeGuard struct {
+	cfg  ScrapingConfig
+	auth AuthConfig
+
+	mu      sync.Mutex
+	clients map[string]*scrapeClient
+
+	detections atomic.Int64
+	rejected   atomic.Int64
+}
+
+// scrapeClient is the recent lookup activity of one client
+type scrapeClient struct {
+	// codes holds when each distinct code was last looked up
+	codes map[string]time.Time
+	// misses holds when unknown codes were looked up, oldest first
+	misses []time.Time
+	// last and run track the current ascending run of codes
+	last         string
+	lastAt       time.Time
+	run          int
+	flaggedUntil time.Time
+}
+
+// ScrapingStats are the detection counters of a guard
+type ScrapingStats struct {
+	// Detections counts clients flagged since the process started
+	Detections int64
+	// Rejected counts lookups refused to flagged clients
+	Rejected int64
+	// Flagged is how many clients are flagged now
+	Flagged int
+}
+
+// NewScrapeGuard creates a guard with cfg's heuristics; auth identifies
+// clients that present a bearer token
+func NewScrapeGuard(cfg ScrapingConfig, auth AuthConfig) *ScrapeGuard {
+	return &ScrapeGuard{cfg: cfg, auth: auth, clients: make(map[string]*scrapeClient)}
+}
+
+// Stats returns the detection counters
+func (g *ScrapeGuard) Stats() ScrapingStats {
+	now := time.Now()
+	g.mu.Lock()
+	flagged := 0
+	for _, client := range g.clients {
+		if now.Before(client.flaggedUntil) {
+			flagged++
+		}
+	}
+	g.mu.Unlock()
+	return ScrapingStats{Detections: g.detections.Load(), Rejected: g.rejected.Load(), Flagged: flagged}
+}
+
+// lookupsKey keys the lookupRecorder of a request in its context
+type lookupsKey struct{}
+
+// lookupRecorder collects the codes a handler looked up itself, such as
+// the rows of a validated file or the fields of a GraphQL query
+type lookupRecorder struct {
+	mu     sync.Mutex
+	codes  []string
+	misses int
+}
+
+// RecordLookup tells the scrape guard watching the request of ctx that code
+// was looked up, and whether it was found. Handlers that look up codes
+// other than the one in their path call it for each; without a guard it
+// does nothing.
+func RecordLookup(ctx context.Context, code string, found bool) {
+	rec, ok := ctx.Value(lookupsKey{}).(*lookupRecorder)
+	if !ok {
+		return
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.codes = append(rec.codes, strings.ToUpper(code))
+	if !found {
+		rec.misses++
+	}
+}
+
+// Handler returns middleware recording the codes of :swiftCode, :base and
+// ?codes= lookups and those the handler reports with RecordLookup. A
+// flagged client is refused according to the action; otherwise the lookup
+// is served and its outcome fed to the heuristics.
+func (g *ScrapeGuard) Handler() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		// Lookups are public, so an invalid token only counts as anonymous
+		// here; the tiers reject it when they are enabled
+		claims, _ := RequestClaims(g.auth, c)
+		key := "ip:" + ClientIP(c)
+		if claims != nil {
+			key = "sub:" + claims.Subject
+		}
+
+		now := time.Now()
+		if until := g.flaggedUntil(key); now.Before(until) {
+			switch {
+			case g.cfg.Action == ScrapeActionThrottle:
+				g.rejected.Add(1)
+				c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(until.Sub(now).Seconds()))))
+				return apierror.Write(c, fiber.StatusTooManyRequests, apierror.CodeRateLimited, "Too many lookups of distinct SWIFT codes")
+			case g.cfg.Action == ScrapeActionRequireAuth && claims == nil:
+				g.rejected.Add(1)
+				return apierror.Write(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "Authentication required")
+			}
+		}
+
+		rec := &lookupRecorder{}
+		c.SetContext(context.WithValue(c.Context(), lookupsKey{}, rec))
+		err := c.Next()
+		status := c.Response().StatusCode()
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			status = fiberErr.Code
+		}
+
+		rec.mu.Lock()
+		codes, misses := append(lookupCodes(c), rec.codes...), rec.misses
+		rec.mu.Unlock()
+		if misses == 0 && status == fiber.StatusNotFound {
+			misses = 1
+		}
+		if reason := g.record(key, codes, misses, time.Now()); reason != "" {
+			log.Printf("WARNING: possible scraping by %s: %s", key, reason)
+		}
+		return err
+	}
+}
+
+// lookupCodes returns the codes a lookup asks for, copied since fiber reuses
+// the memory of parameters after the request
+func lookupCodes(c fiber.Ctx) []string {
+	if code := c.Params("swiftCode"); code != "" {
+		return []string{strings.Clone(strings.ToUpper(code))}
+	}
+	if base := c.Params("base"); base != "" {
+		return []string{strings.Clone(strings.ToUpper(base))}
+	}
+	var codes []string
+	for _, code := range strings.Split(c.Query("codes"), ",") {
+		if code = strings.ToUpper(strings.TrimSpace(code)); code != "" {
+			codes = append(codes, strings.Clone(code))
+		}
+	}
+	return codes
+}
+
+func (g *ScrapeGuard) flaggedUntil(key string) time.Time {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if client, ok := g.clients[key]; ok {
+		return client.flaggedUntil
+	}
+	return time.Time{}
+}
+
+// record adds a lookup of codes, misses of them unknown, to key's activity
+// and returns why the client was flagged, or "" when it was not newly
+// flagged
+func (g *ScrapeGuard) record(key string, codes []string, misses int, now time.Time) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	client, ok := g.clients[key]
+	if !ok {
+		if len(g.clients) >= maxScrapeClients {
+			g.prune(now)
+		}
+		client = &scrapeClient{codes: make(map[string]time.Time)}
+		g.clients[key] = client
+	}
+	client.expire(now.Add(-g.cfg.Window))
+
+	var reason string
+	for _, code := range codes {
+		// Past the threshold the count needs to grow no further
+		if _, seen := client.codes[code]; g.cfg.MaxDistinctCodes > 0 && (seen || len(client.codes) <= g.cfg.MaxDistinctCodes) {
+			client.codes[code] = now
+		}
+		if code > client.last && now.Sub(client.lastAt) <= g.cfg.Window {
+			client.run++
+		} else if code != client.last {
+			client.run = 1
+		}
+		client.last, client.lastAt = code, now
+
+		if g.cfg.MaxSequentialRun > 0 && client.run > g.cfg.MaxSequentialRun {
+			reason = fmt.Sprintf("%d codes looked up in ascending order", client.run)
+		}
+	}
+	if misses > 0 && g.cfg.MaxNotFound > 0 {
+		for range misses {
+			client.misses = append(client.misses, now)
+		}
+		if len(client.misses) > g.cfg.MaxNotFound {
+			reason = fmt.Sprintf("%d unknown codes looked up within %s", len(client.misses), g.cfg.Window)
+			client.misses = client.misses[len(client.misses)-g.cfg.MaxNotFound:]
+		}
+	}
+	if g.cfg.MaxDistinctCodes > 0 && len(client.codes) > g.cfg.MaxDistinctCodes {
+		reason = fmt.Sprintf("%d distinct codes looked up within %s", len(client.codes), g.cfg.Window)
+	}
+
+	if reason == "" || now.Before(client.flaggedUntil) {
+		return ""
+	}
+	client.flaggedUntil = now.Add(g.cfg.Penalty)
+	g.detections.Add(1)
+	return reason
+}
+
+// expire forgets the activity from before cutoff
+func (s *scrapeClient) expire(cutoff time.Time) {
+	for code, at := range s.codes {
+		if at.Before(cutoff) {
+			delete(s.codes, code)
+		}
+	}
+	drop := 0
+	for drop < len(s.misses) && s.misses[drop].Before(cutoff) {
+		drop++
+	}
+	s.misses = s.misses[drop:]
+}
+
+// prune drops the clients that are not flagged and whose activity has all
+// left the window
+func (g *ScrapeGuard) prune(now time.Time) {
+	cutoff := now.Add(-g.cfg.Window)
+	for key, client := range g.clients {
+		if now.Before(client.flaggedUntil) || client.lastAt.After(cutoff) {
+			continue
+		}
+		delete(g.clients, key)
+	}
+}
@@ -0,0 +1,170 @@
+package middleware_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+)
+
+var _ = Describe("ScrapeGuard", func() {
+	var (
+		app   *fiber.App
+		cfg   middleware.ScrapingConfig
+		auth  middleware.AuthConfig
+		guard *middleware.ScrapeGuard
+	)
+
+	BeforeEach(func() {
+		auth = middleware.AuthConfig{Enabled: true, SigningKey: "secret"}
+		cfg = middleware.ScrapingConfig{
+			Enabled:          true,
+			Window:           time.Minute,
+			MaxDistinctCodes: 5,
+			MaxSequentialRun: 3,
+			MaxNotFound:      2,
+			Action:           middleware.ScrapeActionThrottle,
+			Penalty:          time.Minute,
+		}
+	})
+
+	JustBeforeEach(func() {
+		guard = middleware.NewScrapeGuard(cfg, auth)
+		app = fiber.New()
+		app.Get("/swiftCodes/:swiftCode", func(c fiber.Ctx) error {
+			if c.Params("swiftCode") == "UNKNOWNXXXX" {
+				return c.SendStatus(fiber.StatusNotFound)
+			}
+			return c.SendString(c.Params("swiftCode"))
+		}, guard.Handler())
+		app.Get("/swiftCodes", func(c fiber.Ctx) error {
+			return c.SendString(c.Query("codes"))
+		}, guard.Handler())
+		app.Post("/validate/file", func(c fiber.Ctx) error {
+			for _, code := range strings.Split(c.Query("codes"), ",") {
+				middleware.RecordLookup(c.Context(), code, code != "UNKNOWNXXXX")
+			}
+			return c.SendStatus(fiber.StatusOK)
+		}, guard.Handler())
+	})
+
+	doRequest := func(path, token string) *http.Response {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := app.Test(req, fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	lookup := func(code, token string) int {
+		return doRequest("/swiftCodes/"+code, token).StatusCode
+	}
+
+	It("should serve lookups in no particular order", func() {
+		for _, code := range []string{"PKOPPLPWXXX", "BREXPLPWXXX", "DEUTDEFFXXX", "ALBPPLPWXXX"} {
+			Expect(lookup(code, "")).To(Equal(http.StatusOK))
+		}
+		Expect(guard.Stats().Detections).To(BeZero())
+	})
+
+	It("should throttle a client looking up codes in ascending order", func() {
+		for i := 0; i < 4; i++ {
+			Expect(lookup(fmt.Sprintf("AAAAPLPW%03d", i), "")).To(Equal(http.StatusOK))
+		}
+
+		resp := doRequest("/swiftCodes/AAAAPLPW004", "")
+		Expect(resp.StatusCode).To(Equal(http.StatusTooManyRequests))
+		Expect(resp.Header.Get("Retry-After")).To(Equal("60"))
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(ContainSubstring("RATE_LIMITED"))
+
+		Expect(lookup("PKOPPLPWXXX", signToken("secret", map[string]any{"sub": "alice"}))).To(Equal(http.StatusOK))
+		Expect(guard.Stats()).To(Equal(middleware.ScrapingStats{Detections: 1, Rejected: 1, Flagged: 1}))
+	})
+
+	It("should count the distinct codes of batch lookups", func() {
+		Expect(doRequest("/swiftCodes?codes=PKOPPLPWXXX,BREXPLPWXXX,DEUTDEFFXXX", "").StatusCode).To(Equal(http.StatusOK))
+		Expect(doRequest("/swiftCodes?codes=ALBPPLPWXXX,BPKOPLPWXXX,INGBPLPWXXX", "").StatusCode).To(Equal(http.StatusOK))
+		Expect(doRequest("/swiftCodes?codes=PKOPPLPWXXX", "").StatusCode).To(Equal(http.StatusTooManyRequests))
+	})
+
+	It("should count the codes a handler looks up itself", func() {
+		req := httptest.NewRequest(http.MethodPost, "/validate/file?codes=PKOPPLPWXXX,BREXPLPWXXX,DEUTDEFFXXX,ALBPPLPWXXX,BPKOPLPWXXX,INGBPLPWXXX", nil)
+		resp, err := app.Test(req, fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(lookup("PKOPPLPWXXX", "")).To(Equal(http.StatusTooManyRequests))
+	})
+
+	It("should count every miss a handler records", func() {
+		req := httptest.NewRequest(http.MethodPost, "/validate/file?codes=UNKNOWNXXXX,UNKNOWNXXXX,UNKNOWNXXXX", nil)
+		resp, err := app.Test(req, fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(lookup("PKOPPLPWXXX", "")).To(Equal(http.StatusTooManyRequests))
+	})
+
+	It("should flag a client whose lookups keep missing", func() {
+		for i := 0; i < 3; i++ {
+			Expect(lookup("UNKNOWNXXXX", "")).To(Equal(http.StatusNotFound))
+		}
+		Expect(lookup("PKOPPLPWXXX", "")).To(Equal(http.StatusTooManyRequests))
+	})
+
+	Context("when the action is log", func() {
+		BeforeEach(func() {
+			cfg.Action = middleware.ScrapeActionLog
+		})
+
+		It("should count the detection but keep serving", func() {
+			for i := 0; i < 6; i++ {
+				Expect(lookup(fmt.Sprintf("AAAAPLPW%03d", i), "")).To(Equal(http.StatusOK))
+			}
+			Expect(guard.Stats()).To(Equal(middleware.ScrapingStats{Detections: 1, Flagged: 1}))
+		})
+	})
+
+	Context("when the action is require_auth", func() {
+		BeforeEach(func() {
+			cfg.Action = middleware.ScrapeActionRequireAuth
+		})
+
+		It("should ask anonymous clients for a token and keep serving authenticated ones", func() {
+			alice := signToken("secret", map[string]any{"sub": "alice"})
+			for i := 0; i < 4; i++ {
+				Expect(lookup(fmt.Sprintf("AAAAPLPW%03d", i), "")).To(Equal(http.StatusOK))
+				Expect(lookup(fmt.Sprintf("AAAAPLPW%03d", i), alice)).To(Equal(http.StatusOK))
+			}
+
+			Expect(lookup("AAAAPLPW004", "")).To(Equal(http.StatusUnauthorized))
+			Expect(lookup("AAAAPLPW004", alice)).To(Equal(http.StatusOK))
+			Expect(guard.Stats().Detections).To(BeEquivalentTo(2))
+		})
+	})
+
+	It("should validate the window, thresholds and action", func() {
+		Expect(cfg.Validate()).To(Succeed())
+
+		cfg.Action = "block"
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring(`action "block"`)))
+
+		cfg.Action = middleware.ScrapeActionLog
+		cfg.MaxDistinctCodes, cfg.MaxSequentialRun, cfg.MaxNotFound = 0, 0, 0
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("at least one threshold")))
+
+		cfg.MaxNotFound = 1
+		cfg.Penalty = 0
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("penalty")))
+	})
+})
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/timing"
+)
+
+// HeaderServerTiming reports per-stage request durations
+const HeaderServerTiming = "Server-Timing"
+
+// ServerTiming attaches request timings to the context and reports them in
+// the Server-Timing header: parse, service, trino, serialize and total. The
+// service and trino stages are only filled when the service and repository
+// are wrapped with their WithTiming decorators.
+func ServerTiming() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		ctx, timings := timing.NewContext(c.Context())
+		c.SetContext(ctx)
+
+		err := c.Next()
+		c.Set(HeaderServerTiming, timings.Header(time.Now()))
+		return err
+	}
+}
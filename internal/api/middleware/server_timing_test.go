@@ -0,0 +1,29 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+	"github.com/zdziszkee/swift-codes/internal/timing"
+)
+
+var _ = Describe("ServerTiming", func() {
+	It("should report the stages recorded while handling the request", func() {
+		app := fiber.New()
+		app.Use(middleware.ServerTiming())
+		app.Get("/", func(c fiber.Ctx) error {
+			timing.Track(c.Context(), timing.StageService)()
+			return c.SendStatus(fiber.StatusOK)
+		})
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil), fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Header.Get(middleware.HeaderServerTiming)).To(
+			MatchRegexp(`^parse;dur=[\d.]+, service;dur=[\d.]+, serialize;dur=[\d.]+, total;dur=[\d.]+$`))
+	})
+})
@@ -0,0 +1,212 @@
+package middleware
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+// TiersConfig splits callers into an authenticated tier, identified by the
+// subject of their bearer token, and an anonymous tier, identified by client
+// address, each with its own request quota
+type TiersConfig struct {
+	Enabled       bool       `koanf:"enabled"`
+	Anonymous     TierConfig `koanf:"anonymous"`
+	Authenticated TierConfig `koanf:"authenticated"`
+}
+
+// TierConfig is the quota and field visibility of one tier
+type TierConfig struct {
+	// RequestsPerMinute is the sustained rate allowed per caller; 0 means
+	// unlimited
+	RequestsPerMinute int `koanf:"requests_per_minute"`
+	// Burst is how many requests a caller may make at once; 0 means
+	// RequestsPerMinute
+	Burst int `koanf:"burst"`
+	// HiddenFields lists the fields ("address", "website", "phone") served
+	// redacted to the tier
+	HiddenFields []string `koanf:"hidden_fields"`
+}
+
+// Validate checks the quotas and hidden field names of both tiers
+func (c TiersConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	tiers := []struct {
+		name string
+		TierConfig
+	}{{"anonymous", c.Anonymous}, {"authenticated", c.Authenticated}}
+	for _, tier := range tiers {
+		if tier.RequestsPerMinute < 0 || tier.Burst < 0 {
+			return fmt.Errorf("tiers %s quotas cannot be negative", tier.name)
+		}
+		if err := service.ValidateRedactedFields(tier.HiddenFields); err != nil {
+			return fmt.Errorf("tiers %s: %w", tier.name, err)
+		}
+	}
+	return nil
+}
+
+// Tiers returns middleware that rate limits every caller by tier and serves
+// anonymous callers their reduced fields. A request carrying a bearer token
+// must present a valid one; it is not demoted to the anonymous tier. When
+// tiers are disabled it is a no-op.
+func Tiers(cfg TiersConfig, auth AuthConfig) fiber.Handler {
+	if !cfg.Enabled {
+		return func(c fiber.Ctx) error { return c.Next() }
+	}
+	return TiersWith(NewTierLimits(cfg), auth)
+}
+
+// TierLimits holds the quotas and hidden fields in force, so that they can
+// be changed without a restart
+type TierLimits struct {
+	state atomic.Pointer[tierState]
+}
+
+type tierState struct {
+	cfg           TiersConfig
+	anonymous     *rateLimiter
+	authenticated *rateLimiter
+}
+
+// NewTierLimits returns limits that start out as cfg
+func NewTierLimits(cfg TiersConfig) *TierLimits {
+	limits := &TierLimits{}
+	limits.Update(cfg)
+	return limits
+}
+
+// Update puts cfg in force; every caller starts again with a full bucket
+func (l *TierLimits) Update(cfg TiersConfig) {
+	l.state.Store(&tierState{
+		cfg:           cfg,
+		anonymous:     newRateLimiter(cfg.Anonymous),
+		authenticated: newRateLimiter(cfg.Authenticated),
+	})
+}
+
+// TiersWith is Tiers for limits that may be updated while it serves
+func TiersWith(limits *TierLimits, auth AuthConfig) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		claims, err := RequestClaims(auth, c)
+		if err != nil {
+			return RespondAuthError(c, err)
+		}
+		state := limits.state.Load()
+		limiter, key := state.anonymous, "ip:"+ClientIP(c)
+		if claims != nil {
+			limiter, key = state.authenticated, "sub:"+claims.Subject
+			if len(state.cfg.Authenticated.HiddenFields) > 0 {
+				c.SetContext(service.WithRedactedFields(c.Context(), state.cfg.Authenticated.HiddenFields))
+			}
+		} else if len(state.cfg.Anonymous.HiddenFields) > 0 {
+			c.SetContext(service.WithRedactedFields(c.Context(), state.cfg.Anonymous.HiddenFields))
+		}
+
+		if limiter == nil {
+			return c.Next()
+		}
+		remaining, retryAfter, ok := limiter.take(key, time.Now())
+		c.Set("X-RateLimit-Limit", strconv.Itoa(limiter.perMinute))
+		c.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		if !ok {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			return apierror.Write(c, fiber.StatusTooManyRequests, apierror.CodeRateLimited, "Rate limit exceeded")
+		}
+		return c.Next()
+	}
+}
+
+// maxIdleBuckets bounds how many callers are tracked before full buckets,
+// which carry no state worth keeping, are dropped
+const maxIdleBuckets = 10000
+
+// limiterShards splits the buckets of a limiter, so that callers mostly
+// wait on different locks and a prune only scans one shard
+const limiterShards = 64
+
+// rateLimiter is a token bucket per caller key
+type rateLimiter struct {
+	perMinute int
+	burst     float64
+	perSecond float64
+
+	shards [limiterShards]limiterShard
+}
+
+// limiterShard holds the buckets of the keys hashing to it
+type limiterShard struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter returns the limiter of a tier, or nil for an unlimited one
+func newRateLimiter(cfg TierConfig) *rateLimiter {
+	if cfg.RequestsPerMinute == 0 {
+		return nil
+	}
+	burst := cfg.Burst
+	if burst == 0 {
+		burst = cfg.RequestsPerMinute
+	}
+	l := &rateLimiter{
+		perMinute: cfg.RequestsPerMinute,
+		burst:     float64(burst),
+		perSecond: float64(cfg.RequestsPerMinute) / 60,
+	}
+	for i := range l.shards {
+		l.shards[i].buckets = make(map[string]*bucket)
+	}
+	return l
+}
+
+// take spends a token of key, reporting the whole tokens left, or how long
+// until the next one when none is available
+func (l *rateLimiter) take(key string, now time.Time) (remaining int, retryAfter time.Duration, ok bool) {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	shard := &l.shards[h.Sum32()%limiterShards]
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	b, found := shard.buckets[key]
+	if !found {
+		if len(shard.buckets) >= maxIdleBuckets/limiterShards {
+			l.prune(shard, now)
+		}
+		b = &bucket{tokens: l.burst, last: now}
+		shard.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.perSecond)
+	b.last = now
+
+	if b.tokens < 1 {
+		return 0, time.Duration((1 - b.tokens) / l.perSecond * float64(time.Second)), false
+	}
+	b.tokens--
+	return int(b.tokens), 0, true
+}
+
+// prune drops the buckets of shard that have refilled completely
+func (l *rateLimiter) prune(shard *limiterShard, now time.Time) {
+	for key, b := range shard.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.perSecond >= l.burst {
+			delete(shard.buckets, key)
+		}
+	}
+}
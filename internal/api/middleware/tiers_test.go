@@ -0,0 +1,161 @@
+package middleware_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+var _ = Describe("Tiers", func() {
+	var (
+		app     *fiber.App
+		cfg     middleware.TiersConfig
+		auth    middleware.AuthConfig
+		handler fiber.Handler
+	)
+
+	BeforeEach(func() {
+		auth = middleware.AuthConfig{Enabled: true, SigningKey: "secret"}
+		cfg = middleware.TiersConfig{
+			Enabled:       true,
+			Anonymous:     middleware.TierConfig{RequestsPerMinute: 60, Burst: 2, HiddenFields: []string{service.MaskPhone}},
+			Authenticated: middleware.TierConfig{RequestsPerMinute: 600, Burst: 5},
+		}
+	})
+
+	JustBeforeEach(func() {
+		svc := service.WithRedaction(&mocks.MockSwiftService{
+			GetSwiftCodeDetailsFunc: func(_ context.Context, code string) (*repository.SwiftBankDetail, error) {
+				return &repository.SwiftBankDetail{Bank: models.SwiftBank{SwiftCode: code, Phone: "+48 22 000 00 00"}}, nil
+			},
+		})
+		handler = func(c fiber.Ctx) error {
+			detail, err := svc.GetSwiftCodeDetails(c.Context(), "PKOPPLPWXXX")
+			if err != nil {
+				return err
+			}
+			return c.SendString(detail.Bank.Phone)
+		}
+		app = fiber.New()
+		app.Get("/code", handler, middleware.Tiers(cfg, auth))
+	})
+
+	doRequest := func(token string) *http.Response {
+		req := httptest.NewRequest(http.MethodGet, "/code", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := app.Test(req, fiber.TestConfig{})
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	readBody := func(resp *http.Response) string {
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return string(body)
+	}
+
+	It("should limit anonymous callers to their burst and redact their fields", func() {
+		for i := 0; i < 2; i++ {
+			resp := doRequest("")
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(readBody(resp)).To(Equal(service.MaskedValue))
+		}
+		Expect(doRequest("").Header.Get("X-RateLimit-Limit")).To(Equal("60"))
+
+		resp := doRequest("")
+		Expect(resp.StatusCode).To(Equal(http.StatusTooManyRequests))
+		Expect(resp.Header.Get("Retry-After")).To(Equal("1"))
+		Expect(readBody(resp)).To(ContainSubstring("RATE_LIMITED"))
+	})
+
+	It("should key anonymous callers behind a proxy by the first untrusted hop", func() {
+		app = fiber.New(fiber.Config{
+			ProxyHeader:      fiber.HeaderXForwardedFor,
+			TrustProxy:       true,
+			TrustProxyConfig: fiber.TrustProxyConfig{Proxies: []string{"0.0.0.0"}},
+		})
+		app.Get("/code", handler, middleware.Tiers(cfg, auth))
+
+		forwarded := func(spoofed string) int {
+			req := httptest.NewRequest(http.MethodGet, "/code", nil)
+			req.Header.Set(fiber.HeaderXForwardedFor, spoofed+", 203.0.113.9")
+			resp, err := app.Test(req, fiber.TestConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			return resp.StatusCode
+		}
+		Expect(forwarded("10.0.0.1")).To(Equal(http.StatusOK))
+		Expect(forwarded("10.0.0.2")).To(Equal(http.StatusOK))
+		Expect(forwarded("10.0.0.3")).To(Equal(http.StatusTooManyRequests))
+	})
+
+	It("should give each authenticated subject its own larger quota with every field", func() {
+		alice := signToken("secret", map[string]any{"sub": "alice"})
+		for i := 0; i < 5; i++ {
+			resp := doRequest(alice)
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(readBody(resp)).To(Equal("+48 22 000 00 00"))
+		}
+		Expect(doRequest(alice).StatusCode).To(Equal(http.StatusTooManyRequests))
+		Expect(doRequest(signToken("secret", map[string]any{"sub": "bob"})).StatusCode).To(Equal(http.StatusOK))
+	})
+
+	It("should reject an invalid token rather than treat the caller as anonymous", func() {
+		Expect(doRequest(signToken("other", map[string]any{"sub": "alice"})).StatusCode).To(Equal(http.StatusUnauthorized))
+	})
+
+	Context("when disabled", func() {
+		BeforeEach(func() {
+			cfg.Enabled = false
+		})
+
+		It("should neither limit nor redact", func() {
+			for i := 0; i < 5; i++ {
+				resp := doRequest("")
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				Expect(readBody(resp)).To(Equal("+48 22 000 00 00"))
+			}
+		})
+	})
+
+	It("should apply updated quotas and hidden fields to the next request", func() {
+		limits := middleware.NewTierLimits(cfg)
+		app = fiber.New()
+		app.Get("/code", handler, middleware.TiersWith(limits, auth))
+
+		resp := doRequest("")
+		Expect(resp.Header.Get("X-RateLimit-Limit")).To(Equal("60"))
+		Expect(readBody(resp)).To(Equal(service.MaskedValue))
+
+		cfg.Anonymous.RequestsPerMinute = 30
+		cfg.Anonymous.HiddenFields = nil
+		limits.Update(cfg)
+
+		resp = doRequest("")
+		Expect(resp.Header.Get("X-RateLimit-Limit")).To(Equal("30"))
+		Expect(readBody(resp)).To(Equal("+48 22 000 00 00"))
+	})
+
+	It("should validate quotas and hidden fields", func() {
+		Expect(cfg.Validate()).To(Succeed())
+
+		cfg.Anonymous.HiddenFields = []string{"iban"}
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring(`unknown field "iban"`)))
+
+		cfg.Anonymous.HiddenFields = nil
+		cfg.Authenticated.Burst = -1
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("authenticated")))
+	})
+})
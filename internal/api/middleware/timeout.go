@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+)
+
+// TimeoutConfig bounds how long each kind of route may run. A zero
+// duration leaves the routes of that kind unbounded.
+type TimeoutConfig struct {
+	// Lookup bounds code, country and stats reads
+	Lookup time.Duration `koanf:"lookup"`
+	// Write bounds creates, upserts and deletes
+	Write time.Duration `koanf:"write"`
+	// Import bounds reloads, file validation, exports and table maintenance
+	Import time.Duration `koanf:"import"`
+	// Analytics bounds analytical query templates
+	Analytics time.Duration `koanf:"analytics"`
+	// Admin bounds the remaining admin endpoints
+	Admin time.Duration `koanf:"admin"`
+}
+
+// Validate rejects negative timeouts
+func (c TimeoutConfig) Validate() error {
+	if c.Lookup < 0 || c.Write < 0 || c.Import < 0 || c.Analytics < 0 || c.Admin < 0 {
+		return errors.New("timeouts cannot be negative")
+	}
+	return nil
+}
+
+// Timeout cancels the request context after d so queries still running
+// against Trino are abandoned. A handler that fails once the deadline has
+// passed is answered with 504 instead of its own error; a response written
+// in time is kept. A d of 0 or less disables the bound.
+func Timeout(d time.Duration) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if d <= 0 {
+			return c.Next()
+		}
+		ctx, cancel := context.WithTimeout(c.Context(), d)
+		defer cancel()
+		c.SetContext(ctx)
+
+		err := c.Next()
+		// Handlers turn the cancelled query into their own 5xx body, so
+		// the deadline is checked rather than the returned error
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) &&
+			(err != nil || c.Response().StatusCode() >= fiber.StatusInternalServerError) {
+			c.Response().ResetBody()
+			return apierror.Write(c, fiber.StatusGatewayTimeout, apierror.CodeTimeout, "Request timed out")
+		}
+		return err
+	}
+}
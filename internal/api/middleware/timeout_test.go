@@ -0,0 +1,73 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+)
+
+var _ = Describe("Timeout", func() {
+	get := func(timeout time.Duration, handler fiber.Handler) *http.Response {
+		app := fiber.New(fiber.Config{ErrorHandler: apierror.Handler})
+		app.Get("/lookup", handler, middleware.Timeout(timeout))
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/lookup", nil), fiber.TestConfig{Timeout: 5 * time.Second})
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	// slowQuery waits for the request context like a cancelled Trino query
+	// and reports the failure the way handlers do
+	slowQuery := func(c fiber.Ctx) error {
+		<-c.Context().Done()
+		return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Internal server error")
+	}
+
+	It("should answer 504 when the handler fails after the deadline", func() {
+		resp := get(20*time.Millisecond, slowQuery)
+		Expect(resp.StatusCode).To(Equal(http.StatusGatewayTimeout))
+
+		var body apierror.Error
+		Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+		Expect(body.Code).To(Equal(apierror.CodeTimeout))
+	})
+
+	It("should answer 504 when the handler returns the deadline error", func() {
+		resp := get(20*time.Millisecond, func(c fiber.Ctx) error {
+			<-c.Context().Done()
+			return c.Context().Err()
+		})
+		Expect(resp.StatusCode).To(Equal(http.StatusGatewayTimeout))
+	})
+
+	It("should keep responses written in time", func() {
+		resp := get(time.Second, func(c fiber.Ctx) error {
+			_, ok := c.Context().Deadline()
+			Expect(ok).To(BeTrue())
+			return c.SendString("ok")
+		})
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	})
+
+	It("should not bound requests when disabled", func() {
+		resp := get(0, func(c fiber.Ctx) error {
+			_, ok := c.Context().Deadline()
+			Expect(ok).To(BeFalse())
+			return c.SendString("ok")
+		})
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	})
+
+	It("should reject negative timeouts", func() {
+		Expect(middleware.TimeoutConfig{Lookup: time.Second}.Validate()).To(Succeed())
+		Expect(middleware.TimeoutConfig{Import: -time.Second}.Validate()).To(HaveOccurred())
+	})
+})
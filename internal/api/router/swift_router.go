@@ -1,40 +1,328 @@
 package router
 
 import (
+	"math"
+	"os"
+	"strings"
+	"time"
+
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/middleware/logger"
 	"github.com/gofiber/fiber/v3/middleware/recover"
+	"github.com/zdziszkee/swift-codes/internal/api/adminui"
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
+	"github.com/zdziszkee/swift-codes/internal/api/graphql"
 	handler "github.com/zdziszkee/swift-codes/internal/api/handlers"
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+	config "github.com/zdziszkee/swift-codes/internal/configurations"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
 )
 
+// Handlers groups the HTTP handlers mounted by SetupRoutes
+type Handlers struct {
+	Swift       *handler.SwiftHandler
+	GraphQL     *graphql.Handler
+	Admin       *handler.AdminHandler
+	Reload      *handler.ReloadHandler
+	Stats       *handler.StatsHandler
+	Maintenance *handler.MaintenanceHandler
+	Queries     *handler.QueryHandler
+	Webhooks    *handler.WebhookHandler
+	Metrics     *handler.MetricsHandler
+	Events      *handler.EventsHandler
+	Export      *handler.ExportHandler
+	Audit       *handler.AuditHandler
+	Schema      *handler.SchemaHandler
+	// Aliases is set when the alias table is enabled
+	Aliases *handler.AliasHandler
+	// Failover is set when a secondary Trino cluster is configured
+	Failover *handler.FailoverHandler
+	// Config reloads the configuration at runtime
+	Config *handler.ConfigHandler
+	// Cache is set when the repository cache is enabled
+	Cache *handler.CacheHandler
+	// Windows manages the maintenance windows during which writes are
+	// refused
+	Windows *handler.MaintenanceScheduleHandler
+	// TierLimits, when set, holds the tier quotas in force so that a
+	// configuration reload can change them
+	TierLimits *middleware.TierLimits
+	// Datasets is set when several datasets are configured; requests then
+	// pick one with ?dataset= or X-Dataset
+	Datasets *handler.DatasetHandler
+	// LastModified reports when the dataset last changed; when set, reads
+	// answer If-Modified-Since with 304
+	LastModified func() time.Time
+	// CountryVersion returns the change counter of a country; when set,
+	// country listings carry it as their ETag and in meta, and answer
+	// If-None-Match with 304
+	CountryVersion func(country string) uint64
+	// Allowlist restricts write and admin endpoints to client networks;
+	// nil allows every client
+	Allowlist *middleware.IPAllowlist
+	// Scraping, when set, watches code lookups for enumeration
+	Scraping *middleware.ScrapeGuard
+}
+
 // SetupRoutes configures all API routes
-func SetupRoutes(swiftHandler *handler.SwiftHandler) *fiber.App {
-	app := fiber.New(fiber.Config{
-		ErrorHandler: func(c fiber.Ctx, err error) error {
-			// Default error handler
-			code := fiber.StatusInternalServerError
-
-			if e, ok := err.(*fiber.Error); ok {
-				code = e.Code
-			}
-
-			return c.Status(code).JSON(fiber.Map{
-				"message": "Internal server error",
-			})
-		},
-	})
+func SetupRoutes(handlers Handlers, cfg *config.Config) *fiber.App {
+	appConfig := fiber.Config{
+		// Unmatched routes and methods end here too, so every error shares
+		// the apierror body
+		ErrorHandler: apierror.Handler,
+		// Clients often get the casing or a trailing slash wrong; match
+		// /V1/SwiftCodes/abc/ like /v1/swiftCodes/abc
+		CaseSensitive: false,
+		StrictRouting: false,
+	}
+	// Behind a proxy the allowlist, the anonymous tier and the scraping
+	// guard check the forwarded client address, trusted only from the
+	// configured proxies; middleware.ClientIP picks it out of the header
+	if cfg.Server.ProxyHeader != "" {
+		appConfig.ProxyHeader = cfg.Server.ProxyHeader
+		appConfig.TrustProxy = true
+		appConfig.TrustProxyConfig = fiber.TrustProxyConfig{Proxies: cfg.Server.TrustedProxies}
+		appConfig.EnableIPValidation = true
+	}
+	app := fiber.New(appConfig)
+
+	// Liveness probe; registered ahead of the global middleware so probes
+	// skip request IDs and access logging
+	app.Get("/ping", handler.Ping)
 
 	// Add global middleware
-	app.Use(logger.New())
+	app.Use(middleware.CanonicalPath(app))
+	app.Use(middleware.RequestID())
+	// With log.format = "json" every request is logged as an AccessRecord,
+	// query string included, which the replay command reads back
+	if strings.EqualFold(cfg.Log.Format, "json") {
+		app.Use(middleware.JSONAccessLog(os.Stdout))
+	} else {
+		app.Use(logger.New(logger.Config{
+			Format: "[${time}] ${ip} ${status} - ${latency} ${method} ${path} ${respHeader:X-Request-ID} ${error}\n",
+		}))
+	}
 	app.Use(recover.New())
+	if cfg.API.ServerTiming {
+		app.Use(middleware.ServerTiming())
+	}
+
+	// API versioning; every versioned and GraphQL request counts against
+	// the quota of its caller's tier. Versioned JSON responses, rejections
+	// included, may be wrapped in {data, meta, errors}; GraphQL has its own.
+	tiers := middleware.Tiers(cfg.Tiers, cfg.Auth)
+	if handlers.TierLimits != nil {
+		tiers = middleware.TiersWith(handlers.TierLimits, cfg.Auth)
+	}
+	envelope := handler.Envelope(cfg.API, cfg.Auth, cfg.Attribution)
+	v1 := app.Group("/v1", envelope, tiers)
+	v2 := app.Group("/v2", envelope, tiers)
+	if handlers.Datasets != nil {
+		selectDataset := middleware.SelectDataset(handlers.Datasets.Router())
+		v1.Use(selectDataset)
+		v2.Use(selectDataset)
+	}
+
+	// Write operations require a writer or admin token when auth is enabled
+	// and a client in the allowlist when one is configured
+	requireWriter := allowlisted(handlers.Allowlist, middleware.RequireRole(cfg.Auth, middleware.RoleWriter, middleware.RoleAdmin))
+	requireAdmin := allowlisted(handlers.Allowlist, middleware.RequireRole(cfg.Auth, middleware.RoleAdmin))
+	limitBody := middleware.BodyLimit(cfg.API.MaxWriteBodyBytes)
+	idempotent := middleware.Idempotency(cfg.Idempotency, middleware.NewMemoryIdempotencyStore())
+
+	// Dataset reads can be revalidated with If-Modified-Since
+	conditional := func(c fiber.Ctx) error { return c.Next() }
+	if handlers.LastModified != nil {
+		conditional = middleware.LastModified(handlers.LastModified)
+	}
+
+	// Country listings can also be revalidated with If-None-Match
+	countryETag := func(c fiber.Ctx) error { return c.Next() }
+	if handlers.CountryVersion != nil {
+		countryETag = middleware.CountryETag("countryISO2code", handlers.CountryVersion)
+	}
+
+	// Reference data reads may be cached by browsers, CDNs and proxies for
+	// the configured lifetimes
+	vary := []string{fiber.HeaderAccept}
+	if handlers.Datasets != nil {
+		vary = append(vary, middleware.DatasetHeader)
+	}
+	// The caller's key picks the envelope and, with tiers, the fields
+	if cfg.Auth.Enabled {
+		vary = append(vary, fiber.HeaderAuthorization)
+	}
+	cacheCodes := middleware.CacheControl(cfg.CacheControl.Codes, vary...)
+	cacheCountries := middleware.CacheControl(cfg.CacheControl.Countries, vary...)
+
+	// Query parameters are checked up front so a request with several bad
+	// values learns about all of them at once
+	limit := middleware.IntParam("limit", 1, cfg.API.PageSizeLimit())
+	offset := middleware.IntParam("offset", 0, math.MaxInt)
+	format := middleware.OneOfParam("format", handler.FormatJSON, handler.FormatCSV, handler.FormatXML, handler.FormatProtobuf)
+	fields := middleware.QueryParam{Name: "fields", Check: func(v string) error {
+		_, err := handler.ParseFieldMask(v)
+		return err
+	}}
+	sortBy := middleware.QueryParam{Name: "sort", Check: func(v string) error {
+		_, err := repository.ParseSort(v)
+		return err
+	}}
+	bankType := middleware.QueryParam{Name: "type", Check: func(v string) error {
+		_, err := repository.ParseBankType(v)
+		return err
+	}}
+	after := middleware.QueryParam{Name: "after", Check: func(v string) error {
+		_, err := repository.ParseCursor(v)
+		return err
+	}}
+	detailQuery := middleware.ValidateQuery(format, fields,
+		middleware.IntParam("branchLimit", 1, cfg.API.EmbeddedBranchLimit()), middleware.IntParam("branchOffset", 0, math.MaxInt))
+	branchesQuery := middleware.ValidateQuery(limit, offset, format, fields)
+	countryQuery := middleware.ValidateQuery(limit, offset, after, sortBy, bankType, format, fields, middleware.BoolParam("envelope"))
+	writeQuery := middleware.ValidateQuery(middleware.BoolParam("dryRun"))
 
-	// API versioning
-	v1 := app.Group("/v1")
+	// Every route gets the timeout of its kind so runaway Trino queries are
+	// abandoned; the event stream and watches stay unbounded
+	lookup := middleware.Timeout(cfg.Timeouts.Lookup)
+	write := middleware.Timeout(cfg.Timeouts.Write)
+	longRunning := middleware.Timeout(cfg.Timeouts.Import)
+	adminTimeout := middleware.Timeout(cfg.Timeouts.Admin)
+
+	// Code lookups feed the scraping heuristics, which may refuse a client
+	// enumerating the dataset
+	scraping := func(c fiber.Ctx) error { return c.Next() }
+	if handlers.Scraping != nil {
+		scraping = handlers.Scraping.Handler()
+	}
 
 	// SWIFT codes endpoints
-	v1.Get("/swiftCodes/:swiftCode", swiftHandler.GetByCode)
-	v1.Get("/swiftCodes/country/:countryISO2code", swiftHandler.GetByCountry)
-	v1.Post("/swiftCodes", swiftHandler.Create)
-	v1.Delete("/swiftCodes/:swiftCode", swiftHandler.Delete)
+	if handlers.Export != nil {
+		v1.Get("/swiftCodes/export/latest", handlers.Export.Latest, lookup)
+	}
+	if handlers.Events != nil {
+		watchQuery := middleware.ValidateQuery(middleware.QueryParam{Name: "since", Check: handler.CheckWatchCursor},
+			middleware.IntParam("wait", 0, handler.MaxWatchWait))
+		v1.Get("/swiftCodes/watch", handlers.Events.Watch, watchQuery)
+	}
+	v1.Get("/swiftCodes", handlers.Swift.GetByCodes, lookup, scraping, cacheCodes, conditional)
+	v1.Get("/swiftCodes/:swiftCode", handlers.Swift.GetByCode, lookup, scraping, detailQuery, cacheCodes, conditional)
+	v1.Get("/swiftCodes/:swiftCode/validate", handlers.Swift.Validate, lookup)
+	v1.Post("/validate/file", handlers.Swift.ValidateFile, longRunning, scraping)
+	v1.Get("/swiftCodes/:swiftCode/branches", handlers.Swift.GetBranches, lookup, branchesQuery, cacheCodes, conditional)
+	v1.Get("/swiftCodes/:swiftCode/headquarters", handlers.Swift.GetHeadquarters, lookup, scraping, middleware.ValidateQuery(format, fields), cacheCodes, conditional)
+	v1.Get("/swiftBases/:base", handlers.Swift.GetBankGroup, lookup, scraping, middleware.ValidateQuery(format, fields), cacheCodes, conditional)
+	v1.Get("/swiftCodes/country/:countryISO2code", handlers.Swift.GetByCountry, lookup, countryQuery, cacheCountries, conditional, countryETag)
+	v1.Get("/countries/:iso2", handlers.Swift.GetCountry, lookup, cacheCountries)
+	v1.Get("/dataset/status", handlers.Swift.DatasetStatus, lookup)
+	if handlers.Events != nil {
+		v1.Get("/events", handlers.Events.Stream)
+	}
+	if handlers.Stats != nil {
+		v1.Get("/stats", handlers.Stats.Stats, lookup)
+		v1.Get("/stats/completeness", handlers.Stats.Completeness, lookup)
+		v1.Get("/stats/countries", handlers.Stats.Countries, lookup)
+	}
+	v1.Post("/swiftCodes", handlers.Swift.Create, write, writeQuery, requireWriter, limitBody, idempotent)
+	v1.Put("/swiftCodes/:swiftCode", handlers.Swift.Put, write, writeQuery, requireWriter, limitBody, idempotent)
+	v1.Delete("/swiftCodes/:swiftCode", handlers.Swift.Delete, write, writeQuery, requireWriter)
+	if handlers.Aliases != nil {
+		v1.Get("/swiftCodes/:swiftCode/aliases", handlers.Aliases.List, lookup)
+		v1.Post("/swiftCodes/:swiftCode/aliases", handlers.Aliases.Add, write, writeQuery, requireWriter, limitBody)
+		v1.Delete("/swiftCodes/:swiftCode/aliases/:alias", handlers.Aliases.Delete, write, writeQuery, requireWriter)
+	}
+
+	// Analytical query templates; analysts pick a template, never SQL
+	if handlers.Queries != nil {
+		requireAnalyst := middleware.RequireRole(cfg.Auth, middleware.RoleAnalyst, middleware.RoleAdmin)
+		analytics := v1.Group("/analytics", middleware.Timeout(cfg.Timeouts.Analytics), requireAnalyst)
+		analytics.Get("/templates", handlers.Queries.Templates)
+		analytics.Get("/templates/:template", handlers.Queries.Run)
+	}
+
+	// v2 uses camelCase payloads; v1 stays unchanged for existing clients
+	v2.Get("/swiftCodes/:swiftCode", handlers.Swift.GetByCodeV2, lookup, scraping, cacheCodes, conditional)
+	v2.Get("/swiftCodes/:swiftCode/branches", handlers.Swift.GetBranchesV2, lookup, middleware.ValidateQuery(limit, offset), cacheCodes, conditional)
+	v2.Get("/swiftCodes/country/:countryISO2code", handlers.Swift.GetByCountryV2, lookup, middleware.ValidateQuery(limit, offset, after, sortBy, bankType), cacheCountries, conditional, countryETag)
+	v2.Post("/swiftCodes", handlers.Swift.CreateV2, write, writeQuery, requireWriter, limitBody, idempotent)
+	v2.Put("/swiftCodes/:swiftCode", handlers.Swift.PutV2, write, writeQuery, requireWriter, limitBody, idempotent)
+	v2.Delete("/swiftCodes/:swiftCode", handlers.Swift.Delete, write, writeQuery, requireWriter)
+
+	// Admin endpoints; loads, exports and maintenance get the import
+	// timeout since a group-wide one could only shorten theirs
+	admin := v1.Group("/admin", requireAdmin)
+	admin.Get("/queries", handlers.Admin.InflightQueries, adminTimeout)
+	admin.Get("/repository/metrics", handlers.Admin.RepositoryMetrics, adminTimeout)
+	admin.Get("/stats/access", handlers.Admin.AccessStats, adminTimeout, middleware.ValidateQuery(middleware.IntParam("limit", 1, math.MaxInt)))
+	admin.Delete("/swiftCodes/country/:countryISO2code", handlers.Swift.DeleteByCountry, write, writeQuery)
+	if handlers.Reload != nil {
+		admin.Post("/reload", handlers.Reload.Reload, longRunning)
+		admin.Get("/imports", handlers.Reload.History, adminTimeout)
+	}
+	if handlers.Maintenance != nil {
+		admin.Post("/maintenance/:task", handlers.Maintenance.Run, longRunning)
+	}
+	if handlers.Windows != nil {
+		admin.Get("/maintenance-windows", handlers.Windows.List, adminTimeout)
+		admin.Post("/maintenance-windows", handlers.Windows.Schedule, adminTimeout, limitBody)
+		admin.Delete("/maintenance-windows/:id", handlers.Windows.Cancel, adminTimeout)
+	}
+	if handlers.Schema != nil {
+		admin.Get("/schema", handlers.Schema.Status, adminTimeout)
+	}
+	if handlers.Export != nil {
+		admin.Post("/export", handlers.Export.Publish, longRunning)
+	}
+	if handlers.Audit != nil {
+		admin.Get("/audit", handlers.Audit.List, adminTimeout)
+		v1.Get("/swiftCodes/:swiftCode/changes", handlers.Audit.Changes, adminTimeout, requireAdmin)
+	}
+	if handlers.Config != nil {
+		admin.Post("/config/reload", handlers.Config.Reload, adminTimeout)
+	}
+	if handlers.Cache != nil {
+		admin.Post("/cache/warm", handlers.Cache.Warm, longRunning, limitBody)
+	}
+	if handlers.Failover != nil {
+		admin.Get("/failover", handlers.Failover.Status, adminTimeout)
+		admin.Put("/failover/write", handlers.Failover.SetWriteCluster, adminTimeout, limitBody)
+	}
+	if handlers.Datasets != nil {
+		admin.Get("/datasets", handlers.Datasets.List, adminTimeout)
+		admin.Put("/datasets/default", handlers.Datasets.SetDefault, adminTimeout, limitBody)
+	}
+	if handlers.Webhooks != nil {
+		admin.Post("/webhooks", handlers.Webhooks.Create, adminTimeout, limitBody)
+		admin.Get("/webhooks", handlers.Webhooks.List, adminTimeout)
+		admin.Delete("/webhooks/:id", handlers.Webhooks.Delete, adminTimeout)
+	}
+
+	// Prometheus scrape target; it exposes import health, scraping detection
+	// and cache counters
+	if handlers.Metrics != nil {
+		app.Get("/metrics", handlers.Metrics.Metrics)
+	}
+
+	// The admin page is static; its API calls go through requireAdmin
+	if cfg.API.AdminUI {
+		app.Get("/admin/ui", adminui.Index)
+		app.Get("/admin/ui/:file", adminui.Asset)
+	}
+
+	// GraphQL endpoint; mutations are authorized inside the handler. One
+	// document may hold several lookups and mutations, so it runs under
+	// the write timeout and its body is capped like the write endpoints.
+	app.Get("/graphql", handlers.GraphQL.Serve, tiers, write, scraping)
+	app.Post("/graphql", handlers.GraphQL.Serve, tiers, write, limitBody, scraping)
 	return app
 }
+
+// allowlisted runs next only for clients in list
+func allowlisted(list *middleware.IPAllowlist, next fiber.Handler) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if err := middleware.AllowIP(list, c); err != nil {
+			return middleware.RespondIPError(c)
+		}
+		return next(c)
+	}
+}
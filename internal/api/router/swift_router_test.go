@@ -14,6 +14,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	// Import the handlers package for creating a new handler.
+	"github.com/zdziszkee/swift-codes/internal/api/apierror"
 	handlers "github.com/zdziszkee/swift-codes/internal/api/handlers"
 	models "github.com/zdziszkee/swift-codes/internal/models"
 	repository "github.com/zdziszkee/swift-codes/internal/repositories"
@@ -88,10 +89,11 @@ var _ = Describe("Swift Router", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
 
-				var body map[string]string
+				var body apierror.Error
 				err = json.NewDecoder(resp.Body).Decode(&body)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(body["message"]).To(Equal("SWIFT code not found"))
+				Expect(body.Code).To(Equal(apierror.CodeNotFound))
+				Expect(body.Message).To(Equal("SWIFT code not found"))
 			})
 		})
 	})
@@ -99,7 +101,7 @@ var _ = Describe("Swift Router", func() {
 	Describe("GET /country/:countryISO2code", func() {
 		Context("when the country has swift codes", func() {
 			It("should return status 200 and the swift codes list", func() {
-				mockSvc.GetSwiftCodesByCountryFunc = func(ctx context.Context, countryCode string) (*repository.CountrySwiftCodes, error) {
+				mockSvc.GetSwiftCodesByCountryFunc = func(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
 					return &repository.CountrySwiftCodes{
 						CountryISO2: strings.ToUpper(countryCode),
 						CountryName: "Test Country",
@@ -193,10 +195,11 @@ var _ = Describe("Swift Router", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
 
-				var body map[string]string
+				var body apierror.Error
 				err = json.NewDecoder(resp.Body).Decode(&body)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(body["message"]).To(Equal("SWIFT code not found"))
+				Expect(body.Code).To(Equal(apierror.CodeNotFound))
+				Expect(body.Message).To(Equal("SWIFT code not found"))
 			})
 		})
 
@@ -211,10 +214,11 @@ var _ = Describe("Swift Router", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
 
-				var body map[string]string
+				var body apierror.Error
 				err = json.NewDecoder(resp.Body).Decode(&body)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(body["message"]).To(Equal("Invalid input provided"))
+				Expect(body.Code).To(Equal(apierror.CodeInvalidInput))
+				Expect(body.Message).To(Equal("Invalid input provided"))
 			})
 		})
 	})
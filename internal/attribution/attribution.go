@@ -0,0 +1,75 @@
+// Package attribution holds the licensing notice that must travel with
+// redistributed BIC directory data. Export snapshots carry it as comment
+// lines at the top of the file, and enveloped API responses can carry it in
+// their meta.
+package attribution
+
+import (
+	"errors"
+	"strings"
+)
+
+// Config holds the licensing and attribution obligations of the data
+type Config struct {
+	// Notice is the attribution text required by the data provider, e.g.
+	// "Contains BIC directory data © SWIFT"
+	Notice string `koanf:"notice"`
+	// License names the licence the data is distributed under
+	License string `koanf:"license"`
+	// URL points at the licence terms
+	URL string `koanf:"url"`
+	// InResponses adds the attribution to the meta of enveloped API responses
+	InResponses bool `koanf:"in_responses"`
+}
+
+// Attribution is the licensing metadata attached to exported data
+type Attribution struct {
+	Notice  string `json:"notice,omitempty"`
+	License string `json:"license,omitempty"`
+	URL     string `json:"url,omitempty"`
+}
+
+// Validate checks that responses are only asked to carry a configured
+// attribution
+func (c Config) Validate() error {
+	if c.InResponses && c.Attribution() == nil {
+		return errors.New("attribution in_responses needs a notice, license or url")
+	}
+	return nil
+}
+
+// Attribution returns the configured metadata, or nil when none is set
+func (c Config) Attribution() *Attribution {
+	attr := Attribution{
+		Notice:  strings.TrimSpace(c.Notice),
+		License: strings.TrimSpace(c.License),
+		URL:     strings.TrimSpace(c.URL),
+	}
+	if attr == (Attribution{}) {
+		return nil
+	}
+	return &attr
+}
+
+// CommentLines returns the attribution as "# "-prefixed lines for the top
+// of an export file, one per line of the notice followed by the licence and
+// its URL. It returns nil when nothing is configured.
+func (c Config) CommentLines() []string {
+	attr := c.Attribution()
+	if attr == nil {
+		return nil
+	}
+	var lines []string
+	if attr.Notice != "" {
+		for _, line := range strings.Split(attr.Notice, "\n") {
+			lines = append(lines, strings.TrimRight("# "+strings.TrimSpace(line), " "))
+		}
+	}
+	if attr.License != "" {
+		lines = append(lines, "# License: "+attr.License)
+	}
+	if attr.URL != "" {
+		lines = append(lines, "# License URL: "+attr.URL)
+	}
+	return lines
+}
@@ -0,0 +1,69 @@
+// Package audit records who changed which SWIFT codes, when, and what the
+// records looked like before and after, in an audit table next to the data.
+package audit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/zdziszkee/swift-codes/internal/correlation"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+)
+
+// Actions recorded in the audit log
+const (
+	ActionCreate          = "create"
+	ActionUpdate          = "update"
+	ActionDelete          = "delete"
+	ActionDeleteByCountry = "delete_by_country"
+)
+
+// Anonymous is the actor of writes made without an authenticated token
+const Anonymous = "anonymous"
+
+// Config holds the audit log settings
+type Config struct {
+	// Enabled records API writes and serves GET /v1/admin/audit
+	Enabled bool `koanf:"enabled"`
+	// Table is the audit table in the database catalog and schema
+	Table string `koanf:"table"`
+}
+
+// Entry is a single recorded mutation
+type Entry struct {
+	ID          string    `json:"id"`
+	OccurredAt  time.Time `json:"occurredAt"`
+	Actor       string    `json:"actor"`
+	Action      string    `json:"action"`
+	SwiftCode   string    `json:"swiftCode,omitempty"`
+	CountryISO2 string    `json:"countryISO2,omitempty"`
+	// Affected is the number of codes the mutation changed
+	Affected  int64             `json:"affected"`
+	Before    *models.SwiftBank `json:"before,omitempty"`
+	After     *models.SwiftBank `json:"after,omitempty"`
+	RequestID string            `json:"requestId,omitempty"`
+}
+
+// Filter selects entries for List. Zero fields do not filter.
+type Filter struct {
+	SwiftCode string
+	From      time.Time
+	To        time.Time
+	Limit     int
+}
+
+// ActorFromContext returns the actor of the request, or Anonymous
+func ActorFromContext(ctx context.Context) string {
+	if actor := correlation.Actor(ctx); actor != "" {
+		return actor
+	}
+	return Anonymous
+}
+
+func newID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,200 @@
+package audit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/audit"
+	"github.com/zdziszkee/swift-codes/internal/correlation"
+	"github.com/zdziszkee/swift-codes/internal/database"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+func TestAudit(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Audit Suite")
+}
+
+var auditColumns = []string{"id", "occurred_at", "actor", "action", "swift_code", "country_iso_code", "affected", "before_state", "after_state", "request_id"}
+
+var _ = Describe("Log", func() {
+	var (
+		mockDB sqlmock.Sqlmock
+		log    *audit.Log
+	)
+
+	BeforeEach(func() {
+		db, mock, err := sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() { _ = db.Close() })
+		mockDB = mock
+		log = audit.NewLog(&database.Database{DB: db, Config: database.Config{
+			Catalog: "swift_catalog", Schema: "default_schema",
+		}}, "swift_audit_log")
+	})
+
+	It("should store entries with JSON snapshots", func() {
+		mockDB.ExpectExec(`INSERT INTO swift_catalog\.default_schema\.swift_audit_log .* VALUES \(\?, from_iso8601_timestamp\(\?\)`).
+			WithArgs("id-1", "2025-03-01T12:00:00Z", "alice", audit.ActionCreate, "PKOPPLPWXXX", "PL", int64(1),
+				nil, sqlmock.AnyArg(), "req-1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		Expect(log.Record(context.Background(), audit.Entry{
+			ID: "id-1", OccurredAt: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC), Actor: "alice",
+			Action: audit.ActionCreate, SwiftCode: "PKOPPLPWXXX", CountryISO2: "PL", Affected: 1,
+			After: &models.SwiftBank{SwiftCode: "PKOPPLPWXXX"}, RequestID: "req-1",
+		})).To(Succeed())
+		Expect(mockDB.ExpectationsWereMet()).To(Succeed())
+	})
+
+	It("should filter by code and time range, newest first", func() {
+		mockDB.ExpectQuery(`FROM swift_catalog\.default_schema\.swift_audit_log WHERE swift_code = \? AND occurred_at >= from_iso8601_timestamp\(\?\) AND occurred_at < from_iso8601_timestamp\(\?\) ORDER BY occurred_at DESC, id LIMIT 10`).
+			WithArgs("PKOPPLPWXXX", "2025-03-01T00:00:00Z", "2025-04-01T00:00:00Z").
+			WillReturnRows(sqlmock.NewRows(auditColumns).AddRow(
+				"id-1", "2025-03-01T12:00:00.000Z", "alice", audit.ActionDelete, "PKOPPLPWXXX", "PL", int64(1),
+				`{"SwiftCode":"PKOPPLPWXXX","BankName":"PKO BP"}`, nil, "req-1",
+			))
+
+		entries, err := log.List(context.Background(), audit.Filter{
+			SwiftCode: "pkopplpwxxx",
+			From:      time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+			To:        time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+			Limit:     10,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].OccurredAt).To(Equal(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)))
+		Expect(entries[0].Before).To(Equal(&models.SwiftBank{SwiftCode: "PKOPPLPWXXX", BankName: "PKO BP"}))
+		Expect(entries[0].After).To(BeNil())
+		Expect(mockDB.ExpectationsWereMet()).To(Succeed())
+	})
+})
+
+type recorder struct {
+	entries []audit.Entry
+	err     error
+}
+
+func (r *recorder) Record(ctx context.Context, entry audit.Entry) error {
+	r.entries = append(r.entries, entry)
+	return r.err
+}
+
+var _ = Describe("WithAudit", func() {
+	var (
+		ctx   context.Context
+		rec   *recorder
+		inner *mocks.MockSwiftService
+		svc   service.SwiftService
+	)
+
+	BeforeEach(func() {
+		ctx = correlation.WithActor(requestid.NewContext(context.Background(), "req-1"), "alice")
+		rec = &recorder{}
+		inner = &mocks.MockSwiftService{
+			CreateSwiftCodeFunc: func(ctx context.Context, bank *models.SwiftBank) error { return nil },
+			GetSwiftCodeDetailsFunc: func(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+				return &repository.SwiftBankDetail{Bank: models.SwiftBank{SwiftCode: code, CountryISOCode: "PL", BankName: "PKO BP"}}, nil
+			},
+			DeleteSwiftCodeFunc: func(ctx context.Context, code string) error { return nil },
+			DeleteByCountryFunc: func(ctx context.Context, countryCode string) (int64, error) { return 3, nil },
+		}
+		svc = audit.WithAudit(inner, rec)
+	})
+
+	It("should record creates with the actor and the new record", func() {
+		Expect(svc.CreateSwiftCode(ctx, &models.SwiftBank{SwiftCode: "pkopplpwxxx", CountryISOCode: "pl"})).To(Succeed())
+		Expect(rec.entries).To(HaveLen(1))
+		entry := rec.entries[0]
+		Expect(entry.ID).NotTo(BeEmpty())
+		Expect(entry.OccurredAt).To(BeTemporally("~", time.Now(), time.Second))
+		Expect(entry.Actor).To(Equal("alice"))
+		Expect(entry.Action).To(Equal(audit.ActionCreate))
+		Expect(entry.SwiftCode).To(Equal("PKOPPLPWXXX"))
+		Expect(entry.CountryISO2).To(Equal("PL"))
+		Expect(entry.RequestID).To(Equal("req-1"))
+		Expect(entry.After.SwiftCode).To(Equal("pkopplpwxxx"))
+		Expect(entry.Before).To(BeNil())
+	})
+
+	It("should record deletes with the removed record", func() {
+		Expect(svc.DeleteSwiftCode(ctx, "PKOPPLPWXXX")).To(Succeed())
+		Expect(rec.entries).To(HaveLen(1))
+		Expect(rec.entries[0].Action).To(Equal(audit.ActionDelete))
+		Expect(rec.entries[0].CountryISO2).To(Equal("PL"))
+		Expect(rec.entries[0].Before.BankName).To(Equal("PKO BP"))
+
+		_, err := svc.DeleteSwiftCodesByCountry(ctx, "pl")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rec.entries[1].Action).To(Equal(audit.ActionDeleteByCountry))
+		Expect(rec.entries[1].CountryISO2).To(Equal("PL"))
+		Expect(rec.entries[1].Affected).To(Equal(int64(3)))
+	})
+
+	It("should skip dry runs and failed writes and default the actor", func() {
+		Expect(svc.DeleteSwiftCode(service.WithDryRun(ctx), "PKOPPLPWXXX")).To(Succeed())
+		inner.CreateSwiftCodeFunc = func(ctx context.Context, bank *models.SwiftBank) error { return service.ErrAlreadyExists }
+		Expect(svc.CreateSwiftCode(ctx, &models.SwiftBank{SwiftCode: "PKOPPLPWXXX"})).To(MatchError(service.ErrAlreadyExists))
+		Expect(rec.entries).To(BeEmpty())
+
+		Expect(svc.DeleteSwiftCode(context.Background(), "PKOPPLPWXXX")).To(Succeed())
+		Expect(rec.entries[0].Actor).To(Equal(audit.Anonymous))
+	})
+
+	It("should record replacements as updates with both records", func() {
+		upsert := service.WithWriteMode(ctx, service.WriteUpsert)
+		Expect(svc.CreateSwiftCode(upsert, &models.SwiftBank{SwiftCode: "PKOPPLPWXXX", CountryISOCode: "PL", BankName: "PKO BANK POLSKI"})).To(Succeed())
+		Expect(rec.entries).To(HaveLen(1))
+		Expect(rec.entries[0].Action).To(Equal(audit.ActionUpdate))
+		Expect(rec.entries[0].Before.BankName).To(Equal("PKO BP"))
+		Expect(rec.entries[0].After.BankName).To(Equal("PKO BANK POLSKI"))
+
+		inner.GetSwiftCodeDetailsFunc = func(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+			return nil, service.ErrNotFound
+		}
+		Expect(svc.CreateSwiftCode(upsert, &models.SwiftBank{SwiftCode: "PKOPPLPW123", CountryISOCode: "PL"})).To(Succeed())
+		Expect(rec.entries[1].Action).To(Equal(audit.ActionCreate))
+		Expect(rec.entries[1].Before).To(BeNil())
+	})
+
+	It("should keep the write when recording fails", func() {
+		rec.err = errors.New("trino down")
+		Expect(svc.DeleteSwiftCode(ctx, "PKOPPLPWXXX")).To(Succeed())
+	})
+})
+
+var _ = Describe("FieldChanges", func() {
+	created := audit.Entry{Actor: "alice", Action: audit.ActionCreate,
+		After: &models.SwiftBank{SwiftCode: "PKOPPLPWXXX", BankName: "PKO BP", IsHeadquarter: true}}
+	updated := audit.Entry{Actor: "bob", Action: audit.ActionUpdate,
+		Before: &models.SwiftBank{SwiftCode: "PKOPPLPWXXX", BankName: "PKO BP", Address: "ZUBRA 1"},
+		After:  &models.SwiftBank{SwiftCode: "PKOPPLPWXXX", BankName: "PKO BP", Address: "ZUBRA 2", Phone: "+48 22"}}
+
+	It("should list every differing field of each entry", func() {
+		changes := audit.FieldChanges([]audit.Entry{updated, created, {Action: audit.ActionDeleteByCountry}}, "")
+		Expect(changes).To(Equal([]audit.FieldChange{
+			{Field: "Address", Old: "ZUBRA 1", New: "ZUBRA 2", Actor: "bob", Action: audit.ActionUpdate},
+			{Field: "Phone", Old: "", New: "+48 22", Actor: "bob", Action: audit.ActionUpdate},
+			{Field: "SwiftCode", Old: "", New: "PKOPPLPWXXX", Actor: "alice", Action: audit.ActionCreate},
+			{Field: "BankName", Old: "", New: "PKO BP", Actor: "alice", Action: audit.ActionCreate},
+		}))
+	})
+
+	It("should keep a single field, ignoring case", func() {
+		changes := audit.FieldChanges([]audit.Entry{updated, created}, "bankname")
+		Expect(changes).To(HaveLen(1))
+		Expect(changes[0].Actor).To(Equal("alice"))
+		Expect(audit.ValidField("ADDRESS")).To(BeTrue())
+		Expect(audit.ValidField("rating")).To(BeFalse())
+	})
+})
@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	models "github.com/zdziszkee/swift-codes/internal/models"
+)
+
+// FieldChange is one field of one SWIFT code changed by a recorded write.
+// A created field has an empty Old value and a deleted one an empty New
+// value.
+type FieldChange struct {
+	Field      string    `json:"field"`
+	Old        string    `json:"old"`
+	New        string    `json:"new"`
+	Actor      string    `json:"actor"`
+	Action     string    `json:"action"`
+	OccurredAt time.Time `json:"occurredAt"`
+	RequestID  string    `json:"requestId,omitempty"`
+}
+
+// bankFields lists the fields compared by FieldChanges, named as in the
+// audit snapshots
+var bankFields = []struct {
+	name  string
+	value func(b *models.SwiftBank) string
+}{
+	{"SwiftCode", func(b *models.SwiftBank) string { return b.SwiftCode }},
+	{"SwiftCodeBase", func(b *models.SwiftBank) string { return b.SwiftCodeBase }},
+	{"CountryISOCode", func(b *models.SwiftBank) string { return b.CountryISOCode }},
+	{"BankName", func(b *models.SwiftBank) string { return b.BankName }},
+	{"IsHeadquarter", func(b *models.SwiftBank) string { return strconv.FormatBool(b.IsHeadquarter) }},
+	{"Address", func(b *models.SwiftBank) string { return b.Address }},
+	{"CountryName", func(b *models.SwiftBank) string { return b.CountryName }},
+	{"Town", func(b *models.SwiftBank) string { return b.Town }},
+	{"TimeZone", func(b *models.SwiftBank) string { return b.TimeZone }},
+	{"Website", func(b *models.SwiftBank) string { return b.Website }},
+	{"Phone", func(b *models.SwiftBank) string { return b.Phone }},
+}
+
+// ValidField reports whether FieldChanges knows field, ignoring case
+func ValidField(field string) bool {
+	for _, f := range bankFields {
+		if strings.EqualFold(f.name, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldChanges compares the snapshots of every entry and returns the
+// fields that differ, in the order of entries. field, when not empty,
+// keeps only that field. Entries without snapshots, such as country
+// deletes, yield nothing.
+func FieldChanges(entries []Entry, field string) []FieldChange {
+	changes := []FieldChange{}
+	for _, entry := range entries {
+		if entry.Before == nil && entry.After == nil {
+			continue
+		}
+		before, after := entry.Before, entry.After
+		if before == nil {
+			before = &models.SwiftBank{}
+		}
+		if after == nil {
+			after = &models.SwiftBank{}
+		}
+		for _, f := range bankFields {
+			if field != "" && !strings.EqualFold(f.name, field) {
+				continue
+			}
+			// An absent snapshot has no headquarters flag to compare
+			if f.name == "IsHeadquarter" && (entry.Before == nil || entry.After == nil) {
+				continue
+			}
+			old, updated := f.value(before), f.value(after)
+			if old == updated {
+				continue
+			}
+			changes = append(changes, FieldChange{
+				Field:      f.name,
+				Old:        old,
+				New:        updated,
+				Actor:      entry.Actor,
+				Action:     entry.Action,
+				OccurredAt: entry.OccurredAt,
+				RequestID:  entry.RequestID,
+			})
+		}
+	}
+	return changes
+}
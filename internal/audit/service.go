@@ -0,0 +1,107 @@
+package audit
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+// Recorder stores audit entries; *Log implements it
+type Recorder interface {
+	Record(ctx context.Context, entry Entry) error
+}
+
+// auditedService records the successful writes of the wrapped service
+type auditedService struct {
+	service.SwiftService
+	recorder Recorder
+	now      func() time.Time
+}
+
+// WithAudit wraps svc so that every stored create, replacement and delete
+// is recorded with the actor from the context and the record before and
+// after the change. Dry runs and failed writes are not recorded. A failure to record
+// is logged but does not fail the write, which is already applied.
+func WithAudit(svc service.SwiftService, recorder Recorder) service.SwiftService {
+	return &auditedService{SwiftService: svc, recorder: recorder, now: time.Now}
+}
+
+func (s *auditedService) record(ctx context.Context, entry Entry) {
+	if service.IsDryRun(ctx) {
+		return
+	}
+	entry.ID = newID()
+	entry.OccurredAt = s.now().UTC()
+	entry.Actor = ActorFromContext(ctx)
+	entry.RequestID = requestid.FromContext(ctx)
+	if err := s.recorder.Record(ctx, entry); err != nil {
+		requestid.Logf(ctx, "ERROR: audit of %s %s%s not recorded: %v", entry.Action, entry.SwiftCode, entry.CountryISO2, err)
+	}
+}
+
+func (s *auditedService) CreateSwiftCode(ctx context.Context, bank *models.SwiftBank) error {
+	// Writes that may replace a stored code read it first so the entry
+	// shows what changed
+	var before *models.SwiftBank
+	if bank != nil && service.WriteModeOf(ctx) != service.WriteCreate {
+		before = s.current(ctx, bank.SwiftCode)
+	}
+	if err := s.SwiftService.CreateSwiftCode(ctx, bank); err != nil {
+		return err
+	}
+	after := *bank
+	entry := Entry{
+		Action:      ActionCreate,
+		SwiftCode:   strings.ToUpper(bank.SwiftCode),
+		CountryISO2: strings.ToUpper(bank.CountryISOCode),
+		Affected:    1,
+		Before:      before,
+		After:       &after,
+	}
+	if before != nil {
+		entry.Action = ActionUpdate
+	}
+	s.record(ctx, entry)
+	return nil
+}
+
+// current returns the stored record of code, or nil when there is none or
+// the write is a dry run
+func (s *auditedService) current(ctx context.Context, code string) *models.SwiftBank {
+	if service.IsDryRun(ctx) {
+		return nil
+	}
+	detail, err := s.SwiftService.GetSwiftCodeDetails(ctx, code)
+	if err != nil {
+		return nil
+	}
+	return &detail.Bank
+}
+
+func (s *auditedService) DeleteSwiftCode(ctx context.Context, code string) error {
+	// The record is read first so the entry shows what was removed
+	before := s.current(ctx, code)
+	if err := s.SwiftService.DeleteSwiftCode(ctx, code); err != nil {
+		return err
+	}
+
+	entry := Entry{Action: ActionDelete, SwiftCode: strings.ToUpper(code), Affected: 1, Before: before}
+	if before != nil {
+		entry.CountryISO2 = before.CountryISOCode
+	}
+	s.record(ctx, entry)
+	return nil
+}
+
+func (s *auditedService) DeleteSwiftCodesByCountry(ctx context.Context, countryCode string) (int64, error) {
+	deleted, err := s.SwiftService.DeleteSwiftCodesByCountry(ctx, countryCode)
+	if err != nil || deleted == 0 {
+		return deleted, err
+	}
+	s.record(ctx, Entry{Action: ActionDeleteByCountry, CountryISO2: strings.ToUpper(countryCode), Affected: deleted})
+	return deleted, nil
+}
@@ -0,0 +1,140 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zdziszkee/swift-codes/internal/database"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+)
+
+// Log appends entries to and reads them from the audit table
+type Log struct {
+	db    *sql.DB
+	table string
+}
+
+// NewLog creates an audit log on table in the catalog and schema of db
+func NewLog(db *database.Database, table string) *Log {
+	return &Log{db: db.DB, table: fmt.Sprintf("%s.%s.%s", db.Config.Catalog, db.Config.Schema, table)}
+}
+
+// Record appends entry. Snapshots are stored as JSON and timestamps as
+// ISO 8601 strings converted in SQL, so the statement only binds strings
+// and numbers.
+func (l *Log) Record(ctx context.Context, entry Entry) error {
+	before, err := encodeBank(entry.Before)
+	if err != nil {
+		return err
+	}
+	after, err := encodeBank(entry.After)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (id, occurred_at, actor, action, swift_code, country_iso_code, affected, before_state, after_state, request_id) "+
+		"VALUES (?, from_iso8601_timestamp(?), ?, ?, ?, ?, ?, ?, ?, ?)", l.table)
+	_, err = l.db.ExecContext(ctx, query,
+		entry.ID,
+		entry.OccurredAt.UTC().Format(time.RFC3339Nano),
+		entry.Actor,
+		entry.Action,
+		entry.SwiftCode,
+		entry.CountryISO2,
+		entry.Affected,
+		before,
+		after,
+		entry.RequestID,
+	)
+	if err != nil {
+		return fmt.Errorf("audit insert failed: %w", err)
+	}
+	return nil
+}
+
+// List returns the entries matching filter, newest first
+func (l *Log) List(ctx context.Context, filter Filter) ([]Entry, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	if filter.SwiftCode != "" {
+		conditions = append(conditions, "swift_code = ?")
+		args = append(args, strings.ToUpper(filter.SwiftCode))
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "occurred_at >= from_iso8601_timestamp(?)")
+		args = append(args, filter.From.UTC().Format(time.RFC3339Nano))
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "occurred_at < from_iso8601_timestamp(?)")
+		args = append(args, filter.To.UTC().Format(time.RFC3339Nano))
+	}
+
+	query := fmt.Sprintf("SELECT id, to_iso8601(occurred_at), actor, action, swift_code, country_iso_code, affected, before_state, after_state, request_id FROM %s", l.table)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY occurred_at DESC, id"
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	rows, err := l.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("audit query failed: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []Entry{}
+	for rows.Next() {
+		var (
+			entry                    Entry
+			occurredAt               string
+			code, country, requestID sql.NullString
+			before, after            sql.NullString
+		)
+		if err := rows.Scan(&entry.ID, &occurredAt, &entry.Actor, &entry.Action, &code, &country,
+			&entry.Affected, &before, &after, &requestID); err != nil {
+			return nil, fmt.Errorf("audit scan failed: %w", err)
+		}
+		if entry.OccurredAt, err = time.Parse(time.RFC3339Nano, occurredAt); err != nil {
+			return nil, fmt.Errorf("audit entry %s has invalid timestamp %q: %w", entry.ID, occurredAt, err)
+		}
+		entry.SwiftCode, entry.CountryISO2, entry.RequestID = code.String, country.String, requestID.String
+		if entry.Before, err = decodeBank(before); err != nil {
+			return nil, err
+		}
+		if entry.After, err = decodeBank(after); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func encodeBank(bank *models.SwiftBank) (sql.NullString, error) {
+	if bank == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(bank)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("encode audit snapshot: %w", err)
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+func decodeBank(data sql.NullString) (*models.SwiftBank, error) {
+	if !data.Valid || data.String == "" {
+		return nil, nil
+	}
+	var bank models.SwiftBank
+	if err := json.Unmarshal([]byte(data.String), &bank); err != nil {
+		return nil, fmt.Errorf("decode audit snapshot: %w", err)
+	}
+	return &bank, nil
+}
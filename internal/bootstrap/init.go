@@ -0,0 +1,140 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/zdziszkee/swift-codes/internal/importer"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+)
+
+// SchemaApplier creates the schema, table and views from a SQL file
+type SchemaApplier interface {
+	ExecuteSchema(filePath string) error
+}
+
+// Options controls a first-time initialization run
+type Options struct {
+	SchemaFile string
+	DataFile   string
+	Golden     importer.GoldenConfig
+	// Import carries extra importer options such as a tolerant header check
+	Import []importer.Option
+}
+
+// Check is the outcome of a single quality check
+type Check struct {
+	Name   string
+	Passed bool
+	Detail string
+}
+
+// Summary describes what an initialization run did and whether the
+// deployment is ready to serve traffic
+type Summary struct {
+	SchemaFile string
+	Imported   int
+	Skipped    bool
+	Stats      repository.DatasetStats
+	Checks     []Check
+}
+
+// Ready reports whether every quality check passed
+func (s *Summary) Ready() bool {
+	for _, check := range s.Checks {
+		if !check.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// Report writes a human-readable readiness summary to w
+func (s *Summary) Report(w io.Writer) {
+	fmt.Fprintf(w, "Schema:        applied from %s\n", s.SchemaFile)
+	if s.Skipped {
+		fmt.Fprintf(w, "Dataset:       already loaded, import skipped\n")
+	} else {
+		fmt.Fprintf(w, "Dataset:       imported %d SWIFT codes\n", s.Imported)
+	}
+	fmt.Fprintf(w, "Rows:          %d (%d headquarters, %d branches) across %d countries\n",
+		s.Stats.TotalCodes, s.Stats.Headquarters, s.Stats.Branches, s.Stats.Countries)
+	fmt.Fprintf(w, "Quality checks:\n")
+	for _, check := range s.Checks {
+		mark := "PASS"
+		if !check.Passed {
+			mark = "FAIL"
+		}
+		fmt.Fprintf(w, "  [%s] %s", mark, check.Name)
+		if check.Detail != "" {
+			fmt.Fprintf(w, ": %s", check.Detail)
+		}
+		fmt.Fprintln(w)
+	}
+	if s.Ready() {
+		fmt.Fprintln(w, "Status:        READY")
+	} else {
+		fmt.Fprintln(w, "Status:        NOT READY")
+	}
+}
+
+// Run applies the schema, loads the dataset when the table is empty and
+// verifies the result. An error means a step could not run at all; failed
+// quality checks are reported through Summary.Ready instead.
+func Run(ctx context.Context, schema SchemaApplier, repo repository.SwiftRepository, opts Options) (*Summary, error) {
+	summary := &Summary{SchemaFile: opts.SchemaFile}
+
+	if err := schema.ExecuteSchema(opts.SchemaFile); err != nil {
+		return nil, fmt.Errorf("failed to apply schema: %w", err)
+	}
+
+	stats, err := repo.Stats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect dataset: %w", err)
+	}
+
+	// Re-running init must not duplicate rows, so only an empty table is loaded
+	if stats.Empty() {
+		log.Printf("Loading SWIFT codes from %s", opts.DataFile)
+		count, err := importer.NewImporter(repo, importer.GoldenConfig{}, opts.Import...).ImportFile(ctx, opts.DataFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load dataset: %w", err)
+		}
+		summary.Imported = count
+
+		if stats, err = repo.Stats(ctx); err != nil {
+			return nil, fmt.Errorf("failed to inspect dataset: %w", err)
+		}
+	} else {
+		summary.Skipped = true
+	}
+	summary.Stats = *stats
+
+	summary.Checks = append(summary.Checks, Check{
+		Name:   "dataset is not empty",
+		Passed: !stats.Empty(),
+	})
+	summary.Checks = append(summary.Checks, Check{
+		Name:   "headquarters present",
+		Passed: stats.Headquarters > 0,
+		Detail: fmt.Sprintf("%d found", stats.Headquarters),
+	})
+
+	problems, err := importer.VerifyGolden(ctx, repo, opts.Golden.Sentinels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify golden sentinels: %w", err)
+	}
+	golden := Check{
+		Name:   "golden sentinels",
+		Passed: len(problems) == 0,
+		Detail: fmt.Sprintf("%d checked", len(opts.Golden.Sentinels)),
+	}
+	for _, problem := range problems {
+		golden.Detail += "; " + problem
+	}
+	summary.Checks = append(summary.Checks, golden)
+
+	return summary, nil
+}
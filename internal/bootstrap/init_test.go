@@ -0,0 +1,131 @@
+package bootstrap_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/bootstrap"
+	"github.com/zdziszkee/swift-codes/internal/importer"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+func TestBootstrap(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Bootstrap Suite")
+}
+
+const sampleCSV = `COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE
+PL,PKOPPLPWXXX,BIC11,BANK PEKAO SA,"ZUBRA 1 WARSZAWA, 01-066",WARSZAWA,POLAND,Europe/Warsaw
+PL,PKOPPLPW123,BIC11,BANK PEKAO SA,"DLUGA 1 WARSZAWA, 01-066",WARSZAWA,POLAND,Europe/Warsaw
+`
+
+type schemaRecorder struct {
+	applied []string
+}
+
+func (s *schemaRecorder) ExecuteSchema(filePath string) error {
+	s.applied = append(s.applied, filePath)
+	return nil
+}
+
+var _ = Describe("Run", func() {
+	var (
+		ctx     context.Context
+		stored  map[string]models.SwiftBank
+		repo    *mocks.MockSwiftRepository
+		schema  *schemaRecorder
+		opts    bootstrap.Options
+		batches int
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		stored = map[string]models.SwiftBank{}
+		schema = &schemaRecorder{}
+		batches = 0
+
+		dataFile := filepath.Join(GinkgoT().TempDir(), "swift_codes.csv")
+		Expect(os.WriteFile(dataFile, []byte(sampleCSV), 0o600)).To(Succeed())
+		opts = bootstrap.Options{
+			SchemaFile: "schema.sql",
+			DataFile:   dataFile,
+			Golden: importer.GoldenConfig{Sentinels: []importer.Sentinel{
+				{SwiftCode: "PKOPPLPWXXX", CountryISOCode: "PL"},
+			}},
+		}
+
+		repo = &mocks.MockSwiftRepository{
+			CreateBatchFunc: func(ctx context.Context, banks []*models.SwiftBank) error {
+				batches++
+				for _, bank := range banks {
+					stored[bank.SwiftCode] = *bank
+				}
+				return nil
+			},
+			GetByCodeFunc: func(ctx context.Context, code string, opts repository.QueryOptions) (*repository.SwiftBankDetail, error) {
+				bank, ok := stored[code]
+				if !ok {
+					return nil, repository.ErrNotFound
+				}
+				return &repository.SwiftBankDetail{Bank: bank}, nil
+			},
+			StatsFunc: func(ctx context.Context) (*repository.DatasetStats, error) {
+				stats := &repository.DatasetStats{TotalCodes: int64(len(stored))}
+				for _, bank := range stored {
+					if bank.IsHeadquarter {
+						stats.Headquarters++
+					}
+				}
+				if len(stored) > 0 {
+					stats.Countries = 1
+				}
+				stats.Branches = stats.TotalCodes - stats.Headquarters
+				return stats, nil
+			},
+		}
+	})
+
+	It("should apply the schema, load the dataset and report readiness", func() {
+		summary, err := bootstrap.Run(ctx, schema, repo, opts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(schema.applied).To(Equal([]string{"schema.sql"}))
+		Expect(summary.Imported).To(Equal(2))
+		Expect(summary.Stats.TotalCodes).To(Equal(int64(2)))
+		Expect(summary.Ready()).To(BeTrue())
+
+		var out bytes.Buffer
+		summary.Report(&out)
+		Expect(out.String()).To(ContainSubstring("Status:        READY"))
+	})
+
+	It("should not reload a dataset that is already present", func() {
+		_, err := bootstrap.Run(ctx, schema, repo, opts)
+		Expect(err).NotTo(HaveOccurred())
+
+		summary, err := bootstrap.Run(ctx, schema, repo, opts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary.Skipped).To(BeTrue())
+		Expect(batches).To(Equal(1))
+	})
+
+	It("should report failed golden checks as not ready", func() {
+		opts.Golden.Sentinels = append(opts.Golden.Sentinels, importer.Sentinel{SwiftCode: "AAISALTRXXX"})
+
+		summary, err := bootstrap.Run(ctx, schema, repo, opts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary.Ready()).To(BeFalse())
+
+		var out bytes.Buffer
+		summary.Report(&out)
+		Expect(out.String()).To(ContainSubstring("AAISALTRXXX is missing"))
+		Expect(out.String()).To(ContainSubstring("NOT READY"))
+	})
+})
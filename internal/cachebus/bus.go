@@ -0,0 +1,277 @@
+// Package cachebus shares repository cache invalidations between replicas
+// over Redis pub/sub, so that every instance drops the entries a write
+// made through any of them touched. RedisCache goes further and keeps hot
+// lookups in Redis itself, shared by every replica.
+package cachebus
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+)
+
+// queueSize bounds the invalidations waiting to be published
+const queueSize = 1024
+
+// Config describes the Redis server and channel shared by the replicas
+type Config struct {
+	// Enabled publishes cache invalidations and applies those of other
+	// replicas
+	Enabled bool `koanf:"enabled"`
+	// Address is the host:port of the Redis server
+	Address  string `koanf:"address"`
+	Password string `koanf:"password"`
+	// Channel is the pub/sub channel; replicas of one deployment must share
+	// it and other deployments must not
+	Channel string `koanf:"channel"`
+	// DialTimeout bounds connecting and authenticating
+	DialTimeout time.Duration `koanf:"dial_timeout"`
+	// ReconnectInterval is the wait before a lost connection is dialled
+	// again. Every cache is cleared once the subscription is back, since
+	// invalidations published meanwhile were missed.
+	ReconnectInterval time.Duration `koanf:"reconnect_interval"`
+}
+
+// Validate checks an enabled bus for missing settings
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Address == "" || c.Channel == "" {
+		return errors.New("cache_bus address and channel are required when enabled")
+	}
+	if c.DialTimeout <= 0 || c.ReconnectInterval <= 0 {
+		return errors.New("cache_bus dial_timeout and reconnect_interval must be positive")
+	}
+	return nil
+}
+
+// DialFunc opens a connection to the Redis server
+type DialFunc func(ctx context.Context) (io.ReadWriteCloser, error)
+
+// TCPDialer returns a DialFunc that connects to the configured address
+func TCPDialer(cfg Config) DialFunc {
+	return func(ctx context.Context) (io.ReadWriteCloser, error) {
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, "tcp", cfg.Address)
+	}
+}
+
+// message is the payload published on the channel
+type message struct {
+	// Origin identifies the publishing replica, which skips its own
+	// messages
+	Origin string `json:"origin"`
+	repository.Invalidation
+}
+
+// Bus is a repository.InvalidationBus over Redis pub/sub. Publish never
+// blocks a write: invalidations are queued and sent by Run, and when the
+// queue overflows the next message clears every cache instead.
+type Bus struct {
+	cfg    Config
+	dial   DialFunc
+	origin string
+	queue  chan repository.Invalidation
+	// overflow is set when an invalidation did not fit in the queue
+	overflow atomic.Bool
+
+	mu       sync.Mutex
+	handlers []func(repository.Invalidation)
+}
+
+// NewBus creates a bus that reaches Redis through dial
+func NewBus(cfg Config, dial DialFunc) *Bus {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return &Bus{
+		cfg:    cfg,
+		dial:   dial,
+		origin: hex.EncodeToString(id),
+		queue:  make(chan repository.Invalidation, queueSize),
+	}
+}
+
+// Publish queues an invalidation made by this replica
+func (b *Bus) Publish(inv repository.Invalidation) {
+	select {
+	case b.queue <- inv:
+	default:
+		b.overflow.Store(true)
+	}
+}
+
+// Subscribe registers fn for the invalidations of other replicas
+func (b *Bus) Subscribe(fn func(repository.Invalidation)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, fn)
+}
+
+func (b *Bus) deliver(inv repository.Invalidation) {
+	b.mu.Lock()
+	handlers := append([]func(repository.Invalidation){}, b.handlers...)
+	b.mu.Unlock()
+	for _, fn := range handlers {
+		fn(inv)
+	}
+}
+
+// Run publishes queued invalidations and applies those of other replicas
+// until ctx ends, reconnecting whenever the server goes away
+func (b *Bus) Run(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		b.subscribeLoop(ctx)
+	}()
+	b.publishLoop(ctx)
+	<-done
+}
+
+// connect dials the server and authenticates
+func (b *Bus) connect(ctx context.Context) (*conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.cfg.DialTimeout)
+	defer cancel()
+
+	rw, err := b.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c := newConn(rw)
+	if b.cfg.Password != "" {
+		stop := context.AfterFunc(ctx, func() { c.Close() })
+		defer stop()
+		if _, err := c.do("AUTH", b.cfg.Password); err != nil {
+			c.Close()
+			return nil, fmt.Errorf("cache bus authentication: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// wait sleeps for the reconnect interval and reports whether ctx is still
+// live
+func (b *Bus) wait(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(b.cfg.ReconnectInterval):
+		return true
+	}
+}
+
+func (b *Bus) subscribeLoop(ctx context.Context) {
+	for {
+		err := b.subscribe(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("WARNING: cache bus subscription lost: %v", err)
+		if !b.wait(ctx) {
+			return
+		}
+	}
+}
+
+// subscribe listens on the channel until the connection fails or ctx ends
+func (b *Bus) subscribe(ctx context.Context) error {
+	c, err := b.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	stop := context.AfterFunc(ctx, func() { c.Close() })
+	defer stop()
+
+	if err := c.send("SUBSCRIBE", b.cfg.Channel); err != nil {
+		return err
+	}
+	for {
+		reply, err := c.receive()
+		if err != nil {
+			return err
+		}
+		if redisErr, ok := reply.(RedisError); ok {
+			return redisErr
+		}
+		items, ok := reply.([]any)
+		if !ok || len(items) != 3 {
+			continue
+		}
+
+		switch kind, _ := items[0].(string); kind {
+		case "subscribe":
+			// Whatever was published before the subscription was missed
+			b.deliver(repository.Invalidation{All: true})
+		case "message":
+			payload, _ := items[2].(string)
+			var msg message
+			if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+				log.Printf("WARNING: cache bus ignored a malformed message: %v", err)
+				continue
+			}
+			if msg.Origin != b.origin {
+				b.deliver(msg.Invalidation)
+			}
+		}
+	}
+}
+
+func (b *Bus) publishLoop(ctx context.Context) {
+	var c *conn
+	defer func() {
+		if c != nil {
+			c.Close()
+		}
+	}()
+
+	for {
+		var inv repository.Invalidation
+		select {
+		case <-ctx.Done():
+			return
+		case inv = <-b.queue:
+		}
+		if b.overflow.Swap(false) {
+			inv = repository.Invalidation{All: true}
+		}
+		payload, err := json.Marshal(message{Origin: b.origin, Invalidation: inv})
+		if err != nil {
+			continue
+		}
+
+		// Retry until published: other replicas would otherwise keep the
+		// entries until their ttl runs out
+		for {
+			if c == nil {
+				c, err = b.connect(ctx)
+			}
+			if err == nil {
+				if _, err = c.do("PUBLISH", b.cfg.Channel, string(payload)); err == nil {
+					break
+				}
+				c.Close()
+				c = nil
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("WARNING: cache bus publish failed: %v", err)
+			if !b.wait(ctx) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package cachebus_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/cachebus"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+func TestCacheBus(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cache Bus Suite")
+}
+
+// fakeRedis answers AUTH, SUBSCRIBE, PUBLISH, GET, SET, MGET and INCR for
+// connections made through dial
+type fakeRedis struct {
+	password string
+
+	mu    sync.Mutex
+	conns map[net.Conn]bool
+	// subs maps subscribed connections to their channel
+	subs map[net.Conn]string
+	// data holds the values of GET, SET and INCR; expiries are ignored
+	data map[string]string
+	// down refuses every connection
+	down bool
+}
+
+func newFakeRedis(password string) *fakeRedis {
+	return &fakeRedis{password: password, conns: map[net.Conn]bool{}, subs: map[net.Conn]string{}, data: map[string]string{}}
+}
+
+func (f *fakeRedis) dial(ctx context.Context) (io.ReadWriteCloser, error) {
+	client, server := net.Pipe()
+	f.mu.Lock()
+	if f.down {
+		f.mu.Unlock()
+		return nil, errors.New("connection refused")
+	}
+	f.conns[server] = true
+	f.mu.Unlock()
+	go f.serve(server)
+	return client, nil
+}
+
+// disconnect drops every connection, as a restarting server would
+func (f *fakeRedis) disconnect() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for conn := range f.conns {
+		conn.Close()
+	}
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer func() {
+		f.mu.Lock()
+		delete(f.conns, conn)
+		delete(f.subs, conn)
+		f.mu.Unlock()
+		conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	authenticated := f.password == ""
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		switch cmd := strings.ToUpper(args[0]); {
+		case cmd == "AUTH":
+			authenticated = args[1] == f.password
+			if !authenticated {
+				fmt.Fprint(conn, "-WRONGPASS invalid password\r\n")
+				continue
+			}
+			fmt.Fprint(conn, "+OK\r\n")
+		case !authenticated:
+			fmt.Fprint(conn, "-NOAUTH Authentication required.\r\n")
+		case cmd == "SUBSCRIBE":
+			fmt.Fprintf(conn, "*3\r\n$9\r\nsubscribe\r\n%s:1\r\n", bulk(args[1]))
+			f.mu.Lock()
+			f.subs[conn] = args[1]
+			f.mu.Unlock()
+		case cmd == "PUBLISH":
+			f.mu.Lock()
+			receivers := 0
+			for sub, channel := range f.subs {
+				if channel == args[1] {
+					fmt.Fprintf(sub, "*3\r\n$7\r\nmessage\r\n%s%s", bulk(args[1]), bulk(args[2]))
+					receivers++
+				}
+			}
+			f.mu.Unlock()
+			fmt.Fprintf(conn, ":%d\r\n", receivers)
+		case cmd == "GET":
+			f.mu.Lock()
+			value, ok := f.data[args[1]]
+			f.mu.Unlock()
+			if ok {
+				fmt.Fprint(conn, bulk(value))
+			} else {
+				fmt.Fprint(conn, "$-1\r\n")
+			}
+		case cmd == "SET":
+			f.mu.Lock()
+			f.data[args[1]] = args[2]
+			f.mu.Unlock()
+			fmt.Fprint(conn, "+OK\r\n")
+		case cmd == "MGET":
+			f.mu.Lock()
+			reply := fmt.Sprintf("*%d\r\n", len(args)-1)
+			for _, key := range args[1:] {
+				if value, ok := f.data[key]; ok {
+					reply += bulk(value)
+				} else {
+					reply += "$-1\r\n"
+				}
+			}
+			f.mu.Unlock()
+			fmt.Fprint(conn, reply)
+		case cmd == "INCR":
+			f.mu.Lock()
+			n, _ := strconv.Atoi(f.data[args[1]])
+			f.data[args[1]] = strconv.Itoa(n + 1)
+			f.mu.Unlock()
+			fmt.Fprintf(conn, ":%d\r\n", n+1)
+		}
+	}
+}
+
+func bulk(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+// readCommand reads an array of bulk strings
+func readCommand(r *bufio.Reader) ([]string, error) {
+	header, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+	args := make([]string, n)
+	for i := range args {
+		if _, err := r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		arg, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args[i] = strings.TrimSuffix(arg, "\r\n")
+	}
+	return args, nil
+}
+
+var _ = Describe("Bus", func() {
+	var (
+		server *fakeRedis
+		cfg    cachebus.Config
+	)
+
+	BeforeEach(func() {
+		server = newFakeRedis("secret")
+		cfg = cachebus.Config{
+			Enabled:           true,
+			Address:           "redis:6379",
+			Password:          "secret",
+			Channel:           "swift-codes:cache",
+			DialTimeout:       time.Second,
+			ReconnectInterval: 10 * time.Millisecond,
+		}
+	})
+
+	// start runs a bus until the spec ends and returns it with the
+	// invalidations it applied
+	start := func() (*cachebus.Bus, chan repository.Invalidation) {
+		bus := cachebus.NewBus(cfg, server.dial)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			bus.Run(ctx)
+		}()
+		DeferCleanup(func() {
+			cancel()
+			<-done
+		})
+		return bus, make(chan repository.Invalidation, 16)
+	}
+
+	It("should evict entries on every other replica when one writes", func() {
+		writer, writerApplied := start()
+		reader, readerApplied := start()
+
+		calls := 0
+		readerRepo := repository.Chain(&mocks.MockSwiftRepository{
+			GetByCodeFunc: func(ctx context.Context, code string, opts repository.QueryOptions) (*repository.SwiftBankDetail, error) {
+				calls++
+				return &repository.SwiftBankDetail{Bank: models.SwiftBank{SwiftCode: code, CountryISOCode: "US"}}, nil
+			},
+		}, repository.WithSharedCache(repository.NewCacheTTL(time.Minute), reader))
+		writerRepo := repository.Chain(&mocks.MockSwiftRepository{
+			DeleteFunc: func(ctx context.Context, code string) error { return nil },
+		}, repository.WithSharedCache(repository.NewCacheTTL(time.Minute), writer))
+		writer.Subscribe(func(inv repository.Invalidation) { writerApplied <- inv })
+		reader.Subscribe(func(inv repository.Invalidation) { readerApplied <- inv })
+
+		// Subscribing clears the cache, as anything before it was missed
+		Eventually(readerApplied).Should(Receive(Equal(repository.Invalidation{All: true})))
+		Eventually(writerApplied).Should(Receive(Equal(repository.Invalidation{All: true})))
+
+		ctx := context.Background()
+		_, _ = readerRepo.GetByCode(ctx, "ABCDUS33XXX", repository.QueryOptions{})
+		_, _ = readerRepo.GetByCode(ctx, "ABCDUS33XXX", repository.QueryOptions{})
+		Expect(calls).To(Equal(1))
+
+		Expect(writerRepo.Delete(ctx, "ABCDUS33XXX")).To(Succeed())
+		Eventually(readerApplied).Should(Receive(HaveField("Tags", ContainElement("country:US"))))
+		_, _ = readerRepo.GetByCode(ctx, "ABCDUS33XXX", repository.QueryOptions{})
+		Expect(calls).To(Equal(2))
+
+		// A replica does not apply its own invalidations twice
+		Consistently(writerApplied, 50*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("should clear the cache when a lost subscription comes back", func() {
+		bus, applied := start()
+		bus.Subscribe(func(inv repository.Invalidation) { applied <- inv })
+		Eventually(applied).Should(Receive(Equal(repository.Invalidation{All: true})))
+
+		server.disconnect()
+		Eventually(applied).Should(Receive(Equal(repository.Invalidation{All: true})))
+	})
+
+	It("should keep retrying with a wrong password", func() {
+		cfg.Password = "wrong"
+		bus, applied := start()
+		bus.Subscribe(func(inv repository.Invalidation) { applied <- inv })
+		Consistently(applied, 50*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("should require an address and channel when enabled", func() {
+		Expect(cfg.Validate()).To(Succeed())
+		cfg.Channel = ""
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("channel")))
+		Expect(cachebus.Config{}.Validate()).To(Succeed())
+	})
+})
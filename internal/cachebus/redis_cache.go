@@ -0,0 +1,355 @@
+package cachebus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+)
+
+// CacheConfig describes the Redis server holding cached lookups
+type CacheConfig struct {
+	// Enabled keeps GetByCode and GetByCountry results in Redis, shared by
+	// every replica
+	Enabled bool `koanf:"enabled"`
+	// Address is the host:port of the Redis server
+	Address  string `koanf:"address"`
+	Password string `koanf:"password"`
+	// KeyPrefix starts every key; deployments sharing a server must differ
+	KeyPrefix string `koanf:"key_prefix"`
+	// CodeTTL and CountryTTL are the lifetimes of code details and country
+	// listings; they bound staleness should an invalidation be lost
+	CodeTTL    time.Duration `koanf:"code_ttl"`
+	CountryTTL time.Duration `koanf:"country_ttl"`
+	// PoolSize caps the idle connections kept for reuse
+	PoolSize int `koanf:"pool_size"`
+	// DialTimeout bounds connecting and authenticating
+	DialTimeout time.Duration `koanf:"dial_timeout"`
+	// Timeout bounds every command; a slow or lost server falls through to
+	// Trino instead of holding requests up
+	Timeout time.Duration `koanf:"timeout"`
+}
+
+// Validate checks an enabled cache for missing settings
+func (c CacheConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Address == "" {
+		return errors.New("redis_cache address is required when enabled")
+	}
+	if c.CodeTTL <= 0 || c.CountryTTL <= 0 {
+		return errors.New("redis_cache code_ttl and country_ttl must be positive")
+	}
+	if c.PoolSize < 1 {
+		return errors.New("redis_cache pool_size must be at least 1")
+	}
+	if c.DialTimeout <= 0 || c.Timeout <= 0 {
+		return errors.New("redis_cache dial_timeout and timeout must be positive")
+	}
+	return nil
+}
+
+// CacheDialer returns a DialFunc that connects to the configured address
+func CacheDialer(cfg CacheConfig) DialFunc {
+	return func(ctx context.Context) (io.ReadWriteCloser, error) {
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, "tcp", cfg.Address)
+	}
+}
+
+// RedisCache keeps repository lookups in Redis. Keys carry generation
+// counters, one for every country and one for the whole table, that writes
+// increment: a write through any replica thus retires every cached lookup
+// of the countries it touched without knowing their keys, and the old
+// entries expire on their own.
+type RedisCache struct {
+	cfg  CacheConfig
+	dial DialFunc
+	idle chan *conn
+}
+
+// NewRedisCache creates a cache that reaches Redis through dial
+func NewRedisCache(cfg CacheConfig, dial DialFunc) *RedisCache {
+	return &RedisCache{cfg: cfg, dial: dial, idle: make(chan *conn, cfg.PoolSize)}
+}
+
+// Middleware returns the repository middleware caching the lookups of one
+// table; namespace, usually the table name, keeps the keys of different
+// tables apart. With overwrites set, as when batch loads replace stored
+// codes, every batch retires the whole table.
+func (c *RedisCache) Middleware(namespace string, overwrites bool) repository.Middleware {
+	return func(next repository.SwiftRepository) repository.SwiftRepository {
+		return &redisRepository{SwiftRepository: next, cache: c, prefix: c.cfg.KeyPrefix + namespace + ":", overwrites: overwrites}
+	}
+}
+
+// do runs one command on a pooled connection. A connection that fails is
+// dropped rather than returned to the pool.
+func (c *RedisCache) do(ctx context.Context, args ...string) (any, error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if d, ok := cn.rw.(interface{ SetDeadline(time.Time) error }); ok {
+		_ = d.SetDeadline(time.Now().Add(c.cfg.Timeout))
+	}
+	reply, err := cn.do(args...)
+	var redisErr RedisError
+	if err != nil && !errors.As(err, &redisErr) {
+		cn.Close()
+		return nil, err
+	}
+	select {
+	case c.idle <- cn:
+	default:
+		cn.Close()
+	}
+	return reply, err
+}
+
+func (c *RedisCache) get(ctx context.Context) (*conn, error) {
+	select {
+	case cn := <-c.idle:
+		return cn, nil
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.DialTimeout)
+	defer cancel()
+	rw, err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cn := newConn(rw)
+	if c.cfg.Password != "" {
+		stop := context.AfterFunc(ctx, func() { cn.Close() })
+		defer stop()
+		if _, err := cn.do("AUTH", c.cfg.Password); err != nil {
+			cn.Close()
+			return nil, fmt.Errorf("redis cache authentication: %w", err)
+		}
+	}
+	return cn, nil
+}
+
+// redisRepository serves GetByCode and GetByCountry from Redis; the other
+// reads pass through. Redis failures are logged and fall through to next,
+// so the cache can never fail a lookup.
+type redisRepository struct {
+	repository.SwiftRepository
+	cache  *RedisCache
+	prefix string
+	// overwrites is set when CreateBatch may move stored codes to another
+	// country
+	overwrites bool
+}
+
+// generationKey returns the counter of a country, or of the whole table
+// for ""
+func (r *redisRepository) generationKey(country string) string {
+	if country == "" {
+		return r.prefix + "gen"
+	}
+	return r.prefix + "gen:" + country
+}
+
+// versionedKey returns the key of a lookup in country under the current
+// generations
+func (r *redisRepository) versionedKey(ctx context.Context, kind, country, id string, opts repository.QueryOptions) (string, error) {
+	reply, err := r.cache.do(ctx, "MGET", r.generationKey(""), r.generationKey(country))
+	if err != nil {
+		return "", err
+	}
+	generations, _ := reply.([]any)
+	version := make([]string, 2)
+	for i := range version {
+		version[i] = "0"
+		if i < len(generations) {
+			if g, ok := generations[i].(string); ok {
+				version[i] = g
+			}
+		}
+	}
+	return r.prefix + kind + ":" + country + ":" + strings.Join(version, ".") + ":" + id + ":" + opts.CacheKey(), nil
+}
+
+// lookup answers from Redis when key holds an entry, and otherwise stores
+// what load returns for ttl
+func lookup[T any](ctx context.Context, r *redisRepository, key string, ttl time.Duration, load func() (*T, bool, error)) (*T, error) {
+	if reply, err := r.cache.do(ctx, "GET", key); err != nil {
+		log.Printf("WARNING: redis cache read failed: %v", err)
+	} else if payload, ok := reply.(string); ok {
+		var value T
+		if err := json.Unmarshal([]byte(payload), &value); err == nil {
+			return &value, nil
+		}
+	}
+
+	value, cacheable, err := load()
+	if err != nil || !cacheable {
+		return value, err
+	}
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return value, nil
+	}
+	if _, err := r.cache.do(ctx, "SET", key, string(payload), "PX", strconv.FormatInt(ttl.Milliseconds(), 10)); err != nil {
+		log.Printf("WARNING: redis cache write failed: %v", err)
+	}
+	return value, nil
+}
+
+func (r *redisRepository) GetByCode(ctx context.Context, code string, opts repository.QueryOptions) (*repository.SwiftBankDetail, error) {
+	if !opts.Cacheable() {
+		return r.SwiftRepository.GetByCode(ctx, code, opts)
+	}
+	key, err := r.versionedKey(ctx, "code", bicCountry(code), code, opts)
+	if err != nil {
+		log.Printf("WARNING: redis cache unavailable: %v", err)
+		return r.SwiftRepository.GetByCode(ctx, code, opts)
+	}
+	return lookup(ctx, r, key, r.cache.cfg.CodeTTL, func() (*repository.SwiftBankDetail, bool, error) {
+		detail, err := r.SwiftRepository.GetByCode(ctx, code, opts)
+		// Partial answers are not cached so the next lookup retries
+		return detail, err == nil && !detail.BranchesUnavailable, err
+	})
+}
+
+func (r *redisRepository) GetByCountry(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+	if !opts.Cacheable() {
+		return r.SwiftRepository.GetByCountry(ctx, countryCode, opts)
+	}
+	key, err := r.versionedKey(ctx, "country", countryCode, "", opts)
+	if err != nil {
+		log.Printf("WARNING: redis cache unavailable: %v", err)
+		return r.SwiftRepository.GetByCountry(ctx, countryCode, opts)
+	}
+	entry, err := lookup(ctx, r, key, r.cache.cfg.CountryTTL, func() (*countryEntry, bool, error) {
+		codes, err := r.SwiftRepository.GetByCountry(ctx, countryCode, opts)
+		if err != nil {
+			return nil, false, err
+		}
+		return &countryEntry{Codes: codes, Total: codes.Total}, true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if entry.Codes == nil {
+		return r.SwiftRepository.GetByCountry(ctx, countryCode, opts)
+	}
+	entry.Codes.Total = entry.Total
+	return entry.Codes, nil
+}
+
+// countryEntry is a cached country listing; Total is not part of the
+// listing's JSON
+type countryEntry struct {
+	Codes *repository.CountrySwiftCodes `json:"codes"`
+	Total int                           `json:"total"`
+}
+
+// retire increments the generations of countries once a write has
+// finished. A failure leaves the old entries live until their ttl runs out.
+func (r *redisRepository) retire(ctx context.Context, countries ...string) {
+	seen := make(map[string]bool, len(countries))
+	for _, country := range countries {
+		if country == "" || seen[country] {
+			continue
+		}
+		seen[country] = true
+		r.increment(ctx, r.generationKey(country))
+	}
+}
+
+// retireAll increments the generation of the whole table
+func (r *redisRepository) retireAll(ctx context.Context) {
+	r.increment(ctx, r.generationKey(""))
+}
+
+func (r *redisRepository) increment(ctx context.Context, key string) {
+	if _, err := r.cache.do(context.WithoutCancel(ctx), "INCR", key); err != nil {
+		log.Printf("WARNING: redis cache invalidation failed, entries may be stale until they expire: %v", err)
+	}
+}
+
+func (r *redisRepository) Create(ctx context.Context, bank *models.SwiftBank) error {
+	defer r.retire(ctx, bicCountry(bank.SwiftCode), bank.CountryISOCode)
+	return r.SwiftRepository.Create(ctx, bank)
+}
+
+func (r *redisRepository) CreateBatch(ctx context.Context, banks []*models.SwiftBank) error {
+	if r.overwrites {
+		defer r.retireAll(ctx)
+		return r.SwiftRepository.CreateBatch(ctx, banks)
+	}
+	countries := make([]string, 0, 2*len(banks))
+	for _, bank := range banks {
+		countries = append(countries, bicCountry(bank.SwiftCode), bank.CountryISOCode)
+	}
+	defer r.retire(ctx, countries...)
+	return r.SwiftRepository.CreateBatch(ctx, banks)
+}
+
+// Delete retires the country of the code and that of the stored bank,
+// which a listing holds it under when the two differ. Should the stored
+// bank not be read, every country is retired.
+func (r *redisRepository) Delete(ctx context.Context, code string) error {
+	stored, err := r.SwiftRepository.GetByCode(ctx, code, repository.QueryOptions{
+		Consistency:  repository.ConsistencyStrong,
+		OmitBranches: true,
+	})
+	switch {
+	case err == nil:
+		defer r.retire(ctx, bicCountry(code), stored.Bank.CountryISOCode)
+	case errors.Is(err, repository.ErrNotFound):
+		defer r.retire(ctx, bicCountry(code))
+	default:
+		defer r.retireAll(ctx)
+	}
+	return r.SwiftRepository.Delete(ctx, code)
+}
+
+func (r *redisRepository) DeleteByCountry(ctx context.Context, countryCode string) (int64, error) {
+	defer r.retire(ctx, countryCode)
+	return r.SwiftRepository.DeleteByCountry(ctx, countryCode)
+}
+
+func (r *redisRepository) LoadCSV(ctx context.Context, csvPath string) error {
+	defer r.retireAll(ctx)
+	return r.SwiftRepository.LoadCSV(ctx, csvPath)
+}
+
+func (r *redisRepository) UpdateContacts(ctx context.Context, contacts []models.BankContact) (int64, error) {
+	countries := make([]string, 0, len(contacts))
+	for _, contact := range contacts {
+		countries = append(countries, bicCountry(strings.ToUpper(contact.SwiftCode)))
+	}
+	defer r.retire(ctx, countries...)
+	return r.SwiftRepository.UpdateContacts(ctx, contacts)
+}
+
+// Upsert retires every country, as an updated code may have moved to
+// another one
+func (r *redisRepository) Upsert(ctx context.Context, banks []*models.SwiftBank) (int64, error) {
+	defer r.retireAll(ctx)
+	return r.SwiftRepository.Upsert(ctx, banks)
+}
+
+// bicCountry returns the country part of a SWIFT code, or "" when the code
+// is too short to have one
+func bicCountry(code string) string {
+	if len(code) < 6 {
+		return ""
+	}
+	return strings.ToUpper(code[4:6])
+}
@@ -0,0 +1,147 @@
+package cachebus_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/cachebus"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+var _ = Describe("RedisCache", func() {
+	var (
+		server        *fakeRedis
+		cfg           cachebus.CacheConfig
+		codeCalls     int
+		countryCalls  int
+		mock          *mocks.MockSwiftRepository
+		ctx           context.Context
+		newReplicaFor func(table string) repository.SwiftRepository
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		server = newFakeRedis("secret")
+		cfg = cachebus.CacheConfig{
+			Enabled:     true,
+			Address:     "redis:6379",
+			Password:    "secret",
+			KeyPrefix:   "swift-codes:",
+			CodeTTL:     time.Hour,
+			CountryTTL:  time.Minute,
+			PoolSize:    2,
+			DialTimeout: time.Second,
+			Timeout:     time.Second,
+		}
+		codeCalls, countryCalls = 0, 0
+		mock = &mocks.MockSwiftRepository{
+			GetByCodeFunc: func(ctx context.Context, code string, opts repository.QueryOptions) (*repository.SwiftBankDetail, error) {
+				codeCalls++
+				if code == "MISSUS33XXX" {
+					return nil, repository.ErrNotFound
+				}
+				return &repository.SwiftBankDetail{Bank: models.SwiftBank{SwiftCode: code, CountryISOCode: "US", BankName: "BANK"}}, nil
+			},
+			GetByCountryFunc: func(ctx context.Context, country string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+				countryCalls++
+				return &repository.CountrySwiftCodes{CountryISO2: country, SwiftCodes: []models.SwiftBank{{SwiftCode: "ABCDUS33XXX"}}, Total: 7}, nil
+			},
+			CreateFunc: func(ctx context.Context, bank *models.SwiftBank) error { return nil },
+			DeleteFunc: func(ctx context.Context, code string) error { return nil },
+		}
+		// Replicas share the Redis server but not their connections
+		newReplicaFor = func(table string) repository.SwiftRepository {
+			return repository.Chain(mock, cachebus.NewRedisCache(cfg, server.dial).Middleware(table, false))
+		}
+	})
+
+	It("should share lookups between replicas", func() {
+		first, second := newReplicaFor("swift_banks"), newReplicaFor("swift_banks")
+
+		detail, err := first.GetByCode(ctx, "ABCDUS33XXX", repository.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(detail.Bank.BankName).To(Equal("BANK"))
+		detail, err = second.GetByCode(ctx, "ABCDUS33XXX", repository.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(detail.Bank.BankName).To(Equal("BANK"))
+		Expect(codeCalls).To(Equal(1))
+
+		codes, err := second.GetByCountry(ctx, "US", repository.QueryOptions{Limit: 1})
+		Expect(err).NotTo(HaveOccurred())
+		codes, err = first.GetByCountry(ctx, "US", repository.QueryOptions{Limit: 1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(codes.Total).To(Equal(7))
+		Expect(codes.SwiftCodes).To(HaveLen(1))
+		Expect(countryCalls).To(Equal(1))
+
+		// Other options and other tables are other entries
+		_, _ = first.GetByCountry(ctx, "US", repository.QueryOptions{Limit: 2})
+		_, _ = newReplicaFor("swift_banks_staging").GetByCountry(ctx, "US", repository.QueryOptions{Limit: 1})
+		Expect(countryCalls).To(Equal(3))
+	})
+
+	It("should retire the lookups of a country written through any replica", func() {
+		reader, writer := newReplicaFor("swift_banks"), newReplicaFor("swift_banks")
+		_, _ = reader.GetByCode(ctx, "ABCDUS33XXX", repository.QueryOptions{})
+		_, _ = reader.GetByCountry(ctx, "US", repository.QueryOptions{})
+		_, _ = reader.GetByCountry(ctx, "PL", repository.QueryOptions{})
+
+		Expect(writer.Create(ctx, &models.SwiftBank{SwiftCode: "ABCDUS33NYC", CountryISOCode: "US"})).To(Succeed())
+		_, _ = reader.GetByCode(ctx, "ABCDUS33XXX", repository.QueryOptions{})
+		_, _ = reader.GetByCountry(ctx, "US", repository.QueryOptions{})
+		_, _ = reader.GetByCountry(ctx, "PL", repository.QueryOptions{})
+		Expect(codeCalls).To(Equal(2))
+		Expect(countryCalls).To(Equal(3))
+
+		Expect(writer.Delete(ctx, "ABCDUS33NYC")).To(Succeed())
+		_, _ = reader.GetByCountry(ctx, "US", repository.QueryOptions{})
+		Expect(countryCalls).To(Equal(4))
+	})
+
+	It("should retire the stored country of a deleted code that differs from its BIC country", func() {
+		mock.GetByCodeFunc = func(ctx context.Context, code string, opts repository.QueryOptions) (*repository.SwiftBankDetail, error) {
+			return &repository.SwiftBankDetail{Bank: models.SwiftBank{SwiftCode: code, CountryISOCode: "PL"}}, nil
+		}
+		reader, writer := newReplicaFor("swift_banks"), newReplicaFor("swift_banks")
+		_, _ = reader.GetByCountry(ctx, "PL", repository.QueryOptions{})
+
+		Expect(writer.Delete(ctx, "ABCDUS33WAW")).To(Succeed())
+		_, _ = reader.GetByCountry(ctx, "PL", repository.QueryOptions{})
+		Expect(countryCalls).To(Equal(2))
+	})
+
+	It("should not cache errors or strong reads", func() {
+		repo := newReplicaFor("swift_banks")
+		for range 2 {
+			_, err := repo.GetByCode(ctx, "MISSUS33XXX", repository.QueryOptions{})
+			Expect(err).To(MatchError(repository.ErrNotFound))
+			_, _ = repo.GetByCode(ctx, "ABCDUS33XXX", repository.QueryOptions{Consistency: repository.ConsistencyStrong})
+		}
+		Expect(codeCalls).To(Equal(4))
+	})
+
+	It("should fall through to the repository while Redis is down", func() {
+		server.down = true
+		repo := newReplicaFor("swift_banks")
+		detail, err := repo.GetByCode(ctx, "ABCDUS33XXX", repository.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(detail.Bank.SwiftCode).To(Equal("ABCDUS33XXX"))
+		Expect(repo.Delete(ctx, "ABCDUS33XXX")).To(Succeed())
+
+		mock.DeleteFunc = func(ctx context.Context, code string) error { return errors.New("trino unavailable") }
+		Expect(repo.Delete(ctx, "ABCDUS33XXX")).To(MatchError("trino unavailable"))
+	})
+
+	It("should validate an enabled cache", func() {
+		Expect(cfg.Validate()).To(Succeed())
+		cfg.PoolSize = 0
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("pool_size")))
+		Expect(cachebus.CacheConfig{}.Validate()).To(Succeed())
+	})
+})
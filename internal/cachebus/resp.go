@@ -0,0 +1,121 @@
+package cachebus
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxBulk bounds bulk replies so a broken server cannot exhaust memory
+const maxBulk = 1 << 20
+
+// RedisError is an error reply of the server
+type RedisError string
+
+func (e RedisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// conn speaks the subset of the Redis protocol (RESP2) the bus needs:
+// sending commands and reading replies made of strings, integers and arrays
+type conn struct {
+	rw io.ReadWriteCloser
+	r  *bufio.Reader
+}
+
+func newConn(rw io.ReadWriteCloser) *conn {
+	return &conn{rw: rw, r: bufio.NewReader(rw)}
+}
+
+func (c *conn) Close() error {
+	return c.rw.Close()
+}
+
+// send writes a command as an array of bulk strings
+func (c *conn) send(args ...string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	_, err := io.WriteString(c.rw, b.String())
+	return err
+}
+
+// do sends a command and reads its reply, returning error replies as
+// RedisError
+func (c *conn) do(args ...string) (any, error) {
+	if err := c.send(args...); err != nil {
+		return nil, err
+	}
+	reply, err := c.receive()
+	if err != nil {
+		return nil, err
+	}
+	if redisErr, ok := reply.(RedisError); ok {
+		return nil, redisErr
+	}
+	return reply, nil
+}
+
+// receive reads one reply: a string for simple and bulk strings, an int64,
+// a []any, nil for null replies or a RedisError
+func (c *conn) receive() (any, error) {
+	line, err := c.line()
+	if err != nil {
+		return nil, err
+	}
+	if line == "" {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch kind, rest := line[0], line[1:]; kind {
+	case '+':
+		return rest, nil
+	case '-':
+		return RedisError(rest), nil
+	case ':':
+		return strconv.ParseInt(rest, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(rest)
+		if err != nil || n > maxBulk {
+			return nil, fmt.Errorf("redis: bad bulk length %q", rest)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(rest)
+		if err != nil || n > maxBulk {
+			return nil, fmt.Errorf("redis: bad array length %q", rest)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]any, n)
+		for i := range items {
+			if items[i], err = c.receive(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply %q", line)
+	}
+}
+
+func (c *conn) line() (string, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(line, "\r\n"), nil
+}
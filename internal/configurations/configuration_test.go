@@ -25,6 +25,12 @@ var _ = Describe("Config Loader", func() {
 		// Clean up environment variables using double underscores.
 		os.Unsetenv("APP_DATABASE__SERVER_URI")
 		os.Unsetenv("APP_LOG__LEVEL")
+		os.Unsetenv("APP_DATABASE__DRIVER")
+		os.Unsetenv("APP_DATABASE__FAILOVER__ENABLED")
+		os.Unsetenv("APP_DATABASE__DSN")
+		os.Unsetenv("APP_DATABASE__ON_DUPLICATE")
+		os.Unsetenv("APP_REPOSITORY__TIMEOUTS__COUNTRY")
+		os.Unsetenv("APP_SERVER__PROXY_HEADER")
 	})
 
 	It("should load default configuration when no file is provided", func() {
@@ -93,4 +99,97 @@ auto_load = false
 		Expect(err).To(HaveOccurred())
 		Expect(err.Error()).To(ContainSubstring("database server_uri cannot be empty"))
 	})
+
+	It("should accept other drivers only without Trino-only features", func() {
+		os.Setenv("APP_DATABASE__DRIVER", "memory")
+		cfg, err := configurations.Load("")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.Database.Driver).To(Equal("memory"))
+
+		os.Setenv("APP_DATABASE__DRIVER", "sqlite")
+		_, err = configurations.Load("")
+		Expect(err).To(MatchError(ContainSubstring("database dsn cannot be empty with the sqlite driver")))
+
+		os.Setenv("APP_DATABASE__DSN", "/tmp/swift_codes.db")
+		cfg, err = configurations.Load("")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.Database.DSN).To(Equal("/tmp/swift_codes.db"))
+
+		os.Setenv("APP_DATABASE__FAILOVER__ENABLED", "true")
+		_, err = configurations.Load("")
+		Expect(err).To(MatchError(ContainSubstring("need the trino driver")))
+
+		os.Setenv("APP_DATABASE__DRIVER", "mysql")
+		_, err = configurations.Load("")
+		Expect(err).To(MatchError(ContainSubstring(`database driver must be one of "trino", "sqlite", "postgres" or "memory"`)))
+	})
+
+	It("should skip duplicate codes by default and reject unknown modes", func() {
+		cfg, err := configurations.Load("")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.Database.OnDuplicate).To(Equal("skip"))
+		Expect(cfg.Repository.OverwriteDuplicates).To(BeFalse())
+
+		os.Setenv("APP_DATABASE__ON_DUPLICATE", "overwrite")
+		cfg, err = configurations.Load("")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.Repository.OverwriteDuplicates).To(BeTrue())
+
+		os.Setenv("APP_DATABASE__ON_DUPLICATE", "skip")
+		os.Setenv("APP_DATABASE__DRIVER", "memory")
+		cfg, err = configurations.Load("")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.Repository.OverwriteDuplicates).To(BeTrue())
+
+		os.Setenv("APP_DATABASE__ON_DUPLICATE", "ignore")
+		_, err = configurations.Load("")
+		Expect(err).To(MatchError(ContainSubstring(`database on_duplicate must be "skip" or "overwrite"`)))
+	})
+
+	It("should reject negative repository timeouts", func() {
+		cfg, err := configurations.Load("")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.Repository.Timeouts.Country).To(BeZero())
+
+		os.Setenv("APP_REPOSITORY__TIMEOUTS__COUNTRY", "-1s")
+		_, err = configurations.Load("")
+		Expect(err).To(MatchError(ContainSubstring("repository timeouts cannot be negative")))
+	})
+
+	It("should require trusted proxies for the server proxy header", func() {
+		os.Setenv("APP_SERVER__PROXY_HEADER", "X-Forwarded-For")
+		_, err := configurations.Load("")
+		Expect(err).To(MatchError(ContainSubstring("server trusted_proxies cannot be empty")))
+	})
+
+	It("should load maintenance windows and reject inverted ones", func() {
+		load := func(content string) (*configurations.Config, error) {
+			tmpFile, err := os.CreateTemp("", "config-*.toml")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.Remove(tmpFile.Name())
+			_, err = tmpFile.Write([]byte(content))
+			Expect(err).NotTo(HaveOccurred())
+			tmpFile.Close()
+			return configurations.Load(tmpFile.Name())
+		}
+
+		cfg, err := load(`
+[[maintenance_windows]]
+start = "2026-11-01T02:00:00Z"
+end = 2026-11-01T04:00:00Z
+reason = "Trino upgrade"
+`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.MaintenanceWindows).To(HaveLen(1))
+		Expect(cfg.MaintenanceWindows[0].Start).To(Equal(time.Date(2026, 11, 1, 2, 0, 0, 0, time.UTC)))
+		Expect(cfg.MaintenanceWindows[0].End.Equal(time.Date(2026, 11, 1, 4, 0, 0, 0, time.UTC))).To(BeTrue())
+		Expect(cfg.MaintenanceWindows[0].Reason).To(Equal("Trino upgrade"))
+
+		_, err = load(`
+[[maintenance_windows]]
+start = "2026-11-01T04:00:00Z"
+end = "2026-11-01T02:00:00Z"
+`)
+		Expect(err).To(MatchError(ContainSubstring("must end after its start")))
+	})
 })
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/knadh/koanf/providers/structs"
+	"github.com/knadh/koanf/v2"
+	handler "github.com/zdziszkee/swift-codes/internal/api/handlers"
+)
+
+// Diff lists the settings, as dotted keys such as "tiers.anonymous.burst",
+// whose values differ between two configurations
+func Diff(before, after *Config) []string {
+	old, updated := flatten(before), flatten(after)
+
+	var keys []string
+	for key, value := range updated {
+		if previous, ok := old[key]; !ok || !reflect.DeepEqual(previous, value) {
+			keys = append(keys, key)
+		}
+	}
+	for key := range old {
+		if _, ok := updated[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func flatten(cfg *Config) map[string]any {
+	k := koanf.New(".")
+	_ = k.Load(structs.Provider(*cfg, "koanf"), nil)
+	return k.All()
+}
+
+// reloadable is a group of settings applied without a restart
+type reloadable struct {
+	prefix string
+	apply  func(cfg *Config)
+}
+
+// Reloader reads the configuration again and applies the settings that
+// can change while the process runs
+type Reloader struct {
+	path string
+	// running is the configuration the process started with
+	running *Config
+	// overrides reapply command line flags to every loaded configuration
+	overrides func(cfg *Config)
+
+	mu         sync.Mutex
+	last       *Config
+	reloadable []reloadable
+}
+
+// NewReloader creates a reloader for the configuration loaded from path at
+// startup. overrides, if not nil, is applied to every configuration loaded
+// as it was to the running one, e.g. for command line flags.
+func NewReloader(path string, running *Config, overrides func(cfg *Config)) *Reloader {
+	return &Reloader{path: path, running: running, overrides: overrides, last: running}
+}
+
+// OnChange makes the settings under prefix, either a key or a section,
+// reloadable: apply receives the new configuration whenever one of them
+// changes
+func (r *Reloader) OnChange(prefix string, apply func(cfg *Config)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reloadable = append(r.reloadable, reloadable{prefix: prefix, apply: apply})
+}
+
+func (r *Reloader) match(key string) *reloadable {
+	for i, group := range r.reloadable {
+		if key == group.prefix || strings.HasPrefix(key, group.prefix+".") {
+			return &r.reloadable[i]
+		}
+	}
+	return nil
+}
+
+// Reload loads and validates the configuration, applies the reloadable
+// settings that changed since the last reload and reports the others that
+// differ from the running configuration. An invalid configuration changes
+// nothing.
+func (r *Reloader) Reload() (handler.ConfigReloadResult, error) {
+	cfg, err := Load(r.path)
+	if err != nil {
+		return handler.ConfigReloadResult{}, err
+	}
+	if r.overrides != nil {
+		r.overrides(cfg)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := handler.ConfigReloadResult{Applied: []string{}, RestartRequired: []string{}}
+	var apply []*reloadable
+	for _, key := range Diff(r.last, cfg) {
+		if group := r.match(key); group != nil {
+			result.Applied = append(result.Applied, key)
+			if !slices.Contains(apply, group) {
+				apply = append(apply, group)
+			}
+		}
+	}
+	for _, key := range Diff(r.running, cfg) {
+		if r.match(key) == nil {
+			result.RestartRequired = append(result.RestartRequired, key)
+		}
+	}
+
+	for _, group := range apply {
+		group.apply(cfg)
+	}
+	r.last = cfg
+	return result, nil
+}
@@ -0,0 +1,71 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	configurations "github.com/zdziszkee/swift-codes/internal/configurations"
+)
+
+var _ = Describe("Reloader", func() {
+	var (
+		path     string
+		reloader *configurations.Reloader
+		levels   []string
+	)
+
+	write := func(content string) {
+		Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		os.Clearenv()
+		path = filepath.Join(GinkgoT().TempDir(), "config.toml")
+		write("[log]\nlevel = \"info\"\n")
+		running, err := configurations.Load(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		levels = nil
+		reloader = configurations.NewReloader(path, running, nil)
+		reloader.OnChange("log.level", func(cfg *configurations.Config) {
+			levels = append(levels, cfg.Log.Level)
+		})
+	})
+
+	It("should apply reloadable settings and list those needing a restart", func() {
+		write("[log]\nlevel = \"debug\"\n[database]\nmax_open_conns = 9\n")
+
+		result, err := reloader.Reload()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Applied).To(Equal([]string{"log.level"}))
+		Expect(result.RestartRequired).To(Equal([]string{"database.max_open_conns"}))
+		Expect(levels).To(Equal([]string{"debug"}))
+
+		// Settings already applied are not applied again, while pending
+		// restarts are still reported
+		result, err = reloader.Reload()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Applied).To(BeEmpty())
+		Expect(result.RestartRequired).To(Equal([]string{"database.max_open_conns"}))
+		Expect(levels).To(HaveLen(1))
+	})
+
+	It("should change nothing when the new configuration is invalid", func() {
+		write("[log]\nlevel = \"loud\"\n")
+
+		_, err := reloader.Reload()
+		Expect(err).To(MatchError(ContainSubstring("log level")))
+		Expect(levels).To(BeEmpty())
+	})
+
+	It("should list the keys that differ between configurations", func() {
+		before := configurations.DefaultConfig()
+		after := configurations.DefaultConfig()
+		after.Tiers.Anonymous.Burst = 3
+		after.Tiers.Anonymous.HiddenFields = []string{"phone"}
+
+		Expect(configurations.Diff(before, after)).To(Equal([]string{"tiers.anonymous.burst", "tiers.anonymous.hidden_fields"}))
+	})
+})
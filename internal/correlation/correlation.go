@@ -0,0 +1,48 @@
+// Package correlation carries who made a request and under which request
+// ID through the context, so audit rows, change events and webhook
+// deliveries can be traced back to the request that caused them.
+package correlation
+
+import (
+	"context"
+
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+)
+
+// IDs identifies the request and actor behind a piece of work. Embed it in
+// payloads that leave the process.
+type IDs struct {
+	RequestID string `json:"requestId,omitempty"`
+	// Actor is the authenticated subject; it is empty for anonymous requests
+	Actor string `json:"actor,omitempty"`
+}
+
+type actorKey struct{}
+
+// WithActor returns a context recording that actor is making the request
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor returns the actor stored with WithActor, or ""
+func Actor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// FromContext returns the IDs carried by ctx
+func FromContext(ctx context.Context) IDs {
+	return IDs{RequestID: requestid.FromContext(ctx), Actor: Actor(ctx)}
+}
+
+// NewContext returns a context carrying ids, for work that continues a
+// request elsewhere, such as a job started from a queued event
+func NewContext(ctx context.Context, ids IDs) context.Context {
+	if ids.RequestID != "" {
+		ctx = requestid.NewContext(ctx, ids.RequestID)
+	}
+	if ids.Actor != "" {
+		ctx = WithActor(ctx, ids.Actor)
+	}
+	return ctx
+}
@@ -0,0 +1,35 @@
+package correlation_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/correlation"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+)
+
+func TestCorrelation(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Correlation Suite")
+}
+
+var _ = Describe("Correlation", func() {
+	It("should read the request ID and actor of a context", func() {
+		ctx := correlation.WithActor(requestid.NewContext(context.Background(), "req-1"), "alice")
+		Expect(correlation.FromContext(ctx)).To(Equal(correlation.IDs{RequestID: "req-1", Actor: "alice"}))
+	})
+
+	It("should be empty for anonymous requests", func() {
+		Expect(correlation.FromContext(context.Background())).To(BeZero())
+	})
+
+	It("should carry IDs into another context", func() {
+		ids := correlation.IDs{RequestID: "req-2", Actor: "bob"}
+		ctx := correlation.NewContext(context.Background(), ids)
+		Expect(requestid.FromContext(ctx)).To(Equal("req-2"))
+		Expect(correlation.FromContext(ctx)).To(Equal(ids))
+	})
+})
@@ -0,0 +1,56 @@
+// Package countries provides ISO 3166-1 country metadata from an embedded
+// table, so country names and currencies do not depend on the SWIFT rows.
+package countries
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//go:embed iso3166.csv
+var iso3166CSV string
+
+// Country is an ISO 3166-1 entry
+type Country struct {
+	ISO2 string `json:"iso2"`
+	ISO3 string `json:"iso3"`
+	// Name is the ISO 3166 English short name
+	Name string `json:"name"`
+	// Currency is the ISO 4217 code of the main currency, empty for
+	// territories without one
+	Currency string `json:"currency,omitempty"`
+}
+
+var byISO2 = mustLoad(iso3166CSV)
+
+// Lookup returns the country with the alpha-2 code iso2, in any case
+func Lookup(iso2 string) (Country, bool) {
+	country, ok := byISO2[strings.ToUpper(iso2)]
+	return country, ok
+}
+
+// All returns every country ordered by alpha-2 code
+func All() []Country {
+	all := make([]Country, 0, len(byISO2))
+	for _, country := range byISO2 {
+		all = append(all, country)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ISO2 < all[j].ISO2 })
+	return all
+}
+
+// mustLoad parses the embedded table; it panics on a malformed build
+func mustLoad(data string) map[string]Country {
+	records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("countries: parse iso3166.csv: %v", err))
+	}
+	table := make(map[string]Country, len(records))
+	for _, record := range records[1:] {
+		table[record[0]] = Country{ISO2: record[0], ISO3: record[1], Name: record[2], Currency: record[3]}
+	}
+	return table
+}
@@ -0,0 +1,41 @@
+package countries_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/countries"
+)
+
+func TestCountries(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Countries Suite")
+}
+
+var _ = Describe("Countries", func() {
+	It("should look up countries case-insensitively", func() {
+		country, ok := countries.Lookup("pl")
+		Expect(ok).To(BeTrue())
+		Expect(country).To(Equal(countries.Country{ISO2: "PL", ISO3: "POL", Name: "Poland", Currency: "PLN"}))
+	})
+
+	It("should not know unassigned codes", func() {
+		_, ok := countries.Lookup("XX")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should hold every ISO 3166-1 country exactly once", func() {
+		all := countries.All()
+		Expect(all).To(HaveLen(249))
+		for i, country := range all {
+			Expect(country.ISO2).To(MatchRegexp(`^[A-Z]{2}$`))
+			Expect(country.ISO3).To(MatchRegexp(`^[A-Z]{3}$`))
+			Expect(country.Name).NotTo(BeEmpty())
+			if i > 0 {
+				Expect(country.ISO2 > all[i-1].ISO2).To(BeTrue())
+			}
+		}
+	})
+})
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/zdziszkee/swift-codes/internal/countries"
+)
+
+// BackfillCountryNames stores the upper-cased ISO 3166 name in rows whose
+// country_name is NULL or blank and returns the number of rows updated.
+// Countries missing from the ISO table are left untouched.
+func (db *Database) BackfillCountryNames(ctx context.Context) (int64, error) {
+	table := db.tableName(db.Config.TableName)
+	rows, err := db.DB.QueryContext(ctx, fmt.Sprintf(
+		"SELECT DISTINCT country_iso_code FROM %s WHERE country_name IS NULL OR trim(country_name) = ''", table))
+	if err != nil {
+		return 0, fmt.Errorf("failed to find blank country names: %w", err)
+	}
+	var codes []string
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to find blank country names: %w", err)
+		}
+		codes = append(codes, code)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to find blank country names: %w", err)
+	}
+
+	var updated int64
+	for _, code := range codes {
+		country, ok := countries.Lookup(code)
+		if !ok {
+			log.Printf("Cannot backfill country name of unknown country %q", code)
+			continue
+		}
+		result, err := db.DB.ExecContext(ctx, fmt.Sprintf(
+			"UPDATE %s SET country_name = ? WHERE country_iso_code = ? AND (country_name IS NULL OR trim(country_name) = '')", table),
+			strings.ToUpper(country.Name), code)
+		if err != nil {
+			return updated, fmt.Errorf("failed to backfill country name of %s: %w", code, err)
+		}
+		if n, err := result.RowsAffected(); err == nil {
+			updated += n
+		}
+	}
+	return updated, nil
+}
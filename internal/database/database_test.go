@@ -1,9 +1,12 @@
 package database_test
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"os"
 	"testing"
+	"time"
 
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
 	. "github.com/onsi/ginkgo/v2"
@@ -70,4 +73,181 @@ CREATE TABLE IF NOT EXISTS test2 (name VARCHAR(50));
 			Expect(err.Error()).To(ContainSubstring("failed to read schema file"))
 		})
 	})
+
+	Describe("WaitReady", func() {
+		It("should retry until a ping succeeds", func() {
+			pingDB, pingMock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+			Expect(err).NotTo(HaveOccurred())
+			defer pingDB.Close()
+
+			pingMock.ExpectPing().WillReturnError(errors.New("connection refused"))
+			pingMock.ExpectPing()
+
+			databaseInstance := &database.Database{DB: pingDB}
+			Expect(databaseInstance.WaitReady(context.Background(), time.Millisecond)).To(Succeed())
+			Expect(pingMock.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("should give up when the context ends", func() {
+			pingDB, pingMock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+			Expect(err).NotTo(HaveOccurred())
+			defer pingDB.Close()
+
+			pingMock.ExpectPing().WillReturnError(errors.New("connection refused"))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			databaseInstance := &database.Database{DB: pingDB}
+			Expect(databaseInstance.WaitReady(ctx, time.Millisecond)).To(MatchError(ContainSubstring("trino not ready")))
+		})
+	})
+
+	Describe("EnsureLayout", func() {
+		var databaseInstance *database.Database
+
+		BeforeEach(func() {
+			databaseInstance = &database.Database{DB: db, Config: database.Config{
+				Catalog: "swift_catalog", Schema: "default_schema", TableName: "swift_banks",
+			}}
+		})
+
+		expectColumns := func(names ...string) {
+			rows := sqlmock.NewRows([]string{"column_name"})
+			for _, name := range names {
+				rows.AddRow(name)
+			}
+			mockDB.ExpectQuery("information_schema.columns").
+				WithArgs("default_schema", "swift_banks").
+				WillReturnRows(rows)
+		}
+
+		It("should accept the current layout", func() {
+			expectColumns("swift_code", "swift_code_base", "is_headquarter")
+			Expect(databaseInstance.EnsureLayout(context.Background(), false)).To(Succeed())
+			Expect(mockDB.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("should accept a missing table", func() {
+			expectColumns()
+			Expect(databaseInstance.EnsureLayout(context.Background(), false)).To(Succeed())
+		})
+
+		It("should refuse a legacy table unless migration is enabled", func() {
+			expectColumns("swift_code", "hq_swift_base", "entity_type")
+			err := databaseInstance.EnsureLayout(context.Background(), false)
+			Expect(errors.Is(err, database.ErrLegacyLayout)).To(BeTrue())
+		})
+
+		It("should migrate a legacy table into the current layout", func() {
+			expectColumns("swift_code", "hq_swift_base", "entity_type", "country_iso_code", "bank_name")
+			expectColumns("swift_code", "hq_swift_base", "entity_type", "country_iso_code", "bank_name")
+			mockDB.ExpectExec(`CREATE TABLE swift_catalog\.default_schema\.swift_banks_migrated .* AS SELECT swift_code, hq_swift_base AS swift_code_base, country_iso_code, bank_name, COALESCE\(.*entity_type.*\) AS is_headquarter, CAST\(NULL AS VARCHAR\) AS address`).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mockDB.ExpectExec(`ALTER TABLE swift_catalog\.default_schema\.swift_banks RENAME TO swift_catalog\.default_schema\.swift_banks_legacy`).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mockDB.ExpectExec(`ALTER TABLE swift_catalog\.default_schema\.swift_banks_migrated RENAME TO swift_catalog\.default_schema\.swift_banks`).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+
+			Expect(databaseInstance.EnsureLayout(context.Background(), true)).To(Succeed())
+			Expect(mockDB.ExpectationsWereMet()).To(Succeed())
+		})
+	})
+	Describe("SchemaStatus", func() {
+		var databaseInstance *database.Database
+
+		BeforeEach(func() {
+			databaseInstance = &database.Database{DB: db, Config: database.Config{
+				Catalog: "swift_catalog", Schema: "default_schema", TableName: "swift_banks",
+			}}
+		})
+
+		expectColumns := func(names ...string) {
+			rows := sqlmock.NewRows([]string{"column_name"})
+			for _, name := range names {
+				rows.AddRow(name)
+			}
+			mockDB.ExpectQuery("information_schema.columns").
+				WithArgs("default_schema", "swift_banks").
+				WillReturnRows(rows)
+		}
+
+		It("should report the migrations missing from the table as pending", func() {
+			expectColumns("swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter",
+				"address", "country_name", "created_at", "updated_at", "website", "phone", "time_zone")
+
+			status, err := databaseInstance.SchemaStatus(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Table).To(Equal("swift_catalog.default_schema.swift_banks"))
+			Expect(status.Layout).To(Equal(database.LayoutCurrent))
+			Expect(status.CurrentVersion).To(Equal(2))
+			Expect(status.ExpectedVersion).To(Equal(4))
+			Expect(status.UpToDate).To(BeFalse())
+			Expect(status.Applied).To(HaveLen(3))
+			Expect(status.Pending).To(ConsistOf(HaveField("Name", "add_town_name")))
+		})
+
+		It("should be up to date when every migration's columns exist", func() {
+			var columns []string
+			for _, migration := range database.Migrations() {
+				columns = append(columns, migration.Columns...)
+			}
+			expectColumns(columns...)
+
+			status, err := databaseInstance.SchemaStatus(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.UpToDate).To(BeTrue())
+			Expect(status.CurrentVersion).To(Equal(status.ExpectedVersion))
+			Expect(status.Pending).To(BeEmpty())
+		})
+
+		It("should leave everything pending for a legacy table", func() {
+			expectColumns("swift_code", "hq_swift_base", "entity_type")
+
+			status, err := databaseInstance.SchemaStatus(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Layout).To(Equal(database.LayoutLegacy))
+			Expect(status.CurrentVersion).To(BeZero())
+			Expect(status.Applied).To(BeEmpty())
+		})
+	})
+	Describe("BackfillCountryNames", func() {
+		It("should fill blank names of known countries", func() {
+			databaseInstance := &database.Database{DB: db, Config: database.Config{
+				Catalog: "swift_catalog", Schema: "default_schema", TableName: "swift_banks",
+			}}
+			mockDB.ExpectQuery(`SELECT DISTINCT country_iso_code FROM swift_catalog\.default_schema\.swift_banks WHERE country_name IS NULL`).
+				WillReturnRows(sqlmock.NewRows([]string{"country_iso_code"}).AddRow("PL").AddRow("ZZ"))
+			mockDB.ExpectExec(`UPDATE swift_catalog\.default_schema\.swift_banks SET country_name = \?`).
+				WithArgs("POLAND", "PL").
+				WillReturnResult(sqlmock.NewResult(0, 3))
+
+			updated, err := databaseInstance.BackfillCountryNames(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated).To(Equal(int64(3)))
+			Expect(mockDB.ExpectationsWereMet()).To(Succeed())
+		})
+	})
+
+	Describe("RunMaintenance", func() {
+		It("should optimize with the configured file size threshold and no retention", func() {
+			databaseInstance := &database.Database{DB: db, Config: database.Config{
+				Catalog: "swift_catalog", Schema: "default_schema", TableName: "swift_banks",
+				Maintenance: database.MaintenanceConfig{MinRetention: time.Hour, OptimizeFileSizeThreshold: "128MB"},
+			}}
+			mockDB.ExpectExec(`ALTER TABLE swift_catalog\.default_schema\.swift_banks EXECUTE optimize\(file_size_threshold => '128MB'\)`).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+
+			retention, err := databaseInstance.RunMaintenance(context.Background(), database.TaskOptimize, time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retention).To(BeZero())
+			Expect(mockDB.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("should recognise Trino data sizes", func() {
+			Expect(database.ValidDataSize("128MB")).To(BeTrue())
+			Expect(database.ValidDataSize("1.5GB")).To(BeTrue())
+			Expect(database.ValidDataSize("128 MB")).To(BeFalse())
+			Expect(database.ValidDataSize("128MB'); DROP")).To(BeFalse())
+		})
+	})
 })
@@ -0,0 +1,292 @@
+package database
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect covers the SQL differences between the databases the SWIFT codes
+// table can live in. Statements are written for Trino with ? placeholders
+// and passed through the dialect where the others differ.
+type Dialect interface {
+	// Rebind rewrites the ? placeholders of query into the dialect's own
+	Rebind(query string) string
+	// Table qualifies a table name of cfg
+	Table(cfg Config, table string) string
+	// Window returns the clause returning limit rows after offset; a zero
+	// limit returns every row
+	Window(limit, offset int) string
+	// Position returns an expression giving the 1-based position of needle
+	// in haystack, or 0 when it does not occur
+	Position(haystack, needle string) string
+	// TimeTravel reports whether tables can be read as of a past time with
+	// FOR TIMESTAMP AS OF
+	TimeTravel() bool
+	// UpdateContacts returns a statement setting website and phone from
+	// values, rows of (swift_code, website, phone) placeholders, on the
+	// matching codes of table
+	UpdateContacts(table, values string) string
+	// Upsert returns the statements writing values, rows of UpsertColumns
+	// placeholders with unique codes, into table: stored codes are updated
+	// where a column differs and new ones inserted. Every statement takes
+	// the same arguments.
+	Upsert(table, values string) []string
+	// InsertMissing returns a statement inserting the rows of values, as
+	// for Upsert, whose codes table does not hold yet, leaving stored
+	// codes untouched
+	InsertMissing(table, values string) string
+	// CreateTable returns the statements creating table and its indexes;
+	// Trino tables are created by schema.sql instead
+	CreateTable(table string) []string
+	// CreateAliasTable is CreateTable for the table of code aliases
+	CreateAliasTable(table string) []string
+}
+
+// Dialect returns the SQL dialect of the configured driver; the memory
+// driver has none and gets Trino's
+func (c Config) Dialect() Dialect {
+	switch c.Driver {
+	case DriverSQLite:
+		return sqliteDialect{}
+	case DriverPostgres:
+		return postgresDialect{}
+	default:
+		return trinoDialect{}
+	}
+}
+
+type trinoDialect struct{}
+
+func (trinoDialect) Rebind(query string) string { return query }
+
+func (trinoDialect) Table(cfg Config, table string) string {
+	return fmt.Sprintf("%s.%s.%s", cfg.Catalog, cfg.Schema, table)
+}
+
+// Window puts OFFSET first, as Trino requires
+func (trinoDialect) Window(limit, offset int) string {
+	var clause string
+	if offset > 0 {
+		clause += fmt.Sprintf(" OFFSET %d", offset)
+	}
+	if limit > 0 {
+		clause += fmt.Sprintf(" LIMIT %d", limit)
+	}
+	return clause
+}
+
+func (trinoDialect) Position(haystack, needle string) string {
+	return fmt.Sprintf("strpos(%s, %s)", haystack, needle)
+}
+
+func (trinoDialect) TimeTravel() bool { return true }
+
+func (trinoDialect) UpdateContacts(table, values string) string {
+	return fmt.Sprintf("MERGE INTO %s t USING (VALUES %s) AS c (swift_code, website, phone) ON t.swift_code = c.swift_code "+
+		"WHEN MATCHED THEN UPDATE SET website = c.website, phone = c.phone", table, values)
+}
+
+// Upsert leaves unchanged rows alone, so that re-importing a file only
+// rewrites the Iceberg data files holding changed codes
+func (trinoDialect) Upsert(table, values string) []string {
+	return []string{mergeUpsert(table, values)}
+}
+
+func (trinoDialect) InsertMissing(table, values string) string { return antiJoinInsert(table, values) }
+
+func (trinoDialect) CreateTable(string) []string { return nil }
+
+func (trinoDialect) CreateAliasTable(string) []string { return nil }
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Rebind(query string) string { return query }
+
+// Table ignores the catalog and schema; an SQLite file holds one schema
+func (sqliteDialect) Table(_ Config, table string) string { return table }
+
+// Window always has a LIMIT, as SQLite has no OFFSET without one
+func (sqliteDialect) Window(limit, offset int) string {
+	if limit <= 0 && offset <= 0 {
+		return ""
+	}
+	if limit <= 0 {
+		limit = -1
+	}
+	clause := fmt.Sprintf(" LIMIT %d", limit)
+	if offset > 0 {
+		clause += fmt.Sprintf(" OFFSET %d", offset)
+	}
+	return clause
+}
+
+func (sqliteDialect) Position(haystack, needle string) string {
+	return fmt.Sprintf("instr(%s, %s)", haystack, needle)
+}
+
+func (sqliteDialect) TimeTravel() bool { return false }
+
+// UpdateContacts uses UPDATE ... FROM, as SQLite has no MERGE. Its VALUES
+// columns are named column1, column2 and so on.
+func (sqliteDialect) UpdateContacts(table, values string) string {
+	return fmt.Sprintf("UPDATE %s SET website = c.column2, phone = c.column3 FROM (VALUES %s) AS c WHERE %s.swift_code = c.column1",
+		table, values, table)
+}
+
+// Upsert updates and then inserts, as SQLite has no MERGE; codes updated
+// by the first statement are then stored, so the second skips them
+func (sqliteDialect) Upsert(table, values string) []string {
+	source := func(i int) string { return fmt.Sprintf("s.column%d", i+1) }
+	var set, differs []string
+	for i, column := range UpsertColumns[1:] {
+		set = append(set, fmt.Sprintf("%s = %s", column, source(i+1)))
+		differs = append(differs, fmt.Sprintf("%s.%s IS NOT %s", table, column, source(i+1)))
+	}
+	return []string{
+		fmt.Sprintf("UPDATE %s SET %s FROM (VALUES %s) AS s WHERE %s.swift_code = s.column1 AND (%s)",
+			table, strings.Join(set, ", "), values, table, strings.Join(differs, " OR ")),
+		sqliteDialect{}.InsertMissing(table, values),
+	}
+}
+
+func (sqliteDialect) InsertMissing(table, values string) string {
+	return fmt.Sprintf("INSERT INTO %s (%s) SELECT * FROM (VALUES %s) AS s WHERE NOT EXISTS (SELECT 1 FROM %s WHERE %s.swift_code = s.column1)",
+		table, strings.Join(UpsertColumns, ", "), values, table, table)
+}
+
+func (sqliteDialect) CreateTable(table string) []string { return createTable(table) }
+
+func (sqliteDialect) CreateAliasTable(table string) []string { return createAliasTable(table) }
+
+type postgresDialect struct{}
+
+// Rebind numbers the placeholders $1, $2 and so on. Statements carry no
+// string literals containing ?, so every ? is a placeholder.
+func (postgresDialect) Rebind(query string) string {
+	var b strings.Builder
+	n := 0
+	for {
+		i := strings.IndexByte(query, '?')
+		if i < 0 {
+			b.WriteString(query)
+			return b.String()
+		}
+		n++
+		b.WriteString(query[:i])
+		b.WriteByte('$')
+		b.WriteString(strconv.Itoa(n))
+		query = query[i+1:]
+	}
+}
+
+func (postgresDialect) Table(cfg Config, table string) string {
+	return cfg.Schema + "." + table
+}
+
+func (postgresDialect) Window(limit, offset int) string {
+	return trinoDialect{}.Window(limit, offset)
+}
+
+func (postgresDialect) Position(haystack, needle string) string {
+	return fmt.Sprintf("strpos(%s, %s)", haystack, needle)
+}
+
+func (postgresDialect) TimeTravel() bool { return false }
+
+func (postgresDialect) UpdateContacts(table, values string) string {
+	return fmt.Sprintf("UPDATE %s t SET website = c.website, phone = c.phone FROM (VALUES %s) AS c (swift_code, website, phone) WHERE t.swift_code = c.swift_code",
+		table, values)
+}
+
+// Upsert needs Postgres 15 or later, which added MERGE
+func (postgresDialect) Upsert(table, values string) []string {
+	return []string{mergeUpsert(table, values)}
+}
+
+func (postgresDialect) InsertMissing(table, values string) string {
+	return antiJoinInsert(table, values)
+}
+
+// CreateTable also creates the schema of table, as Postgres only has
+// public to begin with
+func (postgresDialect) CreateTable(table string) []string {
+	schema, _, _ := strings.Cut(table, ".")
+	return append([]string{"CREATE SCHEMA IF NOT EXISTS " + schema}, createTable(table)...)
+}
+
+func (postgresDialect) CreateAliasTable(table string) []string {
+	schema, _, _ := strings.Cut(table, ".")
+	return append([]string{"CREATE SCHEMA IF NOT EXISTS " + schema}, createAliasTable(table)...)
+}
+
+// UpsertColumns are the columns Upsert writes, in the order of its values;
+// contacts and timestamps are left as stored
+var UpsertColumns = []string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter",
+	"address", "country_name", "town_name", "time_zone"}
+
+// mergeUpsert returns the MERGE statement Trino and Postgres share, updating
+// a matched row only when one of its columns differs
+func mergeUpsert(table, values string) string {
+	var set, differs, inserted []string
+	for _, column := range UpsertColumns {
+		inserted = append(inserted, "s."+column)
+		if column == "swift_code" {
+			continue
+		}
+		set = append(set, fmt.Sprintf("%s = s.%s", column, column))
+		differs = append(differs, fmt.Sprintf("t.%s IS DISTINCT FROM s.%s", column, column))
+	}
+	columns := strings.Join(UpsertColumns, ", ")
+	return fmt.Sprintf("MERGE INTO %s t USING (VALUES %s) AS s (%s) ON t.swift_code = s.swift_code "+
+		"WHEN MATCHED AND (%s) THEN UPDATE SET %s "+
+		"WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s)",
+		table, values, columns, strings.Join(differs, " OR "), strings.Join(set, ", "), columns, strings.Join(inserted, ", "))
+}
+
+// antiJoinInsert returns the INSERT ... SELECT Trino and Postgres share,
+// skipping source rows whose code is stored
+func antiJoinInsert(table, values string) string {
+	columns := strings.Join(UpsertColumns, ", ")
+	return fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM (VALUES %s) AS s (%s) "+
+		"WHERE NOT EXISTS (SELECT 1 FROM %s t WHERE t.swift_code = s.swift_code)",
+		table, columns, columns, values, columns, table)
+}
+
+// createTable returns the DDL of the SWIFT banks table in the types SQLite
+// and Postgres share. Like the Iceberg table it has no primary key; the
+// indexes serve lookups by code, code base and country.
+func createTable(table string) []string {
+	index := strings.ReplaceAll(table, ".", "_")
+	return []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+    swift_code TEXT,
+    swift_code_base TEXT,
+    country_iso_code TEXT,
+    bank_name TEXT,
+    is_headquarter BOOLEAN,
+    address TEXT,
+    country_name TEXT,
+    website TEXT,
+    phone TEXT,
+    town_name TEXT,
+    time_zone TEXT,
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP
+)`, table),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_swift_code ON %s (swift_code)", index, table),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_swift_code_base ON %s (swift_code_base)", index, table),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_country_iso_code ON %s (country_iso_code)", index, table),
+	}
+}
+
+// createAliasTable returns the DDL of the alias table, indexed both ways
+// for resolving an alias and listing the aliases of a code
+func createAliasTable(table string) []string {
+	index := strings.ReplaceAll(table, ".", "_")
+	return []string{
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (alias TEXT, swift_code TEXT)", table),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_alias ON %s (alias)", index, table),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_swift_code ON %s (swift_code)", index, table),
+	}
+}
@@ -0,0 +1,80 @@
+package database_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/database"
+)
+
+var _ = Describe("Dialect", func() {
+	cfg := database.Config{Catalog: "iceberg", Schema: "swift", TableName: "swift_codes"}
+
+	dialect := func(driver string) database.Dialect {
+		c := cfg
+		c.Driver = driver
+		return c.Dialect()
+	}
+
+	It("should qualify tables per driver", func() {
+		Expect(dialect(database.DriverTrino).Table(cfg, "swift_codes")).To(Equal("iceberg.swift.swift_codes"))
+		Expect(dialect(database.DriverPostgres).Table(cfg, "swift_codes")).To(Equal("swift.swift_codes"))
+		Expect(dialect(database.DriverSQLite).Table(cfg, "swift_codes")).To(Equal("swift_codes"))
+	})
+
+	It("should number Postgres placeholders and leave the others alone", func() {
+		query := "SELECT * FROM t WHERE a = ? AND b IN (?, ?)"
+		Expect(dialect(database.DriverPostgres).Rebind(query)).To(Equal("SELECT * FROM t WHERE a = $1 AND b IN ($2, $3)"))
+		Expect(dialect(database.DriverSQLite).Rebind(query)).To(Equal(query))
+		Expect(dialect(database.DriverTrino).Rebind(query)).To(Equal(query))
+	})
+
+	It("should give SQLite a LIMIT whenever it pages", func() {
+		Expect(dialect(database.DriverSQLite).Window(0, 0)).To(BeEmpty())
+		Expect(dialect(database.DriverSQLite).Window(0, 20)).To(Equal(" LIMIT -1 OFFSET 20"))
+		Expect(dialect(database.DriverSQLite).Window(10, 20)).To(Equal(" LIMIT 10 OFFSET 20"))
+		Expect(dialect(database.DriverTrino).Window(10, 20)).To(Equal(" OFFSET 20 LIMIT 10"))
+	})
+
+	It("should only allow time travel on Trino", func() {
+		Expect(dialect(database.DriverTrino).TimeTravel()).To(BeTrue())
+		Expect(dialect(database.DriverSQLite).TimeTravel()).To(BeFalse())
+		Expect(dialect(database.DriverPostgres).TimeTravel()).To(BeFalse())
+	})
+
+	It("should upsert with one MERGE touching only changed rows, or update then insert on SQLite", func() {
+		statements := dialect(database.DriverTrino).Upsert("iceberg.swift.swift_codes", "(?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		Expect(statements).To(HaveLen(1))
+		Expect(statements[0]).To(HavePrefix("MERGE INTO iceberg.swift.swift_codes t USING (VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)) AS s (swift_code, "))
+		Expect(statements[0]).To(ContainSubstring("WHEN MATCHED AND (t.swift_code_base IS DISTINCT FROM s.swift_code_base OR "))
+		Expect(statements[0]).NotTo(ContainSubstring("SET swift_code ="))
+		Expect(statements[0]).To(HaveSuffix("VALUES (s.swift_code, s.swift_code_base, s.country_iso_code, s.bank_name, s.is_headquarter, s.address, s.country_name, s.town_name, s.time_zone)"))
+		Expect(dialect(database.DriverPostgres).Upsert("swift.swift_codes", "(?)")).To(HaveLen(1))
+
+		statements = dialect(database.DriverSQLite).Upsert("swift_codes", "(?)")
+		Expect(statements).To(HaveLen(2))
+		Expect(statements[0]).To(HavePrefix("UPDATE swift_codes SET swift_code_base = s.column2, "))
+		Expect(statements[1]).To(HaveSuffix("WHERE NOT EXISTS (SELECT 1 FROM swift_codes WHERE swift_codes.swift_code = s.column1)"))
+	})
+
+	It("should insert missing codes with an anti-join against the table", func() {
+		statement := dialect(database.DriverTrino).InsertMissing("iceberg.swift.swift_codes", "(?)")
+		Expect(statement).To(HavePrefix("INSERT INTO iceberg.swift.swift_codes (swift_code, "))
+		Expect(statement).To(ContainSubstring(" FROM (VALUES (?)) AS s (swift_code, "))
+		Expect(statement).To(HaveSuffix("WHERE NOT EXISTS (SELECT 1 FROM iceberg.swift.swift_codes t WHERE t.swift_code = s.swift_code)"))
+		Expect(dialect(database.DriverPostgres).InsertMissing("swift.swift_codes", "(?)")).To(HaveSuffix("t.swift_code = s.swift_code)"))
+		Expect(dialect(database.DriverSQLite).InsertMissing("swift_codes", "(?)")).To(HaveSuffix("WHERE swift_codes.swift_code = s.column1)"))
+	})
+
+	It("should create the Postgres schema before its table", func() {
+		statements := dialect(database.DriverPostgres).CreateTable("swift.swift_codes")
+		Expect(statements[0]).To(Equal("CREATE SCHEMA IF NOT EXISTS swift"))
+		Expect(statements[1]).To(HavePrefix("CREATE TABLE IF NOT EXISTS swift.swift_codes"))
+		Expect(dialect(database.DriverTrino).CreateTable("swift_codes")).To(BeEmpty())
+
+		statements = dialect(database.DriverPostgres).CreateAliasTable("swift.swift_code_aliases")
+		Expect(statements[0]).To(Equal("CREATE SCHEMA IF NOT EXISTS swift"))
+		Expect(statements[1]).To(Equal("CREATE TABLE IF NOT EXISTS swift.swift_code_aliases (alias TEXT, swift_code TEXT)"))
+		Expect(dialect(database.DriverTrino).CreateAliasTable("swift_code_aliases")).To(BeEmpty())
+	})
+})
@@ -0,0 +1,162 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Layout identifies the column set of the SWIFT banks table
+type Layout string
+
+// Table layouts recognized by DetectLayout
+const (
+	// LayoutMissing means the table does not exist yet
+	LayoutMissing Layout = "missing"
+	// LayoutCurrent has swift_code_base and is_headquarter columns
+	LayoutCurrent Layout = "current"
+	// LayoutLegacy has hq_swift_base and entity_type columns instead
+	LayoutLegacy Layout = "legacy"
+	// LayoutUnknown matches neither column set
+	LayoutUnknown Layout = "unknown"
+)
+
+// ErrLegacyLayout is returned by EnsureLayout when migration is disabled
+var ErrLegacyLayout = errors.New("SWIFT banks table uses the legacy layout")
+
+// currentColumns lists the current layout with the type used when a column
+// has no legacy counterpart; it holds the columns of every migration
+var currentColumns = []struct {
+	name string
+	typ  string
+}{
+	{"swift_code", "VARCHAR"},
+	{"swift_code_base", "VARCHAR"},
+	{"country_iso_code", "VARCHAR"},
+	{"bank_name", "VARCHAR"},
+	{"is_headquarter", "BOOLEAN"},
+	{"address", "VARCHAR"},
+	{"country_name", "VARCHAR"},
+	{"website", "VARCHAR"},
+	{"phone", "VARCHAR"},
+	{"town_name", "VARCHAR"},
+	{"time_zone", "VARCHAR"},
+	{"created_at", "TIMESTAMP"},
+	{"updated_at", "TIMESTAMP"},
+}
+
+// legacyExpressions map legacy columns onto the current ones. A legacy row
+// is a headquarters when entity_type says so or, if it is NULL, when the
+// code ends in XXX.
+var legacyExpressions = map[string]string{
+	"swift_code_base": "hq_swift_base",
+	"is_headquarter":  "COALESCE(UPPER(TRIM(entity_type)) IN ('HQ', 'HEADQUARTER', 'HEADQUARTERS'), swift_code LIKE '%XXX')",
+}
+
+func (db *Database) tableName(table string) string {
+	return fmt.Sprintf("%s.%s.%s", db.Config.Catalog, db.Config.Schema, table)
+}
+
+// columns returns the lower-cased column names of table, empty when the
+// table does not exist
+func (db *Database) columns(ctx context.Context, table string) (map[string]bool, error) {
+	query := fmt.Sprintf("SELECT column_name FROM %s.information_schema.columns WHERE table_schema = ? AND table_name = ?", db.Config.Catalog)
+	rows, err := db.DB.QueryContext(ctx, query, db.Config.Schema, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect table columns: %w", err)
+	}
+	defer rows.Close()
+
+	columns := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to inspect table columns: %w", err)
+		}
+		columns[strings.ToLower(name)] = true
+	}
+	return columns, rows.Err()
+}
+
+// DetectLayout reports which column set the configured table uses
+func (db *Database) DetectLayout(ctx context.Context) (Layout, error) {
+	columns, err := db.columns(ctx, db.Config.TableName)
+	if err != nil {
+		return "", err
+	}
+	return layoutOf(columns), nil
+}
+
+// layoutOf identifies the layout of a table from its column names
+func layoutOf(columns map[string]bool) Layout {
+	switch {
+	case len(columns) == 0:
+		return LayoutMissing
+	case columns["swift_code_base"] && columns["is_headquarter"]:
+		return LayoutCurrent
+	case columns["hq_swift_base"] && columns["entity_type"]:
+		return LayoutLegacy
+	default:
+		return LayoutUnknown
+	}
+}
+
+// MigrateLegacy copies a legacy table into the current layout with CREATE
+// TABLE AS, then swaps the tables. The original is kept as <table>_legacy.
+func (db *Database) MigrateLegacy(ctx context.Context) error {
+	legacy, err := db.columns(ctx, db.Config.TableName)
+	if err != nil {
+		return err
+	}
+
+	selects := make([]string, 0, len(currentColumns))
+	for _, col := range currentColumns {
+		switch expr, mapped := legacyExpressions[col.name]; {
+		case mapped:
+			selects = append(selects, expr+" AS "+col.name)
+		case legacy[col.name]:
+			selects = append(selects, col.name)
+		default:
+			selects = append(selects, fmt.Sprintf("CAST(NULL AS %s) AS %s", col.typ, col.name))
+		}
+	}
+
+	table := db.Config.TableName
+	statements := []string{
+		fmt.Sprintf("CREATE TABLE %s WITH (partitioning = ARRAY['country_iso_code']) AS SELECT %s FROM %s",
+			db.tableName(table+"_migrated"), strings.Join(selects, ", "), db.tableName(table)),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", db.tableName(table), db.tableName(table+"_legacy")),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", db.tableName(table+"_migrated"), db.tableName(table)),
+	}
+	for _, statement := range statements {
+		log.Printf("Migrating legacy table: %s", statement)
+		if _, err := db.DB.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("legacy table migration failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// EnsureLayout checks the table layout before the schema is applied. A
+// legacy table is migrated when migrate is set and reported as
+// ErrLegacyLayout otherwise.
+func (db *Database) EnsureLayout(ctx context.Context, migrate bool) error {
+	layout, err := db.DetectLayout(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch layout {
+	case LayoutLegacy:
+		if !migrate {
+			return fmt.Errorf("%w; set database.migrate_legacy = true to convert it", ErrLegacyLayout)
+		}
+		return db.MigrateLegacy(ctx)
+	case LayoutUnknown:
+		return fmt.Errorf("SWIFT banks table %s has an unrecognized column layout", db.tableName(db.Config.TableName))
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+)
+
+// MaintenanceConfig holds the safety settings for Iceberg table maintenance
+type MaintenanceConfig struct {
+	// MinRetention is the shortest retention threshold a task accepts. Files
+	// and snapshots younger than the threshold are never removed, so running
+	// queries and in-flight writes keep the data they reference.
+	MinRetention time.Duration `koanf:"min_retention"`
+	// OptimizeFileSizeThreshold is the size, such as "128MB", below which
+	// optimize rewrites data files; empty keeps the Trino default
+	OptimizeFileSizeThreshold string `koanf:"optimize_file_size_threshold"`
+}
+
+// dataSizeRegex matches a Trino data size such as "128MB" or "1.5GB"
+var dataSizeRegex = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?(B|kB|MB|GB|TB|PB)$`)
+
+// ValidDataSize reports whether size is a Trino data size such as "128MB"
+func ValidDataSize(size string) bool {
+	return dataSizeRegex.MatchString(size)
+}
+
+// MaintenanceTask names an Iceberg table procedure run through ALTER TABLE
+// EXECUTE
+type MaintenanceTask string
+
+// Supported maintenance tasks
+const (
+	// TaskExpireSnapshots drops snapshots older than the retention threshold
+	TaskExpireSnapshots MaintenanceTask = "expire_snapshots"
+	// TaskRemoveOrphanFiles deletes data and metadata files that no snapshot
+	// references
+	TaskRemoveOrphanFiles MaintenanceTask = "remove_orphan_files"
+	// TaskOptimize compacts small data files, such as those left by batched
+	// inserts, into larger ones; it takes no retention
+	TaskOptimize MaintenanceTask = "optimize"
+)
+
+var (
+	// ErrUnknownTask is returned for a task that is not a MaintenanceTask
+	ErrUnknownTask = errors.New("unknown maintenance task")
+	// ErrRetentionTooShort is returned when a retention is below
+	// MaintenanceConfig.MinRetention
+	ErrRetentionTooShort = errors.New("retention is below the configured minimum")
+)
+
+// ParseMaintenanceTask validates a task name
+func ParseMaintenanceTask(name string) (MaintenanceTask, error) {
+	switch task := MaintenanceTask(name); task {
+	case TaskExpireSnapshots, TaskRemoveOrphanFiles, TaskOptimize:
+		return task, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownTask, name)
+	}
+}
+
+// RunMaintenance executes task on the SWIFT banks table, keeping everything
+// newer than retention. A zero retention uses the configured minimum. The
+// returned retention is zero for optimize, which ignores it.
+func (db *Database) RunMaintenance(ctx context.Context, task MaintenanceTask, retention time.Duration) (time.Duration, error) {
+	if _, err := ParseMaintenanceTask(string(task)); err != nil {
+		return 0, err
+	}
+
+	var query string
+	if task == TaskOptimize {
+		retention = 0
+		query = fmt.Sprintf("ALTER TABLE %s EXECUTE optimize", db.tableName(db.Config.TableName))
+		if threshold := db.Config.Maintenance.OptimizeFileSizeThreshold; threshold != "" {
+			query += fmt.Sprintf("(file_size_threshold => '%s')", threshold)
+		}
+	} else {
+		if retention == 0 {
+			retention = db.Config.Maintenance.MinRetention
+		}
+		if retention < db.Config.Maintenance.MinRetention {
+			return 0, fmt.Errorf("%w: %s < %s", ErrRetentionTooShort, retention, db.Config.Maintenance.MinRetention)
+		}
+		query = fmt.Sprintf("ALTER TABLE %s EXECUTE %s(retention_threshold => '%ds')",
+			db.tableName(db.Config.TableName), task, int64(retention.Seconds()))
+	}
+	log.Printf("Running table maintenance: %s", query)
+	if _, err := db.DB.ExecContext(ctx, query); err != nil {
+		return 0, fmt.Errorf("%s failed: %w", task, err)
+	}
+	return retention, nil
+}
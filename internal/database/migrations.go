@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"slices"
+)
+
+// Migration is a change schema.sql makes to the SWIFT banks table, in
+// release order. Iceberg keeps no schema version of its own, so a
+// migration counts as applied once all of its columns exist.
+type Migration struct {
+	Version int      `json:"version"`
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+}
+
+// migrations must stay in step with schema.sql and currentColumns
+var migrations = []Migration{
+	{Version: 1, Name: "create_swift_banks", Columns: []string{
+		"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter",
+		"address", "country_name", "created_at", "updated_at",
+	}},
+	{Version: 2, Name: "add_contacts", Columns: []string{"website", "phone"}},
+	{Version: 3, Name: "add_town_name", Columns: []string{"town_name"}},
+	{Version: 4, Name: "add_time_zone", Columns: []string{"time_zone"}},
+}
+
+// Migrations returns the schema changes this binary expects, oldest first
+func Migrations() []Migration {
+	return slices.Clone(migrations)
+}
+
+// SchemaStatus compares the SWIFT banks table with the migrations this
+// binary expects
+type SchemaStatus struct {
+	Table  string `json:"table"`
+	Layout Layout `json:"layout"`
+	// CurrentVersion is the last migration applied without a gap before it
+	CurrentVersion  int         `json:"current_version"`
+	ExpectedVersion int         `json:"expected_version"`
+	UpToDate        bool        `json:"up_to_date"`
+	Applied         []Migration `json:"applied"`
+	Pending         []Migration `json:"pending"`
+}
+
+// SchemaStatus inspects the columns of the configured table and reports
+// which migrations have been applied. A legacy table lacks the columns of
+// the first migration, so everything is pending until it is converted.
+func (db *Database) SchemaStatus(ctx context.Context) (*SchemaStatus, error) {
+	columns, err := db.columns(ctx, db.Config.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &SchemaStatus{
+		Table:           db.tableName(db.Config.TableName),
+		Layout:          layoutOf(columns),
+		ExpectedVersion: migrations[len(migrations)-1].Version,
+		Applied:         []Migration{},
+		Pending:         []Migration{},
+	}
+	for _, migration := range migrations {
+		applied := true
+		for _, column := range migration.Columns {
+			applied = applied && columns[column]
+		}
+		if !applied {
+			status.Pending = append(status.Pending, migration)
+			continue
+		}
+		status.Applied = append(status.Applied, migration)
+		if len(status.Pending) == 0 {
+			status.CurrentVersion = migration.Version
+		}
+	}
+	status.UpToDate = len(status.Pending) == 0
+	return status, nil
+}
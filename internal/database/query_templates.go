@@ -0,0 +1,271 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Row limits of analytical template queries
+const (
+	DefaultTemplateRows = 100
+	MaxTemplateRows     = 1000
+	maxTextParamLength  = 100
+)
+
+// ParamType says how a template parameter is validated and bound
+type ParamType string
+
+// Supported template parameter types
+const (
+	// ParamCountry is an ISO 3166-1 alpha-2 code, matched case-insensitively
+	ParamCountry ParamType = "country"
+	// ParamPrefix is free text matched as a case-insensitive prefix
+	ParamPrefix ParamType = "prefix"
+	// ParamLimit caps the number of rows returned
+	ParamLimit ParamType = "limit"
+)
+
+var (
+	// ErrUnknownTemplate is returned for a name that is not a QueryTemplate
+	ErrUnknownTemplate = errors.New("unknown query template")
+	countryParam       = regexp.MustCompile(`^[A-Z]{2}$`)
+)
+
+// ParamError reports a template parameter that is missing, unknown or
+// malformed
+type ParamError struct {
+	Param  string
+	Reason string
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("invalid query parameter %s: %s", e.Param, e.Reason)
+}
+
+// TemplateParam describes a parameter accepted by a QueryTemplate
+type TemplateParam struct {
+	Name        string    `json:"name"`
+	Type        ParamType `json:"type"`
+	Required    bool      `json:"required"`
+	Description string    `json:"description"`
+}
+
+// QueryTemplate is a vetted analytical query. Callers only choose the
+// template and its parameter values; the SQL never comes from a request.
+type QueryTemplate struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Params      []TemplateParam `json:"params"`
+
+	// sql is formatted with the table name and the row limit, in that order
+	sql string
+	// bind names the parameter of every ? placeholder in sql, in order
+	bind []string
+}
+
+var limitParam = TemplateParam{
+	Name:        "limit",
+	Type:        ParamLimit,
+	Description: fmt.Sprintf("maximum rows to return, 1-%d (default %d)", MaxTemplateRows, DefaultTemplateRows),
+}
+
+var optionalCountryParam = TemplateParam{
+	Name:        "country",
+	Type:        ParamCountry,
+	Description: "restrict to an ISO 3166-1 alpha-2 country code",
+}
+
+var queryTemplates = []QueryTemplate{
+	{
+		Name:        "codes_per_country",
+		Description: "Number of SWIFT codes and headquarters per country, largest first",
+		Params:      []TemplateParam{limitParam},
+		sql: `SELECT country_iso_code, country_name, COUNT(*) AS codes, COUNT_IF(is_headquarter) AS headquarters
+FROM %[1]s
+GROUP BY country_iso_code, country_name
+ORDER BY codes DESC, country_iso_code
+LIMIT %[2]d`,
+	},
+	{
+		Name:        "largest_branch_networks",
+		Description: "Headquarters with the most branches",
+		Params:      []TemplateParam{optionalCountryParam, limitParam},
+		sql: `SELECT h.swift_code, h.bank_name, h.country_iso_code, COUNT(b.swift_code) AS branches
+FROM %[1]s h
+LEFT JOIN %[1]s b ON h.swift_code_base = b.swift_code_base AND b.is_headquarter = FALSE
+WHERE h.is_headquarter = TRUE AND (? = '' OR h.country_iso_code = ?)
+GROUP BY h.swift_code, h.bank_name, h.country_iso_code
+ORDER BY branches DESC, h.swift_code
+LIMIT %[2]d`,
+		bind: []string{"country", "country"},
+	},
+	{
+		Name:        "banks_by_name_prefix",
+		Description: "SWIFT codes of banks whose name starts with a prefix",
+		Params: []TemplateParam{
+			{Name: "prefix", Type: ParamPrefix, Required: true, Description: "start of the bank name"},
+			optionalCountryParam,
+			limitParam,
+		},
+		sql: `SELECT swift_code, bank_name, country_iso_code, is_headquarter
+FROM %[1]s
+WHERE upper(bank_name) LIKE ? ESCAPE '\' AND (? = '' OR country_iso_code = ?)
+ORDER BY bank_name, swift_code
+LIMIT %[2]d`,
+		bind: []string{"prefix", "country", "country"},
+	},
+	{
+		Name:        "orphan_branches",
+		Description: "Branches whose headquarter code is not in the dataset",
+		Params:      []TemplateParam{optionalCountryParam, limitParam},
+		sql: `SELECT b.swift_code, b.bank_name, b.country_iso_code
+FROM %[1]s b
+LEFT JOIN %[1]s h ON h.swift_code_base = b.swift_code_base AND h.is_headquarter = TRUE
+WHERE b.is_headquarter = FALSE AND h.swift_code IS NULL AND (? = '' OR b.country_iso_code = ?)
+ORDER BY b.swift_code
+LIMIT %[2]d`,
+		bind: []string{"country", "country"},
+	},
+}
+
+// QueryTemplates returns the analytical query templates sorted by name
+func QueryTemplates() []QueryTemplate {
+	templates := append([]QueryTemplate(nil), queryTemplates...)
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates
+}
+
+// LookupQueryTemplate returns the template called name
+func LookupQueryTemplate(name string) (QueryTemplate, error) {
+	for _, template := range queryTemplates {
+		if template.Name == name {
+			return template, nil
+		}
+	}
+	return QueryTemplate{}, fmt.Errorf("%w: %q", ErrUnknownTemplate, name)
+}
+
+// QueryColumn describes a column of a QueryResult
+type QueryColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// QueryResult is the tabular output of a template query
+type QueryResult struct {
+	Template string        `json:"template"`
+	Columns  []QueryColumn `json:"columns"`
+	Rows     [][]any       `json:"rows"`
+}
+
+// RunTemplate validates params against the template called name and runs
+// it on the SWIFT banks table. Parameter values are always bound as
+// statement arguments; only the validated row limit is formatted into the
+// statement.
+func (db *Database) RunTemplate(ctx context.Context, name string, params map[string]string) (*QueryResult, error) {
+	template, err := LookupQueryTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+	values, limit, err := template.bindParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(template.sql, db.tableName(db.Config.TableName), limit)
+	rows, err := db.DB.QueryContext(ctx, query, values...)
+	if err != nil {
+		return nil, fmt.Errorf("query template %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	result := &QueryResult{Template: name, Columns: make([]QueryColumn, len(columnTypes)), Rows: [][]any{}}
+	for i, column := range columnTypes {
+		result.Columns[i] = QueryColumn{Name: column.Name(), Type: strings.ToLower(column.DatabaseTypeName())}
+	}
+
+	for rows.Next() {
+		row := make([]any, len(columnTypes))
+		targets := make([]any, len(row))
+		for i := range row {
+			targets[i] = &row[i]
+		}
+		if err := rows.Scan(targets...); err != nil {
+			return nil, err
+		}
+		for i, value := range row {
+			if raw, ok := value.([]byte); ok {
+				row[i] = string(raw)
+			}
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	return result, rows.Err()
+}
+
+// bindParams validates params and returns the statement arguments and the
+// row limit
+func (t QueryTemplate) bindParams(params map[string]string) ([]any, int, error) {
+	declared := make(map[string]TemplateParam, len(t.Params))
+	for _, param := range t.Params {
+		declared[param.Name] = param
+	}
+	for name := range params {
+		if _, ok := declared[name]; !ok {
+			return nil, 0, &ParamError{Param: name, Reason: "is not a parameter of " + t.Name}
+		}
+	}
+
+	limit := DefaultTemplateRows
+	bound := make(map[string]any, len(t.Params))
+	for _, param := range t.Params {
+		raw := strings.TrimSpace(params[param.Name])
+		if raw == "" {
+			if param.Required {
+				return nil, 0, &ParamError{Param: param.Name, Reason: "is required"}
+			}
+			bound[param.Name] = ""
+			continue
+		}
+
+		switch param.Type {
+		case ParamCountry:
+			value := strings.ToUpper(raw)
+			if !countryParam.MatchString(value) {
+				return nil, 0, &ParamError{Param: param.Name, Reason: "must be a 2-letter country code"}
+			}
+			bound[param.Name] = value
+		case ParamPrefix:
+			if len(raw) > maxTextParamLength {
+				return nil, 0, &ParamError{Param: param.Name, Reason: fmt.Sprintf("must be at most %d characters", maxTextParamLength)}
+			}
+			bound[param.Name] = escapeLike(strings.ToUpper(raw)) + "%"
+		case ParamLimit:
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 1 || n > MaxTemplateRows {
+				return nil, 0, &ParamError{Param: param.Name, Reason: fmt.Sprintf("must be an integer between 1 and %d", MaxTemplateRows)}
+			}
+			limit = n
+		}
+	}
+
+	values := make([]any, len(t.bind))
+	for i, name := range t.bind {
+		values[i] = bound[name]
+	}
+	return values, limit, nil
+}
+
+// escapeLike escapes the LIKE wildcards of s for ESCAPE '\'
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
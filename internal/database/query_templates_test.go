@@ -0,0 +1,92 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/zdziszkee/swift-codes/internal/database"
+)
+
+var _ = Describe("RunTemplate", func() {
+	var (
+		mockDB sqlmock.Sqlmock
+		db     *database.Database
+	)
+
+	BeforeEach(func() {
+		conn, mock, err := sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() { _ = conn.Close() })
+		mockDB = mock
+		db = &database.Database{DB: conn, Config: database.Config{
+			Catalog:   "swift_catalog",
+			Schema:    "default_schema",
+			TableName: "swift_banks",
+		}}
+	})
+
+	It("should bind parameters and describe the result columns", func() {
+		rows := sqlmock.NewRowsWithColumnDefinition(
+			sqlmock.NewColumn("swift_code").OfType("VARCHAR", ""),
+			sqlmock.NewColumn("bank_name").OfType("VARCHAR", ""),
+			sqlmock.NewColumn("country_iso_code").OfType("VARCHAR", ""),
+			sqlmock.NewColumn("is_headquarter").OfType("BOOLEAN", false),
+		).AddRow([]byte("PKOPPLPWXXX"), "PKO BANK POLSKI", "PL", true)
+		mockDB.ExpectQuery(`FROM swift_catalog\.default_schema\.swift_banks\s+WHERE upper\(bank_name\) LIKE \? ESCAPE '\\' .*LIMIT 50`).
+			WithArgs(`PKO\_%`, "PL", "PL").
+			WillReturnRows(rows)
+
+		result, err := db.RunTemplate(context.Background(), "banks_by_name_prefix",
+			map[string]string{"prefix": "pko_", "country": "pl", "limit": "50"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Columns).To(Equal([]database.QueryColumn{
+			{Name: "swift_code", Type: "varchar"},
+			{Name: "bank_name", Type: "varchar"},
+			{Name: "country_iso_code", Type: "varchar"},
+			{Name: "is_headquarter", Type: "boolean"},
+		}))
+		Expect(result.Rows).To(Equal([][]any{{"PKOPPLPWXXX", "PKO BANK POLSKI", "PL", true}}))
+		Expect(mockDB.ExpectationsWereMet()).To(Succeed())
+	})
+
+	It("should default optional parameters and the row limit", func() {
+		mockDB.ExpectQuery(`LIMIT 100$`).
+			WithArgs("", "").
+			WillReturnRows(sqlmock.NewRows([]string{"swift_code", "bank_name", "country_iso_code", "branches"}))
+
+		result, err := db.RunTemplate(context.Background(), "largest_branch_networks", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Rows).To(BeEmpty())
+		Expect(mockDB.ExpectationsWereMet()).To(Succeed())
+	})
+
+	DescribeTable("should reject invalid parameters without querying",
+		func(params map[string]string, param string) {
+			_, err := db.RunTemplate(context.Background(), "banks_by_name_prefix", params)
+			var paramErr *database.ParamError
+			Expect(errors.As(err, &paramErr)).To(BeTrue())
+			Expect(paramErr.Param).To(Equal(param))
+			Expect(mockDB.ExpectationsWereMet()).To(Succeed())
+		},
+		Entry("missing required", map[string]string{}, "prefix"),
+		Entry("unknown", map[string]string{"prefix": "PKO", "sql": "DROP TABLE"}, "sql"),
+		Entry("bad country", map[string]string{"prefix": "PKO", "country": "POL"}, "country"),
+		Entry("limit too high", map[string]string{"prefix": "PKO", "limit": "5000"}, "limit"),
+		Entry("limit not a number", map[string]string{"prefix": "PKO", "limit": "10; DROP"}, "limit"),
+	)
+
+	It("should reject unknown templates", func() {
+		_, err := db.RunTemplate(context.Background(), "drop_everything", nil)
+		Expect(err).To(MatchError(database.ErrUnknownTemplate))
+	})
+
+	It("should wrap query errors", func() {
+		mockDB.ExpectQuery("SELECT").WillReturnError(sql.ErrConnDone)
+		_, err := db.RunTemplate(context.Background(), "codes_per_country", nil)
+		Expect(err).To(MatchError(sql.ErrConnDone))
+	})
+})
@@ -0,0 +1,145 @@
+// Package events fans dataset changes out to in-process subscribers, such
+// as the server-sent events stream.
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+// Event is a change numbered in publishing order
+type Event struct {
+	ID         uint64
+	OccurredAt time.Time
+	Change     service.Change
+}
+
+// Bus delivers every published change to all current subscribers. A
+// subscriber that falls behind by more than the buffer is disconnected
+// rather than silently missing events, so it knows to resynchronize. The
+// latest events are also kept, so that pollers can ask for what they
+// missed since an event ID.
+type Bus struct {
+	mu     sync.Mutex
+	buffer int
+	nextID uint64
+	subs   map[chan Event]struct{}
+	closed bool
+	// history holds the latest events in publishing order, at most retain
+	history []Event
+	retain  int
+	// published is closed and replaced by every publish
+	published chan struct{}
+}
+
+// NewBus creates a bus that buffers up to buffer events per subscriber and
+// keeps the latest history events for After
+func NewBus(buffer, history int) *Bus {
+	if buffer < 1 {
+		buffer = 1
+	}
+	return &Bus{buffer: buffer, subs: make(map[chan Event]struct{}), retain: max(history, 0), published: make(chan struct{})}
+}
+
+// Publish sends change to every subscriber without blocking; it has the
+// service.ChangeHook signature
+func (b *Bus) Publish(_ context.Context, change service.Change) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+
+	b.nextID++
+	event := Event{ID: b.nextID, OccurredAt: time.Now().UTC(), Change: change}
+	if b.retain > 0 {
+		if len(b.history) == b.retain {
+			b.history = append(b.history[:0], b.history[1:]...)
+		}
+		b.history = append(b.history, event)
+	}
+	close(b.published)
+	b.published = make(chan struct{})
+	for ch := range b.subs {
+		select {
+		case ch <- event:
+		default:
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe returns a channel of events published from now on and a
+// function that ends the subscription. The channel is closed when the
+// subscription ends, the subscriber falls behind or the bus is closed.
+func (b *Bus) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, b.buffer)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	b.subs[ch] = struct{}{}
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// After returns the kept events published after the event with ID id and a
+// channel closed by the next publish, or by Close. ok is false when events
+// after id are no longer kept or id was never published, e.g. by an earlier
+// run of the server; the caller has then missed changes.
+func (b *Bus) After(id uint64) (events []Event, next <-chan struct{}, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if id > b.nextID {
+		return nil, b.published, false
+	}
+	missing := b.nextID - id
+	if missing > uint64(len(b.history)) {
+		return nil, b.published, false
+	}
+	kept := b.history[len(b.history)-int(missing):]
+	return append([]Event(nil), kept...), b.published, true
+}
+
+// LastID returns the ID of the latest published event, 0 before any
+func (b *Bus) LastID() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.nextID
+}
+
+// Subscribers returns the number of active subscriptions
+func (b *Bus) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
+
+// Close ends every subscription; later publishes are ignored
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	close(b.published)
+	for ch := range b.subs {
+		delete(b.subs, ch)
+		close(ch)
+	}
+}
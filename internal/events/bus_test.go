@@ -0,0 +1,105 @@
+package events_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/events"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+func TestEvents(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Events Suite")
+}
+
+var _ = Describe("Bus", func() {
+	var (
+		ctx context.Context
+		bus *events.Bus
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		bus = events.NewBus(2, 2)
+	})
+
+	It("should deliver numbered events to every subscriber", func() {
+		first, cancelFirst := bus.Subscribe()
+		defer cancelFirst()
+		second, cancelSecond := bus.Subscribe()
+		defer cancelSecond()
+
+		bus.Publish(ctx, service.Change{Type: service.ChangeCreated, SwiftCode: "PKOPPLPWXXX"})
+		bus.Publish(ctx, service.Change{Type: service.ChangeDeleted, SwiftCode: "PKOPPLPWXXX"})
+
+		for _, ch := range []<-chan events.Event{first, second} {
+			event := <-ch
+			Expect(event.ID).To(Equal(uint64(1)))
+			Expect(event.Change.Type).To(Equal(service.ChangeCreated))
+			Expect((<-ch).ID).To(Equal(uint64(2)))
+		}
+	})
+
+	It("should stop delivering after a subscription ends", func() {
+		ch, cancel := bus.Subscribe()
+		cancel()
+		cancel()
+
+		bus.Publish(ctx, service.Change{Type: service.ChangeCreated})
+		Expect(ch).To(BeClosed())
+		Expect(bus.Subscribers()).To(BeZero())
+	})
+
+	It("should disconnect subscribers that fall behind", func() {
+		ch, cancel := bus.Subscribe()
+		defer cancel()
+		for range 3 {
+			bus.Publish(ctx, service.Change{Type: service.ChangeCreated})
+		}
+
+		Expect(bus.Subscribers()).To(BeZero())
+		Expect(ch).To(Receive())
+		Expect(ch).To(Receive())
+		Expect(ch).To(BeClosed())
+	})
+
+	It("should keep the latest events for pollers", func() {
+		events, next, ok := bus.After(0)
+		Expect(ok).To(BeTrue())
+		Expect(events).To(BeEmpty())
+		Expect(next).NotTo(BeClosed())
+
+		for range 3 {
+			bus.Publish(ctx, service.Change{Type: service.ChangeCreated})
+		}
+		Expect(next).To(BeClosed())
+		Expect(bus.LastID()).To(Equal(uint64(3)))
+
+		events, _, ok = bus.After(1)
+		Expect(ok).To(BeTrue())
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].ID).To(Equal(uint64(2)))
+
+		// Event 1 is no longer kept and event 4 was never published
+		_, _, ok = bus.After(0)
+		Expect(ok).To(BeFalse())
+		_, _, ok = bus.After(4)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should end every subscription when closed", func() {
+		ch, cancel := bus.Subscribe()
+		defer cancel()
+		_, next, _ := bus.After(0)
+		bus.Close()
+		Expect(ch).To(BeClosed())
+		Expect(next).To(BeClosed())
+
+		late, _ := bus.Subscribe()
+		Expect(late).To(BeClosed())
+	})
+})
@@ -0,0 +1,272 @@
+// Package expression implements the small string expression language of
+// computed response fields, such as
+//
+//	join(", ", bankName, town)
+//	upper(countryISO2) + "-" + swiftCodeBase
+//
+// An expression is a chain of terms joined with +, which concatenates them.
+// A term is a double-quoted string literal, a variable, a function call or a
+// parenthesized expression. The functions are upper, lower and trim of one
+// argument, coalesce, returning its first non-empty argument, and join,
+// joining its non-empty arguments after the first with the first as
+// separator. There are no loops, comparisons or side effects, so evaluating
+// an expression takes time linear in its length and the values it reads.
+package expression
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// MaxLength caps the source of an expression
+const MaxLength = 1024
+
+// Expression is a compiled expression, safe for concurrent use
+type Expression struct {
+	root node
+}
+
+// Compile parses src. Variables are looked up in vars, whose position gives
+// the index of their value in the slice passed to Eval; names match
+// case-insensitively and ignoring underscores.
+func Compile(src string, vars []string) (*Expression, error) {
+	if strings.TrimSpace(src) == "" {
+		return nil, errors.New("empty expression")
+	}
+	if len(src) > MaxLength {
+		return nil, fmt.Errorf("expression longer than %d characters", MaxLength)
+	}
+
+	p := &parser{src: src, vars: make(map[string]int, len(vars))}
+	for i, name := range vars {
+		p.vars[normalize(name)] = i
+	}
+	root, err := p.concat()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return nil, p.errorf("unexpected %q", p.src[p.pos:])
+	}
+	return &Expression{root: root}, nil
+}
+
+// Eval evaluates the expression over values, indexed like the vars given to
+// Compile
+func (e *Expression) Eval(values []string) string {
+	return e.root.eval(values)
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+type node interface {
+	eval(values []string) string
+}
+
+type literal string
+
+func (l literal) eval([]string) string { return string(l) }
+
+type variable int
+
+func (v variable) eval(values []string) string {
+	if int(v) < len(values) {
+		return values[v]
+	}
+	return ""
+}
+
+type concat []node
+
+func (c concat) eval(values []string) string {
+	var b strings.Builder
+	for _, n := range c {
+		b.WriteString(n.eval(values))
+	}
+	return b.String()
+}
+
+type call struct {
+	fn   func(args []string) string
+	args []node
+}
+
+func (c call) eval(values []string) string {
+	args := make([]string, len(c.args))
+	for i, arg := range c.args {
+		args[i] = arg.eval(values)
+	}
+	return c.fn(args)
+}
+
+// function describes a built-in; maxArgs < 0 accepts any number
+type function struct {
+	minArgs, maxArgs int
+	fn               func(args []string) string
+}
+
+var functions = map[string]function{
+	"upper": {1, 1, func(args []string) string { return strings.ToUpper(args[0]) }},
+	"lower": {1, 1, func(args []string) string { return strings.ToLower(args[0]) }},
+	"trim":  {1, 1, func(args []string) string { return strings.TrimSpace(args[0]) }},
+	"coalesce": {1, -1, func(args []string) string {
+		for _, arg := range args {
+			if arg != "" {
+				return arg
+			}
+		}
+		return ""
+	}},
+	"join": {2, -1, func(args []string) string {
+		parts := make([]string, 0, len(args)-1)
+		for _, arg := range args[1:] {
+			if arg != "" {
+				parts = append(parts, arg)
+			}
+		}
+		return strings.Join(parts, args[0])
+	}},
+}
+
+type parser struct {
+	src  string
+	pos  int
+	vars map[string]int
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+// peek skips spaces and reports whether the next byte is b
+func (p *parser) peek(b byte) bool {
+	p.skipSpace()
+	return p.pos < len(p.src) && p.src[p.pos] == b
+}
+
+// concat parses term ('+' term)*
+func (p *parser) concat() (node, error) {
+	first, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	terms := concat{first}
+	for p.peek('+') {
+		p.pos++
+		next, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, next)
+	}
+	if len(terms) == 1 {
+		return first, nil
+	}
+	return terms, nil
+}
+
+func (p *parser) term() (node, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, p.errorf("unexpected end of expression")
+	}
+
+	switch c := p.src[p.pos]; {
+	case c == '"':
+		return p.literal()
+	case c == '(':
+		p.pos++
+		inner, err := p.concat()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peek(')') {
+			return nil, p.errorf("missing )")
+		}
+		p.pos++
+		return inner, nil
+	case c == '_' || unicode.IsLetter(rune(c)):
+		return p.identifier()
+	default:
+		return nil, p.errorf("unexpected %q", c)
+	}
+}
+
+func (p *parser) literal() (node, error) {
+	start := p.pos
+	for p.pos++; p.pos < len(p.src); p.pos++ {
+		switch p.src[p.pos] {
+		case '\\':
+			p.pos++
+		case '"':
+			p.pos++
+			s, err := strconv.Unquote(p.src[start:p.pos])
+			if err != nil {
+				return nil, fmt.Errorf("at offset %d: invalid string literal: %w", start, err)
+			}
+			return literal(s), nil
+		}
+	}
+	return nil, fmt.Errorf("at offset %d: unterminated string literal", start)
+}
+
+func (p *parser) identifier() (node, error) {
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := rune(p.src[p.pos])
+		if c != '_' && !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			break
+		}
+		p.pos++
+	}
+	name := p.src[start:p.pos]
+
+	if !p.peek('(') {
+		index, ok := p.vars[normalize(name)]
+		if !ok {
+			return nil, fmt.Errorf("at offset %d: unknown variable %q", start, name)
+		}
+		return variable(index), nil
+	}
+
+	f, ok := functions[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("at offset %d: unknown function %q", start, name)
+	}
+	p.pos++
+	var args []node
+	if !p.peek(')') {
+		for {
+			arg, err := p.concat()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if !p.peek(',') {
+				break
+			}
+			p.pos++
+		}
+	}
+	if !p.peek(')') {
+		return nil, p.errorf("missing ) after the arguments of %s", name)
+	}
+	p.pos++
+
+	if len(args) < f.minArgs || (f.maxArgs >= 0 && len(args) > f.maxArgs) {
+		return nil, fmt.Errorf("at offset %d: wrong number of arguments to %s", start, name)
+	}
+	return call{fn: f.fn, args: args}, nil
+}
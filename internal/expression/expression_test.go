@@ -0,0 +1,57 @@
+package expression_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/expression"
+)
+
+func TestExpression(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Expression Suite")
+}
+
+var _ = Describe("Expression", func() {
+	vars := []string{"bankName", "town", "country_name"}
+	values := []string{"PKO BP", "", "POLAND"}
+
+	eval := func(src string) string {
+		expr, err := expression.Compile(src, vars)
+		Expect(err).NotTo(HaveOccurred())
+		return expr.Eval(values)
+	}
+
+	It("should concatenate literals and variables", func() {
+		Expect(eval(`bankName + ", " + town`)).To(Equal("PKO BP, "))
+		Expect(eval(`"\"" + BANKNAME + "\""`)).To(Equal(`"PKO BP"`))
+		Expect(eval(`countryName`)).To(Equal("POLAND"))
+	})
+
+	It("should call the built-in functions", func() {
+		Expect(eval(`join(", ", bankName, town, countryName)`)).To(Equal("PKO BP, POLAND"))
+		Expect(eval(`coalesce(town, lower(countryName))`)).To(Equal("poland"))
+		Expect(eval(`upper(trim("  a" + "b  "))`)).To(Equal("AB"))
+		Expect(eval(`(bankName + "/") + (town + "-")`)).To(Equal("PKO BP/-"))
+	})
+
+	It("should reject invalid expressions", func() {
+		for src, message := range map[string]string{
+			``:                      "empty expression",
+			`iban`:                  `unknown variable "iban"`,
+			`exec("rm")`:            `unknown function "exec"`,
+			`upper(bankName, town)`: "wrong number of arguments to upper",
+			`bankName +`:            "unexpected end of expression",
+			`"open`:                 "unterminated string literal",
+			`bankName town`:         `unexpected "town"`,
+			`join(", ", town`:       "missing )",
+			strings.Repeat("a", expression.MaxLength+1): "longer than",
+		} {
+			_, err := expression.Compile(src, vars)
+			Expect(err).To(MatchError(ContainSubstring(message)), src)
+		}
+	})
+})
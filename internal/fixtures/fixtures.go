@@ -0,0 +1,237 @@
+// Package fixtures samples rows of the live SWIFT codes table into files
+// integration tests and the memory driver can load, so that test data keeps
+// the shape of production data as the schema and the dataset evolve. Rows
+// may be anonymized, replacing what identifies a real bank while keeping
+// countries, towns and time zones.
+package fixtures
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	"github.com/zdziszkee/swift-codes/internal/database"
+	"github.com/zdziszkee/swift-codes/pkg/swiftfile"
+)
+
+// Formats a fixture can be written in
+const (
+	// FormatCSV is the SWIFT codes file layout the importer reads
+	FormatCSV = "csv"
+	// FormatJSON is an array of objects with the keys of the API responses
+	FormatJSON = "json"
+)
+
+// Options describes a sample
+type Options struct {
+	// PerCountry is how many codes are drawn from every country
+	PerCountry int
+	// Countries limits the sample to these ISO2 codes; empty samples all
+	Countries []string
+	// Seed makes the draw repeatable, so regenerated fixtures only differ
+	// where the table changed
+	Seed uint64
+	// Anonymize replaces bank names, addresses and institution codes
+	Anonymize bool
+}
+
+// Validate checks the sample size
+func (o Options) Validate() error {
+	if o.PerCountry < 1 {
+		return errors.New("fixtures need at least one code per country")
+	}
+	return nil
+}
+
+// Sample draws up to PerCountry codes of every country from the table of
+// db. A drawn branch whose headquarters was not drawn brings it along, so
+// that lookups of the fixture return branches as the live table does; a
+// country may therefore yield more than PerCountry codes. Records come
+// sorted by country and code.
+func Sample(ctx context.Context, db *database.Database, opts Options) ([]swiftfile.Record, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("SELECT country_iso_code, swift_code, bank_name, address, town_name, country_name, time_zone FROM %s",
+		db.Config.Dialect().Table(db.Config, db.Config.TableName))
+	var args []any
+	if len(opts.Countries) > 0 {
+		query += " WHERE country_iso_code IN (?" + strings.Repeat(", ?", len(opts.Countries)-1) + ")"
+		for _, country := range opts.Countries {
+			args = append(args, strings.ToUpper(country))
+		}
+	}
+	query += " ORDER BY country_iso_code, swift_code"
+
+	rows, err := db.DB.QueryContext(ctx, db.Config.Dialect().Rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the table: %w", err)
+	}
+	defer rows.Close()
+
+	byCountry := map[string][]swiftfile.Record{}
+	var countries []string
+	for rows.Next() {
+		var country, code string
+		var name, address, town, countryName, timeZone sql.NullString
+		if err := rows.Scan(&country, &code, &name, &address, &town, &countryName, &timeZone); err != nil {
+			return nil, fmt.Errorf("failed to read the table: %w", err)
+		}
+		if _, ok := byCountry[country]; !ok {
+			countries = append(countries, country)
+		}
+		byCountry[country] = append(byCountry[country], swiftfile.Record{
+			CountryISOCode: country,
+			SwiftCode:      code,
+			CodeType:       "BIC11",
+			BankName:       name.String,
+			Address:        address.String,
+			TownName:       town.String,
+			CountryName:    countryName.String,
+			TimeZone:       timeZone.String,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the table: %w", err)
+	}
+
+	random := rand.New(rand.NewPCG(opts.Seed, opts.Seed))
+	var sample []swiftfile.Record
+	for _, country := range countries {
+		sample = append(sample, draw(byCountry[country], opts.PerCountry, random)...)
+	}
+	if opts.Anonymize {
+		anonymize(sample)
+	}
+	return sample, nil
+}
+
+// draw picks n of a country's records, sorted by code, plus the
+// headquarters of the branches picked
+func draw(records []swiftfile.Record, n int, random *rand.Rand) []swiftfile.Record {
+	if len(records) <= n {
+		return records
+	}
+	picked := make(map[int]bool, n)
+	for _, i := range random.Perm(len(records))[:n] {
+		picked[i] = true
+	}
+	headquarters := make(map[string]int)
+	for i, record := range records {
+		if strings.HasSuffix(record.SwiftCode, "XXX") {
+			headquarters[record.SwiftCode[:8]] = i
+		}
+	}
+	for i := range picked {
+		if hq, ok := headquarters[records[i].SwiftCode[:8]]; ok {
+			picked[hq] = true
+		}
+	}
+
+	var drawn []swiftfile.Record
+	for i, record := range records {
+		if picked[i] {
+			drawn = append(drawn, record)
+		}
+	}
+	return drawn
+}
+
+// anonymize gives every institution a made-up four-letter code in the order
+// the institutions first appear, and replaces bank names and addresses.
+// Codes sharing an institution keep sharing it, so headquarters and
+// branches still match.
+func anonymize(records []swiftfile.Record) {
+	institutions := map[string]string{}
+	for i := range records {
+		record := &records[i]
+		institution, ok := institutions[record.SwiftCode[:4]]
+		if !ok {
+			institution = letters(len(institutions))
+			institutions[record.SwiftCode[:4]] = institution
+		}
+		record.SwiftCode = institution + record.SwiftCode[4:]
+		record.BankName = "TEST BANK " + institution
+		if record.Address != "" {
+			record.Address = fmt.Sprintf("%d TEST STREET", i+1)
+		}
+	}
+	slices.SortFunc(records, func(a, b swiftfile.Record) int {
+		return strings.Compare(a.CountryISOCode+a.SwiftCode, b.CountryISOCode+b.SwiftCode)
+	})
+}
+
+// letters spells n in base 26 with four letters, AAAA being 0
+func letters(n int) string {
+	code := []byte("AAAA")
+	for i := len(code) - 1; i >= 0 && n > 0; i-- {
+		code[i] = byte('A' + n%26)
+		n /= 26
+	}
+	return string(code)
+}
+
+// Write writes records to w in format
+func Write(w io.Writer, format string, records []swiftfile.Record) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, records)
+	case FormatJSON:
+		return writeJSON(w, records)
+	default:
+		return fmt.Errorf("fixture format must be %q or %q, got %q", FormatCSV, FormatJSON, format)
+	}
+}
+
+func writeCSV(w io.Writer, records []swiftfile.Record) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(swiftfile.Columns); err != nil {
+		return err
+	}
+	for _, r := range records {
+		if err := out.Write([]string{r.CountryISOCode, r.SwiftCode, r.CodeType, r.BankName, r.Address, r.TownName, r.CountryName, r.TimeZone}); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// jsonRecord holds the keys the JSON reader of the importer accepts
+type jsonRecord struct {
+	CountryISO2 string `json:"countryISO2"`
+	SwiftCode   string `json:"swiftCode"`
+	CodeType    string `json:"codeType"`
+	BankName    string `json:"bankName"`
+	Address     string `json:"address"`
+	TownName    string `json:"townName"`
+	CountryName string `json:"countryName"`
+	TimeZone    string `json:"timeZone"`
+}
+
+func writeJSON(w io.Writer, records []swiftfile.Record) error {
+	out := make([]jsonRecord, 0, len(records))
+	for _, r := range records {
+		out = append(out, jsonRecord{
+			CountryISO2: r.CountryISOCode,
+			SwiftCode:   r.SwiftCode,
+			CodeType:    r.CodeType,
+			BankName:    r.BankName,
+			Address:     r.Address,
+			TownName:    r.TownName,
+			CountryName: r.CountryName,
+			TimeZone:    r.TimeZone,
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
@@ -0,0 +1,118 @@
+package fixtures_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/database"
+	"github.com/zdziszkee/swift-codes/internal/fixtures"
+	"github.com/zdziszkee/swift-codes/internal/models"
+	jsonreader "github.com/zdziszkee/swift-codes/internal/readers/json"
+	repo "github.com/zdziszkee/swift-codes/internal/repositories"
+	"github.com/zdziszkee/swift-codes/pkg/swiftfile"
+)
+
+func TestFixtures(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Fixtures Suite")
+}
+
+var _ = Describe("Sample", func() {
+	var (
+		ctx context.Context
+		db  *database.Database
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cfg := database.Config{
+			Driver:    database.DriverSQLite,
+			DSN:       filepath.Join(GinkgoT().TempDir(), "swift.db"),
+			TableName: "swift_banks",
+		}
+		var err error
+		db, err = database.Open(cfg)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(db.DB.Close)
+		Expect(db.CreateTable(ctx, cfg.TableName)).To(Succeed())
+
+		Expect(repo.NewSQLSwiftRepository(db, cfg).CreateBatch(ctx, []*models.SwiftBank{
+			{SwiftCode: "PKOPPLPWXXX", CountryISOCode: "PL", BankName: "PKO BP", IsHeadquarter: true, Address: "PULAWSKA 15", CountryName: "POLAND", Town: "WARSZAWA", TimeZone: "Europe/Warsaw"},
+			{SwiftCode: "PKOPPLPWKRK", CountryISOCode: "PL", BankName: "PKO BP KRAKOW", Address: "RYNEK 1", CountryName: "POLAND", Town: "KRAKOW"},
+			{SwiftCode: "PKOPPLPWGDA", CountryISOCode: "PL", BankName: "PKO BP GDANSK", Address: "DLUGA 2", CountryName: "POLAND", Town: "GDANSK"},
+			{SwiftCode: "BREXPLPWXXX", CountryISOCode: "PL", BankName: "MBANK", IsHeadquarter: true, Address: "PROSTA 18", CountryName: "POLAND"},
+			{SwiftCode: "DEUTDEFFXXX", CountryISOCode: "DE", BankName: "DEUTSCHE BANK", IsHeadquarter: true, Address: "TAUNUSANLAGE 12", CountryName: "GERMANY", Town: "FRANKFURT"},
+		})).To(Succeed())
+	})
+
+	It("should draw up to the requested codes of every country, repeatably", func() {
+		records, err := fixtures.Sample(ctx, db, fixtures.Options{PerCountry: 1, Seed: 1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records[0].SwiftCode).To(Equal("DEUTDEFFXXX"))
+		Expect(records[0].TownName).To(Equal("FRANKFURT"))
+
+		// A drawn branch brings its headquarters along
+		poland := records[1:]
+		Expect(poland).To(HaveLen(2))
+		Expect(poland[0].SwiftCode).To(HavePrefix("PKOPPLPW"))
+		Expect(poland[1].SwiftCode).To(Equal("PKOPPLPWXXX"))
+
+		again, err := fixtures.Sample(ctx, db, fixtures.Options{PerCountry: 1, Seed: 1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again).To(Equal(records))
+	})
+
+	It("should limit the sample to the requested countries", func() {
+		records, err := fixtures.Sample(ctx, db, fixtures.Options{PerCountry: 10, Countries: []string{"pl"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(HaveLen(4))
+		Expect(records).To(HaveEach(HaveField("CountryISOCode", "PL")))
+
+		_, err = fixtures.Sample(ctx, db, fixtures.Options{})
+		Expect(err).To(MatchError(ContainSubstring("at least one code per country")))
+	})
+
+	It("should anonymize banks while keeping headquarters and branches together", func() {
+		records, err := fixtures.Sample(ctx, db, fixtures.Options{PerCountry: 10, Countries: []string{"PL"}, Anonymize: true})
+		Expect(err).NotTo(HaveOccurred())
+		codes := make([]string, 0, len(records))
+		for _, record := range records {
+			codes = append(codes, record.SwiftCode)
+			Expect(record.BankName).To(HavePrefix("TEST BANK "))
+			Expect(record.Address).NotTo(ContainSubstring("PULAWSKA"))
+		}
+		Expect(codes).To(Equal([]string{"AAAAPLPWXXX", "AAABPLPWGDA", "AAABPLPWKRK", "AAABPLPWXXX"}))
+		Expect(records[3].TownName).To(Equal("WARSZAWA"))
+	})
+
+	It("should write fixtures the importer reads back", func() {
+		records, err := fixtures.Sample(ctx, db, fixtures.Options{PerCountry: 10})
+		Expect(err).NotTo(HaveOccurred())
+
+		var csvOut bytes.Buffer
+		Expect(fixtures.Write(&csvOut, fixtures.FormatCSV, records)).To(Succeed())
+		reader, err := swiftfile.NewReader(&csvOut, swiftfile.Options{})
+		Expect(err).NotTo(HaveOccurred())
+		var read []string
+		for record, err := range reader.All() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(swiftfile.Validate(record)).To(Succeed())
+			read = append(read, record.SwiftCode)
+		}
+		Expect(read).To(HaveLen(5))
+
+		var jsonOut bytes.Buffer
+		Expect(fixtures.Write(&jsonOut, fixtures.FormatJSON, records)).To(Succeed())
+		jsonRecords, err := (&jsonreader.JSONSwiftBanksReader{}).LoadSwiftBanks(&jsonOut)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(jsonRecords).To(HaveLen(5))
+		Expect(jsonRecords[0].SwiftCode).To(Equal("DEUTDEFFXXX"))
+
+		Expect(fixtures.Write(&jsonOut, "xml", records)).To(MatchError(ContainSubstring(`fixture format must be "csv" or "json"`)))
+	})
+})
@@ -0,0 +1,123 @@
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	models "github.com/zdziszkee/swift-codes/internal/models"
+)
+
+// ErrInvalidContacts is returned for a contacts file without a SWIFT CODE
+// column or without any contact column
+var ErrInvalidContacts = errors.New("invalid contacts file")
+
+// contactColumns maps recognized contact file headers to their field
+var contactColumns = map[string]string{
+	"SWIFT CODE": "code",
+	"SWIFT_CODE": "code",
+	"BIC":        "code",
+	"WEBSITE":    "website",
+	"URL":        "website",
+	"PHONE":      "phone",
+	"TELEPHONE":  "phone",
+}
+
+// ContactSummary reports the outcome of a contacts import
+type ContactSummary struct {
+	// Rows is the number of data rows read from the file
+	Rows int `json:"rows"`
+	// Updated is the number of SWIFT codes whose contacts were stored
+	Updated int64 `json:"updated"`
+	// Skipped is the number of rows without a code or any contact
+	Skipped int `json:"skipped"`
+}
+
+// ImportContactsFile loads the supplementary contacts file at path
+func (i *Importer) ImportContactsFile(ctx context.Context, path string) (ContactSummary, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return ContactSummary{}, fmt.Errorf("failed to open contacts file: %w", err)
+	}
+	defer file.Close()
+
+	return i.ImportContacts(ctx, file)
+}
+
+// ImportContacts stores the website and phone of SWIFT codes already in the
+// repository. The CSV needs a SWIFT CODE column and a WEBSITE or PHONE
+// column; other columns are ignored. Codes that are not in the repository
+// are not created.
+func (i *Importer) ImportContacts(ctx context.Context, r io.Reader) (ContactSummary, error) {
+	if err := i.refuse(); err != nil {
+		return ContactSummary{}, err
+	}
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return ContactSummary{}, fmt.Errorf("%w: %v", ErrInvalidContacts, err)
+	}
+	columns := map[string]int{}
+	for idx, name := range header {
+		name = strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if field, ok := contactColumns[name]; ok {
+			if _, seen := columns[field]; !seen {
+				columns[field] = idx
+			}
+		}
+	}
+	_, hasWebsite := columns["website"]
+	_, hasPhone := columns["phone"]
+	if _, ok := columns["code"]; !ok || (!hasWebsite && !hasPhone) {
+		return ContactSummary{}, fmt.Errorf("%w: need a SWIFT CODE column and a WEBSITE or PHONE column", ErrInvalidContacts)
+	}
+
+	cell := func(row []string, field string) string {
+		idx, ok := columns[field]
+		if !ok || idx >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[idx])
+	}
+
+	var summary ContactSummary
+	var contacts []models.BankContact
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return summary, fmt.Errorf("failed to read contacts: %w", err)
+		}
+		summary.Rows++
+
+		contact := models.BankContact{
+			SwiftCode: models.CanonicalBIC(strings.ToUpper(cell(row, "code"))),
+			Website:   cell(row, "website"),
+			Phone:     cell(row, "phone"),
+		}
+		if len(contact.SwiftCode) != 11 || (contact.Website == "" && contact.Phone == "") {
+			summary.Skipped++
+			continue
+		}
+		contacts = append(contacts, contact)
+	}
+
+	if len(contacts) == 0 {
+		return summary, nil
+	}
+	updated, err := i.repo.UpdateContacts(ctx, contacts)
+	summary.Updated = updated
+	if err != nil {
+		return summary, fmt.Errorf("failed to store contacts: %w", err)
+	}
+	return summary, nil
+}
@@ -0,0 +1,74 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+)
+
+var ErrGoldenMismatch = errors.New("golden dataset mismatch")
+
+// Sentinel is a well-known SWIFT code expected to be present after every
+// import; imports are checked before they store anything. Empty attributes are not checked.
+type Sentinel struct {
+	SwiftCode      string `koanf:"swift_code"`
+	BankName       string `koanf:"bank_name"`
+	CountryISOCode string `koanf:"country_iso_code"`
+	CountryName    string `koanf:"country_name"`
+}
+
+// GoldenConfig holds the sentinel codes every import is verified against
+type GoldenConfig struct {
+	FailOnMismatch bool       `koanf:"fail_on_mismatch"`
+	Sentinels      []Sentinel `koanf:"sentinels"`
+}
+
+// VerifyGolden checks each sentinel against the repository and returns a
+// description of every missing or mismatching code
+func VerifyGolden(ctx context.Context, repo repository.SwiftRepository, sentinels []Sentinel) ([]string, error) {
+	return verifyGolden(ctx, repo, sentinels, nil)
+}
+
+// verifyGolden is VerifyGolden for an import of banks that has not been
+// stored yet: a sentinel in banks is checked against its row there, the
+// others against the repository, whose rows an import does not remove
+func verifyGolden(ctx context.Context, repo repository.SwiftRepository, sentinels []Sentinel, banks []*models.SwiftBank) ([]string, error) {
+	imported := make(map[string]*models.SwiftBank, len(banks))
+	for _, bank := range banks {
+		imported[strings.ToUpper(bank.SwiftCode)] = bank
+	}
+
+	var problems []string
+	for _, sentinel := range sentinels {
+		code := strings.ToUpper(sentinel.SwiftCode)
+		bank, ok := imported[code]
+		if !ok {
+			// Sentinels check what is stored now, so read past any cache
+			detail, err := repo.GetByCode(ctx, code, repository.QueryOptions{Consistency: repository.ConsistencyStrong, OmitBranches: true})
+			if errors.Is(err, repository.ErrNotFound) {
+				problems = append(problems, fmt.Sprintf("%s is missing", code))
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			bank = &detail.Bank
+		}
+
+		if sentinel.BankName != "" && !strings.EqualFold(bank.BankName, sentinel.BankName) {
+			problems = append(problems, fmt.Sprintf("%s bank_name is %q, expected %q", code, bank.BankName, sentinel.BankName))
+		}
+		if sentinel.CountryISOCode != "" && !strings.EqualFold(bank.CountryISOCode, sentinel.CountryISOCode) {
+			problems = append(problems, fmt.Sprintf("%s country_iso_code is %q, expected %q", code, bank.CountryISOCode, sentinel.CountryISOCode))
+		}
+		if sentinel.CountryName != "" && !strings.EqualFold(bank.CountryName, sentinel.CountryName) {
+			problems = append(problems, fmt.Sprintf("%s country_name is %q, expected %q", code, bank.CountryName, sentinel.CountryName))
+		}
+	}
+
+	return problems, nil
+}
@@ -0,0 +1,366 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	parser "github.com/zdziszkee/swift-codes/internal/parsers"
+	reader "github.com/zdziszkee/swift-codes/internal/readers"
+	"github.com/zdziszkee/swift-codes/internal/readers/detect"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+)
+
+// Importer reads, parses and stores SWIFT code files
+type Importer struct {
+	repo repository.SwiftRepository
+	// newReader creates the reader of one import; readers keep the format
+	// and unknown columns of their last load, so concurrent imports must
+	// not share one
+	newReader func() reader.SwiftBanksReader
+	parser    parser.SwiftBanksParser
+	golden    GoldenConfig
+	onLoad    []func(ctx context.Context, summary Summary)
+	// quarantine keeps the input of failed runs when set
+	quarantine QuarantineStore
+	// upsert stores rows with Upsert rather than CreateBatch
+	upsert bool
+	// canonical stores BIC8 codes in their BIC11 form
+	canonical bool
+	// check refuses imports while it returns an error
+	check func() error
+
+	mu       sync.Mutex
+	lastLoad *LoadRecord
+	history  []LoadRecord
+	counters RunCounters
+}
+
+// RunCounters summarizes import runs since the process started, for
+// alerting on stale or failing loads
+type RunCounters struct {
+	// Runs and Failures count every finished and every failed run
+	Runs     int64
+	Failures int64
+	// ConsecutiveFailures is reset by the next successful run
+	ConsecutiveFailures int
+	// LastSuccess is when the last successful run finished; zero if none
+	LastSuccess time.Time
+	// LastSkipped is the number of rows rejected by the most recent run
+	LastSkipped int
+}
+
+// historySize bounds the runs kept by History
+const historySize = 20
+
+// LoadRecord describes a finished import
+type LoadRecord struct {
+	FinishedAt time.Time `json:"finished_at"`
+	DurationMs int64     `json:"duration_ms"`
+	Summary
+	// Error is set when the run failed
+	Error string `json:"error,omitempty"`
+}
+
+// LastLoad returns the most recent successful import, or nil if none has
+// completed since the process started
+func (i *Importer) LastLoad() *LoadRecord {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.lastLoad == nil {
+		return nil
+	}
+	record := *i.lastLoad
+	return &record
+}
+
+// History returns up to the last 20 import runs since the process started,
+// newest first, including failed ones
+func (i *Importer) History() []LoadRecord {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	history := make([]LoadRecord, len(i.history))
+	for idx, record := range i.history {
+		history[len(i.history)-1-idx] = record
+	}
+	return history
+}
+
+// Counters returns the run counters since the process started
+func (i *Importer) Counters() RunCounters {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.counters
+}
+
+// record adds a finished run to the history, and remembers it as the last
+// load when it stored data
+func (i *Importer) record(start time.Time, summary Summary, err error, loaded bool) {
+	record := LoadRecord{FinishedAt: time.Now().UTC(), DurationMs: time.Since(start).Milliseconds(), Summary: summary}
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if loaded {
+		last := record
+		last.Error = ""
+		i.lastLoad = &last
+	}
+	i.counters.Runs++
+	i.counters.LastSkipped = summary.Skipped
+	if err != nil {
+		i.counters.Failures++
+		i.counters.ConsecutiveFailures++
+	} else {
+		i.counters.ConsecutiveFailures = 0
+		i.counters.LastSuccess = record.FinishedAt
+	}
+	i.history = append(i.history, record)
+	if len(i.history) > historySize {
+		i.history = i.history[len(i.history)-historySize:]
+	}
+}
+
+// Summary reports the outcome of a single import run
+type Summary struct {
+	// Rows is the number of data rows read from the file
+	Rows int `json:"rows"`
+	// Loaded is the number of banks stored in the repository
+	Loaded int `json:"loaded"`
+	// Skipped is the number of rows rejected by the parser
+	Skipped int `json:"skipped"`
+	// Changed is the number of banks an upsert run inserted or updated;
+	// the rest were already stored as they are in the file
+	Changed int `json:"changed,omitempty"`
+	// UnknownColumns lists source columns that were ignored
+	UnknownColumns []string `json:"unknown_columns,omitempty"`
+	// Format is the detected file format: csv, xlsx, json or fixed-width
+	Format string `json:"format,omitempty"`
+	// Quarantine is where the input of a failed run was kept
+	Quarantine string `json:"quarantine,omitempty"`
+}
+
+// Option configures optional Importer behavior
+type Option func(*Importer)
+
+// WithTolerantHeader accepts files with extra trailing columns and reports
+// them in the import summary instead of failing
+func WithTolerantHeader() Option {
+	return func(i *Importer) {
+		i.newReader = func() reader.SwiftBanksReader { return &detect.SwiftBanksReader{Tolerant: true} }
+	}
+}
+
+// WithUpsert stores rows with the repository's Upsert, so that importing a
+// file again updates the codes that changed instead of adding copies or
+// failing on duplicates
+func WithUpsert() Option {
+	return func(i *Importer) {
+		i.upsert = true
+	}
+}
+
+// WithCanonicalBICs stores a BIC8 as BIC8 + "XXX", the head office it
+// stands for under ISO 9362:2022. A file holding both spellings of a code
+// then stores it once, as CreateBatch and Upsert handle any repeated code.
+func WithCanonicalBICs() Option {
+	return func(i *Importer) {
+		i.canonical = true
+	}
+}
+
+// WithLoadHook calls hook after every run that stored data
+func WithLoadHook(hook func(ctx context.Context, summary Summary)) Option {
+	return func(i *Importer) {
+		i.onLoad = append(i.onLoad, hook)
+	}
+}
+
+// WithWriteCheck refuses every import with the error of check while it
+// returns one, so that imports honour read-only periods such as
+// maintenance windows. Refused runs store nothing and are not recorded.
+func WithWriteCheck(check func() error) Option {
+	return func(i *Importer) {
+		i.check = check
+	}
+}
+
+// NewImporter creates an importer for CSV files backed by the given repository
+func NewImporter(repo repository.SwiftRepository, golden GoldenConfig, opts ...Option) *Importer {
+	i := &Importer{
+		repo:      repo,
+		newReader: func() reader.SwiftBanksReader { return &detect.SwiftBanksReader{} },
+		parser:    parser.DefaultSwiftBanksParser{},
+		golden:    golden,
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+	return i
+}
+
+// ImportFile loads the SWIFT codes file at path and returns the number of stored banks
+func (i *Importer) ImportFile(ctx context.Context, path string) (int, error) {
+	summary, err := i.RunFile(ctx, path)
+	return summary.Loaded, err
+}
+
+// Import loads SWIFT codes from r once they pass the golden dataset check
+func (i *Importer) Import(ctx context.Context, r io.Reader) (int, error) {
+	summary, err := i.Run(ctx, r)
+	return summary.Loaded, err
+}
+
+// RunFile is like ImportFile but reports how many rows were loaded and skipped
+func (i *Importer) RunFile(ctx context.Context, path string) (Summary, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Summary{}, fmt.Errorf("failed to open SWIFT codes file: %w", err)
+	}
+	defer file.Close()
+
+	return i.RunNamed(ctx, filepath.Base(path), file)
+}
+
+// Run is like Import but reports how many rows were loaded and skipped.
+// Every run is added to History.
+func (i *Importer) Run(ctx context.Context, r io.Reader) (Summary, error) {
+	return i.RunNamed(ctx, "import", r)
+}
+
+// RunNamed is like Run for a file called name, which is the name its input
+// is quarantined under when the run fails
+func (i *Importer) RunNamed(ctx context.Context, name string, r io.Reader) (Summary, error) {
+	if err := i.refuse(); err != nil {
+		return Summary{}, err
+	}
+	start := time.Now()
+	var content bytes.Buffer
+	if i.quarantine != nil {
+		r = io.TeeReader(r, &content)
+	}
+	// The golden dataset check runs before anything is stored, so a
+	// failing file leaves the previous data in place
+	summary, banks, err := i.parse(r)
+	if err == nil {
+		err = i.verifyGolden(ctx, banks)
+	}
+	if err == nil {
+		summary, err = i.store(ctx, summary, banks)
+	}
+	loaded := err == nil
+	if err != nil && i.quarantine != nil {
+		// Readers stop at the first error, so keep the rest of the file too
+		if _, readErr := io.Copy(io.Discard, r); readErr != nil {
+			log.Printf("WARNING: quarantined copy of %s is incomplete: %v", name, readErr)
+		}
+		location, qErr := i.quarantineFile(ctx, name, content.Bytes(), summary, err)
+		if qErr != nil {
+			log.Printf("ERROR: failed to quarantine %s: %v", name, qErr)
+		} else {
+			log.Printf("Quarantined failed import %s at %s", name, location)
+			summary.Quarantine = location
+		}
+	}
+	i.record(start, summary, err, loaded)
+	if loaded {
+		for _, hook := range i.onLoad {
+			hook(ctx, summary)
+		}
+	}
+	return summary, err
+}
+
+// refuse returns the error of the write check, or nil when imports may run
+func (i *Importer) refuse() error {
+	if i.check == nil {
+		return nil
+	}
+	return i.check()
+}
+
+// parse reads and parses the SWIFT codes of r into the banks to store
+func (i *Importer) parse(r io.Reader) (Summary, []*models.SwiftBank, error) {
+	// Load SWIFT bank records
+	rd := i.newReader()
+	records, err := rd.LoadSwiftBanks(r)
+	if err != nil {
+		return Summary{}, nil, fmt.Errorf("failed to read SWIFT codes: %w", err)
+	}
+
+	// Parse the records into SwiftBank models
+	banks, err := i.parser.ParseSwiftBanks(records)
+	if err != nil {
+		return Summary{}, nil, fmt.Errorf("failed to parse SWIFT bank records: %w", err)
+	}
+	summary := Summary{Rows: len(records), Skipped: len(records) - len(banks)}
+	if reporter, ok := rd.(reader.FormatReporter); ok {
+		summary.Format = reporter.Format()
+	}
+	if reporter, ok := rd.(reader.UnknownColumnsReporter); ok {
+		summary.UnknownColumns = reporter.UnknownColumns()
+		if len(summary.UnknownColumns) > 0 {
+			log.Printf("WARNING: ignoring unknown SWIFT codes columns %v", summary.UnknownColumns)
+		}
+	}
+
+	bankPtrs := make([]*models.SwiftBank, 0, len(banks))
+	for idx := range banks {
+		if i.canonical && len(banks[idx].SwiftCode) == 8 {
+			banks[idx].SwiftCode = models.CanonicalBIC(banks[idx].SwiftCode)
+			banks[idx].IsHeadquarter = true
+		}
+		bankPtrs = append(bankPtrs, &banks[idx])
+	}
+	return summary, bankPtrs, nil
+}
+
+// store writes the parsed banks to the repository
+func (i *Importer) store(ctx context.Context, summary Summary, bankPtrs []*models.SwiftBank) (Summary, error) {
+	if i.upsert {
+		changed, err := i.repo.Upsert(ctx, bankPtrs)
+		if err != nil {
+			return summary, fmt.Errorf("failed to upsert SWIFT codes into database: %w", err)
+		}
+		summary.Changed = int(changed)
+	} else if err := i.repo.CreateBatch(ctx, bankPtrs); err != nil {
+		return summary, fmt.Errorf("failed to load SWIFT codes into database: %w", err)
+	}
+	summary.Loaded = len(bankPtrs)
+	return summary, nil
+}
+
+// verifyGolden checks the sentinels against banks, the rows about to be
+// stored, and those banks does not hold against the repository
+func (i *Importer) verifyGolden(ctx context.Context, banks []*models.SwiftBank) error {
+	if len(i.golden.Sentinels) == 0 {
+		return nil
+	}
+
+	problems, err := verifyGolden(ctx, i.repo, i.golden.Sentinels, banks)
+	if err != nil {
+		return fmt.Errorf("golden dataset check could not run: %w", err)
+	}
+	if len(problems) == 0 {
+		log.Printf("Golden dataset check passed for %d sentinel codes", len(i.golden.Sentinels))
+		return nil
+	}
+
+	for _, problem := range problems {
+		log.Printf("ALERT: golden dataset mismatch: %s", problem)
+	}
+	if i.golden.FailOnMismatch {
+		return fmt.Errorf("%w: %d of %d sentinel codes did not match", ErrGoldenMismatch, len(problems), len(i.golden.Sentinels))
+	}
+	return nil
+}
@@ -0,0 +1,391 @@
+package importer_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/importer"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+func TestImporter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Importer Suite")
+}
+
+const sampleCSV = `COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE
+PL,PKOPPLPWXXX,BIC11,BANK PEKAO SA,"ZUBRA 1 WARSZAWA, 01-066",WARSZAWA,POLAND,Europe/Warsaw
+PL,PKOPPLPW123,BIC11,BANK PEKAO SA,"DLUGA 1 WARSZAWA, 01-066",WARSZAWA,POLAND,Europe/Warsaw
+`
+
+// fakeObjectStore keeps uploaded objects in memory
+type fakeObjectStore struct {
+	objects map[string][]byte
+}
+
+func (f *fakeObjectStore) Put(_ context.Context, key, _ string, body []byte) error {
+	f.objects[key] = body
+	return nil
+}
+
+var _ = Describe("Importer", func() {
+	var (
+		ctx    context.Context
+		stored map[string]models.SwiftBank
+		repo   *mocks.MockSwiftRepository
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		stored = map[string]models.SwiftBank{}
+		repo = &mocks.MockSwiftRepository{
+			CreateBatchFunc: func(ctx context.Context, banks []*models.SwiftBank) error {
+				for _, bank := range banks {
+					stored[bank.SwiftCode] = *bank
+				}
+				return nil
+			},
+			GetByCodeFunc: func(ctx context.Context, code string, opts repository.QueryOptions) (*repository.SwiftBankDetail, error) {
+				bank, ok := stored[code]
+				if !ok {
+					return nil, repository.ErrNotFound
+				}
+				return &repository.SwiftBankDetail{Bank: bank}, nil
+			},
+		}
+	})
+
+	It("should store every parsed bank", func() {
+		count, err := importer.NewImporter(repo, importer.GoldenConfig{}).Import(ctx, strings.NewReader(sampleCSV))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(2))
+		Expect(stored).To(HaveKey("PKOPPLPW123"))
+	})
+
+	It("should report loaded and skipped rows", func() {
+		csv := sampleCSV + "PL,INVALID,BIC11,BANK PEKAO SA,\"ZUBRA 1\",WARSZAWA,POLAND,Europe/Warsaw\n"
+		summary, err := importer.NewImporter(repo, importer.GoldenConfig{}).Run(ctx, strings.NewReader(csv))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary).To(Equal(importer.Summary{Rows: 3, Loaded: 2, Skipped: 1, Format: "csv"}))
+	})
+
+	It("should detect other file formats", func() {
+		listing := `{"country_iso2":"PL","swift_codes":[{"SwiftCode":"PKOPPLPWXXX","CountryISOCode":"PL",` +
+			`"BankName":"BANK PEKAO SA","Address":"ZUBRA 1","CountryName":"POLAND","IsHeadquarter":true}]}`
+		summary, err := importer.NewImporter(repo, importer.GoldenConfig{}).Run(ctx, strings.NewReader(listing))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary).To(Equal(importer.Summary{Rows: 1, Loaded: 1, Format: "json"}))
+		Expect(stored).To(HaveKey("PKOPPLPWXXX"))
+	})
+
+	It("should report unknown trailing columns in tolerant mode", func() {
+		csv := "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE,CITY CODE\n" +
+			"PL,PKOPPLPWXXX,BIC11,BANK PEKAO SA,\"ZUBRA 1\",WARSZAWA,POLAND,Europe/Warsaw,WAW\n"
+		summary, err := importer.NewImporter(repo, importer.GoldenConfig{}, importer.WithTolerantHeader()).Run(ctx, strings.NewReader(csv))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary.Loaded).To(Equal(1))
+		Expect(summary.UnknownColumns).To(Equal([]string{"CITY CODE"}))
+	})
+
+	It("should report the unknown columns of each of concurrent imports", func() {
+		var mu sync.Mutex
+		repo.CreateBatchFunc = func(ctx context.Context, banks []*models.SwiftBank) error {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, bank := range banks {
+				stored[bank.SwiftCode] = *bank
+			}
+			return nil
+		}
+		imp := importer.NewImporter(repo, importer.GoldenConfig{}, importer.WithTolerantHeader())
+		withColumn := func(column string) string {
+			return "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE," + column + "\n" +
+				"PL,PKOPPLPWXXX,BIC11,BANK PEKAO SA,\"ZUBRA 1\",WARSZAWA,POLAND,Europe/Warsaw,X\n"
+		}
+
+		var wg sync.WaitGroup
+		for n := range 20 {
+			column := fmt.Sprintf("EXTRA %d", n)
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				summary, err := imp.Run(ctx, strings.NewReader(withColumn(column)))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(summary.UnknownColumns).To(Equal([]string{column}))
+			}()
+		}
+		wg.Wait()
+	})
+
+	It("should store both spellings of a head office once with canonical BICs", func() {
+		csv := sampleCSV + "PL,PKOPPLPW,BIC8,BANK PEKAO SA,\"ZUBRA 1\",WARSZAWA,POLAND,Europe/Warsaw\n"
+		var batch []string
+		repo.CreateBatchFunc = func(ctx context.Context, banks []*models.SwiftBank) error {
+			for _, bank := range banks {
+				batch = append(batch, bank.SwiftCode)
+				Expect(bank.IsHeadquarter).To(Equal(strings.HasSuffix(bank.SwiftCode, "XXX")))
+			}
+			return nil
+		}
+		_, err := importer.NewImporter(repo, importer.GoldenConfig{}, importer.WithCanonicalBICs()).Run(ctx, strings.NewReader(csv))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(batch).To(Equal([]string{"PKOPPLPWXXX", "PKOPPLPW123", "PKOPPLPWXXX"}))
+
+		memory := repository.NewMemorySwiftRepository()
+		_, err = importer.NewImporter(memory, importer.GoldenConfig{}, importer.WithCanonicalBICs()).Run(ctx, strings.NewReader(csv))
+		Expect(err).NotTo(HaveOccurred())
+		codes, err := memory.GetByCountry(ctx, "PL", repository.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(codes.SwiftCodes).To(HaveLen(2))
+	})
+
+	It("should remember the last successful load", func() {
+		imp := importer.NewImporter(repo, importer.GoldenConfig{})
+		Expect(imp.LastLoad()).To(BeNil())
+
+		_, err := imp.Import(ctx, strings.NewReader(sampleCSV))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(imp.LastLoad()).NotTo(BeNil())
+		Expect(imp.LastLoad().Loaded).To(Equal(2))
+		Expect(imp.LastLoad().FinishedAt).NotTo(BeZero())
+	})
+
+	It("should keep a history of runs, newest first", func() {
+		imp := importer.NewImporter(repo, importer.GoldenConfig{})
+		_, err := imp.Import(ctx, strings.NewReader(sampleCSV))
+		Expect(err).NotTo(HaveOccurred())
+
+		repo.CreateBatchFunc = func(ctx context.Context, banks []*models.SwiftBank) error {
+			return errors.New("db error")
+		}
+		_, err = imp.Import(ctx, strings.NewReader(sampleCSV))
+		Expect(err).To(HaveOccurred())
+
+		history := imp.History()
+		Expect(history).To(HaveLen(2))
+		Expect(history[0].Error).To(ContainSubstring("db error"))
+		Expect(history[1].Error).To(BeEmpty())
+		Expect(history[1].Loaded).To(Equal(2))
+		Expect(imp.LastLoad().Loaded).To(Equal(2))
+	})
+
+	It("should upsert when asked to and report the changed rows", func() {
+		repo.CreateBatchFunc = func(ctx context.Context, banks []*models.SwiftBank) error {
+			return errors.New("CreateBatch should not be called")
+		}
+		repo.UpsertFunc = func(ctx context.Context, banks []*models.SwiftBank) (int64, error) {
+			return 1, nil
+		}
+		imp := importer.NewImporter(repo, importer.GoldenConfig{}, importer.WithUpsert())
+		summary, err := imp.Run(ctx, strings.NewReader(sampleCSV))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary.Loaded).To(Equal(2))
+		Expect(summary.Changed).To(Equal(1))
+	})
+
+	It("should call load hooks only when data was stored", func() {
+		var loads []importer.Summary
+		imp := importer.NewImporter(repo, importer.GoldenConfig{}, importer.WithLoadHook(func(ctx context.Context, summary importer.Summary) {
+			loads = append(loads, summary)
+		}))
+		_, err := imp.Import(ctx, strings.NewReader(sampleCSV))
+		Expect(err).NotTo(HaveOccurred())
+
+		repo.CreateBatchFunc = func(ctx context.Context, banks []*models.SwiftBank) error {
+			return errors.New("db error")
+		}
+		_, err = imp.Import(ctx, strings.NewReader(sampleCSV))
+		Expect(err).To(HaveOccurred())
+
+		Expect(loads).To(HaveLen(1))
+		Expect(loads[0].Loaded).To(Equal(2))
+	})
+
+	It("should refuse runs while the write check fails", func() {
+		errClosed := errors.New("read-only")
+		check := errClosed
+		imp := importer.NewImporter(repo, importer.GoldenConfig{}, importer.WithWriteCheck(func() error { return check }))
+
+		_, err := imp.Run(ctx, strings.NewReader(sampleCSV))
+		Expect(err).To(MatchError(errClosed))
+		_, err = imp.ImportContacts(ctx, strings.NewReader("SWIFT CODE,WEBSITE\nPKOPPLPWXXX,https://example.com\n"))
+		Expect(err).To(MatchError(errClosed))
+		Expect(stored).To(BeEmpty())
+		Expect(imp.History()).To(BeEmpty())
+
+		check = nil
+		count, err := imp.Import(ctx, strings.NewReader(sampleCSV))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(2))
+	})
+
+	It("should return repository errors", func() {
+		repo.CreateBatchFunc = func(ctx context.Context, banks []*models.SwiftBank) error {
+			return errors.New("db error")
+		}
+		_, err := importer.NewImporter(repo, importer.GoldenConfig{}).Import(ctx, strings.NewReader(sampleCSV))
+		Expect(err).To(MatchError(ContainSubstring("db error")))
+	})
+
+	Describe("quarantine", func() {
+		It("should keep the whole file and an error report of a failed run", func() {
+			dir := GinkgoT().TempDir()
+			imp := importer.NewImporter(repo, importer.GoldenConfig{}, importer.WithQuarantine(importer.DirQuarantine(dir)))
+			file := "COUNTRY ISO2 CODE,SWIFT CODE\n" + sampleCSV
+
+			summary, err := imp.RunNamed(ctx, "../feed/codes.csv", strings.NewReader(file))
+			Expect(err).To(HaveOccurred())
+			Expect(summary.Quarantine).To(HavePrefix(dir))
+			Expect(summary.Quarantine).To(HaveSuffix("-codes.csv"))
+			Expect(imp.History()[0].Quarantine).To(Equal(summary.Quarantine))
+
+			content, readErr := os.ReadFile(filepath.Join(summary.Quarantine, "codes.csv"))
+			Expect(readErr).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal(file))
+			var report map[string]any
+			content, readErr = os.ReadFile(filepath.Join(summary.Quarantine, "error.json"))
+			Expect(readErr).NotTo(HaveOccurred())
+			Expect(json.Unmarshal(content, &report)).To(Succeed())
+			Expect(report).To(HaveKeyWithValue("file", "codes.csv"))
+			Expect(report).To(HaveKeyWithValue("error", err.Error()))
+		})
+
+		It("should upload to object storage and leave successful runs alone", func() {
+			store := &fakeObjectStore{objects: map[string][]byte{}}
+			imp := importer.NewImporter(repo, importer.GoldenConfig{},
+				importer.WithQuarantine(importer.ObjectQuarantine{Store: store, Prefix: "quarantine"}))
+
+			summary, err := imp.Run(ctx, strings.NewReader(sampleCSV))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(summary.Quarantine).To(BeEmpty())
+			Expect(store.objects).To(BeEmpty())
+
+			repo.CreateBatchFunc = func(ctx context.Context, banks []*models.SwiftBank) error {
+				return errors.New("db error")
+			}
+			summary, err = imp.Run(ctx, strings.NewReader(sampleCSV))
+			Expect(err).To(HaveOccurred())
+			Expect(summary.Quarantine).To(MatchRegexp(`^quarantine/\d{8}T[\d.]+Z-import/$`))
+			Expect(store.objects).To(HaveKeyWithValue(summary.Quarantine+"import", []byte(sampleCSV)))
+			Expect(store.objects).To(HaveKey(summary.Quarantine + "error.json"))
+		})
+	})
+
+	Describe("golden dataset check", func() {
+		It("should pass when every sentinel matches", func() {
+			golden := importer.GoldenConfig{
+				FailOnMismatch: true,
+				Sentinels: []importer.Sentinel{
+					{SwiftCode: "pkopplpwxxx", CountryISOCode: "PL", CountryName: "Poland"},
+				},
+			}
+			_, err := importer.NewImporter(repo, golden).Import(ctx, strings.NewReader(sampleCSV))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should fail the import when a sentinel is missing", func() {
+			golden := importer.GoldenConfig{
+				FailOnMismatch: true,
+				Sentinels:      []importer.Sentinel{{SwiftCode: "BSZLPLP1XXX"}},
+			}
+			count, err := importer.NewImporter(repo, golden).Import(ctx, strings.NewReader(sampleCSV))
+			Expect(err).To(MatchError(importer.ErrGoldenMismatch))
+			Expect(count).To(BeZero())
+			Expect(stored).To(BeEmpty())
+		})
+
+		It("should leave the stored data in place when a file fails the check", func() {
+			stored["PKOPPLPWXXX"] = models.SwiftBank{SwiftCode: "PKOPPLPWXXX", BankName: "BANK PEKAO SA", CountryISOCode: "PL"}
+			golden := importer.GoldenConfig{
+				FailOnMismatch: true,
+				Sentinels:      []importer.Sentinel{{SwiftCode: "PKOPPLPWXXX", CountryISOCode: "PL"}},
+			}
+			broken := strings.Replace(sampleCSV, "PL,PKOPPLPWXXX", "DE,PKOPPLPWXXX", 1)
+			imp := importer.NewImporter(repo, golden)
+
+			_, err := imp.Import(ctx, strings.NewReader(broken))
+			Expect(err).To(MatchError(importer.ErrGoldenMismatch))
+			Expect(stored).To(HaveLen(1))
+			Expect(stored["PKOPPLPWXXX"].CountryISOCode).To(Equal("PL"))
+			Expect(imp.LastLoad()).To(BeNil())
+		})
+
+		It("should accept sentinels already stored by an earlier import", func() {
+			stored["BSZLPLP1XXX"] = models.SwiftBank{SwiftCode: "BSZLPLP1XXX"}
+			golden := importer.GoldenConfig{
+				FailOnMismatch: true,
+				Sentinels:      []importer.Sentinel{{SwiftCode: "BSZLPLP1XXX"}},
+			}
+			_, err := importer.NewImporter(repo, golden).Import(ctx, strings.NewReader(sampleCSV))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should only alert when failing is disabled", func() {
+			golden := importer.GoldenConfig{
+				Sentinels: []importer.Sentinel{{SwiftCode: "PKOPPLPWXXX", BankName: "Another Bank"}},
+			}
+			_, err := importer.NewImporter(repo, golden).Import(ctx, strings.NewReader(sampleCSV))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should describe every mismatching attribute", func() {
+			stored["PKOPPLPWXXX"] = models.SwiftBank{SwiftCode: "PKOPPLPWXXX", BankName: "BANK PEKAO SA", CountryISOCode: "DE"}
+			problems, err := importer.VerifyGolden(ctx, repo, []importer.Sentinel{
+				{SwiftCode: "PKOPPLPWXXX", BankName: "Bank Pekao SA", CountryISOCode: "PL"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(problems).To(ConsistOf(ContainSubstring("country_iso_code")))
+		})
+	})
+
+	Describe("contacts import", func() {
+		var updates []models.BankContact
+
+		BeforeEach(func() {
+			updates = nil
+			repo.UpdateContactsFunc = func(ctx context.Context, contacts []models.BankContact) (int64, error) {
+				updates = append(updates, contacts...)
+				return int64(len(contacts)), nil
+			}
+		})
+
+		It("should store the contacts of each listed code", func() {
+			csv := "SWIFT CODE,NAME,WEBSITE,PHONE\n" +
+				"pkopplpw,BANK PEKAO SA,https://www.pekao.com.pl,+48 22 591 24 00\n" +
+				"PKOPPLPW123,BANK PEKAO SA,,+48 22 000 00 00\n"
+			summary, err := importer.NewImporter(repo, importer.GoldenConfig{}).ImportContacts(ctx, strings.NewReader(csv))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(summary).To(Equal(importer.ContactSummary{Rows: 2, Updated: 2}))
+			Expect(updates).To(Equal([]models.BankContact{
+				{SwiftCode: "PKOPPLPWXXX", Website: "https://www.pekao.com.pl", Phone: "+48 22 591 24 00"},
+				{SwiftCode: "PKOPPLPW123", Phone: "+48 22 000 00 00"},
+			}))
+		})
+
+		It("should skip rows without a valid code or any contact", func() {
+			csv := "SWIFT CODE,WEBSITE\nINVALID,https://example.com\nPKOPPLPWXXX,\n"
+			summary, err := importer.NewImporter(repo, importer.GoldenConfig{}).ImportContacts(ctx, strings.NewReader(csv))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(summary).To(Equal(importer.ContactSummary{Rows: 2, Skipped: 2}))
+			Expect(updates).To(BeEmpty())
+		})
+
+		It("should reject a file without contact columns", func() {
+			_, err := importer.NewImporter(repo, importer.GoldenConfig{}).ImportContacts(ctx, strings.NewReader("SWIFT CODE,NAME\n"))
+			Expect(err).To(MatchError(importer.ErrInvalidContacts))
+		})
+	})
+})
@@ -0,0 +1,137 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// QuarantineConfig says where the files of failed imports are kept
+type QuarantineConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// Dir keeps quarantined files on local disk
+	Dir string `koanf:"dir"`
+	// ObjectPrefix keeps them in the export mirror bucket under this key
+	// prefix instead
+	ObjectPrefix string `koanf:"object_prefix"`
+}
+
+// Validate checks that an enabled quarantine has exactly one location
+func (c QuarantineConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if (c.Dir == "") == (c.ObjectPrefix == "") {
+		return errors.New("quarantine needs exactly one of dir and object_prefix when enabled")
+	}
+	return nil
+}
+
+// QuarantineStore keeps a failed import file and its error report under
+// id and returns where they went
+type QuarantineStore interface {
+	Put(ctx context.Context, id string, files map[string][]byte) (string, error)
+}
+
+// DirQuarantine stores each failed import in its own subdirectory of dir
+type DirQuarantine string
+
+func (d DirQuarantine) Put(_ context.Context, id string, files map[string][]byte) (string, error) {
+	dir := filepath.Join(string(d), id)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", fmt.Errorf("create quarantine directory: %w", err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o640); err != nil {
+			return "", fmt.Errorf("write quarantined %s: %w", name, err)
+		}
+	}
+	return dir, nil
+}
+
+// ObjectPutter uploads objects; *mirror.ObjectStore is one
+type ObjectPutter interface {
+	Put(ctx context.Context, key, contentType string, body []byte) error
+}
+
+// ObjectQuarantine stores each failed import under its own key prefix
+type ObjectQuarantine struct {
+	Store  ObjectPutter
+	Prefix string
+}
+
+func (o ObjectQuarantine) Put(ctx context.Context, id string, files map[string][]byte) (string, error) {
+	prefix := path.Join(o.Prefix, id)
+	for name, content := range files {
+		contentType := "application/octet-stream"
+		if name == reportName {
+			contentType = "application/json"
+		}
+		if err := o.Store.Put(ctx, prefix+"/"+name, contentType, content); err != nil {
+			return "", fmt.Errorf("upload quarantined %s: %w", name, err)
+		}
+	}
+	return prefix + "/", nil
+}
+
+// WithQuarantine copies the input of every failed run and an error report
+// to store, and records the location in the run's summary
+func WithQuarantine(store QuarantineStore) Option {
+	return func(i *Importer) {
+		i.quarantine = store
+	}
+}
+
+// reportName is the error report stored next to a quarantined file
+const reportName = "error.json"
+
+// quarantineReport is the content of the error report
+type quarantineReport struct {
+	File     string    `json:"file"`
+	FailedAt time.Time `json:"failed_at"`
+	Error    string    `json:"error"`
+	Summary  Summary   `json:"summary"`
+}
+
+// quarantineFile keeps content, which failed to import with err, and
+// returns its location; the import has failed already, so a quarantine
+// that cannot be written is only logged by the caller
+func (i *Importer) quarantineFile(ctx context.Context, name string, content []byte, summary Summary, err error) (string, error) {
+	failedAt := time.Now().UTC()
+	name = sanitizeName(name)
+	report, jsonErr := json.MarshalIndent(quarantineReport{File: name, FailedAt: failedAt, Error: err.Error(), Summary: summary}, "", "  ")
+	if jsonErr != nil {
+		return "", jsonErr
+	}
+	if name == reportName {
+		name = "_" + name
+	}
+
+	// Quarantining should not be cut short by the deadline of the run
+	// that failed, which may be what failed it
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Minute)
+	defer cancel()
+	id := failedAt.Format("20060102T150405.000Z") + "-" + name
+	return i.quarantine.Put(ctx, id, map[string][]byte{name: content, reportName: report})
+}
+
+// sanitizeName keeps the base name of a file without characters that
+// would change where it is stored
+func sanitizeName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	if name == "." || name == ".." || name == "/" {
+		return "import"
+	}
+	return strings.Map(func(r rune) rune {
+		if r < ' ' || r == ':' || r == '/' {
+			return '_'
+		}
+		return r
+	}, name)
+}
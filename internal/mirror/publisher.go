@@ -0,0 +1,190 @@
+// Package mirror publishes full-dataset export snapshots to S3-compatible
+// object storage and hands out time-limited signed download URLs, so heavy
+// downloads are served by the object store instead of the API pods.
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zdziszkee/swift-codes/internal/attribution"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	"github.com/zdziszkee/swift-codes/pkg/swiftfile"
+)
+
+// ErrNoSnapshot is returned while nothing has been published yet
+var ErrNoSnapshot = errors.New("no export snapshot published yet")
+
+// Config holds the object storage target of the export mirror
+type Config struct {
+	// Enabled publishes snapshots and serves /v1/swiftCodes/export/latest
+	Enabled bool `koanf:"enabled"`
+	// Endpoint is the object store base URL, e.g. https://s3.eu-central-1.amazonaws.com
+	Endpoint  string `koanf:"endpoint"`
+	Region    string `koanf:"region"`
+	Bucket    string `koanf:"bucket"`
+	AccessKey string `koanf:"access_key"`
+	SecretKey string `koanf:"secret_key"`
+	// PathStyle puts the bucket in the URL path instead of the host name;
+	// MinIO and most self-hosted stores need it
+	PathStyle bool `koanf:"path_style"`
+	// ObjectKey is where the latest snapshot is written
+	ObjectKey string `koanf:"object_key"`
+	// URLExpiry is how long a signed download URL stays valid
+	URLExpiry time.Duration `koanf:"url_expiry"`
+	// Interval is how often the dataset is checked for changes and
+	// republished; 0 only publishes at start-up and on request
+	Interval time.Duration `koanf:"interval"`
+	// UploadTimeout bounds a single snapshot upload
+	UploadTimeout time.Duration `koanf:"upload_timeout"`
+}
+
+// Snapshot describes a published export
+type Snapshot struct {
+	Key         string    `json:"key"`
+	Rows        int       `json:"rows"`
+	Bytes       int       `json:"bytes"`
+	SHA256      string    `json:"sha256"`
+	PublishedAt time.Time `json:"publishedAt"`
+	// DataModifiedAt is the dataset modification time the snapshot covers
+	DataModifiedAt time.Time `json:"dataModifiedAt"`
+}
+
+// Publisher exports the dataset and keeps track of the latest snapshot
+type Publisher struct {
+	repo        repository.SwiftRepository
+	store       *ObjectStore
+	config      Config
+	attribution attribution.Config
+	modified    func() time.Time
+
+	publishing sync.Mutex
+	mu         sync.RWMutex
+	latest     *Snapshot
+}
+
+// NewPublisher creates a publisher that exports repo to the configured
+// bucket, headed by the attribution attr. modified reports when the dataset
+// last changed, so periodic runs skip unchanged data.
+func NewPublisher(repo repository.SwiftRepository, config Config, attr attribution.Config, modified func() time.Time) (*Publisher, error) {
+	store, err := NewObjectStore(config)
+	if err != nil {
+		return nil, err
+	}
+	return &Publisher{repo: repo, store: store, config: config, attribution: attr, modified: modified}, nil
+}
+
+// Publish exports the whole dataset as a SWIFT codes CSV, which the import
+// pipeline can load again, and uploads it as the latest snapshot. The
+// configured attribution precedes the header as comment lines.
+func (p *Publisher) Publish(ctx context.Context) (Snapshot, error) {
+	p.publishing.Lock()
+	defer p.publishing.Unlock()
+
+	// Read the clock first so a write during the export triggers the next run
+	dataModified := p.modified()
+	banks, err := p.repo.ListAll(ctx)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("export dataset: %w", err)
+	}
+	body, err := encodeCSV(banks, p.attribution.CommentLines())
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("encode export: %w", err)
+	}
+	if err := p.store.Put(ctx, p.config.ObjectKey, "text/csv; charset=utf-8", body); err != nil {
+		return Snapshot{}, err
+	}
+
+	snapshot := Snapshot{
+		Key:            p.config.ObjectKey,
+		Rows:           len(banks),
+		Bytes:          len(body),
+		SHA256:         sha256Hex(body),
+		PublishedAt:    time.Now().UTC(),
+		DataModifiedAt: dataModified,
+	}
+	p.mu.Lock()
+	p.latest = &snapshot
+	p.mu.Unlock()
+	return snapshot, nil
+}
+
+// Latest returns the most recent snapshot published by this process
+func (p *Publisher) Latest() (Snapshot, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.latest == nil {
+		return Snapshot{}, ErrNoSnapshot
+	}
+	return *p.latest, nil
+}
+
+// SignedURL returns a download URL for snapshot and when it expires
+func (p *Publisher) SignedURL(snapshot Snapshot) (string, time.Time) {
+	now := time.Now().UTC().Truncate(time.Second)
+	return p.store.PresignGet(snapshot.Key, now, p.config.URLExpiry), now.Add(p.config.URLExpiry)
+}
+
+// Run publishes a snapshot right away and then every Interval when the
+// dataset changed since the last one, until ctx is cancelled
+func (p *Publisher) Run(ctx context.Context) {
+	p.publishLogged(ctx)
+	if p.config.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if latest, err := p.Latest(); err == nil && !p.modified().After(latest.DataModifiedAt) {
+				continue
+			}
+			p.publishLogged(ctx)
+		}
+	}
+}
+
+func (p *Publisher) publishLogged(ctx context.Context) {
+	snapshot, err := p.Publish(ctx)
+	if err != nil {
+		log.Printf("WARNING: export snapshot not published: %v", err)
+		return
+	}
+	log.Printf("Published export snapshot of %d SWIFT codes to %s", snapshot.Rows, snapshot.Key)
+}
+
+// encodeCSV writes banks in the import file layout after the comments, so a
+// snapshot reads back with the town names and time zones it was loaded with
+func encodeCSV(banks []models.SwiftBank, comments []string) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, comment := range comments {
+		buf.WriteString(comment)
+		buf.WriteByte('\n')
+	}
+	w := csv.NewWriter(&buf)
+	if err := w.Write(swiftfile.Columns); err != nil {
+		return nil, err
+	}
+	for _, bank := range banks {
+		codeType := "BIC" + strconv.Itoa(len(bank.SwiftCode))
+		if err := w.Write([]string{
+			bank.CountryISOCode, bank.SwiftCode, codeType, bank.BankName, bank.Address, bank.Town, bank.CountryName, bank.TimeZone,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
@@ -0,0 +1,36 @@
+package models
+
+import "strings"
+
+// HeadquarterBranchCode is the ISO 9362 branch code identifying a head office
+const HeadquarterBranchCode = "XXX"
+
+// BIC8 returns the institution-level part of a BIC (its first 8 characters)
+func BIC8(code string) string {
+	if len(code) < 8 {
+		return code
+	}
+	return code[:8]
+}
+
+// CanonicalBIC returns the BIC11 form of a code. Under ISO 9362:2022 a bare
+// BIC8 is the primary identifier and is equivalent to BIC8 + "XXX".
+func CanonicalBIC(code string) string {
+	if len(code) == 8 {
+		return code + HeadquarterBranchCode
+	}
+	return code
+}
+
+// AlternateBIC returns the other spelling of a head-office BIC (BIC8 for a
+// BIC11 ending in XXX and vice versa), or "" when the code has none.
+func AlternateBIC(code string) string {
+	switch {
+	case len(code) == 8:
+		return code + HeadquarterBranchCode
+	case len(code) == 11 && strings.HasSuffix(code, HeadquarterBranchCode):
+		return code[:8]
+	default:
+		return ""
+	}
+}
@@ -8,4 +8,19 @@ type SwiftBank struct {
 	IsHeadquarter  bool   `db:"is_headquarter"`
 	Address        string `db:"address"`
 	CountryName    string `db:"country_name"`
+	// Town is stored so addresses can be searched by town and TimeZone so
+	// completeness can be measured
+	Town     string `db:"town_name"`
+	TimeZone string `db:"time_zone"`
+	// Website and Phone are optional contact details loaded from
+	// supplementary sources
+	Website string `db:"website"`
+	Phone   string `db:"phone"`
+}
+
+// BankContact carries the contact details of one SWIFT code
+type BankContact struct {
+	SwiftCode string
+	Website   string
+	Phone     string
 }
@@ -1,18 +1,29 @@
 package csv
 
 import (
+	"bytes"
 	"encoding/csv"
-	"fmt"
 	"io"
 	"strings"
 
 	reader "github.com/zdziszkee/swift-codes/internal/readers"
+	"github.com/zdziszkee/swift-codes/pkg/swiftfile"
 )
 
+// CSVSwiftBanksReader reads SWIFT codes CSV files. It keeps the unknown
+// columns of its last load, so concurrent loads need a reader each.
 type CSVSwiftBanksReader struct {
+	// Tolerant accepts files with extra columns after the expected ones,
+	// so new trailing columns in the source do not fail the import
+	Tolerant bool
+
+	unknownColumns []string
 }
 
-const expectedHeader = "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE"
+// UnknownColumns returns the extra columns seen by the last tolerant load
+func (c *CSVSwiftBanksReader) UnknownColumns() []string {
+	return c.unknownColumns
+}
 
 func (c *CSVSwiftBanksReader) LoadSwiftBanks(r io.Reader) ([]reader.SwiftBankRecord, error) {
 	// Handle empty input explicitly
@@ -22,63 +33,43 @@ func (c *CSVSwiftBanksReader) LoadSwiftBanks(r io.Reader) ([]reader.SwiftBankRec
 		}
 	}
 
-	csvReader := csv.NewReader(r)
-	csvReader.TrimLeadingSpace = true
-	csvReader.ReuseRecord = true
-
-	header, err := csvReader.Read()
-	if err != nil {
-		if err == io.EOF {
-			return []reader.SwiftBankRecord{}, nil
-		}
-		return nil, fmt.Errorf("read header: %w", err)
-	}
-
-	// Hardcoded header validation
-	expectedHeaders := strings.Split(expectedHeader, ",")
-	if len(header) != len(expectedHeaders) {
-		return nil, fmt.Errorf("invalid header length: expected %d, got %d", len(expectedHeaders), len(header))
+	c.unknownColumns = nil
+	fileReader, err := swiftfile.NewReader(r, swiftfile.Options{Tolerant: c.Tolerant})
+	if err == io.EOF {
+		return []reader.SwiftBankRecord{}, nil
 	}
-	for i, col := range header {
-		expectedCol := expectedHeaders[i]
-		if strings.TrimSpace(strings.ToUpper(col)) != strings.TrimSpace(strings.ToUpper(expectedCol)) {
-			return nil, fmt.Errorf("invalid header: expected '%s' at index %d, got '%s'", expectedCol, i, col)
-		}
-	}
-
-	// Build a map of column name to index
-	headerMap := map[string]int{}
-	for i, col := range header {
-		headerMap[strings.ToUpper(strings.TrimSpace(col))] = i
+	if err != nil {
+		return nil, err
 	}
+	c.unknownColumns = fileReader.UnknownColumns()
 
 	var records []reader.SwiftBankRecord
-	rowNum := 1
-	for {
-		row, err := csvReader.Read()
-		if err == io.EOF {
-			break
-		}
+	for record, err := range fileReader.All() {
 		if err != nil {
-			return nil, fmt.Errorf("row %d: %w", rowNum, err)
-		}
-		if len(row) != len(expectedHeaders) {
-			return nil, fmt.Errorf("row %d: invalid length", rowNum)
-		}
-
-		// This is the key fix - make sure we're using the right column indices
-		record := reader.SwiftBankRecord{
-			Index:          rowNum,
-			CountryISOCode: strings.TrimSpace(row[headerMap["COUNTRY ISO2 CODE"]]),
-			SwiftCode:      strings.TrimSpace(row[headerMap["SWIFT CODE"]]),
-			BankName:       strings.TrimSpace(row[headerMap["NAME"]]),
-			Address:        strings.TrimSpace(row[headerMap["ADDRESS"]]),
-			CountryName:    strings.TrimSpace(row[headerMap["COUNTRY NAME"]]),
+			return nil, err
 		}
-
-		records = append(records, record)
-		rowNum++
+		records = append(records, reader.SwiftBankRecord{
+			Index:          record.Line,
+			CountryISOCode: record.CountryISOCode,
+			SwiftCode:      record.SwiftCode,
+			BankName:       record.BankName,
+			Address:        record.Address,
+			TownName:       record.TownName,
+			TimeZone:       record.TimeZone,
+			CountryName:    record.CountryName,
+		})
 	}
 
 	return records, nil
 }
+
+// LoadRows loads rows decoded from another format, the first one being the
+// header, with the same header checks as a CSV file
+func (c *CSVSwiftBanksReader) LoadRows(rows [][]string) ([]reader.SwiftBankRecord, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return c.LoadSwiftBanks(&buf)
+}
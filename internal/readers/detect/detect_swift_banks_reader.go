@@ -0,0 +1,110 @@
+package detect
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+
+	reader "github.com/zdziszkee/swift-codes/internal/readers"
+	csvreader "github.com/zdziszkee/swift-codes/internal/readers/csv"
+	"github.com/zdziszkee/swift-codes/internal/readers/fixedwidth"
+	jsonreader "github.com/zdziszkee/swift-codes/internal/readers/json"
+	"github.com/zdziszkee/swift-codes/internal/readers/xlsx"
+)
+
+// Formats recognized by Detect
+const (
+	FormatCSV        = "csv"
+	FormatXLSX       = "xlsx"
+	FormatJSON       = "json"
+	FormatFixedWidth = "fixed-width"
+)
+
+// sniffLen is how much of a file Detect looks at
+const sniffLen = 4096
+
+var (
+	zipMagic = []byte("PK\x03\x04")
+	// cfbMagic starts legacy binary .xls workbooks
+	cfbMagic = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+	utf8BOM  = []byte("\xEF\xBB\xBF")
+)
+
+// ErrLegacyXLS rejects binary Excel 97-2003 workbooks
+var ErrLegacyXLS = errors.New("legacy .xls workbooks are not supported; save the file as .xlsx or CSV")
+
+// Detect names the format of a file from its first bytes: the zip magic
+// for XLSX, an opening bracket or brace for JSON, and a header line that
+// names the SWIFT CODE column without any comma for fixed-width text.
+// Everything else is read as CSV.
+func Detect(head []byte) string {
+	if bytes.HasPrefix(head, zipMagic) {
+		return FormatXLSX
+	}
+	text := bytes.TrimLeft(bytes.TrimPrefix(head, utf8BOM), " \t\r\n")
+	if len(text) > 0 && (text[0] == '[' || text[0] == '{') {
+		return FormatJSON
+	}
+	line, _, _ := bytes.Cut(text, []byte("\n"))
+	if !bytes.ContainsRune(line, ',') && bytes.Contains(bytes.ToUpper(line), []byte("SWIFT CODE")) {
+		return FormatFixedWidth
+	}
+	return FormatCSV
+}
+
+// SwiftBanksReader detects the format of every file it loads and hands it
+// to the matching reader. It keeps the format and reader of its last load,
+// so concurrent loads need a reader each.
+type SwiftBanksReader struct {
+	// Tolerant accepts extra columns after the expected ones in CSV, XLSX
+	// and fixed-width files
+	Tolerant bool
+
+	format string
+	last   reader.SwiftBanksReader
+}
+
+// Format returns the format detected by the last load
+func (d *SwiftBanksReader) Format() string {
+	return d.format
+}
+
+// UnknownColumns returns the columns ignored by the last load
+func (d *SwiftBanksReader) UnknownColumns() []string {
+	if reporter, ok := d.last.(reader.UnknownColumnsReporter); ok {
+		return reporter.UnknownColumns()
+	}
+	return nil
+}
+
+func (d *SwiftBanksReader) LoadSwiftBanks(r io.Reader) ([]reader.SwiftBankRecord, error) {
+	buffered := bufio.NewReaderSize(r, sniffLen)
+	head, err := buffered.Peek(sniffLen)
+	if err != nil && err != io.EOF && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, err
+	}
+	if bytes.HasPrefix(head, cfbMagic) {
+		return nil, ErrLegacyXLS
+	}
+	// Empty input keeps the CSV reader's handling of it
+	if len(head) == 0 {
+		buffered = nil
+	}
+
+	d.format = Detect(head)
+	switch d.format {
+	case FormatXLSX:
+		d.last = &xlsx.XLSXSwiftBanksReader{Tolerant: d.Tolerant}
+	case FormatJSON:
+		d.last = &jsonreader.JSONSwiftBanksReader{}
+	case FormatFixedWidth:
+		d.last = &fixedwidth.FixedWidthSwiftBanksReader{Tolerant: d.Tolerant}
+	default:
+		d.last = &csvreader.CSVSwiftBanksReader{Tolerant: d.Tolerant}
+	}
+	if buffered == nil {
+		return d.last.LoadSwiftBanks(r)
+	}
+	return d.last.LoadSwiftBanks(buffered)
+}
@@ -0,0 +1,150 @@
+package reader_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	reader "github.com/zdziszkee/swift-codes/internal/readers"
+	"github.com/zdziszkee/swift-codes/internal/readers/detect"
+	"github.com/zdziszkee/swift-codes/internal/readers/fixedwidth"
+	jsonreader "github.com/zdziszkee/swift-codes/internal/readers/json"
+	"github.com/zdziszkee/swift-codes/internal/readers/xlsx"
+)
+
+// pekao is the record every format below describes
+var pekao = reader.SwiftBankRecord{
+	Index:          1,
+	CountryISOCode: "PL",
+	SwiftCode:      "PKOPPLPWXXX",
+	BankName:       "BANK PEKAO SA",
+	Address:        "ZUBRA 1",
+	TownName:       "WARSZAWA",
+	TimeZone:       "Europe/Warsaw",
+	CountryName:    "POLAND",
+}
+
+// workbook builds a minimal XLSX file whose first sheet holds the header
+// as inline strings and one row through the shared string table
+func workbook() []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+			`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Codes" sheetId="1" r:id="rId7"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId7" Type="worksheet" Target="worksheets/codes.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst><si><t>PL</t></si><si><t>PKOPPLPWXXX</t></si><si><r><t>BANK </t></r><r><t>PEKAO SA</t></r></si></sst>`,
+		"xl/worksheets/codes.xml": `<worksheet><sheetData>` +
+			`<row r="1">` + inlineCells("COUNTRY ISO2 CODE", "SWIFT CODE", "CODE TYPE", "NAME", "ADDRESS", "TOWN NAME", "COUNTRY NAME", "TIME ZONE") + `</row>` +
+			`<row r="2"><c r="A2" t="s"><v>0</v></c><c r="B2" t="s"><v>1</v></c><c r="D2" t="s"><v>2</v></c>` +
+			inlineCell("E2", "ZUBRA 1") + inlineCell("F2", "WARSZAWA") + inlineCell("G2", "POLAND") + inlineCell("H2", "Europe/Warsaw") + `</row>` +
+			`<row r="4"><c r="A4"/></row>` +
+			`</sheetData></worksheet>`,
+	}
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = w.Write([]byte(content))
+		Expect(err).NotTo(HaveOccurred())
+	}
+	Expect(zw.Close()).To(Succeed())
+	return buf.Bytes()
+}
+
+func inlineCells(texts ...string) string {
+	var cells string
+	for i, text := range texts {
+		cells += inlineCell(string(rune('A'+i))+"1", text)
+	}
+	return cells
+}
+
+func inlineCell(ref, text string) string {
+	return `<c r="` + ref + `" t="inlineStr"><is><t>` + text + `</t></is></c>`
+}
+
+const fixedWidthFile = "" +
+	"COUNTRY ISO2 CODE  SWIFT CODE   CODE TYPE  NAME            ADDRESS   TOWN NAME  COUNTRY NAME  TIME ZONE\n" +
+	"PL                 PKOPPLPWXXX  BIC11      BANK PEKAO SA   ZUBRA 1   WARSZAWA   POLAND        Europe/Warsaw\n" +
+	"\n"
+
+var _ = Describe("Format detection", func() {
+	It("should recognize every supported format", func() {
+		Expect(detect.Detect(workbook())).To(Equal(detect.FormatXLSX))
+		Expect(detect.Detect([]byte("\xEF\xBB\xBF  [{\"SWIFT CODE\":\"PKOPPLPWXXX\"}]"))).To(Equal(detect.FormatJSON))
+		Expect(detect.Detect([]byte(fixedWidthFile))).To(Equal(detect.FormatFixedWidth))
+		Expect(detect.Detect([]byte("COUNTRY ISO2 CODE,SWIFT CODE\n"))).To(Equal(detect.FormatCSV))
+		Expect(detect.Detect([]byte("anything else"))).To(Equal(detect.FormatCSV))
+	})
+
+	It("should dispatch to the reader of the detected format", func() {
+		r := &detect.SwiftBanksReader{}
+		records, err := r.LoadSwiftBanks(bytes.NewReader(workbook()))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Format()).To(Equal(detect.FormatXLSX))
+		Expect(records).To(Equal([]reader.SwiftBankRecord{pekao}))
+
+		records, err = r.LoadSwiftBanks(strings.NewReader(fixedWidthFile))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Format()).To(Equal(detect.FormatFixedWidth))
+		Expect(records).To(Equal([]reader.SwiftBankRecord{pekao}))
+	})
+
+	It("should reject legacy binary workbooks", func() {
+		_, err := (&detect.SwiftBanksReader{}).LoadSwiftBanks(bytes.NewReader([]byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1, 0}))
+		Expect(err).To(MatchError(detect.ErrLegacyXLS))
+	})
+})
+
+var _ = Describe("JSONSwiftBanksReader", func() {
+	It("should accept file column names and report unknown keys", func() {
+		r := &jsonreader.JSONSwiftBanksReader{}
+		records, err := r.LoadSwiftBanks(strings.NewReader(`[{"COUNTRY ISO2 CODE":"PL","SWIFT CODE":"PKOPPLPWXXX",` +
+			`"NAME":"BANK PEKAO SA","ADDRESS":"ZUBRA 1","town_name":"WARSZAWA","countryName":"POLAND",` +
+			`"timeZone":"Europe/Warsaw","rating":"A"}]`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(Equal([]reader.SwiftBankRecord{pekao}))
+		Expect(r.UnknownColumns()).To(Equal([]string{"rating"}))
+	})
+
+	It("should reject documents that hold no list of codes", func() {
+		_, err := (&jsonreader.JSONSwiftBanksReader{}).LoadSwiftBanks(strings.NewReader(`{"bank":{}}`))
+		Expect(err).To(HaveOccurred())
+
+		_, err = (&jsonreader.JSONSwiftBanksReader{}).LoadSwiftBanks(strings.NewReader(`[{"SWIFT CODE":7}]`))
+		Expect(err).To(MatchError(ContainSubstring("must be a string")))
+	})
+})
+
+var _ = Describe("FixedWidthSwiftBanksReader", func() {
+	header := "COUNTRY ISO2 CODE  SWIFT CODE   CODE TYPE  NAME            ADDRESS   TOWN NAME  COUNTRY NAME  TIME ZONE      CITY CODE\n"
+	row := "PL                 PKOPPLPWXXX  BIC11      BANK PEKAO SA   ZUBRA 1   WARSZAWA   POLAND        Europe/Warsaw  WAW\n"
+
+	It("should reject extra columns unless tolerant", func() {
+		_, err := (&fixedwidth.FixedWidthSwiftBanksReader{}).LoadSwiftBanks(strings.NewReader(header + row))
+		Expect(err).To(HaveOccurred())
+
+		r := &fixedwidth.FixedWidthSwiftBanksReader{Tolerant: true}
+		records, err := r.LoadSwiftBanks(strings.NewReader(header + row))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(Equal([]reader.SwiftBankRecord{pekao}))
+		Expect(r.UnknownColumns()).To(Equal([]string{"CITY CODE"}))
+	})
+
+	It("should reject headers missing a column", func() {
+		_, err := (&fixedwidth.FixedWidthSwiftBanksReader{}).LoadSwiftBanks(strings.NewReader("SWIFT CODE  NAME\n"))
+		Expect(err).To(MatchError(ContainSubstring("COUNTRY ISO2 CODE")))
+	})
+})
+
+var _ = Describe("XLSXSwiftBanksReader", func() {
+	It("should reject files that are not workbooks", func() {
+		_, err := (&xlsx.XLSXSwiftBanksReader{}).LoadSwiftBanks(strings.NewReader("PK\x03\x04 truncated"))
+		Expect(err).To(HaveOccurred())
+	})
+})
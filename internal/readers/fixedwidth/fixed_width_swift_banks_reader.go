@@ -0,0 +1,119 @@
+package fixedwidth
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+
+	reader "github.com/zdziszkee/swift-codes/internal/readers"
+	csvreader "github.com/zdziszkee/swift-codes/internal/readers/csv"
+	"github.com/zdziszkee/swift-codes/pkg/swiftfile"
+)
+
+// columnGap separates the names of extra columns in the header
+var columnGap = regexp.MustCompile(`\s{2,}`)
+
+// FixedWidthSwiftBanksReader reads space-aligned text files. Column
+// boundaries are taken from the header: each column starts where its name
+// starts and ends where the next one begins. Positions count characters,
+// not bytes.
+type FixedWidthSwiftBanksReader struct {
+	// Tolerant accepts extra columns after the expected ones
+	Tolerant bool
+
+	unknownColumns []string
+}
+
+// UnknownColumns returns the extra columns seen by the last tolerant load
+func (f *FixedWidthSwiftBanksReader) UnknownColumns() []string {
+	return f.unknownColumns
+}
+
+func (f *FixedWidthSwiftBanksReader) LoadSwiftBanks(r io.Reader) ([]reader.SwiftBankRecord, error) {
+	f.unknownColumns = nil
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var (
+		rows   [][]string
+		starts []int
+		end    int
+	)
+	for scanner.Scan() {
+		line := []rune(strings.TrimRight(strings.TrimPrefix(scanner.Text(), "\ufeff"), "\r"))
+		if strings.TrimSpace(string(line)) == "" {
+			continue
+		}
+		if starts == nil {
+			var err error
+			if starts, end, err = f.header(string(line)); err != nil {
+				return nil, err
+			}
+			rows = append(rows, swiftfile.Columns)
+			continue
+		}
+
+		row := make([]string, len(starts))
+		for i, start := range starts {
+			stop := end
+			if i+1 < len(starts) {
+				stop = starts[i+1]
+			}
+			if stop < 0 || stop > len(line) {
+				stop = len(line)
+			}
+			if start < len(line) {
+				row[i] = strings.TrimSpace(string(line[start:stop]))
+			}
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read fixed-width file: %w", err)
+	}
+
+	return (&csvreader.CSVSwiftBanksReader{}).LoadRows(rows)
+}
+
+// header finds the start of every expected column in line and where the
+// last one ends; -1 runs it to the end of each row
+func (f *FixedWidthSwiftBanksReader) header(line string) (starts []int, end int, err error) {
+	upper := []rune(strings.ToUpper(line))
+	pos := 0
+	for _, column := range swiftfile.Columns {
+		at := indexRunes(upper[pos:], []rune(column))
+		if at < 0 {
+			return nil, 0, fmt.Errorf("invalid fixed-width header: '%s' not found in order", column)
+		}
+		starts = append(starts, pos+at)
+		pos += at + len([]rune(column))
+	}
+
+	tail := []rune(line)[pos:]
+	extra := strings.TrimSpace(string(tail))
+	if extra == "" {
+		return starts, -1, nil
+	}
+	if !f.Tolerant {
+		return nil, 0, fmt.Errorf("invalid fixed-width header: unexpected columns after '%s'", swiftfile.Columns[len(swiftfile.Columns)-1])
+	}
+	f.unknownColumns = columnGap.Split(extra, -1)
+	for i, r := range tail {
+		if !unicode.IsSpace(r) {
+			return starts, pos + i, nil
+		}
+	}
+	return starts, -1, nil
+}
+
+func indexRunes(s, sub []rune) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		if string(s[i:i+len(sub)]) == string(sub) {
+			return i
+		}
+	}
+	return -1
+}
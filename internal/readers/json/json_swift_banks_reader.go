@@ -0,0 +1,110 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	reader "github.com/zdziszkee/swift-codes/internal/readers"
+	csvreader "github.com/zdziszkee/swift-codes/internal/readers/csv"
+	"github.com/zdziszkee/swift-codes/pkg/swiftfile"
+)
+
+// keyColumns maps normalized object keys to file columns. Both the file
+// column names and the keys of the API responses are accepted, so exported
+// listings can be loaded again.
+var keyColumns = map[string]string{
+	"countryiso2code": "COUNTRY ISO2 CODE",
+	"countryiso2":     "COUNTRY ISO2 CODE",
+	"countryisocode":  "COUNTRY ISO2 CODE",
+	"swiftcode":       "SWIFT CODE",
+	"codetype":        "CODE TYPE",
+	"name":            "NAME",
+	"bankname":        "NAME",
+	"address":         "ADDRESS",
+	"townname":        "TOWN NAME",
+	"town":            "TOWN NAME",
+	"countryname":     "COUNTRY NAME",
+	"timezone":        "TIME ZONE",
+}
+
+// derivedKeys are API response keys the parser computes itself
+var derivedKeys = map[string]bool{"swiftcodebase": true, "isheadquarter": true}
+
+// JSONSwiftBanksReader reads an array of SWIFT code objects, either at the
+// top level or under "swift_codes" as in the country listing response.
+// Keys are matched ignoring case, spaces and underscores; other keys are
+// skipped and reported by UnknownColumns. A reader keeps the keys of its
+// last load, so concurrent loads need a reader each.
+type JSONSwiftBanksReader struct {
+	unknownColumns []string
+}
+
+// UnknownColumns returns the keys skipped by the last load
+func (j *JSONSwiftBanksReader) UnknownColumns() []string {
+	return j.unknownColumns
+}
+
+func (j *JSONSwiftBanksReader) LoadSwiftBanks(r io.Reader) ([]reader.SwiftBankRecord, error) {
+	j.unknownColumns = nil
+
+	var doc json.RawMessage
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode JSON: %w", err)
+	}
+	var objects []map[string]any
+	if err := json.Unmarshal(doc, &objects); err != nil {
+		var listing struct {
+			SwiftCodes []map[string]any `json:"swift_codes"`
+		}
+		if err := json.Unmarshal(doc, &listing); err != nil || listing.SwiftCodes == nil {
+			return nil, fmt.Errorf("JSON must be an array of objects or have a swift_codes array")
+		}
+		objects = listing.SwiftCodes
+	}
+
+	index := make(map[string]int, len(swiftfile.Columns))
+	for i, column := range swiftfile.Columns {
+		index[column] = i
+	}
+	unknown := map[string]bool{}
+	rows := [][]string{swiftfile.Columns}
+	for n, object := range objects {
+		row := make([]string, len(swiftfile.Columns))
+		for key, value := range object {
+			normalized := normalizeKey(key)
+			column, ok := keyColumns[normalized]
+			if !ok {
+				if !derivedKeys[normalized] {
+					unknown[key] = true
+				}
+				continue
+			}
+			text, ok := value.(string)
+			if !ok && value != nil {
+				return nil, fmt.Errorf("object %d: %q must be a string", n+1, key)
+			}
+			row[index[column]] = text
+		}
+		rows = append(rows, row)
+	}
+	for key := range unknown {
+		j.unknownColumns = append(j.unknownColumns, key)
+	}
+	sort.Strings(j.unknownColumns)
+
+	return (&csvreader.CSVSwiftBanksReader{}).LoadRows(rows)
+}
+
+// normalizeKey lower-cases key and drops everything but letters and digits
+func normalizeKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, key)
+}
@@ -11,9 +11,23 @@ type SwiftBankRecord struct {
 	SwiftCode      string // SWIFT CODE
 	BankName       string // NAME
 	Address        string // ADDRESS
+	TownName       string // TOWN NAME
+	TimeZone       string // TIME ZONE
 	CountryName    string // COUNTRY NAME
 }
 
+// UnknownColumnsReporter is implemented by readers that tolerate columns
+// they do not know and can list the ones seen by the last load
+type UnknownColumnsReporter interface {
+	UnknownColumns() []string
+}
+
+// FormatReporter is implemented by readers that detect the file format and
+// can name the one of the last load
+type FormatReporter interface {
+	Format() string
+}
+
 // SwiftBanksLoader defines the interface for loading bank data
 type SwiftBanksReader interface {
 	LoadSwiftBanks(reader io.Reader) ([]SwiftBankRecord, error) // Changed to accept io.Reader and return []models.SwiftBank
@@ -66,6 +66,26 @@ var _ = Describe("CSVSwiftBanksReader", func() {
 					Expect(record.CountryName).To(Equal("United States"))
 				})
 
+		It("should reject extra columns unless tolerant", func() {
+			input := "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE,CITY CODE\n" +
+				"US,CHASUS33,N,Chase Bank,123 Main St,New York,United States,EST,NYC"
+			_, err := csvReader.LoadSwiftBanks(strings.NewReader(input))
+			Expect(err).To(MatchError(ContainSubstring("invalid header length")))
+
+			tolerant := &csv.CSVSwiftBanksReader{Tolerant: true}
+			records, err := tolerant.LoadSwiftBanks(strings.NewReader(input))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(records).To(HaveLen(1))
+			Expect(records[0].CountryName).To(Equal("United States"))
+			Expect(tolerant.UnknownColumns()).To(Equal([]string{"CITY CODE"}))
+		})
+
+		It("should still require the expected columns when tolerant", func() {
+			input := "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,BANK NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE,CITY CODE"
+			_, err := (&csv.CSVSwiftBanksReader{Tolerant: true}).LoadSwiftBanks(strings.NewReader(input))
+			Expect(err).To(MatchError(ContainSubstring("invalid header")))
+		})
+
 		It("should reject invalid header with missing column", func() {
 			input := "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME"
 			_, err := csvReader.LoadSwiftBanks(strings.NewReader(input))
@@ -0,0 +1,227 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	reader "github.com/zdziszkee/swift-codes/internal/readers"
+	csvreader "github.com/zdziszkee/swift-codes/internal/readers/csv"
+)
+
+// maxUncompressed bounds each workbook part so a zip bomb cannot exhaust
+// memory
+const maxUncompressed = 256 << 20
+
+// XLSXSwiftBanksReader reads the first worksheet of an Office Open XML
+// workbook, whose first row must be the usual header
+type XLSXSwiftBanksReader struct {
+	// Tolerant accepts extra columns after the expected ones
+	Tolerant bool
+
+	csv *csvreader.CSVSwiftBanksReader
+}
+
+// UnknownColumns returns the extra columns seen by the last tolerant load
+func (x *XLSXSwiftBanksReader) UnknownColumns() []string {
+	if x.csv == nil {
+		return nil
+	}
+	return x.csv.UnknownColumns()
+}
+
+func (x *XLSXSwiftBanksReader) LoadSwiftBanks(r io.Reader) ([]reader.SwiftBankRecord, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read workbook: %w", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("open workbook: %w", err)
+	}
+	parts := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		parts[f.Name] = f
+	}
+
+	sheet, err := firstSheet(parts)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := sharedStrings(parts)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := sheetRows(parts, sheet, shared)
+	if err != nil {
+		return nil, err
+	}
+
+	x.csv = &csvreader.CSVSwiftBanksReader{Tolerant: x.Tolerant}
+	return x.csv.LoadRows(rows)
+}
+
+// firstSheet resolves the part name of the first worksheet through the
+// workbook relationships
+func firstSheet(parts map[string]*zip.File) (string, error) {
+	var workbook struct {
+		Sheets []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodePart(parts, "xl/workbook.xml", &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", fmt.Errorf("workbook has no worksheets")
+	}
+
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodePart(parts, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].ID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return "", fmt.Errorf("workbook relationship %q not found", workbook.Sheets[0].ID)
+}
+
+// richText is a string item; rich text runs are joined
+type richText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t richText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+// sharedStrings returns the shared string table; workbooks with only inline
+// strings have none
+func sharedStrings(parts map[string]*zip.File) ([]string, error) {
+	if parts["xl/sharedStrings.xml"] == nil {
+		return nil, nil
+	}
+	var table struct {
+		Items []richText `xml:"si"`
+	}
+	if err := decodePart(parts, "xl/sharedStrings.xml", &table); err != nil {
+		return nil, err
+	}
+	strs := make([]string, len(table.Items))
+	for i, item := range table.Items {
+		strs[i] = item.String()
+	}
+	return strs, nil
+}
+
+// sheetRows returns the cell text of every non-empty row, padded to the
+// width of the widest one
+func sheetRows(parts map[string]*zip.File, name string, shared []string) ([][]string, error) {
+	var sheet struct {
+		Rows []struct {
+			Cells []struct {
+				Ref    string   `xml:"r,attr"`
+				Type   string   `xml:"t,attr"`
+				Value  string   `xml:"v"`
+				Inline richText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := decodePart(parts, name, &sheet); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	width := 0
+	for _, row := range sheet.Rows {
+		var cells []string
+		for i, cell := range row.Cells {
+			col := columnIndex(cell.Ref)
+			if col < 0 {
+				col = i
+			}
+			var text string
+			switch cell.Type {
+			case "s":
+				idx, err := strconv.Atoi(cell.Value)
+				if err != nil || idx < 0 || idx >= len(shared) {
+					return nil, fmt.Errorf("cell %s: invalid shared string %q", cell.Ref, cell.Value)
+				}
+				text = shared[idx]
+			case "inlineStr":
+				text = cell.Inline.String()
+			default:
+				text = cell.Value
+			}
+			for len(cells) <= col {
+				cells = append(cells, "")
+			}
+			cells[col] = text
+		}
+		if strings.TrimSpace(strings.Join(cells, "")) == "" {
+			continue
+		}
+		width = max(width, len(cells))
+		rows = append(rows, cells)
+	}
+	for i := range rows {
+		for len(rows[i]) < width {
+			rows[i] = append(rows[i], "")
+		}
+	}
+	return rows, nil
+}
+
+// columnIndex returns the 0-based column of a cell reference such as "AB12"
+func columnIndex(ref string) int {
+	col := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+	}
+	return col - 1
+}
+
+func decodePart(parts map[string]*zip.File, name string, v any) error {
+	part := parts[name]
+	if part == nil {
+		return fmt.Errorf("workbook part %s is missing", name)
+	}
+	rc, err := part.Open()
+	if err != nil {
+		return fmt.Errorf("open workbook part %s: %w", name, err)
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(io.LimitReader(rc, maxUncompressed)).Decode(v); err != nil {
+		return fmt.Errorf("decode workbook part %s: %w", name, err)
+	}
+	return nil
+}
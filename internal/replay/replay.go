@@ -0,0 +1,249 @@
+// Package replay sends the GET requests of a JSON access log (log.format =
+// "json") to another environment, keeping the recorded pacing or a multiple
+// of it, so that performance work can be checked against production-shaped
+// traffic. Writes are never replayed.
+package replay
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+)
+
+// maxLineLength caps an access log line; a longer one stops the replay with
+// an error
+const maxLineLength = 1 << 20
+
+// Config describes a replay
+type Config struct {
+	// Target is the base URL requests are sent to, e.g.
+	// http://staging:8081
+	Target string
+	// Speed multiplies the recorded pace: 1 keeps the original gaps between
+	// requests, 2 halves them, and 0 sends requests as fast as Concurrency
+	// allows
+	Speed float64
+	// Concurrency caps the requests in flight; when reached, the replay
+	// falls behind the recorded pace rather than piling requests up
+	Concurrency int
+	// Timeout bounds every request
+	Timeout time.Duration
+	// Header is sent with every request, e.g. an Authorization token
+	Header http.Header
+	// Exclude lists path prefixes that are not replayed, such as the event
+	// stream
+	Exclude []string
+	// Limit stops the replay after this many requests; 0 replays them all
+	Limit int
+}
+
+// Validate checks the target, speed, concurrency and timeout
+func (c Config) Validate() error {
+	target, err := url.Parse(c.Target)
+	if err != nil || target.Scheme == "" || target.Host == "" {
+		return fmt.Errorf("replay target %q must be an absolute URL", c.Target)
+	}
+	if c.Speed < 0 {
+		return errors.New("replay speed cannot be negative")
+	}
+	if c.Concurrency < 1 {
+		return errors.New("replay concurrency must be at least 1")
+	}
+	if c.Timeout <= 0 {
+		return errors.New("replay timeout must be positive")
+	}
+	if c.Limit < 0 {
+		return errors.New("replay limit cannot be negative")
+	}
+	return nil
+}
+
+// Summary is the outcome of a replay
+type Summary struct {
+	// Sent counts the requests that got an answer
+	Sent int
+	// Failed counts the requests that got none: refused connections,
+	// timeouts and the like
+	Failed int
+	// Skipped counts the log lines not replayed: other methods, excluded
+	// paths and lines that are not access records
+	Skipped int
+	// Mismatched counts the answers whose status differs from the recorded
+	// one, a sign that the target does not hold the same data
+	Mismatched int
+	// Statuses counts the answers by status code
+	Statuses map[int]int
+	Duration time.Duration
+	// P50, P90, P99 and Max are latencies of the answered requests
+	P50, P90, P99, Max time.Duration
+}
+
+// Run replays the records read from logs until they or ctx run out. An
+// error is returned only when logs cannot be read; failing requests are
+// counted in the summary.
+func Run(ctx context.Context, logs io.Reader, cfg Config, client *http.Client) (*Summary, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	target := strings.TrimRight(cfg.Target, "/")
+
+	var (
+		mu        sync.Mutex
+		summary   = &Summary{Statuses: make(map[int]int)}
+		latencies []time.Duration
+	)
+	send := func(record middleware.AccessRecord) {
+		rawURL := target + record.Path
+		if record.Query != "" {
+			rawURL += "?" + record.Query
+		}
+		status, latency, err := get(ctx, client, rawURL, cfg)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			summary.Failed++
+			return
+		}
+		summary.Sent++
+		summary.Statuses[status]++
+		if record.Status != 0 && status != record.Status {
+			summary.Mismatched++
+		}
+		latencies = append(latencies, latency)
+	}
+
+	queue := make(chan middleware.AccessRecord)
+	var workers sync.WaitGroup
+	for range cfg.Concurrency {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for record := range queue {
+				send(record)
+			}
+		}()
+	}
+
+	start := time.Now()
+	skipped, err := dispatch(ctx, logs, cfg, queue)
+	close(queue)
+	workers.Wait()
+	summary.Duration = time.Since(start)
+	summary.Skipped = skipped
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	summary.P50 = percentile(latencies, 0.50)
+	summary.P90 = percentile(latencies, 0.90)
+	summary.P99 = percentile(latencies, 0.99)
+	if len(latencies) > 0 {
+		summary.Max = latencies[len(latencies)-1]
+	}
+	return summary, err
+}
+
+// dispatch queues the replayable records of logs at their scaled pace and
+// returns how many lines it skipped
+func dispatch(ctx context.Context, logs io.Reader, cfg Config, queue chan<- middleware.AccessRecord) (skipped int, err error) {
+	scanner := bufio.NewScanner(logs)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+
+	var first time.Time
+	start := time.Now()
+	queued := 0
+	for scanner.Scan() {
+		var record middleware.AccessRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil || !replayable(record, cfg.Exclude) {
+			skipped++
+			continue
+		}
+		if cfg.Limit > 0 && queued == cfg.Limit {
+			return skipped, nil
+		}
+
+		if cfg.Speed > 0 && !record.Time.IsZero() {
+			if first.IsZero() {
+				first = record.Time
+			}
+			// Records are logged as requests finish, so one may come
+			// slightly after a later arrival; it is then sent at once
+			due := start.Add(time.Duration(float64(record.Time.Sub(first)) / cfg.Speed))
+			if wait := time.Until(due); wait > 0 {
+				select {
+				case <-ctx.Done():
+					return skipped, nil
+				case <-time.After(wait):
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return skipped, nil
+		case queue <- record:
+			queued++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return skipped, fmt.Errorf("reading access log: %w", err)
+	}
+	return skipped, nil
+}
+
+// replayable reports whether record is a GET outside the excluded paths
+func replayable(record middleware.AccessRecord, exclude []string) bool {
+	if record.Method != http.MethodGet || !strings.HasPrefix(record.Path, "/") {
+		return false
+	}
+	for _, prefix := range exclude {
+		if prefix != "" && strings.HasPrefix(record.Path, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// get sends one request and returns its status and how long the answer took
+func get(ctx context.Context, client *http.Client, rawURL string, cfg Config) (int, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	for name, values := range cfg.Header {
+		req.Header[name] = values
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	// The body is read so the latency covers the whole answer
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return 0, 0, err
+	}
+	return resp.StatusCode, time.Since(start), nil
+}
+
+// percentile returns the latency below which fraction p of sorted falls
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(float64(len(sorted))*p+0.5) - 1
+	return sorted[max(0, min(i, len(sorted)-1))]
+}
@@ -0,0 +1,125 @@
+package replay_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/api/middleware"
+	"github.com/zdziszkee/swift-codes/internal/replay"
+)
+
+func TestReplay(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Replay Suite")
+}
+
+var _ = Describe("Run", func() {
+	var (
+		server   *httptest.Server
+		mu       sync.Mutex
+		received []string
+		cfg      replay.Config
+	)
+
+	BeforeEach(func() {
+		received = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			received = append(received, r.URL.RequestURI()+" "+r.Header.Get("Authorization"))
+			mu.Unlock()
+			if strings.HasSuffix(r.URL.Path, "/MISSINGXXXX") {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		DeferCleanup(server.Close)
+		cfg = replay.Config{Target: server.URL + "/", Concurrency: 2, Timeout: time.Second}
+	})
+
+	accessLog := func(records ...middleware.AccessRecord) string {
+		var b strings.Builder
+		for _, record := range records {
+			line, err := json.Marshal(record)
+			Expect(err).NotTo(HaveOccurred())
+			b.Write(line)
+			b.WriteByte('\n')
+		}
+		return b.String()
+	}
+
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+
+	It("should replay GET requests with their query strings and count the outcomes", func() {
+		logs := accessLog(
+			middleware.AccessRecord{Time: start, Method: http.MethodGet, Path: "/v1/swiftCodes/PKOPPLPWXXX", Query: "fields=bankName", Status: 200},
+			middleware.AccessRecord{Time: start, Method: http.MethodPost, Path: "/v1/swiftCodes", Status: 201},
+			middleware.AccessRecord{Time: start, Method: http.MethodGet, Path: "/v1/swiftCodes/MISSINGXXXX", Status: 200},
+			middleware.AccessRecord{Time: start, Method: http.MethodGet, Path: "/v1/events", Status: 200},
+		) + "Starting server on port 8081\n"
+		cfg.Header = http.Header{"Authorization": {"Bearer token"}}
+		cfg.Exclude = []string{"/v1/events"}
+
+		summary, err := replay.Run(context.Background(), strings.NewReader(logs), cfg, server.Client())
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(received).To(ConsistOf(
+			"/v1/swiftCodes/PKOPPLPWXXX?fields=bankName Bearer token",
+			"/v1/swiftCodes/MISSINGXXXX Bearer token",
+		))
+		Expect(summary.Sent).To(Equal(2))
+		Expect(summary.Skipped).To(Equal(3))
+		Expect(summary.Statuses).To(Equal(map[int]int{200: 1, 404: 1}))
+		Expect(summary.Mismatched).To(Equal(1))
+		Expect(summary.Max).To(BeNumerically(">=", summary.P50))
+	})
+
+	It("should keep the recorded gaps divided by the speed", func() {
+		logs := accessLog(
+			middleware.AccessRecord{Time: start, Method: http.MethodGet, Path: "/v1/swiftCodes/AAAAPLPWXXX"},
+			middleware.AccessRecord{Time: start.Add(400 * time.Millisecond), Method: http.MethodGet, Path: "/v1/swiftCodes/BBBBPLPWXXX"},
+		)
+		cfg.Speed = 2
+
+		summary, err := replay.Run(context.Background(), strings.NewReader(logs), cfg, server.Client())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary.Sent).To(Equal(2))
+		Expect(summary.Duration).To(BeNumerically(">=", 200*time.Millisecond))
+		Expect(summary.Duration).To(BeNumerically("<", 400*time.Millisecond))
+	})
+
+	It("should stop after the limit and count unanswered requests as failed", func() {
+		logs := accessLog(
+			middleware.AccessRecord{Method: http.MethodGet, Path: "/a"},
+			middleware.AccessRecord{Method: http.MethodGet, Path: "/b"},
+			middleware.AccessRecord{Method: http.MethodGet, Path: "/c"},
+		)
+		cfg.Limit = 2
+		server.Close()
+
+		summary, err := replay.Run(context.Background(), strings.NewReader(logs), cfg, server.Client())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary.Sent).To(BeZero())
+		Expect(summary.Failed).To(Equal(2))
+	})
+
+	It("should validate the configuration", func() {
+		Expect(cfg.Validate()).To(Succeed())
+
+		cfg.Target = "staging:8081"
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("absolute URL")))
+
+		cfg.Target = server.URL
+		cfg.Concurrency = 0
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("concurrency")))
+	})
+})
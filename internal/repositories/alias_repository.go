@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/zdziszkee/swift-codes/internal/database"
+)
+
+// AliasRepository stores aliases: other codes a stored bank is also known
+// by, such as the code of an institution it absorbed. Each alias names one
+// bank. Codes are stored upper case; checking that an alias is not itself
+// a stored code is left to the service.
+type AliasRepository interface {
+	// Resolve returns the code alias stands for, or ErrNotFound
+	Resolve(ctx context.Context, alias string) (string, error)
+	// List returns the aliases of code in order
+	List(ctx context.Context, code string) ([]string, error)
+	// Add stores alias for code, failing with ErrDuplicate when alias is
+	// already stored
+	Add(ctx context.Context, alias, code string) error
+	// Remove deletes alias, failing with ErrNotFound when it is not stored
+	Remove(ctx context.Context, alias string) error
+	// RemoveCode deletes every alias of code
+	RemoveCode(ctx context.Context, code string) error
+}
+
+// SQLAliasRepository implements AliasRepository on an alias table of
+// (alias, swift_code) rows next to the SWIFT banks table. Like that table
+// it has no key, so Add checks for the alias first.
+type SQLAliasRepository struct {
+	db    conn
+	table string
+}
+
+// NewSQLAliasRepository creates a repository on table in the catalog and
+// schema of db
+func NewSQLAliasRepository(db *database.Database, table string) *SQLAliasRepository {
+	dialect := db.Config.Dialect()
+	return &SQLAliasRepository{db: conn{db: db.DB, dialect: dialect}, table: dialect.Table(db.Config, table)}
+}
+
+func (r *SQLAliasRepository) Resolve(ctx context.Context, alias string) (string, error) {
+	query := fmt.Sprintf("SELECT swift_code FROM %s WHERE alias = ?", r.table)
+	var code string
+	err := r.db.QueryRowContext(ctx, query, strings.ToUpper(alias)).Scan(&code)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("alias query failed: %w", err)
+	}
+	return code, nil
+}
+
+func (r *SQLAliasRepository) List(ctx context.Context, code string) ([]string, error) {
+	query := fmt.Sprintf("SELECT alias FROM %s WHERE swift_code = ? ORDER BY alias", r.table)
+	rows, err := r.db.QueryContext(ctx, query, strings.ToUpper(code))
+	if err != nil {
+		return nil, fmt.Errorf("alias query failed: %w", err)
+	}
+	defer rows.Close()
+
+	aliases := []string{}
+	for rows.Next() {
+		var alias string
+		if err := rows.Scan(&alias); err != nil {
+			return nil, fmt.Errorf("alias scan failed: %w", err)
+		}
+		aliases = append(aliases, alias)
+	}
+	return aliases, rows.Err()
+}
+
+func (r *SQLAliasRepository) Add(ctx context.Context, alias, code string) error {
+	_, err := r.Resolve(ctx, alias)
+	switch {
+	case err == nil:
+		return fmt.Errorf("%w: alias %s", ErrDuplicate, strings.ToUpper(alias))
+	case !errors.Is(err, ErrNotFound):
+		return err
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (alias, swift_code) VALUES (?, ?)", r.table)
+	if _, err := r.db.ExecContext(ctx, query, strings.ToUpper(alias), strings.ToUpper(code)); err != nil {
+		return fmt.Errorf("alias insert failed: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLAliasRepository) Remove(ctx context.Context, alias string) error {
+	if _, err := r.Resolve(ctx, alias); err != nil {
+		return err
+	}
+	query := fmt.Sprintf("DELETE FROM %s WHERE alias = ?", r.table)
+	if _, err := r.db.ExecContext(ctx, query, strings.ToUpper(alias)); err != nil {
+		return fmt.Errorf("alias delete failed: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLAliasRepository) RemoveCode(ctx context.Context, code string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE swift_code = ?", r.table)
+	if _, err := r.db.ExecContext(ctx, query, strings.ToUpper(code)); err != nil {
+		return fmt.Errorf("alias delete failed: %w", err)
+	}
+	return nil
+}
+
+// MemoryAliasRepository implements AliasRepository with a map, for the
+// memory driver
+type MemoryAliasRepository struct {
+	mu      sync.RWMutex
+	aliases map[string]string
+}
+
+// NewMemoryAliasRepository creates an empty in-memory alias repository
+func NewMemoryAliasRepository() *MemoryAliasRepository {
+	return &MemoryAliasRepository{aliases: make(map[string]string)}
+}
+
+func (r *MemoryAliasRepository) Resolve(ctx context.Context, alias string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	code, ok := r.aliases[strings.ToUpper(alias)]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return code, nil
+}
+
+func (r *MemoryAliasRepository) List(ctx context.Context, code string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	code = strings.ToUpper(code)
+	aliases := []string{}
+	for alias, target := range r.aliases {
+		if target == code {
+			aliases = append(aliases, alias)
+		}
+	}
+	slices.Sort(aliases)
+	return aliases, nil
+}
+
+func (r *MemoryAliasRepository) Add(ctx context.Context, alias, code string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	alias = strings.ToUpper(alias)
+	if _, ok := r.aliases[alias]; ok {
+		return fmt.Errorf("%w: alias %s", ErrDuplicate, alias)
+	}
+	r.aliases[alias] = strings.ToUpper(code)
+	return nil
+}
+
+func (r *MemoryAliasRepository) Remove(ctx context.Context, alias string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	alias = strings.ToUpper(alias)
+	if _, ok := r.aliases[alias]; !ok {
+		return ErrNotFound
+	}
+	delete(r.aliases, alias)
+	return nil
+}
+
+func (r *MemoryAliasRepository) RemoveCode(ctx context.Context, code string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	code = strings.ToUpper(code)
+	for alias, target := range r.aliases {
+		if target == code {
+			delete(r.aliases, alias)
+		}
+	}
+	return nil
+}
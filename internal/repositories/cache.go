@@ -0,0 +1,613 @@
+package repository
+
+import (
+	"container/list"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	model "github.com/zdziszkee/swift-codes/internal/models"
+)
+
+type cacheEntry struct {
+	key       string
+	value     any
+	expiresAt time.Time
+	tags      []string
+}
+
+// maxDropped bounds how many dropped tags a cache remembers for the loads
+// in flight; past it they are forgotten and every load in flight is
+// treated as stale
+const maxDropped = 4096
+
+// cacheEntries numbers the details put in every cache, so no two entries
+// share a SwiftBankDetail.CacheEntry
+var cacheEntries atomic.Uint64
+
+// statsKey, completenessKey and countryCountsKey are dropped on every
+// write since any change moves the totals
+const (
+	statsKey         = "stats"
+	completenessKey  = "completeness"
+	countryCountsKey = "country_counts"
+)
+
+// cachedRepository serves reads from memory for ttl. Entries are tagged
+// with the countries they hold data for, so a write drops only the entries
+// of the countries it touched; readers never see stale data after a
+// mutation made via this repository. A load that misses while a write is
+// in flight is not cached when the write drops one of its tags before the
+// load finishes, so it cannot put back what the write just dropped. With
+// maxEntries set, the least recently used entry makes room for a new one.
+type cachedRepository struct {
+	next       SwiftRepository
+	ttl        *CacheTTL
+	maxEntries int
+	// bus shares invalidations with other replicas when set
+	bus      InvalidationBus
+	counters *cacheCounters
+	// overwrites is set when CreateBatch replaces stored codes, which may
+	// move them to another country
+	overwrites bool
+
+	mu sync.Mutex
+	// entries maps keys to their element of lru, most recently used first
+	entries map[string]*list.Element
+	lru     *list.List
+	// tagged indexes entry keys by tag
+	tagged map[string]map[string]struct{}
+	// seq numbers the drops; dropped holds the seq at which each tag or
+	// key was last dropped, and floor the seq before which drops are no
+	// longer told apart
+	seq     uint64
+	dropped map[string]uint64
+	floor   uint64
+}
+
+// cacheCounters counts the traffic of one or more caches
+type cacheCounters struct {
+	hits, misses, evictions, entries atomic.Int64
+}
+
+// CacheStats are the counters of the repository caches
+type CacheStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+	// HitRate is Hits over all lookups, 0 before the first one
+	HitRate float64 `json:"hit_rate"`
+	// Evictions counts entries dropped to stay within the size bound;
+	// invalidations and expiry are not counted
+	Evictions int64 `json:"evictions"`
+	// Entries is how many entries are held now, expired ones included
+	Entries int64 `json:"entries"`
+}
+
+func (c *cacheCounters) snapshot() CacheStats {
+	stats := CacheStats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Entries:   c.entries.Load(),
+	}
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(lookups)
+	}
+	return stats
+}
+
+// Invalidation names the cache entries dropped by a write: those carrying
+// or cached under one of Tags, or every entry when All is set
+type Invalidation struct {
+	Tags []string `json:"tags,omitempty"`
+	All  bool     `json:"all,omitempty"`
+	// Version is set on the invalidations that announce a change to the
+	// versions of other replicas. It orders changes across replicas and is
+	// at least the microseconds since the epoch at which the change was
+	// made. Invalidations of the cache leave it zero.
+	Version uint64 `json:"version,omitempty"`
+}
+
+// CountryInvalidation drops the entries holding data of the countries
+func CountryInvalidation(countries ...string) Invalidation {
+	return Invalidation{Tags: countryTags(countries...)}
+}
+
+// Countries returns the countries whose entries inv drops by tag
+func (inv Invalidation) Countries() []string {
+	var countries []string
+	for _, tag := range inv.Tags {
+		if country, ok := strings.CutPrefix(tag, countryTag("")); ok && country != "" {
+			countries = append(countries, country)
+		}
+	}
+	return countries
+}
+
+// InvalidationBus carries cache invalidations between replicas, so that a
+// write made through one of them does not leave the others serving stale
+// entries until their ttl runs out
+type InvalidationBus interface {
+	// Publish announces an invalidation made by this replica
+	Publish(inv Invalidation)
+	// Subscribe calls fn with the invalidations of other replicas
+	Subscribe(fn func(Invalidation))
+}
+
+// CacheTTL is the lifetime of new cache entries. It is shared by every
+// cache built with it and may be changed while they serve.
+type CacheTTL struct {
+	ttl atomic.Int64
+}
+
+// NewCacheTTL returns a lifetime of ttl
+func NewCacheTTL(ttl time.Duration) *CacheTTL {
+	t := &CacheTTL{}
+	t.Set(ttl)
+	return t
+}
+
+// Set changes the lifetime of entries cached from now on
+func (t *CacheTTL) Set(ttl time.Duration) {
+	t.ttl.Store(int64(ttl))
+}
+
+// Get returns the current lifetime
+func (t *CacheTTL) Get() time.Duration {
+	return time.Duration(t.ttl.Load())
+}
+
+// WithCache caches GetByCode, GetByCountry, GetBranchesByHQBase,
+// GetHeadquartersByBase, GetByBase and Stats results for ttl
+func WithCache(ttl time.Duration) Middleware {
+	return WithSharedCache(NewCacheTTL(ttl), nil)
+}
+
+// WithSharedCache is WithCache for a deployment of several replicas: the
+// entries a write drops are published on bus, and entries dropped by other
+// replicas are dropped here too. A nil bus keeps invalidations local.
+func WithSharedCache(ttl *CacheTTL, bus InvalidationBus) Middleware {
+	return WithBoundedCache(ttl, 0, bus, nil)
+}
+
+// WithBoundedCache is WithSharedCache holding at most maxEntries entries,
+// 0 meaning no bound, whose hits, misses and evictions are counted in
+// metrics when it is not nil
+func WithBoundedCache(ttl *CacheTTL, maxEntries int, bus InvalidationBus, metrics *Metrics) Middleware {
+	return newCache(ttl, maxEntries, bus, metrics, false)
+}
+
+// WithOverwritingCache is WithBoundedCache in front of a repository whose
+// CreateBatch overwrites stored codes: a batch drops every entry, as
+// Upsert does, since a reloaded code may have moved to another country
+func WithOverwritingCache(ttl *CacheTTL, maxEntries int, bus InvalidationBus, metrics *Metrics) Middleware {
+	return newCache(ttl, maxEntries, bus, metrics, true)
+}
+
+func newCache(ttl *CacheTTL, maxEntries int, bus InvalidationBus, metrics *Metrics, overwrites bool) Middleware {
+	counters := &cacheCounters{}
+	if metrics != nil {
+		counters = &metrics.cache
+	}
+	return func(next SwiftRepository) SwiftRepository {
+		r := &cachedRepository{
+			next:       next,
+			ttl:        ttl,
+			maxEntries: maxEntries,
+			bus:        bus,
+			counters:   counters,
+			overwrites: overwrites,
+			entries:    make(map[string]*list.Element),
+			lru:        list.New(),
+			tagged:     make(map[string]map[string]struct{}),
+			dropped:    make(map[string]uint64),
+		}
+		if bus != nil {
+			bus.Subscribe(r.apply)
+		}
+		return r
+	}
+}
+
+// countryTag tags entries holding data of a country
+func countryTag(countryCode string) string {
+	return "country:" + countryCode
+}
+
+// codeTag tags the detail entries of a SWIFT code, one per set of query
+// options
+func codeTag(code string) string {
+	return "code:" + code
+}
+
+// bicCountry returns the country part of a SWIFT code, or "" when the code
+// is too short to have one
+func bicCountry(code string) string {
+	if len(code) < 6 {
+		return ""
+	}
+	return code[4:6]
+}
+
+// countryTags returns the tags of the given countries, skipping blanks and
+// duplicates
+func countryTags(countries ...string) []string {
+	tags := make([]string, 0, len(countries))
+	for _, country := range countries {
+		if country == "" {
+			continue
+		}
+		tag := countryTag(country)
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// get returns the entry cached under key. On a miss it returns the seq to
+// pass to put with the loaded value.
+func (r *cachedRepository) get(key string) (any, uint64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	element, ok := r.entries[key]
+	if !ok {
+		r.counters.misses.Add(1)
+		return nil, r.seq, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		r.remove(key)
+		r.counters.misses.Add(1)
+		return nil, r.seq, false
+	}
+	r.lru.MoveToFront(element)
+	r.counters.hits.Add(1)
+	return entry.value, 0, true
+}
+
+// put caches value under key with tags, unless key or one of tags was
+// dropped after the miss that returned since
+func (r *cachedRepository) put(key string, since uint64, value any, tags ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if since < r.floor || r.dropped[key] > since {
+		return
+	}
+	for _, tag := range tags {
+		if r.dropped[tag] > since {
+			return
+		}
+	}
+
+	r.remove(key)
+	r.entries[key] = r.lru.PushFront(&cacheEntry{key: key, value: value, expiresAt: time.Now().Add(r.ttl.Get()), tags: tags})
+	r.counters.entries.Add(1)
+	for _, tag := range tags {
+		keys, ok := r.tagged[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			r.tagged[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+
+	for r.maxEntries > 0 && r.lru.Len() > r.maxEntries {
+		r.remove(r.lru.Back().Value.(*cacheEntry).key)
+		r.counters.evictions.Add(1)
+	}
+}
+
+// remove drops key and its tag index entries; r.mu must be held
+func (r *cachedRepository) remove(key string) {
+	element, ok := r.entries[key]
+	if !ok {
+		return
+	}
+	delete(r.entries, key)
+	r.lru.Remove(element)
+	r.counters.entries.Add(-1)
+	for _, tag := range element.Value.(*cacheEntry).tags {
+		delete(r.tagged[tag], key)
+		if len(r.tagged[tag]) == 0 {
+			delete(r.tagged, tag)
+		}
+	}
+}
+
+// tagsOf returns the tags of the entries carrying tag
+func (r *cachedRepository) tagsOf(tag string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var tags []string
+	for key := range r.tagged[tag] {
+		tags = append(tags, r.entries[key].Value.(*cacheEntry).tags...)
+	}
+	return tags
+}
+
+// evict drops the entries carrying any of tags along with the dataset stats
+func (r *cachedRepository) evict(tags ...string) {
+	r.forget(append(tags, statsKey, completenessKey, countryCountsKey)...)
+}
+
+// forget drops the entries carrying any of tags, or cached under one of
+// them as a key, here and on the other replicas
+func (r *cachedRepository) forget(tags ...string) {
+	r.forgetLocal(tags)
+	if r.bus != nil {
+		r.bus.Publish(Invalidation{Tags: tags})
+	}
+}
+
+// invalidate drops every entry here and on the other replicas
+func (r *cachedRepository) invalidate() {
+	r.invalidateLocal()
+	if r.bus != nil {
+		r.bus.Publish(Invalidation{All: true})
+	}
+}
+
+// apply carries out an invalidation published by another replica
+func (r *cachedRepository) apply(inv Invalidation) {
+	if inv.All {
+		r.invalidateLocal()
+		return
+	}
+	r.forgetLocal(inv.Tags)
+}
+
+func (r *cachedRepository) forgetLocal(tags []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.seq++
+	if len(r.dropped)+len(tags) > maxDropped {
+		r.dropped = make(map[string]uint64)
+		r.floor = r.seq
+	}
+	for _, tag := range tags {
+		r.dropped[tag] = r.seq
+		r.remove(tag)
+		for key := range r.tagged[tag] {
+			r.remove(key)
+		}
+	}
+}
+
+func (r *cachedRepository) invalidateLocal() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counters.entries.Add(-int64(len(r.entries)))
+	r.entries = make(map[string]*list.Element)
+	r.lru.Init()
+	r.tagged = make(map[string]map[string]struct{})
+	r.seq++
+	r.dropped = make(map[string]uint64)
+	r.floor = r.seq
+}
+
+func (r *cachedRepository) GetByCode(ctx context.Context, code string, opts QueryOptions) (*SwiftBankDetail, error) {
+	if !opts.Cacheable() {
+		return r.next.GetByCode(ctx, code, opts)
+	}
+	key := "detail:" + code + ":" + opts.CacheKey()
+	v, since, ok := r.get(key)
+	if ok {
+		detail := *v.(*SwiftBankDetail)
+		return &detail, nil
+	}
+
+	detail, err := r.next.GetByCode(ctx, code, opts)
+	if err != nil {
+		return nil, err
+	}
+	if detail.BranchesUnavailable {
+		// Partial answers are not cached so the next lookup retries
+		return detail, nil
+	}
+	cached := *detail
+	cached.CacheEntry = cacheEntries.Add(1)
+	r.put(key, since, &cached, append(countryTags(bicCountry(code), detail.Bank.CountryISOCode), codeTag(code))...)
+	return detail, nil
+}
+
+func (r *cachedRepository) GetByCountry(ctx context.Context, countryCode string, opts QueryOptions) (*CountrySwiftCodes, error) {
+	if !opts.Cacheable() {
+		return r.next.GetByCountry(ctx, countryCode, opts)
+	}
+	key := "country:" + countryCode + ":" + opts.CacheKey()
+	v, since, ok := r.get(key)
+	if ok {
+		codes := *v.(*CountrySwiftCodes)
+		return &codes, nil
+	}
+
+	codes, err := r.next.GetByCountry(ctx, countryCode, opts)
+	if err != nil {
+		return nil, err
+	}
+	cached := *codes
+	r.put(key, since, &cached, countryTags(countryCode)...)
+	return codes, nil
+}
+
+func (r *cachedRepository) GetBranchesByHQBase(ctx context.Context, hqBase string, opts QueryOptions) ([]model.SwiftBank, error) {
+	if !opts.Cacheable() {
+		return r.next.GetBranchesByHQBase(ctx, hqBase, opts)
+	}
+	key := "branches:" + hqBase + ":" + opts.CacheKey()
+	v, since, ok := r.get(key)
+	if ok {
+		return v.([]model.SwiftBank), nil
+	}
+
+	branches, err := r.next.GetBranchesByHQBase(ctx, hqBase, opts)
+	if err != nil {
+		return nil, err
+	}
+	countries := []string{bicCountry(hqBase)}
+	for _, branch := range branches {
+		countries = append(countries, branch.CountryISOCode)
+	}
+	r.put(key, since, branches, countryTags(countries...)...)
+	return branches, nil
+}
+
+func (r *cachedRepository) GetHeadquartersByBase(ctx context.Context, hqBase string, opts QueryOptions) (*model.SwiftBank, error) {
+	if !opts.Cacheable() {
+		return r.next.GetHeadquartersByBase(ctx, hqBase, opts)
+	}
+	key := "headquarters:" + hqBase + ":" + opts.CacheKey()
+	v, since, ok := r.get(key)
+	if ok {
+		bank := *v.(*model.SwiftBank)
+		return &bank, nil
+	}
+
+	bank, err := r.next.GetHeadquartersByBase(ctx, hqBase, opts)
+	if err != nil {
+		return nil, err
+	}
+	cached := *bank
+	r.put(key, since, &cached, append(countryTags(bicCountry(hqBase), bank.CountryISOCode), codeTag(bank.SwiftCode))...)
+	return bank, nil
+}
+
+func (r *cachedRepository) GetByBase(ctx context.Context, base string, opts QueryOptions) (*BankGroup, error) {
+	if !opts.Cacheable() {
+		return r.next.GetByBase(ctx, base, opts)
+	}
+	key := "base:" + base + ":" + opts.CacheKey()
+	v, since, ok := r.get(key)
+	if ok {
+		group := *v.(*BankGroup)
+		return &group, nil
+	}
+
+	group, err := r.next.GetByBase(ctx, base, opts)
+	if err != nil {
+		return nil, err
+	}
+	countries := []string{bicCountry(base)}
+	if group.Headquarters != nil {
+		countries = append(countries, group.Headquarters.CountryISOCode)
+	}
+	for _, branch := range group.Branches {
+		countries = append(countries, branch.CountryISOCode)
+	}
+	cached := *group
+	r.put(key, since, &cached, countryTags(countries...)...)
+	return group, nil
+}
+
+func (r *cachedRepository) Stats(ctx context.Context) (*DatasetStats, error) {
+	v, since, ok := r.get(statsKey)
+	if ok {
+		stats := *v.(*DatasetStats)
+		return &stats, nil
+	}
+
+	stats, err := r.next.Stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cached := *stats
+	r.put(statsKey, since, &cached)
+	return stats, nil
+}
+
+func (r *cachedRepository) Completeness(ctx context.Context) ([]CountryCompleteness, error) {
+	v, since, ok := r.get(completenessKey)
+	if ok {
+		return slices.Clone(v.([]CountryCompleteness)), nil
+	}
+
+	countries, err := r.next.Completeness(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.put(completenessKey, since, slices.Clone(countries))
+	return countries, nil
+}
+
+func (r *cachedRepository) CountryCounts(ctx context.Context) ([]CountryCount, error) {
+	v, since, ok := r.get(countryCountsKey)
+	if ok {
+		return slices.Clone(v.([]CountryCount)), nil
+	}
+
+	countries, err := r.next.CountryCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.put(countryCountsKey, since, slices.Clone(countries))
+	return countries, nil
+}
+
+func (r *cachedRepository) Create(ctx context.Context, bank *model.SwiftBank) error {
+	defer r.evict(countryTags(bicCountry(bank.SwiftCode), bank.CountryISOCode)...)
+	return r.next.Create(ctx, bank)
+}
+
+func (r *cachedRepository) CreateBatch(ctx context.Context, banks []*model.SwiftBank) error {
+	if r.overwrites {
+		defer r.invalidate()
+		return r.next.CreateBatch(ctx, banks)
+	}
+	countries := make([]string, 0, 2*len(banks))
+	for _, bank := range banks {
+		countries = append(countries, bicCountry(bank.SwiftCode), bank.CountryISOCode)
+	}
+	defer r.evict(countryTags(countries...)...)
+	return r.next.CreateBatch(ctx, banks)
+}
+
+func (r *cachedRepository) Delete(ctx context.Context, code string) error {
+	// The cached detail knows the bank's country when it differs from the code's
+	tags := append(countryTags(bicCountry(code)), r.tagsOf(codeTag(code))...)
+	defer r.evict(tags...)
+	return r.next.Delete(ctx, code)
+}
+
+func (r *cachedRepository) DeleteByCountry(ctx context.Context, countryCode string) (int64, error) {
+	defer r.evict(countryTags(countryCode)...)
+	return r.next.DeleteByCountry(ctx, countryCode)
+}
+
+func (r *cachedRepository) LoadCSV(ctx context.Context, csvPath string) error {
+	defer r.invalidate()
+	return r.next.LoadCSV(ctx, csvPath)
+}
+
+func (r *cachedRepository) UpdateContacts(ctx context.Context, contacts []model.BankContact) (int64, error) {
+	// Listings read contacts too; a cached detail knows the bank's country
+	// when it differs from the code's
+	var tags []string
+	for _, contact := range contacts {
+		code := strings.ToUpper(contact.SwiftCode)
+		tags = append(tags, codeTag(code))
+		tags = append(tags, countryTags(bicCountry(code))...)
+		tags = append(tags, r.tagsOf(codeTag(code))...)
+	}
+	defer r.forget(tags...)
+	return r.next.UpdateContacts(ctx, contacts)
+}
+
+// Upsert drops every entry, as an updated code may have moved to another
+// country
+func (r *cachedRepository) Upsert(ctx context.Context, banks []*model.SwiftBank) (int64, error) {
+	defer r.invalidate()
+	return r.next.Upsert(ctx, banks)
+}
+
+// ListAll is not cached: exports are rare and would pin the whole table in
+// memory
+func (r *cachedRepository) ListAll(ctx context.Context) ([]model.SwiftBank, error) {
+	return r.next.ListAll(ctx)
+}
@@ -0,0 +1,326 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/zdziszkee/swift-codes/internal/database"
+	model "github.com/zdziszkee/swift-codes/internal/models"
+)
+
+// Failover cluster names
+const (
+	ClusterPrimary   = "primary"
+	ClusterSecondary = "secondary"
+)
+
+// ErrUnknownCluster is returned by SetWriteCluster for a name that is not
+// configured
+var ErrUnknownCluster = errors.New("unknown cluster")
+
+// Cluster is a Trino cluster serving the tables
+type Cluster struct {
+	Name string
+	// Ping checks that the cluster answers queries
+	Ping func(ctx context.Context) error
+}
+
+// ClusterStatus is the last known health of a cluster
+type ClusterStatus struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	// LastError is why the cluster was marked unhealthy
+	LastError string    `json:"last_error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// FailoverStatus reports the health of every cluster and where calls go
+type FailoverStatus struct {
+	Clusters []ClusterStatus `json:"clusters"`
+	// ReadCluster is the cluster reads are sent to first
+	ReadCluster  string `json:"read_cluster"`
+	WriteCluster string `json:"write_cluster"`
+}
+
+// Failover tracks the health of several clusters, the first one being the
+// primary, and which of them takes writes. Repositories made by Repository
+// send reads to the first healthy cluster, falling through to the next on
+// infrastructure errors, and writes to the write cluster only. Writes are
+// never moved automatically: a cluster that lags behind must not accept
+// them until an operator decides so with SetWriteCluster.
+type Failover struct {
+	clusters []Cluster
+	interval time.Duration
+	timeout  time.Duration
+
+	mu     sync.RWMutex
+	health []ClusterStatus
+	write  int
+}
+
+// NewFailover creates a failover over clusters, all assumed healthy until
+// a check or a call fails
+func NewFailover(cfg database.FailoverConfig, clusters ...Cluster) *Failover {
+	f := &Failover{clusters: clusters, interval: cfg.HealthInterval, timeout: cfg.HealthTimeout}
+	for _, cluster := range clusters {
+		f.health = append(f.health, ClusterStatus{Name: cluster.Name, Healthy: true})
+	}
+	return f
+}
+
+// Run pings every cluster each health interval until ctx ends
+func (f *Failover) Run(ctx context.Context) {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+	for {
+		f.check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (f *Failover) check(ctx context.Context) {
+	for i, cluster := range f.clusters {
+		pingCtx, cancel := context.WithTimeout(ctx, f.timeout)
+		err := cluster.Ping(pingCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		f.mark(i, err)
+	}
+}
+
+// mark records the outcome of a check or call on cluster i
+func (f *Failover) mark(i int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	status := &f.health[i]
+	switch {
+	case err != nil && status.Healthy:
+		log.Printf("WARNING: Trino cluster %s is unhealthy: %v", status.Name, err)
+	case err == nil && !status.Healthy:
+		log.Printf("Trino cluster %s is healthy again", status.Name)
+	}
+	status.Healthy = err == nil
+	status.LastError = ""
+	if err != nil {
+		status.LastError = err.Error()
+	}
+	status.CheckedAt = time.Now().UTC()
+}
+
+// readOrder lists the healthy clusters in configuration order, followed by
+// the unhealthy ones as a last resort
+func (f *Failover) readOrder() []int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	order := make([]int, 0, len(f.health))
+	for i, status := range f.health {
+		if status.Healthy {
+			order = append(order, i)
+		}
+	}
+	for i, status := range f.health {
+		if !status.Healthy {
+			order = append(order, i)
+		}
+	}
+	return order
+}
+
+func (f *Failover) writeCluster() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.write
+}
+
+// SetWriteCluster sends writes to the named cluster from now on
+func (f *Failover) SetWriteCluster(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, cluster := range f.clusters {
+		if cluster.Name == name {
+			if i != f.write {
+				log.Printf("Writes switched from Trino cluster %s to %s", f.clusters[f.write].Name, name)
+			}
+			f.write = i
+			return nil
+		}
+	}
+	return fmt.Errorf("%w %q", ErrUnknownCluster, name)
+}
+
+// Status reports the health of every cluster and where calls go
+func (f *Failover) Status() FailoverStatus {
+	read := f.clusters[f.readOrder()[0]].Name
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return FailoverStatus{
+		Clusters:     append([]ClusterStatus(nil), f.health...),
+		ReadCluster:  read,
+		WriteCluster: f.clusters[f.write].Name,
+	}
+}
+
+// Repository routes calls between repos, one per cluster in the order the
+// clusters were given
+func (f *Failover) Repository(repos ...SwiftRepository) SwiftRepository {
+	if len(repos) != len(f.clusters) {
+		panic("repository: failover needs one repository per cluster")
+	}
+	return &failoverRepository{failover: f, repos: repos}
+}
+
+// failoverRepository sends reads to the first healthy cluster and writes
+// to the write cluster
+type failoverRepository struct {
+	failover *Failover
+	repos    []SwiftRepository
+}
+
+// read tries the clusters in read order until one answers without an
+// infrastructure error, marking the ones that fail as unhealthy
+func (r *failoverRepository) read(ctx context.Context, call func(repo SwiftRepository) error) error {
+	var err error
+	for _, i := range r.failover.readOrder() {
+		err = call(r.repos[i])
+		if !isInfrastructureError(err) || ctx.Err() != nil {
+			return err
+		}
+		r.failover.mark(i, err)
+	}
+	return err
+}
+
+func (r *failoverRepository) writer() SwiftRepository {
+	return r.repos[r.failover.writeCluster()]
+}
+
+func (r *failoverRepository) GetByCode(ctx context.Context, code string, opts QueryOptions) (*SwiftBankDetail, error) {
+	var result *SwiftBankDetail
+	err := r.read(ctx, func(repo SwiftRepository) error {
+		var err error
+		result, err = repo.GetByCode(ctx, code, opts)
+		return err
+	})
+	return result, err
+}
+
+func (r *failoverRepository) GetByCountry(ctx context.Context, countryCode string, opts QueryOptions) (*CountrySwiftCodes, error) {
+	var result *CountrySwiftCodes
+	err := r.read(ctx, func(repo SwiftRepository) error {
+		var err error
+		result, err = repo.GetByCountry(ctx, countryCode, opts)
+		return err
+	})
+	return result, err
+}
+
+func (r *failoverRepository) GetBranchesByHQBase(ctx context.Context, hqBase string, opts QueryOptions) ([]model.SwiftBank, error) {
+	var result []model.SwiftBank
+	err := r.read(ctx, func(repo SwiftRepository) error {
+		var err error
+		result, err = repo.GetBranchesByHQBase(ctx, hqBase, opts)
+		return err
+	})
+	return result, err
+}
+
+func (r *failoverRepository) GetHeadquartersByBase(ctx context.Context, hqBase string, opts QueryOptions) (*model.SwiftBank, error) {
+	var result *model.SwiftBank
+	err := r.read(ctx, func(repo SwiftRepository) error {
+		var err error
+		result, err = repo.GetHeadquartersByBase(ctx, hqBase, opts)
+		return err
+	})
+	return result, err
+}
+
+func (r *failoverRepository) GetByBase(ctx context.Context, base string, opts QueryOptions) (*BankGroup, error) {
+	var result *BankGroup
+	err := r.read(ctx, func(repo SwiftRepository) error {
+		var err error
+		result, err = repo.GetByBase(ctx, base, opts)
+		return err
+	})
+	return result, err
+}
+
+func (r *failoverRepository) Stats(ctx context.Context) (*DatasetStats, error) {
+	var result *DatasetStats
+	err := r.read(ctx, func(repo SwiftRepository) error {
+		var err error
+		result, err = repo.Stats(ctx)
+		return err
+	})
+	return result, err
+}
+
+func (r *failoverRepository) Completeness(ctx context.Context) ([]CountryCompleteness, error) {
+	var result []CountryCompleteness
+	err := r.read(ctx, func(repo SwiftRepository) error {
+		var err error
+		result, err = repo.Completeness(ctx)
+		return err
+	})
+	return result, err
+}
+
+func (r *failoverRepository) CountryCounts(ctx context.Context) ([]CountryCount, error) {
+	var result []CountryCount
+	err := r.read(ctx, func(repo SwiftRepository) error {
+		var err error
+		result, err = repo.CountryCounts(ctx)
+		return err
+	})
+	return result, err
+}
+
+func (r *failoverRepository) ListAll(ctx context.Context) ([]model.SwiftBank, error) {
+	var result []model.SwiftBank
+	err := r.read(ctx, func(repo SwiftRepository) error {
+		var err error
+		result, err = repo.ListAll(ctx)
+		return err
+	})
+	return result, err
+}
+
+func (r *failoverRepository) Create(ctx context.Context, bank *model.SwiftBank) error {
+	return r.writer().Create(ctx, bank)
+}
+
+func (r *failoverRepository) CreateBatch(ctx context.Context, banks []*model.SwiftBank) error {
+	return r.writer().CreateBatch(ctx, banks)
+}
+
+func (r *failoverRepository) Delete(ctx context.Context, code string) error {
+	return r.writer().Delete(ctx, code)
+}
+
+func (r *failoverRepository) DeleteByCountry(ctx context.Context, countryCode string) (int64, error) {
+	return r.writer().DeleteByCountry(ctx, countryCode)
+}
+
+func (r *failoverRepository) LoadCSV(ctx context.Context, csvPath string) error {
+	return r.writer().LoadCSV(ctx, csvPath)
+}
+
+func (r *failoverRepository) UpdateContacts(ctx context.Context, contacts []model.BankContact) (int64, error) {
+	return r.writer().UpdateContacts(ctx, contacts)
+}
+
+func (r *failoverRepository) Upsert(ctx context.Context, banks []*model.SwiftBank) (int64, error) {
+	return r.writer().Upsert(ctx, banks)
+}
@@ -0,0 +1,116 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/database"
+	"github.com/zdziszkee/swift-codes/internal/models"
+	repo "github.com/zdziszkee/swift-codes/internal/repositories"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+var _ = Describe("Failover", func() {
+	var (
+		ctx                context.Context
+		failover           *repo.Failover
+		primary, secondary *mocks.MockSwiftRepository
+		primaryDown        atomic.Bool
+		reads, writes      map[string]int
+		errDown            = errors.New("trino unavailable")
+	)
+
+	cluster := func(name string, down *atomic.Bool) *mocks.MockSwiftRepository {
+		return &mocks.MockSwiftRepository{
+			GetByCodeFunc: func(ctx context.Context, code string, opts repo.QueryOptions) (*repo.SwiftBankDetail, error) {
+				reads[name]++
+				if down != nil && down.Load() {
+					return nil, errDown
+				}
+				if code == "MISSPLPWXXX" {
+					return nil, repo.ErrNotFound
+				}
+				return &repo.SwiftBankDetail{Bank: models.SwiftBank{SwiftCode: code}}, nil
+			},
+			DeleteFunc: func(ctx context.Context, code string) error {
+				writes[name]++
+				return nil
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		primaryDown.Store(false)
+		reads, writes = map[string]int{}, map[string]int{}
+		primary = cluster(repo.ClusterPrimary, &primaryDown)
+		secondary = cluster(repo.ClusterSecondary, nil)
+		failover = repo.NewFailover(database.FailoverConfig{HealthInterval: 10 * time.Millisecond, HealthTimeout: time.Second},
+			repo.Cluster{Name: repo.ClusterPrimary, Ping: func(ctx context.Context) error {
+				if primaryDown.Load() {
+					return errDown
+				}
+				return nil
+			}},
+			repo.Cluster{Name: repo.ClusterSecondary, Ping: func(ctx context.Context) error { return nil }},
+		)
+	})
+
+	It("should read from the primary while it is healthy", func() {
+		r := failover.Repository(primary, secondary)
+		_, err := r.GetByCode(ctx, "ABCDPLPWXXX", repo.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = r.GetByCode(ctx, "MISSPLPWXXX", repo.QueryOptions{})
+		Expect(err).To(MatchError(repo.ErrNotFound))
+		Expect(reads).To(Equal(map[string]int{repo.ClusterPrimary: 2}))
+	})
+
+	It("should fail reads over to the secondary and stay there until the primary recovers", func() {
+		r := failover.Repository(primary, secondary)
+		primaryDown.Store(true)
+
+		_, err := r.GetByCode(ctx, "ABCDPLPWXXX", repo.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = r.GetByCode(ctx, "ABCDPLPWXXX", repo.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reads).To(Equal(map[string]int{repo.ClusterPrimary: 1, repo.ClusterSecondary: 2}))
+
+		status := failover.Status()
+		Expect(status.ReadCluster).To(Equal(repo.ClusterSecondary))
+		Expect(status.Clusters[0].Healthy).To(BeFalse())
+		Expect(status.Clusters[0].LastError).To(Equal("trino unavailable"))
+
+		primaryDown.Store(false)
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			failover.Run(runCtx)
+		}()
+		Eventually(func() string { return failover.Status().ReadCluster }).Should(Equal(repo.ClusterPrimary))
+		cancel()
+		<-done
+	})
+
+	It("should keep writes on the write cluster until it is switched", func() {
+		r := failover.Repository(primary, secondary)
+		primaryDown.Store(true)
+		_, _ = r.GetByCode(ctx, "ABCDPLPWXXX", repo.QueryOptions{})
+
+		Expect(r.Delete(ctx, "ABCDPLPWXXX")).To(Succeed())
+		Expect(writes).To(Equal(map[string]int{repo.ClusterPrimary: 1}))
+
+		Expect(failover.SetWriteCluster(repo.ClusterSecondary)).To(Succeed())
+		Expect(r.Delete(ctx, "ABCDPLPWXXX")).To(Succeed())
+		Expect(writes).To(Equal(map[string]int{repo.ClusterPrimary: 1, repo.ClusterSecondary: 1}))
+		Expect(failover.Status().WriteCluster).To(Equal(repo.ClusterSecondary))
+
+		Expect(failover.SetWriteCluster("tertiary")).To(MatchError(repo.ErrUnknownCluster))
+	})
+})
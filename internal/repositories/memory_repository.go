@@ -0,0 +1,447 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	model "github.com/zdziszkee/swift-codes/internal/models"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+)
+
+// MemorySwiftRepository implements SwiftRepository with maps indexed by
+// code, code base and country, so the API runs without a Trino cluster.
+// It answers like SQLSwiftRepository, returning every stored column.
+type MemorySwiftRepository struct {
+	mu        sync.RWMutex
+	banks     map[string]model.SwiftBank
+	byBase    map[string]map[string]struct{}
+	byCountry map[string]map[string]struct{}
+}
+
+// NewMemorySwiftRepository creates an empty in-memory repository
+func NewMemorySwiftRepository() SwiftRepository {
+	return &MemorySwiftRepository{
+		banks:     make(map[string]model.SwiftBank),
+		byBase:    make(map[string]map[string]struct{}),
+		byCountry: make(map[string]map[string]struct{}),
+	}
+}
+
+// CreateBatch stores every bank. A code that is already stored is
+// replaced, as the map holds one row per code.
+func (r *MemorySwiftRepository) CreateBatch(ctx context.Context, banks []*model.SwiftBank) error {
+	if len(banks) == 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, bank := range banks {
+		normalize(bank)
+		r.put(*bank)
+	}
+	requestid.Logf(ctx, "Successfully loaded %d SWIFT codes", len(banks))
+	return nil
+}
+
+// Upsert stores banks, leaving codes whose columns are unchanged alone,
+// and returns the number of codes inserted or changed. Contacts of stored
+// codes are kept.
+func (r *MemorySwiftRepository) Upsert(ctx context.Context, banks []*model.SwiftBank) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	changed := make(map[string]struct{})
+	for _, bank := range banks {
+		normalize(bank)
+		stored, ok := r.banks[bank.SwiftCode]
+		next := *bank
+		if ok {
+			next.Website, next.Phone = stored.Website, stored.Phone
+			if next == stored {
+				continue
+			}
+		}
+		r.put(next)
+		changed[next.SwiftCode] = struct{}{}
+	}
+	return int64(len(changed)), nil
+}
+
+// Create adds a single SWIFT bank
+func (r *MemorySwiftRepository) Create(ctx context.Context, bank *model.SwiftBank) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.banks[strings.ToUpper(bank.SwiftCode)]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicate, strings.ToUpper(bank.SwiftCode))
+	}
+	normalize(bank)
+	r.put(*bank)
+	return nil
+}
+
+// LoadCSV is a placeholder
+func (r *MemorySwiftRepository) LoadCSV(ctx context.Context, csvPath string) error {
+	return fmt.Errorf("LoadCSV not implemented for the memory driver; use CreateBatch instead")
+}
+
+// GetByCode retrieves a SWIFT bank and, unless opts omits them, its branches
+// if it's a headquarters
+func (r *MemorySwiftRepository) GetByCode(ctx context.Context, code string, opts QueryOptions) (*SwiftBankDetail, error) {
+	if !opts.AsOf.IsZero() {
+		return nil, errTimeTravel
+	}
+	code = strings.ToUpper(code)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	bank, ok := r.banks[code]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, code)
+	}
+
+	result := &SwiftBankDetail{Bank: bank}
+	if bank.IsHeadquarter && !opts.OmitBranches {
+		all := r.branches(bank.SwiftCodeBase, QueryOptions{Sort: opts.Sort})
+		result.Branches = window(all, opts)
+		result.BranchesTotal = len(all)
+	}
+	return result, nil
+}
+
+// GetBranchesByHQBase retrieves the branches of a headquarters, ordered and
+// windowed as opts asks
+func (r *MemorySwiftRepository) GetBranchesByHQBase(ctx context.Context, hqBase string, opts QueryOptions) ([]model.SwiftBank, error) {
+	if !opts.AsOf.IsZero() {
+		return nil, errTimeTravel
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.branches(hqBase, opts), nil
+}
+
+// GetHeadquartersByBase retrieves the headquarters sharing the first eight
+// characters hqBase
+func (r *MemorySwiftRepository) GetHeadquartersByBase(ctx context.Context, hqBase string, opts QueryOptions) (*model.SwiftBank, error) {
+	if !opts.AsOf.IsZero() {
+		return nil, errTimeTravel
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, bank := range r.listedBanks(r.byBase[hqBase], Sort{}) {
+		if bank.IsHeadquarter {
+			hq := r.banks[bank.SwiftCode]
+			return &hq, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: headquarters of %s", ErrNotFound, hqBase)
+}
+
+// GetByBase retrieves every code whose first eight characters are base
+func (r *MemorySwiftRepository) GetByBase(ctx context.Context, base string, opts QueryOptions) (*BankGroup, error) {
+	if !opts.AsOf.IsZero() {
+		return nil, errTimeTravel
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	banks := r.listedBanks(r.byBase[base], Sort{})
+	if len(banks) == 0 {
+		return nil, fmt.Errorf("%w: swift code base %s", ErrNotFound, base)
+	}
+	// Headquarters first, as ORDER BY is_headquarter DESC, swift_code
+	slices.SortStableFunc(banks, func(a, b model.SwiftBank) int {
+		return cmp.Compare(headquarterRank(b), headquarterRank(a))
+	})
+
+	group := &BankGroup{SwiftCodeBase: base, Branches: []model.SwiftBank{}}
+	for _, bank := range banks {
+		if bank.IsHeadquarter && group.Headquarters == nil {
+			hq := bank
+			group.Headquarters = &hq
+			continue
+		}
+		group.Branches = append(group.Branches, bank)
+	}
+	return group, nil
+}
+
+// GetByCountry retrieves all SWIFT banks for a country, ordered as opts asks
+func (r *MemorySwiftRepository) GetByCountry(ctx context.Context, countryCode string, opts QueryOptions) (*CountrySwiftCodes, error) {
+	if !opts.AsOf.IsZero() {
+		return nil, errTimeTravel
+	}
+	sort, err := opts.keysetSort()
+	if err != nil {
+		return nil, err
+	}
+	countryCode = strings.ToUpper(countryCode)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	codes := r.byCountry[countryCode]
+	if len(codes) == 0 {
+		return nil, fmt.Errorf("%w: country %s", ErrNotFound, countryCode)
+	}
+
+	result := &CountrySwiftCodes{CountryISO2: countryCode}
+	var matching []model.SwiftBank
+	after := 0
+	for _, bank := range r.listedBanks(codes, sort) {
+		if result.CountryName == "" {
+			result.CountryName = bank.CountryName
+		}
+		stored := r.banks[bank.SwiftCode]
+		if opts.Type != BankTypeAny && bank.IsHeadquarter != (opts.Type == BankTypeHeadquarter) ||
+			!containsFold(stored.Address, opts.Address) || !containsFold(stored.Town, opts.Town) {
+			continue
+		}
+		matching = append(matching, bank)
+		if opts.After != "" && sort.compare(bank, model.SwiftBank{SwiftCode: opts.After}) <= 0 {
+			after = len(matching)
+		}
+	}
+	result.Total = len(matching)
+	result.SwiftCodes = window(matching[after:], opts)
+	return result, nil
+}
+
+// ListAll retrieves every SWIFT bank ordered by code, for full exports
+func (r *MemorySwiftRepository) ListAll(ctx context.Context) ([]model.SwiftBank, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	banks := make([]model.SwiftBank, 0, len(r.banks))
+	for _, bank := range r.banks {
+		banks = append(banks, bank)
+	}
+	slices.SortFunc(banks, Sort{}.compare)
+	return banks, nil
+}
+
+// Delete removes a SWIFT bank
+func (r *MemorySwiftRepository) Delete(ctx context.Context, code string) error {
+	code = strings.ToUpper(code)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.banks[code]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, code)
+	}
+	r.remove(code)
+	return nil
+}
+
+// DeleteByCountry removes every SWIFT bank of a country and returns the
+// number of deleted rows
+func (r *MemorySwiftRepository) DeleteByCountry(ctx context.Context, countryCode string) (int64, error) {
+	countryCode = strings.ToUpper(countryCode)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var deleted int64
+	for code := range r.byCountry[countryCode] {
+		r.remove(code)
+		deleted++
+	}
+	return deleted, nil
+}
+
+// UpdateContacts sets the website and phone of existing SWIFT codes and
+// returns the number of codes updated. Contacts for unknown codes are
+// ignored.
+func (r *MemorySwiftRepository) UpdateContacts(ctx context.Context, contacts []model.BankContact) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var updated int64
+	for _, contact := range contacts {
+		code := strings.ToUpper(contact.SwiftCode)
+		bank, ok := r.banks[code]
+		if !ok {
+			continue
+		}
+		bank.Website, bank.Phone = contact.Website, contact.Phone
+		r.banks[code] = bank
+		updated++
+	}
+	return updated, nil
+}
+
+// Stats counts codes, headquarters and countries
+func (r *MemorySwiftRepository) Stats(ctx context.Context) (*DatasetStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	stats := DatasetStats{TotalCodes: int64(len(r.banks)), Countries: int64(len(r.byCountry))}
+	for _, bank := range r.banks {
+		if bank.IsHeadquarter {
+			stats.Headquarters++
+		}
+	}
+	stats.Branches = stats.TotalCodes - stats.Headquarters
+	return &stats, nil
+}
+
+// CountryCounts totals codes, headquarters and branches per country,
+// ordered by country
+func (r *MemorySwiftRepository) CountryCounts(ctx context.Context) ([]CountryCount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var countries []CountryCount
+	for _, country := range r.countries() {
+		c := CountryCount{CountryISO2: country}
+		for code := range r.byCountry[country] {
+			bank := r.banks[code]
+			c.CountryName = max(c.CountryName, bank.CountryName)
+			c.TotalCodes++
+			if bank.IsHeadquarter {
+				c.Headquarters++
+			}
+		}
+		c.Branches = c.TotalCodes - c.Headquarters
+		countries = append(countries, c)
+	}
+	return countries, nil
+}
+
+// Completeness counts filled optional fields and headquarters coverage per
+// country, ordered by country. Blank values count as missing.
+func (r *MemorySwiftRepository) Completeness(ctx context.Context) ([]CountryCompleteness, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	filled := func(value string) int64 {
+		if strings.TrimSpace(value) == "" {
+			return 0
+		}
+		return 1
+	}
+
+	var countries []CountryCompleteness
+	for _, country := range r.countries() {
+		c := CountryCompleteness{CountryISO2: country}
+		for code := range r.byCountry[country] {
+			bank := r.banks[code]
+			c.Codes++
+			c.WithAddress += filled(bank.Address)
+			c.WithTown += filled(bank.Town)
+			c.WithTimeZone += filled(bank.TimeZone)
+			if !bank.IsHeadquarter {
+				c.Branches++
+				if r.hasHeadquarters(bank.SwiftCodeBase) {
+					c.BranchesWithHQ++
+				}
+			}
+		}
+		countries = append(countries, c)
+	}
+	return countries, nil
+}
+
+// Helper methods; callers hold the lock
+
+// put stores bank and indexes it, replacing a bank with the same code
+func (r *MemorySwiftRepository) put(bank model.SwiftBank) {
+	r.remove(bank.SwiftCode)
+	r.banks[bank.SwiftCode] = bank
+	index(r.byBase, bank.SwiftCodeBase, bank.SwiftCode)
+	index(r.byCountry, bank.CountryISOCode, bank.SwiftCode)
+}
+
+// remove drops the bank stored under code, if any, and its index entries
+func (r *MemorySwiftRepository) remove(code string) {
+	bank, ok := r.banks[code]
+	if !ok {
+		return
+	}
+	delete(r.banks, code)
+	unindex(r.byBase, bank.SwiftCodeBase, code)
+	unindex(r.byCountry, bank.CountryISOCode, code)
+}
+
+// listedBanks returns the listed form of the banks stored under codes, ordered
+// by sort
+func (r *MemorySwiftRepository) listedBanks(codes map[string]struct{}, sort Sort) []model.SwiftBank {
+	banks := make([]model.SwiftBank, 0, len(codes))
+	for code := range codes {
+		banks = append(banks, r.banks[code])
+	}
+	slices.SortFunc(banks, sort.compare)
+	return banks
+}
+
+// branches returns the branches sharing base, ordered and windowed as opts
+// asks
+func (r *MemorySwiftRepository) branches(base string, opts QueryOptions) []model.SwiftBank {
+	var branches []model.SwiftBank
+	for _, bank := range r.listedBanks(r.byBase[base], opts.Sort) {
+		if !bank.IsHeadquarter {
+			branches = append(branches, bank)
+		}
+	}
+	return window(branches, opts)
+}
+
+func (r *MemorySwiftRepository) hasHeadquarters(base string) bool {
+	for code := range r.byBase[base] {
+		if r.banks[code].IsHeadquarter {
+			return true
+		}
+	}
+	return false
+}
+
+// countries returns the stored country codes in order
+func (r *MemorySwiftRepository) countries() []string {
+	countries := make([]string, 0, len(r.byCountry))
+	for country := range r.byCountry {
+		countries = append(countries, country)
+	}
+	slices.Sort(countries)
+	return countries
+}
+
+// normalize upper-cases the codes of bank and derives its code base, as
+// the SQL repository does before inserting
+func normalize(bank *model.SwiftBank) {
+	bank.SwiftCode = strings.ToUpper(bank.SwiftCode)
+	bank.CountryISOCode = strings.ToUpper(bank.CountryISOCode)
+	if bank.SwiftCodeBase == "" {
+		bank.SwiftCodeBase = model.BIC8(bank.SwiftCode)
+	}
+}
+
+// window returns the part of rows selected by the Offset and Limit of opts,
+// or nil when it is empty, as a query returning no rows leaves its slice
+func window(rows []model.SwiftBank, opts QueryOptions) []model.SwiftBank {
+	rows = rows[min(opts.Offset, len(rows)):]
+	if opts.Limit > 0 && opts.Limit < len(rows) {
+		rows = rows[:opts.Limit]
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return rows
+}
+
+func containsFold(value, substr string) bool {
+	return strings.Contains(strings.ToUpper(value), strings.ToUpper(substr))
+}
+
+func headquarterRank(bank model.SwiftBank) int {
+	if bank.IsHeadquarter {
+		return 1
+	}
+	return 0
+}
+
+func index(idx map[string]map[string]struct{}, key, code string) {
+	if idx[key] == nil {
+		idx[key] = make(map[string]struct{})
+	}
+	idx[key][code] = struct{}{}
+}
+
+func unindex(idx map[string]map[string]struct{}, key, code string) {
+	delete(idx[key], code)
+	if len(idx[key]) == 0 {
+		delete(idx, key)
+	}
+}
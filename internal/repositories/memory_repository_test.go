@@ -0,0 +1,166 @@
+package repository_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/models"
+	repo "github.com/zdziszkee/swift-codes/internal/repositories"
+)
+
+var _ = Describe("MemorySwiftRepository", func() {
+	var (
+		ctx        context.Context
+		repository repo.SwiftRepository
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repository = repo.NewMemorySwiftRepository()
+		Expect(repository.CreateBatch(ctx, []*models.SwiftBank{
+			{SwiftCode: "pkopplpwxxx", CountryISOCode: "pl", BankName: "PKO BP", IsHeadquarter: true, Address: "PULAWSKA 15", CountryName: "POLAND", Town: "WARSZAWA"},
+			{SwiftCode: "PKOPPLPWKRK", CountryISOCode: "PL", BankName: "PKO BP KRAKOW", Address: "RYNEK 1", CountryName: "POLAND", Town: "KRAKOW"},
+			{SwiftCode: "PKOPPLPWGDA", CountryISOCode: "PL", BankName: "PKO BP GDANSK", Address: "DLUGA 2", CountryName: "POLAND", Town: "GDANSK", TimeZone: "Europe/Warsaw"},
+			{SwiftCode: "BREXPLPWXXX", CountryISOCode: "PL", BankName: "MBANK", IsHeadquarter: true, CountryName: "POLAND"},
+			{SwiftCode: "ORPHMTMTABC", CountryISOCode: "MT", BankName: "ORPHAN BRANCH", CountryName: "MALTA"},
+		})).To(Succeed())
+	})
+
+	It("should normalize codes and return a headquarters with its sorted branches", func() {
+		detail, err := repository.GetByCode(ctx, "pkopplpwxxx", repo.QueryOptions{Sort: repo.Sort{Field: repo.SortBankName, Descending: true}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(detail.Bank.SwiftCodeBase).To(Equal("PKOPPLPW"))
+		Expect(detail.Bank.CountryISOCode).To(Equal("PL"))
+		Expect(detail.Bank.Town).To(Equal("WARSZAWA"))
+		Expect(detail.Branches).To(HaveLen(2))
+		Expect(detail.Branches[0].SwiftCode).To(Equal("PKOPPLPWKRK"))
+		Expect(detail.Branches[0].Town).To(Equal("KRAKOW"))
+
+		_, err = repository.GetByCode(ctx, "MISSPLPWXXX", repo.QueryOptions{})
+		Expect(err).To(MatchError(repo.ErrNotFound))
+	})
+
+	It("should filter, sort and page a country while counting every match", func() {
+		codes, err := repository.GetByCountry(ctx, "pl", repo.QueryOptions{Type: repo.BankTypeBranch, Limit: 1, Offset: 1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(codes.CountryName).To(Equal("POLAND"))
+		Expect(codes.Total).To(Equal(2))
+		Expect(codes.SwiftCodes).To(ConsistOf(HaveField("SwiftCode", "PKOPPLPWKRK")))
+
+		codes, err = repository.GetByCountry(ctx, "PL", repo.QueryOptions{Town: "krak"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(codes.SwiftCodes).To(ConsistOf(HaveField("SwiftCode", "PKOPPLPWKRK")))
+
+		_, err = repository.GetByCountry(ctx, "DE", repo.QueryOptions{})
+		Expect(err).To(MatchError(repo.ErrNotFound))
+	})
+
+	It("should page a country by cursor in either direction", func() {
+		codes, err := repository.GetByCountry(ctx, "PL", repo.QueryOptions{Limit: 2, After: "PKOPPLPWGDA"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(codes.Total).To(Equal(4))
+		Expect(codes.SwiftCodes).To(HaveLen(2))
+		Expect(codes.SwiftCodes[0].SwiftCode).To(Equal("PKOPPLPWKRK"))
+		Expect(codes.SwiftCodes[1].SwiftCode).To(Equal("PKOPPLPWXXX"))
+
+		codes, err = repository.GetByCountry(ctx, "PL", repo.QueryOptions{After: "PKOPPLPWGDA", Sort: repo.Sort{Field: repo.SortSwiftCode, Descending: true}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(codes.SwiftCodes).To(ConsistOf(HaveField("SwiftCode", "BREXPLPWXXX")))
+
+		_, err = repository.GetByCountry(ctx, "PL", repo.QueryOptions{After: "PKOPPLPWGDA", Offset: 1})
+		Expect(err).To(MatchError(repo.ErrInvalidData))
+	})
+
+	It("should upsert changed and new codes while keeping contacts", func() {
+		_, err := repository.UpdateContacts(ctx, []models.BankContact{{SwiftCode: "BREXPLPWXXX", Phone: "+48 801 300 800"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		changed, err := repository.Upsert(ctx, []*models.SwiftBank{
+			{SwiftCode: "BREXPLPWXXX", CountryISOCode: "PL", BankName: "MBANK", IsHeadquarter: true, CountryName: "POLAND"},
+			{SwiftCode: "ORPHMTMTABC", CountryISOCode: "PL", BankName: "MOVED BRANCH", CountryName: "POLAND"},
+			{SwiftCode: "INGBPLPWXXX", CountryISOCode: "PL", BankName: "ING", IsHeadquarter: true, CountryName: "POLAND"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeEquivalentTo(2))
+
+		detail, err := repository.GetByCode(ctx, "BREXPLPWXXX", repo.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(detail.Bank.Phone).To(Equal("+48 801 300 800"))
+
+		_, err = repository.GetByCountry(ctx, "MT", repo.QueryOptions{})
+		Expect(err).To(MatchError(repo.ErrNotFound))
+		codes, err := repository.GetByCountry(ctx, "PL", repo.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(codes.Total).To(Equal(6))
+	})
+
+	It("should group a code base with its headquarters first", func() {
+		group, err := repository.GetByBase(ctx, "PKOPPLPW", repo.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(group.Headquarters.SwiftCode).To(Equal("PKOPPLPWXXX"))
+		Expect(group.Branches).To(HaveLen(2))
+
+		_, err = repository.GetHeadquartersByBase(ctx, "ORPHMTMT", repo.QueryOptions{})
+		Expect(err).To(MatchError(repo.ErrNotFound))
+	})
+
+	It("should reject duplicates and keep the indexes in step with deletes", func() {
+		Expect(repository.Create(ctx, &models.SwiftBank{SwiftCode: "pkopplpwxxx"})).To(MatchError(repo.ErrDuplicate))
+
+		Expect(repository.Delete(ctx, "PKOPPLPWKRK")).To(Succeed())
+		Expect(repository.Delete(ctx, "PKOPPLPWKRK")).To(MatchError(repo.ErrNotFound))
+		branches, err := repository.GetBranchesByHQBase(ctx, "PKOPPLPW", repo.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(branches).To(ConsistOf(HaveField("SwiftCode", "PKOPPLPWGDA")))
+
+		deleted, err := repository.DeleteByCountry(ctx, "mt")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(BeEquivalentTo(1))
+		stats, err := repository.Stats(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*stats).To(Equal(repo.DatasetStats{TotalCodes: 3, Headquarters: 2, Branches: 1, Countries: 1}))
+	})
+
+	It("should read contacts back on every lookup", func() {
+		updated, err := repository.UpdateContacts(ctx, []models.BankContact{
+			{SwiftCode: "pkopplpwxxx", Website: "https://pkobp.pl", Phone: "+48 800 302 302"},
+			{SwiftCode: "MISSPLPWXXX", Website: "https://example.com"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated).To(BeEquivalentTo(1))
+
+		detail, err := repository.GetByCode(ctx, "PKOPPLPWXXX", repo.QueryOptions{OmitBranches: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(detail.Bank.Website).To(Equal("https://pkobp.pl"))
+
+		all, err := repository.ListAll(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(all).To(HaveLen(5))
+		Expect(all[0].SwiftCode).To(Equal("BREXPLPWXXX"))
+		Expect(all).To(ContainElement(And(HaveField("SwiftCode", "PKOPPLPWXXX"), HaveField("Website", "https://pkobp.pl"), HaveField("Town", "WARSZAWA"))))
+	})
+
+	It("should report per-country counts and completeness", func() {
+		counts, err := repository.CountryCounts(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(counts).To(Equal([]repo.CountryCount{
+			{CountryISO2: "MT", CountryName: "MALTA", TotalCodes: 1, Branches: 1},
+			{CountryISO2: "PL", CountryName: "POLAND", TotalCodes: 4, Headquarters: 2, Branches: 2},
+		}))
+
+		completeness, err := repository.Completeness(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(completeness).To(Equal([]repo.CountryCompleteness{
+			{CountryISO2: "MT", Codes: 1, Branches: 1},
+			{CountryISO2: "PL", Codes: 4, WithAddress: 3, WithTown: 3, WithTimeZone: 1, Branches: 2, BranchesWithHQ: 2},
+		}))
+	})
+
+	It("should refuse time travel", func() {
+		_, err := repository.GetByCode(ctx, "PKOPPLPWXXX", repo.QueryOptions{AsOf: time.Now().Add(-time.Hour)})
+		Expect(err).To(MatchError(repo.ErrInvalidData))
+	})
+})
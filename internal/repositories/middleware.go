@@ -0,0 +1,519 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	model "github.com/zdziszkee/swift-codes/internal/models"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+	"github.com/zdziszkee/swift-codes/internal/timing"
+)
+
+// Operation names passed to interceptors
+const (
+	OpGetByCode             = "GetByCode"
+	OpGetByCountry          = "GetByCountry"
+	OpCreate                = "Create"
+	OpCreateBatch           = "CreateBatch"
+	OpDelete                = "Delete"
+	OpDeleteByCountry       = "DeleteByCountry"
+	OpGetBranchesByHQBase   = "GetBranchesByHQBase"
+	OpGetHeadquartersByBase = "GetHeadquartersByBase"
+	OpGetByBase             = "GetByBase"
+	OpLoadCSV               = "LoadCSV"
+	OpStats                 = "Stats"
+	OpCompleteness          = "Completeness"
+	OpCountryCounts         = "CountryCounts"
+	OpUpdateContacts        = "UpdateContacts"
+	OpUpsert                = "Upsert"
+	OpListAll               = "ListAll"
+)
+
+var ErrCircuitOpen = errors.New("repository circuit breaker is open")
+
+// ErrQueryTimeout is returned when an operation outlives its QueryTimeouts
+// bound while the caller's own deadline has not passed
+var ErrQueryTimeout = errors.New("repository query timed out")
+
+// Middleware decorates a SwiftRepository with cross-cutting behavior
+type Middleware func(SwiftRepository) SwiftRepository
+
+// Chain wraps repo with the given middlewares. The first middleware is the
+// outermost one and sees every call first.
+func Chain(repo SwiftRepository, middlewares ...Middleware) SwiftRepository {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		repo = middlewares[i](repo)
+	}
+	return repo
+}
+
+// Interceptor wraps a single repository call identified by op
+type Interceptor func(ctx context.Context, op string, call func(ctx context.Context) error) error
+
+// Intercept turns an Interceptor into a Middleware applied to every method
+func Intercept(interceptor Interceptor) Middleware {
+	return func(next SwiftRepository) SwiftRepository {
+		return &interceptedRepository{next: next, intercept: interceptor}
+	}
+}
+
+// MiddlewareConfig selects which repository middlewares are enabled.
+// Zero values disable the corresponding middleware.
+type MiddlewareConfig struct {
+	Logging          bool          `koanf:"logging"`
+	RetryAttempts    int           `koanf:"retry_attempts"`
+	RetryBackoff     time.Duration `koanf:"retry_backoff"`
+	BreakerThreshold int           `koanf:"breaker_threshold"`
+	BreakerCooldown  time.Duration `koanf:"breaker_cooldown"`
+	CacheTTL         time.Duration `koanf:"cache_ttl"`
+	// CacheMaxEntries bounds each table's cache, evicting the least
+	// recently used entries; 0 means no bound
+	CacheMaxEntries int `koanf:"cache_max_entries"`
+	// PartialBranches answers code lookups without branches, flagged with
+	// branches_unavailable, when only the branch query fails
+	PartialBranches bool `koanf:"partial_branches"`
+	// QueryLog configures SQL logging, active when the log level is debug
+	QueryLog QueryLogConfig `koanf:"query_log"`
+	// Timeouts bounds single operations within the request timeout
+	Timeouts QueryTimeouts `koanf:"timeouts"`
+	// OverwriteDuplicates is set when batch loads replace stored codes, as
+	// with database.on_duplicate = "overwrite" or the memory driver, so the
+	// cache drops every entry on each one
+	OverwriteDuplicates bool `koanf:"-"`
+}
+
+// QueryTimeouts bounds each kind of repository operation, so that a slow
+// country scan fails on its own instead of using up the whole request
+// budget. A zero duration leaves that kind unbounded.
+type QueryTimeouts struct {
+	// Lookup bounds code, branch, headquarters and institution reads
+	Lookup time.Duration `koanf:"lookup"`
+	// Country bounds country listings
+	Country time.Duration `koanf:"country"`
+	// Batch bounds batch inserts and upserts, each covering a whole import
+	Batch time.Duration `koanf:"batch"`
+}
+
+// Validate rejects negative timeouts
+func (t QueryTimeouts) Validate() error {
+	if t.Lookup < 0 || t.Country < 0 || t.Batch < 0 {
+		return errors.New("repository timeouts cannot be negative")
+	}
+	return nil
+}
+
+func (t QueryTimeouts) enabled() bool {
+	return t.Lookup > 0 || t.Country > 0 || t.Batch > 0
+}
+
+func (t QueryTimeouts) forOp(op string) time.Duration {
+	switch op {
+	case OpGetByCode, OpGetBranchesByHQBase, OpGetHeadquartersByBase, OpGetByBase:
+		return t.Lookup
+	case OpGetByCountry:
+		return t.Country
+	case OpCreateBatch, OpUpsert:
+		return t.Batch
+	}
+	return 0
+}
+
+// Middlewares builds the configured chain: logging and metrics observe every
+// call, the cache answers before the timeouts and the breaker, and retries
+// sit closest to the database, so a timeout covers every attempt. A non-nil
+// bus shares cache invalidations with other replicas, and a non-nil ttl
+// replaces CacheTTL so that it can be changed later. A non-nil remote cache,
+// such as Redis, answers what the in-process cache misses.
+func (cfg MiddlewareConfig) Middlewares(metrics *Metrics, bus InvalidationBus, ttl *CacheTTL, remote Middleware) []Middleware {
+	var middlewares []Middleware
+	if cfg.Logging {
+		middlewares = append(middlewares, WithLogging())
+	}
+	if metrics != nil {
+		middlewares = append(middlewares, WithMetrics(metrics))
+	}
+	if cfg.CacheTTL > 0 {
+		if ttl == nil {
+			ttl = NewCacheTTL(cfg.CacheTTL)
+		}
+		cache := WithBoundedCache
+		if cfg.OverwriteDuplicates {
+			cache = WithOverwritingCache
+		}
+		middlewares = append(middlewares, cache(ttl, cfg.CacheMaxEntries, bus, metrics))
+	}
+	if remote != nil {
+		middlewares = append(middlewares, remote)
+	}
+	if cfg.Timeouts.enabled() {
+		middlewares = append(middlewares, WithTimeouts(cfg.Timeouts))
+	}
+	if cfg.BreakerThreshold > 0 {
+		middlewares = append(middlewares, WithCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown))
+	}
+	if cfg.RetryAttempts > 1 {
+		middlewares = append(middlewares, WithRetry(cfg.RetryAttempts, cfg.RetryBackoff))
+	}
+	return middlewares
+}
+
+// isInfrastructureError reports whether err is a failure of the backend
+// rather than an expected domain outcome
+func isInfrastructureError(err error) bool {
+	return err != nil &&
+		!errors.Is(err, ErrNotFound) &&
+		!errors.Is(err, ErrDuplicate) &&
+		!errors.Is(err, ErrInvalidData) &&
+		!errors.Is(err, context.Canceled)
+}
+
+// WithLogging logs every repository call with its duration and error
+func WithLogging() Middleware {
+	return Intercept(func(ctx context.Context, op string, call func(ctx context.Context) error) error {
+		start := time.Now()
+		err := call(ctx)
+		if err != nil {
+			requestid.Logf(ctx, "repository %s failed after %v: %v", op, time.Since(start), err)
+		} else {
+			requestid.Logf(ctx, "repository %s completed in %v", op, time.Since(start))
+		}
+		return err
+	})
+}
+
+// OperationStats holds aggregated metrics for a single operation
+type OperationStats struct {
+	Operation       string  `json:"operation"`
+	Calls           int64   `json:"calls"`
+	Errors          int64   `json:"errors"`
+	TotalDurationMs float64 `json:"total_duration_ms"`
+	// Degraded counts calls answered with partial data
+	Degraded int64 `json:"degraded"`
+}
+
+// Metrics aggregates per-operation call counts, errors and durations, and
+// the counters of the caches built with it
+type Metrics struct {
+	mu    sync.Mutex
+	stats map[string]*OperationStats
+	cache cacheCounters
+}
+
+// NewMetrics creates an empty metrics collector
+func NewMetrics() *Metrics {
+	return &Metrics{stats: make(map[string]*OperationStats)}
+}
+
+// Snapshot returns the collected stats ordered by operation name
+func (m *Metrics) Snapshot() []OperationStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make([]OperationStats, 0, len(m.stats))
+	for _, s := range m.stats {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Operation < stats[j].Operation
+	})
+	return stats
+}
+
+// Cache returns the counters of the caches built with m
+func (m *Metrics) Cache() CacheStats {
+	return m.cache.snapshot()
+}
+
+func (m *Metrics) record(op string, d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.stats[op]
+	if !ok {
+		s = &OperationStats{Operation: op}
+		m.stats[op] = s
+	}
+	s.Calls++
+	if isInfrastructureError(err) {
+		s.Errors++
+	}
+	s.TotalDurationMs += float64(d) / float64(time.Millisecond)
+}
+
+func (m *Metrics) recordDegraded(op string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.stats[op]
+	if !ok {
+		s = &OperationStats{Operation: op}
+		m.stats[op] = s
+	}
+	s.Degraded++
+}
+
+// WithMetrics records every call in metrics
+func WithMetrics(metrics *Metrics) Middleware {
+	return Intercept(func(ctx context.Context, op string, call func(ctx context.Context) error) error {
+		start := time.Now()
+		err := call(ctx)
+		metrics.record(op, time.Since(start), err)
+		return err
+	})
+}
+
+// retryableOps are safe to repeat; writes are never retried
+var retryableOps = map[string]bool{
+	OpGetByCode:             true,
+	OpGetByCountry:          true,
+	OpGetBranchesByHQBase:   true,
+	OpGetHeadquartersByBase: true,
+	OpGetByBase:             true,
+	OpStats:                 true,
+	OpCountryCounts:         true,
+}
+
+// WithRetry retries read operations that fail with infrastructure errors,
+// waiting backoff (doubled each attempt) between tries
+func WithRetry(attempts int, backoff time.Duration) Middleware {
+	return Intercept(func(ctx context.Context, op string, call func(ctx context.Context) error) error {
+		if !retryableOps[op] {
+			return call(ctx)
+		}
+
+		var err error
+		wait := backoff
+		for attempt := 1; attempt <= attempts; attempt++ {
+			err = call(ctx)
+			if !isInfrastructureError(err) || attempt == attempts {
+				return err
+			}
+
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(wait):
+			}
+			wait *= 2
+		}
+		return err
+	})
+}
+
+// WithCircuitBreaker fails fast with ErrCircuitOpen after threshold
+// consecutive infrastructure errors, letting a trial call through once
+// cooldown has elapsed
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Middleware {
+	var (
+		mu        sync.Mutex
+		failures  int
+		openUntil time.Time
+	)
+
+	return Intercept(func(ctx context.Context, op string, call func(ctx context.Context) error) error {
+		mu.Lock()
+		if failures >= threshold && time.Now().Before(openUntil) {
+			mu.Unlock()
+			return ErrCircuitOpen
+		}
+		mu.Unlock()
+
+		err := call(ctx)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if isInfrastructureError(err) {
+			failures++
+			if failures >= threshold {
+				openUntil = time.Now().Add(cooldown)
+			}
+		} else {
+			failures = 0
+		}
+		return err
+	})
+}
+
+// WithTimeouts runs each operation under a context.WithTimeout of its kind
+// of timeout. An operation cut off by that bound, rather than by the
+// caller's context, fails with ErrQueryTimeout.
+func WithTimeouts(timeouts QueryTimeouts) Middleware {
+	return Intercept(func(ctx context.Context, op string, call func(ctx context.Context) error) error {
+		d := timeouts.forOp(op)
+		if d <= 0 {
+			return call(ctx)
+		}
+		opCtx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+
+		err := call(opCtx)
+		if err != nil && ctx.Err() == nil && errors.Is(opCtx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w: %s exceeded %v", ErrQueryTimeout, op, d)
+		}
+		return err
+	})
+}
+
+// interceptedRepository routes every method through an Interceptor
+type interceptedRepository struct {
+	next      SwiftRepository
+	intercept Interceptor
+}
+
+func (r *interceptedRepository) GetByCode(ctx context.Context, code string, opts QueryOptions) (*SwiftBankDetail, error) {
+	var result *SwiftBankDetail
+	err := r.intercept(ctx, OpGetByCode, func(ctx context.Context) error {
+		var err error
+		result, err = r.next.GetByCode(ctx, code, opts)
+		return err
+	})
+	return result, err
+}
+
+func (r *interceptedRepository) GetByCountry(ctx context.Context, countryCode string, opts QueryOptions) (*CountrySwiftCodes, error) {
+	var result *CountrySwiftCodes
+	err := r.intercept(ctx, OpGetByCountry, func(ctx context.Context) error {
+		var err error
+		result, err = r.next.GetByCountry(ctx, countryCode, opts)
+		return err
+	})
+	return result, err
+}
+
+func (r *interceptedRepository) Create(ctx context.Context, bank *model.SwiftBank) error {
+	return r.intercept(ctx, OpCreate, func(ctx context.Context) error {
+		return r.next.Create(ctx, bank)
+	})
+}
+
+func (r *interceptedRepository) CreateBatch(ctx context.Context, banks []*model.SwiftBank) error {
+	return r.intercept(ctx, OpCreateBatch, func(ctx context.Context) error {
+		return r.next.CreateBatch(ctx, banks)
+	})
+}
+
+func (r *interceptedRepository) Delete(ctx context.Context, code string) error {
+	return r.intercept(ctx, OpDelete, func(ctx context.Context) error {
+		return r.next.Delete(ctx, code)
+	})
+}
+
+func (r *interceptedRepository) DeleteByCountry(ctx context.Context, countryCode string) (int64, error) {
+	var deleted int64
+	err := r.intercept(ctx, OpDeleteByCountry, func(ctx context.Context) error {
+		var err error
+		deleted, err = r.next.DeleteByCountry(ctx, countryCode)
+		return err
+	})
+	return deleted, err
+}
+
+func (r *interceptedRepository) GetBranchesByHQBase(ctx context.Context, hqBase string, opts QueryOptions) ([]model.SwiftBank, error) {
+	var result []model.SwiftBank
+	err := r.intercept(ctx, OpGetBranchesByHQBase, func(ctx context.Context) error {
+		var err error
+		result, err = r.next.GetBranchesByHQBase(ctx, hqBase, opts)
+		return err
+	})
+	return result, err
+}
+
+func (r *interceptedRepository) GetHeadquartersByBase(ctx context.Context, hqBase string, opts QueryOptions) (*model.SwiftBank, error) {
+	var result *model.SwiftBank
+	err := r.intercept(ctx, OpGetHeadquartersByBase, func(ctx context.Context) error {
+		var err error
+		result, err = r.next.GetHeadquartersByBase(ctx, hqBase, opts)
+		return err
+	})
+	return result, err
+}
+
+func (r *interceptedRepository) GetByBase(ctx context.Context, base string, opts QueryOptions) (*BankGroup, error) {
+	var result *BankGroup
+	err := r.intercept(ctx, OpGetByBase, func(ctx context.Context) error {
+		var err error
+		result, err = r.next.GetByBase(ctx, base, opts)
+		return err
+	})
+	return result, err
+}
+
+func (r *interceptedRepository) LoadCSV(ctx context.Context, csvPath string) error {
+	return r.intercept(ctx, OpLoadCSV, func(ctx context.Context) error {
+		return r.next.LoadCSV(ctx, csvPath)
+	})
+}
+
+func (r *interceptedRepository) Stats(ctx context.Context) (*DatasetStats, error) {
+	var result *DatasetStats
+	err := r.intercept(ctx, OpStats, func(ctx context.Context) error {
+		var err error
+		result, err = r.next.Stats(ctx)
+		return err
+	})
+	return result, err
+}
+
+func (r *interceptedRepository) CountryCounts(ctx context.Context) ([]CountryCount, error) {
+	var result []CountryCount
+	err := r.intercept(ctx, OpCountryCounts, func(ctx context.Context) error {
+		var err error
+		result, err = r.next.CountryCounts(ctx)
+		return err
+	})
+	return result, err
+}
+
+func (r *interceptedRepository) Completeness(ctx context.Context) ([]CountryCompleteness, error) {
+	var result []CountryCompleteness
+	err := r.intercept(ctx, OpCompleteness, func(ctx context.Context) error {
+		var err error
+		result, err = r.next.Completeness(ctx)
+		return err
+	})
+	return result, err
+}
+
+func (r *interceptedRepository) UpdateContacts(ctx context.Context, contacts []model.BankContact) (int64, error) {
+	var updated int64
+	err := r.intercept(ctx, OpUpdateContacts, func(ctx context.Context) error {
+		var err error
+		updated, err = r.next.UpdateContacts(ctx, contacts)
+		return err
+	})
+	return updated, err
+}
+
+func (r *interceptedRepository) Upsert(ctx context.Context, banks []*model.SwiftBank) (int64, error) {
+	var changed int64
+	err := r.intercept(ctx, OpUpsert, func(ctx context.Context) error {
+		var err error
+		changed, err = r.next.Upsert(ctx, banks)
+		return err
+	})
+	return changed, err
+}
+
+func (r *interceptedRepository) ListAll(ctx context.Context) ([]model.SwiftBank, error) {
+	var result []model.SwiftBank
+	err := r.intercept(ctx, OpListAll, func(ctx context.Context) error {
+		var err error
+		result, err = r.next.ListAll(ctx)
+		return err
+	})
+	return result, err
+}
+
+// WithTiming adds the duration of every call to the trino stage of the
+// request timings carried in the context. Place it innermost so cache hits
+// are not counted.
+func WithTiming() Middleware {
+	return Intercept(func(ctx context.Context, op string, call func(ctx context.Context) error) error {
+		defer timing.Track(ctx, timing.StageTrino)()
+		return call(ctx)
+	})
+}
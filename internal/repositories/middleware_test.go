@@ -0,0 +1,313 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/models"
+	repo "github.com/zdziszkee/swift-codes/internal/repositories"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+var _ = Describe("Repository middleware", func() {
+	var (
+		ctx      context.Context
+		mockRepo *mocks.MockSwiftRepository
+		calls    int
+		errDown  = errors.New("trino unavailable")
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		calls = 0
+		mockRepo = &mocks.MockSwiftRepository{
+			GetByCodeFunc: func(ctx context.Context, code string, opts repo.QueryOptions) (*repo.SwiftBankDetail, error) {
+				calls++
+				return &repo.SwiftBankDetail{Bank: models.SwiftBank{SwiftCode: code}}, nil
+			},
+			DeleteFunc: func(ctx context.Context, code string) error {
+				return nil
+			},
+		}
+	})
+
+	It("should apply middlewares outermost first", func() {
+		var order []string
+		trace := func(name string) repo.Middleware {
+			return repo.Intercept(func(ctx context.Context, op string, call func(ctx context.Context) error) error {
+				order = append(order, name+":"+op)
+				return call(ctx)
+			})
+		}
+
+		chained := repo.Chain(mockRepo, trace("outer"), trace("inner"))
+		_, err := chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(order).To(Equal([]string{"outer:GetByCode", "inner:GetByCode"}))
+	})
+
+	It("should record metrics per operation", func() {
+		metrics := repo.NewMetrics()
+		mockRepo.DeleteFunc = func(ctx context.Context, code string) error {
+			return errDown
+		}
+
+		chained := repo.Chain(mockRepo, repo.WithMetrics(metrics))
+		_, _ = chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+		_ = chained.Delete(ctx, "ABCDUS33XXX")
+
+		stats := metrics.Snapshot()
+		Expect(stats).To(HaveLen(2))
+		Expect(stats[0].Operation).To(Equal(repo.OpDelete))
+		Expect(stats[0].Errors).To(Equal(int64(1)))
+		Expect(stats[1].Operation).To(Equal(repo.OpGetByCode))
+		Expect(stats[1].Calls).To(Equal(int64(1)))
+	})
+
+	It("should retry reads on infrastructure errors only", func() {
+		mockRepo.GetByCodeFunc = func(ctx context.Context, code string, opts repo.QueryOptions) (*repo.SwiftBankDetail, error) {
+			calls++
+			if calls < 3 {
+				return nil, errDown
+			}
+			return &repo.SwiftBankDetail{}, nil
+		}
+
+		chained := repo.Chain(mockRepo, repo.WithRetry(3, time.Millisecond))
+		_, err := chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(calls).To(Equal(3))
+
+		calls = 0
+		mockRepo.GetByCodeFunc = func(ctx context.Context, code string, opts repo.QueryOptions) (*repo.SwiftBankDetail, error) {
+			calls++
+			return nil, repo.ErrNotFound
+		}
+		_, err = chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+		Expect(err).To(MatchError(repo.ErrNotFound))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("should open the circuit after consecutive failures", func() {
+		mockRepo.GetByCodeFunc = func(ctx context.Context, code string, opts repo.QueryOptions) (*repo.SwiftBankDetail, error) {
+			calls++
+			return nil, errDown
+		}
+
+		chained := repo.Chain(mockRepo, repo.WithCircuitBreaker(2, time.Minute))
+		_, _ = chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+		_, _ = chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+		_, err := chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+		Expect(err).To(MatchError(repo.ErrCircuitOpen))
+		Expect(calls).To(Equal(2))
+	})
+
+	It("should serve cached reads until a write invalidates them", func() {
+		chained := repo.Chain(mockRepo, repo.WithCache(time.Minute))
+
+		_, _ = chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+		_, _ = chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+		Expect(calls).To(Equal(1))
+
+		Expect(chained.Delete(ctx, "ABCDUS33XXX")).To(Succeed())
+		_, _ = chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+		Expect(calls).To(Equal(2))
+	})
+
+	It("should number the cached details until a write drops them", func() {
+		chained := repo.Chain(mockRepo, repo.WithCache(time.Minute))
+
+		first, err := chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(first.CacheEntry).To(BeZero())
+		hit, err := chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hit.CacheEntry).NotTo(BeZero())
+		again, err := chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again.CacheEntry).To(Equal(hit.CacheEntry))
+
+		Expect(chained.Delete(ctx, "ABCDUS33XXX")).To(Succeed())
+		_, _ = chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+		reloaded, err := chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reloaded.CacheEntry).To(BeNumerically(">", hit.CacheEntry))
+	})
+
+	It("should not cache a read that a write overtook while it loaded", func() {
+		var chained repo.SwiftRepository
+		mockRepo.GetByCodeFunc = func(ctx context.Context, code string, opts repo.QueryOptions) (*repo.SwiftBankDetail, error) {
+			calls++
+			if calls == 1 {
+				// The delete commits and evicts after this read loaded the row
+				Expect(chained.Delete(ctx, code)).To(Succeed())
+			}
+			return &repo.SwiftBankDetail{Bank: models.SwiftBank{SwiftCode: code}}, nil
+		}
+		chained = repo.Chain(mockRepo, repo.WithCache(time.Minute))
+
+		_, _ = chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+		_, _ = chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+		_, _ = chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+		Expect(calls).To(Equal(2))
+	})
+
+	It("should not cache details without their branches", func() {
+		mockRepo.GetByCodeFunc = func(ctx context.Context, code string, opts repo.QueryOptions) (*repo.SwiftBankDetail, error) {
+			calls++
+			return &repo.SwiftBankDetail{Bank: models.SwiftBank{SwiftCode: code}, BranchesUnavailable: true}, nil
+		}
+		chained := repo.Chain(mockRepo, repo.WithCache(time.Minute))
+
+		_, _ = chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+		_, _ = chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+		Expect(calls).To(Equal(2))
+	})
+
+	It("should read past the cache for strong and time-travel reads", func() {
+		chained := repo.Chain(mockRepo, repo.WithCache(time.Minute))
+
+		_, _ = chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+		_, _ = chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{Consistency: repo.ConsistencyStrong})
+		_, _ = chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{AsOf: time.Now()})
+		_, _ = chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+		Expect(calls).To(Equal(3))
+	})
+
+	It("should cache details separately per option set", func() {
+		chained := repo.Chain(mockRepo, repo.WithCache(time.Minute))
+
+		_, _ = chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+		_, _ = chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{OmitBranches: true})
+		_, _ = chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{OmitBranches: true})
+		Expect(calls).To(Equal(2))
+
+		Expect(chained.Delete(ctx, "ABCDUS33XXX")).To(Succeed())
+		_, _ = chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+		_, _ = chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{OmitBranches: true})
+		Expect(calls).To(Equal(4))
+	})
+
+	It("should evict only the entries of the countries a write touched", func() {
+		countryCalls := map[string]int{}
+		mockRepo.GetByCountryFunc = func(ctx context.Context, countryCode string, opts repo.QueryOptions) (*repo.CountrySwiftCodes, error) {
+			countryCalls[countryCode]++
+			return &repo.CountrySwiftCodes{CountryISO2: countryCode}, nil
+		}
+		mockRepo.DeleteByCountryFunc = func(ctx context.Context, countryCode string) (int64, error) { return 1, nil }
+		chained := repo.Chain(mockRepo, repo.WithCache(time.Minute))
+
+		read := func() {
+			_, _ = chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+			_, _ = chained.GetByCode(ctx, "ABCDPLPWXXX", repo.QueryOptions{})
+			_, _ = chained.GetByCountry(ctx, "US", repo.QueryOptions{})
+			_, _ = chained.GetByCountry(ctx, "PL", repo.QueryOptions{})
+		}
+		read()
+		_, err := chained.DeleteByCountry(ctx, "PL")
+		Expect(err).NotTo(HaveOccurred())
+		read()
+
+		Expect(calls).To(Equal(3))
+		Expect(countryCalls).To(Equal(map[string]int{"US": 1, "PL": 2}))
+	})
+
+	It("should drop cached details and listings of codes whose contacts changed", func() {
+		mockRepo.UpdateContactsFunc = func(ctx context.Context, contacts []models.BankContact) (int64, error) {
+			return int64(len(contacts)), nil
+		}
+		countryCalls := map[string]int{}
+		mockRepo.GetByCountryFunc = func(ctx context.Context, countryCode string, opts repo.QueryOptions) (*repo.CountrySwiftCodes, error) {
+			countryCalls[countryCode]++
+			return &repo.CountrySwiftCodes{CountryISO2: countryCode}, nil
+		}
+		chained := repo.Chain(mockRepo, repo.WithCache(time.Minute))
+
+		read := func() {
+			_, _ = chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+			_, _ = chained.GetByCode(ctx, "ABCDPLPWXXX", repo.QueryOptions{})
+			_, _ = chained.GetByCountry(ctx, "US", repo.QueryOptions{})
+			_, _ = chained.GetByCountry(ctx, "PL", repo.QueryOptions{})
+		}
+		read()
+		_, err := chained.UpdateContacts(ctx, []models.BankContact{{SwiftCode: "abcdus33xxx", Phone: "1"}})
+		Expect(err).NotTo(HaveOccurred())
+		read()
+
+		Expect(calls).To(Equal(3))
+		Expect(countryCalls).To(Equal(map[string]int{"US": 2, "PL": 1}))
+	})
+
+	It("should drop every entry when an overwriting batch moves a code to another country", func() {
+		stored := repo.NewMemorySwiftRepository()
+		Expect(stored.CreateBatch(ctx, []*models.SwiftBank{
+			{SwiftCode: "ABCDPLPWXXX", CountryISOCode: "DE", BankName: "ABCD", IsHeadquarter: true, CountryName: "GERMANY"},
+			{SwiftCode: "DEUTDEFFXXX", CountryISOCode: "DE", BankName: "DEUTSCHE BANK", IsHeadquarter: true, CountryName: "GERMANY"},
+		})).To(Succeed())
+		cfg := repo.MiddlewareConfig{CacheTTL: time.Minute, OverwriteDuplicates: true}
+		chained := repo.Chain(stored, cfg.Middlewares(nil, nil, nil, nil)...)
+
+		codes, err := chained.GetByCountry(ctx, "DE", repo.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(codes.SwiftCodes).To(HaveLen(2))
+
+		// Neither the code nor the new country names Germany
+		Expect(chained.CreateBatch(ctx, []*models.SwiftBank{
+			{SwiftCode: "ABCDPLPWXXX", CountryISOCode: "PL", BankName: "ABCD", IsHeadquarter: true, CountryName: "POLAND"},
+		})).To(Succeed())
+
+		codes, err = chained.GetByCountry(ctx, "DE", repo.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(codes.SwiftCodes).To(ConsistOf(HaveField("SwiftCode", "DEUTDEFFXXX")))
+	})
+
+	It("should evict the least recently used entries and count hits and misses", func() {
+		metrics := repo.NewMetrics()
+		chained := repo.Chain(mockRepo, repo.WithBoundedCache(repo.NewCacheTTL(time.Minute), 2, nil, metrics))
+
+		for _, code := range []string{"AAAAUS33XXX", "BBBBUS33XXX", "AAAAUS33XXX", "CCCCUS33XXX", "AAAAUS33XXX", "BBBBUS33XXX"} {
+			_, _ = chained.GetByCode(ctx, code, repo.QueryOptions{})
+		}
+
+		Expect(calls).To(Equal(4))
+		Expect(metrics.Cache()).To(Equal(repo.CacheStats{Hits: 2, Misses: 4, HitRate: 2.0 / 6, Evictions: 2, Entries: 2}))
+
+		Expect(chained.Delete(ctx, "AAAAUS33XXX")).To(Succeed())
+		Expect(metrics.Cache().Entries).To(BeZero())
+	})
+
+	It("should cut off slow operations at the timeout of their kind", func() {
+		mockRepo.GetByCountryFunc = func(ctx context.Context, countryCode string, opts repo.QueryOptions) (*repo.CountrySwiftCodes, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		chained := repo.Chain(mockRepo, repo.WithTimeouts(repo.QueryTimeouts{Country: 10 * time.Millisecond}))
+
+		start := time.Now()
+		_, err := chained.GetByCountry(ctx, "PL", repo.QueryOptions{})
+		Expect(err).To(MatchError(repo.ErrQueryTimeout))
+		Expect(err).To(MatchError(ContainSubstring("GetByCountry exceeded 10ms")))
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+
+		// Lookups have no bound configured and the caller's own deadline is reported as is
+		_, err = chained.GetByCode(ctx, "ABCDUS33XXX", repo.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err = chained.GetByCountry(cancelled, "PL", repo.QueryOptions{})
+		Expect(err).To(MatchError(context.Canceled))
+		Expect(err).NotTo(MatchError(repo.ErrQueryTimeout))
+	})
+
+	It("should build the configured chain", func() {
+		cfg := repo.MiddlewareConfig{CacheTTL: time.Minute, RetryAttempts: 3}
+		Expect(cfg.Middlewares(nil, nil, nil, nil)).To(HaveLen(2))
+		Expect(cfg.Middlewares(repo.NewMetrics(), nil, nil, nil)).To(HaveLen(3))
+		cfg.Timeouts = repo.QueryTimeouts{Lookup: time.Second}
+		Expect(cfg.Middlewares(nil, nil, nil, nil)).To(HaveLen(3))
+	})
+})
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+)
+
+// defaultMaxLoggedSQL applies when QueryLogConfig.MaxLength is not set
+const defaultMaxLoggedSQL = 500
+
+// QueryLogConfig controls debug logging of the SQL the repository runs
+type QueryLogConfig struct {
+	// IncludeParams logs bound parameter values; by default they are redacted
+	IncludeParams bool `koanf:"include_params"`
+	// MaxLength caps the logged statement and parameter list, in bytes
+	MaxLength int `koanf:"max_length"`
+}
+
+// WithQueryLog logs every statement as configured by cfg while debug
+// returns true, that is while the log level is debug
+func WithQueryLog(cfg QueryLogConfig, debug func() bool) Option {
+	return func(r *SQLSwiftRepository) {
+		r.queryLog = &cfg
+		r.debug = debug
+	}
+}
+
+// logging reports whether statements are logged right now
+func (r *SQLSwiftRepository) logging() bool {
+	return r.queryLog != nil && r.debug()
+}
+
+// begin logs the statement when query logging is enabled and registers it
+// with the tracker; the returned function must be called when it finishes
+func (r *SQLSwiftRepository) begin(ctx context.Context, operation, query string, args ...any) func() {
+	if r.logging() {
+		requestid.Logf(ctx, "DEBUG: sql %s: %s", operation, r.queryLog.format(query, args))
+	}
+	return r.tracker.Begin(operation, query)
+}
+
+// debugf logs a message only when query logging is enabled
+func (r *SQLSwiftRepository) debugf(ctx context.Context, format string, args ...any) {
+	if r.logging() {
+		requestid.Logf(ctx, "DEBUG: "+format, args...)
+	}
+}
+
+// format renders query and its parameters, redacting values unless
+// IncludeParams is set
+func (cfg *QueryLogConfig) format(query string, args []any) string {
+	maxLength := cfg.MaxLength
+	if maxLength <= 0 {
+		maxLength = defaultMaxLoggedSQL
+	}
+
+	line := truncate(query, maxLength)
+	if len(args) == 0 {
+		return line
+	}
+	if !cfg.IncludeParams {
+		return fmt.Sprintf("%s [%d params redacted]", line, len(args))
+	}
+
+	params := make([]string, len(args))
+	for i, arg := range args {
+		params[i] = fmt.Sprintf("%v", arg)
+	}
+	return line + " [" + truncate(strings.Join(params, ", "), maxLength) + "]"
+}
+
+// truncate shortens s to at most n bytes, marking that it was cut
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	model "github.com/zdziszkee/swift-codes/internal/models"
+)
+
+// SortField names a column list results can be ordered by
+type SortField string
+
+// Sortable fields, named as they appear in the API
+const (
+	SortSwiftCode   SortField = "swiftCode"
+	SortBankName    SortField = "bankName"
+	SortCountryName SortField = "countryName"
+)
+
+// sortColumns maps sortable fields onto table columns. Only these columns
+// are ever interpolated into ORDER BY.
+var sortColumns = map[SortField]string{
+	SortSwiftCode:   "swift_code",
+	SortBankName:    "bank_name",
+	SortCountryName: "country_name",
+}
+
+// Sort orders list results by a single field
+type Sort struct {
+	Field      SortField
+	Descending bool
+}
+
+// BankType restricts list results to headquarters or branches
+type BankType string
+
+// Bank types accepted by ParseBankType; the zero value matches both
+const (
+	BankTypeAny         BankType = ""
+	BankTypeHeadquarter BankType = "headquarter"
+	BankTypeBranch      BankType = "branch"
+)
+
+// ParseBankType parses "headquarter" or "branch"; an empty value matches
+// every bank
+func ParseBankType(value string) (BankType, error) {
+	switch t := BankType(strings.ToLower(value)); t {
+	case BankTypeAny, BankTypeHeadquarter, BankTypeBranch:
+		return t, nil
+	default:
+		return BankTypeAny, fmt.Errorf("%w: unknown bank type %q", ErrInvalidData, value)
+	}
+}
+
+// Consistency says whether a read may be answered from a cache
+type Consistency string
+
+// Read consistency levels; the zero value lets caches answer
+const (
+	ConsistencyEventual Consistency = ""
+	ConsistencyStrong   Consistency = "strong"
+)
+
+// ParseConsistency parses "eventual" or "strong"; an empty value is
+// eventual
+func ParseConsistency(value string) (Consistency, error) {
+	switch strings.ToLower(value) {
+	case "", "eventual":
+		return ConsistencyEventual, nil
+	case "strong":
+		return ConsistencyStrong, nil
+	default:
+		return ConsistencyEventual, fmt.Errorf("%w: unknown consistency %q", ErrInvalidData, value)
+	}
+}
+
+// QueryOptions controls how read queries shape their results, so new query
+// features extend this struct instead of the SwiftRepository interface.
+// List queries window their rows with Limit and Offset, or with Limit and
+// After; GetByCode applies Sort, Limit and Offset to the branches of a
+// headquarter. A zero Limit returns every row from Offset onwards.
+type QueryOptions struct {
+	Sort   Sort
+	Type   BankType
+	Limit  int
+	Offset int
+	// After is a keyset cursor: list queries return the rows whose
+	// swift_code sorts after it, before it when sorting by swiftCode
+	// descending, ordered by swift_code. Unlike an Offset, which the
+	// database must scan past, it costs the same on every page. It cannot
+	// be combined with an Offset or another sort field.
+	After string
+
+	// Address and Town keep list rows whose address or town name contains
+	// them, ignoring case
+	Address string
+	Town    string
+
+	// Consistency set to strong bypasses caches
+	Consistency Consistency
+	// OmitBranches skips loading the branches of a headquarter in GetByCode
+	OmitBranches bool
+	// AsOf reads the table as it was at that time; the zero time reads the
+	// current snapshot
+	AsOf time.Time
+}
+
+// Paged reports whether the options select a window of the results
+func (o QueryOptions) Paged() bool {
+	return o.Limit > 0 || o.Offset > 0 || o.After != ""
+}
+
+// maxCursorLength is the length of the longest SWIFT code
+const maxCursorLength = 11
+
+// ParseCursor parses an After cursor, the SWIFT code that ended the previous
+// page, into upper case
+func ParseCursor(value string) (string, error) {
+	if len(value) > maxCursorLength {
+		return "", fmt.Errorf("%w: cursor longer than %d characters", ErrInvalidData, maxCursorLength)
+	}
+	for _, r := range value {
+		if (r < '0' || r > '9') && (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return "", fmt.Errorf("%w: cursor %q must be letters and digits", ErrInvalidData, value)
+		}
+	}
+	return strings.ToUpper(value), nil
+}
+
+// CheckCursor reports whether After, when set, can be used with the other
+// options
+func (o QueryOptions) CheckCursor() error {
+	_, err := o.keysetSort()
+	return err
+}
+
+// keysetSort returns the sort of a list read with the options: by
+// swift_code, in the requested direction, when paging by cursor. It
+// rejects a cursor combined with an offset or another sort field.
+func (o QueryOptions) keysetSort() (Sort, error) {
+	if o.After == "" {
+		return o.windowSort(), nil
+	}
+	if o.Offset > 0 {
+		return Sort{}, fmt.Errorf("%w: a cursor cannot be combined with an offset", ErrInvalidData)
+	}
+	if o.Sort.Field != "" && o.Sort.Field != SortSwiftCode {
+		return Sort{}, fmt.Errorf("%w: a cursor pages by swiftCode and cannot be sorted by %s", ErrInvalidData, o.Sort.Field)
+	}
+	return Sort{Field: SortSwiftCode, Descending: o.Sort.Descending}, nil
+}
+
+// keysetCondition returns the comparison of swift_code against After for
+// sort; ">" when ascending
+func (s Sort) keysetCondition() string {
+	if s.Descending {
+		return "swift_code < ?"
+	}
+	return "swift_code > ?"
+}
+
+// windowSort returns Sort, ordered by swift_code when it has no field and
+// the options select a window: without ORDER BY, Trino may return the rows
+// of a table in a different order on every query, so pages would overlap
+// or skip rows
+func (o QueryOptions) windowSort() Sort {
+	if o.Sort.Field == "" && o.Paged() {
+		return Sort{Field: SortSwiftCode, Descending: o.Sort.Descending}
+	}
+	return o.Sort
+}
+
+// Cacheable reports whether results read with the options may come from
+// or go into a cache
+func (o QueryOptions) Cacheable() bool {
+	return o.Consistency != ConsistencyStrong && o.AsOf.IsZero()
+}
+
+// CacheKey identifies the options in cache keys. Consistency and AsOf are
+// left out because such reads are never cached.
+func (o QueryOptions) CacheKey() string {
+	return fmt.Sprintf("%s:%s:%d:%d:%q:%t:%q:%q", o.Sort, o.Type, o.Limit, o.Offset, o.After, o.OmitBranches, o.Address, o.Town)
+}
+
+// timeTravel returns the Iceberg FOR TIMESTAMP AS OF clause for AsOf. The
+// literal is formatted from a time.Time, so it is safe in the statement.
+func (o QueryOptions) timeTravel() string {
+	if o.AsOf.IsZero() {
+		return ""
+	}
+	return " FOR TIMESTAMP AS OF TIMESTAMP '" + o.AsOf.UTC().Format("2006-01-02 15:04:05.000") + " UTC'"
+}
+
+// ParseSort parses "field" or "field:asc|desc", e.g. "bankName:desc".
+// An empty spec leaves results in storage order, or orders them by
+// swiftCode when a window of them is read.
+func ParseSort(spec string) (Sort, error) {
+	if spec == "" {
+		return Sort{}, nil
+	}
+
+	name, direction, _ := strings.Cut(spec, ":")
+	var sort Sort
+	for field := range sortColumns {
+		if strings.EqualFold(string(field), name) {
+			sort.Field = field
+		}
+	}
+	if sort.Field == "" {
+		return Sort{}, fmt.Errorf("%w: unknown sort field %q", ErrInvalidData, name)
+	}
+
+	switch strings.ToLower(direction) {
+	case "", "asc":
+	case "desc":
+		sort.Descending = true
+	default:
+		return Sort{}, fmt.Errorf("%w: unknown sort direction %q", ErrInvalidData, direction)
+	}
+	return sort, nil
+}
+
+// String returns the sort in the form accepted by ParseSort
+func (s Sort) String() string {
+	if s.Field == "" {
+		return ""
+	}
+	if s.Descending {
+		return string(s.Field) + ":desc"
+	}
+	return string(s.Field) + ":asc"
+}
+
+// orderBy returns the ORDER BY clause for the sort, with swift_code as a
+// tie-breaker so paging through equal names stays stable
+func (s Sort) orderBy() string {
+	column, ok := sortColumns[s.Field]
+	if !ok {
+		return ""
+	}
+	direction := "ASC"
+	if s.Descending {
+		direction = "DESC"
+	}
+	clause := " ORDER BY " + column + " " + direction
+	if column != "swift_code" {
+		clause += ", swift_code " + direction
+	}
+	return clause
+}
+
+// compare orders two banks as orderBy orders rows, with swift_code as the
+// tie-breaker. Without a sort field banks are ordered by swift_code.
+func (s Sort) compare(a, b model.SwiftBank) int {
+	var c int
+	switch s.Field {
+	case SortBankName:
+		c = strings.Compare(a.BankName, b.BankName)
+	case SortCountryName:
+		c = strings.Compare(a.CountryName, b.CountryName)
+	}
+	if c == 0 {
+		c = strings.Compare(a.SwiftCode, b.SwiftCode)
+	}
+	if s.Descending {
+		return -c
+	}
+	return c
+}
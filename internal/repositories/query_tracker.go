@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// InflightQuery describes a repository operation that is still running
+type InflightQuery struct {
+	ID          uint64    `json:"id"`
+	Operation   string    `json:"operation"`
+	Fingerprint string    `json:"fingerprint"`
+	StartedAt   time.Time `json:"started_at"`
+	DurationMs  int64     `json:"duration_ms"`
+}
+
+// QueryTracker records in-flight repository operations so operators can see
+// what the service is waiting on. A nil tracker is valid and tracks nothing.
+type QueryTracker struct {
+	mu       sync.Mutex
+	nextID   uint64
+	inflight map[uint64]InflightQuery
+}
+
+// NewQueryTracker creates an empty tracker
+func NewQueryTracker() *QueryTracker {
+	return &QueryTracker{inflight: make(map[uint64]InflightQuery)}
+}
+
+// Begin registers an operation and returns a function that must be called
+// when it finishes
+func (t *QueryTracker) Begin(operation, query string) func() {
+	if t == nil {
+		return func() {}
+	}
+
+	t.mu.Lock()
+	t.nextID++
+	id := t.nextID
+	t.inflight[id] = InflightQuery{
+		ID:          id,
+		Operation:   operation,
+		Fingerprint: Fingerprint(query),
+		StartedAt:   time.Now(),
+	}
+	t.mu.Unlock()
+
+	return func() {
+		t.mu.Lock()
+		delete(t.inflight, id)
+		t.mu.Unlock()
+	}
+}
+
+// Snapshot returns the in-flight operations, longest-running first
+func (t *QueryTracker) Snapshot() []InflightQuery {
+	if t == nil {
+		return []InflightQuery{}
+	}
+
+	now := time.Now()
+	t.mu.Lock()
+	queries := make([]InflightQuery, 0, len(t.inflight))
+	for _, q := range t.inflight {
+		q.DurationMs = now.Sub(q.StartedAt).Milliseconds()
+		queries = append(queries, q)
+	}
+	t.mu.Unlock()
+
+	sort.Slice(queries, func(i, j int) bool {
+		return queries[i].StartedAt.Before(queries[j].StartedAt)
+	})
+	return queries
+}
+
+var (
+	whitespaceRegex  = regexp.MustCompile(`\s+`)
+	valueGroupsRegex = regexp.MustCompile(`(\(\?(?:, \?)*\))(?:,\s*\(\?(?:, \?)*\))+`)
+)
+
+// Fingerprint normalizes a query so that statements differing only in
+// whitespace or batch size share the same fingerprint
+func Fingerprint(query string) string {
+	query = strings.TrimSpace(whitespaceRegex.ReplaceAllString(query, " "))
+	return valueGroupsRegex.ReplaceAllString(query, "$1, ...")
+}
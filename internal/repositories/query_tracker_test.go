@@ -0,0 +1,64 @@
+package repository_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/database"
+	repo "github.com/zdziszkee/swift-codes/internal/repositories"
+)
+
+var _ = Describe("QueryTracker", func() {
+	It("should list operations until they finish", func() {
+		tracker := repo.NewQueryTracker()
+		done := tracker.Begin("GetByCode", "SELECT  *\n FROM t WHERE swift_code = ?")
+
+		queries := tracker.Snapshot()
+		Expect(queries).To(HaveLen(1))
+		Expect(queries[0].Operation).To(Equal("GetByCode"))
+		Expect(queries[0].Fingerprint).To(Equal("SELECT * FROM t WHERE swift_code = ?"))
+
+		done()
+		Expect(tracker.Snapshot()).To(BeEmpty())
+	})
+
+	It("should be safe to use when nil", func() {
+		var tracker *repo.QueryTracker
+		tracker.Begin("Delete", "DELETE FROM t")()
+		Expect(tracker.Snapshot()).To(BeEmpty())
+	})
+
+	It("should collapse batch insert value groups", func() {
+		Expect(repo.Fingerprint("INSERT INTO t (a, b) VALUES (?, ?),(?, ?),(?, ?)")).
+			To(Equal("INSERT INTO t (a, b) VALUES (?, ?), ..."))
+	})
+
+	It("should track statements issued by the SQL repository", func() {
+		mockDB, mock, err := sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+		defer mockDB.Close()
+
+		tracker := repo.NewQueryTracker()
+		repository := repo.NewSQLSwiftRepository(&database.Database{DB: mockDB}, database.Config{
+			Catalog:   "swift_catalog",
+			Schema:    "default_schema",
+			TableName: "swift_banks",
+		}, repo.WithQueryTracker(tracker))
+
+		mock.ExpectQuery(`SELECT country_name FROM swift_catalog.default_schema.swift_banks`).
+			WillDelayFor(200 * time.Millisecond).
+			WillReturnRows(sqlmock.NewRows([]string{"country_name"}))
+
+		go func() {
+			defer GinkgoRecover()
+			_, _ = repository.GetByCountry(context.Background(), "PL", repo.QueryOptions{})
+		}()
+
+		Eventually(tracker.Snapshot).Should(ContainElement(HaveField("Operation", "GetByCountry")))
+		Eventually(tracker.Snapshot, time.Second).Should(BeEmpty())
+	})
+})
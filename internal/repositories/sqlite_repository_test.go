@@ -0,0 +1,242 @@
+package repository_test
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/database"
+	"github.com/zdziszkee/swift-codes/internal/models"
+	repo "github.com/zdziszkee/swift-codes/internal/repositories"
+)
+
+var _ = Describe("SQLSwiftRepository on SQLite", func() {
+	var (
+		ctx        context.Context
+		db         *database.Database
+		cfg        database.Config
+		repository repo.SwiftRepository
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cfg = database.Config{
+			Driver:    database.DriverSQLite,
+			DSN:       filepath.Join(GinkgoT().TempDir(), "swift.db"),
+			TableName: "swift_banks",
+		}
+		var err error
+		db, err = database.Open(cfg)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(db.DB.Close)
+		Expect(db.CreateTable(ctx, cfg.TableName)).To(Succeed())
+
+		repository = repo.NewSQLSwiftRepository(db, cfg)
+		Expect(repository.CreateBatch(ctx, []*models.SwiftBank{
+			{SwiftCode: "PKOPPLPWXXX", SwiftCodeBase: "PKOPPLPW", CountryISOCode: "PL", BankName: "PKO BP", IsHeadquarter: true, Address: "PULAWSKA 15", CountryName: "POLAND", Town: "WARSZAWA"},
+			{SwiftCode: "PKOPPLPWKRK", SwiftCodeBase: "PKOPPLPW", CountryISOCode: "PL", BankName: "PKO BP KRAKOW", Address: "RYNEK 1", CountryName: "POLAND", Town: "KRAKOW"},
+			{SwiftCode: "PKOPPLPWGDA", SwiftCodeBase: "PKOPPLPW", CountryISOCode: "PL", BankName: "PKO BP GDANSK", Address: "DLUGA 2", CountryName: "POLAND", Town: "GDANSK"},
+			{SwiftCode: "BREXPLPWXXX", SwiftCodeBase: "BREXPLPW", CountryISOCode: "PL", BankName: "MBANK", IsHeadquarter: true, CountryName: "POLAND"},
+		})).To(Succeed())
+	})
+
+	It("should return a headquarters with its branches", func() {
+		detail, err := repository.GetByCode(ctx, "PKOPPLPWXXX", repo.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(detail.Bank.BankName).To(Equal("PKO BP"))
+		Expect(detail.Bank.Town).To(Equal("WARSZAWA"))
+		Expect(detail.Branches).To(HaveLen(2))
+		Expect(detail.Branches).To(ContainElement(HaveField("Town", "KRAKOW")))
+
+		_, err = repository.GetByCode(ctx, "MISSPLPWXXX", repo.QueryOptions{})
+		Expect(err).To(MatchError(repo.ErrNotFound))
+	})
+
+	It("should store the town and time zone of a created code", func() {
+		Expect(repository.Create(ctx, &models.SwiftBank{
+			SwiftCode: "PKOPPLPWPOZ", CountryISOCode: "PL", BankName: "PKO BP POZNAN", CountryName: "POLAND", Town: "POZNAN", TimeZone: "Europe/Warsaw",
+		})).To(Succeed())
+
+		detail, err := repository.GetByCode(ctx, "PKOPPLPWPOZ", repo.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(detail.Bank.Town).To(Equal("POZNAN"))
+		Expect(detail.Bank.TimeZone).To(Equal("Europe/Warsaw"))
+	})
+
+	It("should read only the requested window of branches and count them all", func() {
+		detail, err := repository.GetByCode(ctx, "PKOPPLPWXXX", repo.QueryOptions{Sort: repo.Sort{Field: repo.SortSwiftCode}, Limit: 1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(detail.Branches).To(ConsistOf(HaveField("SwiftCode", "PKOPPLPWGDA")))
+		Expect(detail.BranchesTotal).To(Equal(2))
+
+		detail, err = repository.GetByCode(ctx, "PKOPPLPWXXX", repo.QueryOptions{Limit: 1, Offset: 5})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(detail.Branches).To(BeEmpty())
+		Expect(detail.BranchesTotal).To(Equal(2))
+
+		detail, err = repository.GetByCode(ctx, "PKOPPLPWXXX", repo.QueryOptions{Limit: 10})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(detail.Branches).To(HaveLen(2))
+		Expect(detail.BranchesTotal).To(Equal(2))
+	})
+
+	It("should filter and page a country with an offset and no limit", func() {
+		codes, err := repository.GetByCountry(ctx, "PL", repo.QueryOptions{Type: repo.BankTypeBranch, Offset: 1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(codes.Total).To(Equal(2))
+		Expect(codes.SwiftCodes).To(HaveLen(1))
+
+		codes, err = repository.GetByCountry(ctx, "PL", repo.QueryOptions{Town: "KRAK"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(codes.SwiftCodes).To(ConsistOf(And(HaveField("SwiftCode", "PKOPPLPWKRK"), HaveField("Town", "KRAKOW"))))
+	})
+
+	It("should page through a country without a sort, every code exactly once", func() {
+		var paged []string
+		for offset := 0; offset < 6; offset += 2 {
+			codes, err := repository.GetByCountry(ctx, "PL", repo.QueryOptions{Limit: 2, Offset: offset})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(codes.Total).To(Equal(4))
+			for _, bank := range codes.SwiftCodes {
+				paged = append(paged, bank.SwiftCode)
+			}
+		}
+		Expect(paged).To(Equal([]string{"BREXPLPWXXX", "PKOPPLPWGDA", "PKOPPLPWKRK", "PKOPPLPWXXX"}))
+	})
+
+	It("should skip or overwrite codes a batch loads again", func() {
+		reloaded := []*models.SwiftBank{
+			{SwiftCode: "PKOPPLPWXXX", CountryISOCode: "PL", BankName: "PKO BANK POLSKI", IsHeadquarter: true, CountryName: "POLAND"},
+			{SwiftCode: "INGBPLPWXXX", CountryISOCode: "PL", BankName: "ING", IsHeadquarter: true, CountryName: "POLAND"},
+		}
+		Expect(repository.CreateBatch(ctx, reloaded)).To(Succeed())
+
+		codes, err := repository.GetByCountry(ctx, "PL", repo.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(codes.Total).To(Equal(5))
+		detail, err := repository.GetByCode(ctx, "PKOPPLPWXXX", repo.QueryOptions{OmitBranches: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(detail.Bank.BankName).To(Equal("PKO BP"))
+
+		cfg.OnDuplicate = database.DuplicatesOverwrite
+		overwriting := repo.NewSQLSwiftRepository(db, cfg)
+		Expect(overwriting.CreateBatch(ctx, reloaded)).To(Succeed())
+
+		codes, err = repository.GetByCountry(ctx, "PL", repo.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(codes.Total).To(Equal(5))
+		detail, err = repository.GetByCode(ctx, "PKOPPLPWXXX", repo.QueryOptions{OmitBranches: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(detail.Bank.BankName).To(Equal("PKO BANK POLSKI"))
+	})
+
+	It("should store codes shorter than a code base as their own base", func() {
+		Expect(repository.CreateBatch(ctx, []*models.SwiftBank{{SwiftCode: "abcdpl", CountryISOCode: "PL", BankName: "LEGACY", CountryName: "POLAND"}})).To(Succeed())
+		_, err := repository.Upsert(ctx, []*models.SwiftBank{{SwiftCode: "EFGHPL", CountryISOCode: "PL", BankName: "LEGACY", CountryName: "POLAND"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(repository.Create(ctx, &models.SwiftBank{SwiftCode: "IJKLPL", CountryISOCode: "PL", BankName: "LEGACY", CountryName: "POLAND"})).To(Succeed())
+
+		detail, err := repository.GetByCode(ctx, "ABCDPL", repo.QueryOptions{OmitBranches: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(detail.Bank.SwiftCodeBase).To(Equal("ABCDPL"))
+	})
+
+	It("should update contacts without MERGE", func() {
+		updated, err := repository.UpdateContacts(ctx, []models.BankContact{
+			{SwiftCode: "PKOPPLPWXXX", Website: "https://pkobp.pl", Phone: "+48 800 302 302"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated).To(BeEquivalentTo(1))
+
+		detail, err := repository.GetByCode(ctx, "PKOPPLPWXXX", repo.QueryOptions{OmitBranches: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(detail.Bank.Website).To(Equal("https://pkobp.pl"))
+		Expect(detail.Bank.Phone).To(Equal("+48 800 302 302"))
+
+		all, err := repository.ListAll(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(all).To(ContainElement(And(HaveField("SwiftCode", "PKOPPLPWXXX"), HaveField("Website", "https://pkobp.pl"))))
+	})
+
+	It("should upsert without MERGE, rewriting only changed codes", func() {
+		_, err := repository.UpdateContacts(ctx, []models.BankContact{{SwiftCode: "PKOPPLPWXXX", Website: "https://pkobp.pl"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		changed, err := repository.Upsert(ctx, []*models.SwiftBank{
+			{SwiftCode: "PKOPPLPWXXX", CountryISOCode: "PL", BankName: "PKO BANK POLSKI", IsHeadquarter: true, Address: "PULAWSKA 15", CountryName: "POLAND", Town: "WARSZAWA"},
+			{SwiftCode: "PKOPPLPWKRK", CountryISOCode: "PL", BankName: "PKO BP KRAKOW", Address: "RYNEK 1", CountryName: "POLAND", Town: "KRAKOW"},
+			{SwiftCode: "INGBPLPWXXX", CountryISOCode: "PL", BankName: "ING", IsHeadquarter: true, CountryName: "POLAND"},
+			{SwiftCode: "ingbplpwxxx", CountryISOCode: "pl", BankName: "ING BANK SLASKI", IsHeadquarter: true, CountryName: "POLAND"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeEquivalentTo(2))
+
+		detail, err := repository.GetByCode(ctx, "PKOPPLPWXXX", repo.QueryOptions{OmitBranches: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(detail.Bank.BankName).To(Equal("PKO BANK POLSKI"))
+		Expect(detail.Bank.Website).To(Equal("https://pkobp.pl"))
+
+		codes, err := repository.GetByCountry(ctx, "PL", repo.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(codes.Total).To(Equal(5))
+		Expect(codes.SwiftCodes).To(ContainElement(HaveField("BankName", "ING BANK SLASKI")))
+	})
+
+	It("should delete codes and refuse time travel", func() {
+		Expect(repository.Delete(ctx, "PKOPPLPWGDA")).To(Succeed())
+		Expect(repository.Delete(ctx, "PKOPPLPWGDA")).To(MatchError(repo.ErrNotFound))
+
+		stats, err := repository.Stats(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats.TotalCodes).To(BeEquivalentTo(3))
+
+		_, err = repository.GetByCode(ctx, "PKOPPLPWXXX", repo.QueryOptions{AsOf: time.Now().Add(-time.Hour)})
+		Expect(err).To(MatchError(repo.ErrInvalidData))
+	})
+})
+
+var _ = Describe("SQLAliasRepository on SQLite", func() {
+	var (
+		ctx     context.Context
+		aliases *repo.SQLAliasRepository
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cfg := database.Config{Driver: database.DriverSQLite, DSN: filepath.Join(GinkgoT().TempDir(), "swift.db")}
+		db, err := database.Open(cfg)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(db.DB.Close)
+		Expect(db.CreateAliasTable(ctx, "swift_code_aliases")).To(Succeed())
+		aliases = repo.NewSQLAliasRepository(db, "swift_code_aliases")
+	})
+
+	It("should resolve and list the stored aliases", func() {
+		Expect(aliases.Add(ctx, "bpkopplpxxx", "PKOPPLPWXXX")).To(Succeed())
+		Expect(aliases.Add(ctx, "AAAAPLPWXXX", "PKOPPLPWXXX")).To(Succeed())
+
+		Expect(aliases.Resolve(ctx, "BPKOPPLPXXX")).To(Equal("PKOPPLPWXXX"))
+		Expect(aliases.List(ctx, "pkopplpwxxx")).To(Equal([]string{"AAAAPLPWXXX", "BPKOPPLPXXX"}))
+		_, err := aliases.Resolve(ctx, "BREXPLPWXXX")
+		Expect(err).To(MatchError(repo.ErrNotFound))
+	})
+
+	It("should refuse an alias stored already", func() {
+		Expect(aliases.Add(ctx, "BPKOPPLPXXX", "PKOPPLPWXXX")).To(Succeed())
+		Expect(aliases.Add(ctx, "BPKOPPLPXXX", "BREXPLPWXXX")).To(MatchError(repo.ErrDuplicate))
+		Expect(aliases.Resolve(ctx, "BPKOPPLPXXX")).To(Equal("PKOPPLPWXXX"))
+	})
+
+	It("should remove single aliases and every alias of a code", func() {
+		Expect(aliases.Add(ctx, "BPKOPPLPXXX", "PKOPPLPWXXX")).To(Succeed())
+		Expect(aliases.Add(ctx, "AAAAPLPWXXX", "PKOPPLPWXXX")).To(Succeed())
+
+		Expect(aliases.Remove(ctx, "AAAAPLPWXXX")).To(Succeed())
+		Expect(aliases.Remove(ctx, "AAAAPLPWXXX")).To(MatchError(repo.ErrNotFound))
+		Expect(aliases.RemoveCode(ctx, "PKOPPLPWXXX")).To(Succeed())
+		Expect(aliases.List(ctx, "PKOPPLPWXXX")).To(BeEmpty())
+	})
+})
@@ -5,62 +5,178 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/zdziszkee/swift-codes/internal/database"
 	model "github.com/zdziszkee/swift-codes/internal/models"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
 )
 
+// Repository errors are wrapped with the code or country they concern;
+// test for them with errors.Is
 var (
 	ErrNotFound    = errors.New("swift code not found")
 	ErrDuplicate   = errors.New("swift code already exists")
 	ErrInvalidData = errors.New("invalid data provided")
 )
 
+// errTimeTravel rejects AsOf reads on databases that keep no snapshots
+var errTimeTravel = fmt.Errorf("%w: reading as of a time needs the trino driver", ErrInvalidData)
+
 // SwiftBankDetail represents detailed bank information including branches
 type SwiftBankDetail struct {
-	Bank     model.SwiftBank   `json:"bank"`
-	Branches []model.SwiftBank `json:"branches,omitempty"`
+	Bank     model.SwiftBank   `json:"bank" xml:"bank"`
+	Branches []model.SwiftBank `json:"branches,omitempty" xml:"branches>branch,omitempty"`
+	// BranchesUnavailable is set when the headquarters was found but its
+	// branches could not be read; Branches is then empty
+	BranchesUnavailable bool `json:"branches_unavailable,omitempty" xml:"branches_unavailable,omitempty"`
+	// BranchesTotal counts every branch of a headquarters, including those
+	// outside the window of branches the options asked for
+	BranchesTotal int `json:"branches_total,omitempty" xml:"-"`
+	// CacheEntry numbers the repository cache entry the detail was served
+	// from, or is zero for details read from the database. Details with the
+	// same non-zero CacheEntry hold the same data.
+	CacheEntry uint64 `json:"-" xml:"-"`
 }
 
 // CountrySwiftCodes holds all SWIFT codes for a specific country
 type CountrySwiftCodes struct {
-	CountryISO2 string            `json:"country_iso2"`
-	CountryName string            `json:"country_name"`
-	SwiftCodes  []model.SwiftBank `json:"swift_codes"`
+	CountryISO2 string            `json:"country_iso2" xml:"country_iso2"`
+	CountryName string            `json:"country_name" xml:"country_name"`
+	SwiftCodes  []model.SwiftBank `json:"swift_codes" xml:"swift_codes>swift_code"`
+	// Total counts every matching code, including those outside the
+	// requested page
+	Total int `json:"-" xml:"-"`
+}
+
+// BankGroup holds every code sharing an 8-character SWIFT code base: the
+// headquarters, when one is stored, and its branches
+type BankGroup struct {
+	SwiftCodeBase string            `json:"swift_code_base" xml:"swift_code_base"`
+	Headquarters  *model.SwiftBank  `json:"headquarters" xml:"headquarters,omitempty"`
+	Branches      []model.SwiftBank `json:"branches" xml:"branches>branch"`
 }
 
 // SwiftRepository defines the interface for SWIFT code data operations
 type SwiftRepository interface {
-	GetByCode(ctx context.Context, code string) (*SwiftBankDetail, error)
-	GetByCountry(ctx context.Context, countryCode string) (*CountrySwiftCodes, error)
+	GetByCode(ctx context.Context, code string, opts QueryOptions) (*SwiftBankDetail, error)
+	GetByCountry(ctx context.Context, countryCode string, opts QueryOptions) (*CountrySwiftCodes, error)
 	Create(ctx context.Context, bank *model.SwiftBank) error
 	CreateBatch(ctx context.Context, banks []*model.SwiftBank) error
 	Delete(ctx context.Context, code string) error
-	GetBranchesByHQBase(ctx context.Context, hqBase string) ([]model.SwiftBank, error)
+	DeleteByCountry(ctx context.Context, countryCode string) (int64, error)
+	GetBranchesByHQBase(ctx context.Context, hqBase string, opts QueryOptions) ([]model.SwiftBank, error)
+	GetHeadquartersByBase(ctx context.Context, hqBase string, opts QueryOptions) (*model.SwiftBank, error)
+	GetByBase(ctx context.Context, base string, opts QueryOptions) (*BankGroup, error)
 	LoadCSV(ctx context.Context, csvPath string) error
+	Stats(ctx context.Context) (*DatasetStats, error)
+	Completeness(ctx context.Context) ([]CountryCompleteness, error)
+	CountryCounts(ctx context.Context) ([]CountryCount, error)
+	UpdateContacts(ctx context.Context, contacts []model.BankContact) (int64, error)
+	Upsert(ctx context.Context, banks []*model.SwiftBank) (int64, error)
+	ListAll(ctx context.Context) ([]model.SwiftBank, error)
+}
+
+// DatasetStats summarizes the contents of the SWIFT codes table
+type DatasetStats struct {
+	TotalCodes   int64 `json:"total_codes"`
+	Headquarters int64 `json:"headquarters"`
+	Branches     int64 `json:"branches"`
+	Countries    int64 `json:"countries"`
+}
+
+// Empty reports whether the table holds no SWIFT codes at all
+func (s *DatasetStats) Empty() bool {
+	return s.TotalCodes == 0
 }
 
-// SQLSwiftRepository implements SwiftRepository using Trino via database/sql
+// CountryCount totals the codes of one country
+type CountryCount struct {
+	CountryISO2  string `json:"country_iso2"`
+	CountryName  string `json:"country_name"`
+	TotalCodes   int64  `json:"total_codes"`
+	Headquarters int64  `json:"headquarters"`
+	Branches     int64  `json:"branches"`
+}
+
+// CountryCompleteness counts how many codes of one country carry each
+// optional field
+type CountryCompleteness struct {
+	CountryISO2  string
+	Codes        int64
+	WithAddress  int64
+	WithTown     int64
+	WithTimeZone int64
+	Branches     int64
+	// BranchesWithHQ counts branches whose headquarters is in the dataset
+	BranchesWithHQ int64
+}
+
+// SQLSwiftRepository implements SwiftRepository using Trino via database/sql,
+// or SQLite or Postgres through their dialect
 type SQLSwiftRepository struct {
-	db     *sql.DB
-	config database.Config
+	db       conn
+	config   database.Config
+	dialect  database.Dialect
+	tracker  *QueryTracker
+	queryLog *QueryLogConfig
+	// debug switches query logging on and off with the log level
+	debug func() bool
+	// partialBranches answers GetByCode without branches when only the
+	// branch query fails; degraded counts those answers when set
+	partialBranches bool
+	degraded        *Metrics
+}
+
+// Option configures optional SQLSwiftRepository behavior
+type Option func(*SQLSwiftRepository)
+
+// WithQueryTracker records every statement in the given tracker while it runs
+func WithQueryTracker(tracker *QueryTracker) Option {
+	return func(r *SQLSwiftRepository) {
+		r.tracker = tracker
+	}
+}
+
+// WithPartialBranches makes GetByCode return a headquarters with
+// BranchesUnavailable set instead of failing when its branch query fails.
+// Each degraded answer is logged and, when metrics is not nil, counted.
+func WithPartialBranches(metrics *Metrics) Option {
+	return func(r *SQLSwiftRepository) {
+		r.partialBranches = true
+		r.degraded = metrics
+	}
 }
 
 // NewSQLSwiftRepository creates a new repository instance with Trino
-func NewSQLSwiftRepository(db *database.Database, config database.Config) SwiftRepository {
-	return &SQLSwiftRepository{db: db.DB, config: config}
+func NewSQLSwiftRepository(db *database.Database, config database.Config, opts ...Option) SwiftRepository {
+	dialect := config.Dialect()
+	r := &SQLSwiftRepository{db: conn{db: db.DB, dialect: dialect}, config: config, dialect: dialect}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 const batchSize = 100
 
-// CreateBatch inserts multiple SWIFT banks in batches using parameterized queries
+// CreateBatch inserts multiple SWIFT banks in batches using parameterized
+// queries. Codes the table already holds are handled as the on_duplicate
+// setting asks: skipped by an anti-join against the table, or overwritten
+// through Upsert. Within banks the first row of a code is kept when
+// skipping and the last one when overwriting.
 func (r *SQLSwiftRepository) CreateBatch(ctx context.Context, banks []*model.SwiftBank) error {
 	if len(banks) == 0 {
 		return nil
 	}
+	if r.config.OnDuplicate == database.DuplicatesOverwrite {
+		_, err := r.Upsert(ctx, banks)
+		return err
+	}
 
+	banks = firstByCode(banks)
 	totalRows := len(banks)
 	insertedRows := 0
 
@@ -71,20 +187,12 @@ func (r *SQLSwiftRepository) CreateBatch(ctx context.Context, banks []*model.Swi
 		}
 		batch := banks[i:endIdx]
 
-		// Build parameterized INSERT query
-		var sb strings.Builder
-		sb.WriteString(fmt.Sprintf("INSERT INTO %s (swift_code, swift_code_base, country_iso_code, bank_name, is_headquarter, address, country_name) VALUES ", r.tableName()))
+		// Build parameterized INSERT ... SELECT skipping stored codes
 		placeholders := make([]string, 0, len(batch))
-		args := make([]interface{}, 0, len(batch)*7)
+		args := make([]interface{}, 0, len(batch)*len(database.UpsertColumns))
 
 		for _, bank := range batch {
-			bank.SwiftCode = strings.ToUpper(bank.SwiftCode)
-			bank.CountryISOCode = strings.ToUpper(bank.CountryISOCode)
-			if bank.SwiftCodeBase == "" {
-				bank.SwiftCodeBase = bank.SwiftCode[:8]
-			}
-
-			placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?, ?)")
+			placeholders = append(placeholders, upsertRow)
 			args = append(args,
 				bank.SwiftCode,
 				bank.SwiftCodeBase,
@@ -93,27 +201,121 @@ func (r *SQLSwiftRepository) CreateBatch(ctx context.Context, banks []*model.Swi
 				bank.IsHeadquarter,
 				bank.Address,
 				bank.CountryName,
+				bank.Town,
+				bank.TimeZone,
 			)
 		}
 
-		sb.WriteString(strings.Join(placeholders, ","))
-		query := sb.String()
+		query := r.dialect.InsertMissing(r.tableName(), strings.Join(placeholders, ","))
 
-		fmt.Printf("Executing Trino batch INSERT with %d rows: %s\n", len(batch), query[:min(200, len(query))])
 		start := time.Now()
+		done := r.begin(ctx, "CreateBatch", query, args...)
 		result, err := r.db.ExecContext(ctx, query, args...)
+		done()
 		if err != nil {
 			return fmt.Errorf("trino batch insert failed for batch %d-%d: %v (query: %s)", i+1, endIdx, err, query[:min(500, len(query))])
 		}
 		rowsAffected, _ := result.RowsAffected()
 		insertedRows += int(rowsAffected)
-		fmt.Printf("Completed Trino batch INSERT of %d rows in %v\n", len(batch), time.Since(start))
+		r.debugf(ctx, "Completed Trino batch INSERT of %d rows in %v", len(batch), time.Since(start))
 	}
 
-	fmt.Printf("Successfully loaded %d SWIFT codes\n", insertedRows)
+	requestid.Logf(ctx, "Successfully loaded %d SWIFT codes, skipped %d already stored", insertedRows, totalRows-insertedRows)
 	return nil
 }
 
+// upsertRow holds the placeholders of one bank in an upsert or a batch
+// insert. The flag is cast since Postgres types VALUES parameters as text.
+const upsertRow = "(?, ?, ?, ?, CAST(? AS BOOLEAN), ?, ?, ?, ?)"
+
+// Upsert stores banks with MERGE INTO, or its equivalent in the dialect:
+// stored codes are updated where a column changed, unchanged ones are left
+// alone and new ones are inserted, so a file can be imported again without
+// duplicating rows. When a code repeats in banks, its last row wins. It
+// returns the number of rows inserted or updated.
+func (r *SQLSwiftRepository) Upsert(ctx context.Context, banks []*model.SwiftBank) (int64, error) {
+	banks = lastByCode(banks)
+	var changed int64
+	for i := 0; i < len(banks); i += batchSize {
+		batch := banks[i:min(i+batchSize, len(banks))]
+
+		placeholders := make([]string, 0, len(batch))
+		args := make([]any, 0, len(batch)*len(database.UpsertColumns))
+		for _, bank := range batch {
+			placeholders = append(placeholders, upsertRow)
+			args = append(args,
+				bank.SwiftCode,
+				bank.SwiftCodeBase,
+				bank.CountryISOCode,
+				bank.BankName,
+				bank.IsHeadquarter,
+				bank.Address,
+				bank.CountryName,
+				bank.Town,
+				bank.TimeZone,
+			)
+		}
+
+		for _, query := range r.dialect.Upsert(r.tableName(), strings.Join(placeholders, ",")) {
+			done := r.begin(ctx, "Upsert", query, args...)
+			result, err := r.db.ExecContext(ctx, query, args...)
+			done()
+			if err != nil {
+				return changed, fmt.Errorf("trino upsert failed for batch %d-%d: %w", i+1, i+len(batch), err)
+			}
+			rows, err := result.RowsAffected()
+			if err != nil {
+				return changed, fmt.Errorf("trino upsert failed for batch %d-%d: %w", i+1, i+len(batch), err)
+			}
+			changed += rows
+		}
+	}
+	requestid.Logf(ctx, "Upserted %d SWIFT codes, %d of them new or changed", len(banks), changed)
+	return changed, nil
+}
+
+// lastByCode normalizes banks and keeps the last row of every code, in
+// the order the codes first appear, as MERGE fails when several source
+// rows match one target row
+func lastByCode(banks []*model.SwiftBank) []*model.SwiftBank {
+	position := make(map[string]int, len(banks))
+	unique := make([]*model.SwiftBank, 0, len(banks))
+	for _, bank := range banks {
+		bank.SwiftCode = strings.ToUpper(bank.SwiftCode)
+		bank.CountryISOCode = strings.ToUpper(bank.CountryISOCode)
+		if bank.SwiftCodeBase == "" {
+			bank.SwiftCodeBase = model.BIC8(bank.SwiftCode)
+		}
+		if i, ok := position[bank.SwiftCode]; ok {
+			unique[i] = bank
+			continue
+		}
+		position[bank.SwiftCode] = len(unique)
+		unique = append(unique, bank)
+	}
+	return unique
+}
+
+// firstByCode normalizes banks like lastByCode but keeps the first row of
+// every code, as an anti-join only sees codes stored before the statement
+func firstByCode(banks []*model.SwiftBank) []*model.SwiftBank {
+	seen := make(map[string]bool, len(banks))
+	unique := make([]*model.SwiftBank, 0, len(banks))
+	for _, bank := range banks {
+		bank.SwiftCode = strings.ToUpper(bank.SwiftCode)
+		bank.CountryISOCode = strings.ToUpper(bank.CountryISOCode)
+		if bank.SwiftCodeBase == "" {
+			bank.SwiftCodeBase = model.BIC8(bank.SwiftCode)
+		}
+		if seen[bank.SwiftCode] {
+			continue
+		}
+		seen[bank.SwiftCode] = true
+		unique = append(unique, bank)
+	}
+	return unique
+}
+
 // Create adds a single SWIFT bank to the database
 func (r *SQLSwiftRepository) Create(ctx context.Context, bank *model.SwiftBank) error {
 	if err := r.checkDuplicate(ctx, bank.SwiftCode); err != nil {
@@ -123,10 +325,21 @@ func (r *SQLSwiftRepository) Create(ctx context.Context, bank *model.SwiftBank)
 	bank.SwiftCode = strings.ToUpper(bank.SwiftCode)
 	bank.CountryISOCode = strings.ToUpper(bank.CountryISOCode)
 	if bank.SwiftCodeBase == "" {
-		bank.SwiftCodeBase = bank.SwiftCode[:8]
+		bank.SwiftCodeBase = model.BIC8(bank.SwiftCode)
 	}
 
-	query := fmt.Sprintf("INSERT INTO %s (swift_code, swift_code_base, country_iso_code, bank_name, is_headquarter, address, country_name) VALUES (?, ?, ?, ?, ?, ?, ?)", r.tableName())
+	query := fmt.Sprintf("INSERT INTO %s (swift_code, swift_code_base, country_iso_code, bank_name, is_headquarter, address, country_name, town_name, time_zone) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)", r.tableName())
+	defer r.begin(ctx, "Create", query,
+		bank.SwiftCode,
+		bank.SwiftCodeBase,
+		bank.CountryISOCode,
+		bank.BankName,
+		bank.IsHeadquarter,
+		bank.Address,
+		bank.CountryName,
+		bank.Town,
+		bank.TimeZone,
+	)()
 	_, err := r.db.ExecContext(ctx, query,
 		bank.SwiftCode,
 		bank.SwiftCodeBase,
@@ -135,6 +348,8 @@ func (r *SQLSwiftRepository) Create(ctx context.Context, bank *model.SwiftBank)
 		bank.IsHeadquarter,
 		bank.Address,
 		bank.CountryName,
+		bank.Town,
+		bank.TimeZone,
 	)
 	if err != nil {
 		return fmt.Errorf("trino insert failed: %w", err)
@@ -147,29 +362,64 @@ func (r *SQLSwiftRepository) LoadCSV(ctx context.Context, csvPath string) error
 	return fmt.Errorf("LoadCSV not implemented for Trino; use CreateBatch instead")
 }
 
-// GetByCode retrieves a SWIFT bank and its branches if it's a headquarters
-func (r *SQLSwiftRepository) GetByCode(ctx context.Context, code string) (*SwiftBankDetail, error) {
-	bank, err := r.getBankByCode(ctx, strings.ToUpper(code))
+// GetByCode retrieves a SWIFT bank and, unless opts omits them, its branches
+// if it's a headquarters
+func (r *SQLSwiftRepository) GetByCode(ctx context.Context, code string, opts QueryOptions) (*SwiftBankDetail, error) {
+	if err := r.checkTimeTravel(opts); err != nil {
+		return nil, err
+	}
+	bank, err := r.getBankByCode(ctx, strings.ToUpper(code), opts)
 	if err != nil {
 		return nil, err
 	}
 
 	result := &SwiftBankDetail{Bank: *bank}
 
-	if bank.IsHeadquarter {
-		branches, err := r.GetBranchesByHQBase(ctx, bank.SwiftCodeBase)
-		if err != nil {
+	if bank.IsHeadquarter && !opts.OmitBranches {
+		branches, err := r.GetBranchesByHQBase(ctx, bank.SwiftCodeBase, opts)
+		switch {
+		case err != nil && r.partialBranches && ctx.Err() == nil:
+			requestid.Logf(ctx, "WARNING: returning %s without branches: %v", bank.SwiftCode, err)
+			if r.degraded != nil {
+				r.degraded.recordDegraded(OpGetByCode)
+			}
+			result.BranchesUnavailable = true
+		case err != nil:
 			return nil, fmt.Errorf("trino fetch branches failed: %w", err)
+		default:
+			result.Branches = branches
+			result.BranchesTotal, err = r.branchesTotal(ctx, bank.SwiftCodeBase, opts, len(branches))
+			if err != nil {
+				return nil, fmt.Errorf("trino count branches failed: %w", err)
+			}
 		}
-		result.Branches = branches
 	}
 
 	return result, nil
 }
 
-// GetBranchesByHQBase retrieves all branches for a headquarters
-func (r *SQLSwiftRepository) GetBranchesByHQBase(ctx context.Context, hqBase string) ([]model.SwiftBank, error) {
-	query := fmt.Sprintf("SELECT swift_code, swift_code_base, country_iso_code, bank_name, is_headquarter, address, country_name FROM %s WHERE swift_code_base = ? AND is_headquarter = false", r.tableName())
+// branchesTotal counts the branches of hqBase after read of them were
+// returned for opts, querying only when the window may have left some out
+func (r *SQLSwiftRepository) branchesTotal(ctx context.Context, hqBase string, opts QueryOptions, read int) (int, error) {
+	if (read > 0 || opts.Offset == 0) && (opts.Limit == 0 || read < opts.Limit) {
+		return opts.Offset + read, nil
+	}
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s%s WHERE swift_code_base = ? AND is_headquarter = false", r.tableName(), opts.timeTravel())
+	defer r.begin(ctx, "GetBranchesByHQBase", query, hqBase)()
+	var total int
+	err := r.db.QueryRowContext(ctx, query, hqBase).Scan(&total)
+	return total, err
+}
+
+// GetBranchesByHQBase retrieves the branches of a headquarters, ordered and
+// windowed as opts asks
+func (r *SQLSwiftRepository) GetBranchesByHQBase(ctx context.Context, hqBase string, opts QueryOptions) ([]model.SwiftBank, error) {
+	if err := r.checkTimeTravel(opts); err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf("SELECT "+bankColumns+" FROM %s%s WHERE swift_code_base = ? AND is_headquarter = false", r.tableName(), opts.timeTravel()) +
+		opts.windowSort().orderBy() + r.dialect.Window(opts.Limit, opts.Offset)
+	defer r.begin(ctx, "GetBranchesByHQBase", query, hqBase)()
 	rows, err := r.db.QueryContext(ctx, query, hqBase)
 	if err != nil {
 		return nil, fmt.Errorf("trino query failed: %w", err)
@@ -188,16 +438,103 @@ func (r *SQLSwiftRepository) GetBranchesByHQBase(ctx context.Context, hqBase str
 	return branches, rows.Err()
 }
 
-// GetByCountry retrieves all SWIFT banks for a country
-func (r *SQLSwiftRepository) GetByCountry(ctx context.Context, countryCode string) (*CountrySwiftCodes, error) {
+// GetHeadquartersByBase retrieves the headquarters sharing the first eight
+// characters hqBase
+func (r *SQLSwiftRepository) GetHeadquartersByBase(ctx context.Context, hqBase string, opts QueryOptions) (*model.SwiftBank, error) {
+	if err := r.checkTimeTravel(opts); err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf("SELECT "+bankColumns+" FROM %s%s WHERE swift_code_base = ? AND is_headquarter = true LIMIT 1", r.tableName(), opts.timeTravel())
+	defer r.begin(ctx, "GetHeadquartersByBase", query, hqBase)()
+	bank, err := scanBank(r.db.QueryRowContext(ctx, query, hqBase))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: headquarters of %s", ErrNotFound, hqBase)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("trino query failed: %w", err)
+	}
+	return bank, nil
+}
+
+// GetByBase retrieves every code whose first eight characters are base in
+// one query, so a group is read the same way whichever of its codes the
+// caller knows
+func (r *SQLSwiftRepository) GetByBase(ctx context.Context, base string, opts QueryOptions) (*BankGroup, error) {
+	if err := r.checkTimeTravel(opts); err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf("SELECT "+bankColumns+" FROM %s%s WHERE swift_code_base = ? ORDER BY is_headquarter DESC, swift_code", r.tableName(), opts.timeTravel())
+	defer r.begin(ctx, "GetByBase", query, base)()
+	rows, err := r.db.QueryContext(ctx, query, base)
+	if err != nil {
+		return nil, fmt.Errorf("trino query failed: %w", err)
+	}
+	defer rows.Close()
+
+	group := &BankGroup{SwiftCodeBase: base, Branches: []model.SwiftBank{}}
+	found := false
+	for rows.Next() {
+		bank, err := scanBank(rows)
+		if err != nil {
+			return nil, fmt.Errorf("trino scan failed: %w", err)
+		}
+		found = true
+		if bank.IsHeadquarter && group.Headquarters == nil {
+			group.Headquarters = bank
+			continue
+		}
+		group.Branches = append(group.Branches, *bank)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("trino scan failed: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: swift code base %s", ErrNotFound, base)
+	}
+	return group, nil
+}
+
+// GetByCountry retrieves all SWIFT banks for a country, ordered as opts asks
+func (r *SQLSwiftRepository) GetByCountry(ctx context.Context, countryCode string, opts QueryOptions) (*CountrySwiftCodes, error) {
+	if err := r.checkTimeTravel(opts); err != nil {
+		return nil, err
+	}
+	sort, err := opts.keysetSort()
+	if err != nil {
+		return nil, err
+	}
 	countryCode = strings.ToUpper(countryCode)
-	countryName, err := r.getCountryName(ctx, countryCode)
+	countryName, err := r.getCountryName(ctx, countryCode, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	query := fmt.Sprintf("SELECT swift_code, swift_code_base, country_iso_code, bank_name, is_headquarter, address, country_name FROM %s WHERE country_iso_code = ?", r.tableName())
-	rows, err := r.db.QueryContext(ctx, query, countryCode)
+	filter := "WHERE country_iso_code = ?"
+	args := []any{countryCode}
+	if opts.Type != BankTypeAny {
+		filter += " AND is_headquarter = ?"
+		args = append(args, opts.Type == BankTypeHeadquarter)
+	}
+	if opts.Address != "" {
+		filter += " AND " + r.dialect.Position("upper(address)", "?") + " > 0"
+		args = append(args, strings.ToUpper(opts.Address))
+	}
+	if opts.Town != "" {
+		filter += " AND " + r.dialect.Position("upper(town_name)", "?") + " > 0"
+		args = append(args, strings.ToUpper(opts.Town))
+	}
+
+	// The count covers every matching row, not just those after the cursor
+	pageFilter, pageArgs := filter, args
+	if opts.After != "" {
+		pageFilter += " AND " + sort.keysetCondition()
+		pageArgs = append(slices.Clip(args), opts.After)
+	}
+
+	query := fmt.Sprintf("SELECT "+bankColumns+" FROM %s%s %s", r.tableName(), opts.timeTravel(), pageFilter) +
+		sort.orderBy() + r.dialect.Window(opts.Limit, opts.Offset)
+	defer r.begin(ctx, "GetByCountry", query, pageArgs...)()
+	rows, err := r.db.QueryContext(ctx, query, pageArgs...)
 	if err != nil {
 		return nil, fmt.Errorf("trino query failed: %w", err)
 	}
@@ -215,8 +552,42 @@ func (r *SQLSwiftRepository) GetByCountry(ctx context.Context, countryCode strin
 		}
 		result.SwiftCodes = append(result.SwiftCodes, *bank)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result.Total = len(result.SwiftCodes)
+	if opts.Paged() {
+		countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s%s %s", r.tableName(), opts.timeTravel(), filter)
+		defer r.begin(ctx, "GetByCountry", countQuery, args...)()
+		if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&result.Total); err != nil {
+			return nil, fmt.Errorf("trino count query failed: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// ListAll retrieves every SWIFT bank ordered by code, for full exports
+func (r *SQLSwiftRepository) ListAll(ctx context.Context) ([]model.SwiftBank, error) {
+	query := fmt.Sprintf("SELECT "+bankColumns+" FROM %s ORDER BY swift_code", r.tableName())
+	defer r.begin(ctx, "ListAll", query)()
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("trino query failed: %w", err)
+	}
+	defer rows.Close()
+
+	banks := []model.SwiftBank{}
+	for rows.Next() {
+		bank, err := scanBank(rows)
+		if err != nil {
+			return nil, fmt.Errorf("trino scan failed: %w", err)
+		}
+		banks = append(banks, *bank)
+	}
 
-	return result, rows.Err()
+	return banks, rows.Err()
 }
 
 // Delete removes a SWIFT bank from the database
@@ -227,6 +598,7 @@ func (r *SQLSwiftRepository) Delete(ctx context.Context, code string) error {
 	}
 
 	query := fmt.Sprintf("DELETE FROM %s WHERE swift_code = ?", r.tableName())
+	defer r.begin(ctx, "Delete", query, code)()
 	_, err := r.db.ExecContext(ctx, query, code)
 	if err != nil {
 		return fmt.Errorf("trino delete failed: %w", err)
@@ -235,18 +607,152 @@ func (r *SQLSwiftRepository) Delete(ctx context.Context, code string) error {
 	return nil
 }
 
+// DeleteByCountry removes every SWIFT bank of a country in one statement
+// and returns the number of deleted rows
+func (r *SQLSwiftRepository) DeleteByCountry(ctx context.Context, countryCode string) (int64, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE country_iso_code = ?", r.tableName())
+	countryCode = strings.ToUpper(countryCode)
+	defer r.begin(ctx, "DeleteByCountry", query, countryCode)()
+	result, err := r.db.ExecContext(ctx, query, countryCode)
+	if err != nil {
+		return 0, fmt.Errorf("trino delete by country failed: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("trino delete by country failed: %w", err)
+	}
+	return deleted, nil
+}
+
+// UpdateContacts sets the website and phone of existing SWIFT codes with
+// one MERGE, or the dialect's equivalent, per batch and returns the number
+// of codes updated. Contacts for unknown codes are ignored.
+func (r *SQLSwiftRepository) UpdateContacts(ctx context.Context, contacts []model.BankContact) (int64, error) {
+	var updated int64
+	for i := 0; i < len(contacts); i += batchSize {
+		batch := contacts[i:min(i+batchSize, len(contacts))]
+
+		placeholders := make([]string, 0, len(batch))
+		args := make([]any, 0, len(batch)*3)
+		for _, contact := range batch {
+			placeholders = append(placeholders, "(?, ?, ?)")
+			args = append(args, strings.ToUpper(contact.SwiftCode), contact.Website, contact.Phone)
+		}
+
+		query := r.dialect.UpdateContacts(r.tableName(), strings.Join(placeholders, ","))
+		done := r.begin(ctx, "UpdateContacts", query, args...)
+		result, err := r.db.ExecContext(ctx, query, args...)
+		done()
+		if err != nil {
+			return updated, fmt.Errorf("trino contact update failed: %w", err)
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return updated, fmt.Errorf("trino contact update failed: %w", err)
+		}
+		updated += rows
+	}
+	return updated, nil
+}
+
+// Stats counts codes, headquarters and countries in a single scan. It works
+// on an empty table, where every count is zero.
+func (r *SQLSwiftRepository) Stats(ctx context.Context) (*DatasetStats, error) {
+	query := fmt.Sprintf("SELECT COUNT(*), COALESCE(SUM(CASE WHEN is_headquarter THEN 1 ELSE 0 END), 0), COUNT(DISTINCT country_iso_code) FROM %s", r.tableName())
+	defer r.begin(ctx, "Stats", query)()
+
+	var stats DatasetStats
+	err := r.db.QueryRowContext(ctx, query).Scan(&stats.TotalCodes, &stats.Headquarters, &stats.Countries)
+	if err != nil {
+		return nil, fmt.Errorf("trino stats query failed: %w", err)
+	}
+	stats.Branches = stats.TotalCodes - stats.Headquarters
+	return &stats, nil
+}
+
+// CountryCounts totals codes, headquarters and branches per country in a
+// single GROUP BY, ordered by country
+func (r *SQLSwiftRepository) CountryCounts(ctx context.Context) ([]CountryCount, error) {
+	query := fmt.Sprintf("SELECT country_iso_code, COALESCE(MAX(country_name), ''), COUNT(*), "+
+		"SUM(CASE WHEN is_headquarter THEN 1 ELSE 0 END) FROM %s GROUP BY country_iso_code ORDER BY country_iso_code", r.tableName())
+	defer r.begin(ctx, "CountryCounts", query)()
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("trino country counts query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var countries []CountryCount
+	for rows.Next() {
+		var c CountryCount
+		if err := rows.Scan(&c.CountryISO2, &c.CountryName, &c.TotalCodes, &c.Headquarters); err != nil {
+			return nil, fmt.Errorf("trino country counts query failed: %w", err)
+		}
+		c.Branches = c.TotalCodes - c.Headquarters
+		countries = append(countries, c)
+	}
+	return countries, rows.Err()
+}
+
+// Completeness counts filled optional fields and headquarters coverage per
+// country in a single scan, ordered by country. Blank values count as
+// missing.
+func (r *SQLSwiftRepository) Completeness(ctx context.Context) ([]CountryCompleteness, error) {
+	filled := func(column string) string {
+		return fmt.Sprintf("SUM(CASE WHEN trim(COALESCE(b.%s, '')) <> '' THEN 1 ELSE 0 END)", column)
+	}
+	query := fmt.Sprintf("SELECT b.country_iso_code, COUNT(*), %s, %s, %s, "+
+		"SUM(CASE WHEN b.is_headquarter THEN 0 ELSE 1 END), "+
+		"SUM(CASE WHEN NOT b.is_headquarter AND h.swift_code_base IS NOT NULL THEN 1 ELSE 0 END) "+
+		"FROM %s b LEFT JOIN (SELECT DISTINCT swift_code_base FROM %s WHERE is_headquarter) h ON b.swift_code_base = h.swift_code_base "+
+		"GROUP BY b.country_iso_code ORDER BY b.country_iso_code",
+		filled("address"), filled("town_name"), filled("time_zone"), r.tableName(), r.tableName())
+	defer r.begin(ctx, "Completeness", query)()
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("trino completeness query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var countries []CountryCompleteness
+	for rows.Next() {
+		var c CountryCompleteness
+		if err := rows.Scan(&c.CountryISO2, &c.Codes, &c.WithAddress, &c.WithTown, &c.WithTimeZone, &c.Branches, &c.BranchesWithHQ); err != nil {
+			return nil, fmt.Errorf("trino completeness query failed: %w", err)
+		}
+		countries = append(countries, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("trino completeness query failed: %w", err)
+	}
+	return countries, nil
+}
+
 // Helper methods
 
 func (r *SQLSwiftRepository) tableName() string {
-	return fmt.Sprintf("%s.%s.%s", r.config.Catalog, r.config.Schema, r.config.TableName)
+	return r.dialect.Table(r.config, r.config.TableName)
+}
+
+// checkTimeTravel rejects reads as of a past time on databases that keep no
+// snapshots
+func (r *SQLSwiftRepository) checkTimeTravel(opts QueryOptions) error {
+	if !opts.AsOf.IsZero() && !r.dialect.TimeTravel() {
+		return errTimeTravel
+	}
+	return nil
 }
 
-func (r *SQLSwiftRepository) getBankByCode(ctx context.Context, code string) (*model.SwiftBank, error) {
-	query := fmt.Sprintf("SELECT swift_code, swift_code_base, country_iso_code, bank_name, is_headquarter, address, country_name FROM %s WHERE swift_code = ?", r.tableName())
+func (r *SQLSwiftRepository) getBankByCode(ctx context.Context, code string, opts QueryOptions) (*model.SwiftBank, error) {
+	query := fmt.Sprintf("SELECT "+bankColumns+" FROM %s%s WHERE swift_code = ?", r.tableName(), opts.timeTravel())
+	defer r.begin(ctx, "GetByCode", query, code)()
 	row := r.db.QueryRowContext(ctx, query, code)
 	bank, err := scanBank(row)
-	if err == sql.ErrNoRows {
-		return nil, ErrNotFound
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, code)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("trino query failed: %w", err)
@@ -254,12 +760,13 @@ func (r *SQLSwiftRepository) getBankByCode(ctx context.Context, code string) (*m
 	return bank, nil
 }
 
-func (r *SQLSwiftRepository) getCountryName(ctx context.Context, countryCode string) (string, error) {
-	query := fmt.Sprintf("SELECT country_name FROM %s WHERE country_iso_code = ? LIMIT 1", r.tableName())
+func (r *SQLSwiftRepository) getCountryName(ctx context.Context, countryCode string, opts QueryOptions) (string, error) {
+	query := fmt.Sprintf("SELECT country_name FROM %s%s WHERE country_iso_code = ? LIMIT 1", r.tableName(), opts.timeTravel())
+	defer r.begin(ctx, "GetByCountry", query, countryCode)()
 	var countryName string
 	err := r.db.QueryRowContext(ctx, query, countryCode).Scan(&countryName)
-	if err == sql.ErrNoRows {
-		return "", ErrNotFound
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%w: country %s", ErrNotFound, countryCode)
 	}
 	if err != nil {
 		return "", fmt.Errorf("trino query failed: %w", err)
@@ -269,12 +776,14 @@ func (r *SQLSwiftRepository) getCountryName(ctx context.Context, countryCode str
 
 func (r *SQLSwiftRepository) checkDuplicate(ctx context.Context, code string) error {
 	query := fmt.Sprintf("SELECT 1 FROM %s WHERE swift_code = ? LIMIT 1", r.tableName())
+	code = strings.ToUpper(code)
+	defer r.begin(ctx, "Create", query, code)()
 	var exists int
-	err := r.db.QueryRowContext(ctx, query, strings.ToUpper(code)).Scan(&exists)
+	err := r.db.QueryRowContext(ctx, query, code).Scan(&exists)
 	if err == nil {
-		return ErrDuplicate
+		return fmt.Errorf("%w: %s", ErrDuplicate, code)
 	}
-	if err != sql.ErrNoRows {
+	if !errors.Is(err, sql.ErrNoRows) {
 		return fmt.Errorf("trino check duplicate failed: %w", err)
 	}
 	return nil
@@ -282,10 +791,11 @@ func (r *SQLSwiftRepository) checkDuplicate(ctx context.Context, code string) er
 
 func (r *SQLSwiftRepository) checkExists(ctx context.Context, code string) error {
 	query := fmt.Sprintf("SELECT 1 FROM %s WHERE swift_code = ? LIMIT 1", r.tableName())
+	defer r.begin(ctx, "Delete", query, code)()
 	var exists int
 	err := r.db.QueryRowContext(ctx, query, code).Scan(&exists)
-	if err == sql.ErrNoRows {
-		return ErrNotFound
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %s", ErrNotFound, code)
 	}
 	if err != nil {
 		return fmt.Errorf("trino check exists failed: %w", err)
@@ -293,12 +803,47 @@ func (r *SQLSwiftRepository) checkExists(ctx context.Context, code string) error
 	return nil
 }
 
-func scanBank(scanner interface {
+// conn runs statements written with ? placeholders on db, rebound for the
+// dialect
+type conn struct {
+	db      *sql.DB
+	dialect database.Dialect
+}
+
+func (c conn) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return c.db.ExecContext(ctx, c.dialect.Rebind(query), args...)
+}
+
+func (c conn) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return c.db.QueryContext(ctx, c.dialect.Rebind(query), args...)
+}
+
+func (c conn) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return c.db.QueryRowContext(ctx, c.dialect.Rebind(query), args...)
+}
+
+type rowScanner interface {
 	Scan(dest ...any) error
-}) (*model.SwiftBank, error) {
+}
+
+func scanBank(scanner rowScanner) (*model.SwiftBank, error) {
 	var bank model.SwiftBank
 
-	err := scanner.Scan(
+	err := scanner.Scan(bankFields(&bank)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bank, nil
+}
+
+// bankColumns are the columns every read selects, in the order of bankFields
+const bankColumns = "swift_code, swift_code_base, country_iso_code, bank_name, is_headquarter, address, country_name, " +
+	"COALESCE(town_name, ''), COALESCE(time_zone, ''), COALESCE(website, ''), COALESCE(phone, '')"
+
+// bankFields returns the scan destinations of bankColumns
+func bankFields(bank *model.SwiftBank) []any {
+	return []any{
 		&bank.SwiftCode,
 		&bank.SwiftCodeBase,
 		&bank.CountryISOCode,
@@ -306,12 +851,11 @@ func scanBank(scanner interface {
 		&bank.IsHeadquarter,
 		&bank.Address,
 		&bank.CountryName,
-	)
-	if err != nil {
-		return nil, err
+		&bank.Town,
+		&bank.TimeZone,
+		&bank.Website,
+		&bank.Phone,
 	}
-
-	return &bank, nil
 }
 
 func Min(a, b int) int {
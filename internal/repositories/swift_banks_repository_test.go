@@ -1,12 +1,16 @@
 package repository_test
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"database/sql/driver"
 	"errors"
 	"fmt"
+	"log"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	. "github.com/onsi/ginkgo/v2"
@@ -66,6 +70,8 @@ var _ = Describe("SQLSwiftRepository", func() {
 				IsHeadquarter:  false,
 				Address:        "456 Branch St",
 				CountryName:    "United States",
+				Town:           "Springfield",
+				TimeZone:       "America/Chicago",
 			},
 		}
 	})
@@ -84,8 +90,8 @@ var _ = Describe("SQLSwiftRepository", func() {
 					WillReturnError(sql.ErrNoRows)
 
 				// Insert new record
-				mock.ExpectExec(`INSERT INTO `+tableName+` \(swift_code, swift_code_base, country_iso_code, bank_name, is_headquarter, address, country_name\) VALUES \(\?, \?, \?, \?, \?, \?, \?\)`).
-					WithArgs("TESTCODE123", "TESTCODE", "US", "Test Bank", true, "123 Test St", "United States").
+				mock.ExpectExec(`INSERT INTO `+tableName+` \(swift_code, swift_code_base, country_iso_code, bank_name, is_headquarter, address, country_name, town_name, time_zone\) VALUES \(\?, \?, \?, \?, \?, \?, \?, \?, \?\)`).
+					WithArgs("TESTCODE123", "TESTCODE", "US", "Test Bank", true, "123 Test St", "United States", "", "").
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
 				err := repository.Create(ctx, sampleBank)
@@ -98,7 +104,7 @@ var _ = Describe("SQLSwiftRepository", func() {
 					WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
 
 				err := repository.Create(ctx, sampleBank)
-				Expect(err).To(Equal(repo.ErrDuplicate))
+				Expect(err).To(MatchError(repo.ErrDuplicate))
 			})
 
 			It("should handle database errors during existence check", func() {
@@ -116,8 +122,8 @@ var _ = Describe("SQLSwiftRepository", func() {
 					WithArgs("TESTCODE123").
 					WillReturnError(sql.ErrNoRows)
 
-				mock.ExpectExec(`INSERT INTO `+tableName+` \(swift_code, swift_code_base, country_iso_code, bank_name, is_headquarter, address, country_name\) VALUES \(\?, \?, \?, \?, \?, \?, \?\)`).
-					WithArgs("TESTCODE123", "TESTCODE", "US", "Test Bank", true, "123 Test St", "United States").
+				mock.ExpectExec(`INSERT INTO `+tableName+` \(swift_code, swift_code_base, country_iso_code, bank_name, is_headquarter, address, country_name, town_name, time_zone\) VALUES \(\?, \?, \?, \?, \?, \?, \?, \?, \?\)`).
+					WithArgs("TESTCODE123", "TESTCODE", "US", "Test Bank", true, "123 Test St", "United States", "", "").
 					WillReturnError(errors.New("insert error"))
 
 				err := repository.Create(ctx, sampleBank)
@@ -139,8 +145,8 @@ var _ = Describe("SQLSwiftRepository", func() {
 					WithArgs("TESTCODE123").
 					WillReturnError(sql.ErrNoRows)
 
-				mock.ExpectExec(`INSERT INTO `+tableName+` \(swift_code, swift_code_base, country_iso_code, bank_name, is_headquarter, address, country_name\) VALUES \(\?, \?, \?, \?, \?, \?, \?\)`).
-					WithArgs("TESTCODE123", "TESTCODE", "US", "Test Bank", true, "123 Test St", "United States").
+				mock.ExpectExec(`INSERT INTO `+tableName+` \(swift_code, swift_code_base, country_iso_code, bank_name, is_headquarter, address, country_name, town_name, time_zone\) VALUES \(\?, \?, \?, \?, \?, \?, \?, \?, \?\)`).
+					WithArgs("TESTCODE123", "TESTCODE", "US", "Test Bank", true, "123 Test St", "United States", "", "").
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
 				err := repository.Create(ctx, bankWithoutBase)
@@ -152,10 +158,12 @@ var _ = Describe("SQLSwiftRepository", func() {
 	Describe("CreateBatch", func() {
 		Context("when creating multiple banks in batch", func() {
 			It("should succeed with valid data", func() {
-				mock.ExpectExec(`INSERT INTO `+tableName+` \(swift_code, swift_code_base, country_iso_code, bank_name, is_headquarter, address, country_name\) VALUES \(\?, \?, \?, \?, \?, \?, \?\),\(\?, \?, \?, \?, \?, \?, \?\)`).
+				mock.ExpectExec(`INSERT INTO `+tableName+` \(swift_code, swift_code_base, country_iso_code, bank_name, is_headquarter, address, country_name, town_name, time_zone\) `+
+					`SELECT .* FROM \(VALUES \(\?, \?, \?, \?, CAST\(\? AS BOOLEAN\), \?, \?, \?, \?\),\(.*\)\) AS s \(.*\) `+
+					`WHERE NOT EXISTS \(SELECT 1 FROM `+tableName+` t WHERE t.swift_code = s.swift_code\)$`).
 					WithArgs(
-						"TESTCODE123", "TESTCODE", "US", "Test Bank", true, "123 Test St", "United States",
-						"TESTCODE456", "TESTCODE", "US", "Test Bank Branch", false, "456 Branch St", "United States",
+						"TESTCODE123", "TESTCODE", "US", "Test Bank", true, "123 Test St", "United States", "", "",
+						"TESTCODE456", "TESTCODE", "US", "Test Bank Branch", false, "456 Branch St", "United States", "Springfield", "America/Chicago",
 					).
 					WillReturnResult(sqlmock.NewResult(2, 2))
 
@@ -171,8 +179,8 @@ var _ = Describe("SQLSwiftRepository", func() {
 			It("should handle database errors during batch insert", func() {
 				mock.ExpectExec(`INSERT INTO .*`).
 					WithArgs(
-						"TESTCODE123", "TESTCODE", "US", "Test Bank", true, "123 Test St", "United States",
-						"TESTCODE456", "TESTCODE", "US", "Test Bank Branch", false, "456 Branch St", "United States",
+						"TESTCODE123", "TESTCODE", "US", "Test Bank", true, "123 Test St", "United States", "", "",
+						"TESTCODE456", "TESTCODE", "US", "Test Bank Branch", false, "456 Branch St", "United States", "Springfield", "America/Chicago",
 					).
 					WillReturnError(errors.New("batch insert error"))
 
@@ -186,7 +194,7 @@ var _ = Describe("SQLSwiftRepository", func() {
 				largeBatch := make([]*models.SwiftBank, 150)
 				for i := range largeBatch {
 					largeBatch[i] = &models.SwiftBank{
-						SwiftCode:      fmt.Sprintf("BANK%c%c", rune('A'+i%26), rune('0'+i%10)),
+						SwiftCode:      fmt.Sprintf("BANK%c%c%03d", rune('A'+i%26), rune('0'+i%10), i),
 						SwiftCodeBase:  fmt.Sprintf("BANK%c", rune('A'+i%26)),
 						CountryISOCode: "US",
 						BankName:       fmt.Sprintf("Bank %c", rune('A'+i%26)),
@@ -196,8 +204,8 @@ var _ = Describe("SQLSwiftRepository", func() {
 					}
 				}
 
-				// For the first batch of 100, match exact arguments count (7 fields * 100 items)
-				firstBatchArgs := make([]driver.Value, 7*100)
+				// For the first batch of 100, match exact arguments count (9 fields * 100 items)
+				firstBatchArgs := make([]driver.Value, 9*100)
 				for i := 0; i < len(firstBatchArgs); i++ {
 					firstBatchArgs[i] = sqlmock.AnyArg()
 				}
@@ -205,8 +213,8 @@ var _ = Describe("SQLSwiftRepository", func() {
 					WithArgs(firstBatchArgs...).
 					WillReturnResult(sqlmock.NewResult(100, 100))
 
-				// For the second batch of 50, match exact arguments count (7 fields * 50 items)
-				secondBatchArgs := make([]driver.Value, 7*50)
+				// For the second batch of 50, match exact arguments count (9 fields * 50 items)
+				secondBatchArgs := make([]driver.Value, 9*50)
 				for i := 0; i < len(secondBatchArgs); i++ {
 					secondBatchArgs[i] = sqlmock.AnyArg()
 				}
@@ -217,34 +225,62 @@ var _ = Describe("SQLSwiftRepository", func() {
 				err := repository.CreateBatch(ctx, largeBatch)
 				Expect(err).NotTo(HaveOccurred())
 			})
+
+			It("should keep the first row of a code repeated in the batch", func() {
+				repeated := *sampleBank
+				repeated.BankName = "Renamed Bank"
+				mock.ExpectExec(`INSERT INTO .* \(VALUES \(.*\)\) AS s`).
+					WithArgs("TESTCODE123", "TESTCODE", "US", "Test Bank", true, "123 Test St", "United States", "", "").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				Expect(repository.CreateBatch(ctx, []*models.SwiftBank{sampleBank, &repeated})).To(Succeed())
+			})
+
+			It("should merge instead when duplicates are overwritten", func() {
+				repository = repo.NewSQLSwiftRepository(&database.Database{DB: mockDB}, database.Config{
+					Catalog:     "swift_catalog",
+					Schema:      "default_schema",
+					TableName:   "swift_banks",
+					OnDuplicate: database.DuplicatesOverwrite,
+				})
+				mock.ExpectExec(`MERGE INTO ` + tableName + ` t USING \(VALUES .*\)`).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+
+				Expect(repository.CreateBatch(ctx, sampleBanks)).To(Succeed())
+			})
 		})
 	})
 
 	Describe("GetByCode", func() {
 		Context("when retrieving a bank by code", func() {
 			It("should return the correct bank", func() {
-				rows := sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name"}).
-					AddRow("TESTCODE123", "TESTCODE", "US", "Test Bank", true, "123 Test St", "United States")
+				rows := sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name", "town_name", "time_zone", "website", "phone"}).
+					AddRow("TESTCODE123", "TESTCODE", "US", "Test Bank", true, "123 Test St", "United States", "NEW YORK", "America/New_York", "https://test.example", "+1 555 0100")
 
 				mock.ExpectQuery(`SELECT .* FROM ` + tableName + ` WHERE swift_code = \?`).
 					WithArgs("TESTCODE123").
 					WillReturnRows(rows)
 
 				// For the branches query as it's a headquarters
-				branchRows := sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name"}).
-					AddRow("TESTCODE456", "TESTCODE", "US", "Test Branch", false, "456 Branch St", "United States")
+				branchRows := sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name", "town_name", "time_zone", "website", "phone"}).
+					AddRow("TESTCODE456", "TESTCODE", "US", "Test Branch", false, "456 Branch St", "United States", "BOSTON", "", "", "")
 
 				mock.ExpectQuery(`SELECT .* FROM ` + tableName + ` WHERE swift_code_base = \? AND is_headquarter = false`).
 					WithArgs("TESTCODE").
 					WillReturnRows(branchRows)
 
-				result, err := repository.GetByCode(ctx, "TESTCODE123")
+				result, err := repository.GetByCode(ctx, "TESTCODE123", repo.QueryOptions{})
 				Expect(err).NotTo(HaveOccurred())
 				Expect(result).NotTo(BeNil())
 				Expect(result.Bank.SwiftCode).To(Equal("TESTCODE123"))
 				Expect(result.Bank.BankName).To(Equal("Test Bank"))
+				Expect(result.Bank.Website).To(Equal("https://test.example"))
+				Expect(result.Bank.Phone).To(Equal("+1 555 0100"))
+				Expect(result.Bank.Town).To(Equal("NEW YORK"))
+				Expect(result.Bank.TimeZone).To(Equal("America/New_York"))
 				Expect(result.Branches).To(HaveLen(1))
 				Expect(result.Branches[0].SwiftCode).To(Equal("TESTCODE456"))
+				Expect(result.Branches[0].Town).To(Equal("BOSTON"))
 			})
 
 			It("should handle non-headquarters banks", func() {
@@ -258,15 +294,15 @@ var _ = Describe("SQLSwiftRepository", func() {
 					CountryName:    "United States",
 				}
 
-				rows := sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name"}).
-					AddRow(nonHQBank.SwiftCode, nonHQBank.SwiftCodeBase, nonHQBank.CountryISOCode, nonHQBank.BankName, nonHQBank.IsHeadquarter, nonHQBank.Address, nonHQBank.CountryName)
+				rows := sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name", "town_name", "time_zone", "website", "phone"}).
+					AddRow(nonHQBank.SwiftCode, nonHQBank.SwiftCodeBase, nonHQBank.CountryISOCode, nonHQBank.BankName, nonHQBank.IsHeadquarter, nonHQBank.Address, nonHQBank.CountryName, "", "", "", "")
 
 				mock.ExpectQuery(`SELECT .* FROM ` + tableName + ` WHERE swift_code = \?`).
 					WithArgs("BRANCH456").
 					WillReturnRows(rows)
 
 				// Should not query for branches since it's not a headquarters
-				result, err := repository.GetByCode(ctx, "BRANCH456")
+				result, err := repository.GetByCode(ctx, "BRANCH456", repo.QueryOptions{})
 				Expect(err).NotTo(HaveOccurred())
 				Expect(result).NotTo(BeNil())
 				Expect(result.Bank.SwiftCode).To(Equal("BRANCH456"))
@@ -279,8 +315,8 @@ var _ = Describe("SQLSwiftRepository", func() {
 					WithArgs("NOTFOUND").
 					WillReturnError(sql.ErrNoRows)
 
-				result, err := repository.GetByCode(ctx, "NOTFOUND")
-				Expect(err).To(Equal(repo.ErrNotFound))
+				result, err := repository.GetByCode(ctx, "NOTFOUND", repo.QueryOptions{})
+				Expect(err).To(MatchError(repo.ErrNotFound))
 				Expect(result).To(BeNil())
 			})
 
@@ -289,15 +325,15 @@ var _ = Describe("SQLSwiftRepository", func() {
 					WithArgs("TESTCODE123").
 					WillReturnError(errors.New("database error"))
 
-				result, err := repository.GetByCode(ctx, "TESTCODE123")
+				result, err := repository.GetByCode(ctx, "TESTCODE123", repo.QueryOptions{})
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("trino query failed"))
 				Expect(result).To(BeNil())
 			})
 
 			It("should handle errors when fetching branches", func() {
-				rows := sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name"}).
-					AddRow("TESTCODE123", "TESTCODE", "US", "Test Bank", true, "123 Test St", "United States")
+				rows := sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name", "town_name", "time_zone", "website", "phone"}).
+					AddRow("TESTCODE123", "TESTCODE", "US", "Test Bank", true, "123 Test St", "United States", "", "", "", "")
 
 				mock.ExpectQuery(`SELECT .* FROM ` + tableName + ` WHERE swift_code = \?`).
 					WithArgs("TESTCODE123").
@@ -307,40 +343,112 @@ var _ = Describe("SQLSwiftRepository", func() {
 					WithArgs("TESTCODE").
 					WillReturnError(errors.New("branch query error"))
 
-				result, err := repository.GetByCode(ctx, "TESTCODE123")
+				result, err := repository.GetByCode(ctx, "TESTCODE123", repo.QueryOptions{})
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("trino fetch branches failed"))
 				Expect(result).To(BeNil())
 			})
+
+			It("should return the headquarters alone in partial mode when branches fail", func() {
+				metrics := repo.NewMetrics()
+				repository = repo.NewSQLSwiftRepository(&database.Database{DB: mockDB}, database.Config{
+					Catalog:   "swift_catalog",
+					Schema:    "default_schema",
+					TableName: "swift_banks",
+				}, repo.WithPartialBranches(metrics))
+
+				rows := sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name", "town_name", "time_zone", "website", "phone"}).
+					AddRow("TESTCODE123", "TESTCODE", "US", "Test Bank", true, "123 Test St", "United States", "", "", "", "")
+				mock.ExpectQuery(`SELECT .* FROM ` + tableName + ` WHERE swift_code = \?`).
+					WithArgs("TESTCODE123").
+					WillReturnRows(rows)
+				mock.ExpectQuery(`SELECT .* FROM ` + tableName + ` WHERE swift_code_base = \? AND is_headquarter = false`).
+					WithArgs("TESTCODE").
+					WillReturnError(errors.New("branch query error"))
+
+				result, err := repository.GetByCode(ctx, "TESTCODE123", repo.QueryOptions{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Bank.SwiftCode).To(Equal("TESTCODE123"))
+				Expect(result.Branches).To(BeEmpty())
+				Expect(result.BranchesUnavailable).To(BeTrue())
+				Expect(metrics.Snapshot()).To(ConsistOf(repo.OperationStats{Operation: repo.OpGetByCode, Degraded: 1}))
+			})
+
+			It("should skip the branches when the options omit them", func() {
+				rows := sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name", "town_name", "time_zone", "website", "phone"}).
+					AddRow("TESTCODE123", "TESTCODE", "US", "Test Bank", true, "123 Test St", "United States", "", "", "", "")
+				mock.ExpectQuery(`SELECT .* FROM ` + tableName + ` WHERE swift_code = \?`).
+					WithArgs("TESTCODE123").
+					WillReturnRows(rows)
+
+				result, err := repository.GetByCode(ctx, "TESTCODE123", repo.QueryOptions{OmitBranches: true})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Bank.IsHeadquarter).To(BeTrue())
+				Expect(result.Branches).To(BeEmpty())
+			})
+
+			It("should read the snapshot current at AsOf", func() {
+				asOf := time.Date(2025, 3, 1, 12, 30, 0, 0, time.FixedZone("CET", 3600))
+				rows := sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name", "town_name", "time_zone", "website", "phone"}).
+					AddRow("TESTCODE123", "TESTCODE", "US", "Test Bank", true, "123 Test St", "United States", "", "", "", "")
+				mock.ExpectQuery(`SELECT .* FROM ` + tableName + ` FOR TIMESTAMP AS OF TIMESTAMP '2025-03-01 11:30:00.000 UTC' WHERE swift_code = \?`).
+					WithArgs("TESTCODE123").
+					WillReturnRows(rows)
+				mock.ExpectQuery(`SELECT .* FROM ` + tableName + ` FOR TIMESTAMP AS OF TIMESTAMP '2025-03-01 11:30:00.000 UTC' WHERE swift_code_base = \? AND is_headquarter = false`).
+					WithArgs("TESTCODE").
+					WillReturnRows(sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name", "town_name", "time_zone", "website", "phone"}))
+
+				_, err := repository.GetByCode(ctx, "TESTCODE123", repo.QueryOptions{AsOf: asOf})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mock.ExpectationsWereMet()).To(Succeed())
+			})
 		})
 	})
 
 	Describe("GetBranchesByHQBase", func() {
 		Context("when fetching branches for a headquarters", func() {
 			It("should return all branches", func() {
-				branchRows := sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name"}).
-					AddRow("BRANCH123", "TESTCODE", "US", "Branch 1", false, "123 Branch St", "United States").
-					AddRow("BRANCH456", "TESTCODE", "US", "Branch 2", false, "456 Branch St", "United States")
+				branchRows := sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name", "town_name", "time_zone", "website", "phone"}).
+					AddRow("BRANCH123", "TESTCODE", "US", "Branch 1", false, "123 Branch St", "United States", "", "", "", "").
+					AddRow("BRANCH456", "TESTCODE", "US", "Branch 2", false, "456 Branch St", "United States", "", "", "", "")
 
 				mock.ExpectQuery(`SELECT .* FROM ` + tableName + ` WHERE swift_code_base = \? AND is_headquarter = false`).
 					WithArgs("TESTCODE").
 					WillReturnRows(branchRows)
 
-				branches, err := repository.GetBranchesByHQBase(ctx, "TESTCODE")
+				branches, err := repository.GetBranchesByHQBase(ctx, "TESTCODE", repo.QueryOptions{})
 				Expect(err).NotTo(HaveOccurred())
 				Expect(branches).To(HaveLen(2))
 				Expect(branches[0].SwiftCode).To(Equal("BRANCH123"))
 				Expect(branches[1].SwiftCode).To(Equal("BRANCH456"))
 			})
 
+			It("should order and window branches as the options ask", func() {
+				branchRows := sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name", "town_name", "time_zone", "website", "phone"}).
+					AddRow("BRANCH456", "TESTCODE", "US", "Branch 2", false, "456 Branch St", "United States", "", "", "", "")
+
+				mock.ExpectQuery(`SELECT .* FROM ` + tableName + ` WHERE swift_code_base = \? AND is_headquarter = false ORDER BY swift_code ASC OFFSET 1 LIMIT 1`).
+					WithArgs("TESTCODE").
+					WillReturnRows(branchRows)
+
+				// A window is ordered by swift_code even without a sort
+				branches, err := repository.GetBranchesByHQBase(ctx, "TESTCODE", repo.QueryOptions{
+					Limit:  1,
+					Offset: 1,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(branches).To(HaveLen(1))
+				Expect(branches[0].SwiftCode).To(Equal("BRANCH456"))
+			})
+
 			It("should return empty slice when no branches found", func() {
-				emptyRows := sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name"})
+				emptyRows := sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name", "town_name", "time_zone", "website", "phone"})
 
 				mock.ExpectQuery(`SELECT .* FROM ` + tableName + ` WHERE swift_code_base = \? AND is_headquarter = false`).
 					WithArgs("TESTCODE").
 					WillReturnRows(emptyRows)
 
-				branches, err := repository.GetBranchesByHQBase(ctx, "TESTCODE")
+				branches, err := repository.GetBranchesByHQBase(ctx, "TESTCODE", repo.QueryOptions{})
 				Expect(err).NotTo(HaveOccurred())
 				Expect(branches).To(BeEmpty())
 			})
@@ -350,7 +458,7 @@ var _ = Describe("SQLSwiftRepository", func() {
 					WithArgs("TESTCODE").
 					WillReturnError(errors.New("database error"))
 
-				branches, err := repository.GetBranchesByHQBase(ctx, "TESTCODE")
+				branches, err := repository.GetBranchesByHQBase(ctx, "TESTCODE", repo.QueryOptions{})
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("trino query failed"))
 				Expect(branches).To(BeNil())
@@ -365,7 +473,7 @@ var _ = Describe("SQLSwiftRepository", func() {
 					WithArgs("TESTCODE").
 					WillReturnRows(incorrectRows)
 
-				branches, err := repository.GetBranchesByHQBase(ctx, "TESTCODE")
+				branches, err := repository.GetBranchesByHQBase(ctx, "TESTCODE", repo.QueryOptions{})
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("trino scan failed"))
 				Expect(branches).To(BeNil())
@@ -385,15 +493,15 @@ var _ = Describe("SQLSwiftRepository", func() {
 					WillReturnRows(countryNameRow)
 
 				// Then mock the banks query
-				bankRows := sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name"}).
-					AddRow("TESTCODE123", "TESTCODE", "US", "Test Bank", true, "123 Test St", "United States").
-					AddRow("BRANCH456", "TESTCODE", "US", "Branch Bank", false, "456 Branch St", "United States")
+				bankRows := sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name", "town_name", "time_zone", "website", "phone"}).
+					AddRow("TESTCODE123", "TESTCODE", "US", "Test Bank", true, "123 Test St", "United States", "", "", "", "").
+					AddRow("BRANCH456", "TESTCODE", "US", "Branch Bank", false, "456 Branch St", "United States", "", "", "", "")
 
 				mock.ExpectQuery(`SELECT .* FROM ` + tableName + ` WHERE country_iso_code = \?`).
 					WithArgs("US").
 					WillReturnRows(bankRows)
 
-				result, err := repository.GetByCountry(ctx, "US")
+				result, err := repository.GetByCountry(ctx, "US", repo.QueryOptions{})
 				Expect(err).NotTo(HaveOccurred())
 				Expect(result).NotTo(BeNil())
 				Expect(result.CountryISO2).To(Equal("US"))
@@ -403,13 +511,107 @@ var _ = Describe("SQLSwiftRepository", func() {
 				Expect(result.SwiftCodes[1].SwiftCode).To(Equal("BRANCH456"))
 			})
 
+			It("should filter by bank type in the query", func() {
+				mock.ExpectQuery(`SELECT country_name FROM ` + tableName + ` WHERE country_iso_code = \? LIMIT 1`).
+					WithArgs("US").
+					WillReturnRows(sqlmock.NewRows([]string{"country_name"}).AddRow("United States"))
+				mock.ExpectQuery(`SELECT .* FROM `+tableName+` WHERE country_iso_code = \? AND is_headquarter = \?$`).
+					WithArgs("US", true).
+					WillReturnRows(sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name", "town_name", "time_zone", "website", "phone"}).
+						AddRow("TESTCODEXXX", "TESTCODE", "US", "Test Bank", true, "123 Test St", "United States", "", "", "", ""))
+
+				result, err := repository.GetByCountry(ctx, "US", repo.QueryOptions{Type: repo.BankTypeHeadquarter})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.SwiftCodes).To(HaveLen(1))
+				Expect(mock.ExpectationsWereMet()).To(Succeed())
+			})
+
+			It("should filter by address and town substrings ignoring case", func() {
+				mock.ExpectQuery(`SELECT country_name FROM ` + tableName + ` WHERE country_iso_code = \? LIMIT 1`).
+					WithArgs("US").
+					WillReturnRows(sqlmock.NewRows([]string{"country_name"}).AddRow("United States"))
+				mock.ExpectQuery(`SELECT .* FROM `+tableName+` WHERE country_iso_code = \? AND strpos\(upper\(address\), \?\) > 0 AND strpos\(upper\(town_name\), \?\) > 0$`).
+					WithArgs("US", "MAIN ST", "SPRINGFIELD").
+					WillReturnRows(sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name", "town_name", "time_zone", "website", "phone"}).
+						AddRow("TESTCODEXXX", "TESTCODE", "US", "Test Bank", true, "1 Main St", "United States", "", "", "", ""))
+
+				result, err := repository.GetByCountry(ctx, "US", repo.QueryOptions{Address: "main st", Town: "Springfield"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.SwiftCodes).To(HaveLen(1))
+			})
+
+			It("should page results and count the full match", func() {
+				mock.ExpectQuery(`SELECT country_name FROM ` + tableName + ` WHERE country_iso_code = \? LIMIT 1`).
+					WithArgs("US").
+					WillReturnRows(sqlmock.NewRows([]string{"country_name"}).AddRow("United States"))
+				mock.ExpectQuery(`SELECT .* FROM ` + tableName + ` WHERE country_iso_code = \? ORDER BY swift_code ASC OFFSET 2 LIMIT 1$`).
+					WithArgs("US").
+					WillReturnRows(sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name", "town_name", "time_zone", "website", "phone"}).
+						AddRow("TESTCODEXXX", "TESTCODE", "US", "Test Bank", true, "123 Test St", "United States", "", "", "", ""))
+				mock.ExpectQuery(`SELECT COUNT\(\*\) FROM ` + tableName + ` WHERE country_iso_code = \?$`).
+					WithArgs("US").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+
+				// A window is ordered by swift_code even without a sort
+				result, err := repository.GetByCountry(ctx, "US", repo.QueryOptions{
+					Limit:  1,
+					Offset: 2,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.SwiftCodes).To(HaveLen(1))
+				Expect(result.Total).To(Equal(5))
+				Expect(mock.ExpectationsWereMet()).To(Succeed())
+			})
+
+			It("should page by cursor without an offset and count the full match", func() {
+				mock.ExpectQuery(`SELECT country_name FROM ` + tableName + ` WHERE country_iso_code = \? LIMIT 1`).
+					WithArgs("US").
+					WillReturnRows(sqlmock.NewRows([]string{"country_name"}).AddRow("United States"))
+				mock.ExpectQuery(`SELECT .* FROM `+tableName+` WHERE country_iso_code = \? AND is_headquarter = \? AND swift_code > \? ORDER BY swift_code ASC LIMIT 2$`).
+					WithArgs("US", true, "TESTCODEXXX").
+					WillReturnRows(sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name", "town_name", "time_zone", "website", "phone"}).
+						AddRow("TESTCODFXXX", "TESTCODF", "US", "Test Bank", true, "123 Test St", "United States", "", "", "", ""))
+				mock.ExpectQuery(`SELECT COUNT\(\*\) FROM `+tableName+` WHERE country_iso_code = \? AND is_headquarter = \?$`).
+					WithArgs("US", true).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+
+				result, err := repository.GetByCountry(ctx, "US", repo.QueryOptions{Type: repo.BankTypeHeadquarter, Limit: 2, After: "TESTCODEXXX"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.SwiftCodes).To(HaveLen(1))
+				Expect(result.Total).To(Equal(5))
+				Expect(mock.ExpectationsWereMet()).To(Succeed())
+			})
+
+			It("should reject a cursor with an offset or another sort field before querying", func() {
+				_, err := repository.GetByCountry(ctx, "US", repo.QueryOptions{After: "TESTCODEXXX", Offset: 10})
+				Expect(err).To(MatchError(repo.ErrInvalidData))
+				_, err = repository.GetByCountry(ctx, "US", repo.QueryOptions{After: "TESTCODEXXX", Sort: repo.Sort{Field: repo.SortBankName}})
+				Expect(err).To(MatchError(repo.ErrInvalidData))
+				Expect(mock.ExpectationsWereMet()).To(Succeed())
+			})
+
+			It("should order results in the query when a sort is given", func() {
+				mock.ExpectQuery(`SELECT country_name FROM ` + tableName + ` WHERE country_iso_code = \? LIMIT 1`).
+					WithArgs("US").
+					WillReturnRows(sqlmock.NewRows([]string{"country_name"}).AddRow("United States"))
+				mock.ExpectQuery(`SELECT .* FROM ` + tableName + ` WHERE country_iso_code = \? ORDER BY bank_name DESC, swift_code DESC$`).
+					WithArgs("US").
+					WillReturnRows(sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name", "town_name", "time_zone", "website", "phone"}))
+
+				_, err := repository.GetByCountry(ctx, "US", repo.QueryOptions{
+					Sort: repo.Sort{Field: repo.SortBankName, Descending: true},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mock.ExpectationsWereMet()).To(Succeed())
+			})
+
 			It("should handle country not found", func() {
 				mock.ExpectQuery(`SELECT country_name FROM ` + tableName + ` WHERE country_iso_code = \? LIMIT 1`).
 					WithArgs("XX").
 					WillReturnError(sql.ErrNoRows)
 
-				result, err := repository.GetByCountry(ctx, "XX")
-				Expect(err).To(Equal(repo.ErrNotFound))
+				result, err := repository.GetByCountry(ctx, "XX", repo.QueryOptions{})
+				Expect(err).To(MatchError(repo.ErrNotFound))
 				Expect(result).To(BeNil())
 			})
 
@@ -418,7 +620,7 @@ var _ = Describe("SQLSwiftRepository", func() {
 					WithArgs("US").
 					WillReturnError(errors.New("database error"))
 
-				result, err := repository.GetByCountry(ctx, "US")
+				result, err := repository.GetByCountry(ctx, "US", repo.QueryOptions{})
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("trino query failed"))
 				Expect(result).To(BeNil())
@@ -437,7 +639,7 @@ var _ = Describe("SQLSwiftRepository", func() {
 					WithArgs("US").
 					WillReturnError(errors.New("database error"))
 
-				result, err := repository.GetByCountry(ctx, "US")
+				result, err := repository.GetByCountry(ctx, "US", repo.QueryOptions{})
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("trino query failed"))
 				Expect(result).To(BeNil())
@@ -453,13 +655,13 @@ var _ = Describe("SQLSwiftRepository", func() {
 					WillReturnRows(countryNameRow)
 
 				// Then mock empty banks results
-				emptyRows := sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name"})
+				emptyRows := sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name", "town_name", "time_zone", "website", "phone"})
 
 				mock.ExpectQuery(`SELECT .* FROM ` + tableName + ` WHERE country_iso_code = \?`).
 					WithArgs("US").
 					WillReturnRows(emptyRows)
 
-				result, err := repository.GetByCountry(ctx, "US")
+				result, err := repository.GetByCountry(ctx, "US", repo.QueryOptions{})
 				Expect(err).NotTo(HaveOccurred())
 				Expect(result).NotTo(BeNil())
 				Expect(result.CountryISO2).To(Equal("US"))
@@ -484,7 +686,7 @@ var _ = Describe("SQLSwiftRepository", func() {
 					WithArgs("US").
 					WillReturnRows(incorrectRows)
 
-				result, err := repository.GetByCountry(ctx, "US")
+				result, err := repository.GetByCountry(ctx, "US", repo.QueryOptions{})
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("trino scan failed"))
 				Expect(result).To(BeNil())
@@ -515,7 +717,7 @@ var _ = Describe("SQLSwiftRepository", func() {
 					WillReturnError(sql.ErrNoRows)
 
 				err := repository.Delete(ctx, "NOTFOUND")
-				Expect(err).To(Equal(repo.ErrNotFound))
+				Expect(err).To(MatchError(repo.ErrNotFound))
 			})
 
 			It("should handle database errors during existence check", func() {
@@ -545,6 +747,71 @@ var _ = Describe("SQLSwiftRepository", func() {
 		})
 	})
 
+	Describe("DeleteByCountry", func() {
+		It("should delete every bank of the country in one statement", func() {
+			mock.ExpectExec(`DELETE FROM ` + tableName + ` WHERE country_iso_code = \?`).
+				WithArgs("PL").
+				WillReturnResult(sqlmock.NewResult(0, 42))
+
+			deleted, err := repository.DeleteByCountry(ctx, "pl")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deleted).To(Equal(int64(42)))
+		})
+
+		It("should handle database errors", func() {
+			mock.ExpectExec(`DELETE FROM ` + tableName + ` WHERE country_iso_code = \?`).
+				WithArgs("PL").
+				WillReturnError(errors.New("delete error"))
+
+			_, err := repository.DeleteByCountry(ctx, "PL")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("trino delete by country failed"))
+		})
+	})
+
+	Describe("UpdateContacts", func() {
+		It("should merge the contacts of every code in one statement", func() {
+			mock.ExpectExec(`MERGE INTO `+tableName+` t USING \(VALUES \(\?, \?, \?\),\(\?, \?, \?\)\) AS c \(swift_code, website, phone\) ON t.swift_code = c.swift_code WHEN MATCHED THEN UPDATE SET website = c.website, phone = c.phone`).
+				WithArgs("PKOPPLPWXXX", "https://www.pekao.com.pl", "", "PKOPPLPW123", "", "+48 22 000 00 00").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			updated, err := repository.UpdateContacts(ctx, []models.BankContact{
+				{SwiftCode: "pkopplpwxxx", Website: "https://www.pekao.com.pl"},
+				{SwiftCode: "PKOPPLPW123", Phone: "+48 22 000 00 00"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated).To(Equal(int64(1)))
+		})
+
+		It("should handle database errors", func() {
+			mock.ExpectExec(`MERGE INTO .*`).WillReturnError(errors.New("merge error"))
+
+			_, err := repository.UpdateContacts(ctx, []models.BankContact{{SwiftCode: "PKOPPLPWXXX", Phone: "1"}})
+			Expect(err).To(MatchError(ContainSubstring("trino contact update failed")))
+		})
+	})
+
+	Describe("ListAll", func() {
+		It("should return every bank ordered by code", func() {
+			rows := sqlmock.NewRows([]string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name", "town_name", "time_zone", "website", "phone"}).
+				AddRow("AAAAPLPWXXX", "AAAAPLPW", "PL", "Bank A", true, "Street 1", "POLAND", "", "", "", "").
+				AddRow("BBBBMTMTXXX", "BBBBMTMT", "MT", "Bank B", true, "Street 2", "MALTA", "", "", "", "")
+			mock.ExpectQuery(`SELECT .* FROM ` + tableName + ` ORDER BY swift_code`).WillReturnRows(rows)
+
+			banks, err := repository.ListAll(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(banks).To(HaveLen(2))
+			Expect(banks[1].CountryName).To(Equal("MALTA"))
+		})
+
+		It("should handle database errors", func() {
+			mock.ExpectQuery(`SELECT .* ORDER BY swift_code`).WillReturnError(errors.New("database error"))
+
+			_, err := repository.ListAll(ctx)
+			Expect(err).To(MatchError(ContainSubstring("trino query failed")))
+		})
+	})
+
 	Describe("LoadCSV", func() {
 		Context("when trying to load CSV", func() {
 			It("should return not implemented error", func() {
@@ -555,3 +822,234 @@ var _ = Describe("SQLSwiftRepository", func() {
 		})
 	})
 })
+
+var _ = Describe("Stats", func() {
+	It("should report zero counts for an empty table", func() {
+		mockDB, mock, err := sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+		defer mockDB.Close()
+
+		repository := repo.NewSQLSwiftRepository(&database.Database{DB: mockDB}, database.Config{
+			Catalog:   "swift_catalog",
+			Schema:    "default_schema",
+			TableName: "swift_banks",
+		})
+		mock.ExpectQuery(`SELECT COUNT\(\*\), .* FROM swift_catalog.default_schema.swift_banks`).
+			WillReturnRows(sqlmock.NewRows([]string{"total", "hq", "countries"}).AddRow(0, 0, 0))
+
+		stats, err := repository.Stats(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats.Empty()).To(BeTrue())
+		Expect(stats.Branches).To(BeZero())
+	})
+})
+
+var _ = Describe("Completeness", func() {
+	It("should scan one row of counts per country", func() {
+		mockDB, mock, err := sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+		defer mockDB.Close()
+
+		repository := repo.NewSQLSwiftRepository(&database.Database{DB: mockDB}, database.Config{
+			Catalog:   "swift_catalog",
+			Schema:    "default_schema",
+			TableName: "swift_banks",
+		})
+		mock.ExpectQuery(`SELECT b.country_iso_code, COUNT\(\*\), .* FROM swift_catalog.default_schema.swift_banks b LEFT JOIN .* GROUP BY b.country_iso_code`).
+			WillReturnRows(sqlmock.NewRows([]string{"country", "codes", "address", "town", "tz", "branches", "with_hq"}).
+				AddRow("MT", 3, 2, 3, 1, 2, 1).
+				AddRow("PL", 1, 1, 1, 1, 0, 0))
+
+		countries, err := repository.Completeness(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(countries).To(Equal([]repo.CountryCompleteness{
+			{CountryISO2: "MT", Codes: 3, WithAddress: 2, WithTown: 3, WithTimeZone: 1, Branches: 2, BranchesWithHQ: 1},
+			{CountryISO2: "PL", Codes: 1, WithAddress: 1, WithTown: 1, WithTimeZone: 1},
+		}))
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+})
+
+var _ = Describe("CountryCounts", func() {
+	It("should total codes per country in one grouped query", func() {
+		mockDB, mock, err := sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+		defer mockDB.Close()
+
+		repository := repo.NewSQLSwiftRepository(&database.Database{DB: mockDB}, database.Config{
+			Catalog:   "swift_catalog",
+			Schema:    "default_schema",
+			TableName: "swift_banks",
+		})
+		mock.ExpectQuery(`SELECT country_iso_code, .* FROM swift_catalog.default_schema.swift_banks GROUP BY country_iso_code ORDER BY country_iso_code`).
+			WillReturnRows(sqlmock.NewRows([]string{"country", "name", "codes", "hq"}).
+				AddRow("MT", "MALTA", 3, 1).
+				AddRow("PL", "", 2, 2))
+
+		counts, err := repository.CountryCounts(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(counts).To(Equal([]repo.CountryCount{
+			{CountryISO2: "MT", CountryName: "MALTA", TotalCodes: 3, Headquarters: 1, Branches: 2},
+			{CountryISO2: "PL", TotalCodes: 2, Headquarters: 2},
+		}))
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+})
+
+var _ = Describe("GetHeadquartersByBase", func() {
+	It("should look up the headquarters flag of the base", func() {
+		mockDB, mock, err := sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+		defer mockDB.Close()
+
+		repository := repo.NewSQLSwiftRepository(&database.Database{DB: mockDB}, database.Config{
+			Catalog:   "swift_catalog",
+			Schema:    "default_schema",
+			TableName: "swift_banks",
+		})
+		columns := []string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name", "town_name", "time_zone", "website", "phone"}
+		query := `SELECT .* FROM swift_catalog.default_schema.swift_banks WHERE swift_code_base = \? AND is_headquarter = true LIMIT 1`
+		mock.ExpectQuery(query).WithArgs("BSZLPLP1").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("BSZLPLP1XXX", "BSZLPLP1", "PL", "BANK", true, "ADDR", "POLAND", "", "", "", ""))
+		mock.ExpectQuery(query).WithArgs("ABCDPLPW").WillReturnRows(sqlmock.NewRows(columns))
+
+		hq, err := repository.GetHeadquartersByBase(context.Background(), "BSZLPLP1", repo.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hq.SwiftCode).To(Equal("BSZLPLP1XXX"))
+		Expect(hq.IsHeadquarter).To(BeTrue())
+
+		_, err = repository.GetHeadquartersByBase(context.Background(), "ABCDPLPW", repo.QueryOptions{})
+		Expect(err).To(MatchError(repo.ErrNotFound))
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+})
+
+var _ = Describe("GetByBase", func() {
+	It("should split the codes of a base into headquarters and branches", func() {
+		mockDB, mock, err := sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+		defer mockDB.Close()
+
+		repository := repo.NewSQLSwiftRepository(&database.Database{DB: mockDB}, database.Config{
+			Catalog:   "swift_catalog",
+			Schema:    "default_schema",
+			TableName: "swift_banks",
+		})
+		columns := []string{"swift_code", "swift_code_base", "country_iso_code", "bank_name", "is_headquarter", "address", "country_name", "town_name", "time_zone", "website", "phone"}
+		query := `SELECT .* FROM swift_catalog.default_schema.swift_banks WHERE swift_code_base = \? ORDER BY is_headquarter DESC, swift_code`
+		mock.ExpectQuery(query).WithArgs("BSZLPLP1").
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("BSZLPLP1XXX", "BSZLPLP1", "PL", "BANK", true, "ADDR", "POLAND", "", "", "", "").
+				AddRow("BSZLPLP1KRK", "BSZLPLP1", "PL", "BANK", false, "ADDR", "POLAND", "", "", "", "").
+				AddRow("BSZLPLP1WAW", "BSZLPLP1", "PL", "BANK", false, "ADDR", "POLAND", "", "", "", ""))
+		mock.ExpectQuery(query).WithArgs("ABCDPLPW").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("ABCDPLPWKRK", "ABCDPLPW", "PL", "BANK", false, "ADDR", "POLAND", "", "", "", ""))
+		mock.ExpectQuery(query).WithArgs("EFGHPLPW").WillReturnRows(sqlmock.NewRows(columns))
+
+		group, err := repository.GetByBase(context.Background(), "BSZLPLP1", repo.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(group.SwiftCodeBase).To(Equal("BSZLPLP1"))
+		Expect(group.Headquarters.SwiftCode).To(Equal("BSZLPLP1XXX"))
+		Expect(group.Branches).To(HaveLen(2))
+
+		group, err = repository.GetByBase(context.Background(), "ABCDPLPW", repo.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(group.Headquarters).To(BeNil())
+		Expect(group.Branches).To(ConsistOf(HaveField("SwiftCode", "ABCDPLPWKRK")))
+
+		_, err = repository.GetByBase(context.Background(), "EFGHPLPW", repo.QueryOptions{})
+		Expect(err).To(MatchError(repo.ErrNotFound))
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+})
+
+var _ = Describe("ParseSort", func() {
+	It("should parse a field with an optional direction", func() {
+		sort, err := repo.ParseSort("bankname:DESC")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sort).To(Equal(repo.Sort{Field: repo.SortBankName, Descending: true}))
+
+		sort, err = repo.ParseSort("swiftCode")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sort).To(Equal(repo.Sort{Field: repo.SortSwiftCode}))
+	})
+
+	It("should reject unknown fields and directions", func() {
+		_, err := repo.ParseSort("address")
+		Expect(err).To(MatchError(repo.ErrInvalidData))
+
+		_, err = repo.ParseSort("bankName:up")
+		Expect(err).To(MatchError(repo.ErrInvalidData))
+	})
+})
+
+var _ = Describe("ParseConsistency", func() {
+	It("should parse eventual and strong reads", func() {
+		Expect(repo.ParseConsistency("")).To(Equal(repo.ConsistencyEventual))
+		Expect(repo.ParseConsistency("eventual")).To(Equal(repo.ConsistencyEventual))
+		Expect(repo.ParseConsistency("STRONG")).To(Equal(repo.ConsistencyStrong))
+
+		_, err := repo.ParseConsistency("linearizable")
+		Expect(err).To(MatchError(repo.ErrInvalidData))
+	})
+})
+
+var _ = Describe("WithQueryLog", func() {
+	var (
+		mock    sqlmock.Sqlmock
+		output  *bytes.Buffer
+		debug   bool
+		newRepo func(cfg repo.QueryLogConfig) repo.SwiftRepository
+	)
+
+	BeforeEach(func() {
+		mockDB, m, err := sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+		mock = m
+
+		debug = true
+		output = &bytes.Buffer{}
+		log.SetOutput(output)
+		DeferCleanup(func() { log.SetOutput(os.Stderr) })
+
+		newRepo = func(cfg repo.QueryLogConfig) repo.SwiftRepository {
+			return repo.NewSQLSwiftRepository(&database.Database{DB: mockDB}, database.Config{
+				Catalog:   "c",
+				Schema:    "s",
+				TableName: "t",
+			}, repo.WithQueryLog(cfg, func() bool { return debug }))
+		}
+	})
+
+	It("should redact bound parameters by default", func() {
+		mock.ExpectExec(`DELETE FROM c.s.t WHERE country_iso_code = \?`).
+			WithArgs("PL").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		_, err := newRepo(repo.QueryLogConfig{}).DeleteByCountry(context.Background(), "PL")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output.String()).To(ContainSubstring("DEBUG: sql DeleteByCountry: DELETE FROM c.s.t WHERE country_iso_code = ? [1 params redacted]"))
+		Expect(output.String()).NotTo(ContainSubstring("PL]"))
+	})
+
+	It("should include parameters and cap the statement length when asked", func() {
+		mock.ExpectExec(`DELETE FROM c.s.t WHERE country_iso_code = \?`).
+			WithArgs("PL").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		_, err := newRepo(repo.QueryLogConfig{IncludeParams: true, MaxLength: 11}).DeleteByCountry(context.Background(), "PL")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output.String()).To(ContainSubstring("DEBUG: sql DeleteByCountry: DELETE FROM... [PL]"))
+	})
+
+	It("should stay quiet while the log level is above debug", func() {
+		mock.ExpectExec(`DELETE FROM c.s.t WHERE country_iso_code = \?`).
+			WithArgs("PL").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		debug = false
+		_, err := newRepo(repo.QueryLogConfig{}).DeleteByCountry(context.Background(), "PL")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output.String()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+)
+
+// AccessCount is how often a single country or SWIFT code was looked up
+type AccessCount struct {
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+}
+
+// AccessStats counts successful lookups per country and per SWIFT code.
+// Code lookups are also attributed to the country of the returned bank, so
+// the country ranking reflects all traffic touching that country.
+type AccessStats struct {
+	mu        sync.Mutex
+	countries map[string]int64
+	codes     map[string]int64
+}
+
+// NewAccessStats creates an empty statistics collector
+func NewAccessStats() *AccessStats {
+	return &AccessStats{
+		countries: make(map[string]int64),
+		codes:     make(map[string]int64),
+	}
+}
+
+func (s *AccessStats) recordCode(code, country string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.codes[code]++
+	if country != "" {
+		s.countries[country]++
+	}
+}
+
+func (s *AccessStats) recordCountry(country string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.countries[country]++
+}
+
+// TopCountries returns the n most queried countries, most queried first
+func (s *AccessStats) TopCountries(n int) []AccessCount {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return topN(s.countries, n)
+}
+
+// TopCodes returns the n most queried SWIFT codes, most queried first
+func (s *AccessStats) TopCodes(n int) []AccessCount {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return topN(s.codes, n)
+}
+
+// Totals returns the number of recorded country and code lookups
+func (s *AccessStats) Totals() (countries, codes int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.countries {
+		countries += c
+	}
+	for _, c := range s.codes {
+		codes += c
+	}
+	return countries, codes
+}
+
+// topN ranks counts by count descending, breaking ties by key
+func topN(counts map[string]int64, n int) []AccessCount {
+	ranked := make([]AccessCount, 0, len(counts))
+	for key, count := range counts {
+		ranked = append(ranked, AccessCount{Key: key, Count: count})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Count != ranked[j].Count {
+			return ranked[i].Count > ranked[j].Count
+		}
+		return ranked[i].Key < ranked[j].Key
+	})
+	if n >= 0 && n < len(ranked) {
+		ranked = ranked[:n]
+	}
+	return ranked
+}
+
+// accessTrackingService records lookups served by the wrapped service
+type accessTrackingService struct {
+	SwiftService
+	stats *AccessStats
+}
+
+// WithAccessStats wraps svc so that every successful lookup is counted in
+// stats. Writes pass through untouched.
+func WithAccessStats(svc SwiftService, stats *AccessStats) SwiftService {
+	return &accessTrackingService{SwiftService: svc, stats: stats}
+}
+
+func (s *accessTrackingService) GetSwiftCodeDetails(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+	detail, err := s.SwiftService.GetSwiftCodeDetails(ctx, code)
+	if err == nil {
+		s.stats.recordCode(detail.Bank.SwiftCode, detail.Bank.CountryISOCode)
+	}
+	return detail, err
+}
+
+func (s *accessTrackingService) GetHeadquarters(ctx context.Context, code string) (*models.SwiftBank, error) {
+	hq, err := s.SwiftService.GetHeadquarters(ctx, code)
+	if err == nil {
+		s.stats.recordCode(hq.SwiftCode, hq.CountryISOCode)
+	}
+	return hq, err
+}
+
+func (s *accessTrackingService) GetBankGroup(ctx context.Context, base string) (*repository.BankGroup, error) {
+	group, err := s.SwiftService.GetBankGroup(ctx, base)
+	if err == nil && group.Headquarters != nil {
+		s.stats.recordCode(group.Headquarters.SwiftCode, group.Headquarters.CountryISOCode)
+	}
+	return group, err
+}
+
+func (s *accessTrackingService) GetSwiftCodesByCountry(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+	codes, err := s.SwiftService.GetSwiftCodesByCountry(ctx, countryCode, opts)
+	if err == nil {
+		s.stats.recordCountry(codes.CountryISO2)
+	}
+	return codes, err
+}
+
+var _ SwiftService = (*accessTrackingService)(nil)
@@ -0,0 +1,62 @@
+package service_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+var _ = Describe("AccessStats", func() {
+	var (
+		ctx   context.Context
+		stats *service.AccessStats
+		svc   service.SwiftService
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		stats = service.NewAccessStats()
+		svc = service.WithAccessStats(&mocks.MockSwiftService{
+			GetSwiftCodeDetailsFunc: func(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+				if code == "MISSING1XXX" {
+					return nil, service.ErrNotFound
+				}
+				return &repository.SwiftBankDetail{
+					Bank: models.SwiftBank{SwiftCode: code, CountryISOCode: code[4:6]},
+				}, nil
+			},
+			GetSwiftCodesByCountryFunc: func(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+				return &repository.CountrySwiftCodes{CountryISO2: countryCode}, nil
+			},
+		}, stats)
+	})
+
+	It("should rank countries and codes by lookup count", func() {
+		_, _ = svc.GetSwiftCodeDetails(ctx, "PKOPPLPWXXX")
+		_, _ = svc.GetSwiftCodeDetails(ctx, "PKOPPLPWXXX")
+		_, _ = svc.GetSwiftCodeDetails(ctx, "ABCDUS33XXX")
+		_, _ = svc.GetSwiftCodesByCountry(ctx, "US", repository.QueryOptions{})
+		_, _ = svc.GetSwiftCodesByCountry(ctx, "US", repository.QueryOptions{})
+
+		Expect(stats.TopCountries(1)).To(Equal([]service.AccessCount{{Key: "US", Count: 3}}))
+		Expect(stats.TopCodes(10)).To(Equal([]service.AccessCount{
+			{Key: "PKOPPLPWXXX", Count: 2},
+			{Key: "ABCDUS33XXX", Count: 1},
+		}))
+	})
+
+	It("should ignore failed lookups", func() {
+		_, err := svc.GetSwiftCodeDetails(ctx, "MISSING1XXX")
+		Expect(err).To(MatchError(service.ErrNotFound))
+
+		countries, codes := stats.Totals()
+		Expect(countries).To(BeZero())
+		Expect(codes).To(BeZero())
+	})
+})
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+)
+
+// AliasConfig holds the settings of the alias table
+type AliasConfig struct {
+	// Enabled resolves lookups of aliases, rejects new codes stored as an
+	// alias and serves /v1/swiftCodes/{code}/aliases
+	Enabled bool `koanf:"enabled"`
+	// Table is the alias table in the database catalog and schema
+	Table string `koanf:"table"`
+}
+
+// aliasService checks writes against the alias table and resolves lookups
+// of aliases
+type aliasService struct {
+	SwiftService
+	aliases repository.AliasRepository
+}
+
+// WithAliases wraps svc so that a code stored as an alias reads as the bank
+// it names, a new code stored as an alias fails with ErrAliasConflict and a
+// deleted code takes its aliases with it. Aliases are kept in BIC11 form,
+// so either head-office spelling of an alias matches.
+func WithAliases(svc SwiftService, aliases repository.AliasRepository) SwiftService {
+	return &aliasService{SwiftService: svc, aliases: aliases}
+}
+
+func (s *aliasService) GetSwiftCodeDetails(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+	detail, err := s.SwiftService.GetSwiftCodeDetails(ctx, code)
+	if !errors.Is(err, ErrNotFound) {
+		return detail, err
+	}
+	target, aliasErr := s.aliases.Resolve(ctx, models.CanonicalBIC(strings.ToUpper(code)))
+	switch {
+	case errors.Is(aliasErr, repository.ErrNotFound):
+		return nil, err
+	case aliasErr != nil:
+		return nil, aliasErr
+	}
+	return s.SwiftService.GetSwiftCodeDetails(ctx, target)
+}
+
+func (s *aliasService) CreateSwiftCode(ctx context.Context, bank *models.SwiftBank) error {
+	if bank != nil {
+		code := models.CanonicalBIC(strings.ToUpper(bank.SwiftCode))
+		target, err := s.aliases.Resolve(ctx, code)
+		switch {
+		case err == nil:
+			return fmt.Errorf("%w: %s is an alias of %s", ErrAliasConflict, code, target)
+		case !errors.Is(err, repository.ErrNotFound):
+			return err
+		}
+	}
+	return s.SwiftService.CreateSwiftCode(ctx, bank)
+}
+
+// DeleteSwiftCode removes the aliases of a deleted code. A failure to do so
+// is logged; the aliases then keep resolving to a code that reads as not
+// found until they are removed.
+func (s *aliasService) DeleteSwiftCode(ctx context.Context, code string) error {
+	if err := s.SwiftService.DeleteSwiftCode(ctx, code); err != nil {
+		return err
+	}
+	if IsDryRun(ctx) {
+		return nil
+	}
+	code = models.CanonicalBIC(strings.ToUpper(code))
+	if err := s.aliases.RemoveCode(ctx, code); err != nil {
+		requestid.Logf(ctx, "ERROR: aliases of deleted code %s not removed: %v", code, err)
+	}
+	return nil
+}
+
+// Aliases manages the aliases of the codes of a service. An alias is
+// unique across both tables: it may be neither a stored code nor an alias
+// of another bank.
+type Aliases struct {
+	svc     SwiftService
+	aliases repository.AliasRepository
+	// mu serializes adds, which check both tables before storing
+	mu sync.Mutex
+}
+
+// NewAliases manages the aliases of the codes of svc in aliases; svc is
+// normally wrapped with WithAliases on the same repository
+func NewAliases(svc SwiftService, aliases repository.AliasRepository) *Aliases {
+	return &Aliases{svc: svc, aliases: aliases}
+}
+
+// storedCode returns the stored code a lookup of code reads, reading a
+// single branch at most
+func (a *Aliases) storedCode(ctx context.Context, code string) (string, error) {
+	detail, err := a.svc.GetSwiftCodeDetails(WithBranchWindow(ctx, 1, 0), code)
+	if err != nil {
+		return "", err
+	}
+	return detail.Bank.SwiftCode, nil
+}
+
+// List returns the aliases of the stored code
+func (a *Aliases) List(ctx context.Context, code string) ([]string, error) {
+	stored, err := a.storedCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	return a.aliases.List(ctx, stored)
+}
+
+// Add stores alias for the stored code. It fails with ErrAliasConflict
+// when alias is a stored code or an alias already, and with ErrNotFound
+// when code is not stored.
+func (a *Aliases) Add(ctx context.Context, code, alias string) error {
+	alias = strings.ToUpper(alias)
+	if !swiftCodeRegex.MatchString(alias) {
+		return invalidInput("alias", reasonSwiftCode)
+	}
+	alias = models.CanonicalBIC(alias)
+	stored, err := a.storedCode(ctx, code)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	existing, err := a.storedCode(ctx, alias)
+	switch {
+	case err == nil:
+		return fmt.Errorf("%w: %s reads as %s", ErrAliasConflict, alias, existing)
+	case !errors.Is(err, ErrNotFound):
+		return err
+	}
+	if IsDryRun(ctx) {
+		return nil
+	}
+	err = a.aliases.Add(ctx, alias, stored)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return fmt.Errorf("%w: %s", ErrAliasConflict, alias)
+	}
+	return err
+}
+
+// Remove deletes alias of the stored code, failing with ErrNotFound when
+// it is not one of its aliases
+func (a *Aliases) Remove(ctx context.Context, code, alias string) error {
+	stored, err := a.storedCode(ctx, code)
+	if err != nil {
+		return err
+	}
+	alias = models.CanonicalBIC(strings.ToUpper(alias))
+	target, err := a.aliases.Resolve(ctx, alias)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && target != stored) {
+		return fmt.Errorf("%w: alias %s of %s", ErrNotFound, alias, stored)
+	}
+	if err != nil || IsDryRun(ctx) {
+		return err
+	}
+	return a.aliases.Remove(ctx, alias)
+}
@@ -0,0 +1,91 @@
+package service_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+var _ = Describe("Aliases", func() {
+	var (
+		ctx     context.Context
+		repo    repository.SwiftRepository
+		store   *repository.MemoryAliasRepository
+		svc     service.SwiftService
+		aliases *service.Aliases
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repo = repository.NewMemorySwiftRepository()
+		Expect(repo.CreateBatch(ctx, []*models.SwiftBank{
+			{SwiftCode: "PKOPPLPWXXX", CountryISOCode: "PL", BankName: "PKO BP", IsHeadquarter: true},
+			{SwiftCode: "BREXPLPWXXX", CountryISOCode: "PL", BankName: "MBANK", IsHeadquarter: true},
+		})).To(Succeed())
+		store = repository.NewMemoryAliasRepository()
+		svc = service.WithAliases(service.NewSwiftService(repo), store)
+		aliases = service.NewAliases(svc, store)
+	})
+
+	It("should resolve lookups of an alias to its bank", func() {
+		Expect(aliases.Add(ctx, "PKOPPLPWXXX", "bpkopplp")).To(Succeed())
+
+		detail, err := svc.GetSwiftCodeDetails(ctx, "INGBPLPWXXX")
+		Expect(err).To(MatchError(service.ErrNotFound))
+		Expect(detail).To(BeNil())
+
+		detail, err = svc.GetSwiftCodeDetails(ctx, "BPKOPPLPXXX")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(detail.Bank.SwiftCode).To(Equal("PKOPPLPWXXX"))
+		Expect(aliases.List(ctx, "PKOPPLPWXXX")).To(Equal([]string{"BPKOPPLPXXX"}))
+	})
+
+	It("should reject creating a code stored as an alias", func() {
+		Expect(aliases.Add(ctx, "PKOPPLPWXXX", "BPKOPPLPXXX")).To(Succeed())
+
+		err := svc.CreateSwiftCode(ctx, &models.SwiftBank{SwiftCode: "BPKOPPLP", CountryISOCode: "PL", BankName: "BANK PKO"})
+		Expect(err).To(MatchError(service.ErrAliasConflict))
+		Expect(err).To(MatchError(service.ErrAlreadyExists))
+		_, err = repo.GetByCode(ctx, "BPKOPPLPXXX", repository.QueryOptions{})
+		Expect(err).To(MatchError(repository.ErrNotFound))
+	})
+
+	It("should reject aliases that are stored codes or aliases already", func() {
+		Expect(aliases.Add(ctx, "PKOPPLPWXXX", "BREXPLPWXXX")).To(MatchError(service.ErrAliasConflict))
+
+		Expect(aliases.Add(ctx, "PKOPPLPWXXX", "BPKOPPLPXXX")).To(Succeed())
+		Expect(aliases.Add(ctx, "BREXPLPWXXX", "BPKOPPLPXXX")).To(MatchError(service.ErrAliasConflict))
+		Expect(aliases.List(ctx, "BREXPLPWXXX")).To(BeEmpty())
+	})
+
+	It("should only add aliases to stored codes", func() {
+		Expect(aliases.Add(ctx, "INGBPLPWXXX", "BPKOPPLPXXX")).To(MatchError(service.ErrNotFound))
+		Expect(aliases.Add(ctx, "PKOPPLPWXXX", "NOT-A-BIC")).To(MatchError(service.ErrInvalidInput))
+	})
+
+	It("should store nothing in dry-run mode", func() {
+		Expect(aliases.Add(service.WithDryRun(ctx), "PKOPPLPWXXX", "BPKOPPLPXXX")).To(Succeed())
+		Expect(aliases.List(ctx, "PKOPPLPWXXX")).To(BeEmpty())
+	})
+
+	It("should remove only aliases of the given code", func() {
+		Expect(aliases.Add(ctx, "PKOPPLPWXXX", "BPKOPPLPXXX")).To(Succeed())
+
+		Expect(aliases.Remove(ctx, "BREXPLPWXXX", "BPKOPPLPXXX")).To(MatchError(service.ErrNotFound))
+		Expect(aliases.Remove(ctx, "PKOPPLPWXXX", "BPKOPPLPXXX")).To(Succeed())
+		Expect(aliases.List(ctx, "PKOPPLPWXXX")).To(BeEmpty())
+	})
+
+	It("should drop the aliases of a deleted code", func() {
+		Expect(aliases.Add(ctx, "PKOPPLPWXXX", "BPKOPPLPXXX")).To(Succeed())
+		Expect(svc.DeleteSwiftCode(ctx, "PKOPPLPWXXX")).To(Succeed())
+
+		_, err := store.Resolve(ctx, "BPKOPPLPXXX")
+		Expect(err).To(MatchError(repository.ErrNotFound))
+	})
+})
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+)
+
+type branchWindowKey struct{}
+
+// WithBranchWindow makes GetSwiftCodeDetails read only limit branches of a
+// headquarters from offset onwards, so a large headquarters costs a single
+// page of rows. Branches are ordered by code, so consecutive windows neither
+// repeat nor skip one. BranchesTotal still counts every branch. A zero limit
+// reads every branch from offset.
+func WithBranchWindow(ctx context.Context, limit, offset int) context.Context {
+	return context.WithValue(ctx, branchWindowKey{}, repository.QueryOptions{
+		Sort:   repository.Sort{Field: repository.SortSwiftCode},
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// branchWindow returns the read options set with WithBranchWindow
+func branchWindow(ctx context.Context) repository.QueryOptions {
+	opts, _ := ctx.Value(branchWindowKey{}).(repository.QueryOptions)
+	return opts
+}
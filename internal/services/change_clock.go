@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+)
+
+// ChangeClock remembers when the dataset last changed, for HTTP
+// Last-Modified validation. Times have whole-second resolution like HTTP
+// dates and strictly increase with every change, so a client that saw one
+// value never sees it again after a later write.
+type ChangeClock struct {
+	mu       sync.RWMutex
+	modified time.Time
+}
+
+// NewChangeClock creates a clock that reports the current time until the
+// first change. Changes made before the process started are unknown, so
+// the start time is the earliest safe answer.
+func NewChangeClock() *ChangeClock {
+	return &ChangeClock{modified: ceilSecond(time.Now())}
+}
+
+// Follow advances the clock with the changes other replicas announce on
+// bus, as published by CountryVersions.Share, so that no replica answers
+// 304 for data another one changed
+func (c *ChangeClock) Follow(bus repository.InvalidationBus) *ChangeClock {
+	bus.Subscribe(c.apply)
+	return c
+}
+
+// Record marks the dataset as modified now; it has the ChangeHook
+// signature
+func (c *ChangeClock) Record(context.Context, Change) {
+	c.advance(time.Now())
+}
+
+// apply marks the dataset as modified when the change of another replica
+// was made. Invalidations of the cache carry no version and are skipped,
+// except for those dropping everything, which may stand for missed changes.
+func (c *ChangeClock) apply(inv repository.Invalidation) {
+	switch {
+	case inv.Version != 0:
+		c.advance(time.UnixMicro(int64(inv.Version)))
+	case inv.All:
+		c.advance(time.Now())
+	}
+}
+
+// advance moves the clock to at, or a second past its current value when
+// at is not later
+func (c *ChangeClock) advance(at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	next := ceilSecond(at)
+	if !next.After(c.modified) {
+		next = c.modified.Add(time.Second)
+	}
+	c.modified = next
+}
+
+// LastModified returns when the dataset last changed
+func (c *ChangeClock) LastModified() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.modified
+}
+
+// ceilSecond rounds t up to a whole second in UTC
+func ceilSecond(t time.Time) time.Time {
+	truncated := t.Truncate(time.Second)
+	if truncated.Before(t) {
+		truncated = truncated.Add(time.Second)
+	}
+	return truncated.UTC()
+}
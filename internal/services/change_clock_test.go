@@ -0,0 +1,50 @@
+package service_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+var _ = Describe("ChangeClock", func() {
+	It("should start at a whole second no earlier than now", func() {
+		before := time.Now()
+		clock := service.NewChangeClock()
+		Expect(clock.LastModified().Nanosecond()).To(BeZero())
+		Expect(clock.LastModified()).NotTo(BeTemporally("<", before.Truncate(time.Second)))
+	})
+
+	It("should move forward on every change, even within one second", func() {
+		clock := service.NewChangeClock()
+		first := clock.LastModified()
+		clock.Record(context.Background(), service.Change{Type: service.ChangeCreated})
+		second := clock.LastModified()
+		clock.Record(context.Background(), service.Change{Type: service.ChangeDeleted})
+
+		Expect(second).To(BeTemporally(">", first))
+		Expect(clock.LastModified()).To(BeTemporally(">", second))
+		Expect(clock.LastModified().Nanosecond()).To(BeZero())
+	})
+
+	It("should move forward with the changes of other replicas", func() {
+		buses := newReplicaBuses(2)
+		versions := service.NewCountryVersions().Share(buses[0])
+		clock := service.NewChangeClock().Follow(buses[1])
+		before := clock.LastModified()
+
+		versions.Record(context.Background(), service.Change{Type: service.ChangeCreated, CountryISO2: "PL"})
+		after := clock.LastModified()
+		Expect(after).To(BeTemporally(">", before))
+
+		// Cache invalidations only move it when changes may have been missed
+		buses[1].deliver(repository.CountryInvalidation("PL"))
+		Expect(clock.LastModified()).To(Equal(after))
+		buses[1].deliver(repository.Invalidation{All: true})
+		Expect(clock.LastModified()).To(BeTemporally(">", after))
+	})
+})
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/zdziszkee/swift-codes/internal/correlation"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+)
+
+// Change types reported to ChangeHooks
+const (
+	ChangeCreated    = "swift_code.created"
+	ChangeDeleted    = "swift_code.deleted"
+	ChangeBulkLoaded = "swift_code.bulk_loaded"
+)
+
+// ChangeTypes lists every change type, in a stable order
+var ChangeTypes = []string{ChangeCreated, ChangeDeleted, ChangeBulkLoaded}
+
+// Change describes a write that was applied to the dataset
+type Change struct {
+	Type string `json:"type"`
+	// SwiftCode is set for single-code changes
+	SwiftCode string `json:"swiftCode,omitempty"`
+	// CountryISO2 is set when the change is limited to one country
+	CountryISO2 string `json:"countryISO2,omitempty"`
+	// Count is the number of codes affected by country deletes and bulk loads
+	Count int64 `json:"count,omitempty"`
+	// IDs names the request and actor that made the change
+	correlation.IDs
+}
+
+// ChangeHook is called after a write succeeds. Hooks run on the request
+// path, so they should hand slow work off instead of doing it inline.
+type ChangeHook func(ctx context.Context, change Change)
+
+// changeHookService reports successful writes of the wrapped service
+type changeHookService struct {
+	SwiftService
+	hooks []ChangeHook
+}
+
+// WithChangeHooks wraps svc so that every create and delete that stores a
+// change calls hooks. Dry runs and failed writes are not reported.
+func WithChangeHooks(svc SwiftService, hooks ...ChangeHook) SwiftService {
+	return &changeHookService{SwiftService: svc, hooks: hooks}
+}
+
+func (s *changeHookService) notify(ctx context.Context, change Change) {
+	if IsDryRun(ctx) {
+		return
+	}
+	change.IDs = correlation.FromContext(ctx)
+	for _, hook := range s.hooks {
+		hook(ctx, change)
+	}
+}
+
+func (s *changeHookService) CreateSwiftCode(ctx context.Context, bank *models.SwiftBank) error {
+	if err := s.SwiftService.CreateSwiftCode(ctx, bank); err != nil {
+		return err
+	}
+	s.notify(ctx, Change{Type: ChangeCreated, SwiftCode: bank.SwiftCode, CountryISO2: bank.CountryISOCode})
+	return nil
+}
+
+func (s *changeHookService) DeleteSwiftCode(ctx context.Context, code string) error {
+	if err := s.SwiftService.DeleteSwiftCode(ctx, code); err != nil {
+		return err
+	}
+	s.notify(ctx, Change{Type: ChangeDeleted, SwiftCode: strings.ToUpper(code)})
+	return nil
+}
+
+func (s *changeHookService) DeleteSwiftCodesByCountry(ctx context.Context, countryCode string) (int64, error) {
+	deleted, err := s.SwiftService.DeleteSwiftCodesByCountry(ctx, countryCode)
+	if err != nil || deleted == 0 {
+		return deleted, err
+	}
+	s.notify(ctx, Change{Type: ChangeDeleted, CountryISO2: strings.ToUpper(countryCode), Count: deleted})
+	return deleted, nil
+}
+
+var _ SwiftService = (*changeHookService)(nil)
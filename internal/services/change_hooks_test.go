@@ -0,0 +1,75 @@
+package service_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/correlation"
+	"github.com/zdziszkee/swift-codes/internal/models"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+var _ = Describe("ChangeHooks", func() {
+	var (
+		ctx     context.Context
+		changes []service.Change
+		svc     service.SwiftService
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		changes = nil
+		svc = service.WithChangeHooks(&mocks.MockSwiftService{
+			CreateSwiftCodeFunc: func(ctx context.Context, bank *models.SwiftBank) error {
+				if bank.SwiftCode == "TAKENPLPXXX" {
+					return service.ErrAlreadyExists
+				}
+				return nil
+			},
+			DeleteSwiftCodeFunc: func(ctx context.Context, code string) error {
+				return nil
+			},
+			DeleteByCountryFunc: func(ctx context.Context, countryCode string) (int64, error) {
+				if countryCode == "mt" {
+					return 0, nil
+				}
+				return 12, nil
+			},
+		}, func(ctx context.Context, change service.Change) {
+			changes = append(changes, change)
+		})
+	})
+
+	It("should report successful writes", func() {
+		Expect(svc.CreateSwiftCode(ctx, &models.SwiftBank{SwiftCode: "PKOPPLPWXXX", CountryISOCode: "PL"})).To(Succeed())
+		Expect(svc.DeleteSwiftCode(ctx, "pkopplpwxxx")).To(Succeed())
+		_, err := svc.DeleteSwiftCodesByCountry(ctx, "pl")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(changes).To(Equal([]service.Change{
+			{Type: service.ChangeCreated, SwiftCode: "PKOPPLPWXXX", CountryISO2: "PL"},
+			{Type: service.ChangeDeleted, SwiftCode: "PKOPPLPWXXX"},
+			{Type: service.ChangeDeleted, CountryISO2: "PL", Count: 12},
+		}))
+	})
+
+	It("should name the request and actor behind a change", func() {
+		ctx = correlation.WithActor(requestid.NewContext(ctx, "req-1"), "alice")
+		Expect(svc.DeleteSwiftCode(ctx, "PKOPPLPWXXX")).To(Succeed())
+
+		Expect(changes).To(ConsistOf(HaveField("IDs", correlation.IDs{RequestID: "req-1", Actor: "alice"})))
+	})
+
+	It("should not report failed writes, empty deletes or dry runs", func() {
+		Expect(svc.CreateSwiftCode(ctx, &models.SwiftBank{SwiftCode: "TAKENPLPXXX"})).To(MatchError(service.ErrAlreadyExists))
+		_, err := svc.DeleteSwiftCodesByCountry(ctx, "mt")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(svc.DeleteSwiftCode(service.WithDryRun(ctx), "PKOPPLPWXXX")).To(Succeed())
+
+		Expect(changes).To(BeEmpty())
+	})
+})
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"math"
+
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+)
+
+// CountryCompleteness scores how much of one country's directory carries
+// the optional fields, as percentages rounded to one decimal
+type CountryCompleteness struct {
+	CountryISO2 string  `json:"country_iso2"`
+	TotalCodes  int64   `json:"total_codes"`
+	Address     float64 `json:"address_pct"`
+	Town        float64 `json:"town_pct"`
+	TimeZone    float64 `json:"time_zone_pct"`
+	// HQCoverage is the share of branches whose headquarters is listed;
+	// a country without branches is fully covered
+	HQCoverage float64 `json:"hq_coverage_pct"`
+	// Score averages the four percentages above
+	Score float64 `json:"score"`
+}
+
+// CountryCounts totals the codes of every country in the dataset, ordered
+// by country
+func (s *swiftService) CountryCounts(ctx context.Context) ([]repository.CountryCount, error) {
+	return s.repo.CountryCounts(ctx)
+}
+
+// Completeness scores every country in the dataset, ordered by country
+func (s *swiftService) Completeness(ctx context.Context) ([]CountryCompleteness, error) {
+	counts, err := s.repo.Completeness(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make([]CountryCompleteness, 0, len(counts))
+	for _, c := range counts {
+		scores = append(scores, scoreCompleteness(c))
+	}
+	return scores, nil
+}
+
+func scoreCompleteness(c repository.CountryCompleteness) CountryCompleteness {
+	score := CountryCompleteness{
+		CountryISO2: c.CountryISO2,
+		TotalCodes:  c.Codes,
+		Address:     percent(c.WithAddress, c.Codes),
+		Town:        percent(c.WithTown, c.Codes),
+		TimeZone:    percent(c.WithTimeZone, c.Codes),
+		HQCoverage:  percent(c.BranchesWithHQ, c.Branches),
+	}
+	score.Score = round1((score.Address + score.Town + score.TimeZone + score.HQCoverage) / 4)
+	return score
+}
+
+// percent reports part of whole, counting an empty whole as complete
+func percent(part, whole int64) float64 {
+	if whole == 0 {
+		return 100
+	}
+	return round1(float64(part) * 100 / float64(whole))
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
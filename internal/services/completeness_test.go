@@ -0,0 +1,44 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+var _ = Describe("Completeness", func() {
+	It("should turn counts into rounded percentages and a score", func() {
+		repo := &mocks.MockSwiftRepository{
+			CompletenessFunc: func(ctx context.Context) ([]repository.CountryCompleteness, error) {
+				return []repository.CountryCompleteness{
+					{CountryISO2: "MT", Codes: 3, WithAddress: 2, WithTown: 3, WithTimeZone: 1, Branches: 2, BranchesWithHQ: 1},
+					{CountryISO2: "PL", Codes: 1, WithAddress: 1, WithTown: 1, WithTimeZone: 1},
+				}, nil
+			},
+		}
+
+		scores, err := service.NewSwiftService(repo).Completeness(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(scores).To(Equal([]service.CountryCompleteness{
+			{CountryISO2: "MT", TotalCodes: 3, Address: 66.7, Town: 100, TimeZone: 33.3, HQCoverage: 50, Score: 62.5},
+			{CountryISO2: "PL", TotalCodes: 1, Address: 100, Town: 100, TimeZone: 100, HQCoverage: 100, Score: 100},
+		}))
+	})
+
+	It("should pass repository errors through", func() {
+		repo := &mocks.MockSwiftRepository{
+			CompletenessFunc: func(ctx context.Context) ([]repository.CountryCompleteness, error) {
+				return nil, errors.New("db error")
+			},
+		}
+
+		_, err := service.NewSwiftService(repo).Completeness(context.Background())
+		Expect(err).To(MatchError("db error"))
+	})
+})
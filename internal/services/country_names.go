@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/zdziszkee/swift-codes/internal/countries"
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+)
+
+// countryNameService fills blank country names of stored rows
+type countryNameService struct {
+	SwiftService
+}
+
+// WithCountryNames wraps svc so that reads never return an empty country
+// name. Blank names are resolved from the ISO 3166 table, upper-cased like
+// the SWIFT data; rows of a listing take the name of the listing, so one
+// response names a country consistently.
+func WithCountryNames(svc SwiftService) SwiftService {
+	return &countryNameService{SwiftService: svc}
+}
+
+// countryName returns the upper-cased ISO name of iso2, or "" when unknown
+func countryName(iso2 string) string {
+	country, ok := countries.Lookup(iso2)
+	if !ok {
+		return ""
+	}
+	return strings.ToUpper(country.Name)
+}
+
+func fillCountryName(bank *models.SwiftBank, name string) {
+	if strings.TrimSpace(bank.CountryName) == "" {
+		bank.CountryName = name
+	}
+}
+
+func (s *countryNameService) GetSwiftCodeDetails(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+	detail, err := s.SwiftService.GetSwiftCodeDetails(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	filled := *detail
+	fillCountryName(&filled.Bank, countryName(filled.Bank.CountryISOCode))
+	if detail.Branches != nil {
+		filled.Branches = make([]models.SwiftBank, len(detail.Branches))
+		for i, branch := range detail.Branches {
+			fillCountryName(&branch, countryName(branch.CountryISOCode))
+			filled.Branches[i] = branch
+		}
+	}
+	return &filled, nil
+}
+
+func (s *countryNameService) GetHeadquarters(ctx context.Context, code string) (*models.SwiftBank, error) {
+	hq, err := s.SwiftService.GetHeadquarters(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	filled := *hq
+	fillCountryName(&filled, countryName(filled.CountryISOCode))
+	return &filled, nil
+}
+
+func (s *countryNameService) GetBankGroup(ctx context.Context, base string) (*repository.BankGroup, error) {
+	group, err := s.SwiftService.GetBankGroup(ctx, base)
+	if err != nil {
+		return nil, err
+	}
+
+	filled := *group
+	if group.Headquarters != nil {
+		hq := *group.Headquarters
+		fillCountryName(&hq, countryName(hq.CountryISOCode))
+		filled.Headquarters = &hq
+	}
+	filled.Branches = make([]models.SwiftBank, len(group.Branches))
+	for i, branch := range group.Branches {
+		fillCountryName(&branch, countryName(branch.CountryISOCode))
+		filled.Branches[i] = branch
+	}
+	return &filled, nil
+}
+
+func (s *countryNameService) CountryCounts(ctx context.Context) ([]repository.CountryCount, error) {
+	counts, err := s.SwiftService.CountryCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filled := make([]repository.CountryCount, len(counts))
+	for i, count := range counts {
+		if strings.TrimSpace(count.CountryName) == "" {
+			count.CountryName = countryName(count.CountryISO2)
+		}
+		filled[i] = count
+	}
+	return filled, nil
+}
+
+func (s *countryNameService) GetSwiftCodesByCountry(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+	codes, err := s.SwiftService.GetSwiftCodesByCountry(ctx, countryCode, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	filled := *codes
+	if strings.TrimSpace(filled.CountryName) == "" {
+		filled.CountryName = countryName(filled.CountryISO2)
+	}
+	if codes.SwiftCodes != nil {
+		filled.SwiftCodes = make([]models.SwiftBank, len(codes.SwiftCodes))
+		for i, bank := range codes.SwiftCodes {
+			fillCountryName(&bank, filled.CountryName)
+			filled.SwiftCodes[i] = bank
+		}
+	}
+	return &filled, nil
+}
@@ -0,0 +1,69 @@
+package service_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+var _ = Describe("Country names", func() {
+	var (
+		ctx   context.Context
+		inner *mocks.MockSwiftService
+		svc   service.SwiftService
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		inner = &mocks.MockSwiftService{
+			GetSwiftCodeDetailsFunc: func(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+				return &repository.SwiftBankDetail{
+					Bank: models.SwiftBank{SwiftCode: code, CountryISOCode: "PL", CountryName: " "},
+					Branches: []models.SwiftBank{
+						{SwiftCode: code[:8] + "WAW", CountryISOCode: "PL", CountryName: "POLAND"},
+						{SwiftCode: code[:8] + "KRK", CountryISOCode: "ZZ"},
+					},
+				}, nil
+			},
+			GetSwiftCodesByCountryFunc: func(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+				return &repository.CountrySwiftCodes{
+					CountryISO2: countryCode,
+					SwiftCodes: []models.SwiftBank{
+						{SwiftCode: "PKOPPLPWXXX", CountryISOCode: countryCode},
+						{SwiftCode: "BREXPLPWXXX", CountryISOCode: countryCode, CountryName: "POLAND"},
+					},
+				}, nil
+			},
+		}
+		svc = service.WithCountryNames(inner)
+	})
+
+	It("should resolve blank names of a code and its branches", func() {
+		detail, err := svc.GetSwiftCodeDetails(ctx, "PKOPPLPWXXX")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(detail.Bank.CountryName).To(Equal("POLAND"))
+		Expect(detail.Branches[0].CountryName).To(Equal("POLAND"))
+		Expect(detail.Branches[1].CountryName).To(BeEmpty())
+	})
+
+	It("should name a listing and its rows", func() {
+		codes, err := svc.GetSwiftCodesByCountry(ctx, "PL", repository.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(codes.CountryName).To(Equal("POLAND"))
+		Expect(codes.SwiftCodes).To(HaveEach(HaveField("CountryName", "POLAND")))
+	})
+
+	It("should pass errors through", func() {
+		inner.GetSwiftCodeDetailsFunc = func(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+			return nil, service.ErrNotFound
+		}
+		_, err := svc.GetSwiftCodeDetails(ctx, "PKOPPLPWXXX")
+		Expect(err).To(MatchError(service.ErrNotFound))
+	})
+})
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+)
+
+// CountryVersions numbers the changes of every country, for the ETags of
+// country listings and for mirrors checking which countries changed since
+// they last looked. A country's version increases with every change
+// touching it; changes not limited to one country, such as bulk loads,
+// move every version. A deleted code counts against the country of its
+// BIC, as the change does not name the country it was stored under.
+//
+// Versions are at least the current time in microseconds, so they are not
+// handed out again after a restart. Shared over a bus, every replica moves
+// a country to the version the replica making the change gave it, and
+// replicas serve the same ETags.
+type CountryVersions struct {
+	mu sync.RWMutex
+	// seq is the last version handed out or received
+	seq uint64
+	// all is the version of the last change not limited to a country
+	all       uint64
+	countries map[string]uint64
+	bus       repository.InvalidationBus
+}
+
+// NewCountryVersions creates versions at which no change has been seen
+func NewCountryVersions() *CountryVersions {
+	start := uint64(time.Now().UnixMicro())
+	return &CountryVersions{seq: start, all: start, countries: make(map[string]uint64)}
+}
+
+// Share publishes the changes recorded from now on over bus and applies
+// those published by other replicas
+func (v *CountryVersions) Share(bus repository.InvalidationBus) *CountryVersions {
+	v.mu.Lock()
+	v.bus = bus
+	v.mu.Unlock()
+	bus.Subscribe(v.apply)
+	return v
+}
+
+// Record moves the versions of the countries change touched; it has the
+// ChangeHook signature
+func (v *CountryVersions) Record(_ context.Context, change Change) {
+	country := strings.ToUpper(change.CountryISO2)
+	if country == "" && len(change.SwiftCode) >= 6 {
+		country = strings.ToUpper(change.SwiftCode[4:6])
+	}
+
+	v.mu.Lock()
+	version := max(v.seq+1, uint64(time.Now().UnixMicro()))
+	inv := repository.Invalidation{All: true, Version: version}
+	if country != "" {
+		inv = repository.CountryInvalidation(country)
+		inv.Version = version
+	}
+	v.move(inv)
+	bus := v.bus
+	v.mu.Unlock()
+
+	if bus != nil {
+		bus.Publish(inv)
+	}
+}
+
+// apply moves the versions to a change of another replica. Invalidations
+// of the cache carry no version and are skipped, except for those dropping
+// everything, such as the one sent after the bus reconnects, since changes
+// may have been missed.
+func (v *CountryVersions) apply(inv repository.Invalidation) {
+	if inv.Version == 0 && !inv.All {
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if inv.Version == 0 {
+		inv.Version = max(v.seq+1, uint64(time.Now().UnixMicro()))
+	}
+	v.move(inv)
+}
+
+// move sets the countries of inv, or every country, to its version unless
+// they are past it already
+func (v *CountryVersions) move(inv repository.Invalidation) {
+	v.seq = max(v.seq, inv.Version)
+	if inv.All {
+		v.all = max(v.all, inv.Version)
+		return
+	}
+	for _, country := range inv.Countries() {
+		v.countries[country] = max(v.countries[country], inv.Version)
+	}
+}
+
+// Version returns the current version of country
+func (v *CountryVersions) Version(country string) uint64 {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return max(v.all, v.countries[strings.ToUpper(country)])
+}
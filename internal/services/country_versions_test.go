@@ -0,0 +1,102 @@
+package service_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+// replicaBus is the bus of one replica: it delivers what the replica
+// publishes to the other replicas of the network
+type replicaBus struct {
+	network  *[]*replicaBus
+	handlers []func(repository.Invalidation)
+}
+
+func newReplicaBuses(n int) []*replicaBus {
+	network := new([]*replicaBus)
+	for range n {
+		*network = append(*network, &replicaBus{network: network})
+	}
+	return *network
+}
+
+func (b *replicaBus) Publish(inv repository.Invalidation) {
+	for _, other := range *b.network {
+		if other != b {
+			other.deliver(inv)
+		}
+	}
+}
+
+func (b *replicaBus) Subscribe(fn func(repository.Invalidation)) {
+	b.handlers = append(b.handlers, fn)
+}
+
+func (b *replicaBus) deliver(inv repository.Invalidation) {
+	for _, fn := range b.handlers {
+		fn(inv)
+	}
+}
+
+var _ = Describe("CountryVersions", func() {
+	ctx := context.Background()
+
+	It("should only move the versions of the countries a change touches", func() {
+		versions := service.NewCountryVersions()
+		pl, de := versions.Version("PL"), versions.Version("DE")
+		Expect(pl).To(Equal(de))
+
+		versions.Record(ctx, service.Change{Type: service.ChangeCreated, SwiftCode: "PKOPPLPWXXX", CountryISO2: "pl"})
+		Expect(versions.Version("PL")).To(BeNumerically(">", pl))
+		Expect(versions.Version("DE")).To(Equal(de))
+
+		// A deleted code is counted against the country of its BIC
+		pl = versions.Version("PL")
+		versions.Record(ctx, service.Change{Type: service.ChangeDeleted, SwiftCode: "DEUTDEFFXXX"})
+		Expect(versions.Version("DE")).To(BeNumerically(">", de))
+		Expect(versions.Version("pl")).To(Equal(pl))
+	})
+
+	It("should move every version on a bulk load", func() {
+		versions := service.NewCountryVersions()
+		versions.Record(ctx, service.Change{Type: service.ChangeCreated, CountryISO2: "PL"})
+		pl, mt := versions.Version("PL"), versions.Version("MT")
+
+		versions.Record(ctx, service.Change{Type: service.ChangeBulkLoaded, Count: 10})
+		Expect(versions.Version("PL")).To(BeNumerically(">", pl))
+		Expect(versions.Version("MT")).To(BeNumerically(">", mt))
+	})
+
+	It("should give every replica the versions of a change made on one of them", func() {
+		buses := newReplicaBuses(2)
+		writer := service.NewCountryVersions().Share(buses[0])
+		reader := service.NewCountryVersions().Share(buses[1])
+		de := reader.Version("DE")
+
+		writer.Record(ctx, service.Change{Type: service.ChangeCreated, SwiftCode: "PKOPPLPWXXX", CountryISO2: "PL"})
+		Expect(reader.Version("PL")).To(Equal(writer.Version("PL")))
+		Expect(reader.Version("DE")).To(Equal(de))
+
+		writer.Record(ctx, service.Change{Type: service.ChangeBulkLoaded, Count: 10})
+		Expect(reader.Version("DE")).To(Equal(writer.Version("DE")))
+		Expect(reader.Version("DE")).To(BeNumerically(">", de))
+	})
+
+	It("should move every version when the bus may have missed changes", func() {
+		bus := newReplicaBuses(1)[0]
+		versions := service.NewCountryVersions().Share(bus)
+		pl := versions.Version("PL")
+
+		// Cache invalidations announce no change of their own
+		bus.deliver(repository.CountryInvalidation("PL"))
+		Expect(versions.Version("PL")).To(Equal(pl))
+
+		bus.deliver(repository.Invalidation{All: true})
+		Expect(versions.Version("PL")).To(BeNumerically(">", pl))
+	})
+})
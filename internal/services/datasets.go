@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+)
+
+// ErrUnknownDataset is returned for a dataset name that is not configured
+var ErrUnknownDataset = errors.New("unknown dataset")
+
+var datasetNameRegex = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// DatasetsConfig maps logical dataset names, e.g. directory releases such
+// as "2025Q1", to the tables holding them. With no tables the API serves
+// only database.table_name.
+type DatasetsConfig struct {
+	// Default is the dataset served when a request does not pick one
+	Default string `koanf:"default"`
+	// Tables maps dataset names to table names in the configured schema
+	Tables map[string]string `koanf:"tables"`
+}
+
+// Validate checks the dataset names and that Default is one of them
+func (c DatasetsConfig) Validate() error {
+	if len(c.Tables) == 0 {
+		return nil
+	}
+	for name, table := range c.Tables {
+		if !datasetNameRegex.MatchString(name) {
+			return fmt.Errorf("dataset name %q must be 1-64 letters, digits, '_' or '-'", name)
+		}
+		if table == "" {
+			return fmt.Errorf("dataset %q has no table", name)
+		}
+	}
+	if _, ok := c.Tables[c.Default]; !ok {
+		return fmt.Errorf("default dataset %q is not one of the configured tables", c.Default)
+	}
+	return nil
+}
+
+type datasetKey struct{}
+
+// WithDataset selects the dataset that a DatasetRouter serves for ctx
+func WithDataset(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, datasetKey{}, name)
+}
+
+// DatasetFromContext returns the dataset selected with WithDataset, or ""
+func DatasetFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(datasetKey{}).(string)
+	return name
+}
+
+// DatasetRouter is a SwiftService that sends every call to the service of
+// the dataset selected in the context, or of the default dataset. The
+// default can be switched at runtime for a cutover between releases.
+type DatasetRouter struct {
+	services map[string]SwiftService
+
+	mu          sync.RWMutex
+	defaultName string
+}
+
+// NewDatasetRouter creates a router over services, keyed by dataset name
+func NewDatasetRouter(services map[string]SwiftService, defaultName string) (*DatasetRouter, error) {
+	if _, ok := services[defaultName]; !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownDataset, defaultName)
+	}
+	return &DatasetRouter{services: services, defaultName: defaultName}, nil
+}
+
+// Has reports whether name is a configured dataset
+func (r *DatasetRouter) Has(name string) bool {
+	_, ok := r.services[name]
+	return ok
+}
+
+// Names returns the configured datasets in name order
+func (r *DatasetRouter) Names() []string {
+	names := make([]string, 0, len(r.services))
+	for name := range r.services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Default returns the dataset served when none is selected
+func (r *DatasetRouter) Default() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.defaultName
+}
+
+// SetDefault switches the default dataset to name
+func (r *DatasetRouter) SetDefault(name string) error {
+	if !r.Has(name) {
+		return fmt.Errorf("%w: %q", ErrUnknownDataset, name)
+	}
+	r.mu.Lock()
+	r.defaultName = name
+	r.mu.Unlock()
+	return nil
+}
+
+// Resolve returns the dataset that serves ctx
+func (r *DatasetRouter) Resolve(ctx context.Context) string {
+	if name := DatasetFromContext(ctx); r.Has(name) {
+		return name
+	}
+	return r.Default()
+}
+
+func (r *DatasetRouter) service(ctx context.Context) SwiftService {
+	return r.services[r.Resolve(ctx)]
+}
+
+func (r *DatasetRouter) GetSwiftCodeDetails(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+	return r.service(ctx).GetSwiftCodeDetails(ctx, code)
+}
+
+func (r *DatasetRouter) GetHeadquarters(ctx context.Context, code string) (*models.SwiftBank, error) {
+	return r.service(ctx).GetHeadquarters(ctx, code)
+}
+
+func (r *DatasetRouter) GetBankGroup(ctx context.Context, base string) (*repository.BankGroup, error) {
+	return r.service(ctx).GetBankGroup(ctx, base)
+}
+
+func (r *DatasetRouter) GetSwiftCodesByCountry(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+	return r.service(ctx).GetSwiftCodesByCountry(ctx, countryCode, opts)
+}
+
+func (r *DatasetRouter) CreateSwiftCode(ctx context.Context, bank *models.SwiftBank) error {
+	return r.service(ctx).CreateSwiftCode(ctx, bank)
+}
+
+func (r *DatasetRouter) DeleteSwiftCode(ctx context.Context, code string) error {
+	return r.service(ctx).DeleteSwiftCode(ctx, code)
+}
+
+func (r *DatasetRouter) DeleteSwiftCodesByCountry(ctx context.Context, countryCode string) (int64, error) {
+	return r.service(ctx).DeleteSwiftCodesByCountry(ctx, countryCode)
+}
+
+func (r *DatasetRouter) DatasetStatus(ctx context.Context) (*DatasetStatus, error) {
+	return r.service(ctx).DatasetStatus(ctx)
+}
+
+func (r *DatasetRouter) Completeness(ctx context.Context) ([]CountryCompleteness, error) {
+	return r.service(ctx).Completeness(ctx)
+}
+
+func (r *DatasetRouter) CountryCounts(ctx context.Context) ([]repository.CountryCount, error) {
+	return r.service(ctx).CountryCounts(ctx)
+}
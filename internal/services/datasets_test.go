@@ -0,0 +1,67 @@
+package service_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+var _ = Describe("DatasetRouter", func() {
+	var router *service.DatasetRouter
+
+	release := func(name string) service.SwiftService {
+		return &mocks.MockSwiftService{
+			GetSwiftCodeDetailsFunc: func(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+				return &repository.SwiftBankDetail{Bank: models.SwiftBank{SwiftCode: code, BankName: name}}, nil
+			},
+		}
+	}
+
+	servedBy := func(ctx context.Context) string {
+		detail, err := router.GetSwiftCodeDetails(ctx, "PKOPPLPWXXX")
+		Expect(err).NotTo(HaveOccurred())
+		return detail.Bank.BankName
+	}
+
+	BeforeEach(func() {
+		var err error
+		router, err = service.NewDatasetRouter(map[string]service.SwiftService{
+			"2024Q4": release("2024Q4"),
+			"2025Q1": release("2025Q1"),
+		}, "2024Q4")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should serve the default unless the context selects a dataset", func() {
+		Expect(servedBy(context.Background())).To(Equal("2024Q4"))
+		Expect(servedBy(service.WithDataset(context.Background(), "2025Q1"))).To(Equal("2025Q1"))
+		Expect(router.Names()).To(Equal([]string{"2024Q4", "2025Q1"}))
+	})
+
+	It("should switch the default for a cutover", func() {
+		Expect(router.SetDefault("2025Q1")).To(Succeed())
+		Expect(router.Default()).To(Equal("2025Q1"))
+		Expect(servedBy(context.Background())).To(Equal("2025Q1"))
+	})
+
+	It("should reject unknown datasets", func() {
+		Expect(router.SetDefault("2023Q1")).To(MatchError(service.ErrUnknownDataset))
+		Expect(router.Default()).To(Equal("2024Q4"))
+
+		_, err := service.NewDatasetRouter(map[string]service.SwiftService{"2024Q4": release("2024Q4")}, "current")
+		Expect(err).To(MatchError(service.ErrUnknownDataset))
+	})
+
+	It("should validate the configuration", func() {
+		Expect(service.DatasetsConfig{}.Validate()).To(Succeed())
+		Expect(service.DatasetsConfig{Default: "a", Tables: map[string]string{"a": "swift_banks"}}.Validate()).To(Succeed())
+		Expect(service.DatasetsConfig{Default: "b", Tables: map[string]string{"a": "swift_banks"}}.Validate()).NotTo(Succeed())
+		Expect(service.DatasetsConfig{Default: "a.b", Tables: map[string]string{"a.b": "swift_banks"}}.Validate()).NotTo(Succeed())
+	})
+})
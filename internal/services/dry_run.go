@@ -0,0 +1,18 @@
+package service
+
+import "context"
+
+type dryRunKey struct{}
+
+// WithDryRun marks ctx so that CreateSwiftCode, DeleteSwiftCode and
+// DeleteSwiftCodesByCountry run every validation and conflict check, then
+// return what they would have done without writing anything
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun reports whether ctx was marked by WithDryRun
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
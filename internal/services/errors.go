@@ -0,0 +1,46 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Service errors are sentinels that callers test for with errors.Is. The
+// service wraps them with the code or country involved, so compare with
+// errors.Is rather than ==.
+var (
+	ErrNotFound      = errors.New("swift code not found")
+	ErrInvalidInput  = errors.New("invalid input provided")
+	ErrAlreadyExists = errors.New("swift code already exists")
+	// ErrAliasConflict is returned when a new code is stored in the alias
+	// table, or a new alias is a stored code or an alias already. It wraps
+	// ErrAlreadyExists so callers that only know that error still see a
+	// conflict.
+	ErrAliasConflict = fmt.Errorf("%w under an alias", ErrAlreadyExists)
+)
+
+// InputError names the field that made input invalid and why. It matches
+// ErrInvalidInput with errors.Is; use errors.As to read the field.
+type InputError struct {
+	Field  string
+	Reason string
+}
+
+func (e *InputError) Error() string {
+	return fmt.Sprintf("%v: %s %s", ErrInvalidInput, e.Field, e.Reason)
+}
+
+func (e *InputError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+// invalidInput returns an *InputError for field
+func invalidInput(field, reason string) error {
+	return &InputError{Field: field, Reason: reason}
+}
+
+// Reasons shared by the validations of several methods
+const (
+	reasonSwiftCode = "must be an 8 or 11 character SWIFT code"
+	reasonCountry   = "must be a 2-letter country code"
+)
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zdziszkee/swift-codes/internal/models"
+)
+
+// ErrReadOnly is returned for writes during a maintenance window
+var ErrReadOnly = errors.New("service is read-only for maintenance")
+
+// ErrWindowNotFound is returned when cancelling a window that was not
+// scheduled through the schedule, or has already ended
+var ErrWindowNotFound = errors.New("maintenance window not found")
+
+// MaintenanceWindow is a period during which the service is read-only
+type MaintenanceWindow struct {
+	// ID is assigned by the schedule: "config-n" for the configured
+	// windows and a number for those scheduled at runtime
+	ID    string    `json:"id" koanf:"-"`
+	Start time.Time `json:"start" koanf:"start"`
+	End   time.Time `json:"end" koanf:"end"`
+	// Reason is shown to clients while the window is active
+	Reason string `json:"reason,omitempty" koanf:"reason"`
+}
+
+// Validate checks that the window ends after it starts
+func (w MaintenanceWindow) Validate() error {
+	if w.Start.IsZero() || w.End.IsZero() {
+		return errors.New("maintenance window needs a start and an end")
+	}
+	if !w.End.After(w.Start) {
+		return fmt.Errorf("maintenance window ending at %s must end after its start %s", w.End.Format(time.RFC3339), w.Start.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// MaintenanceError reports a write refused during Window. It matches
+// ErrReadOnly with errors.Is; use errors.As to read the window.
+type MaintenanceError struct {
+	Window MaintenanceWindow
+}
+
+func (e *MaintenanceError) Error() string {
+	return fmt.Sprintf("%v until %s", ErrReadOnly, e.Window.End.Format(time.RFC3339))
+}
+
+func (e *MaintenanceError) Unwrap() error {
+	return ErrReadOnly
+}
+
+// MaintenanceSchedule holds the configured maintenance windows and those
+// scheduled by operators at runtime. The latter are kept in memory only,
+// so windows meant to survive a restart belong in the configuration.
+type MaintenanceSchedule struct {
+	mu         sync.RWMutex
+	configured []MaintenanceWindow
+	scheduled  []MaintenanceWindow
+	nextID     int
+	now        func() time.Time
+}
+
+// NewMaintenanceSchedule creates a schedule of the configured windows
+func NewMaintenanceSchedule(configured []MaintenanceWindow) *MaintenanceSchedule {
+	s := &MaintenanceSchedule{now: time.Now}
+	s.SetConfigured(configured)
+	return s
+}
+
+// SetConfigured replaces the configured windows, e.g. after a
+// configuration reload; windows scheduled at runtime are kept
+func (s *MaintenanceSchedule) SetConfigured(windows []MaintenanceWindow) {
+	configured := make([]MaintenanceWindow, len(windows))
+	for i, window := range windows {
+		window.ID = "config-" + strconv.Itoa(i+1)
+		configured[i] = window
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.configured = configured
+}
+
+// Schedule adds a window and returns it with its ID. Windows that already
+// ended are refused.
+func (s *MaintenanceSchedule) Schedule(window MaintenanceWindow) (MaintenanceWindow, error) {
+	if err := window.Validate(); err != nil {
+		return MaintenanceWindow{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !window.End.After(s.now()) {
+		return MaintenanceWindow{}, errors.New("maintenance window has already ended")
+	}
+	s.nextID++
+	window.ID = strconv.Itoa(s.nextID)
+	s.scheduled = append(s.scheduled, window)
+	return window, nil
+}
+
+// Cancel removes a window scheduled at runtime, ending it early when it is
+// active. Configured windows are changed through the configuration.
+func (s *MaintenanceSchedule) Cancel(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.IndexFunc(s.scheduled, func(w MaintenanceWindow) bool { return w.ID == id })
+	if i < 0 || !s.scheduled[i].End.After(s.now()) {
+		return ErrWindowNotFound
+	}
+	s.scheduled = slices.Delete(s.scheduled, i, i+1)
+	return nil
+}
+
+// Windows returns the windows that have not ended, by start time
+func (s *MaintenanceSchedule) Windows() []MaintenanceWindow {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	now := s.now()
+	var windows []MaintenanceWindow
+	for _, window := range slices.Concat(s.configured, s.scheduled) {
+		if window.End.After(now) {
+			windows = append(windows, window)
+		}
+	}
+	slices.SortStableFunc(windows, func(a, b MaintenanceWindow) int { return a.Start.Compare(b.Start) })
+	return windows
+}
+
+// Active returns the window the service is in. Of overlapping windows the
+// one ending last is returned, as the service stays read-only until then.
+func (s *MaintenanceSchedule) Active() (MaintenanceWindow, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	now := s.now()
+	var active MaintenanceWindow
+	found := false
+	for _, window := range slices.Concat(s.configured, s.scheduled) {
+		if !now.Before(window.Start) && now.Before(window.End) && (!found || window.End.After(active.End)) {
+			active, found = window, true
+		}
+	}
+	return active, found
+}
+
+// InMaintenance reports whether a window is active; it suits callers that
+// only pause, such as scheduled imports
+func (s *MaintenanceSchedule) InMaintenance() bool {
+	_, active := s.Active()
+	return active
+}
+
+// Refuse returns a *MaintenanceError for the active window, or nil outside
+// maintenance; it suits writers outside the service, such as imports
+func (s *MaintenanceSchedule) Refuse() error {
+	if window, active := s.Active(); active {
+		return &MaintenanceError{Window: window}
+	}
+	return nil
+}
+
+// maintenanceService refuses writes while a maintenance window is active
+type maintenanceService struct {
+	SwiftService
+	schedule *MaintenanceSchedule
+}
+
+// WithMaintenance wraps svc so that creates and deletes fail with a
+// *MaintenanceError while a window of schedule is active, and DatasetStatus
+// reports the window. Dry runs still go through, as they store nothing.
+// Reads are served throughout, and writes resume when the window ends.
+func WithMaintenance(svc SwiftService, schedule *MaintenanceSchedule) SwiftService {
+	return &maintenanceService{SwiftService: svc, schedule: schedule}
+}
+
+// refuse returns the error for a write in ctx, or nil outside maintenance
+func (s *maintenanceService) refuse(ctx context.Context) error {
+	if IsDryRun(ctx) {
+		return nil
+	}
+	return s.schedule.Refuse()
+}
+
+func (s *maintenanceService) CreateSwiftCode(ctx context.Context, bank *models.SwiftBank) error {
+	if err := s.refuse(ctx); err != nil {
+		return err
+	}
+	return s.SwiftService.CreateSwiftCode(ctx, bank)
+}
+
+func (s *maintenanceService) DeleteSwiftCode(ctx context.Context, code string) error {
+	if err := s.refuse(ctx); err != nil {
+		return err
+	}
+	return s.SwiftService.DeleteSwiftCode(ctx, code)
+}
+
+func (s *maintenanceService) DeleteSwiftCodesByCountry(ctx context.Context, countryCode string) (int64, error) {
+	if err := s.refuse(ctx); err != nil {
+		return 0, err
+	}
+	return s.SwiftService.DeleteSwiftCodesByCountry(ctx, countryCode)
+}
+
+func (s *maintenanceService) DatasetStatus(ctx context.Context) (*DatasetStatus, error) {
+	status, err := s.SwiftService.DatasetStatus(ctx)
+	window, active := s.schedule.Active()
+	if err != nil || !active {
+		return status, err
+	}
+	annotated := *status
+	annotated.Maintenance = &window
+	return &annotated, nil
+}
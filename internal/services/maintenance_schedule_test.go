@@ -0,0 +1,115 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/models"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+var _ = Describe("MaintenanceSchedule", func() {
+	var now time.Time
+
+	BeforeEach(func() {
+		now = time.Now()
+	})
+
+	It("should report the active window ending last", func() {
+		schedule := service.NewMaintenanceSchedule([]service.MaintenanceWindow{
+			{Start: now.Add(-time.Hour), End: now.Add(time.Hour), Reason: "upgrade"},
+			{Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour)},
+		})
+		active, ok := schedule.Active()
+		Expect(ok).To(BeTrue())
+		Expect(active.ID).To(Equal("config-1"))
+
+		later, err := schedule.Schedule(service.MaintenanceWindow{Start: now.Add(-time.Minute), End: now.Add(2 * time.Hour)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(later.ID).To(Equal("1"))
+		active, _ = schedule.Active()
+		Expect(active.ID).To(Equal("1"))
+
+		// Ended windows are no longer listed
+		Expect(schedule.Windows()).To(HaveLen(2))
+	})
+
+	It("should leave maintenance when the last active window is cancelled", func() {
+		schedule := service.NewMaintenanceSchedule(nil)
+		Expect(schedule.InMaintenance()).To(BeFalse())
+
+		window, err := schedule.Schedule(service.MaintenanceWindow{Start: now.Add(-time.Minute), End: now.Add(time.Hour)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(schedule.InMaintenance()).To(BeTrue())
+
+		Expect(schedule.Cancel(window.ID)).To(Succeed())
+		Expect(schedule.InMaintenance()).To(BeFalse())
+		Expect(schedule.Cancel(window.ID)).To(MatchError(service.ErrWindowNotFound))
+	})
+
+	It("should refuse windows that are inverted or already over", func() {
+		schedule := service.NewMaintenanceSchedule(nil)
+		_, err := schedule.Schedule(service.MaintenanceWindow{Start: now, End: now.Add(-time.Hour)})
+		Expect(err).To(MatchError(ContainSubstring("must end after its start")))
+		_, err = schedule.Schedule(service.MaintenanceWindow{Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour)})
+		Expect(err).To(MatchError(ContainSubstring("already ended")))
+		_, err = schedule.Schedule(service.MaintenanceWindow{End: now.Add(time.Hour)})
+		Expect(err).To(MatchError(ContainSubstring("needs a start and an end")))
+	})
+})
+
+var _ = Describe("WithMaintenance", func() {
+	var (
+		ctx      context.Context
+		schedule *service.MaintenanceSchedule
+		svc      service.SwiftService
+		created  int
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		created = 0
+		schedule = service.NewMaintenanceSchedule(nil)
+		svc = service.WithMaintenance(&mocks.MockSwiftService{
+			CreateSwiftCodeFunc: func(ctx context.Context, bank *models.SwiftBank) error {
+				created++
+				return nil
+			},
+			DatasetStatusFunc: func(ctx context.Context) (*service.DatasetStatus, error) {
+				return &service.DatasetStatus{Status: service.DatasetReady}, nil
+			},
+		}, schedule)
+	})
+
+	It("should refuse writes other than dry runs during a window and resume after it", func() {
+		bank := &models.SwiftBank{SwiftCode: "PKOPPLPWXXX"}
+		window, err := schedule.Schedule(service.MaintenanceWindow{Start: time.Now().Add(-time.Minute), End: time.Now().Add(time.Hour), Reason: "upgrade"})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = svc.CreateSwiftCode(ctx, bank)
+		Expect(err).To(MatchError(service.ErrReadOnly))
+		var maintenanceErr *service.MaintenanceError
+		Expect(errors.As(err, &maintenanceErr)).To(BeTrue())
+		Expect(maintenanceErr.Window.Reason).To(Equal("upgrade"))
+		_, err = svc.DeleteSwiftCodesByCountry(ctx, "PL")
+		Expect(err).To(MatchError(service.ErrReadOnly))
+
+		Expect(svc.CreateSwiftCode(service.WithDryRun(ctx), bank)).To(Succeed())
+		status, err := svc.DatasetStatus(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Maintenance).NotTo(BeNil())
+		Expect(status.Maintenance.ID).To(Equal(window.ID))
+
+		Expect(schedule.Cancel(window.ID)).To(Succeed())
+		Expect(svc.CreateSwiftCode(ctx, bank)).To(Succeed())
+		Expect(created).To(Equal(2))
+		status, err = svc.DatasetStatus(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Maintenance).To(BeNil())
+	})
+})
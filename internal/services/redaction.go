@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+)
+
+type redactedFieldsKey struct{}
+
+// ValidateRedactedFields checks that every field is one of the Mask* names
+func ValidateRedactedFields(fields []string) error {
+	for _, field := range fields {
+		if !maskable(field) {
+			return fmt.Errorf("cannot redact unknown field %q", field)
+		}
+	}
+	return nil
+}
+
+// WithRedactedFields returns a context under which a service wrapped with
+// WithRedaction replaces fields, named as the Mask* constants, with
+// MaskedValue
+func WithRedactedFields(ctx context.Context, fields []string) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+	mask := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		mask[field] = true
+	}
+	return context.WithValue(ctx, redactedFieldsKey{}, mask)
+}
+
+func redactedFields(ctx context.Context) map[string]bool {
+	mask, _ := ctx.Value(redactedFieldsKey{}).(map[string]bool)
+	return mask
+}
+
+// redactingService masks fields per request, as chosen by the caller's
+// context
+type redactingService struct {
+	SwiftService
+}
+
+// WithRedaction wraps svc so that reads made under WithRedactedFields mask
+// those fields, as sampling mode does for every request. It lets the API
+// serve anonymous callers fewer fields than authenticated ones.
+func WithRedaction(svc SwiftService) SwiftService {
+	return &redactingService{SwiftService: svc}
+}
+
+func (s *redactingService) GetSwiftCodeDetails(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+	detail, err := s.SwiftService.GetSwiftCodeDetails(ctx, code)
+	mask := redactedFields(ctx)
+	if err != nil || mask == nil {
+		return detail, err
+	}
+
+	redacted := &repository.SwiftBankDetail{Bank: redactFields(detail.Bank, mask), BranchesUnavailable: detail.BranchesUnavailable, BranchesTotal: detail.BranchesTotal}
+	for _, branch := range detail.Branches {
+		redacted.Branches = append(redacted.Branches, redactFields(branch, mask))
+	}
+	return redacted, nil
+}
+
+func (s *redactingService) GetHeadquarters(ctx context.Context, code string) (*models.SwiftBank, error) {
+	hq, err := s.SwiftService.GetHeadquarters(ctx, code)
+	mask := redactedFields(ctx)
+	if err != nil || mask == nil {
+		return hq, err
+	}
+	redacted := redactFields(*hq, mask)
+	return &redacted, nil
+}
+
+func (s *redactingService) GetBankGroup(ctx context.Context, base string) (*repository.BankGroup, error) {
+	group, err := s.SwiftService.GetBankGroup(ctx, base)
+	mask := redactedFields(ctx)
+	if err != nil || mask == nil {
+		return group, err
+	}
+	return redactGroup(group, mask), nil
+}
+
+func (s *redactingService) GetSwiftCodesByCountry(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+	codes, err := s.SwiftService.GetSwiftCodesByCountry(ctx, countryCode, opts)
+	mask := redactedFields(ctx)
+	if err != nil || mask == nil {
+		return codes, err
+	}
+
+	redacted := *codes
+	redacted.SwiftCodes = make([]models.SwiftBank, len(codes.SwiftCodes))
+	for i, bank := range codes.SwiftCodes {
+		redacted.SwiftCodes[i] = redactFields(bank, mask)
+	}
+	return &redacted, nil
+}
@@ -0,0 +1,56 @@
+package service_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+var _ = Describe("Redaction", func() {
+	var svc service.SwiftService
+
+	BeforeEach(func() {
+		svc = service.WithRedaction(&mocks.MockSwiftService{
+			GetSwiftCodesByCountryFunc: func(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+				return &repository.CountrySwiftCodes{CountryISO2: countryCode, Total: 1, SwiftCodes: []models.SwiftBank{
+					{SwiftCode: "PKOPPLPWXXX", Address: "ZUBRA 1", Website: "https://bank.example"},
+				}}, nil
+			},
+			GetHeadquartersFunc: func(ctx context.Context, code string) (*models.SwiftBank, error) {
+				return &models.SwiftBank{SwiftCode: code[:8] + "XXX", Address: "ZUBRA 1", Phone: "+48 22 000 00 00"}, nil
+			},
+		})
+	})
+
+	It("should mask the fields named by the request context", func() {
+		ctx := service.WithRedactedFields(context.Background(), []string{service.MaskAddress, service.MaskPhone})
+
+		codes, err := svc.GetSwiftCodesByCountry(ctx, "PL", repository.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(codes.SwiftCodes).To(ConsistOf(models.SwiftBank{
+			SwiftCode: "PKOPPLPWXXX", Address: service.MaskedValue, Website: "https://bank.example",
+		}))
+
+		hq, err := svc.GetHeadquarters(ctx, "PKOPPLPWKRK")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hq.Address).To(Equal(service.MaskedValue))
+		Expect(hq.Phone).To(Equal(service.MaskedValue))
+	})
+
+	It("should pass reads through without redacted fields", func() {
+		hq, err := svc.GetHeadquarters(context.Background(), "PKOPPLPWKRK")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hq.Address).To(Equal("ZUBRA 1"))
+	})
+
+	It("should only accept maskable field names", func() {
+		Expect(service.ValidateRedactedFields([]string{service.MaskWebsite})).To(Succeed())
+		Expect(service.ValidateRedactedFields([]string{"bankName"})).To(HaveOccurred())
+	})
+})
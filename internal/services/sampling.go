@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+)
+
+// MaskedValue replaces masked fields in sampled responses
+const MaskedValue = "[redacted]"
+
+// Fields that SamplingConfig.MaskFields may name
+const (
+	MaskAddress = "address"
+	MaskWebsite = "website"
+	MaskPhone   = "phone"
+)
+
+// SamplingConfig turns the service into a public demo that exposes only a
+// fixed sample of the licensed directory
+type SamplingConfig struct {
+	Enabled bool `koanf:"enabled"`
+	// Rate is the share of institutions served, between 0 and 1
+	Rate float64 `koanf:"rate"`
+	// MaskFields lists the fields replaced with MaskedValue
+	MaskFields []string `koanf:"mask_fields"`
+}
+
+// Validate checks the rate and the masked field names
+func (c SamplingConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Rate <= 0 || c.Rate > 1 {
+		return fmt.Errorf("sampling rate must be in (0, 1], got %v", c.Rate)
+	}
+	for _, field := range c.MaskFields {
+		if !maskable(field) {
+			return fmt.Errorf("sampling cannot mask unknown field %q", field)
+		}
+	}
+	return nil
+}
+
+// maskable reports whether field is one of the Mask* field names
+func maskable(field string) bool {
+	switch field {
+	case MaskAddress, MaskWebsite, MaskPhone:
+		return true
+	}
+	return false
+}
+
+// redactGroup returns a copy of group with redactFields applied to every
+// code
+func redactGroup(group *repository.BankGroup, mask map[string]bool) *repository.BankGroup {
+	redacted := &repository.BankGroup{SwiftCodeBase: group.SwiftCodeBase, Branches: make([]models.SwiftBank, len(group.Branches))}
+	if group.Headquarters != nil {
+		hq := redactFields(*group.Headquarters, mask)
+		redacted.Headquarters = &hq
+	}
+	for i, branch := range group.Branches {
+		redacted.Branches[i] = redactFields(branch, mask)
+	}
+	return redacted
+}
+
+// redactFields replaces the non-empty fields of bank named in mask with
+// MaskedValue
+func redactFields(bank models.SwiftBank, mask map[string]bool) models.SwiftBank {
+	if mask[MaskAddress] && bank.Address != "" {
+		bank.Address = MaskedValue
+	}
+	if mask[MaskWebsite] && bank.Website != "" {
+		bank.Website = MaskedValue
+	}
+	if mask[MaskPhone] && bank.Phone != "" {
+		bank.Phone = MaskedValue
+	}
+	return bank
+}
+
+// samplingService serves a deterministic sample of the wrapped service
+type samplingService struct {
+	SwiftService
+	threshold uint32
+	mask      map[string]bool
+}
+
+// WithSampling wraps svc so that reads only return institutions in the
+// sample and mask the configured fields. Membership is a hash of the 8-char
+// institution code, so a headquarters and its branches are sampled together
+// and every replica serves the same sample. Codes outside the sample read
+// as not found.
+func WithSampling(svc SwiftService, config SamplingConfig) SwiftService {
+	mask := make(map[string]bool, len(config.MaskFields))
+	for _, field := range config.MaskFields {
+		mask[field] = true
+	}
+	return &samplingService{
+		SwiftService: svc,
+		threshold:    uint32(config.Rate * float64(^uint32(0))),
+		mask:         mask,
+	}
+}
+
+// sampled reports whether the institution of code is in the sample
+func (s *samplingService) sampled(code string) bool {
+	if len(code) > 8 {
+		code = code[:8]
+	}
+	h := fnv.New32a()
+	h.Write([]byte(code))
+	return h.Sum32() <= s.threshold
+}
+
+func (s *samplingService) redact(bank models.SwiftBank) models.SwiftBank {
+	return redactFields(bank, s.mask)
+}
+
+// GetSwiftCodeDetails filters the branches after they are read, so
+// BranchesTotal only counts sampled branches exactly when every branch was
+// read
+func (s *samplingService) GetSwiftCodeDetails(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+	detail, err := s.SwiftService.GetSwiftCodeDetails(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if !s.sampled(detail.Bank.SwiftCode) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, code)
+	}
+
+	sampled := &repository.SwiftBankDetail{Bank: s.redact(detail.Bank), BranchesUnavailable: detail.BranchesUnavailable}
+	for _, branch := range detail.Branches {
+		if s.sampled(branch.SwiftCode) {
+			sampled.Branches = append(sampled.Branches, s.redact(branch))
+		}
+	}
+	// Only the branches read are filtered, so the total drops by those
+	// left out of a window and counts the sampled branches
+	if detail.BranchesTotal != 0 {
+		sampled.BranchesTotal = max(detail.BranchesTotal-(len(detail.Branches)-len(sampled.Branches)), len(sampled.Branches))
+	}
+	return sampled, nil
+}
+
+func (s *samplingService) GetHeadquarters(ctx context.Context, code string) (*models.SwiftBank, error) {
+	hq, err := s.SwiftService.GetHeadquarters(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if !s.sampled(hq.SwiftCode) {
+		return nil, fmt.Errorf("%w: headquarters of %s", ErrNotFound, code)
+	}
+	redacted := s.redact(*hq)
+	return &redacted, nil
+}
+
+// GetBankGroup serves a group whole or not at all, since its codes share
+// the institution code that decides membership
+func (s *samplingService) GetBankGroup(ctx context.Context, base string) (*repository.BankGroup, error) {
+	group, err := s.SwiftService.GetBankGroup(ctx, base)
+	if err != nil {
+		return nil, err
+	}
+	if !s.sampled(group.SwiftCodeBase) {
+		return nil, fmt.Errorf("%w: swift code base %s", ErrNotFound, group.SwiftCodeBase)
+	}
+	return redactGroup(group, s.mask), nil
+}
+
+// GetSwiftCodesByCountry filters each page after it is read, so paged
+// listings may return fewer codes than the limit and Total is an estimate
+func (s *samplingService) GetSwiftCodesByCountry(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+	codes, err := s.SwiftService.GetSwiftCodesByCountry(ctx, countryCode, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	sampled := *codes
+	sampled.SwiftCodes = make([]models.SwiftBank, 0, len(codes.SwiftCodes))
+	for _, bank := range codes.SwiftCodes {
+		if s.sampled(bank.SwiftCode) {
+			sampled.SwiftCodes = append(sampled.SwiftCodes, s.redact(bank))
+		}
+	}
+	sampled.Total -= len(codes.SwiftCodes) - len(sampled.SwiftCodes)
+	if sampled.Total < len(sampled.SwiftCodes) {
+		sampled.Total = len(sampled.SwiftCodes)
+	}
+	return &sampled, nil
+}
@@ -0,0 +1,108 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+var _ = Describe("Sampling", func() {
+	var (
+		ctx   context.Context
+		inner *mocks.MockSwiftService
+		banks []models.SwiftBank
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		banks = nil
+		for i := 0; i < 200; i++ {
+			base := fmt.Sprintf("BK%02dPLPW", i%100)
+			banks = append(banks, models.SwiftBank{
+				SwiftCode: base + fmt.Sprintf("%03d", i/100), CountryISOCode: "PL",
+				Address: "ZUBRA 1", Website: "https://bank.example", Phone: "+48 22 000 00 00",
+			})
+		}
+		inner = &mocks.MockSwiftService{
+			GetSwiftCodeDetailsFunc: func(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+				return &repository.SwiftBankDetail{
+					Bank:     models.SwiftBank{SwiftCode: code, Address: "ZUBRA 1", Phone: "+48 22 000 00 00"},
+					Branches: []models.SwiftBank{{SwiftCode: code[:8] + "WAW", Address: "PROSTA 2"}},
+				}, nil
+			},
+			GetSwiftCodesByCountryFunc: func(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+				return &repository.CountrySwiftCodes{CountryISO2: countryCode, SwiftCodes: banks, Total: len(banks)}, nil
+			},
+		}
+	})
+
+	It("should mask the configured fields of sampled codes", func() {
+		svc := service.WithSampling(inner, service.SamplingConfig{Enabled: true, Rate: 1, MaskFields: []string{service.MaskAddress}})
+
+		detail, err := svc.GetSwiftCodeDetails(ctx, "PKOPPLPWXXX")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(detail.Bank.Address).To(Equal(service.MaskedValue))
+		Expect(detail.Bank.Phone).To(Equal("+48 22 000 00 00"))
+		Expect(detail.Branches).To(ConsistOf(HaveField("Address", service.MaskedValue)))
+	})
+
+	It("should hide codes outside the sample", func() {
+		svc := service.WithSampling(inner, service.SamplingConfig{Enabled: true, Rate: 1e-9})
+
+		_, err := svc.GetSwiftCodeDetails(ctx, "PKOPPLPWXXX")
+		Expect(err).To(MatchError(service.ErrNotFound))
+	})
+
+	It("should sample whole institutions consistently", func() {
+		svc := service.WithSampling(inner, service.SamplingConfig{Enabled: true, Rate: 0.5})
+
+		codes, err := svc.GetSwiftCodesByCountry(ctx, "PL", repository.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(codes.SwiftCodes)).To(BeNumerically(">", 40))
+		Expect(len(codes.SwiftCodes)).To(BeNumerically("<", 160))
+		Expect(codes.Total).To(Equal(len(codes.SwiftCodes)))
+
+		// Both codes of an institution are either in or out of the sample
+		institutions := map[string]int{}
+		for _, bank := range codes.SwiftCodes {
+			institutions[bank.SwiftCode[:8]]++
+		}
+		for _, count := range institutions {
+			Expect(count).To(Equal(2))
+		}
+
+		// A second replica serves the same sample
+		again, err := service.WithSampling(inner, service.SamplingConfig{Enabled: true, Rate: 0.5}).
+			GetSwiftCodesByCountry(ctx, "PL", repository.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again.SwiftCodes).To(Equal(codes.SwiftCodes))
+	})
+
+	It("should count only the sampled branches", func() {
+		svc := service.WithSampling(inner, service.SamplingConfig{Enabled: true, Rate: 0.5})
+		codes, err := svc.GetSwiftCodesByCountry(ctx, "PL", repository.QueryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		hq := codes.SwiftCodes[0].SwiftCode
+		inner.GetSwiftCodeDetailsFunc = func(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+			return &repository.SwiftBankDetail{Bank: models.SwiftBank{SwiftCode: code}, Branches: banks, BranchesTotal: len(banks)}, nil
+		}
+
+		detail, err := svc.GetSwiftCodeDetails(ctx, hq)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(detail.Branches)).To(BeNumerically("<", len(banks)))
+		Expect(detail.BranchesTotal).To(Equal(len(detail.Branches)))
+	})
+
+	It("should validate the configuration", func() {
+		Expect(service.SamplingConfig{}.Validate()).To(Succeed())
+		Expect(service.SamplingConfig{Enabled: true, Rate: 0}.Validate()).NotTo(Succeed())
+		Expect(service.SamplingConfig{Enabled: true, Rate: 0.1, MaskFields: []string{"bank_name"}}.Validate()).NotTo(Succeed())
+	})
+})
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"sync"
+)
+
+// StartupLoad is the state of the import run at startup
+type StartupLoad struct {
+	mu      sync.Mutex
+	running bool
+	err     error
+}
+
+// Begin marks the startup import as running
+func (l *StartupLoad) Begin() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.running, l.err = true, nil
+}
+
+// Finish marks the startup import as done, having failed with err when it
+// is not nil
+func (l *StartupLoad) Finish(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.running, l.err = false, err
+}
+
+func (l *StartupLoad) state() (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.running, l.err
+}
+
+// loadingService reports the dataset as loading while the startup import
+// runs in the background
+type loadingService struct {
+	SwiftService
+	load *StartupLoad
+}
+
+// WithStartupLoad wraps svc so that DatasetStatus reports DatasetLoading
+// while load is running, and the error of a failed load in LoadError once
+// it finished. Reads are served throughout, from whatever the import has
+// stored so far.
+func WithStartupLoad(svc SwiftService, load *StartupLoad) SwiftService {
+	return &loadingService{SwiftService: svc, load: load}
+}
+
+func (s *loadingService) DatasetStatus(ctx context.Context) (*DatasetStatus, error) {
+	status, err := s.SwiftService.DatasetStatus(ctx)
+	if err != nil {
+		return status, err
+	}
+	running, loadErr := s.load.state()
+	if !running && loadErr == nil {
+		return status, nil
+	}
+	reported := *status
+	if running {
+		reported.Status = DatasetLoading
+	} else {
+		reported.LoadError = loadErr.Error()
+	}
+	return &reported, nil
+}
@@ -0,0 +1,52 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+	mocks "github.com/zdziszkee/swift-codes/tests/mocks"
+)
+
+var _ = Describe("WithStartupLoad", func() {
+	It("should report loading with the counts so far until the load finishes", func() {
+		load := &service.StartupLoad{}
+		load.Begin()
+		svc := service.WithStartupLoad(&mocks.MockSwiftService{
+			DatasetStatusFunc: func(ctx context.Context) (*service.DatasetStatus, error) {
+				return &service.DatasetStatus{Status: service.DatasetReady, DatasetStats: repository.DatasetStats{TotalCodes: 42}}, nil
+			},
+		}, load)
+
+		status, err := svc.DatasetStatus(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Status).To(Equal(service.DatasetLoading))
+		Expect(status.TotalCodes).To(BeEquivalentTo(42))
+
+		load.Finish(nil)
+		status, err = svc.DatasetStatus(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Status).To(Equal(service.DatasetReady))
+		Expect(status.LoadError).To(BeEmpty())
+	})
+
+	It("should report the error of a failed load and keep the dataset state", func() {
+		load := &service.StartupLoad{}
+		load.Begin()
+		load.Finish(errors.New("golden dataset mismatch"))
+		svc := service.WithStartupLoad(&mocks.MockSwiftService{
+			DatasetStatusFunc: func(ctx context.Context) (*service.DatasetStatus, error) {
+				return &service.DatasetStatus{Status: service.DatasetReady}, nil
+			},
+		}, load)
+
+		status, err := svc.DatasetStatus(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Status).To(Equal(service.DatasetReady))
+		Expect(status.LoadError).To(Equal("golden dataset mismatch"))
+	})
+})
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	models "github.com/zdziszkee/swift-codes/internal/models"
+)
+
+// SwiftCodeBreakdown splits a SWIFT code into its ISO 9362 parts. It is
+// computed from the code alone, without consulting the repository.
+type SwiftCodeBreakdown struct {
+	SwiftCode     string `json:"swiftCode"`
+	FormatValid   bool   `json:"formatValid"`
+	Reason        string `json:"reason,omitempty"`
+	BankCode      string `json:"bankCode,omitempty"`
+	CountryISO2   string `json:"countryISO2,omitempty"`
+	LocationCode  string `json:"locationCode,omitempty"`
+	BranchCode    string `json:"branchCode,omitempty"`
+	IsHeadquarter bool   `json:"isHeadquarter"`
+}
+
+// ParseSwiftCode validates code with the same rules the service applies to
+// lookups and breaks it down into bank, country, location and branch.
+// A bare BIC8 is reported with the head-office branch code.
+func ParseSwiftCode(code string) SwiftCodeBreakdown {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	breakdown := SwiftCodeBreakdown{SwiftCode: code}
+
+	if len(code) != 8 && len(code) != 11 {
+		breakdown.Reason = fmt.Sprintf("length must be 8 or 11 characters, got %d", len(code))
+		return breakdown
+	}
+	if !swiftCodeRegex.MatchString(code) {
+		breakdown.Reason = "expected 6 letters followed by 2 or 5 letters or digits"
+		return breakdown
+	}
+
+	breakdown.FormatValid = true
+	breakdown.BankCode = code[:4]
+	breakdown.CountryISO2 = code[4:6]
+	breakdown.LocationCode = code[6:8]
+	breakdown.BranchCode = models.HeadquarterBranchCode
+	if len(code) == 11 {
+		breakdown.BranchCode = code[8:]
+	}
+	breakdown.IsHeadquarter = breakdown.BranchCode == models.HeadquarterBranchCode
+	return breakdown
+}
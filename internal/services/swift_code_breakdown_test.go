@@ -0,0 +1,37 @@
+package service_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+var _ = Describe("ParseSwiftCode", func() {
+	It("should break a branch code into its parts", func() {
+		Expect(service.ParseSwiftCode("bszlplp1abc")).To(Equal(service.SwiftCodeBreakdown{
+			SwiftCode:    "BSZLPLP1ABC",
+			FormatValid:  true,
+			BankCode:     "BSZL",
+			CountryISO2:  "PL",
+			LocationCode: "P1",
+			BranchCode:   "ABC",
+		}))
+	})
+
+	It("should treat a BIC8 as a headquarters", func() {
+		breakdown := service.ParseSwiftCode("BSZLPLP1")
+		Expect(breakdown.FormatValid).To(BeTrue())
+		Expect(breakdown.BranchCode).To(Equal("XXX"))
+		Expect(breakdown.IsHeadquarter).To(BeTrue())
+	})
+
+	It("should explain why a code is invalid", func() {
+		breakdown := service.ParseSwiftCode("BSZL1LP1XXX")
+		Expect(breakdown.FormatValid).To(BeFalse())
+		Expect(breakdown.Reason).NotTo(BeEmpty())
+		Expect(breakdown.BankCode).To(BeEmpty())
+
+		Expect(service.ParseSwiftCode("ABC").Reason).To(ContainSubstring("got 3"))
+	})
+})
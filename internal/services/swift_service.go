@@ -3,18 +3,13 @@ package service
 import (
 	"context"
 	"errors"
-	"log"
+	"fmt"
 	"regexp"
 	"strings"
 
 	models "github.com/zdziszkee/swift-codes/internal/models"
 	repository "github.com/zdziszkee/swift-codes/internal/repositories"
-)
-
-var (
-	ErrNotFound      = errors.New("swift code not found")
-	ErrInvalidInput  = errors.New("invalid input provided")
-	ErrAlreadyExists = errors.New("swift code already exists")
+	"github.com/zdziszkee/swift-codes/internal/requestid"
 )
 
 // SWIFT code validation regex - Updated to be more accurate
@@ -25,60 +20,170 @@ var countryCodeRegex = regexp.MustCompile(`^[A-Z]{2}$`)
 // SwiftService handles business logic for SWIFT codes
 type SwiftService interface {
 	GetSwiftCodeDetails(ctx context.Context, code string) (*repository.SwiftBankDetail, error)
-	GetSwiftCodesByCountry(ctx context.Context, countryCode string) (*repository.CountrySwiftCodes, error)
+	GetHeadquarters(ctx context.Context, code string) (*models.SwiftBank, error)
+	GetBankGroup(ctx context.Context, base string) (*repository.BankGroup, error)
+	GetSwiftCodesByCountry(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error)
 	CreateSwiftCode(ctx context.Context, bank *models.SwiftBank) error
 	DeleteSwiftCode(ctx context.Context, code string) error
+	DeleteSwiftCodesByCountry(ctx context.Context, countryCode string) (int64, error)
+	DatasetStatus(ctx context.Context) (*DatasetStatus, error)
+	Completeness(ctx context.Context) ([]CountryCompleteness, error)
+	CountryCounts(ctx context.Context) ([]repository.CountryCount, error)
+}
+
+// Dataset states reported by DatasetStatus
+const (
+	DatasetEmpty = "empty"
+	DatasetReady = "ready"
+	// DatasetLoading is reported while the startup import is still running
+	DatasetLoading = "loading"
+)
+
+// DatasetStatus describes whether SWIFT data has been loaded
+type DatasetStatus struct {
+	Status string `json:"status"`
+	repository.DatasetStats
+	// Maintenance is the active maintenance window, during which writes
+	// are refused
+	Maintenance *MaintenanceWindow `json:"maintenance,omitempty"`
+	// LoadError is the error of a failed startup import; the data stored
+	// before it is still served
+	LoadError string `json:"load_error,omitempty"`
+}
+
+// Config holds business-rule switches for the Swift service
+type Config struct {
+	// LegacyBICMatching disables the ISO 9362:2022 rule that a BIC8 and
+	// BIC8+XXX identify the same institution
+	LegacyBICMatching bool `koanf:"legacy_bic_matching"`
 }
 
 // swiftService implements SwiftService
 type swiftService struct {
-	repo repository.SwiftRepository
+	repo   repository.SwiftRepository
+	config Config
 }
 
 // NewSwiftService creates a new instance of the Swift service
-func NewSwiftService(repo repository.SwiftRepository) SwiftService {
-	return &swiftService{repo: repo}
+func NewSwiftService(repo repository.SwiftRepository, config ...Config) SwiftService {
+	s := &swiftService{repo: repo}
+	if len(config) > 0 {
+		s.config = config[0]
+	}
+	return s
+}
+
+// canonicalCode applies BIC8 primacy unless legacy matching is enabled
+func (s *swiftService) canonicalCode(code string) string {
+	if s.config.LegacyBICMatching {
+		return code
+	}
+	return models.CanonicalBIC(code)
+}
+
+// alternateCode returns the equivalent head-office spelling to fall back
+// on, or "" when there is none or legacy matching is enabled
+func (s *swiftService) alternateCode(code string) string {
+	if s.config.LegacyBICMatching {
+		return ""
+	}
+	return models.AlternateBIC(code)
 }
 
 // GetSwiftCodeDetails retrieves detailed info for a SWIFT code
 func (s *swiftService) GetSwiftCodeDetails(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
-	log.Printf("GetSwiftCodeDetails called with code: %s", code)
+	requestid.Logf(ctx, "GetSwiftCodeDetails called with code: %s", code)
 
 	// Convert to uppercase before validation
 	code = strings.ToUpper(code)
 
 	if !swiftCodeRegex.MatchString(code) {
-		log.Printf("Invalid swift code format: %s", code)
-		return nil, ErrInvalidInput
+		requestid.Logf(ctx, "Invalid swift code format: %s", code)
+		return nil, invalidInput("swiftCode", reasonSwiftCode)
 	}
 
-	bank, err := s.repo.GetByCode(ctx, code)
+	code = s.canonicalCode(code)
+	opts := branchWindow(ctx)
+	bank, err := s.repo.GetByCode(ctx, code, opts)
+	if errors.Is(err, repository.ErrNotFound) {
+		if alt := s.alternateCode(code); alt != "" {
+			bank, err = s.repo.GetByCode(ctx, alt, opts)
+		}
+	}
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
-			log.Printf("Swift code not found: %s", code)
-			return nil, ErrNotFound
+			requestid.Logf(ctx, "Swift code not found: %s", code)
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, code)
 		}
-		log.Printf("Error retrieving swift code details for %s: %v", code, err)
+		requestid.Logf(ctx, "Error retrieving swift code details for %s: %v", code, err)
 		return nil, err
 	}
 
-	log.Printf("Successfully retrieved swift code details for %s", code)
+	requestid.Logf(ctx, "Successfully retrieved swift code details for %s", code)
 	return bank, nil
 }
 
+// GetHeadquarters resolves the headquarters of a branch: the record with
+// the same first eight characters that is flagged as headquarters. The
+// branch itself need not be stored, and a headquarters code resolves to
+// itself.
+func (s *swiftService) GetHeadquarters(ctx context.Context, code string) (*models.SwiftBank, error) {
+	code = strings.ToUpper(code)
+	if !swiftCodeRegex.MatchString(code) {
+		return nil, invalidInput("swiftCode", reasonSwiftCode)
+	}
+
+	hq, err := s.repo.GetHeadquartersByBase(ctx, code[:8], repository.QueryOptions{})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			requestid.Logf(ctx, "Headquarters not found for %s", code)
+			return nil, fmt.Errorf("%w: headquarters of %s", ErrNotFound, code)
+		}
+		return nil, err
+	}
+	return hq, nil
+}
+
+// GetBankGroup retrieves the headquarters and branches sharing an 8-char
+// SWIFT code base. An 11-char code is accepted too and stands for its base.
+func (s *swiftService) GetBankGroup(ctx context.Context, base string) (*repository.BankGroup, error) {
+	base = strings.ToUpper(base)
+	if !swiftCodeRegex.MatchString(base) {
+		return nil, invalidInput("base", reasonSwiftCode)
+	}
+
+	group, err := s.repo.GetByBase(ctx, base[:8], repository.QueryOptions{})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			requestid.Logf(ctx, "Bank group not found for %s", base)
+			return nil, fmt.Errorf("%w: swift code base %s", ErrNotFound, base[:8])
+		}
+		return nil, err
+	}
+	return group, nil
+}
+
 // GetSwiftCodesByCountry retrieves all SWIFT codes for a country
-func (s *swiftService) GetSwiftCodesByCountry(ctx context.Context, countryCode string) (*repository.CountrySwiftCodes, error) {
+func (s *swiftService) GetSwiftCodesByCountry(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
 	// Convert to uppercase before validation
 	countryCode = strings.ToUpper(countryCode)
 
 	if !countryCodeRegex.MatchString(countryCode) {
-		return nil, ErrInvalidInput
+		return nil, invalidInput("countryISO2", reasonCountry)
 	}
 
-	codes, err := s.repo.GetByCountry(ctx, countryCode)
+	codes, err := s.repo.GetByCountry(ctx, countryCode, opts)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
-			return nil, ErrNotFound
+			// Before any data is loaded every country is unknown; answer
+			// with an empty list instead of a misleading 404
+			if stats, statsErr := s.repo.Stats(ctx); statsErr == nil && stats.Empty() {
+				return &repository.CountrySwiftCodes{
+					CountryISO2: countryCode,
+					SwiftCodes:  []models.SwiftBank{},
+				}, nil
+			}
+			return nil, fmt.Errorf("%w: country %s", ErrNotFound, countryCode)
 		}
 		return nil, err
 	}
@@ -86,11 +191,52 @@ func (s *swiftService) GetSwiftCodesByCountry(ctx context.Context, countryCode s
 	return codes, nil
 }
 
+// DeleteSwiftCodesByCountry removes every SWIFT code of a country and
+// returns how many were deleted
+func (s *swiftService) DeleteSwiftCodesByCountry(ctx context.Context, countryCode string) (int64, error) {
+	countryCode = strings.ToUpper(countryCode)
+	if !countryCodeRegex.MatchString(countryCode) {
+		return 0, invalidInput("countryISO2", reasonCountry)
+	}
+
+	if IsDryRun(ctx) {
+		codes, err := s.repo.GetByCountry(ctx, countryCode, repository.QueryOptions{Limit: 1})
+		if errors.Is(err, repository.ErrNotFound) {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		return int64(codes.Total), nil
+	}
+
+	deleted, err := s.repo.DeleteByCountry(ctx, countryCode)
+	if err != nil {
+		return 0, err
+	}
+	requestid.Logf(ctx, "Deleted %d SWIFT codes for country %s", deleted, countryCode)
+	return deleted, nil
+}
+
+// DatasetStatus reports whether the dataset is empty along with its counts
+func (s *swiftService) DatasetStatus(ctx context.Context) (*DatasetStatus, error) {
+	stats, err := s.repo.Stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &DatasetStatus{Status: DatasetReady, DatasetStats: *stats}
+	if stats.Empty() {
+		status.Status = DatasetEmpty
+	}
+	return status, nil
+}
+
 // CreateSwiftCode adds a new SWIFT code to the database
 func (s *swiftService) CreateSwiftCode(ctx context.Context, bank *models.SwiftBank) error {
 	// Check for nil bank to prevent panic
 	if bank == nil {
-		return ErrInvalidInput
+		return invalidInput("body", "is required")
 	}
 
 	// Convert to uppercase before validation
@@ -99,31 +245,40 @@ func (s *swiftService) CreateSwiftCode(ctx context.Context, bank *models.SwiftBa
 
 	// Validate SWIFT code
 	if !swiftCodeRegex.MatchString(bank.SwiftCode) {
-		return ErrInvalidInput
+		return invalidInput("swiftCode", reasonSwiftCode)
 	}
+	bank.SwiftCode = s.canonicalCode(bank.SwiftCode)
 
 	// Validate country code
 	if !countryCodeRegex.MatchString(bank.CountryISOCode) {
-		return ErrInvalidInput
+		return invalidInput("countryISO2", reasonCountry)
 	}
 
 	// Validate other fields
 	if bank.BankName == "" {
-		return ErrInvalidInput
+		return invalidInput("bankName", "is required")
 	}
 
-	// Set headquarter flag based on SWIFT code suffix
+	// The headquarter flag and the base are derived from the code, whatever
+	// the client sent
 	bank.IsHeadquarter = strings.HasSuffix(bank.SwiftCode, "XXX")
+	bank.SwiftCodeBase = bank.SwiftCode[:8]
+
+	if mode := WriteModeOf(ctx); mode != WriteCreate {
+		return s.replace(ctx, bank, mode)
+	}
 
-	// Set SwiftCodeBase if not set
-	if bank.SwiftCodeBase == "" {
-		bank.SwiftCodeBase = bank.SwiftCode[:8]
+	if err := s.checkUnique(ctx, bank.SwiftCode); err != nil {
+		return err
+	}
+	if IsDryRun(ctx) {
+		return nil
 	}
 
 	err := s.repo.Create(ctx, bank)
 	if err != nil {
 		if errors.Is(err, repository.ErrDuplicate) {
-			return ErrAlreadyExists
+			return fmt.Errorf("%w: %s", ErrAlreadyExists, bank.SwiftCode)
 		}
 		return err
 	}
@@ -131,19 +286,114 @@ func (s *swiftService) CreateSwiftCode(ctx context.Context, bank *models.SwiftBa
 	return nil
 }
 
+// replace writes bank over a stored code with the same SWIFT code in one
+// repository upsert, so the code is never missing in between. The town and
+// time zone of the stored row are kept unless bank sets them, and so are
+// its contacts. A new code is inserted by WriteUpsert and rejected with
+// ErrNotFound by WriteUpdate. A head office stored under its other BIC
+// spelling is rejected as in a plain create.
+func (s *swiftService) replace(ctx context.Context, bank *models.SwiftBank, mode WriteMode) error {
+	if alt := s.alternateCode(bank.SwiftCode); alt != "" {
+		_, err := s.repo.GetByCode(ctx, alt, existenceCheck)
+		switch {
+		case err == nil:
+			return fmt.Errorf("%w: as %s", ErrAlreadyExists, alt)
+		case !errors.Is(err, repository.ErrNotFound):
+			return err
+		}
+	}
+
+	stored, err := s.repo.GetByCode(ctx, bank.SwiftCode, existenceCheck)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	if stored == nil && mode == WriteUpdate {
+		return fmt.Errorf("%w: %s", ErrNotFound, bank.SwiftCode)
+	}
+	if IsDryRun(ctx) {
+		return nil
+	}
+
+	if stored != nil {
+		if bank.Town == "" {
+			bank.Town = stored.Bank.Town
+		}
+		if bank.TimeZone == "" {
+			bank.TimeZone = stored.Bank.TimeZone
+		}
+	}
+	_, err = s.repo.Upsert(ctx, []*models.SwiftBank{bank})
+	return err
+}
+
+// existenceCheck reads a code without its branches and past any cache, for
+// checks that decide whether a write may go ahead
+var existenceCheck = repository.QueryOptions{Consistency: repository.ConsistencyStrong, OmitBranches: true}
+
+// checkUnique rejects a code that collides with a stored one. The other
+// BIC spelling of a head office is checked first, as it names the same
+// institution. The code itself is only looked up in dry-run mode; otherwise
+// the repository's insert reports the duplicate. Aliases are checked by
+// WithAliases.
+func (s *swiftService) checkUnique(ctx context.Context, code string) error {
+	exists := func(code string) (bool, error) {
+		_, err := s.repo.GetByCode(ctx, code, existenceCheck)
+		if errors.Is(err, repository.ErrNotFound) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	if alt := s.alternateCode(code); alt != "" {
+		taken, err := exists(alt)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return fmt.Errorf("%w: as %s", ErrAlreadyExists, alt)
+		}
+	}
+
+	if IsDryRun(ctx) {
+		taken, err := exists(code)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return fmt.Errorf("%w: %s", ErrAlreadyExists, code)
+		}
+	}
+	return nil
+}
+
 // DeleteSwiftCode removes a SWIFT code from the database
 func (s *swiftService) DeleteSwiftCode(ctx context.Context, code string) error {
 	// Convert to uppercase before validation
 	code = strings.ToUpper(code)
 
 	if !swiftCodeRegex.MatchString(code) {
-		return ErrInvalidInput
+		return invalidInput("swiftCode", reasonSwiftCode)
 	}
 
-	err := s.repo.Delete(ctx, code)
+	code = s.canonicalCode(code)
+	remove := s.repo.Delete
+	if IsDryRun(ctx) {
+		// Only look the code up; the existence check is what a delete would report
+		remove = func(ctx context.Context, code string) error {
+			_, err := s.repo.GetByCode(ctx, code, existenceCheck)
+			return err
+		}
+	}
+
+	err := remove(ctx, code)
+	if errors.Is(err, repository.ErrNotFound) {
+		if alt := s.alternateCode(code); alt != "" {
+			err = remove(ctx, alt)
+		}
+	}
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
-			return ErrNotFound
+			return fmt.Errorf("%w: %s", ErrNotFound, code)
 		}
 		return err
 	}
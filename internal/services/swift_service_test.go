@@ -3,6 +3,7 @@ package service_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -45,7 +46,7 @@ var _ = Describe("SwiftService", func() {
 		Context("when called with a valid SWIFT code", func() {
 			It("should return the bank details", func() {
 				repo := &mocks.MockSwiftRepository{
-					GetByCodeFunc: func(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+					GetByCodeFunc: func(ctx context.Context, code string, opts repository.QueryOptions) (*repository.SwiftBankDetail, error) {
 						return &repository.SwiftBankDetail{
 							Bank:     models.SwiftBank{SwiftCode: "ABCDUS33XXX"},
 							Branches: []models.SwiftBank{},
@@ -64,6 +65,22 @@ var _ = Describe("SwiftService", func() {
 			})
 		})
 
+		Context("when called with a branch window", func() {
+			It("should read the window in code order", func() {
+				var got repository.QueryOptions
+				repo := &mocks.MockSwiftRepository{
+					GetByCodeFunc: func(ctx context.Context, code string, opts repository.QueryOptions) (*repository.SwiftBankDetail, error) {
+						got = opts
+						return &repository.SwiftBankDetail{Bank: models.SwiftBank{SwiftCode: code}}, nil
+					},
+				}
+
+				_, err := service.NewSwiftService(repo).GetSwiftCodeDetails(service.WithBranchWindow(ctx, 10, 20), "ABCDUS33XXX")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(got).To(Equal(repository.QueryOptions{Sort: repository.Sort{Field: repository.SortSwiftCode}, Limit: 10, Offset: 20}))
+			})
+		})
+
 		Context("when called with an invalid SWIFT code", func() {
 			It("should return an invalid input error", func() {
 				repo := &mocks.MockSwiftRepository{}
@@ -72,13 +89,16 @@ var _ = Describe("SwiftService", func() {
 				_, err := s.GetSwiftCodeDetails(ctx, "ABC123")
 
 				Expect(err).To(MatchError(service.ErrInvalidInput))
+				var inputErr *service.InputError
+				Expect(errors.As(err, &inputErr)).To(BeTrue())
+				Expect(inputErr.Field).To(Equal("swiftCode"))
 			})
 		})
 
 		Context("when the code is not found", func() {
 			It("should return not found error", func() {
 				repo := &mocks.MockSwiftRepository{
-					GetByCodeFunc: func(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+					GetByCodeFunc: func(ctx context.Context, code string, opts repository.QueryOptions) (*repository.SwiftBankDetail, error) {
 						return nil, repository.ErrNotFound
 					},
 				}
@@ -86,15 +106,50 @@ var _ = Describe("SwiftService", func() {
 				s := service.NewSwiftService(repo)
 				_, err := s.GetSwiftCodeDetails(ctx, "ABCDUS33XXX")
 
+				Expect(err).To(MatchError(service.ErrNotFound))
+				Expect(err.Error()).To(ContainSubstring("ABCDUS33XXX"))
+			})
+
+			It("should recognise repository errors wrapped by middlewares", func() {
+				repo := &mocks.MockSwiftRepository{
+					GetByCodeFunc: func(ctx context.Context, code string, opts repository.QueryOptions) (*repository.SwiftBankDetail, error) {
+						return nil, fmt.Errorf("after 3 attempts: %w", fmt.Errorf("%w: %s", repository.ErrNotFound, code))
+					},
+				}
+
+				s := service.NewSwiftService(repo)
+				_, err := s.GetSwiftCodeDetails(ctx, "ABCDUS33XXX")
+
 				Expect(err).To(MatchError(service.ErrNotFound))
 			})
 		})
 
+		Context("when the dataset is empty", func() {
+			It("should return an empty list instead of not found", func() {
+				repo := &mocks.MockSwiftRepository{
+					GetByCountryFunc: func(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+						return nil, repository.ErrNotFound
+					},
+					StatsFunc: func(ctx context.Context) (*repository.DatasetStats, error) {
+						return &repository.DatasetStats{}, nil
+					},
+				}
+
+				s := service.NewSwiftService(repo)
+				got, err := s.GetSwiftCodesByCountry(ctx, "us", repository.QueryOptions{})
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(got.CountryISO2).To(Equal("US"))
+				Expect(got.SwiftCodes).To(BeEmpty())
+				Expect(got.SwiftCodes).NotTo(BeNil())
+			})
+		})
+
 		Context("when repository returns an error", func() {
 			It("should return the error", func() {
 				expectedError := errors.New("db error")
 				repo := &mocks.MockSwiftRepository{
-					GetByCodeFunc: func(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+					GetByCodeFunc: func(ctx context.Context, code string, opts repository.QueryOptions) (*repository.SwiftBankDetail, error) {
 						return nil, expectedError
 					},
 				}
@@ -109,7 +164,7 @@ var _ = Describe("SwiftService", func() {
 		Context("when called with a valid 8-character SWIFT code", func() {
 			It("should return the bank details", func() {
 				repo := &mocks.MockSwiftRepository{
-					GetByCodeFunc: func(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+					GetByCodeFunc: func(ctx context.Context, code string, opts repository.QueryOptions) (*repository.SwiftBankDetail, error) {
 						return &repository.SwiftBankDetail{
 							Bank:     models.SwiftBank{SwiftCode: "ABCDUS33"},
 							Branches: []models.SwiftBank{},
@@ -129,11 +184,75 @@ var _ = Describe("SwiftService", func() {
 		})
 	})
 
+	Describe("GetHeadquarters", func() {
+		It("should look up the headquarters by the first eight characters", func() {
+			var base string
+			repo := &mocks.MockSwiftRepository{
+				GetHeadquartersByBaseFunc: func(ctx context.Context, hqBase string, opts repository.QueryOptions) (*models.SwiftBank, error) {
+					base = hqBase
+					return &models.SwiftBank{SwiftCode: "ABCDUS33XXX", IsHeadquarter: true}, nil
+				},
+			}
+
+			got, err := service.NewSwiftService(repo).GetHeadquarters(ctx, "abcdus33nyc")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(base).To(Equal("ABCDUS33"))
+			Expect(got.SwiftCode).To(Equal("ABCDUS33XXX"))
+		})
+
+		It("should report a missing headquarters as not found", func() {
+			repo := &mocks.MockSwiftRepository{
+				GetHeadquartersByBaseFunc: func(ctx context.Context, hqBase string, opts repository.QueryOptions) (*models.SwiftBank, error) {
+					return nil, repository.ErrNotFound
+				},
+			}
+
+			_, err := service.NewSwiftService(repo).GetHeadquarters(ctx, "ABCDUS33NYC")
+			Expect(err).To(MatchError(service.ErrNotFound))
+
+			_, err = service.NewSwiftService(repo).GetHeadquarters(ctx, "ABC")
+			Expect(err).To(MatchError(service.ErrInvalidInput))
+		})
+	})
+
+	Describe("GetBankGroup", func() {
+		It("should look up the group by the first eight characters of any of its codes", func() {
+			var bases []string
+			repo := &mocks.MockSwiftRepository{
+				GetByBaseFunc: func(ctx context.Context, base string, opts repository.QueryOptions) (*repository.BankGroup, error) {
+					bases = append(bases, base)
+					return &repository.BankGroup{SwiftCodeBase: base}, nil
+				},
+			}
+			svc := service.NewSwiftService(repo)
+
+			_, err := svc.GetBankGroup(ctx, "abcdus33")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = svc.GetBankGroup(ctx, "ABCDUS33NYC")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(bases).To(Equal([]string{"ABCDUS33", "ABCDUS33"}))
+		})
+
+		It("should reject invalid bases and report unknown ones as not found", func() {
+			repo := &mocks.MockSwiftRepository{
+				GetByBaseFunc: func(ctx context.Context, base string, opts repository.QueryOptions) (*repository.BankGroup, error) {
+					return nil, repository.ErrNotFound
+				},
+			}
+
+			_, err := service.NewSwiftService(repo).GetBankGroup(ctx, "ABCDUS33")
+			Expect(err).To(MatchError(service.ErrNotFound))
+
+			_, err = service.NewSwiftService(repo).GetBankGroup(ctx, "ABCD")
+			Expect(err).To(MatchError(service.ErrInvalidInput))
+		})
+	})
+
 	Describe("GetSwiftCodesByCountry", func() {
 		Context("when called with a valid country code", func() {
 			It("should return the country codes", func() {
 				repo := &mocks.MockSwiftRepository{
-					GetByCountryFunc: func(ctx context.Context, countryCode string) (*repository.CountrySwiftCodes, error) {
+					GetByCountryFunc: func(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
 						return &repository.CountrySwiftCodes{
 							SwiftCodes: []models.SwiftBank{},
 						}, nil
@@ -141,7 +260,7 @@ var _ = Describe("SwiftService", func() {
 				}
 
 				s := service.NewSwiftService(repo)
-				got, err := s.GetSwiftCodesByCountry(ctx, "US")
+				got, err := s.GetSwiftCodesByCountry(ctx, "US", repository.QueryOptions{})
 
 				Expect(err).ToNot(HaveOccurred())
 				Expect(got).To(Equal(&repository.CountrySwiftCodes{
@@ -155,7 +274,7 @@ var _ = Describe("SwiftService", func() {
 				repo := &mocks.MockSwiftRepository{}
 				s := service.NewSwiftService(repo)
 
-				_, err := s.GetSwiftCodesByCountry(ctx, "USA")
+				_, err := s.GetSwiftCodesByCountry(ctx, "USA", repository.QueryOptions{})
 
 				Expect(err).To(MatchError(service.ErrInvalidInput))
 			})
@@ -166,7 +285,7 @@ var _ = Describe("SwiftService", func() {
 				repo := &mocks.MockSwiftRepository{}
 				s := service.NewSwiftService(repo)
 
-				_, err := s.GetSwiftCodesByCountry(ctx, "")
+				_, err := s.GetSwiftCodesByCountry(ctx, "", repository.QueryOptions{})
 
 				Expect(err).To(MatchError(service.ErrInvalidInput))
 			})
@@ -175,13 +294,13 @@ var _ = Describe("SwiftService", func() {
 		Context("when the country code is not found", func() {
 			It("should return not found error", func() {
 				repo := &mocks.MockSwiftRepository{
-					GetByCountryFunc: func(ctx context.Context, countryCode string) (*repository.CountrySwiftCodes, error) {
+					GetByCountryFunc: func(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
 						return nil, repository.ErrNotFound
 					},
 				}
 
 				s := service.NewSwiftService(repo)
-				_, err := s.GetSwiftCodesByCountry(ctx, "US")
+				_, err := s.GetSwiftCodesByCountry(ctx, "US", repository.QueryOptions{})
 
 				Expect(err).To(MatchError(service.ErrNotFound))
 			})
@@ -191,13 +310,13 @@ var _ = Describe("SwiftService", func() {
 			It("should return the error", func() {
 				expectedError := errors.New("db error")
 				repo := &mocks.MockSwiftRepository{
-					GetByCountryFunc: func(ctx context.Context, countryCode string) (*repository.CountrySwiftCodes, error) {
+					GetByCountryFunc: func(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
 						return nil, expectedError
 					},
 				}
 
 				s := service.NewSwiftService(repo)
-				_, err := s.GetSwiftCodesByCountry(ctx, "US")
+				_, err := s.GetSwiftCodesByCountry(ctx, "US", repository.QueryOptions{})
 
 				Expect(err.Error()).To(Equal(expectedError.Error()))
 			})
@@ -206,7 +325,7 @@ var _ = Describe("SwiftService", func() {
 		Context("when called with a lowercase country code", func() {
 			It("should convert and return the codes", func() {
 				repo := &mocks.MockSwiftRepository{
-					GetByCountryFunc: func(ctx context.Context, countryCode string) (*repository.CountrySwiftCodes, error) {
+					GetByCountryFunc: func(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
 						countryCode = strings.ToUpper(countryCode)
 						if countryCode == "US" {
 							return &repository.CountrySwiftCodes{
@@ -218,7 +337,7 @@ var _ = Describe("SwiftService", func() {
 				}
 
 				s := service.NewSwiftService(repo)
-				got, err := s.GetSwiftCodesByCountry(ctx, "us")
+				got, err := s.GetSwiftCodesByCountry(ctx, "us", repository.QueryOptions{})
 
 				Expect(err).ToNot(HaveOccurred())
 				Expect(got).To(Equal(&repository.CountrySwiftCodes{
@@ -236,7 +355,7 @@ var _ = Describe("SwiftService", func() {
 				}
 
 				s := service.NewSwiftService(repo)
-				bank := &models.SwiftBank{SwiftCode: "ABCDUS33XXX", CountryISOCode: "US", BankName: "Test Bank"}
+				bank := &models.SwiftBank{SwiftCode: "ABCDUS33XXX", SwiftCodeBase: "WXYZUS33", CountryISOCode: "US", BankName: "Test Bank"}
 				err := s.CreateSwiftCode(ctx, bank)
 
 				Expect(err).ToNot(HaveOccurred())
@@ -299,6 +418,53 @@ var _ = Describe("SwiftService", func() {
 			})
 		})
 
+		Context("when called in upsert mode", func() {
+			It("should replace a stored code in one upsert, keeping every column", func() {
+				repo := repository.NewMemorySwiftRepository()
+				Expect(repo.CreateBatch(ctx, []*models.SwiftBank{{
+					SwiftCode: "ABCDUS33XXX", CountryISOCode: "US", BankName: "Old Bank", IsHeadquarter: true,
+					Address: "1 MAIN ST", CountryName: "UNITED STATES", Town: "NEW YORK", TimeZone: "America/New_York",
+				}})).To(Succeed())
+				_, err := repo.UpdateContacts(ctx, []models.BankContact{{SwiftCode: "ABCDUS33XXX", Website: "https://abcd.example", Phone: "+1 555 0100"}})
+				Expect(err).NotTo(HaveOccurred())
+				var writes []string
+				s := service.NewSwiftService(repository.Chain(repo, repository.Intercept(func(ctx context.Context, op string, call func(ctx context.Context) error) error {
+					if op != repository.OpGetByCode {
+						writes = append(writes, op)
+					}
+					return call(ctx)
+				})))
+
+				bank := &models.SwiftBank{SwiftCode: "ABCDUS33XXX", CountryISOCode: "US", BankName: "New Bank", Address: "2 MAIN ST", CountryName: "UNITED STATES"}
+				Expect(s.CreateSwiftCode(service.WithWriteMode(ctx, service.WriteUpsert), bank)).To(Succeed())
+				Expect(writes).To(Equal([]string{repository.OpUpsert}))
+
+				detail, err := repo.GetByCode(ctx, "ABCDUS33XXX", repository.QueryOptions{OmitBranches: true})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(detail.Bank).To(Equal(models.SwiftBank{
+					SwiftCode: "ABCDUS33XXX", SwiftCodeBase: "ABCDUS33", CountryISOCode: "US", BankName: "New Bank", IsHeadquarter: true,
+					Address: "2 MAIN ST", CountryName: "UNITED STATES", Town: "NEW YORK", TimeZone: "America/New_York",
+					Website: "https://abcd.example", Phone: "+1 555 0100",
+				}))
+			})
+		})
+
+		Context("when called in update mode for a new code", func() {
+			It("should return a not found error without writing", func() {
+				repo := &mocks.MockSwiftRepository{
+					GetByCodeFunc: func(ctx context.Context, code string, opts repository.QueryOptions) (*repository.SwiftBankDetail, error) {
+						return nil, repository.ErrNotFound
+					},
+				}
+
+				s := service.NewSwiftService(repo)
+				bank := &models.SwiftBank{SwiftCode: "ABCDUS33XXX", CountryISOCode: "US", BankName: "Test Bank"}
+				err := s.CreateSwiftCode(service.WithWriteMode(ctx, service.WriteUpdate), bank)
+
+				Expect(err).To(MatchError(service.ErrNotFound))
+			})
+		})
+
 		Context("when bank is nil", func() {
 			It("should return an invalid input error", func() {
 				repo := &mocks.MockSwiftRepository{}
@@ -349,6 +515,88 @@ var _ = Describe("SwiftService", func() {
 		})
 	})
 
+	Describe("DeleteSwiftCodesByCountry", func() {
+		It("should return the number of deleted codes", func() {
+			var got string
+			repo := &mocks.MockSwiftRepository{
+				DeleteByCountryFunc: func(ctx context.Context, countryCode string) (int64, error) {
+					got = countryCode
+					return 7, nil
+				},
+			}
+
+			deleted, err := service.NewSwiftService(repo).DeleteSwiftCodesByCountry(ctx, "de")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deleted).To(Equal(int64(7)))
+			Expect(got).To(Equal("DE"))
+		})
+
+		It("should reject an invalid country code", func() {
+			repo := &mocks.MockSwiftRepository{}
+
+			_, err := service.NewSwiftService(repo).DeleteSwiftCodesByCountry(ctx, "DEU")
+
+			Expect(err).To(MatchError(service.ErrInvalidInput))
+		})
+	})
+
+	Describe("dry run", func() {
+		BeforeEach(func() {
+			ctx = service.WithDryRun(ctx)
+		})
+
+		// Create, Delete and DeleteByCountry are left unset so any write panics
+		It("should validate a create and report a conflict without writing", func() {
+			repo := &mocks.MockSwiftRepository{
+				GetByCodeFunc: func(ctx context.Context, code string, opts repository.QueryOptions) (*repository.SwiftBankDetail, error) {
+					if code == "ABCDUS33XXX" {
+						return &repository.SwiftBankDetail{}, nil
+					}
+					return nil, repository.ErrNotFound
+				},
+			}
+			s := service.NewSwiftService(repo)
+
+			Expect(s.CreateSwiftCode(ctx, &models.SwiftBank{SwiftCode: "ABCDUS33XXX", CountryISOCode: "US", BankName: "Test Bank"})).
+				To(MatchError(service.ErrAlreadyExists))
+			Expect(s.CreateSwiftCode(ctx, &models.SwiftBank{SwiftCode: "ABCDUS33123", CountryISOCode: "US", BankName: "Test Bank"})).
+				To(Succeed())
+			Expect(s.CreateSwiftCode(ctx, &models.SwiftBank{SwiftCode: "ABC", CountryISOCode: "US", BankName: "Test Bank"})).
+				To(MatchError(service.ErrInvalidInput))
+		})
+
+		It("should check that a code exists instead of deleting it", func() {
+			repo := &mocks.MockSwiftRepository{
+				GetByCodeFunc: func(ctx context.Context, code string, opts repository.QueryOptions) (*repository.SwiftBankDetail, error) {
+					if code == "ABCDUS33XXX" {
+						return &repository.SwiftBankDetail{}, nil
+					}
+					return nil, repository.ErrNotFound
+				},
+			}
+			s := service.NewSwiftService(repo)
+
+			Expect(s.DeleteSwiftCode(ctx, "ABCDUS33XXX")).To(Succeed())
+			Expect(s.DeleteSwiftCode(ctx, "ABCDUS33123")).To(MatchError(service.ErrNotFound))
+		})
+
+		It("should count the codes a country delete would remove", func() {
+			repo := &mocks.MockSwiftRepository{
+				GetByCountryFunc: func(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+					if countryCode == "DE" {
+						return &repository.CountrySwiftCodes{Total: 7}, nil
+					}
+					return nil, repository.ErrNotFound
+				},
+			}
+			s := service.NewSwiftService(repo)
+
+			Expect(s.DeleteSwiftCodesByCountry(ctx, "de")).To(Equal(int64(7)))
+			Expect(s.DeleteSwiftCodesByCountry(ctx, "FR")).To(Equal(int64(0)))
+		})
+	})
+
 	Describe("DeleteSwiftCode", func() {
 		Context("when called with a valid SWIFT code", func() {
 			It("should delete the bank", func() {
@@ -423,4 +671,124 @@ var _ = Describe("SwiftService", func() {
 			})
 		})
 	})
+
+	Describe("BIC8 primacy", func() {
+		Context("when looking up a BIC8", func() {
+			It("should query the equivalent BIC11 head office", func() {
+				var queried []string
+				repo := &mocks.MockSwiftRepository{
+					GetByCodeFunc: func(ctx context.Context, code string, opts repository.QueryOptions) (*repository.SwiftBankDetail, error) {
+						queried = append(queried, code)
+						return &repository.SwiftBankDetail{Bank: models.SwiftBank{SwiftCode: code}}, nil
+					},
+				}
+
+				s := service.NewSwiftService(repo)
+				got, err := s.GetSwiftCodeDetails(ctx, "ABCDUS33")
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(got.Bank.SwiftCode).To(Equal("ABCDUS33XXX"))
+				Expect(queried).To(Equal([]string{"ABCDUS33XXX"}))
+			})
+
+			It("should fall back to a head office stored as a bare BIC8", func() {
+				repo := &mocks.MockSwiftRepository{
+					GetByCodeFunc: func(ctx context.Context, code string, opts repository.QueryOptions) (*repository.SwiftBankDetail, error) {
+						if code == "ABCDUS33" {
+							return &repository.SwiftBankDetail{Bank: models.SwiftBank{SwiftCode: code}}, nil
+						}
+						return nil, repository.ErrNotFound
+					},
+				}
+
+				s := service.NewSwiftService(repo)
+				got, err := s.GetSwiftCodeDetails(ctx, "ABCDUS33XXX")
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(got.Bank.SwiftCode).To(Equal("ABCDUS33"))
+			})
+		})
+
+		Context("when creating a BIC8", func() {
+			It("should store it as a BIC11 head office", func() {
+				repo := &mocks.MockSwiftRepository{
+					CreateFunc: func(ctx context.Context, bank *models.SwiftBank) error { return nil },
+				}
+
+				s := service.NewSwiftService(repo)
+				bank := &models.SwiftBank{SwiftCode: "ABCDUS33", CountryISOCode: "US", BankName: "Test Bank"}
+				err := s.CreateSwiftCode(ctx, bank)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(bank.SwiftCode).To(Equal("ABCDUS33XXX"))
+				Expect(bank.IsHeadquarter).To(BeTrue())
+			})
+
+			It("should detect a duplicate stored under the other spelling", func() {
+				repo := &mocks.MockSwiftRepository{
+					GetByCodeFunc: func(ctx context.Context, code string, opts repository.QueryOptions) (*repository.SwiftBankDetail, error) {
+						if code == "ABCDUS33" {
+							return &repository.SwiftBankDetail{Bank: models.SwiftBank{SwiftCode: code}}, nil
+						}
+						return nil, repository.ErrNotFound
+					},
+					CreateFunc: func(ctx context.Context, bank *models.SwiftBank) error {
+						return errors.New("create should not be called")
+					},
+				}
+
+				s := service.NewSwiftService(repo)
+				bank := &models.SwiftBank{SwiftCode: "ABCDUS33XXX", CountryISOCode: "US", BankName: "Test Bank"}
+				err := s.CreateSwiftCode(ctx, bank)
+
+				Expect(err).To(MatchError(service.ErrAlreadyExists))
+				Expect(err).NotTo(MatchError(service.ErrAliasConflict))
+			})
+		})
+
+		Context("when legacy matching is enabled", func() {
+			It("should treat BIC8 and BIC11 as distinct codes", func() {
+				var queried []string
+				repo := &mocks.MockSwiftRepository{
+					GetByCodeFunc: func(ctx context.Context, code string, opts repository.QueryOptions) (*repository.SwiftBankDetail, error) {
+						queried = append(queried, code)
+						return nil, repository.ErrNotFound
+					},
+				}
+
+				s := service.NewSwiftService(repo, service.Config{LegacyBICMatching: true})
+				_, err := s.GetSwiftCodeDetails(ctx, "ABCDUS33")
+
+				Expect(err).To(MatchError(service.ErrNotFound))
+				Expect(queried).To(Equal([]string{"ABCDUS33"}))
+			})
+		})
+	})
+})
+
+var _ = Describe("DatasetStatus", func() {
+	It("should report an empty dataset", func() {
+		repo := &mocks.MockSwiftRepository{
+			StatsFunc: func(ctx context.Context) (*repository.DatasetStats, error) {
+				return &repository.DatasetStats{}, nil
+			},
+		}
+
+		status, err := service.NewSwiftService(repo).DatasetStatus(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Status).To(Equal(service.DatasetEmpty))
+	})
+
+	It("should report a loaded dataset with its counts", func() {
+		repo := &mocks.MockSwiftRepository{
+			StatsFunc: func(ctx context.Context) (*repository.DatasetStats, error) {
+				return &repository.DatasetStats{TotalCodes: 3, Headquarters: 1, Branches: 2, Countries: 1}, nil
+			},
+		}
+
+		status, err := service.NewSwiftService(repo).DatasetStatus(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Status).To(Equal(service.DatasetReady))
+		Expect(status.TotalCodes).To(Equal(int64(3)))
+	})
 })
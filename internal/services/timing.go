@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+
+	models "github.com/zdziszkee/swift-codes/internal/models"
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	"github.com/zdziszkee/swift-codes/internal/timing"
+)
+
+// timedService records every call in the service stage of the request
+// timings
+type timedService struct {
+	SwiftService
+}
+
+// WithTiming wraps svc so that its calls show up in the Server-Timing header
+func WithTiming(svc SwiftService) SwiftService {
+	return &timedService{SwiftService: svc}
+}
+
+func (s *timedService) GetSwiftCodeDetails(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
+	defer timing.Track(ctx, timing.StageService)()
+	return s.SwiftService.GetSwiftCodeDetails(ctx, code)
+}
+
+func (s *timedService) GetHeadquarters(ctx context.Context, code string) (*models.SwiftBank, error) {
+	defer timing.Track(ctx, timing.StageService)()
+	return s.SwiftService.GetHeadquarters(ctx, code)
+}
+
+func (s *timedService) GetBankGroup(ctx context.Context, base string) (*repository.BankGroup, error) {
+	defer timing.Track(ctx, timing.StageService)()
+	return s.SwiftService.GetBankGroup(ctx, base)
+}
+
+func (s *timedService) GetSwiftCodesByCountry(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+	defer timing.Track(ctx, timing.StageService)()
+	return s.SwiftService.GetSwiftCodesByCountry(ctx, countryCode, opts)
+}
+
+func (s *timedService) CreateSwiftCode(ctx context.Context, bank *models.SwiftBank) error {
+	defer timing.Track(ctx, timing.StageService)()
+	return s.SwiftService.CreateSwiftCode(ctx, bank)
+}
+
+func (s *timedService) DeleteSwiftCode(ctx context.Context, code string) error {
+	defer timing.Track(ctx, timing.StageService)()
+	return s.SwiftService.DeleteSwiftCode(ctx, code)
+}
+
+func (s *timedService) DeleteSwiftCodesByCountry(ctx context.Context, countryCode string) (int64, error) {
+	defer timing.Track(ctx, timing.StageService)()
+	return s.SwiftService.DeleteSwiftCodesByCountry(ctx, countryCode)
+}
+
+func (s *timedService) DatasetStatus(ctx context.Context) (*DatasetStatus, error) {
+	defer timing.Track(ctx, timing.StageService)()
+	return s.SwiftService.DatasetStatus(ctx)
+}
+
+func (s *timedService) Completeness(ctx context.Context) ([]CountryCompleteness, error) {
+	defer timing.Track(ctx, timing.StageService)()
+	return s.SwiftService.Completeness(ctx)
+}
+
+func (s *timedService) CountryCounts(ctx context.Context) ([]repository.CountryCount, error) {
+	defer timing.Track(ctx, timing.StageService)()
+	return s.SwiftService.CountryCounts(ctx)
+}
+
+var _ SwiftService = (*timedService)(nil)
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+)
+
+// Per-country outcomes of a cache warm-up
+const (
+	WarmWarmed   = "warmed"
+	WarmNotFound = "not_found"
+	WarmFailed   = "failed"
+)
+
+// WarmResult is the outcome of warming the cache for one country
+type WarmResult struct {
+	Country    string `json:"country"`
+	Status     string `json:"status"`
+	Codes      int    `json:"codes"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// WarmCountries reads the full listing of every country through svc, one at
+// a time so Trino sees no more load than a single client, which leaves the
+// listings in the repository cache for the requests that follow. svc should
+// be the service below access statistics so that warming does not count as
+// traffic. A country that fails does not stop the others; only the end of
+// ctx does, and the countries not reached are reported as failed.
+func WarmCountries(ctx context.Context, svc SwiftService, countries []string) []WarmResult {
+	results := make([]WarmResult, 0, len(countries))
+	for _, country := range countries {
+		country = strings.ToUpper(country)
+		if err := ctx.Err(); err != nil {
+			results = append(results, WarmResult{Country: country, Status: WarmFailed, Error: err.Error()})
+			continue
+		}
+
+		start := time.Now()
+		codes, err := svc.GetSwiftCodesByCountry(ctx, country, repository.QueryOptions{})
+		result := WarmResult{Country: country, DurationMs: time.Since(start).Milliseconds()}
+		switch {
+		case errors.Is(err, ErrNotFound):
+			result.Status = WarmNotFound
+		case err != nil:
+			result.Status = WarmFailed
+			result.Error = err.Error()
+		default:
+			result.Status = WarmWarmed
+			result.Codes = len(codes.SwiftCodes)
+		}
+		results = append(results, result)
+	}
+	return results
+}
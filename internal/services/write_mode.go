@@ -0,0 +1,32 @@
+package service
+
+import "context"
+
+// WriteMode selects what CreateSwiftCode does when the code is already
+// stored
+type WriteMode int
+
+const (
+	// WriteCreate only inserts new codes and fails with ErrAlreadyExists
+	// otherwise; it is the default
+	WriteCreate WriteMode = iota
+	// WriteUpsert replaces a stored code or inserts a new one
+	WriteUpsert
+	// WriteUpdate only replaces stored codes and fails with ErrNotFound
+	// otherwise
+	WriteUpdate
+)
+
+type writeModeKey struct{}
+
+// WithWriteMode marks ctx so that CreateSwiftCode writes in mode
+func WithWriteMode(ctx context.Context, mode WriteMode) context.Context {
+	return context.WithValue(ctx, writeModeKey{}, mode)
+}
+
+// WriteModeOf returns the mode ctx was marked with by WithWriteMode, or
+// WriteCreate
+func WriteModeOf(ctx context.Context) WriteMode {
+	mode, _ := ctx.Value(writeModeKey{}).(WriteMode)
+	return mode
+}
@@ -0,0 +1,397 @@
+// Package sftpfeed pulls SWIFT codes files that institutions drop on an SFTP
+// server and hands every new one to the import pipeline.
+package sftpfeed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// Config describes the SFTP server and the files of the feed
+type Config struct {
+	// Enabled polls the server for new files
+	Enabled bool `koanf:"enabled"`
+	// Address is the host:port of the SFTP server
+	Address string `koanf:"address"`
+	User    string `koanf:"user"`
+	// KeyFile is the PEM private key used to authenticate
+	KeyFile string `koanf:"key_file"`
+	// KnownHostsFile lists the accepted host keys of the server in
+	// OpenSSH known_hosts format
+	KnownHostsFile string `koanf:"known_hosts_file"`
+	// Dir is the remote directory that is listed
+	Dir string `koanf:"dir"`
+	// Pattern selects the files of Dir to import, e.g. "swift_*.csv"
+	Pattern string `koanf:"pattern"`
+	// Interval is how often the directory is listed
+	Interval time.Duration `koanf:"interval"`
+	// Timeout bounds the SFTP work of a poll: connecting, listing and
+	// downloading. The imports of the downloaded files run without it.
+	Timeout time.Duration `koanf:"timeout"`
+	// MaxFileSize rejects files larger than this many bytes; 0 accepts any
+	MaxFileSize int64 `koanf:"max_file_size"`
+	// MaxAttempts quarantines a file after it failed this many polls: it is
+	// marked as seen and no longer retried. 0 retries failed files forever.
+	MaxAttempts int `koanf:"max_attempts"`
+	// ImportExisting also imports the files already present at start-up;
+	// by default the first poll only records them as seen
+	ImportExisting bool `koanf:"import_existing"`
+	// SettleInterval holds back files modified less than this long ago, as
+	// they may still be uploading
+	SettleInterval time.Duration `koanf:"settle_interval"`
+}
+
+// ErrFileTooLarge is returned for files over MaxFileSize
+var ErrFileTooLarge = errors.New("sftp: file too large")
+
+// Validate checks an enabled feed for missing settings
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Address == "" || c.User == "" || c.KeyFile == "" || c.KnownHostsFile == "" || c.Dir == "" {
+		return errors.New("sftp address, user, key_file, known_hosts_file and dir are required when enabled")
+	}
+	if _, err := path.Match(c.Pattern, ""); err != nil {
+		return fmt.Errorf("sftp pattern %q: %w", c.Pattern, err)
+	}
+	if c.Interval <= 0 || c.Timeout < 0 || c.SettleInterval < 0 {
+		return errors.New("sftp interval must be positive and timeout and settle_interval cannot be negative")
+	}
+	if c.MaxFileSize < 0 || c.MaxAttempts < 0 {
+		return errors.New("sftp max_file_size and max_attempts cannot be negative")
+	}
+	return nil
+}
+
+// DialFunc opens an SFTP session; closing the client ends it
+type DialFunc func(ctx context.Context) (*sftp.Client, error)
+
+// SSHDialer returns a DialFunc that authenticates with the configured key
+// and only accepts host keys listed in the known hosts file
+func SSHDialer(cfg Config) (DialFunc, error) {
+	pem, err := os.ReadFile(cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("read sftp key: %w", err)
+	}
+	signer, err := ssh.ParsePrivateKey(pem)
+	if err != nil {
+		return nil, fmt.Errorf("parse sftp key: %w", err)
+	}
+	hostKeys, err := knownhosts.New(cfg.KnownHostsFile)
+	if err != nil {
+		return nil, fmt.Errorf("read sftp known hosts: %w", err)
+	}
+	sshConfig := &ssh.ClientConfig{
+		User:            cfg.User,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeys,
+	}
+
+	return func(ctx context.Context) (*sftp.Client, error) {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", cfg.Address)
+		if err != nil {
+			return nil, fmt.Errorf("dial sftp: %w", err)
+		}
+		sshConn, chans, reqs, err := ssh.NewClientConn(conn, cfg.Address, sshConfig)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("ssh handshake: %w", err)
+		}
+		sshClient := ssh.NewClient(sshConn, chans, reqs)
+		client, err := sftp.NewClient(sshClient)
+		if err != nil {
+			sshClient.Close()
+			return nil, fmt.Errorf("start sftp subsystem: %w", err)
+		}
+		// Closing the sftp client only ends its session; drop the whole
+		// connection with it
+		go func() {
+			_ = client.Wait()
+			sshClient.Close()
+		}()
+		return client, nil
+	}, nil
+}
+
+// ImportFunc loads one downloaded file
+type ImportFunc func(ctx context.Context, name string, r io.Reader) error
+
+// version identifies the content of a listed file by its size and
+// modification time
+type version struct {
+	size    int64
+	modTime time.Time
+}
+
+func versionOf(file os.FileInfo) version {
+	return version{size: file.Size(), modTime: file.ModTime()}
+}
+
+func (v version) equal(other version) bool {
+	return v.size == other.size && v.modTime.Equal(other.modTime)
+}
+
+// Fetcher lists the feed directory and imports files it has not seen,
+// oldest first. A file is seen by name, size and modification time, so one
+// replaced under the same name is imported again. Seen files and failures
+// are only remembered in memory.
+type Fetcher struct {
+	config Config
+	dial   DialFunc
+	// paused skips the polls of Run while it returns true
+	paused func() bool
+
+	mu     sync.Mutex
+	primed bool
+	// seen holds the version of every listed file that was imported or
+	// quarantined, and listed the versions of the previous listing
+	seen   map[string]version
+	listed map[string]version
+	// failures counts the failed polls of files that are retried
+	failures map[string]int
+}
+
+// NewFetcher creates a fetcher that opens sessions with dial
+func NewFetcher(cfg Config, dial DialFunc) *Fetcher {
+	return &Fetcher{config: cfg, dial: dial, primed: cfg.ImportExisting, seen: map[string]version{}}
+}
+
+// PauseWhile makes Run skip its polls while paused returns true, such as
+// during maintenance windows. Files delivered meanwhile are imported by the
+// first poll after the pause.
+func (f *Fetcher) PauseWhile(paused func() bool) *Fetcher {
+	f.paused = paused
+	return f
+}
+
+// Poll imports the new files of the feed and returns how many were
+// imported. All new files are downloaded first, within Timeout, and then
+// imported. A file that fails to download or import does not hold back the
+// others: it is retried on the next polls until MaxAttempts, and the
+// failures are returned together.
+func (f *Fetcher) Poll(ctx context.Context, load ImportFunc) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	downloads, errs, err := f.download(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	imported := 0
+	for _, d := range downloads {
+		if err := load(ctx, d.name, bytes.NewReader(d.content)); err != nil {
+			errs = append(errs, f.fail(d.file, fmt.Errorf("import %s: %w", d.name, err)))
+			continue
+		}
+		f.markSeen(d.file)
+		delete(f.failures, d.file.Name())
+		imported++
+	}
+	return imported, errors.Join(errs...)
+}
+
+// download is a file read by a poll
+type download struct {
+	file    os.FileInfo
+	name    string
+	content []byte
+}
+
+// download lists the feed and reads its new files in one SFTP session
+// bounded by Timeout. It returns the files read and the failures of the
+// others, or an error when the feed could not be listed.
+func (f *Fetcher) download(ctx context.Context) ([]download, []error, error) {
+	if f.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.config.Timeout)
+		defer cancel()
+	}
+	client, err := f.dial(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer client.Close()
+	// SFTP reads block, so a cancelled poll drops the connection
+	stop := context.AfterFunc(ctx, func() { client.Close() })
+	defer stop()
+
+	entries, err := client.ReadDir(f.config.Dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	files := f.newFiles(entries)
+	if !f.primed {
+		for _, file := range files {
+			f.markSeen(file)
+		}
+		f.primed = true
+		return nil, nil, nil
+	}
+
+	var (
+		downloads []download
+		errs      []error
+	)
+	for _, file := range files {
+		name := path.Join(f.config.Dir, file.Name())
+		if f.config.MaxFileSize > 0 && file.Size() > f.config.MaxFileSize {
+			errs = append(errs, f.fail(file, fmt.Errorf("%w: %s has %d bytes, at most %d are accepted", ErrFileTooLarge, name, file.Size(), f.config.MaxFileSize)))
+			continue
+		}
+		content, err := readFile(client, name, f.config.MaxFileSize)
+		if err != nil {
+			if ctx.Err() != nil {
+				// The session is gone; the remaining files are not at
+				// fault and wait for the next poll
+				errs = append(errs, fmt.Errorf("download %s: %w", name, ctx.Err()))
+				break
+			}
+			errs = append(errs, f.fail(file, fmt.Errorf("download %s: %w", name, err)))
+			continue
+		}
+		downloads = append(downloads, download{file: file, name: name, content: content})
+	}
+	return downloads, errs, nil
+}
+
+// readFile returns the whole content of name. With a positive limit it
+// fails with ErrFileTooLarge once more than limit bytes were read, as the
+// file may have grown since it was listed.
+func readFile(client *sftp.Client, name string, limit int64) ([]byte, error) {
+	file, err := client.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if limit > 0 {
+		r = io.LimitReader(file, limit+1)
+	}
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && int64(len(content)) > limit {
+		return nil, fmt.Errorf("%w: %s has more than %d bytes", ErrFileTooLarge, name, limit)
+	}
+	return content, nil
+}
+
+// fail counts a failed poll of file and returns err. Once the file reaches
+// MaxAttempts it is quarantined: marked as seen and no longer retried.
+func (f *Fetcher) fail(file os.FileInfo, err error) error {
+	if f.failures == nil {
+		f.failures = map[string]int{}
+	}
+	f.failures[file.Name()]++
+	if f.config.MaxAttempts > 0 && f.failures[file.Name()] >= f.config.MaxAttempts {
+		log.Printf("WARNING: sftp feed quarantined %s after %d failed attempts", path.Join(f.config.Dir, file.Name()), f.failures[file.Name()])
+		delete(f.failures, file.Name())
+		f.markSeen(file)
+	}
+	return err
+}
+
+// newFiles returns the matching regular files not seen in their current
+// version, oldest first. Files still settling are left for a later poll:
+// those whose size or modification time changed since the previous listing
+// and those modified less than SettleInterval ago. Seen files and failures
+// no longer listed are forgotten.
+func (f *Fetcher) newFiles(entries []os.FileInfo) []os.FileInfo {
+	var files []os.FileInfo
+	previous := f.listed
+	f.listed = make(map[string]version, len(entries))
+	for _, file := range entries {
+		if !file.Mode().IsRegular() {
+			continue
+		}
+		if f.config.Pattern != "" {
+			if ok, _ := path.Match(f.config.Pattern, file.Name()); !ok {
+				continue
+			}
+		}
+		current := versionOf(file)
+		f.listed[file.Name()] = current
+		if seen, ok := f.seen[file.Name()]; ok && seen.equal(current) {
+			continue
+		}
+		if before, ok := previous[file.Name()]; ok && !before.equal(current) {
+			continue
+		}
+		if time.Since(file.ModTime()) < f.config.SettleInterval {
+			continue
+		}
+		files = append(files, file)
+	}
+	for name := range f.seen {
+		if _, ok := f.listed[name]; !ok {
+			delete(f.seen, name)
+		}
+	}
+	for name := range f.failures {
+		if _, ok := f.listed[name]; !ok {
+			delete(f.failures, name)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].ModTime().Equal(files[j].ModTime()) {
+			return files[i].ModTime().Before(files[j].ModTime())
+		}
+		return files[i].Name() < files[j].Name()
+	})
+	return files
+}
+
+// markSeen records the listed version of file, which is not imported again
+// until it changes
+func (f *Fetcher) markSeen(file os.FileInfo) {
+	f.seen[file.Name()] = versionOf(file)
+}
+
+// Run polls right away and then every Interval until ctx is cancelled,
+// skipping the polls that fall in a pause
+func (f *Fetcher) Run(ctx context.Context, load ImportFunc) {
+	f.pollLogged(ctx, load)
+
+	ticker := time.NewTicker(f.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			f.pollLogged(ctx, load)
+		}
+	}
+}
+
+func (f *Fetcher) pollLogged(ctx context.Context, load ImportFunc) {
+	if f.paused != nil && f.paused() {
+		return
+	}
+	imported, err := f.Poll(ctx, load)
+	if imported > 0 {
+		log.Printf("Imported %d SWIFT codes files from sftp://%s%s", imported, f.config.Address, f.config.Dir)
+	}
+	if err != nil {
+		log.Printf("WARNING: sftp feed poll failed: %v", err)
+	}
+}
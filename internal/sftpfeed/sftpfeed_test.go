@@ -0,0 +1,323 @@
+package sftpfeed_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/sftp"
+
+	"github.com/zdziszkee/swift-codes/internal/sftpfeed"
+)
+
+func TestSFTPFeed(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "SFTP Feed Suite")
+}
+
+// remoteFile is a file or directory served by fakeServer
+type remoteFile struct {
+	content string
+	modTime time.Time
+	dir     bool
+}
+
+// fakeServer serves a temporary directory over SFTP
+type fakeServer struct {
+	dir string
+}
+
+func newFakeServer(files map[string]remoteFile) *fakeServer {
+	s := &fakeServer{dir: GinkgoT().TempDir()}
+	for name, f := range files {
+		s.set(name, f)
+	}
+	return s
+}
+
+func (s *fakeServer) set(name string, f remoteFile) {
+	target := filepath.Join(s.dir, name)
+	if f.dir {
+		Expect(os.Mkdir(target, 0o755)).To(Succeed())
+	} else {
+		Expect(os.WriteFile(target, []byte(f.content), 0o644)).To(Succeed())
+	}
+	Expect(os.Chtimes(target, f.modTime, f.modTime)).To(Succeed())
+}
+
+// path returns the remote path of name
+func (s *fakeServer) path(name string) string {
+	return path.Join(filepath.ToSlash(s.dir), name)
+}
+
+// dial connects a client to the server over an in-memory pipe
+func (s *fakeServer) dial(ctx context.Context) (*sftp.Client, error) {
+	client, conn := net.Pipe()
+	server, err := sftp.NewServer(conn, sftp.ReadOnly())
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		_ = server.Serve()
+		server.Close()
+	}()
+	return sftp.NewClientPipe(client, client)
+}
+
+var _ = Describe("Fetcher", func() {
+	var (
+		server   *fakeServer
+		cfg      sftpfeed.Config
+		imported []string
+		failOn   string
+	)
+
+	load := func(ctx context.Context, name string, r io.Reader) error {
+		content, err := io.ReadAll(r)
+		Expect(err).NotTo(HaveOccurred())
+		if name == failOn {
+			return errors.New("import failed")
+		}
+		imported = append(imported, name+"="+string(content))
+		return nil
+	}
+
+	BeforeEach(func() {
+		server = newFakeServer(map[string]remoteFile{
+			"swift_2.csv": {content: "second", modTime: time.Unix(2000, 0)},
+			"swift_1.csv": {content: "first", modTime: time.Unix(1000, 0)},
+			"readme.txt":  {content: "ignored", modTime: time.Unix(3000, 0)},
+			"archive.csv": {dir: true, modTime: time.Unix(3000, 0)},
+		})
+		cfg = sftpfeed.Config{Dir: server.path(""), Pattern: "*.csv", ImportExisting: true}
+		imported, failOn = nil, ""
+	})
+
+	It("should import matching files oldest first and only once", func() {
+		fetcher := sftpfeed.NewFetcher(cfg, server.dial)
+		n, err := fetcher.Poll(context.Background(), load)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(2))
+		Expect(imported).To(Equal([]string{server.path("swift_1.csv") + "=first", server.path("swift_2.csv") + "=second"}))
+
+		server.set("swift_3.csv", remoteFile{content: "third", modTime: time.Unix(2000, 0)})
+		n, err = fetcher.Poll(context.Background(), load)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(1))
+		Expect(imported).To(HaveLen(3))
+		Expect(imported[2]).To(Equal(server.path("swift_3.csv") + "=third"))
+	})
+
+	It("should only mark files present at start-up as seen by default", func() {
+		cfg.ImportExisting = false
+		fetcher := sftpfeed.NewFetcher(cfg, server.dial)
+		n, err := fetcher.Poll(context.Background(), load)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(BeZero())
+
+		server.set("swift_4.csv", remoteFile{content: "fourth", modTime: time.Unix(4000, 0)})
+		_, err = fetcher.Poll(context.Background(), load)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(imported).To(Equal([]string{server.path("swift_4.csv") + "=fourth"}))
+	})
+
+	It("should retry a failed file on the next poll", func() {
+		fetcher := sftpfeed.NewFetcher(cfg, server.dial)
+		failOn = server.path("swift_2.csv")
+		n, err := fetcher.Poll(context.Background(), load)
+		Expect(err).To(MatchError(ContainSubstring("import failed")))
+		Expect(n).To(Equal(1))
+
+		failOn = ""
+		n, err = fetcher.Poll(context.Background(), load)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(1))
+		Expect(imported).To(Equal([]string{server.path("swift_1.csv") + "=first", server.path("swift_2.csv") + "=second"}))
+	})
+
+	It("should import the files after a failed one and retry it", func() {
+		fetcher := sftpfeed.NewFetcher(cfg, server.dial)
+		failOn = server.path("swift_1.csv")
+		n, err := fetcher.Poll(context.Background(), load)
+		Expect(err).To(MatchError(ContainSubstring("import " + server.path("swift_1.csv"))))
+		Expect(n).To(Equal(1))
+		Expect(imported).To(Equal([]string{server.path("swift_2.csv") + "=second"}))
+
+		failOn = ""
+		n, err = fetcher.Poll(context.Background(), load)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(1))
+		Expect(imported).To(Equal([]string{server.path("swift_2.csv") + "=second", server.path("swift_1.csv") + "=first"}))
+	})
+
+	It("should quarantine a file after MaxAttempts failed polls", func() {
+		cfg.MaxAttempts = 2
+		fetcher := sftpfeed.NewFetcher(cfg, server.dial)
+		failOn = server.path("swift_1.csv")
+		for range 2 {
+			_, err := fetcher.Poll(context.Background(), load)
+			Expect(err).To(HaveOccurred())
+		}
+
+		failOn = ""
+		n, err := fetcher.Poll(context.Background(), load)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(BeZero())
+		Expect(imported).To(Equal([]string{server.path("swift_2.csv") + "=second"}))
+	})
+
+	It("should reject files over MaxFileSize and import the others", func() {
+		cfg.MaxFileSize = 5
+		fetcher := sftpfeed.NewFetcher(cfg, server.dial)
+		n, err := fetcher.Poll(context.Background(), load)
+		Expect(err).To(MatchError(sftpfeed.ErrFileTooLarge))
+		Expect(n).To(Equal(1))
+		Expect(imported).To(Equal([]string{server.path("swift_1.csv") + "=first"}))
+	})
+
+	It("should import files larger than one SFTP read", func() {
+		large := strings.Repeat("x", 70*1024)
+		server.set("swift_3.csv", remoteFile{content: large, modTime: time.Unix(3000, 0)})
+		cfg.MaxFileSize = int64(len(large))
+		fetcher := sftpfeed.NewFetcher(cfg, server.dial)
+		n, err := fetcher.Poll(context.Background(), load)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(3))
+		Expect(imported[2]).To(Equal(server.path("swift_3.csv") + "=" + large))
+	})
+
+	It("should not bound the imports with the timeout", func() {
+		cfg.Timeout = 20 * time.Millisecond
+		fetcher := sftpfeed.NewFetcher(cfg, server.dial)
+		n, err := fetcher.Poll(context.Background(), func(ctx context.Context, name string, r io.Reader) error {
+			time.Sleep(30 * time.Millisecond)
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return load(ctx, name, r)
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(2))
+	})
+
+	It("should skip the polls of Run during a pause", func() {
+		cfg.Interval = 10 * time.Millisecond
+		var paused atomic.Bool
+		paused.Store(true)
+		var loaded atomic.Int32
+		fetcher := sftpfeed.NewFetcher(cfg, server.dial).PauseWhile(paused.Load)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			fetcher.Run(ctx, func(context.Context, string, io.Reader) error {
+				loaded.Add(1)
+				return nil
+			})
+		}()
+		Consistently(loaded.Load, 50*time.Millisecond).Should(BeZero())
+
+		paused.Store(false)
+		Eventually(loaded.Load).Should(Equal(int32(2)))
+		cancel()
+		Eventually(done).Should(BeClosed())
+	})
+
+	It("should import files delivered later with an older modification time", func() {
+		fetcher := sftpfeed.NewFetcher(cfg, server.dial)
+		_, err := fetcher.Poll(context.Background(), load)
+		Expect(err).NotTo(HaveOccurred())
+
+		server.set("swift_0.csv", remoteFile{content: "zeroth", modTime: time.Unix(500, 0)})
+		n, err := fetcher.Poll(context.Background(), load)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(1))
+		Expect(imported[2]).To(Equal(server.path("swift_0.csv") + "=zeroth"))
+	})
+
+	It("should import a file again once it is replaced", func() {
+		fetcher := sftpfeed.NewFetcher(cfg, server.dial)
+		_, err := fetcher.Poll(context.Background(), load)
+		Expect(err).NotTo(HaveOccurred())
+
+		server.set("swift_1.csv", remoteFile{content: "replaced", modTime: time.Unix(1000, 0)})
+		n, err := fetcher.Poll(context.Background(), load)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(BeZero())
+
+		n, err = fetcher.Poll(context.Background(), load)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(1))
+		Expect(imported[2]).To(Equal(server.path("swift_1.csv") + "=replaced"))
+	})
+
+	It("should wait for files to settle before importing them", func() {
+		cfg.SettleInterval = time.Hour
+		fetcher := sftpfeed.NewFetcher(cfg, server.dial)
+		server.set("swift_3.csv", remoteFile{content: "thi", modTime: time.Now()})
+		n, err := fetcher.Poll(context.Background(), load)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(2))
+
+		// Still growing: older than the settle interval now, but changed
+		// since the previous listing
+		server.set("swift_3.csv", remoteFile{content: "third", modTime: time.Unix(3000, 0)})
+		n, err = fetcher.Poll(context.Background(), load)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(BeZero())
+
+		n, err = fetcher.Poll(context.Background(), load)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(1))
+		Expect(imported[2]).To(Equal(server.path("swift_3.csv") + "=third"))
+	})
+
+	It("should report dial failures", func() {
+		fetcher := sftpfeed.NewFetcher(cfg, func(ctx context.Context) (*sftp.Client, error) {
+			return nil, errors.New("connection refused")
+		})
+		_, err := fetcher.Poll(context.Background(), load)
+		Expect(err).To(MatchError("connection refused"))
+	})
+})
+
+var _ = Describe("Config", func() {
+	valid := sftpfeed.Config{
+		Enabled: true, Address: "sftp.example.com:22", User: "swift", KeyFile: "/key",
+		KnownHostsFile: "/known_hosts", Dir: "/outgoing", Pattern: "*.csv", Interval: time.Minute,
+	}
+
+	It("should accept a complete feed", func() {
+		Expect(valid.Validate()).To(Succeed())
+		Expect(sftpfeed.Config{}.Validate()).To(Succeed())
+	})
+
+	It("should reject missing settings and bad patterns", func() {
+		missing := valid
+		missing.KnownHostsFile = ""
+		Expect(missing.Validate()).To(HaveOccurred())
+
+		pattern := valid
+		pattern.Pattern = "["
+		Expect(pattern.Validate()).To(HaveOccurred())
+
+		interval := valid
+		interval.Interval = 0
+		Expect(interval.Validate()).To(HaveOccurred())
+
+		attempts := valid
+		attempts.MaxAttempts = -1
+		Expect(attempts.Validate()).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,119 @@
+// Package timing collects per-stage durations of a single request for the
+// Server-Timing response header.
+package timing
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Stages reported in the Server-Timing header
+const (
+	// StageParse covers the work before the first service call
+	StageParse = "parse"
+	// StageService covers service calls, including their Trino queries
+	StageService = "service"
+	// StageTrino covers repository calls that reached Trino
+	StageTrino = "trino"
+	// StageSerialize covers the work after the last service call
+	StageSerialize = "serialize"
+	// StageTotal covers the whole request
+	StageTotal = "total"
+)
+
+type contextKey struct{}
+
+type span struct {
+	total       time.Duration
+	first, last time.Time
+}
+
+// Timings accumulates stage durations. It is safe for concurrent use, and
+// a nil *Timings ignores every call so callers need not check for it.
+type Timings struct {
+	mu    sync.Mutex
+	start time.Time
+	order []string
+	spans map[string]*span
+}
+
+// NewContext returns a context carrying a fresh Timings started now
+func NewContext(ctx context.Context) (context.Context, *Timings) {
+	t := &Timings{start: time.Now(), spans: map[string]*span{}}
+	return context.WithValue(ctx, contextKey{}, t), t
+}
+
+// FromContext returns the Timings carried by ctx, or nil
+func FromContext(ctx context.Context) *Timings {
+	t, _ := ctx.Value(contextKey{}).(*Timings)
+	return t
+}
+
+// Track starts timing stage on the Timings in ctx and returns the function
+// that stops it. Repeated spans of a stage add up; stages are reported in
+// the order they first started.
+func Track(ctx context.Context, stage string) func() {
+	t := FromContext(ctx)
+	if t == nil {
+		return func() {}
+	}
+	start := time.Now()
+	s := t.span(stage, start)
+	return func() {
+		end := time.Now()
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		s.total += end.Sub(start)
+		s.last = end
+	}
+}
+
+// span returns the span of stage, creating it when it first starts
+func (t *Timings) span(stage string, start time.Time) *span {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.spans[stage]
+	if !ok {
+		s = &span{first: start}
+		t.spans[stage] = s
+		t.order = append(t.order, stage)
+	}
+	return s
+}
+
+// Header formats the Server-Timing value as of end. Parse and serialize are
+// derived from the first and last service span; without a service call the
+// whole request counts as serialize.
+func (t *Timings) Header(end time.Time) string {
+	if t == nil {
+		return ""
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	parse, serialize := time.Duration(0), end.Sub(t.start)
+	if s, ok := t.spans[StageService]; ok {
+		parse, serialize = s.first.Sub(t.start), end.Sub(s.last)
+	}
+
+	var b strings.Builder
+	write := func(stage string, d time.Duration) {
+		if b.Len() > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(stage)
+		b.WriteString(";dur=")
+		b.WriteString(strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64))
+	}
+	write(StageParse, parse)
+	for _, stage := range t.order {
+		write(stage, t.spans[stage].total)
+	}
+	write(StageSerialize, serialize)
+	write(StageTotal, end.Sub(t.start))
+	return b.String()
+}
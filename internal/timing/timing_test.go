@@ -0,0 +1,37 @@
+package timing_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/timing"
+)
+
+func TestTiming(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Timing Suite")
+}
+
+var _ = Describe("Timings", func() {
+	It("should report stages in order around the service calls", func() {
+		ctx, timings := timing.NewContext(context.Background())
+
+		stopService := timing.Track(ctx, timing.StageService)
+		timing.Track(ctx, timing.StageTrino)()
+		timing.Track(ctx, timing.StageTrino)()
+		stopService()
+
+		header := timings.Header(time.Now())
+		Expect(header).To(MatchRegexp(`^parse;dur=\d+\.\d{3}, service;dur=\d+\.\d{3}, trino;dur=\d+\.\d{3}, serialize;dur=\d+\.\d{3}, total;dur=\d+\.\d{3}$`))
+	})
+
+	It("should ignore tracking without timings in the context", func() {
+		Expect(timing.FromContext(context.Background())).To(BeNil())
+		timing.Track(context.Background(), timing.StageService)()
+		Expect(timing.FromContext(context.Background()).Header(time.Now())).To(BeEmpty())
+	})
+})
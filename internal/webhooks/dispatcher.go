@@ -0,0 +1,217 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/zdziszkee/swift-codes/internal/correlation"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+// Headers set on every delivery
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderID        = "X-Webhook-ID"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Event is the JSON body POSTed to subscribers
+type Event struct {
+	ID         string         `json:"id"`
+	Type       string         `json:"type"`
+	OccurredAt time.Time      `json:"occurredAt"`
+	Data       service.Change `json:"data"`
+}
+
+// Sign returns the signature header value for body: "sha256=" followed by
+// the hex HMAC-SHA256 of body keyed with secret. Receivers recompute it over
+// the raw request body to verify a delivery.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher queues change events and delivers them to the matching
+// subscriptions in the background. Every subscription has its own worker
+// and queue, so the retries of one never hold back the others.
+type Dispatcher struct {
+	registry *Registry
+	config   Config
+	client   *http.Client
+	queue    chan Event
+}
+
+// delivery is an encoded event waiting for a subscription's worker
+type delivery struct {
+	sub   Subscription
+	event Event
+	body  []byte
+}
+
+// worker delivers the events queued for one subscription in order
+type worker struct {
+	queue  chan delivery
+	cancel context.CancelFunc
+}
+
+// NewDispatcher creates a dispatcher for the subscriptions in registry.
+// Nothing is delivered until Run is started.
+func NewDispatcher(registry *Registry, config Config) *Dispatcher {
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = 1
+	}
+	if config.QueueSize < 1 {
+		config.QueueSize = 1
+	}
+	return &Dispatcher{
+		registry: registry,
+		config:   config,
+		client:   &http.Client{Timeout: config.Timeout},
+		queue:    make(chan Event, config.QueueSize),
+	}
+}
+
+// Notify queues change for delivery without blocking; it has the
+// service.ChangeHook signature. The event is dropped when the queue is
+// full.
+func (d *Dispatcher) Notify(ctx context.Context, change service.Change) {
+	event := Event{ID: randomHex(16), Type: change.Type, OccurredAt: time.Now().UTC(), Data: change}
+	select {
+	case d.queue <- event:
+	default:
+		requestid.Logf(ctx, "WARNING: webhook queue full, dropping %s event %s", event.Type, event.ID)
+	}
+}
+
+// Run hands queued events to the workers of the subscriptions until ctx is
+// cancelled, which also stops the workers
+func (d *Dispatcher) Run(ctx context.Context) {
+	workers := map[string]*worker{}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-d.queue:
+			d.dispatch(ctx, workers, event)
+		}
+	}
+}
+
+// dispatch queues event for every subscription that wants it without
+// blocking, dropping it for subscriptions whose queue is full. Workers of
+// removed subscriptions are stopped.
+func (d *Dispatcher) dispatch(ctx context.Context, workers map[string]*worker, event Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("ERROR: encoding webhook event %s: %v", event.ID, err)
+		return
+	}
+	subs := d.registry.snapshot()
+	current := make(map[string]bool, len(subs))
+	for _, sub := range subs {
+		current[sub.ID] = true
+		if !sub.wants(event.Type) {
+			continue
+		}
+		w, ok := workers[sub.ID]
+		if !ok {
+			w = d.startWorker(ctx, sub.ID)
+			workers[sub.ID] = w
+		}
+		select {
+		case w.queue <- delivery{sub: sub, event: event, body: body}:
+		default:
+			requestid.Logf(correlation.NewContext(ctx, event.Data.IDs), "WARNING: webhook %s queue full, dropping %s event %s", sub.ID, event.Type, event.ID)
+		}
+	}
+	for id, w := range workers {
+		if !current[id] {
+			w.cancel()
+			delete(workers, id)
+		}
+	}
+}
+
+// startWorker starts delivering the events queued for subscription id
+// until ctx is cancelled or the worker is stopped
+func (d *Dispatcher) startWorker(ctx context.Context, id string) *worker {
+	ctx, cancel := context.WithCancel(ctx)
+	w := &worker{queue: make(chan delivery, d.config.QueueSize), cancel: cancel}
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case job := <-w.queue:
+				if err := d.deliver(ctx, job.sub, job.event, job.body); err != nil && ctx.Err() == nil {
+					requestid.Logf(correlation.NewContext(ctx, job.event.Data.IDs), "WARNING: webhook %s gave up on %s event %s: %v", id, job.event.Type, job.event.ID, err)
+				}
+			}
+		}
+	}()
+	return w
+}
+
+// deliver POSTs body to sub, retrying failures with exponential backoff
+func (d *Dispatcher) deliver(ctx context.Context, sub Subscription, event Event, body []byte) error {
+	backoff := d.config.RetryBackoff
+	var err error
+	for attempt := 1; attempt <= d.config.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			if !sleepContext(ctx, backoff) {
+				return ctx.Err()
+			}
+			backoff *= 2
+		}
+		if err = d.post(ctx, sub, event, body); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("%d attempts failed, last error: %w", d.config.MaxAttempts, err)
+}
+
+func (d *Dispatcher) post(ctx context.Context, sub Subscription, event Event, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, event.Type)
+	req.Header.Set(HeaderID, event.ID)
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, body))
+	if event.Data.RequestID != "" {
+		req.Header.Set(requestid.Header, event.Data.RequestID)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// sleepContext waits for d and reports false if ctx ended first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
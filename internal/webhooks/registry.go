@@ -0,0 +1,142 @@
+// Package webhooks lets operators subscribe URLs to dataset changes and
+// delivers those changes as signed JSON POSTs.
+package webhooks
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	service "github.com/zdziszkee/swift-codes/internal/services"
+)
+
+var (
+	ErrNotFound     = errors.New("webhook not found")
+	ErrInvalidURL   = errors.New("webhook url must be an absolute http or https URL")
+	ErrInvalidEvent = errors.New("unknown webhook event")
+)
+
+// Config holds the delivery settings of the webhook dispatcher
+type Config struct {
+	// Enabled mounts the subscription endpoints and starts the dispatcher
+	Enabled bool `koanf:"enabled"`
+	// MaxAttempts is how often a delivery is tried before it is dropped
+	MaxAttempts int `koanf:"max_attempts"`
+	// RetryBackoff is the wait before the second attempt; it doubles after
+	// every further failure
+	RetryBackoff time.Duration `koanf:"retry_backoff"`
+	// Timeout bounds a single delivery request
+	Timeout time.Duration `koanf:"timeout"`
+	// QueueSize bounds the events waiting to be dispatched and those
+	// waiting for each subscription; new events are dropped while a queue
+	// is full
+	QueueSize int `koanf:"queue_size"`
+}
+
+// Subscription is a URL that receives the listed change events
+type Subscription struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// Events lists the change types delivered to URL; empty means all
+	Events []string `json:"events"`
+	// Secret keys the HMAC signature of every delivery. It is only
+	// returned when the subscription is created.
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// wants reports whether the subscription receives events of the given type
+func (s Subscription) wants(eventType string) bool {
+	return len(s.Events) == 0 || slices.Contains(s.Events, eventType)
+}
+
+// Registry keeps webhook subscriptions in memory; they do not survive a
+// restart
+type Registry struct {
+	mu   sync.RWMutex
+	subs map[string]Subscription
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{subs: make(map[string]Subscription)}
+}
+
+// Add validates and stores a subscription, assigning its ID and, when
+// none is given, a random secret. The stored subscription is returned
+// including the secret.
+func (r *Registry) Add(sub Subscription) (Subscription, error) {
+	target, err := url.Parse(sub.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return Subscription{}, ErrInvalidURL
+	}
+	for _, event := range sub.Events {
+		if !slices.Contains(service.ChangeTypes, event) {
+			return Subscription{}, ErrInvalidEvent
+		}
+	}
+
+	sub.ID = randomHex(8)
+	if sub.Secret == "" {
+		sub.Secret = randomHex(32)
+	}
+	if sub.Events == nil {
+		sub.Events = []string{}
+	}
+	sub.CreatedAt = time.Now().UTC()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subs[sub.ID] = sub
+	return sub, nil
+}
+
+// Remove deletes the subscription with the given ID
+func (r *Registry) Remove(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.subs[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.subs, id)
+	return nil
+}
+
+// List returns all subscriptions, oldest first, without their secrets
+func (r *Registry) List() []Subscription {
+	subs := r.snapshot()
+	for idx := range subs {
+		subs[idx].Secret = ""
+	}
+	return subs
+}
+
+// snapshot copies the subscriptions, oldest first, with their secrets
+func (r *Registry) snapshot() []Subscription {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subs := make([]Subscription, 0, len(r.subs))
+	for _, sub := range r.subs {
+		subs = append(subs, sub)
+	}
+	sort.Slice(subs, func(i, j int) bool {
+		if !subs[i].CreatedAt.Equal(subs[j].CreatedAt) {
+			return subs[i].CreatedAt.Before(subs[j].CreatedAt)
+		}
+		return subs[i].ID < subs[j].ID
+	})
+	return subs
+}
+
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	// crypto/rand.Read never returns an error on supported platforms
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,160 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/internal/correlation"
+	"github.com/zdziszkee/swift-codes/internal/requestid"
+	service "github.com/zdziszkee/swift-codes/internal/services"
+	"github.com/zdziszkee/swift-codes/internal/webhooks"
+)
+
+func TestWebhooks(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Webhooks Suite")
+}
+
+// delivery is a request received by the test subscriber
+type delivery struct {
+	header http.Header
+	body   []byte
+}
+
+var _ = Describe("Dispatcher", func() {
+	var (
+		registry   *webhooks.Registry
+		dispatcher *webhooks.Dispatcher
+		server     *httptest.Server
+
+		mu         sync.Mutex
+		deliveries []delivery
+		failures   int
+	)
+
+	received := func() []delivery {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]delivery(nil), deliveries...)
+	}
+
+	BeforeEach(func() {
+		deliveries, failures = nil, 0
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			defer mu.Unlock()
+			if failures > 0 {
+				failures--
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			deliveries = append(deliveries, delivery{header: r.Header.Clone(), body: body})
+		}))
+		DeferCleanup(server.Close)
+
+		registry = webhooks.NewRegistry()
+		dispatcher = webhooks.NewDispatcher(registry, webhooks.Config{MaxAttempts: 3, RetryBackoff: time.Millisecond, Timeout: time.Second, QueueSize: 10})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		DeferCleanup(cancel)
+		go dispatcher.Run(ctx)
+	})
+
+	It("should POST signed events to matching subscriptions", func() {
+		sub, err := registry.Add(webhooks.Subscription{URL: server.URL, Events: []string{service.ChangeCreated}, Secret: "s3cret"})
+		Expect(err).NotTo(HaveOccurred())
+
+		dispatcher.Notify(context.Background(), service.Change{Type: service.ChangeDeleted, SwiftCode: "AAAABBCCXXX"})
+		dispatcher.Notify(context.Background(), service.Change{Type: service.ChangeCreated, SwiftCode: "AAAABBCCXXX", CountryISO2: "BB"})
+
+		Eventually(received).Should(HaveLen(1))
+		Consistently(received, 50*time.Millisecond).Should(HaveLen(1))
+		got := received()[0]
+		Expect(got.header.Get(webhooks.HeaderEvent)).To(Equal(service.ChangeCreated))
+		Expect(got.header.Get(webhooks.HeaderSignature)).To(Equal(webhooks.Sign(sub.Secret, got.body)))
+
+		var event webhooks.Event
+		Expect(json.Unmarshal(got.body, &event)).To(Succeed())
+		Expect(event.ID).To(Equal(got.header.Get(webhooks.HeaderID)))
+		Expect(event.Data).To(Equal(service.Change{Type: service.ChangeCreated, SwiftCode: "AAAABBCCXXX", CountryISO2: "BB"}))
+	})
+
+	It("should carry the request ID and actor of the change", func() {
+		_, err := registry.Add(webhooks.Subscription{URL: server.URL, Secret: "s3cret"})
+		Expect(err).NotTo(HaveOccurred())
+
+		ids := correlation.IDs{RequestID: "req-1", Actor: "alice"}
+		dispatcher.Notify(context.Background(), service.Change{Type: service.ChangeDeleted, SwiftCode: "AAAABBCCXXX", IDs: ids})
+
+		Eventually(received).Should(HaveLen(1))
+		got := received()[0]
+		Expect(got.header.Get(requestid.Header)).To(Equal("req-1"))
+		var event webhooks.Event
+		Expect(json.Unmarshal(got.body, &event)).To(Succeed())
+		Expect(event.Data.IDs).To(Equal(ids))
+	})
+
+	It("should retry failed deliveries", func() {
+		failures = 2
+		_, err := registry.Add(webhooks.Subscription{URL: server.URL})
+		Expect(err).NotTo(HaveOccurred())
+
+		dispatcher.Notify(context.Background(), service.Change{Type: service.ChangeBulkLoaded, Count: 42})
+		Eventually(received).Should(HaveLen(1))
+	})
+
+	It("should give up after the configured attempts", func() {
+		failures = 3
+		_, err := registry.Add(webhooks.Subscription{URL: server.URL})
+		Expect(err).NotTo(HaveOccurred())
+
+		dispatcher.Notify(context.Background(), service.Change{Type: service.ChangeBulkLoaded, Count: 42})
+		dispatcher.Notify(context.Background(), service.Change{Type: service.ChangeBulkLoaded, Count: 7})
+
+		Eventually(received).Should(HaveLen(1))
+		var event webhooks.Event
+		Expect(json.Unmarshal(received()[0].body, &event)).To(Succeed())
+		Expect(event.Data.Count).To(Equal(int64(7)))
+	})
+
+	It("should not hold back other subscriptions while one is retrying", func() {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		DeferCleanup(failing.Close)
+		slow := webhooks.NewDispatcher(registry, webhooks.Config{MaxAttempts: 2, RetryBackoff: time.Hour, Timeout: time.Second, QueueSize: 10})
+		ctx, cancel := context.WithCancel(context.Background())
+		DeferCleanup(cancel)
+		go slow.Run(ctx)
+
+		_, err := registry.Add(webhooks.Subscription{URL: failing.URL})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = registry.Add(webhooks.Subscription{URL: server.URL})
+		Expect(err).NotTo(HaveOccurred())
+
+		slow.Notify(context.Background(), service.Change{Type: service.ChangeBulkLoaded, Count: 1})
+		slow.Notify(context.Background(), service.Change{Type: service.ChangeBulkLoaded, Count: 2})
+		Eventually(received).Should(HaveLen(2))
+	})
+})
+
+var _ = Describe("Registry", func() {
+	It("should validate URLs and event types", func() {
+		registry := webhooks.NewRegistry()
+		_, err := registry.Add(webhooks.Subscription{URL: "/relative"})
+		Expect(err).To(MatchError(webhooks.ErrInvalidURL))
+		_, err = registry.Add(webhooks.Subscription{URL: "https://example.com", Events: []string{"bank.renamed"}})
+		Expect(err).To(MatchError(webhooks.ErrInvalidEvent))
+		Expect(registry.List()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,136 @@
+// Package swiftfile reads and validates SWIFT code CSV files. It is the
+// parsing half of the swift-codes import pipeline, exposed so other tools
+// can process the same files without depending on internal packages.
+package swiftfile
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+)
+
+// Columns are the expected header columns of a SWIFT codes file, in order
+var Columns = []string{
+	"COUNTRY ISO2 CODE",
+	"SWIFT CODE",
+	"CODE TYPE",
+	"NAME",
+	"ADDRESS",
+	"TOWN NAME",
+	"COUNTRY NAME",
+	"TIME ZONE",
+}
+
+// Record is a single data row of a SWIFT codes file with surrounding
+// whitespace trimmed from every field
+type Record struct {
+	// Line is the 1-based position of the row after the header
+	Line           int
+	CountryISOCode string
+	SwiftCode      string
+	CodeType       string
+	BankName       string
+	Address        string
+	TownName       string
+	CountryName    string
+	TimeZone       string
+}
+
+// Options configures a Reader
+type Options struct {
+	// Tolerant accepts files with extra columns after the expected ones;
+	// their names are available from Reader.UnknownColumns
+	Tolerant bool
+}
+
+// Reader streams records from a SWIFT codes file
+type Reader struct {
+	csv     *csv.Reader
+	width   int
+	unknown []string
+	line    int
+}
+
+// NewReader reads and checks the header of r. Lines starting with '#' are
+// comments, such as the licensing notice heading an export, and are
+// skipped. It returns io.EOF when r is empty.
+func NewReader(r io.Reader, opts Options) (*Reader, error) {
+	csvReader := csv.NewReader(r)
+	csvReader.Comment = '#'
+	csvReader.TrimLeadingSpace = true
+	csvReader.ReuseRecord = true
+
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	if len(header) != len(Columns) && !(opts.Tolerant && len(header) > len(Columns)) {
+		return nil, fmt.Errorf("invalid header length: expected %d, got %d", len(Columns), len(header))
+	}
+	for i, expected := range Columns {
+		if !strings.EqualFold(strings.TrimSpace(header[i]), expected) {
+			return nil, fmt.Errorf("invalid header: expected '%s' at index %d, got '%s'", expected, i, header[i])
+		}
+	}
+
+	reader := &Reader{csv: csvReader, width: len(header)}
+	for _, col := range header[len(Columns):] {
+		reader.unknown = append(reader.unknown, strings.TrimSpace(col))
+	}
+	return reader, nil
+}
+
+// UnknownColumns returns the extra header columns accepted in tolerant mode
+func (r *Reader) UnknownColumns() []string {
+	return r.unknown
+}
+
+// Next returns the next record, or io.EOF after the last one
+func (r *Reader) Next() (Record, error) {
+	row, err := r.csv.Read()
+	if err == io.EOF {
+		return Record{}, io.EOF
+	}
+	r.line++
+	if err != nil {
+		return Record{}, fmt.Errorf("row %d: %w", r.line, err)
+	}
+	if len(row) != r.width {
+		return Record{}, fmt.Errorf("row %d: invalid length", r.line)
+	}
+
+	field := func(i int) string { return strings.TrimSpace(row[i]) }
+	return Record{
+		Line:           r.line,
+		CountryISOCode: field(0),
+		SwiftCode:      field(1),
+		CodeType:       field(2),
+		BankName:       field(3),
+		Address:        field(4),
+		TownName:       field(5),
+		CountryName:    field(6),
+		TimeZone:       field(7),
+	}, nil
+}
+
+// All iterates over the remaining records. Iteration stops after the first
+// error, which is yielded with a zero Record.
+func (r *Reader) All() iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		for {
+			record, err := r.Next()
+			if err == io.EOF {
+				return
+			}
+			if !yield(record, err) || err != nil {
+				return
+			}
+		}
+	}
+}
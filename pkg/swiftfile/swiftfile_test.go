@@ -0,0 +1,99 @@
+package swiftfile_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zdziszkee/swift-codes/pkg/swiftfile"
+)
+
+func TestSwiftFile(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "SWIFT File Suite")
+}
+
+const header = "COUNTRY ISO2 CODE,SWIFT CODE,CODE TYPE,NAME,ADDRESS,TOWN NAME,COUNTRY NAME,TIME ZONE\n"
+
+var _ = Describe("Reader", func() {
+	It("should stream every record", func() {
+		input := header +
+			"PL,PKOPPLPWXXX,BIC11,BANK PEKAO SA,\"ZUBRA 1, WARSZAWA\",WARSZAWA,POLAND,Europe/Warsaw\n" +
+			"PL,PKOPPLPW123,BIC11,BANK PEKAO SA, DLUGA 1 ,WARSZAWA,POLAND,Europe/Warsaw\n"
+		reader, err := swiftfile.NewReader(strings.NewReader(input), swiftfile.Options{})
+		Expect(err).NotTo(HaveOccurred())
+
+		var records []swiftfile.Record
+		for record, err := range reader.All() {
+			Expect(err).NotTo(HaveOccurred())
+			records = append(records, record)
+		}
+		Expect(records).To(HaveLen(2))
+		Expect(records[0].Address).To(Equal("ZUBRA 1, WARSZAWA"))
+		Expect(records[0].TownName).To(Equal("WARSZAWA"))
+		Expect(records[1].Line).To(Equal(2))
+		Expect(records[1].Address).To(Equal("DLUGA 1"))
+	})
+
+	It("should return io.EOF for empty input", func() {
+		_, err := swiftfile.NewReader(strings.NewReader(""), swiftfile.Options{})
+		Expect(err).To(Equal(io.EOF))
+	})
+
+	It("should accept extra trailing columns only when tolerant", func() {
+		input := strings.TrimSuffix(header, "\n") + ",CITY CODE\n"
+		_, err := swiftfile.NewReader(strings.NewReader(input), swiftfile.Options{})
+		Expect(err).To(MatchError(ContainSubstring("invalid header length")))
+
+		reader, err := swiftfile.NewReader(strings.NewReader(input), swiftfile.Options{Tolerant: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reader.UnknownColumns()).To(Equal([]string{"CITY CODE"}))
+	})
+
+	It("should stop iterating at a malformed row", func() {
+		input := header + "PL,PKOPPLPWXXX\nPL,PKOPPLPW123,BIC11,BANK,ADDR,TOWN,POLAND,Europe/Warsaw\n"
+		reader, err := swiftfile.NewReader(strings.NewReader(input), swiftfile.Options{})
+		Expect(err).NotTo(HaveOccurred())
+
+		var errs []error
+		for _, err := range reader.All() {
+			errs = append(errs, err)
+		}
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0]).To(MatchError(ContainSubstring("row 1")))
+	})
+})
+
+var _ = Describe("Parse", func() {
+	valid := swiftfile.Record{
+		Line:           1,
+		CountryISOCode: "PL",
+		SwiftCode:      "PKOPPLPWXXX",
+		BankName:       "BANK PEKAO SA",
+		Address:        "ZUBRA 1",
+		CountryName:    "POLAND",
+	}
+
+	It("should derive the code base and headquarter flag", func() {
+		bank, err := swiftfile.Parse(valid)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bank.SwiftCodeBase).To(Equal("PKOPPLPW"))
+		Expect(bank.IsHeadquarter).To(BeTrue())
+	})
+
+	It("should report the first invalid field", func() {
+		record := valid
+		record.Line = 7
+		record.CountryISOCode = "POL"
+		_, err := swiftfile.Parse(record)
+
+		var validationErr *swiftfile.ValidationError
+		Expect(errors.As(err, &validationErr)).To(BeTrue())
+		Expect(validationErr.Line).To(Equal(7))
+		Expect(validationErr.Field).To(Equal("CountryISOCode"))
+	})
+})
@@ -0,0 +1,94 @@
+package swiftfile
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	bicRegex         = regexp.MustCompile(`^[A-Z]{6}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+	countryCodeRegex = regexp.MustCompile(`^[A-Z]{2}$`)
+)
+
+// Field length limits enforced by Validate
+const (
+	MaxBankNameLength    = 100
+	MaxAddressLength     = 200
+	MaxCountryNameLength = 100
+)
+
+// ValidationError describes why a record was rejected
+type ValidationError struct {
+	Line   int
+	Field  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("row %d: %s %s", e.Line, e.Field, e.Reason)
+}
+
+// Bank is a validated record in the shape stored by the service
+type Bank struct {
+	SwiftCode      string
+	SwiftCodeBase  string
+	CountryISOCode string
+	BankName       string
+	IsHeadquarter  bool
+	Address        string
+	TownName       string
+	TimeZone       string
+	CountryName    string
+}
+
+// Validate checks a record and returns a *ValidationError for the first
+// field that is missing or malformed
+func Validate(r Record) error {
+	invalid := func(field, reason string) error {
+		return &ValidationError{Line: r.Line, Field: field, Reason: reason}
+	}
+
+	switch {
+	case r.SwiftCode == "":
+		return invalid("SwiftCode", "cannot be empty")
+	case !bicRegex.MatchString(r.SwiftCode):
+		return invalid("SwiftCode", fmt.Sprintf("'%s' does not match BIC format", r.SwiftCode))
+	case r.BankName == "":
+		return invalid("BankName", "cannot be empty")
+	case len(r.BankName) > MaxBankNameLength:
+		return invalid("BankName", "exceeds maximum length")
+	case r.CountryISOCode == "":
+		return invalid("CountryISOCode", "cannot be empty")
+	case !countryCodeRegex.MatchString(r.CountryISOCode):
+		return invalid("CountryISOCode", fmt.Sprintf("'%s' does not match ISO2 format", r.CountryISOCode))
+	case r.Address == "":
+		return invalid("Address", "cannot be empty")
+	case len(r.Address) > MaxAddressLength:
+		return invalid("Address", "exceeds maximum length")
+	case r.CountryName == "":
+		return invalid("CountryName", "cannot be empty")
+	case len(r.CountryName) > MaxCountryNameLength:
+		return invalid("CountryName", "exceeds maximum length")
+	}
+	return nil
+}
+
+// Parse validates a record and converts it to a Bank. Codes ending in XXX
+// are headquarters; the first eight characters form the code base.
+func Parse(r Record) (Bank, error) {
+	if err := Validate(r); err != nil {
+		return Bank{}, err
+	}
+	return Bank{
+		SwiftCode:      r.SwiftCode,
+		SwiftCodeBase:  r.SwiftCode[:8],
+		CountryISOCode: r.CountryISOCode,
+		BankName:       r.BankName,
+		IsHeadquarter:  strings.HasSuffix(r.SwiftCode, "XXX"),
+		Address:        r.Address,
+		TownName:       r.TownName,
+		TimeZone:       r.TimeZone,
+		CountryName:    r.CountryName,
+	}, nil
+}
@@ -10,6 +10,10 @@ CREATE TABLE IF NOT EXISTS swift_catalog.default_schema.swift_banks (
     is_headquarter BOOLEAN,
     address VARCHAR,
     country_name VARCHAR,
+    website VARCHAR,
+    phone VARCHAR,
+    town_name VARCHAR,
+    time_zone VARCHAR,
     created_at TIMESTAMP,
     updated_at TIMESTAMP
 )
@@ -17,6 +21,37 @@ WITH (
     partitioning = ARRAY['country_iso_code']
 );
 
+-- Contact columns were added after the first release
+ALTER TABLE swift_catalog.default_schema.swift_banks ADD COLUMN IF NOT EXISTS website VARCHAR;
+ALTER TABLE swift_catalog.default_schema.swift_banks ADD COLUMN IF NOT EXISTS phone VARCHAR;
+-- Town names were added for address search
+ALTER TABLE swift_catalog.default_schema.swift_banks ADD COLUMN IF NOT EXISTS town_name VARCHAR;
+-- Time zones were added for completeness metrics
+ALTER TABLE swift_catalog.default_schema.swift_banks ADD COLUMN IF NOT EXISTS time_zone VARCHAR;
+
+-- Audit log of API writes; snapshots are JSON-encoded records
+CREATE TABLE IF NOT EXISTS swift_catalog.default_schema.swift_audit_log (
+    id VARCHAR,
+    occurred_at TIMESTAMP(6) WITH TIME ZONE,
+    actor VARCHAR,
+    action VARCHAR,
+    swift_code VARCHAR,
+    country_iso_code VARCHAR,
+    affected BIGINT,
+    before_state VARCHAR,
+    after_state VARCHAR,
+    request_id VARCHAR
+)
+WITH (
+    partitioning = ARRAY['day(occurred_at)']
+);
+
+-- Aliases: other codes a stored bank is also known by
+CREATE TABLE IF NOT EXISTS swift_catalog.default_schema.swift_code_aliases (
+    alias VARCHAR,
+    swift_code VARCHAR
+);
+
 -- Create the views using the Iceberg table
 CREATE OR REPLACE VIEW swift_catalog.default_schema.v_swift_bank_headquarters AS
 SELECT
@@ -10,21 +10,33 @@ import (
 
 // MockSwiftRepository implements the SwiftRepository interface for testing
 type MockSwiftRepository struct {
-	GetByCodeFunc           func(ctx context.Context, code string) (*repository.SwiftBankDetail, error)
-	GetByCountryFunc        func(ctx context.Context, countryCode string) (*repository.CountrySwiftCodes, error)
-	CreateFunc              func(ctx context.Context, bank *models.SwiftBank) error
-	CreateBatchFunc         func(ctx context.Context, banks []*models.SwiftBank) error
-	DeleteFunc              func(ctx context.Context, code string) error
-	GetBranchesByHQBaseFunc func(ctx context.Context, hqBase string) ([]models.SwiftBank, error)
-	LoadCSVFunc             func(ctx context.Context, file string) error
+	GetByCodeFunc             func(ctx context.Context, code string, opts repository.QueryOptions) (*repository.SwiftBankDetail, error)
+	GetByCountryFunc          func(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error)
+	CreateFunc                func(ctx context.Context, bank *models.SwiftBank) error
+	CreateBatchFunc           func(ctx context.Context, banks []*models.SwiftBank) error
+	DeleteFunc                func(ctx context.Context, code string) error
+	DeleteByCountryFunc       func(ctx context.Context, countryCode string) (int64, error)
+	GetBranchesByHQBaseFunc   func(ctx context.Context, hqBase string, opts repository.QueryOptions) ([]models.SwiftBank, error)
+	GetHeadquartersByBaseFunc func(ctx context.Context, hqBase string, opts repository.QueryOptions) (*models.SwiftBank, error)
+	GetByBaseFunc             func(ctx context.Context, base string, opts repository.QueryOptions) (*repository.BankGroup, error)
+	LoadCSVFunc               func(ctx context.Context, file string) error
+	StatsFunc                 func(ctx context.Context) (*repository.DatasetStats, error)
+	CountryCountsFunc         func(ctx context.Context) ([]repository.CountryCount, error)
+	CompletenessFunc          func(ctx context.Context) ([]repository.CountryCompleteness, error)
+	UpdateContactsFunc        func(ctx context.Context, contacts []models.BankContact) (int64, error)
+	UpsertFunc                func(ctx context.Context, banks []*models.SwiftBank) (int64, error)
+	ListAllFunc               func(ctx context.Context) ([]models.SwiftBank, error)
 }
 
-func (m *MockSwiftRepository) GetByCode(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
-	return m.GetByCodeFunc(ctx, code)
+func (m *MockSwiftRepository) GetByCode(ctx context.Context, code string, opts repository.QueryOptions) (*repository.SwiftBankDetail, error) {
+	if m.GetByCodeFunc != nil {
+		return m.GetByCodeFunc(ctx, code, opts)
+	}
+	return nil, repository.ErrNotFound
 }
 
-func (m *MockSwiftRepository) GetByCountry(ctx context.Context, countryCode string) (*repository.CountrySwiftCodes, error) {
-	return m.GetByCountryFunc(ctx, countryCode)
+func (m *MockSwiftRepository) GetByCountry(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+	return m.GetByCountryFunc(ctx, countryCode, opts)
 }
 
 func (m *MockSwiftRepository) Create(ctx context.Context, bank *models.SwiftBank) error {
@@ -39,16 +51,76 @@ func (m *MockSwiftRepository) Delete(ctx context.Context, code string) error {
 	return m.DeleteFunc(ctx, code)
 }
 
-func (m *MockSwiftRepository) GetBranchesByHQBase(ctx context.Context, hqBase string) ([]models.SwiftBank, error) {
+func (m *MockSwiftRepository) DeleteByCountry(ctx context.Context, countryCode string) (int64, error) {
+	return m.DeleteByCountryFunc(ctx, countryCode)
+}
+
+func (m *MockSwiftRepository) GetBranchesByHQBase(ctx context.Context, hqBase string, opts repository.QueryOptions) ([]models.SwiftBank, error) {
 	if m.GetBranchesByHQBaseFunc != nil {
-		return m.GetBranchesByHQBaseFunc(ctx, hqBase)
+		return m.GetBranchesByHQBaseFunc(ctx, hqBase, opts)
 	}
 	return nil, errors.New("GetBranchesByHQBase not implemented")
 }
 
+func (m *MockSwiftRepository) GetHeadquartersByBase(ctx context.Context, hqBase string, opts repository.QueryOptions) (*models.SwiftBank, error) {
+	if m.GetHeadquartersByBaseFunc != nil {
+		return m.GetHeadquartersByBaseFunc(ctx, hqBase, opts)
+	}
+	return nil, errors.New("GetHeadquartersByBase not implemented")
+}
+
+func (m *MockSwiftRepository) GetByBase(ctx context.Context, base string, opts repository.QueryOptions) (*repository.BankGroup, error) {
+	if m.GetByBaseFunc != nil {
+		return m.GetByBaseFunc(ctx, base, opts)
+	}
+	return nil, errors.New("GetByBase not implemented")
+}
+
 func (m *MockSwiftRepository) LoadCSV(ctx context.Context, file string) error {
 	if m.LoadCSVFunc != nil {
 		return m.LoadCSVFunc(ctx, file)
 	}
 	return errors.New("LoadCSV not implemented")
 }
+
+func (m *MockSwiftRepository) Stats(ctx context.Context) (*repository.DatasetStats, error) {
+	if m.StatsFunc != nil {
+		return m.StatsFunc(ctx)
+	}
+	return nil, errors.New("Stats not implemented")
+}
+
+func (m *MockSwiftRepository) CountryCounts(ctx context.Context) ([]repository.CountryCount, error) {
+	if m.CountryCountsFunc != nil {
+		return m.CountryCountsFunc(ctx)
+	}
+	return nil, errors.New("CountryCounts not implemented")
+}
+
+func (m *MockSwiftRepository) Completeness(ctx context.Context) ([]repository.CountryCompleteness, error) {
+	if m.CompletenessFunc != nil {
+		return m.CompletenessFunc(ctx)
+	}
+	return nil, errors.New("Completeness not implemented")
+}
+
+func (m *MockSwiftRepository) UpdateContacts(ctx context.Context, contacts []models.BankContact) (int64, error) {
+	if m.UpdateContactsFunc != nil {
+		return m.UpdateContactsFunc(ctx, contacts)
+	}
+	return 0, errors.New("UpdateContacts not implemented")
+}
+
+func (m *MockSwiftRepository) Upsert(ctx context.Context, banks []*models.SwiftBank) (int64, error) {
+	if m.UpsertFunc != nil {
+		return m.UpsertFunc(ctx, banks)
+	}
+	return 0, errors.New("Upsert not implemented")
+}
+
+func (m *MockSwiftRepository) ListAll(ctx context.Context) ([]models.SwiftBank, error) {
+	if m.ListAllFunc != nil {
+		return m.ListAllFunc(ctx)
+	}
+	return nil, errors.New("ListAll not implemented")
+}
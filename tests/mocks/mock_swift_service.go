@@ -5,22 +5,37 @@ import (
 
 	models "github.com/zdziszkee/swift-codes/internal/models"
 	repository "github.com/zdziszkee/swift-codes/internal/repositories"
+	service "github.com/zdziszkee/swift-codes/internal/services"
 )
 
 // MockSwiftService implements service.SwiftService.
 type MockSwiftService struct {
 	GetSwiftCodeDetailsFunc    func(ctx context.Context, code string) (*repository.SwiftBankDetail, error)
-	GetSwiftCodesByCountryFunc func(ctx context.Context, countryCode string) (*repository.CountrySwiftCodes, error)
+	GetHeadquartersFunc        func(ctx context.Context, code string) (*models.SwiftBank, error)
+	GetBankGroupFunc           func(ctx context.Context, base string) (*repository.BankGroup, error)
+	GetSwiftCodesByCountryFunc func(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error)
 	CreateSwiftCodeFunc        func(ctx context.Context, bank *models.SwiftBank) error
 	DeleteSwiftCodeFunc        func(ctx context.Context, code string) error
+	DeleteByCountryFunc        func(ctx context.Context, countryCode string) (int64, error)
+	DatasetStatusFunc          func(ctx context.Context) (*service.DatasetStatus, error)
+	CompletenessFunc           func(ctx context.Context) ([]service.CountryCompleteness, error)
+	CountryCountsFunc          func(ctx context.Context) ([]repository.CountryCount, error)
 }
 
 func (m *MockSwiftService) GetSwiftCodeDetails(ctx context.Context, code string) (*repository.SwiftBankDetail, error) {
 	return m.GetSwiftCodeDetailsFunc(ctx, code)
 }
 
-func (m *MockSwiftService) GetSwiftCodesByCountry(ctx context.Context, countryCode string) (*repository.CountrySwiftCodes, error) {
-	return m.GetSwiftCodesByCountryFunc(ctx, countryCode)
+func (m *MockSwiftService) GetHeadquarters(ctx context.Context, code string) (*models.SwiftBank, error) {
+	return m.GetHeadquartersFunc(ctx, code)
+}
+
+func (m *MockSwiftService) GetBankGroup(ctx context.Context, base string) (*repository.BankGroup, error) {
+	return m.GetBankGroupFunc(ctx, base)
+}
+
+func (m *MockSwiftService) GetSwiftCodesByCountry(ctx context.Context, countryCode string, opts repository.QueryOptions) (*repository.CountrySwiftCodes, error) {
+	return m.GetSwiftCodesByCountryFunc(ctx, countryCode, opts)
 }
 
 func (m *MockSwiftService) CreateSwiftCode(ctx context.Context, bank *models.SwiftBank) error {
@@ -30,3 +45,19 @@ func (m *MockSwiftService) CreateSwiftCode(ctx context.Context, bank *models.Swi
 func (m *MockSwiftService) DeleteSwiftCode(ctx context.Context, code string) error {
 	return m.DeleteSwiftCodeFunc(ctx, code)
 }
+
+func (m *MockSwiftService) DatasetStatus(ctx context.Context) (*service.DatasetStatus, error) {
+	return m.DatasetStatusFunc(ctx)
+}
+
+func (m *MockSwiftService) DeleteSwiftCodesByCountry(ctx context.Context, countryCode string) (int64, error) {
+	return m.DeleteByCountryFunc(ctx, countryCode)
+}
+
+func (m *MockSwiftService) Completeness(ctx context.Context) ([]service.CountryCompleteness, error) {
+	return m.CompletenessFunc(ctx)
+}
+
+func (m *MockSwiftService) CountryCounts(ctx context.Context) ([]repository.CountryCount, error) {
+	return m.CountryCountsFunc(ctx)
+}